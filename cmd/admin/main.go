@@ -0,0 +1,307 @@
+// Command admin runs operator actions on accounts against any environment,
+// with the same code paths as the deployed handlers:
+//
+//	admin -config config/staging.json user alice
+//	admin -profile prod suspend alice@example.com
+//	admin invites 5
+//	admin bootstrap
+//	admin quota example.edu
+//	admin stats hour 48
+//	admin blocklist add squatter "impersonates staff"
+//	admin review reject squatter
+//
+// Settings come from the -config file, as for the service, and AWS
+// credentials from the -profile named in the shared config. Suspensions,
+// reviews and blocklist changes are recorded under the IAM identity of
+// those credentials. Results are printed to stdout as JSON and
+// logs go to stderr.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	appconfig "github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/app"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/handlers"
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `usage: admin [flags] <command> [arguments]
+
+commands:
+  user <did|handle|email>                 show an account
+  resend-verification <did|handle|email>  send the verification email again
+  suspend <did|handle|email>              suspend an account and take it down on the PDS
+  invites [count]                         mint single-use invite codes
+  bootstrap                               create the BOOTSTRAP_ACCOUNTS operator accounts
+  quota [email domain]                    show today's signup quotas and what is left of them
+  stats [hour|day] [count]                show the signup stats of the last count hours or days
+  blocklist add <handle> [reason]         block a handle
+  blocklist remove <handle> [reason]      unblock a handle
+  blocklist list                          list blocked handles
+  blocklist audit [limit]                 show recent blocklist changes
+  review list [limit]                     list signups held for review, oldest first
+  review approve <did|handle|email>       let a held signup carry on to verification
+  review reject <did|handle|email>        reject a held signup and release its handle and email
+
+flags:
+`
+
+func main() {
+	configFile := flag.String("config", "", "path to a JSON file of environment settings")
+	profile := flag.String("profile", "", "AWS shared config profile to use")
+	tenant := flag.String("tenant", "", "tenant to act on (default: the default tenant)")
+	logLevel := flag.String("log-level", "warn", "log level")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *configFile != "" {
+		if err := appconfig.LoadEnvFile(*configFile); err != nil {
+			fail(err)
+		}
+	}
+	if *profile != "" {
+		os.Setenv("AWS_PROFILE", *profile)
+	}
+
+	logrus.SetOutput(os.Stderr)
+	if err := logging.Configure(*logLevel, ""); err != nil {
+		fail(err)
+	}
+	logging.InstallHooks(os.Getenv("LOG_REDACT_FIELDS"), os.Getenv("LOG_REDACT_ALLOW"))
+	// Operator runs stay out of the service's dashboards, and EMF would
+	// print to stdout.
+	if err := metrics.Configure(metrics.BackendNone, "", ""); err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+	container, err := app.New(ctx, app.Options{})
+	if err != nil {
+		fail(err)
+	}
+
+	result, err := run(ctx, container, *tenant, flag.Args())
+	if err != nil {
+		fail(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fail(err)
+	}
+}
+
+func run(ctx context.Context, container *app.Container, tenant string, args []string) (interface{}, error) {
+	command, args := args[0], args[1:]
+	admin := container.Admin
+
+	switch command {
+	case "user":
+		if len(args) != 1 {
+			return nil, usageError("user <did|handle|email>")
+		}
+		return admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionLookup, User: args[0], Tenant: tenant})
+	case "resend-verification":
+		if len(args) != 1 {
+			return nil, usageError("resend-verification <did|handle|email>")
+		}
+		return admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionResendVerification, User: args[0], Tenant: tenant})
+	case "suspend":
+		if len(args) != 1 {
+			return nil, usageError("suspend <did|handle|email>")
+		}
+		found, err := admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionLookup, User: args[0], Tenant: tenant})
+		if err != nil {
+			return nil, err
+		}
+		if ctx, err = authenticated(ctx, container.Services.AWS); err != nil {
+			return nil, err
+		}
+		return container.Lifecycle.Handle(ctx, models.LifecycleRequest{
+			Event:  lifecycle.EventSuspend,
+			DID:    found.Account.DID,
+			Tenant: tenant,
+		})
+	case "invites":
+		count := 1
+		if len(args) > 1 {
+			return nil, usageError("invites [count]")
+		}
+		if len(args) == 1 {
+			var err error
+			if count, err = strconv.Atoi(args[0]); err != nil {
+				return nil, usageError("invites [count]")
+			}
+		}
+		return admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionMintInvites, Count: count, Tenant: tenant})
+	case "bootstrap":
+		if len(args) != 0 {
+			return nil, usageError("bootstrap")
+		}
+		return admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionBootstrap, Tenant: tenant})
+	case "quota":
+		if len(args) > 1 {
+			return nil, usageError("quota [email domain]")
+		}
+		req := models.AdminRequest{Action: handlers.AdminActionQuota, Tenant: tenant}
+		if len(args) == 1 {
+			req.Domain = args[0]
+		}
+		return admin.Handle(ctx, req)
+	case "stats":
+		return stats(ctx, container, tenant, args)
+	case "blocklist":
+		req, err := blocklistRequest(args)
+		if err != nil {
+			return nil, err
+		}
+		req.Tenant = tenant
+		if req.Action == handlers.BlocklistActionAdd || req.Action == handlers.BlocklistActionRemove {
+			if ctx, err = authenticated(ctx, container.Services.AWS); err != nil {
+				return nil, err
+			}
+		}
+		return container.Blocklist.Handle(ctx, req)
+	case "review":
+		return review(ctx, container, tenant, args)
+	default:
+		return nil, fmt.Errorf("unknown command %q; run admin -h for usage", command)
+	}
+}
+
+// authenticated returns ctx with the IAM identity of the AWS credentials in
+// use as the caller, as a Lambda integration with IAM authorization would
+// see it.
+func authenticated(ctx context.Context, awsCfg aws.Config) (context.Context, error) {
+	identity, err := sts.NewFromConfig(awsCfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return ctx, fmt.Errorf("failed to look up the AWS caller identity: %w", err)
+	}
+	return caller.With(ctx, aws.ToString(identity.Arn)), nil
+}
+
+func blocklistRequest(args []string) (models.BlocklistRequest, error) {
+	if len(args) == 0 {
+		return models.BlocklistRequest{}, usageError("blocklist add|remove|list|audit")
+	}
+
+	req := models.BlocklistRequest{Action: args[0]}
+	switch req.Action {
+	case handlers.BlocklistActionAdd, handlers.BlocklistActionRemove:
+		if len(args) < 2 || len(args) > 3 {
+			return req, usageError("blocklist " + req.Action + " <handle> [reason]")
+		}
+		req.Handle = args[1]
+		if len(args) == 3 {
+			req.Reason = args[2]
+		}
+	case handlers.BlocklistActionList:
+		if len(args) != 1 {
+			return req, usageError("blocklist list")
+		}
+	case handlers.BlocklistActionAudit:
+		if len(args) > 2 {
+			return req, usageError("blocklist audit [limit]")
+		}
+		if len(args) == 2 {
+			limit, err := strconv.Atoi(args[1])
+			if err != nil {
+				return req, usageError("blocklist audit [limit]")
+			}
+			req.Limit = limit
+		}
+	default:
+		return req, usageError("blocklist add|remove|list|audit")
+	}
+	return req, nil
+}
+
+func review(ctx context.Context, container *app.Container, tenant string, args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, usageError("review list|approve|reject")
+	}
+
+	req := models.ReviewRequest{Action: args[0], Tenant: tenant}
+	switch req.Action {
+	case handlers.ReviewActionList:
+		if len(args) > 2 {
+			return nil, usageError("review list [limit]")
+		}
+		if len(args) == 2 {
+			limit, err := strconv.Atoi(args[1])
+			if err != nil {
+				return nil, usageError("review list [limit]")
+			}
+			req.Limit = limit
+		}
+	case handlers.ReviewActionApprove, handlers.ReviewActionReject:
+		if len(args) != 2 {
+			return nil, usageError("review " + req.Action + " <did|handle|email>")
+		}
+		found, err := container.Admin.Handle(ctx, models.AdminRequest{Action: handlers.AdminActionLookup, User: args[1], Tenant: tenant})
+		if err != nil {
+			return nil, err
+		}
+		req.DID = found.Account.DID
+		if ctx, err = authenticated(ctx, container.Services.AWS); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, usageError("review list|approve|reject")
+	}
+	return container.Review.Handle(ctx, req)
+}
+
+func stats(ctx context.Context, container *app.Container, tenant string, args []string) (interface{}, error) {
+	req := models.AdminRequest{Action: handlers.AdminActionStats, Tenant: tenant}
+	if len(args) > 0 && (args[0] == models.StatsPeriodHour || args[0] == models.StatsPeriodDay) {
+		req.Period, args = args[0], args[1:]
+	}
+	if len(args) > 1 {
+		return nil, usageError("stats [hour|day] [count]")
+	}
+	if len(args) == 1 {
+		count, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, usageError("stats [hour|day] [count]")
+		}
+		req.Count = count
+	}
+	return container.Admin.Handle(ctx, req)
+}
+
+func usageError(form string) error {
+	return fmt.Errorf("usage: admin %s", form)
+}
+
+// fail prints err, with its category when it has one, and exits.
+func fail(err error) {
+	if category := apperr.CategoryOf(err); category != apperr.Internal {
+		fmt.Fprintf(os.Stderr, "admin: %s: %v\n", category, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "admin: %v\n", err)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,366 @@
+// Command import creates accounts in bulk from a CSV file, for moving an
+// existing community onto ShareFrame:
+//
+//	import -config config/prod.json -tenant acme -report results.csv members.csv
+//
+// The file needs a header row with handle and email columns; password,
+// display_name and locale are optional. Rows without a password get a
+// random one nobody is told, and their owners set their own through the
+// password reset flow.
+//
+// Every row is checked with the same format validators as a signup before
+// any account is created, and rows that repeat a handle or mailbox from an
+// earlier row are rejected. The rest are signed up through the signup
+// handler, -concurrency at a time, so they get the full validation, the
+// audit trail and the welcome email. One result per row is written to the
+// -report file as CSV, in the order rows finish.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	appconfig "github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/app"
+	"github.com/ShareFrame/user-management/internal/handlers"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/sirupsen/logrus"
+)
+
+// Row outcomes in the report.
+const (
+	statusCreated = "created"
+	statusValid   = "valid"
+	statusInvalid = "invalid"
+	statusFailed  = "failed"
+)
+
+// passwordAlphabet is what generated passwords are drawn from. It covers
+// every class validate.Password requires.
+const passwordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!@#$%^&*-_=+"
+
+type row struct {
+	number      int
+	handle      string
+	email       string
+	password    string
+	displayName string
+	locale      string
+}
+
+type result struct {
+	row    row
+	status string
+	did    string
+	code   string
+	err    string
+}
+
+func main() {
+	configFile := flag.String("config", "", "path to a JSON file of environment settings")
+	profile := flag.String("profile", "", "AWS shared config profile to use")
+	tenantID := flag.String("tenant", "", "tenant to import into (default: the default tenant)")
+	concurrency := flag.Int("concurrency", 4, "accounts to create at once")
+	reportFile := flag.String("report", "", "file to write the per-row results to (default: stdout)")
+	dryRun := flag.Bool("dry-run", false, "only check the rows; create nothing")
+	logLevel := flag.String("log-level", "warn", "log level")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: import [flags] <file.csv>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *concurrency < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *configFile != "" {
+		if err := appconfig.LoadEnvFile(*configFile); err != nil {
+			fail(err)
+		}
+	}
+	if *profile != "" {
+		os.Setenv("AWS_PROFILE", *profile)
+	}
+
+	logrus.SetOutput(os.Stderr)
+	if err := logging.Configure(*logLevel, ""); err != nil {
+		fail(err)
+	}
+	logging.InstallHooks(os.Getenv("LOG_REDACT_FIELDS"), os.Getenv("LOG_REDACT_ALLOW"))
+	if err := metrics.Configure(metrics.BackendNone, "", ""); err != nil {
+		fail(err)
+	}
+
+	input, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	defer input.Close()
+	rows, err := readRows(input)
+	if err != nil {
+		fail(fmt.Errorf("%s: %w", flag.Arg(0), err))
+	}
+
+	ctx := context.Background()
+	container, err := app.New(ctx, app.Options{})
+	if err != nil {
+		fail(err)
+	}
+
+	tenant, err := container.Services.Config.ResolveTenant(*tenantID)
+	if err != nil {
+		fail(err)
+	}
+
+	output := os.Stdout
+	if *reportFile != "" {
+		if output, err = os.Create(*reportFile); err != nil {
+			fail(err)
+		}
+		defer output.Close()
+	}
+	report := newReport(output)
+
+	valid := checkRows(rows, tenant.HandleSuffix, report)
+	if *dryRun {
+		for _, r := range valid {
+			report.write(result{row: r, status: statusValid})
+		}
+	} else {
+		importRows(ctx, container.Users, tenant.ID, valid, *concurrency, report)
+	}
+
+	if err := report.close(); err != nil {
+		fail(fmt.Errorf("failed to write report: %w", err))
+	}
+	fmt.Fprintln(os.Stderr, report.summary())
+	if report.counts[statusInvalid]+report.counts[statusFailed] > 0 {
+		os.Exit(1)
+	}
+}
+
+// readRows reads the CSV, locating columns by the header row.
+func readRows(input io.Reader) ([]row, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"handle", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header has no %s column", required)
+		}
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, row{
+			number:      line,
+			handle:      strings.TrimPrefix(field("handle"), "@"),
+			email:       field("email"),
+			password:    field("password"),
+			displayName: field("display_name"),
+			locale:      field("locale"),
+		})
+	}
+}
+
+// checkRows reports the rows that fail the format validators or repeat an
+// earlier row's handle or mailbox, and returns the rest.
+func checkRows(rows []row, suffix string, report *report) []row {
+	handles := map[string]int{}
+	emails := map[string]int{}
+
+	var valid []row
+	for _, r := range rows {
+		if err := checkRow(r, suffix); err != nil {
+			report.write(result{row: r, status: statusInvalid, code: validate.ErrorCode(err), err: err.Error()})
+			continue
+		}
+
+		handle := strings.ToLower(validate.EnsureHandleSuffix(r.handle, suffix))
+		if first, ok := handles[handle]; ok {
+			report.write(result{row: r, status: statusInvalid, code: validate.CodeHandleTaken, err: fmt.Sprintf("handle repeats row %d", first)})
+			continue
+		}
+		email := postgres.NormalizeEmail(r.email)
+		if first, ok := emails[email]; ok {
+			report.write(result{row: r, status: statusInvalid, code: validate.CodeEmailTaken, err: fmt.Sprintf("email repeats row %d", first)})
+			continue
+		}
+		handles[handle], emails[email] = r.number, r.number
+		valid = append(valid, r)
+	}
+	return valid
+}
+
+func checkRow(r row, suffix string) error {
+	if r.handle == "" || r.email == "" {
+		return validate.NewError(validate.CodeMissingFields, "handle and email are required")
+	}
+	if err := validate.Email(validate.CanonicalizeEmail(r.email)); err != nil {
+		return err
+	}
+
+	handle := strings.TrimSuffix(strings.ToLower(r.handle), suffix)
+	if strings.Contains(handle, ".") {
+		if err := validate.DomainHandle(handle); err != nil {
+			return err
+		}
+	} else if validate.IsASCII(handle) {
+		if err := validate.Handle(handle); err != nil {
+			return err
+		}
+	}
+
+	if r.password != "" {
+		if err := validate.Password(r.password); err != nil {
+			return err
+		}
+	}
+	if r.displayName != "" {
+		if err := validate.DisplayName(validate.NormalizeDisplayName(r.displayName)); err != nil {
+			return err
+		}
+	}
+	if r.locale != "" {
+		if _, err := validate.Locale(r.locale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRows signs the rows up, concurrency at a time.
+func importRows(ctx context.Context, users *handlers.UserHandler, tenant string, rows []row, concurrency int, report *report) {
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, r := range rows {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(r row) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			report.write(importRow(ctx, users, tenant, r))
+		}(r)
+	}
+	wg.Wait()
+}
+
+func importRow(ctx context.Context, users *handlers.UserHandler, tenant string, r row) result {
+	password := r.password
+	if password == "" {
+		var err error
+		if password, err = randomPassword(); err != nil {
+			return result{row: r, status: statusFailed, code: validate.CodeInternal, err: err.Error()}
+		}
+	}
+
+	user, err := handlers.Recover("import", users.Handle)(ctx, models.UserRequest{
+		Handle:          r.handle,
+		Email:           r.email,
+		Password:        password,
+		PasswordConfirm: password,
+		DisplayName:     r.displayName,
+		Locale:          r.locale,
+		Tenant:          tenant,
+	})
+	if err != nil {
+		status := statusFailed
+		if validate.ErrorCode(err) != "" {
+			status = statusInvalid
+		}
+		return result{row: r, status: status, code: validate.ErrorCode(err), err: err.Error()}
+	}
+	return result{row: r, status: statusCreated, did: user.DID}
+}
+
+// randomPassword returns a password that meets validate.Password.
+func randomPassword() (string, error) {
+	for {
+		password := make([]byte, 24)
+		for i := range password {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+			if err != nil {
+				return "", fmt.Errorf("failed to generate password: %w", err)
+			}
+			password[i] = passwordAlphabet[n.Int64()]
+		}
+		if validate.Password(string(password)) == nil {
+			return string(password), nil
+		}
+	}
+}
+
+// report writes one CSV line per row as results come in.
+type report struct {
+	mu     sync.Mutex
+	writer *csv.Writer
+	counts map[string]int
+}
+
+func newReport(output io.Writer) *report {
+	r := &report{writer: csv.NewWriter(output), counts: map[string]int{}}
+	r.writer.Write([]string{"row", "handle", "email", "status", "did", "code", "error"})
+	return r
+}
+
+func (r *report) write(res result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[res.status]++
+	r.writer.Write([]string{strconv.Itoa(res.row.number), res.row.handle, res.row.email, res.status, res.did, res.code, res.err})
+	r.writer.Flush()
+}
+
+func (r *report) close() error {
+	r.writer.Flush()
+	return r.writer.Error()
+}
+
+func (r *report) summary() string {
+	return fmt.Sprintf("import: %d created, %d valid, %d invalid, %d failed",
+		r.counts[statusCreated], r.counts[statusValid], r.counts[statusInvalid], r.counts[statusFailed])
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "import: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,103 @@
+// Command repair fixes a tenant's accounts in batches:
+//
+//	repair -config config/prod.json -tenant acme -dry-run
+//	repair -config config/prod.json -tenant acme
+//	repair -config config/prod.json -tenant acme -delete-orphans
+//
+// Users rows written before the current schema version get their
+// normalized email, handle skeleton and verified flag filled in and are
+// stamped with the version. PDS accounts with no users row, left by
+// signups that failed after registering them, are listed, and deleted with
+// -delete-orphans. Review a -dry-run first: a signup in progress looks
+// like an orphan for a moment.
+//
+// The report is printed to stdout as JSON and logs go to stderr. It is
+// safe to run again after a failure.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	appconfig "github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/app"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/repair"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	configFile := flag.String("config", "", "path to a JSON file of environment settings")
+	profile := flag.String("profile", "", "AWS shared config profile to use")
+	tenant := flag.String("tenant", "", "tenant to repair (default: the default tenant)")
+	batchSize := flag.Int("batch-size", repair.DefaultBatchSize, "rows or PDS accounts to read at a time")
+	dryRun := flag.Bool("dry-run", false, "report what would be fixed without fixing it")
+	deleteOrphans := flag.Bool("delete-orphans", false, "delete PDS accounts that have no stored account")
+	logLevel := flag.String("log-level", "info", "log level")
+	flag.Parse()
+	if flag.NArg() != 0 || *batchSize < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *configFile != "" {
+		if err := appconfig.LoadEnvFile(*configFile); err != nil {
+			fail(err)
+		}
+	}
+	if *profile != "" {
+		os.Setenv("AWS_PROFILE", *profile)
+	}
+
+	logrus.SetOutput(os.Stderr)
+	if err := logging.Configure(*logLevel, ""); err != nil {
+		fail(err)
+	}
+	logging.InstallHooks(os.Getenv("LOG_REDACT_FIELDS"), os.Getenv("LOG_REDACT_ALLOW"))
+	if err := metrics.Configure(metrics.BackendNone, "", ""); err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+	container, err := app.New(ctx, app.Options{})
+	if err != nil {
+		fail(err)
+	}
+
+	resp, err := container.Repair.Handle(ctx, models.RepairRequest{
+		Tenant:        *tenant,
+		BatchSize:     *batchSize,
+		DryRun:        *dryRun,
+		DeleteOrphans: *deleteOrphans,
+	})
+	// A run that stopped part way still reports what it got through.
+	if resp != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(resp); err != nil {
+			fail(err)
+		}
+	}
+	if err != nil {
+		fail(err)
+	}
+	if len(resp.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// fail prints err, with its category when it has one, and exits.
+func fail(err error) {
+	if category := apperr.CategoryOf(err); category != apperr.Internal {
+		fmt.Fprintf(os.Stderr, "repair: %s: %v\n", category, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "repair: %v\n", err)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,134 @@
+// Command server serves the account API over plain HTTP, for container
+// deployments and local development. It runs the same handlers as the
+// Lambda functions, with REST routes and a GraphQL endpoint at /graphql;
+// see handlers.NewRouter for the routes.
+//
+//	server -addr :8080 -config config/dev.json
+//
+// With -grpc-addr it also serves the UserService of proto/users/v1 over
+// gRPC, for internal callers on latency-sensitive paths; its GetUser and
+// DeleteUser calls are operator calls, refused unless -admin is set.
+//
+// Settings come from the environment, or the -config file, exactly as for
+// the Lambda functions. The server drains in-flight requests on SIGTERM.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	appconfig "github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/app"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds how long in-flight requests get to finish after
+// SIGTERM. Kubernetes waits 30 seconds by default before killing the pod.
+const shutdownTimeout = 25 * time.Second
+
+func main() {
+	addr := flag.String("addr", defaultAddr(), "address to listen on")
+	backend := flag.String("backend", "", "storage backend to use: postgres (default) or memory")
+	configFile := flag.String("config", "", "path to a JSON file of environment settings")
+	admin := flag.Bool("admin", false, "also serve the unauthenticated /admin routes and gRPC operator calls; only for servers unreachable from outside")
+	grpcAddr := flag.String("grpc-addr", os.Getenv("GRPC_ADDR"), "address to serve gRPC on (default: no gRPC server)")
+	flag.Parse()
+
+	if *configFile != "" {
+		if err := appconfig.LoadEnvFile(*configFile); err != nil {
+			logrus.Fatalf("Failed to load config file: %v", err)
+		}
+	}
+	if *backend != "" {
+		os.Setenv("STORAGE_BACKEND", *backend)
+	}
+
+	if err := logging.Configure(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_DEBUG_SAMPLE_RATE")); err != nil {
+		logrus.Fatalf("Invalid logging configuration: %v", err)
+	}
+	if err := logging.ConfigureBackend(os.Getenv("LOG_BACKEND")); err != nil {
+		logrus.Fatalf("Invalid logging configuration: %v", err)
+	}
+	if err := metrics.Configure(os.Getenv("METRICS_BACKEND"), os.Getenv("METRICS_NAMESPACE"), os.Getenv("METRICS_STATSD_ADDRESS")); err != nil {
+		logrus.Fatalf("Invalid metrics configuration: %v", err)
+	}
+	logging.InstallHooks(os.Getenv("LOG_REDACT_FIELDS"), os.Getenv("LOG_REDACT_ALLOW"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	container, err := app.New(ctx, app.Options{RecordConfigSnapshot: true})
+	if err != nil {
+		logrus.Fatalf("Failed to build application: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           container.Router(*admin),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		logrus.WithFields(logrus.Fields{"addr": *addr, "admin": *admin}).Info("Starting HTTP server")
+		errs <- fmt.Errorf("HTTP server stopped: %w", server.ListenAndServe())
+	}()
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			logrus.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer = container.GRPCServer(*admin)
+		go func() {
+			logrus.WithFields(logrus.Fields{"addr": *grpcAddr, "admin": *admin}).Info("Starting gRPC server")
+			errs <- fmt.Errorf("gRPC server stopped: %w", grpcServer.Serve(listener))
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		logrus.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+	}()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logrus.WithError(err).Error("HTTP server did not shut down cleanly")
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		logrus.Error("gRPC server did not shut down cleanly")
+		grpcServer.Stop()
+	}
+}
+
+// defaultAddr listens on $PORT when the platform sets it.
+func defaultAddr() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
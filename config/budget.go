@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/budget"
+)
+
+// DefaultExecutionBudget leaves the PDS most of the invocation but keeps
+// enough back for the database write that follows a successful
+// registration.
+var DefaultExecutionBudget = map[budget.Step]int{
+	budget.StepValidation: 15,
+	budget.StepPDS:        45,
+	budget.StepDB:         25,
+	budget.StepEmail:      15,
+}
+
+// loadExecutionBudget reads the comma-separated step=percent pairs in
+// EXECUTION_BUDGET. Steps left out keep their default share, and the
+// shares can't add up to more than 100.
+func loadExecutionBudget(env *envResolver) (map[budget.Step]int, error) {
+	shares := map[budget.Step]int{}
+	for step, share := range DefaultExecutionBudget {
+		shares[step] = share
+	}
+
+	for _, pair := range env.list("EXECUTION_BUDGET") {
+		name, raw, ok := strings.Cut(pair, "=")
+		step := budget.Step(strings.ToLower(strings.TrimSpace(name)))
+		share, err := strconv.Atoi(strings.TrimSpace(raw))
+		if _, known := DefaultExecutionBudget[step]; !ok || !known || err != nil || share < 0 {
+			return nil, fmt.Errorf("invalid EXECUTION_BUDGET entry: %q", pair)
+		}
+		shares[step] = share
+	}
+
+	total := 0
+	for _, share := range shares {
+		total += share
+	}
+	if total > 100 {
+		return nil, fmt.Errorf("EXECUTION_BUDGET shares add up to %d%%, more than 100%%", total)
+	}
+	return shares, nil
+}
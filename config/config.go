@@ -6,12 +6,19 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"os"
+	"strings"
+	"time"
 
+	"github.com/ShareFrame/user-management/internal/budget"
+	"github.com/ShareFrame/user-management/internal/crm"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/tracing"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
-	"github.com/sirupsen/logrus"
 )
 
 type PostgresSecret struct {
@@ -24,12 +31,232 @@ type PostgresSecret struct {
 	SecretARN    string `json:"secretArn"`
 }
 
+const (
+	DefaultQueryTimeout = 3 * time.Second
+	DefaultHTTPTimeout  = 15 * time.Second
+
+	BackendPostgres = "postgres"
+	// BackendMemory keeps every table in process memory, for local runs and
+	// tests. Nothing survives a restart, and no POSTGRES_CONN_STR is needed.
+	BackendMemory = "memory"
+
+	DefaultMinPasswordScore   = 3
+	DefaultBreachCheckTimeout = 2 * time.Second
+	DefaultDomainCacheTTL     = 5 * time.Minute
+	DefaultDLQMaxReceives     = 5
+	DefaultEmailMaxAttempts   = 10
+	DefaultRateLimitShards    = 4
+	DefaultRiskWindow         = time.Hour
+	DefaultRiskReviewScore    = 50
+	DefaultRiskCaptchaScore   = 80
+	DefaultResponseTokenTTL   = 5 * time.Minute
+	DefaultExportURLTTL       = 24 * time.Hour
+	DefaultErasureConfirmTTL  = 24 * time.Hour
+	DefaultHandleClaimTTL     = 30 * 24 * time.Hour
+	DefaultHandleQuarantine   = 90 * 24 * time.Hour
+	DefaultPhoneCodeTTL       = 10 * time.Minute
+	DefaultPhoneMaxAccounts   = 3
+	DefaultPhoneSendsPerDay   = 5
+	DefaultBotSignupsPerDay   = 3
+	DefaultBotEmailDomain     = "bots.invalid"
+	DefaultAvatarUploadTTL    = 15 * time.Minute
+	DefaultAvatarMaxBytes     = 1000000
+	DefaultSignupStatsWindow  = 7 * 24 * time.Hour
+
+	// ProfanityReject fails validation for profane handles and display names;
+	// ProfanityFlag lets them through but marks the account for review.
+	ProfanityReject = "reject"
+	ProfanityFlag   = "flag"
+
+	// SocialCredentialGenerated gives social signups a random password
+	// nobody sees; SocialCredentialAppPassword also returns an app password
+	// for clients that sign in over the AT Protocol.
+	SocialCredentialGenerated   = "generated"
+	SocialCredentialAppPassword = "app_password"
+)
+
+var supportedBackends = map[string]bool{
+	BackendPostgres: true,
+	BackendMemory:   true,
+}
+
 type Config struct {
 	DBClusterARN    string
 	SecretARN       string
 	DatabaseName    string
 	PostgresConnStr string
 	AtProtoBaseURL  string
+	Tenants         map[string]Tenant
+	QueryTimeout    time.Duration
+	HTTPTimeout     time.Duration
+	// EmailTemplateSource is "embedded", a file path, or an s3://bucket/key URI.
+	EmailTemplateSource string
+	EmailSecretName     string
+	Retry               retry.Policy
+	ProfileDefaults     ProfileDefaults
+	StorageBackend      string
+	// AllowUnicodeHandles opts in to internationalized handles, which are
+	// registered with the PDS in their punycode form.
+	AllowUnicodeHandles bool
+	ProfanityMode       string
+	// MinPasswordScore is the lowest zxcvbn score (1-4) accepted at signup.
+	MinPasswordScore int
+	// BreachCheckEnabled turns on the Have I Been Pwned lookup. The lookup
+	// fails open after BreachCheckTimeout.
+	BreachCheckEnabled bool
+	BreachCheckTimeout time.Duration
+	// AllowCustomDomainHandles lets users sign up with a handle on their own
+	// domain once its _atproto TXT record or well-known endpoint points at
+	// their DID. Verified domains are cached for DomainCacheTTL.
+	AllowCustomDomainHandles bool
+	DomainCacheTTL           time.Duration
+	// UserInviteCodes makes signups supply their own invite code, checked
+	// against the invites table, instead of the service minting one with
+	// the tenant's admin account.
+	UserInviteCodes bool
+	// DLQMaxReceives is how many times a failed signup is re-driven from the
+	// dead-letter queue before it is filed for manual review.
+	DLQMaxReceives int
+	// EmailMaxAttempts is how many times a queued email is retried before
+	// the queue gives up on it.
+	EmailMaxAttempts int
+	// AccountEventsTopicARN, when set, is the SNS topic account lifecycle
+	// notifications are published to.
+	AccountEventsTopicARN string
+	WebhookEndpoints      []WebhookEndpoint
+	// RiskScoring scores signups for abuse from the attempts in the last
+	// RiskWindow. Scores from RiskReviewScore are flagged for review; from
+	// RiskCaptchaScore a captcha is required, verified with the Turnstile
+	// secret in CaptchaSecretName, or flagged if that isn't set.
+	RiskScoring       bool
+	RiskWindow        time.Duration
+	RiskReviewScore   int
+	RiskCaptchaScore  int
+	CaptchaSecretName string
+	DomainThrottle    DomainThrottle
+	// RateLimitTable, when set, is the DynamoDB table shared rate limit
+	// counters are kept in, each split over RateLimitShards items. Without
+	// it the domain throttle counts signups in Postgres.
+	RateLimitTable  string
+	RateLimitShards int
+	// ConfigSnapshotTable, when set, is the DynamoDB table the snapshot of
+	// each cold start's configuration is kept in, to compare the next one
+	// against. Without it the snapshot is only logged.
+	ConfigSnapshotTable string
+	// SignupQuota caps the signups per day, overall and per email domain.
+	SignupQuota SignupQuota
+	// SignupStats records why each failed signup attempt failed and rolls
+	// signups up into hourly and daily stats. A scheduled run recomputes
+	// the last SignupStatsWindow, the time in which late verifications
+	// still change a period's conversion.
+	SignupStats       bool
+	SignupStatsWindow time.Duration
+	// EmailRecipientLimit caps the emails sent to one address per hour; 0
+	// means no cap. It needs RateLimitTable.
+	EmailRecipientLimit int
+	// ExecutionBudget is the share, in percent, of the time left in an
+	// invocation reserved for each step of a signup.
+	ExecutionBudget map[budget.Step]int
+	// FaultInjection is the percentage of calls to each dependency that
+	// fail on purpose, for resilience testing outside production.
+	FaultInjection map[faults.Target]int
+	// ResponseTokenSecretName, when set, names the signing key for the
+	// short-lived token returned with each signup, which lets downstream
+	// services trust the result without querying the database. Tokens are
+	// valid for ResponseTokenTTL.
+	ResponseTokenSecretName string
+	ResponseTokenTTL        time.Duration
+	// TokenKMSKeyID, when set, signs tokens with this asymmetric KMS key
+	// instead of the shared secret. Tokens signed with one of
+	// TokenKMSPreviousKeyIDs still verify, so keys can be rotated without
+	// invalidating tokens in flight.
+	TokenKMSKeyID          string
+	TokenKMSPreviousKeyIDs []string
+	// PrivacyExportBucket receives the data exports made for access
+	// requests; each is shared through a link valid for ExportURLTTL.
+	// Erasures must be confirmed within ErasureConfirmTTL and need a token
+	// key, TokenKMSKeyID or ResponseTokenSecretName.
+	PrivacyExportBucket string
+	ExportURLTTL        time.Duration
+	ErasureConfirmTTL   time.Duration
+	// RequireProcessingConsent rejects signups that don't grant data
+	// processing consent. Consent choices are recorded either way.
+	RequireProcessingConsent bool
+	// StripeCustomers creates a Stripe customer for each new account, with
+	// the API key from StripeSecretName, and stores its ID on the account.
+	StripeCustomers  bool
+	StripeSecretName string
+	// AnalyticsSecretName, when set, names the write key and pseudonym salt
+	// for reporting signups to the Segment-compatible API at
+	// AnalyticsEndpoint.
+	AnalyticsSecretName string
+	AnalyticsEndpoint   string
+	// CRMProvider, when set, is the CRM verified accounts are synced to,
+	// with the API key from CRMSecretName. CRMSyncDisabled pauses syncing
+	// without removing the configuration.
+	CRMProvider     string
+	CRMSecretName   string
+	CRMSyncDisabled bool
+	// HandleClaims lets a handle be reserved for an email address, for
+	// HandleClaimTTL, before the account exists. Signups honor the claims
+	// while it is on.
+	HandleClaims   bool
+	HandleClaimTTL time.Duration
+	// HandleQuarantine is how long the handle of an erased account is held
+	// back before anyone can register it again, so a deleted user can't be
+	// impersonated straight away; 0 releases it at once.
+	HandleQuarantine time.Duration
+	// OrganizationAccounts allows signups for organizations, validated with
+	// their own rules and run by the owners they name.
+	OrganizationAccounts bool
+	// ActivationEmailTemplate, when set, is the template source of the
+	// email sent once an account becomes active.
+	ActivationEmailTemplate string
+	// GoogleClientIDs and AppleClientIDs are the OAuth client IDs social
+	// signup identity tokens may be issued to. A provider with none is
+	// turned off.
+	GoogleClientIDs  []string
+	AppleClientIDs   []string
+	SocialCredential string
+	// PhoneVerification turns on SMS verification of phone numbers. Codes
+	// last PhoneCodeTTL and a number can be verified by PhoneMaxAccounts
+	// accounts at most. A number is texted PhoneSendsPerDay codes a day at
+	// most, whichever accounts ask for them. SMSSenderID is shown as the sender where carriers
+	// support it. PhoneClearsRiskReview approves accounts held for review
+	// only because of their signup risk once their phone is verified.
+	PhoneVerification     bool
+	PhoneCodeTTL          time.Duration
+	PhoneMaxAccounts      int
+	PhoneSendsPerDay      int
+	SMSSenderID           string
+	PhoneClearsRiskReview bool
+	// BotAccounts opens the bot provisioning path. An owner can create
+	// BotSignupsPerDay bots a day. Bots have no mailbox, so they are
+	// registered with an address under BotEmailDomain.
+	BotAccounts      bool
+	BotSignupsPerDay int
+	BotEmailDomain   string
+	// AvatarBucket receives profile pictures uploaded at signup through a
+	// presigned link valid for AvatarUploadTTL. Pictures over
+	// AvatarMaxBytes, the PDS's own limit, are refused.
+	AvatarBucket    string
+	AvatarUploadTTL time.Duration
+	AvatarMaxBytes  int
+	// OnboardingSeeding seeds each new account after signup with its
+	// profile record and follows of the StarterFollows DIDs.
+	OnboardingSeeding bool
+	StarterFollows    []string
+	// ShareFrameProfileRecord creates each new account's
+	// social.shareframe.profile record in its own repo, so its theme,
+	// colors and banner don't live only in our storage.
+	ShareFrameProfileRecord bool
+	// BootstrapAccounts are the operator accounts the admin bootstrap
+	// command provisions, each with the role its email address is
+	// assigned.
+	BootstrapAccounts []BootstrapAccount
+	// Rollout limits who can complete a signup during a soft launch; the
+	// rest get a waitlist response. Bootstrap accounts always get through.
+	Rollout Rollout
 }
 
 type SecretsManagerAPI interface {
@@ -42,10 +269,155 @@ func LoadConfig(ctx context.Context, secretsClient SecretsManagerAPI) (*Config,
 		return nil, aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	secretName := os.Getenv("POSTGRES_CONN_STR")
-	baseURL := os.Getenv("ATPROTO_BASE_URL")
+	env := newEnvResolver(ctx, newKMSClient(awsCfg))
+	secretName := env.get("POSTGRES_CONN_STR")
+	baseURL := env.get("ATPROTO_BASE_URL")
+	tenantsRaw := env.get("TENANTS_CONFIG")
+	fallbackTenant := defaultTenant(env, baseURL)
+	queryTimeout := env.duration("QUERY_TIMEOUT", DefaultQueryTimeout)
+	httpTimeout := env.duration("HTTP_TIMEOUT", DefaultHTTPTimeout)
+	emailTemplateSource := env.get("EMAIL_TEMPLATE_SOURCE")
+	emailSecretName := env.get("RESEND_SECRET_NAME")
+	retryPolicy, err := loadRetryPolicy(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	profileDefaults, err := loadProfileDefaults(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	bootstrapAccounts, err := parseBootstrapAccounts(env.get("BOOTSTRAP_ACCOUNTS"), profileDefaults.RoleAssignments)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	allowUnicodeHandles := env.boolean("ALLOW_UNICODE_HANDLES", false)
+	minPasswordScore := env.integer("PASSWORD_MIN_SCORE", DefaultMinPasswordScore)
+	if minPasswordScore > 4 {
+		return nil, aws.Config{}, fmt.Errorf("PASSWORD_MIN_SCORE must be between 1 and 4, got %d", minPasswordScore)
+	}
+	breachCheckEnabled := env.boolean("PASSWORD_BREACH_CHECK", false)
+	breachCheckTimeout := env.duration("PASSWORD_BREACH_CHECK_TIMEOUT", DefaultBreachCheckTimeout)
+	allowCustomDomainHandles := env.boolean("ALLOW_CUSTOM_DOMAIN_HANDLES", false)
+	domainCacheTTL := env.duration("DOMAIN_VERIFICATION_CACHE_TTL", DefaultDomainCacheTTL)
+	userInviteCodes := env.boolean("USER_INVITE_CODES", false)
+	dlqMaxReceives := env.integer("DLQ_MAX_RECEIVES", DefaultDLQMaxReceives)
+	emailMaxAttempts := env.integer("EMAIL_QUEUE_MAX_ATTEMPTS", DefaultEmailMaxAttempts)
+	accountEventsTopicARN := env.get("ACCOUNT_EVENTS_TOPIC_ARN")
+	webhooksRaw := env.get("WEBHOOK_ENDPOINTS")
+	riskScoring := env.boolean("RISK_SCORING", false)
+	riskWindow := env.duration("RISK_WINDOW", DefaultRiskWindow)
+	riskReviewScore := env.integer("RISK_REVIEW_SCORE", DefaultRiskReviewScore)
+	riskCaptchaScore := env.integer("RISK_CAPTCHA_SCORE", DefaultRiskCaptchaScore)
+	captchaSecretName := env.get("CAPTCHA_SECRET_NAME")
+	domainThrottle, err := loadDomainThrottle(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	rateLimitTable := env.get("RATE_LIMIT_TABLE")
+	rateLimitShards := env.integer("RATE_LIMIT_SHARDS", DefaultRateLimitShards)
+	configSnapshotTable := env.get("CONFIG_SNAPSHOT_TABLE")
+	signupQuota, err := loadSignupQuota(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	if signupQuota.Enabled() && rateLimitTable == "" {
+		return nil, aws.Config{}, errors.New("signup quotas require RATE_LIMIT_TABLE")
+	}
+	signupStats := env.boolean("SIGNUP_STATS", false)
+	signupStatsWindow := env.duration("SIGNUP_STATS_WINDOW", DefaultSignupStatsWindow)
+	emailRecipientLimit := env.integer("EMAIL_RECIPIENT_LIMIT", 0)
+	executionBudget, err := loadExecutionBudget(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	faultInjection, err := loadFaultInjection(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	responseTokenSecretName := env.get("RESPONSE_TOKEN_SECRET_NAME")
+	responseTokenTTL := env.duration("RESPONSE_TOKEN_TTL", DefaultResponseTokenTTL)
+	tokenKMSKeyID := env.get("TOKEN_KMS_KEY_ID")
+	tokenKMSPreviousKeyIDs := env.list("TOKEN_KMS_PREVIOUS_KEY_IDS")
+	privacyExportBucket := env.get("PRIVACY_EXPORT_BUCKET")
+	exportURLTTL := env.duration("PRIVACY_EXPORT_URL_TTL", DefaultExportURLTTL)
+	erasureConfirmTTL := env.duration("ERASURE_CONFIRMATION_TTL", DefaultErasureConfirmTTL)
+	requireProcessingConsent := env.boolean("REQUIRE_DATA_PROCESSING_CONSENT", false)
+	stripeCustomers := env.boolean("STRIPE_CUSTOMERS", false)
+	stripeSecretName := env.get("STRIPE_SECRET_NAME")
+	if stripeCustomers && stripeSecretName == "" {
+		return nil, aws.Config{}, errors.New("STRIPE_CUSTOMERS requires STRIPE_SECRET_NAME")
+	}
+	analyticsSecretName := env.get("ANALYTICS_SECRET_NAME")
+	analyticsEndpoint := env.get("ANALYTICS_ENDPOINT")
+	crmProvider := env.get("CRM_PROVIDER")
+	if crmProvider != "" && !crm.ValidProvider(crmProvider) {
+		return nil, aws.Config{}, fmt.Errorf("invalid CRM_PROVIDER %q", crmProvider)
+	}
+	crmSecretName := env.get("CRM_SECRET_NAME")
+	if crmProvider != "" && crmSecretName == "" {
+		return nil, aws.Config{}, errors.New("CRM_PROVIDER requires CRM_SECRET_NAME")
+	}
+	crmSyncDisabled := env.boolean("CRM_SYNC_DISABLED", false)
+	handleClaims := env.boolean("HANDLE_CLAIMS", false)
+	handleClaimTTL := env.duration("HANDLE_CLAIM_TTL", DefaultHandleClaimTTL)
+	handleQuarantine := env.duration("HANDLE_QUARANTINE", DefaultHandleQuarantine)
+	organizationAccounts := env.boolean("ORGANIZATION_ACCOUNTS", false)
+	activationEmailTemplate := env.get("ACTIVATION_EMAIL_TEMPLATE")
+	googleClientIDs := env.list("GOOGLE_CLIENT_IDS")
+	appleClientIDs := env.list("APPLE_CLIENT_IDS")
+	socialCredential := env.get("SOCIAL_SIGNUP_CREDENTIAL")
+	if socialCredential == "" {
+		socialCredential = SocialCredentialGenerated
+	}
+	if socialCredential != SocialCredentialGenerated && socialCredential != SocialCredentialAppPassword {
+		return nil, aws.Config{}, fmt.Errorf("invalid SOCIAL_SIGNUP_CREDENTIAL: %s", socialCredential)
+	}
+	phoneVerification := env.boolean("PHONE_VERIFICATION", false)
+	phoneCodeTTL := env.duration("PHONE_CODE_TTL", DefaultPhoneCodeTTL)
+	phoneMaxAccounts := env.integer("PHONE_MAX_ACCOUNTS", DefaultPhoneMaxAccounts)
+	phoneSendsPerDay := env.integer("PHONE_SENDS_PER_DAY", DefaultPhoneSendsPerDay)
+	smsSenderID := env.get("SMS_SENDER_ID")
+	phoneClearsRiskReview := env.boolean("PHONE_CLEARS_RISK_REVIEW", true)
+	botAccounts := env.boolean("BOT_ACCOUNTS", false)
+	botSignupsPerDay := env.integer("BOT_SIGNUPS_PER_DAY", DefaultBotSignupsPerDay)
+	botEmailDomain := env.get("BOT_EMAIL_DOMAIN")
+	if botEmailDomain == "" {
+		botEmailDomain = DefaultBotEmailDomain
+	}
+	avatarBucket := env.get("AVATAR_BUCKET")
+	avatarUploadTTL := env.duration("AVATAR_UPLOAD_URL_TTL", DefaultAvatarUploadTTL)
+	avatarMaxBytes := env.integer("AVATAR_MAX_BYTES", DefaultAvatarMaxBytes)
+	onboardingSeeding := env.boolean("ONBOARDING_SEEDING", false)
+	starterFollows := env.list("STARTER_FOLLOWS")
+	for _, did := range starterFollows {
+		if !strings.HasPrefix(did, "did:") {
+			return nil, aws.Config{}, fmt.Errorf("invalid STARTER_FOLLOWS entry %q: must be a DID", did)
+		}
+	}
+	shareFrameProfileRecord := env.boolean("SHAREFRAME_PROFILE_RECORD", false)
+	rollout, err := loadRollout(env)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	profanityMode := env.get("PROFANITY_MODE")
+	if profanityMode == "" {
+		profanityMode = ProfanityReject
+	}
+	if profanityMode != ProfanityReject && profanityMode != ProfanityFlag {
+		return nil, aws.Config{}, fmt.Errorf("invalid PROFANITY_MODE: %s", profanityMode)
+	}
+	backend := env.get("STORAGE_BACKEND")
+	if backend == "" {
+		backend = BackendPostgres
+	}
+	if !supportedBackends[backend] {
+		return nil, aws.Config{}, fmt.Errorf("unsupported storage backend: %s", backend)
+	}
+	if env.err != nil {
+		return nil, aws.Config{}, env.err
+	}
 
-	if secretName == "" {
+	if secretName == "" && backend == BackendPostgres {
 		return nil, aws.Config{}, errors.New("POSTGRES_CONN_STR environment variable is required")
 	}
 
@@ -53,18 +425,124 @@ func LoadConfig(ctx context.Context, secretsClient SecretsManagerAPI) (*Config,
 		return nil, aws.Config{}, errors.New("ATPROTO_BASE_URL environment variable is required")
 	}
 
+	var secret PostgresSecret
+	var formattedConnStr string
+	if backend == BackendPostgres {
+		if secret, formattedConnStr, err = loadPostgresSecret(ctx, secretName, secretsClient); err != nil {
+			return nil, aws.Config{}, err
+		}
+	}
+
+	tenants, err := loadTenants(tenantsRaw, fallbackTenant)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+
+	webhooks, err := loadWebhookEndpoints(webhooksRaw)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+
+	return &Config{
+		DBClusterARN:        secret.DBClusterARN,
+		SecretARN:           secret.SecretARN,
+		DatabaseName:        secret.Database,
+		PostgresConnStr:     formattedConnStr,
+		AtProtoBaseURL:      baseURL,
+		Tenants:             tenants,
+		QueryTimeout:        queryTimeout,
+		HTTPTimeout:         httpTimeout,
+		EmailTemplateSource: emailTemplateSource,
+		EmailSecretName:     emailSecretName,
+		Retry:               retryPolicy,
+		ProfileDefaults:     profileDefaults,
+		StorageBackend:      backend,
+		AllowUnicodeHandles: allowUnicodeHandles,
+		ProfanityMode:       profanityMode,
+		MinPasswordScore:    minPasswordScore,
+		BreachCheckEnabled:  breachCheckEnabled,
+		BreachCheckTimeout:  breachCheckTimeout,
+
+		AllowCustomDomainHandles: allowCustomDomainHandles,
+		DomainCacheTTL:           domainCacheTTL,
+		UserInviteCodes:          userInviteCodes,
+		DLQMaxReceives:           dlqMaxReceives,
+		EmailMaxAttempts:         emailMaxAttempts,
+		AccountEventsTopicARN:    accountEventsTopicARN,
+		WebhookEndpoints:         webhooks,
+		RiskScoring:              riskScoring,
+		RiskWindow:               riskWindow,
+		RiskReviewScore:          riskReviewScore,
+		RiskCaptchaScore:         riskCaptchaScore,
+		CaptchaSecretName:        captchaSecretName,
+		DomainThrottle:           domainThrottle,
+		RateLimitTable:           rateLimitTable,
+		ConfigSnapshotTable:      configSnapshotTable,
+		RateLimitShards:          rateLimitShards,
+		SignupQuota:              signupQuota,
+		SignupStats:              signupStats,
+		SignupStatsWindow:        signupStatsWindow,
+		EmailRecipientLimit:      emailRecipientLimit,
+		ExecutionBudget:          executionBudget,
+		FaultInjection:           faultInjection,
+		ResponseTokenSecretName:  responseTokenSecretName,
+		ResponseTokenTTL:         responseTokenTTL,
+		TokenKMSKeyID:            tokenKMSKeyID,
+		TokenKMSPreviousKeyIDs:   tokenKMSPreviousKeyIDs,
+		PrivacyExportBucket:      privacyExportBucket,
+		ExportURLTTL:             exportURLTTL,
+		ErasureConfirmTTL:        erasureConfirmTTL,
+		RequireProcessingConsent: requireProcessingConsent,
+		StripeCustomers:          stripeCustomers,
+		StripeSecretName:         stripeSecretName,
+		AnalyticsSecretName:      analyticsSecretName,
+		AnalyticsEndpoint:        analyticsEndpoint,
+		CRMProvider:              crmProvider,
+		CRMSecretName:            crmSecretName,
+		CRMSyncDisabled:          crmSyncDisabled,
+		HandleClaims:             handleClaims,
+		HandleClaimTTL:           handleClaimTTL,
+		HandleQuarantine:         handleQuarantine,
+		OrganizationAccounts:     organizationAccounts,
+		ActivationEmailTemplate:  activationEmailTemplate,
+		GoogleClientIDs:          googleClientIDs,
+		AppleClientIDs:           appleClientIDs,
+		SocialCredential:         socialCredential,
+		PhoneVerification:        phoneVerification,
+		PhoneCodeTTL:             phoneCodeTTL,
+		PhoneMaxAccounts:         phoneMaxAccounts,
+		PhoneSendsPerDay:         phoneSendsPerDay,
+		SMSSenderID:              smsSenderID,
+		PhoneClearsRiskReview:    phoneClearsRiskReview,
+		BotAccounts:              botAccounts,
+		BotSignupsPerDay:         botSignupsPerDay,
+		BotEmailDomain:           botEmailDomain,
+		AvatarBucket:             avatarBucket,
+		AvatarUploadTTL:          avatarUploadTTL,
+		AvatarMaxBytes:           avatarMaxBytes,
+		OnboardingSeeding:        onboardingSeeding,
+		StarterFollows:           starterFollows,
+		ShareFrameProfileRecord:  shareFrameProfileRecord,
+		BootstrapAccounts:        bootstrapAccounts,
+		Rollout:                  rollout,
+	}, awsCfg, nil
+}
+
+// loadPostgresSecret reads the PostgreSQL connection details from the
+// secret secretName and returns them with the connection string they make.
+func loadPostgresSecret(ctx context.Context, secretName string, secretsClient SecretsManagerAPI) (PostgresSecret, string, error) {
 	secretValue, err := RetrieveSecret(ctx, secretName, secretsClient)
 	if err != nil {
-		return nil, aws.Config{}, fmt.Errorf("failed to retrieve PostgreSQL secret: %w", err)
+		return PostgresSecret{}, "", fmt.Errorf("failed to retrieve PostgreSQL secret: %w", err)
 	}
 
 	var secret PostgresSecret
 	if err := json.Unmarshal([]byte(secretValue), &secret); err != nil {
-		return nil, aws.Config{}, fmt.Errorf("failed to parse PostgreSQL secret JSON: %w", err) // **Fix: Proper error**
+		return PostgresSecret{}, "", fmt.Errorf("failed to parse PostgreSQL secret JSON: %w", err)
 	}
 
 	if secret.Database == "" || secret.Host == "" || secret.Username == "" || secret.Password == "" {
-		return nil, aws.Config{}, errors.New("parsed PostgreSQL secret is missing required fields")
+		return PostgresSecret{}, "", errors.New("parsed PostgreSQL secret is missing required fields")
 	}
 
 	formattedConnStr := fmt.Sprintf(
@@ -72,21 +550,15 @@ func LoadConfig(ctx context.Context, secretsClient SecretsManagerAPI) (*Config,
 		url.QueryEscape(secret.Username), url.QueryEscape(secret.Password),
 		secret.Host, secret.Port, secret.Database,
 	)
+	logging.RegisterSecrets(secret.Password, url.QueryEscape(secret.Password), formattedConnStr)
 
-	logrus.WithFields(logrus.Fields{
+	logging.FromContext(ctx).WithFields(logging.Fields{
 		"host":         secret.Host,
 		"database":     secret.Database,
 		"dbClusterArn": secret.DBClusterARN,
 		"secretArn":    secret.SecretARN,
 	}).Info("Successfully loaded PostgreSQL connection details")
-
-	return &Config{
-		DBClusterARN:    secret.DBClusterARN,
-		SecretARN:       secret.SecretARN,
-		DatabaseName:    secret.Database,
-		PostgresConnStr: formattedConnStr,
-		AtProtoBaseURL:  baseURL,
-	}, awsCfg, nil
+	return secret, formattedConnStr, nil
 }
 
 func RetrieveSecret(ctx context.Context, secretName string, svc SecretsManagerAPI) (string, error) {
@@ -95,8 +567,12 @@ func RetrieveSecret(ctx context.Context, secretName string, svc SecretsManagerAP
 		VersionStage: aws.String("AWSCURRENT"),
 	}
 
+	ctx, seg := tracing.Begin(ctx, "SecretsManager", tracing.NamespaceAWS)
+	seg.SetAWS("GetSecretValue")
 	result, err := svc.GetSecretValue(ctx, input)
+	seg.Close(err)
 	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencySecretsManager, err)
 		return "", fmt.Errorf("failed to retrieve secret: %w", err)
 	}
 
@@ -104,5 +580,6 @@ func RetrieveSecret(ctx context.Context, secretName string, svc SecretsManagerAP
 		return "", fmt.Errorf("secret string is nil")
 	}
 
+	logging.RegisterSecrets(*result.SecretString)
 	return *result.SecretString, nil
 }
@@ -6,7 +6,11 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/ShareFrame/user-management/internal/budget"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/retry"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/stretchr/testify/assert"
@@ -85,6 +89,22 @@ func TestLoadConfig(t *testing.T) {
 			mockSecretErr:  nil,
 			expectedErrMsg: "parsed PostgreSQL secret is missing required fields",
 		},
+		{
+			name: "Memory Backend Needs No Secret",
+			envVars: map[string]string{
+				"STORAGE_BACKEND":  "memory",
+				"ATPROTO_BASE_URL": "https://example.com",
+			},
+		},
+		{
+			name: "Unsupported Backend",
+			envVars: map[string]string{
+				"STORAGE_BACKEND":   "dynamodb",
+				"POSTGRES_CONN_STR": "test-secret",
+				"ATPROTO_BASE_URL":  "https://example.com",
+			},
+			expectedErrMsg: "unsupported storage backend: dynamodb",
+		},
 	}
 
 	for _, test := range tests {
@@ -95,8 +115,10 @@ func TestLoadConfig(t *testing.T) {
 			}
 
 			mockSecretsClient.ExpectedCalls = nil
-			mockSecretsClient.On("GetSecretValue", mock.Anything, mock.Anything).
-				Return(test.mockSecret, test.mockSecretErr)
+			if test.mockSecret != nil {
+				mockSecretsClient.On("GetSecretValue", mock.Anything, mock.Anything).
+					Return(test.mockSecret, test.mockSecretErr)
+			}
 
 			_, _, err := LoadConfig(ctx, mockSecretsClient)
 
@@ -171,3 +193,556 @@ func TestRetrieveSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadTenants(t *testing.T) {
+	fallback := Tenant{
+		ID:              DefaultTenantID,
+		PDSBaseURL:      "https://pds.example.com",
+		HandleSuffix:    DefaultHandleSuffix,
+		AdminSecretName: "admin-secret",
+		UtilSecretName:  "util-secret",
+	}
+
+	tests := []struct {
+		name           string
+		raw            string
+		expectedIDs    []string
+		expectedErrMsg string
+	}{
+		{
+			name:        "Default Tenant Only",
+			raw:         "",
+			expectedIDs: []string{DefaultTenantID},
+		},
+		{
+			name:        "Additional Tenant",
+			raw:         `[{"id":"acme","pdsBaseUrl":"https://pds.acme.com","handleSuffix":"acme.social","tablePrefix":"acme_"}]`,
+			expectedIDs: []string{DefaultTenantID, "acme"},
+		},
+		{
+			name:           "Invalid JSON",
+			raw:            `[{"id":`,
+			expectedErrMsg: "failed to parse TENANTS_CONFIG",
+		},
+		{
+			name:           "Missing Required Fields",
+			raw:            `[{"id":"acme"}]`,
+			expectedErrMsg: "tenant entries require id, pdsBaseUrl and handleSuffix",
+		},
+		{
+			name:           "Table Prefix Injection",
+			raw:            `[{"id":"acme","pdsBaseUrl":"https://pds.acme.com","handleSuffix":"acme.social","tablePrefix":"acme; DROP TABLE users; --"}]`,
+			expectedErrMsg: "tenant acme: tablePrefix",
+		},
+		{
+			name:           "Table Prefix Uppercase",
+			raw:            `[{"id":"acme","pdsBaseUrl":"https://pds.acme.com","handleSuffix":"acme.social","tablePrefix":"Acme_"}]`,
+			expectedErrMsg: "may only contain lowercase letters, digits and underscores",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tenants, err := loadTenants(test.raw, fallback)
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, tenants, len(test.expectedIDs))
+			for _, id := range test.expectedIDs {
+				assert.Contains(t, tenants, id)
+			}
+		})
+	}
+}
+
+func TestLoadWebhookEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            string
+		expectedNames  []string
+		expectedErrMsg string
+	}{
+		{
+			name: "No Endpoints",
+			raw:  "",
+		},
+		{
+			name:          "Valid Endpoints",
+			raw:           `[{"name":"analytics","url":"https://hooks.example.com/shareframe","secretName":"webhook-analytics","events":["account.created"]},{"name":"crm","url":"https://crm.example.com/hook","secretName":"webhook-crm"}]`,
+			expectedNames: []string{"analytics", "crm"},
+		},
+		{
+			name:           "Invalid JSON",
+			raw:            `[{"name":`,
+			expectedErrMsg: "failed to parse WEBHOOK_ENDPOINTS",
+		},
+		{
+			name:           "Missing Secret",
+			raw:            `[{"name":"analytics","url":"https://hooks.example.com"}]`,
+			expectedErrMsg: "webhook endpoints require name, url and secretName",
+		},
+		{
+			name:           "Plain HTTP",
+			raw:            `[{"name":"analytics","url":"http://hooks.example.com","secretName":"s"}]`,
+			expectedErrMsg: "webhook endpoint analytics must use an https URL",
+		},
+		{
+			name:           "Duplicate Name",
+			raw:            `[{"name":"a","url":"https://a.example.com","secretName":"s"},{"name":"a","url":"https://b.example.com","secretName":"s"}]`,
+			expectedErrMsg: "duplicate webhook endpoint: a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoints, err := loadWebhookEndpoints(test.raw)
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			var names []string
+			for _, e := range endpoints {
+				names = append(names, e.Name)
+			}
+			assert.Equal(t, test.expectedNames, names)
+		})
+	}
+}
+
+func TestResolveTenant(t *testing.T) {
+	tenants, err := loadTenants(
+		`[{"id":"acme","pdsBaseUrl":"https://pds.acme.com","handleSuffix":"acme.social","tablePrefix":"acme_"}]`,
+		Tenant{ID: DefaultTenantID, PDSBaseURL: "https://pds.example.com", HandleSuffix: DefaultHandleSuffix, AdminSecretName: "admin-secret"},
+	)
+	assert.NoError(t, err)
+	cfg := &Config{Tenants: tenants}
+
+	tenant, err := cfg.ResolveTenant("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTenantID, tenant.ID)
+
+	tenant, err = cfg.ResolveTenant("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, ".acme.social", tenant.HandleSuffix)
+	assert.Equal(t, "acme_", tenant.TablePrefix)
+	assert.Equal(t, "admin-secret", tenant.AdminSecretName)
+
+	_, err = cfg.ResolveTenant("unknown")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown tenant")
+}
+
+func TestEnvResolverDuration(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		value          string
+		expected       time.Duration
+		expectedErrMsg string
+	}{
+		{"Unset Uses Fallback", "", 3 * time.Second, ""},
+		{"Valid Override", "750ms", 750 * time.Millisecond, ""},
+		{"Invalid Duration", "soon", 3 * time.Second, "invalid duration for QUERY_TIMEOUT"},
+		{"Non-Positive Duration", "0s", 3 * time.Second, "invalid duration for QUERY_TIMEOUT"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.value != "" {
+				os.Setenv("QUERY_TIMEOUT", test.value)
+			}
+
+			env := newEnvResolver(ctx, new(mockKMSClient))
+			result := env.duration("QUERY_TIMEOUT", DefaultQueryTimeout)
+
+			assert.Equal(t, test.expected, result)
+			if test.expectedErrMsg != "" {
+				assert.Error(t, env.err)
+				assert.Contains(t, env.err.Error(), test.expectedErrMsg)
+			} else {
+				assert.NoError(t, env.err)
+			}
+		})
+	}
+}
+
+func TestEnvResolverBoolean(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		value          string
+		expected       bool
+		expectedErrMsg string
+	}{
+		{"Unset Uses Fallback", "", false, ""},
+		{"Enabled", "true", true, ""},
+		{"Numeric Form", "1", true, ""},
+		{"Invalid Boolean", "sometimes", false, "invalid boolean for ALLOW_UNICODE_HANDLES"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.value != "" {
+				os.Setenv("ALLOW_UNICODE_HANDLES", test.value)
+			}
+
+			env := newEnvResolver(ctx, new(mockKMSClient))
+			result := env.boolean("ALLOW_UNICODE_HANDLES", false)
+
+			assert.Equal(t, test.expected, result)
+			if test.expectedErrMsg != "" {
+				assert.Error(t, env.err)
+				assert.Contains(t, env.err.Error(), test.expectedErrMsg)
+			} else {
+				assert.NoError(t, env.err)
+			}
+		})
+	}
+}
+
+func TestLoadRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		envVars         map[string]string
+		expectedClasses []retry.ErrorClass
+		expectedErrMsg  string
+	}{
+		{
+			name:            "Defaults",
+			envVars:         map[string]string{},
+			expectedClasses: retry.DefaultClasses,
+		},
+		{
+			name:            "Custom Classes",
+			envVars:         map[string]string{"RETRY_ERROR_CLASSES": "throttled, server"},
+			expectedClasses: []retry.ErrorClass{retry.ClassThrottled, retry.ClassServer},
+		},
+		{
+			name:           "Unknown Class",
+			envVars:        map[string]string{"RETRY_ERROR_CLASSES": "cosmic-rays"},
+			expectedErrMsg: "unknown retry error class: cosmic-rays",
+		},
+		{
+			name:           "Max Below Base",
+			envVars:        map[string]string{"RETRY_BASE_DELAY": "5s", "RETRY_MAX_DELAY": "1s"},
+			expectedErrMsg: "RETRY_MAX_DELAY must not be smaller than RETRY_BASE_DELAY",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range test.envVars {
+				os.Setenv(key, value)
+			}
+
+			policy, err := loadRetryPolicy(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, DefaultRetryMaxAttempts, policy.MaxAttempts)
+			assert.Equal(t, test.expectedClasses, policy.RetryableClasses)
+		})
+	}
+}
+
+func TestLoadDomainThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		limit          string
+		overrides      string
+		expected       DomainThrottle
+		enabled        bool
+		expectedErrMsg string
+	}{
+		{name: "Unset", expected: DomainThrottle{}},
+		{name: "Default Limit", limit: "50", expected: DomainThrottle{Limit: 50}, enabled: true},
+		{
+			name:      "Overrides",
+			limit:     "50",
+			overrides: "Example.edu=500, bulk.example=0",
+			expected:  DomainThrottle{Limit: 50, Overrides: map[string]int{"example.edu": 500, "bulk.example": 0}},
+			enabled:   true,
+		},
+		{
+			name:      "Only Exemptions",
+			overrides: "example.edu=0",
+			expected:  DomainThrottle{Overrides: map[string]int{"example.edu": 0}},
+		},
+		{name: "Missing Limit", overrides: "example.edu", expectedErrMsg: `invalid DOMAIN_SIGNUP_LIMIT_OVERRIDES entry: "example.edu"`},
+		{name: "Negative Limit", overrides: "example.edu=-1", expectedErrMsg: `invalid DOMAIN_SIGNUP_LIMIT_OVERRIDES entry: "example.edu=-1"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.limit != "" {
+				os.Setenv("DOMAIN_SIGNUP_LIMIT", test.limit)
+			}
+			if test.overrides != "" {
+				os.Setenv("DOMAIN_SIGNUP_LIMIT_OVERRIDES", test.overrides)
+			}
+
+			throttle, err := loadDomainThrottle(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.EqualError(t, err, test.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, throttle)
+			assert.Equal(t, test.enabled, throttle.Enabled())
+		})
+	}
+}
+
+func TestLoadSignupQuota(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		env            map[string]string
+		expected       SignupQuota
+		enabled        bool
+		expectedErrMsg string
+	}{
+		{name: "Unset", expected: SignupQuota{}},
+		{
+			name:     "Daily Quotas",
+			env:      map[string]string{"SIGNUP_DAILY_QUOTA": "5000", "DOMAIN_SIGNUP_DAILY_QUOTA": "200"},
+			expected: SignupQuota{Daily: 5000, DomainDaily: 200},
+			enabled:  true,
+		},
+		{
+			name:     "Overrides",
+			env:      map[string]string{"DOMAIN_SIGNUP_DAILY_QUOTA": "200", "DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES": "Example.edu=1000, partner.example=0"},
+			expected: SignupQuota{DomainDaily: 200, DomainOverrides: map[string]int{"example.edu": 1000, "partner.example": 0}},
+			enabled:  true,
+		},
+		{
+			name:     "Only Exemptions",
+			env:      map[string]string{"DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES": "example.edu=0"},
+			expected: SignupQuota{DomainOverrides: map[string]int{"example.edu": 0}},
+		},
+		{
+			name:           "Invalid Override",
+			env:            map[string]string{"DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES": "example.edu"},
+			expectedErrMsg: `invalid DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES entry: "example.edu"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range test.env {
+				os.Setenv(key, value)
+			}
+
+			quota, err := loadSignupQuota(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.EqualError(t, err, test.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, quota)
+			assert.Equal(t, test.enabled, quota.Enabled())
+		})
+	}
+}
+
+func TestLoadRollout(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		percent        string
+		domains        string
+		expected       Rollout
+		expectedErrMsg string
+	}{
+		{name: "Unset", expected: Rollout{}},
+		{name: "Percent", percent: "25", expected: Rollout{Enabled: true, Percent: 25}},
+		{name: "Percent Sign", percent: "25%", expected: Rollout{Enabled: true, Percent: 25}},
+		{name: "Closed", percent: "0", expected: Rollout{Enabled: true}},
+		{
+			name:     "Only Domains",
+			domains:  "Partner.example, @example.edu",
+			expected: Rollout{Enabled: true, Domains: []string{"partner.example", "example.edu"}},
+		},
+		{name: "Over 100", percent: "101", expectedErrMsg: `SIGNUP_ROLLOUT_PERCENT must be between 0 and 100, got "101"`},
+		{name: "Not A Number", percent: "half", expectedErrMsg: `SIGNUP_ROLLOUT_PERCENT must be between 0 and 100, got "half"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.percent != "" {
+				os.Setenv("SIGNUP_ROLLOUT_PERCENT", test.percent)
+			}
+			if test.domains != "" {
+				os.Setenv("SIGNUP_ROLLOUT_DOMAINS", test.domains)
+			}
+
+			rollout, err := loadRollout(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.EqualError(t, err, test.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rollout)
+		})
+	}
+}
+
+func TestRolloutAdmits(t *testing.T) {
+	const email = "alice@example.com"
+	bucket := RolloutBucket(email)
+
+	tests := []struct {
+		name     string
+		rollout  Rollout
+		email    string
+		expected bool
+	}{
+		{name: "Gate Off", rollout: Rollout{}, email: email, expected: true},
+		{name: "Bucket Below Percent", rollout: Rollout{Enabled: true, Percent: bucket + 1}, email: email, expected: true},
+		{name: "Bucket At Percent", rollout: Rollout{Enabled: true, Percent: bucket}, email: email},
+		{name: "Bucket Ignores Case", rollout: Rollout{Enabled: true, Percent: bucket + 1}, email: " Alice@Example.com", expected: true},
+		{name: "Everyone", rollout: Rollout{Enabled: true, Percent: 100}, email: email, expected: true},
+		{name: "Allowed Domain", rollout: Rollout{Enabled: true, Domains: []string{"example.com"}}, email: "Bob@EXAMPLE.com", expected: true},
+		{name: "Other Domain", rollout: Rollout{Enabled: true, Domains: []string{"example.edu"}}, email: email},
+		{name: "Subdomain Is Not Allowed", rollout: Rollout{Enabled: true, Domains: []string{"example.com"}}, email: "bob@mail.example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.rollout.Admits(test.email))
+		})
+	}
+}
+
+func TestRolloutBucketVariants(t *testing.T) {
+	bucket := RolloutBucket("alice@example.com")
+
+	for _, variant := range []string{
+		"Alice@Example.com",
+		"ALICE@EXAMPLE.COM",
+		"alice+beta@example.com",
+		"Alice+Retry2@example.com",
+		" <alice+x@Example.com> ",
+	} {
+		t.Run(variant, func(t *testing.T) {
+			assert.Equal(t, bucket, RolloutBucket(variant))
+		})
+	}
+
+	assert.Equal(t, RolloutBucket("alice.smith@gmail.com"), RolloutBucket("AliceSmith+beta@googlemail.com"))
+}
+
+func TestLoadExecutionBudget(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		value          string
+		expected       map[budget.Step]int
+		expectedErrMsg string
+	}{
+		{name: "Unset", expected: DefaultExecutionBudget},
+		{
+			name:     "Override",
+			value:    "PDS=35, db=35",
+			expected: map[budget.Step]int{budget.StepValidation: 15, budget.StepPDS: 35, budget.StepDB: 35, budget.StepEmail: 15},
+		},
+		{name: "Unknown Step", value: "dns=10", expectedErrMsg: `invalid EXECUTION_BUDGET entry: "dns=10"`},
+		{name: "Bad Share", value: "pds=lots", expectedErrMsg: `invalid EXECUTION_BUDGET entry: "pds=lots"`},
+		{name: "Over 100", value: "pds=80", expectedErrMsg: "EXECUTION_BUDGET shares add up to 135%, more than 100%"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.value != "" {
+				os.Setenv("EXECUTION_BUDGET", test.value)
+			}
+
+			shares, err := loadExecutionBudget(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.EqualError(t, err, test.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, shares)
+		})
+	}
+}
+
+func TestLoadFaultInjection(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		environment    string
+		value          string
+		expected       map[faults.Target]int
+		expectedErrMsg string
+	}{
+		{name: "Unset", environment: "production"},
+		{
+			name:        "Staging",
+			environment: "staging",
+			value:       "PDS=10, email=100",
+			expected:    map[faults.Target]int{faults.TargetPDS: 10, faults.TargetEmail: 100},
+		},
+		{name: "Production", environment: "Prod", value: "pds=10", expectedErrMsg: "FAULT_INJECTION requires ENVIRONMENT to name a non-production environment"},
+		{name: "No Environment", value: "pds=10", expectedErrMsg: "FAULT_INJECTION requires ENVIRONMENT to name a non-production environment"},
+		{name: "Unknown Target", environment: "dev", value: "s3=10", expectedErrMsg: `invalid FAULT_INJECTION entry: "s3=10"`},
+		{name: "Over 100", environment: "dev", value: "db=150", expectedErrMsg: `invalid FAULT_INJECTION entry: "db=150"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			if test.environment != "" {
+				os.Setenv("ENVIRONMENT", test.environment)
+			}
+			if test.value != "" {
+				os.Setenv("FAULT_INJECTION", test.value)
+			}
+
+			rates, err := loadFaultInjection(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.EqualError(t, err, test.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rates)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envResolver reads environment variables through ResolveValue and keeps the
+// first error so callers can read several settings before checking.
+type envResolver struct {
+	ctx    context.Context
+	client KMSAPI
+	err    error
+}
+
+func newEnvResolver(ctx context.Context, client KMSAPI) *envResolver {
+	return &envResolver{ctx: ctx, client: client}
+}
+
+// duration parses a Go duration setting such as "3s", returning fallback
+// when the variable is unset.
+func (r *envResolver) duration(key string, fallback time.Duration) time.Duration {
+	raw := r.get(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid duration for %s: %q", key, raw)
+		}
+		return fallback
+	}
+	return value
+}
+
+// integer parses a positive integer setting, returning fallback when the
+// variable is unset.
+func (r *envResolver) integer(key string, fallback int) int {
+	raw := r.get(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid integer for %s: %q", key, raw)
+		}
+		return fallback
+	}
+	return value
+}
+
+// boolean parses a true/false setting, returning fallback when the variable
+// is unset.
+func (r *envResolver) boolean(key string, fallback bool) bool {
+	raw := r.get(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid boolean for %s: %q", key, raw)
+		}
+		return fallback
+	}
+	return value
+}
+
+// list splits a comma-separated setting, returning nil when unset.
+func (r *envResolver) list(key string) []string {
+	raw := r.get(key)
+	if raw == "" {
+		return nil
+	}
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (r *envResolver) get(key string) string {
+	value, err := ResolveValue(r.ctx, os.Getenv(key), r.client)
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/faults"
+)
+
+var productionEnvironments = map[string]bool{"prod": true, "production": true}
+
+// loadFaultInjection reads the comma-separated target=percent pairs in
+// FAULT_INJECTION. Faults are for resilience testing only, so they are
+// refused unless ENVIRONMENT names an environment other than production.
+func loadFaultInjection(env *envResolver) (map[faults.Target]int, error) {
+	pairs := env.list("FAULT_INJECTION")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	environment := strings.ToLower(strings.TrimSpace(env.get("ENVIRONMENT")))
+	if environment == "" || productionEnvironments[environment] {
+		return nil, errors.New("FAULT_INJECTION requires ENVIRONMENT to name a non-production environment")
+	}
+
+	known := map[faults.Target]bool{}
+	for _, target := range faults.Targets {
+		known[target] = true
+	}
+
+	rates := map[faults.Target]int{}
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		target := faults.Target(strings.ToLower(strings.TrimSpace(name)))
+		rate, err := strconv.Atoi(strings.TrimSpace(raw))
+		if !ok || !known[target] || err != nil || rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("invalid FAULT_INJECTION entry: %q", pair)
+		}
+		rates[target] = rate
+	}
+	return rates, nil
+}
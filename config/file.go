@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadEnvFile reads a flat JSON object of environment settings, as used for
+// local runs outside Lambda, and exports any keys not already set so real
+// environment variables still win.
+func LoadEnvFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]string
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for key, value := range settings {
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s from config file: %w", key, err)
+		}
+	}
+
+	return nil
+}
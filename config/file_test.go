@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ATPROTO_BASE_URL", "https://from-env.example.com")
+
+	path := filepath.Join(t.TempDir(), "local.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"ATPROTO_BASE_URL": "https://from-file.example.com",
+		"POSTGRES_CONN_STR": "local-postgres"
+	}`), 0o600))
+
+	assert.NoError(t, LoadEnvFile(path))
+	assert.Equal(t, "https://from-env.example.com", os.Getenv("ATPROTO_BASE_URL"))
+	assert.Equal(t, "local-postgres", os.Getenv("POSTGRES_CONN_STR"))
+}
+
+func TestLoadEnvFileErrors(t *testing.T) {
+	assert.ErrorContains(t, LoadEnvFile("/does/not/exist.json"), "failed to read config file")
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"KEY": 1}`), 0o600))
+	assert.ErrorContains(t, LoadEnvFile(path), "failed to parse config file")
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+const KMSValuePrefix = "kms:"
+
+type KMSAPI interface {
+	Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+var (
+	// decryptedValues caches plaintexts by ciphertext so warm invocations
+	// don't pay a KMS round trip per setting.
+	decryptedValues sync.Map
+
+	newKMSClient = func(cfg aws.Config) KMSAPI {
+		return kms.NewFromConfig(cfg)
+	}
+)
+
+// ResolveValue returns value unchanged unless it is of the form
+// kms:<base64 ciphertext>, in which case the decrypted plaintext is returned.
+func ResolveValue(ctx context.Context, value string, client KMSAPI) (string, error) {
+	if !strings.HasPrefix(value, KMSValuePrefix) {
+		return value, nil
+	}
+
+	ciphertext := strings.TrimPrefix(value, KMSValuePrefix)
+	if cached, ok := decryptedValues.Load(ciphertext); ok {
+		return cached.(string), nil
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid KMS ciphertext encoding: %w", err)
+	}
+
+	result, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: blob})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt KMS value: %w", err)
+	}
+
+	plaintext := string(result.Plaintext)
+	decryptedValues.Store(ciphertext, plaintext)
+	return plaintext, nil
+}
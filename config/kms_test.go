@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockKMSClient struct {
+	mock.Mock
+}
+
+func (m *mockKMSClient) Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) != nil {
+		return args.Get(0).(*kms.DecryptOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestResolveValue(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		value          string
+		mockOutput     *kms.DecryptOutput
+		mockErr        error
+		expectDecrypt  bool
+		expectedResult string
+		expectedErrMsg string
+	}{
+		{
+			name:           "Plain Value Passes Through",
+			value:          "https://example.com",
+			expectedResult: "https://example.com",
+		},
+		{
+			name:           "Encrypted Value Is Decrypted",
+			value:          KMSValuePrefix + base64.StdEncoding.EncodeToString([]byte("ciphertext-1")),
+			mockOutput:     &kms.DecryptOutput{Plaintext: []byte("hmac-secret")},
+			expectDecrypt:  true,
+			expectedResult: "hmac-secret",
+		},
+		{
+			name:           "Invalid Base64",
+			value:          KMSValuePrefix + "not base64!",
+			expectedErrMsg: "invalid KMS ciphertext encoding",
+		},
+		{
+			name:           "KMS Error",
+			value:          KMSValuePrefix + base64.StdEncoding.EncodeToString([]byte("ciphertext-2")),
+			mockErr:        errors.New("access denied"),
+			expectDecrypt:  true,
+			expectedErrMsg: "failed to decrypt KMS value: access denied",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockKMS := new(mockKMSClient)
+			if test.expectDecrypt {
+				mockKMS.On("Decrypt", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockErr)
+			}
+
+			result, err := ResolveValue(ctx, test.value, mockKMS)
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedResult, result)
+			}
+
+			mockKMS.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResolveValueCachesPlaintext(t *testing.T) {
+	ctx := context.Background()
+	value := KMSValuePrefix + base64.StdEncoding.EncodeToString([]byte("ciphertext-cached"))
+
+	mockKMS := new(mockKMSClient)
+	mockKMS.On("Decrypt", mock.Anything, mock.Anything).
+		Return(&kms.DecryptOutput{Plaintext: []byte("cached-secret")}, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		result, err := ResolveValue(ctx, value, mockKMS)
+		assert.NoError(t, err)
+		assert.Equal(t, "cached-secret", result)
+	}
+
+	mockKMS.AssertNumberOfCalls(t, "Decrypt", 1)
+}
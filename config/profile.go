@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/ShareFrame/user-management/internal/models"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ProfileDefaults are applied to every new account, whichever storage
+// backend ends up persisting it.
+type ProfileDefaults struct {
+	Status         string
+	Role           string
+	Picture        string
+	Banner         string
+	Theme          string
+	PrimaryColor   string
+	SecondaryColor string
+	// RoleAssignments give accounts a role other than Role by their email
+	// address.
+	RoleAssignments RoleAssignments
+	// Preferences start from models.DefaultPreferences, with any settings
+	// in DEFAULT_PREFERENCES applied over them.
+	Preferences models.Preferences
+}
+
+func loadProfileDefaults(env *envResolver) (ProfileDefaults, error) {
+	defaults := ProfileDefaults{
+		Status:         env.get("DEFAULT_STATUS"),
+		Role:           env.get("DEFAULT_ROLE"),
+		Picture:        env.get("DEFAULT_PROFILE_PICTURE"),
+		Banner:         env.get("DEFAULT_PROFILE_BANNER"),
+		Theme:          env.get("DEFAULT_THEME"),
+		PrimaryColor:   env.get("DEFAULT_PRIMARY_COLOR"),
+		SecondaryColor: env.get("DEFAULT_SECONDARY_COLOR"),
+	}
+
+	if defaults.Status == "" {
+		defaults.Status = models.StatusPending
+	}
+	if defaults.Role == "" {
+		defaults.Role = models.RoleUser
+	}
+	if defaults.Theme == "" {
+		defaults.Theme = "{}"
+	}
+	if defaults.PrimaryColor == "" {
+		defaults.PrimaryColor = "#FFFFFF"
+	}
+	if defaults.SecondaryColor == "" {
+		defaults.SecondaryColor = "#000000"
+	}
+	roles, err := parseRoleAssignments(env.get("ROLE_ASSIGNMENTS"))
+	if err != nil {
+		return ProfileDefaults{}, err
+	}
+	defaults.RoleAssignments = roles
+	defaults.Preferences = models.DefaultPreferences()
+	if raw := env.get("DEFAULT_PREFERENCES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &defaults.Preferences); err != nil {
+			return ProfileDefaults{}, fmt.Errorf("DEFAULT_PREFERENCES must be a JSON preferences object: %w", err)
+		}
+	}
+
+	// Accounts start pending until their email address is verified;
+	// deployments that don't verify email can start them active.
+	if defaults.Status != models.StatusPending && defaults.Status != models.StatusActive {
+		return ProfileDefaults{}, fmt.Errorf("DEFAULT_STATUS must be %s or %s", models.StatusPending, models.StatusActive)
+	}
+	if !json.Valid([]byte(defaults.Theme)) {
+		return ProfileDefaults{}, fmt.Errorf("DEFAULT_THEME must be valid JSON")
+	}
+	if !hexColorRegex.MatchString(defaults.PrimaryColor) || !hexColorRegex.MatchString(defaults.SecondaryColor) {
+		return ProfileDefaults{}, fmt.Errorf("default profile colors must be #RRGGBB hex values")
+	}
+	if err := validatePreferences(defaults.Preferences); err != nil {
+		return ProfileDefaults{}, fmt.Errorf("DEFAULT_PREFERENCES: %w", err)
+	}
+
+	return defaults, nil
+}
+
+// validatePreferences rejects settings outside the known values.
+func validatePreferences(preferences models.Preferences) error {
+	switch preferences.Privacy.DirectMessages {
+	case models.DirectMessagesEveryone, models.DirectMessagesFollowing, models.DirectMessagesNobody:
+	default:
+		return fmt.Errorf("directMessages must be %s, %s or %s", models.DirectMessagesEveryone, models.DirectMessagesFollowing, models.DirectMessagesNobody)
+	}
+	filters := preferences.ContentFilters
+	for _, filter := range []struct{ name, value string }{
+		{"adult", filters.Adult},
+		{"graphic", filters.Graphic},
+		{"spam", filters.Spam},
+	} {
+		if filter.value != models.FilterShow && filter.value != models.FilterWarn && filter.value != models.FilterHide {
+			return fmt.Errorf("content filter %s must be %s, %s or %s", filter.name, models.FilterShow, models.FilterWarn, models.FilterHide)
+		}
+	}
+	return nil
+}
+
+// NewUserRecord combines the PDS registration result and the validated
+// request with the configured defaults into the record we persist.
+// Marketing notifications are on only when marketing consent was granted.
+func (d ProfileDefaults) NewUserRecord(user models.CreateUserResponse, event models.UserRequest) models.UserRecord {
+	role := d.Role
+	if assigned, ok := d.RoleAssignments.Role(event.Email); ok {
+		role = assigned
+	}
+	displayName := event.DisplayName
+	if displayName == "" {
+		displayName = user.Handle
+	}
+	accountType := event.AccountType
+	if accountType == "" {
+		accountType = models.AccountTypePerson
+	}
+	preferences := d.Preferences
+	marketing, _ := models.FindConsent(event.Consents, models.ConsentMarketing)
+	preferences.Notifications.Marketing = marketing.Granted
+
+	return models.UserRecord{
+		DID:            user.DID,
+		Email:          event.Email,
+		Handle:         user.Handle,
+		DisplayName:    displayName,
+		Status:         d.Status,
+		Verified:       false,
+		Role:           role,
+		ProfilePicture: d.Picture,
+		ProfileBanner:  d.Banner,
+		Theme:          d.Theme,
+		PrimaryColor:   d.PrimaryColor,
+		SecondaryColor: d.SecondaryColor,
+		Locale:         event.Locale,
+		Country:        event.Country,
+		Timezone:       event.Timezone,
+		Bio:            event.Bio,
+		Pronouns:       event.Pronouns,
+		Website:        event.Website,
+		Location:       event.Location,
+		ReferralSource: event.ReferralSource,
+		AccountType:    accountType,
+		Owners:         event.Owners,
+		Preferences:    preferences,
+	}
+}
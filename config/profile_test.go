@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfileDefaults(t *testing.T) {
+	ctx := context.Background()
+	everyoneMayMessage := models.DefaultPreferences()
+	everyoneMayMessage.Privacy.DirectMessages = models.DirectMessagesEveryone
+
+	tests := []struct {
+		name           string
+		envVars        map[string]string
+		expected       ProfileDefaults
+		expectedErrMsg string
+	}{
+		{
+			name:    "Built-in Defaults",
+			envVars: map[string]string{},
+			expected: ProfileDefaults{
+				Status:         "pending",
+				Role:           "user",
+				Theme:          "{}",
+				PrimaryColor:   "#FFFFFF",
+				SecondaryColor: "#000000",
+				Preferences:    models.DefaultPreferences(),
+			},
+		},
+		{
+			name: "Overrides",
+			envVars: map[string]string{
+				"DEFAULT_STATUS":        "active",
+				"DEFAULT_ROLE":          "member",
+				"DEFAULT_THEME":         `{"mode":"dark"}`,
+				"DEFAULT_PRIMARY_COLOR": "#1A2B3C",
+				"DEFAULT_PREFERENCES":   `{"privacy":{"directMessages":"everyone"}}`,
+			},
+			expected: ProfileDefaults{
+				Status:         "active",
+				Role:           "member",
+				Theme:          `{"mode":"dark"}`,
+				PrimaryColor:   "#1A2B3C",
+				SecondaryColor: "#000000",
+				Preferences:    everyoneMayMessage,
+			},
+		},
+		{
+			name:    "Role Assignments",
+			envVars: map[string]string{"ROLE_ASSIGNMENTS": `{"moderator":["@ShareFrame.social"]}`},
+			expected: ProfileDefaults{
+				Status:          "pending",
+				Role:            "user",
+				RoleAssignments: RoleAssignments{"moderator": {"@shareframe.social"}},
+				Theme:           "{}",
+				PrimaryColor:    "#FFFFFF",
+				SecondaryColor:  "#000000",
+				Preferences:     models.DefaultPreferences(),
+			},
+		},
+		{
+			name:           "Invalid Role Assignments",
+			envVars:        map[string]string{"ROLE_ASSIGNMENTS": `{"admin":"ops@shareframe.social"}`},
+			expectedErrMsg: "ROLE_ASSIGNMENTS must be a JSON object",
+		},
+		{
+			name:           "Invalid Status",
+			envVars:        map[string]string{"DEFAULT_STATUS": "verified"},
+			expectedErrMsg: "DEFAULT_STATUS must be pending or active",
+		},
+		{
+			name:           "Invalid Theme",
+			envVars:        map[string]string{"DEFAULT_THEME": "{dark"},
+			expectedErrMsg: "DEFAULT_THEME must be valid JSON",
+		},
+		{
+			name:           "Invalid Color",
+			envVars:        map[string]string{"DEFAULT_SECONDARY_COLOR": "black"},
+			expectedErrMsg: "default profile colors must be #RRGGBB hex values",
+		},
+		{
+			name:           "Invalid Preferences JSON",
+			envVars:        map[string]string{"DEFAULT_PREFERENCES": "{"},
+			expectedErrMsg: "DEFAULT_PREFERENCES must be a JSON preferences object",
+		},
+		{
+			name:           "Invalid Content Filter",
+			envVars:        map[string]string{"DEFAULT_PREFERENCES": `{"contentFilters":{"graphic":"blur"}}`},
+			expectedErrMsg: "DEFAULT_PREFERENCES: content filter graphic must be show, warn or hide",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range test.envVars {
+				os.Setenv(key, value)
+			}
+
+			defaults, err := loadProfileDefaults(newEnvResolver(ctx, new(mockKMSClient)))
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, defaults)
+			}
+		})
+	}
+}
+
+func TestNewUserRecord(t *testing.T) {
+	defaults := ProfileDefaults{Status: "pending", Role: "user", Theme: "{}", PrimaryColor: "#FFFFFF", SecondaryColor: "#000000", Preferences: models.DefaultPreferences()}
+
+	record := defaults.NewUserRecord(
+		models.CreateUserResponse{DID: "did:plc:123", Handle: "alice.shareframe.social"},
+		models.UserRequest{Email: "alice@example.com"},
+	)
+
+	assert.Equal(t, "did:plc:123", record.DID)
+	assert.Equal(t, "alice@example.com", record.Email)
+	assert.Equal(t, "alice.shareframe.social", record.DisplayName)
+	assert.Equal(t, "pending", record.Status)
+	assert.False(t, record.Verified)
+	assert.Equal(t, "#000000", record.SecondaryColor)
+	assert.Equal(t, models.AccountTypePerson, record.AccountType)
+	assert.Equal(t, models.DefaultPreferences(), record.Preferences)
+
+	record = defaults.NewUserRecord(
+		models.CreateUserResponse{DID: "did:plc:123", Handle: "alice.shareframe.social"},
+		models.UserRequest{Email: "alice@example.com", DisplayName: "Alice", Locale: "pt-BR", Country: "BR", Timezone: "America/Sao_Paulo", ReferralSource: "friend",
+			Bio: "Photographer", Pronouns: "she/her", Website: "https://alice.example", Location: "Lisbon",
+			Consents: []models.Consent{{Purpose: models.ConsentMarketing, Granted: true, TextVersion: "marketing-2026-01"}}},
+	)
+	assert.Equal(t, "Alice", record.DisplayName)
+	assert.Equal(t, "pt-BR", record.Locale)
+	assert.Equal(t, "BR", record.Country)
+	assert.Equal(t, "America/Sao_Paulo", record.Timezone)
+	assert.Equal(t, "friend", record.ReferralSource)
+	assert.Equal(t, "Photographer", record.Bio)
+	assert.Equal(t, "she/her", record.Pronouns)
+	assert.Equal(t, "https://alice.example", record.Website)
+	assert.Equal(t, "Lisbon", record.Location)
+	assert.True(t, record.Preferences.Notifications.Marketing)
+	assert.True(t, record.Preferences.Notifications.Mentions)
+
+	record = defaults.NewUserRecord(
+		models.CreateUserResponse{DID: "did:plc:456", Handle: "acme.shareframe.social"},
+		models.UserRequest{Email: "team@acme.example", DisplayName: "Acme", AccountType: models.AccountTypeOrganization, Owners: []string{"did:plc:123"}},
+	)
+	assert.Equal(t, models.AccountTypeOrganization, record.AccountType)
+	assert.Equal(t, []string{"did:plc:123"}, record.Owners)
+	assert.Equal(t, models.RoleUser, record.Role)
+
+	defaults.RoleAssignments = RoleAssignments{models.RoleAdmin: {"ops@shareframe.social"}}
+	record = defaults.NewUserRecord(
+		models.CreateUserResponse{DID: "did:plc:789", Handle: "ops.shareframe.social"},
+		models.UserRequest{Email: "Ops@ShareFrame.social"},
+	)
+	assert.Equal(t, models.RoleAdmin, record.Role)
+}
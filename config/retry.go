@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 100 * time.Millisecond
+	DefaultRetryMaxDelay    = 2 * time.Second
+)
+
+var knownErrorClasses = map[retry.ErrorClass]bool{
+	retry.ClassNetwork:   true,
+	retry.ClassTimeout:   true,
+	retry.ClassThrottled: true,
+	retry.ClassServer:    true,
+	retry.ClassOther:     true,
+}
+
+// loadRetryPolicy reads the shared retry policy used by the PDS client, the
+// email sender and the storage layer.
+func loadRetryPolicy(env *envResolver) (retry.Policy, error) {
+	policy := retry.Policy{
+		MaxAttempts:      env.integer("RETRY_MAX_ATTEMPTS", DefaultRetryMaxAttempts),
+		BaseDelay:        env.duration("RETRY_BASE_DELAY", DefaultRetryBaseDelay),
+		MaxDelay:         env.duration("RETRY_MAX_DELAY", DefaultRetryMaxDelay),
+		RetryableClasses: retry.DefaultClasses,
+	}
+
+	if classes := env.list("RETRY_ERROR_CLASSES"); classes != nil {
+		policy.RetryableClasses = nil
+		for _, class := range classes {
+			if !knownErrorClasses[retry.ErrorClass(class)] {
+				return retry.Policy{}, fmt.Errorf("unknown retry error class: %s", class)
+			}
+			policy.RetryableClasses = append(policy.RetryableClasses, retry.ErrorClass(class))
+		}
+	}
+
+	if policy.MaxDelay < policy.BaseDelay {
+		return retry.Policy{}, fmt.Errorf("RETRY_MAX_DELAY must not be smaller than RETRY_BASE_DELAY")
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/models"
+)
+
+// RoleAssignments give new accounts a role other than the default by
+// email address. Each role maps to the addresses, or "@domain" entries
+// for whole domains, whose accounts are created with it. The role is
+// given whether or not the address has been verified yet, so consumers
+// should only honor it for verified accounts.
+type RoleAssignments map[string][]string
+
+// Role returns the role assigned to email. An exact address match comes
+// before its domain.
+func (r RoleAssignments) Role(email string) (string, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", false
+	}
+	domainRole := ""
+	for role, entries := range r {
+		for _, entry := range entries {
+			switch entry {
+			case email:
+				return role, true
+			case email[at:]:
+				domainRole = role
+			}
+		}
+	}
+	return domainRole, domainRole != ""
+}
+
+// parseRoleAssignments reads ROLE_ASSIGNMENTS, a JSON object such as
+// {"admin":["ops@shareframe.social"],"moderator":["@shareframe.social"]}.
+// Entries are lowercased and may only be listed under one role.
+func parseRoleAssignments(raw string) (RoleAssignments, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var assignments RoleAssignments
+	if err := json.Unmarshal([]byte(raw), &assignments); err != nil {
+		return nil, fmt.Errorf("ROLE_ASSIGNMENTS must be a JSON object of roles to email addresses: %w", err)
+	}
+
+	assigned := map[string]string{}
+	for role, entries := range assignments {
+		if role == "" || role == models.RoleBot {
+			return nil, fmt.Errorf("ROLE_ASSIGNMENTS: role %q can't be assigned by email", role)
+		}
+		for i, entry := range entries {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if at := strings.LastIndex(entry, "@"); at < 0 || at == len(entry)-1 {
+				return nil, fmt.Errorf("ROLE_ASSIGNMENTS: %q is not an email address or @domain", entries[i])
+			}
+			if other, ok := assigned[entry]; ok && other != role {
+				return nil, fmt.Errorf("ROLE_ASSIGNMENTS: %s is assigned both %s and %s", entry, other, role)
+			}
+			assigned[entry] = role
+			entries[i] = entry
+		}
+	}
+	return assignments, nil
+}
+
+// BootstrapAccount is an operator account provisioned by the admin
+// bootstrap command. Its email address must be assigned a role.
+type BootstrapAccount struct {
+	Handle string `json:"handle"`
+	Email  string `json:"email"`
+}
+
+// parseBootstrapAccounts reads BOOTSTRAP_ACCOUNTS, a JSON list such as
+// [{"handle":"ops","email":"ops@shareframe.social"}].
+func parseBootstrapAccounts(raw string, roles RoleAssignments) ([]BootstrapAccount, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var accounts []BootstrapAccount
+	if err := json.Unmarshal([]byte(raw), &accounts); err != nil {
+		return nil, fmt.Errorf("BOOTSTRAP_ACCOUNTS must be a JSON list of accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if account.Handle == "" || account.Email == "" {
+			return nil, fmt.Errorf("BOOTSTRAP_ACCOUNTS: every account needs a handle and an email")
+		}
+		if _, ok := roles.Role(account.Email); !ok {
+			return nil, fmt.Errorf("BOOTSTRAP_ACCOUNTS: %s has no role in ROLE_ASSIGNMENTS", account.Email)
+		}
+	}
+	return accounts, nil
+}
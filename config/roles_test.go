@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAssignments(t *testing.T) {
+	roles, err := parseRoleAssignments(`{"admin":["Ops@ShareFrame.social"],"moderator":["@shareframe.social"]}`)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		email string
+		role  string
+		ok    bool
+	}{
+		{email: "ops@shareframe.social", role: "admin", ok: true},
+		{email: " OPS@shareframe.social", role: "admin", ok: true},
+		{email: "mod@shareframe.social", role: "moderator", ok: true},
+		{email: "alice@example.com"},
+		{email: "shareframe.social"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			role, ok := roles.Role(tt.email)
+			assert.Equal(t, tt.role, role)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+
+	var none RoleAssignments
+	_, ok := none.Role("ops@shareframe.social")
+	assert.False(t, ok)
+}
+
+func TestParseRoleAssignments(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expectedErr string
+	}{
+		{name: "Unset"},
+		{name: "Valid", raw: `{"admin":["ops@shareframe.social"]}`},
+		{name: "Not JSON", raw: `admin=ops@shareframe.social`, expectedErr: "ROLE_ASSIGNMENTS must be a JSON object of roles to email addresses"},
+		{name: "Bot Role", raw: `{"bot":["@bots.shareframe.social"]}`, expectedErr: `ROLE_ASSIGNMENTS: role "bot" can't be assigned by email`},
+		{name: "Not An Address", raw: `{"admin":["ops"]}`, expectedErr: `ROLE_ASSIGNMENTS: "ops" is not an email address or @domain`},
+		{name: "Two Roles", raw: `{"admin":["ops@shareframe.social"],"moderator":["OPS@shareframe.social"]}`, expectedErr: "is assigned both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRoleAssignments(tt.raw)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseBootstrapAccounts(t *testing.T) {
+	roles := RoleAssignments{"admin": {"ops@shareframe.social"}}
+
+	accounts, err := parseBootstrapAccounts(`[{"handle":"ops","email":"ops@shareframe.social"}]`, roles)
+	assert.NoError(t, err)
+	assert.Equal(t, []BootstrapAccount{{Handle: "ops", Email: "ops@shareframe.social"}}, accounts)
+
+	accounts, err = parseBootstrapAccounts("", roles)
+	assert.NoError(t, err)
+	assert.Nil(t, accounts)
+
+	_, err = parseBootstrapAccounts(`[{"handle":"alice","email":"alice@example.com"}]`, roles)
+	assert.EqualError(t, err, "BOOTSTRAP_ACCOUNTS: alice@example.com has no role in ROLE_ASSIGNMENTS")
+	_, err = parseBootstrapAccounts(`[{"handle":"ops"}]`, roles)
+	assert.EqualError(t, err, "BOOTSTRAP_ACCOUNTS: every account needs a handle and an email")
+	_, err = parseBootstrapAccounts(`{}`, roles)
+	assert.ErrorContains(t, err, "BOOTSTRAP_ACCOUNTS must be a JSON list of accounts")
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// DefaultSecretCacheTTL is how long a secret read through SecretCache is
+// reused before it is read again, so rotated secrets are picked up.
+const DefaultSecretCacheTTL = 5 * time.Minute
+
+// SecretCache is a SecretsManagerAPI that keeps what it reads for a TTL, so
+// warm invocations don't read the same secrets for every request. Callers
+// asking for a secret that is already being read wait for that read.
+// Failed reads aren't kept.
+type SecretCache struct {
+	client SecretsManagerAPI
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*secretEntry
+}
+
+type secretEntry struct {
+	done    chan struct{}
+	output  *secretsmanager.GetSecretValueOutput
+	err     error
+	expires time.Time
+}
+
+func NewSecretCache(client SecretsManagerAPI, ttl time.Duration) *SecretCache {
+	return &SecretCache{client: client, ttl: ttl, now: time.Now, entries: map[string]*secretEntry{}}
+}
+
+func (c *SecretCache) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	entry := c.entry(ctx, input, opts...)
+	select {
+	case <-entry.done:
+		return entry.output, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Prefetch starts reading the named secrets in the background and returns
+// at once. Run during init, it lets the first invocation on a cold
+// container find them cached instead of reading them one after another.
+func (c *SecretCache) Prefetch(ctx context.Context, names ...string) {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		c.entry(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId:     aws.String(name),
+			VersionStage: aws.String("AWSCURRENT"),
+		})
+	}
+}
+
+// entry returns the cached read of input, starting one when there is none
+// or it has expired.
+func (c *SecretCache) entry(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) *secretEntry {
+	key := aws.ToString(input.SecretId) + "@" + aws.ToString(input.VersionStage)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		select {
+		case <-entry.done:
+			if c.now().Before(entry.expires) {
+				return entry
+			}
+		default:
+			return entry
+		}
+	}
+
+	entry := &secretEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	// The read outlives the caller that started it, since others may be
+	// waiting on it.
+	go func(ctx context.Context) {
+		entry.output, entry.err = c.client.GetSecretValue(ctx, input, opts...)
+		c.mu.Lock()
+		entry.expires = c.now().Add(c.ttl)
+		if entry.err != nil {
+			logging.FromContext(ctx).WithError(entry.err).WithField("secret_name", aws.ToString(input.SecretId)).Warn("Failed to read secret")
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		close(entry.done)
+	}(context.WithoutCancel(ctx))
+	return entry
+}
+
+// SecretPrefetch is what a cold container reads ahead of its first
+// invocation: the secrets every signup reads, and how long to keep them.
+type SecretPrefetch struct {
+	Names []string
+	TTL   time.Duration
+}
+
+// LoadSecretPrefetch names the PostgreSQL secret and the default tenant's
+// PDS admin, util account and email secrets, and reads SECRET_CACHE_TTL.
+func LoadSecretPrefetch(ctx context.Context, awsCfg aws.Config) (SecretPrefetch, error) {
+	env := newEnvResolver(ctx, newKMSClient(awsCfg))
+	prefetch := SecretPrefetch{
+		Names: []string{
+			env.get("POSTGRES_CONN_STR"),
+			env.get("PDS_ADMIN_SECRET_NAME"),
+			env.get("PDS_UTIL_ACCOUNT_CREDS"),
+			env.get("RESEND_SECRET_NAME"),
+		},
+		TTL: env.duration("SECRET_CACHE_TTL", DefaultSecretCacheTTL),
+	}
+	return prefetch, env.err
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func secretNamed(name string) interface{} {
+	return mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.SecretId) == name
+	})
+}
+
+func TestSecretCachePrefetch(t *testing.T) {
+	ctx := context.Background()
+	client := new(mockSecretsManagerClient)
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-admin")).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("admin-secret")}, nil).Once()
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-util")).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("util-secret")}, nil).Once()
+
+	cache := NewSecretCache(client, time.Minute)
+	cache.Prefetch(ctx, "pds-admin", "", "pds-util")
+
+	for i := 0; i < 2; i++ {
+		admin, err := RetrieveSecret(ctx, "pds-admin", cache)
+		assert.NoError(t, err)
+		assert.Equal(t, "admin-secret", admin)
+		util, err := RetrieveSecret(ctx, "pds-util", cache)
+		assert.NoError(t, err)
+		assert.Equal(t, "util-secret", util)
+	}
+	client.AssertExpectations(t)
+}
+
+func TestSecretCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	client := new(mockSecretsManagerClient)
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-util")).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("old")}, nil).Once()
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-util")).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("rotated")}, nil).Once()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewSecretCache(client, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	value, _ := RetrieveSecret(ctx, "pds-util", cache)
+	assert.Equal(t, "old", value)
+	now = now.Add(30 * time.Second)
+	value, _ = RetrieveSecret(ctx, "pds-util", cache)
+	assert.Equal(t, "old", value)
+	now = now.Add(time.Minute)
+	value, _ = RetrieveSecret(ctx, "pds-util", cache)
+	assert.Equal(t, "rotated", value)
+	client.AssertExpectations(t)
+}
+
+func TestSecretCacheDropsFailedReads(t *testing.T) {
+	ctx := context.Background()
+	client := new(mockSecretsManagerClient)
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-util")).
+		Return(nil, errors.New("ThrottlingException")).Once()
+	client.On("GetSecretValue", mock.Anything, secretNamed("pds-util")).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("util-secret")}, nil).Once()
+
+	cache := NewSecretCache(client, time.Minute)
+	cache.Prefetch(ctx, "pds-util")
+
+	_, err := RetrieveSecret(ctx, "pds-util", cache)
+	assert.Error(t, err)
+	value, err := RetrieveSecret(ctx, "pds-util", cache)
+	assert.NoError(t, err)
+	assert.Equal(t, "util-secret", value)
+	client.AssertExpectations(t)
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Snapshot is a flattened, log-safe view of the effective configuration.
+// Sensitive values are replaced with a short hash so two snapshots can still
+// be compared without exposing the value itself.
+type Snapshot map[string]string
+
+func hashValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+func (c *Config) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		"dbClusterArn":       c.DBClusterARN,
+		"secretArn":          c.SecretARN,
+		"databaseName":       c.DatabaseName,
+		"postgresConnStr":    hashValue(c.PostgresConnStr),
+		"atprotoBaseUrl":     c.AtProtoBaseURL,
+		"queryTimeout":       c.QueryTimeout.String(),
+		"httpTimeout":        c.HTTPTimeout.String(),
+		"emailTemplate":      c.EmailTemplateSource,
+		"emailSecretName":    c.EmailSecretName,
+		"retryPolicy":        fmt.Sprintf("%+v", c.Retry),
+		"profileDefaults":    fmt.Sprintf("%+v", c.ProfileDefaults),
+		"storageBackend":     c.StorageBackend,
+		"unicodeHandles":     strconv.FormatBool(c.AllowUnicodeHandles),
+		"profanityMode":      c.ProfanityMode,
+		"minPasswordScore":   strconv.Itoa(c.MinPasswordScore),
+		"breachCheck":        strconv.FormatBool(c.BreachCheckEnabled),
+		"breachTimeout":      c.BreachCheckTimeout.String(),
+		"customDomains":      strconv.FormatBool(c.AllowCustomDomainHandles),
+		"domainCacheTTL":     c.DomainCacheTTL.String(),
+		"userInviteCodes":    strconv.FormatBool(c.UserInviteCodes),
+		"dlqMaxReceives":     strconv.Itoa(c.DLQMaxReceives),
+		"emailMaxAttempts":   strconv.Itoa(c.EmailMaxAttempts),
+		"accountEventsTopic": c.AccountEventsTopicARN,
+		"riskScoring":        strconv.FormatBool(c.RiskScoring),
+		"riskWindow":         c.RiskWindow.String(),
+		"riskReviewScore":    strconv.Itoa(c.RiskReviewScore),
+		"riskCaptchaScore":   strconv.Itoa(c.RiskCaptchaScore),
+		"captchaSecretName":  c.CaptchaSecretName,
+		"domainSignupLimit":  strconv.Itoa(c.DomainThrottle.Limit),
+		"rateLimitTable":     c.RateLimitTable,
+		"rateLimitShards":    strconv.Itoa(c.RateLimitShards),
+		"snapshotTable":      c.ConfigSnapshotTable,
+		"emailRecipientCap":  strconv.Itoa(c.EmailRecipientLimit),
+		"responseTokenKey":   c.ResponseTokenSecretName,
+		"responseTokenTTL":   c.ResponseTokenTTL.String(),
+		"tokenKmsKey":        c.TokenKMSKeyID,
+		"tokenKmsPrevious":   strings.Join(c.TokenKMSPreviousKeyIDs, ","),
+		"exportBucket":       c.PrivacyExportBucket,
+		"exportUrlTTL":       c.ExportURLTTL.String(),
+		"erasureConfirmTTL":  c.ErasureConfirmTTL.String(),
+		"requireConsent":     strconv.FormatBool(c.RequireProcessingConsent),
+		"stripeCustomers":    strconv.FormatBool(c.StripeCustomers),
+		"stripeKey":          c.StripeSecretName,
+		"analyticsKey":       c.AnalyticsSecretName,
+		"analyticsEndpoint":  c.AnalyticsEndpoint,
+		"crmProvider":        c.CRMProvider,
+		"crmKey":             c.CRMSecretName,
+		"crmSyncDisabled":    strconv.FormatBool(c.CRMSyncDisabled),
+		"handleClaims":       strconv.FormatBool(c.HandleClaims),
+		"handleClaimTTL":     c.HandleClaimTTL.String(),
+		"handleQuarantine":   c.HandleQuarantine.String(),
+		"organizations":      strconv.FormatBool(c.OrganizationAccounts),
+		"activationTemplate": c.ActivationEmailTemplate,
+		"googleClientIds":    strings.Join(c.GoogleClientIDs, ","),
+		"appleClientIds":     strings.Join(c.AppleClientIDs, ","),
+		"socialCredential":   c.SocialCredential,
+		"phoneVerification":  strconv.FormatBool(c.PhoneVerification),
+		"phoneCodeTTL":       c.PhoneCodeTTL.String(),
+		"phoneMaxAccounts":   strconv.Itoa(c.PhoneMaxAccounts),
+		"phoneSendsPerDay":   strconv.Itoa(c.PhoneSendsPerDay),
+		"smsSenderId":        c.SMSSenderID,
+		"phoneClearsReview":  strconv.FormatBool(c.PhoneClearsRiskReview),
+		"botAccounts":        strconv.FormatBool(c.BotAccounts),
+		"botSignupsPerDay":   strconv.Itoa(c.BotSignupsPerDay),
+		"botEmailDomain":     c.BotEmailDomain,
+		"avatarBucket":       c.AvatarBucket,
+		"avatarUploadTTL":    c.AvatarUploadTTL.String(),
+		"avatarMaxBytes":     strconv.Itoa(c.AvatarMaxBytes),
+		"onboardingSeeding":  strconv.FormatBool(c.OnboardingSeeding),
+		"starterFollows":     strings.Join(c.StarterFollows, ","),
+		"profileRecord":      strconv.FormatBool(c.ShareFrameProfileRecord),
+		"signupQuotaDaily":   strconv.Itoa(c.SignupQuota.Daily),
+		"signupQuotaDomain":  strconv.Itoa(c.SignupQuota.DomainDaily),
+		"signupStats":        strconv.FormatBool(c.SignupStats),
+		"signupStatsWindow":  c.SignupStatsWindow.String(),
+		"rollout":            strconv.FormatBool(c.Rollout.Enabled),
+		"rolloutPercent":     strconv.Itoa(c.Rollout.Percent),
+		"rolloutDomains":     strings.Join(c.Rollout.Domains, ","),
+	}
+
+	for id, tenant := range c.Tenants {
+		prefix := "tenants." + id + "."
+		snapshot[prefix+"pdsBaseUrl"] = tenant.PDSBaseURL
+		snapshot[prefix+"handleSuffix"] = tenant.HandleSuffix
+		snapshot[prefix+"emailFrom"] = tenant.EmailFrom
+		snapshot[prefix+"tablePrefix"] = tenant.TablePrefix
+		snapshot[prefix+"adminSecretName"] = tenant.AdminSecretName
+		snapshot[prefix+"utilSecretName"] = tenant.UtilSecretName
+		snapshot[prefix+"emailTemplate"] = tenant.EmailTemplateSource
+		snapshot[prefix+"disabledValidationRules"] = strings.Join(tenant.DisabledValidationRules, ",")
+	}
+
+	for _, webhook := range c.WebhookEndpoints {
+		prefix := "webhooks." + webhook.Name + "."
+		snapshot[prefix+"url"] = webhook.URL
+		snapshot[prefix+"secretName"] = webhook.SecretName
+		snapshot[prefix+"events"] = strings.Join(webhook.Events, ",")
+	}
+
+	for domain, limit := range c.DomainThrottle.Overrides {
+		snapshot["domainSignupLimits."+domain] = strconv.Itoa(limit)
+	}
+
+	for domain, limit := range c.SignupQuota.DomainOverrides {
+		snapshot["signupQuotas."+domain] = strconv.Itoa(limit)
+	}
+
+	// Bootstrap accounts are operators' own, so only their handles are kept
+	// in the clear.
+	for _, account := range c.BootstrapAccounts {
+		snapshot["bootstrapAccounts."+account.Handle] = hashValue(account.Email)
+	}
+
+	for step, share := range c.ExecutionBudget {
+		snapshot["executionBudget."+string(step)] = strconv.Itoa(share)
+	}
+
+	for target, rate := range c.FaultInjection {
+		snapshot["faultInjection."+string(target)] = strconv.Itoa(rate)
+	}
+
+	return snapshot
+}
+
+// DiffSnapshots returns the sorted keys whose values were added, removed or
+// changed between two snapshots.
+func DiffSnapshots(previous, current Snapshot) []string {
+	var changed []string
+	for key, value := range current {
+		if old, ok := previous[key]; !ok || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
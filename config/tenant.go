@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultTenantID     = "shareframe"
+	DefaultHandleSuffix = ".shareframe.social"
+)
+
+// tablePrefixRegex limits table prefixes to what can be spliced into a
+// table name in SQL without quoting.
+var tablePrefixRegex = regexp.MustCompile(`^[a-z0-9_]*$`)
+
+// Tenant describes a single white-label community. Each tenant runs on its
+// own PDS host and owns its handle namespace, email identity and tables.
+type Tenant struct {
+	ID              string `json:"id"`
+	PDSBaseURL      string `json:"pdsBaseUrl"`
+	HandleSuffix    string `json:"handleSuffix"`
+	EmailFrom       string `json:"emailFrom"`
+	TablePrefix     string `json:"tablePrefix"`
+	AdminSecretName string `json:"adminSecretName"`
+	UtilSecretName  string `json:"utilSecretName"`
+	// EmailTemplateSource overrides Config.EmailTemplateSource for brand
+	// variants of the welcome email.
+	EmailTemplateSource string `json:"emailTemplateSource"`
+	// DisabledValidationRules names signup validation rules this tenant
+	// opts out of, e.g. "profanity".
+	DisabledValidationRules []string `json:"disabledValidationRules,omitempty"`
+}
+
+func defaultTenant(env *envResolver, baseURL string) Tenant {
+	return Tenant{
+		ID:              DefaultTenantID,
+		PDSBaseURL:      baseURL,
+		HandleSuffix:    DefaultHandleSuffix,
+		EmailFrom:       env.get("EMAIL_FROM"),
+		AdminSecretName: env.get("PDS_ADMIN_SECRET_NAME"),
+		UtilSecretName:  env.get("PDS_UTIL_ACCOUNT_CREDS"),
+	}
+}
+
+// loadTenants builds the tenant table from the optional TENANTS_CONFIG JSON
+// array. The default tenant is always present and can be overridden by an
+// entry with the same ID.
+func loadTenants(raw string, fallback Tenant) (map[string]Tenant, error) {
+	tenants := map[string]Tenant{fallback.ID: fallback}
+	if raw == "" {
+		return tenants, nil
+	}
+
+	var entries []Tenant
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse TENANTS_CONFIG: %w", err)
+	}
+
+	for _, t := range entries {
+		if t.ID == "" || t.PDSBaseURL == "" || t.HandleSuffix == "" {
+			return nil, errors.New("tenant entries require id, pdsBaseUrl and handleSuffix")
+		}
+		if !tablePrefixRegex.MatchString(t.TablePrefix) {
+			return nil, fmt.Errorf("tenant %s: tablePrefix %q may only contain lowercase letters, digits and underscores", t.ID, t.TablePrefix)
+		}
+		if !strings.HasPrefix(t.HandleSuffix, ".") {
+			t.HandleSuffix = "." + t.HandleSuffix
+		}
+		if t.AdminSecretName == "" {
+			t.AdminSecretName = fallback.AdminSecretName
+		}
+		if t.UtilSecretName == "" {
+			t.UtilSecretName = fallback.UtilSecretName
+		}
+		tenants[t.ID] = t
+	}
+
+	return tenants, nil
+}
+
+// ResolveTenant returns the tenant for the given ID, falling back to the
+// default tenant when the request does not name one.
+func (c *Config) ResolveTenant(id string) (Tenant, error) {
+	if id == "" {
+		id = DefaultTenantID
+	}
+
+	tenant, ok := c.Tenants[id]
+	if !ok {
+		return Tenant{}, fmt.Errorf("unknown tenant: %s", id)
+	}
+	return tenant, nil
+}
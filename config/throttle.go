@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DomainThrottle caps how many accounts can be created with one email
+// domain per hour, since bot waves tend to share a domain.
+type DomainThrottle struct {
+	// Limit applies to every domain without an override; 0 turns the
+	// default limit off.
+	Limit int
+	// Overrides sets the limit for particular domains, such as a partner
+	// university that signs up whole classes. An override of 0 exempts the
+	// domain.
+	Overrides map[string]int
+}
+
+// Enabled reports whether any domain is throttled.
+func (t DomainThrottle) Enabled() bool {
+	if t.Limit > 0 {
+		return true
+	}
+	for _, limit := range t.Overrides {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDomainThrottle reads DOMAIN_SIGNUP_LIMIT and the comma-separated
+// domain=limit pairs in DOMAIN_SIGNUP_LIMIT_OVERRIDES.
+func loadDomainThrottle(env *envResolver) (DomainThrottle, error) {
+	overrides, err := domainLimits(env, "DOMAIN_SIGNUP_LIMIT_OVERRIDES")
+	if err != nil {
+		return DomainThrottle{}, err
+	}
+	return DomainThrottle{Limit: env.integer("DOMAIN_SIGNUP_LIMIT", 0), Overrides: overrides}, nil
+}
+
+// domainLimits reads the comma-separated domain=limit pairs in key, or nil
+// when it is unset.
+func domainLimits(env *envResolver, key string) (map[string]int, error) {
+	var limits map[string]int
+	for _, pair := range env.list(key) {
+		domain, raw, ok := strings.Cut(pair, "=")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		limit, err := strconv.Atoi(strings.TrimSpace(raw))
+		if !ok || domain == "" || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid %s entry: %q", key, pair)
+		}
+		if limits == nil {
+			limits = map[string]int{}
+		}
+		limits[domain] = limit
+	}
+	return limits, nil
+}
+
+// SignupQuota caps the accounts created per UTC day, across the deployment
+// and per email domain, so a spam wave can't use up the PDS's invite
+// capacity or run up the DynamoDB bill overnight. Unlike DomainThrottle,
+// its counters are exact; they need RateLimitTable.
+type SignupQuota struct {
+	// Daily caps every signup; 0 means no cap.
+	Daily int
+	// DomainDaily caps each email domain without an override; 0 means no
+	// cap.
+	DomainDaily int
+	// DomainOverrides sets the cap for particular domains. An override of
+	// 0 exempts the domain.
+	DomainOverrides map[string]int
+}
+
+// Enabled reports whether any quota applies.
+func (q SignupQuota) Enabled() bool {
+	if q.Daily > 0 || q.DomainDaily > 0 {
+		return true
+	}
+	for _, limit := range q.DomainOverrides {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSignupQuota reads SIGNUP_DAILY_QUOTA, DOMAIN_SIGNUP_DAILY_QUOTA and
+// the domain=limit pairs in DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES.
+func loadSignupQuota(env *envResolver) (SignupQuota, error) {
+	overrides, err := domainLimits(env, "DOMAIN_SIGNUP_DAILY_QUOTA_OVERRIDES")
+	if err != nil {
+		return SignupQuota{}, err
+	}
+	return SignupQuota{
+		Daily:           env.integer("SIGNUP_DAILY_QUOTA", 0),
+		DomainDaily:     env.integer("DOMAIN_SIGNUP_DAILY_QUOTA", 0),
+		DomainOverrides: overrides,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// WebhookEndpoint is a partner URL that receives account lifecycle events.
+// Deliveries are signed with the secret stored under SecretName.
+type WebhookEndpoint struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	SecretName string `json:"secretName"`
+	// Events limits the endpoint to these events; empty means all of them.
+	Events []string `json:"events,omitempty"`
+}
+
+// loadWebhookEndpoints parses the optional WEBHOOK_ENDPOINTS JSON array.
+func loadWebhookEndpoints(raw string) ([]WebhookEndpoint, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var endpoints []WebhookEndpoint
+	if err := json.Unmarshal([]byte(raw), &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse WEBHOOK_ENDPOINTS: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, e := range endpoints {
+		if e.Name == "" || e.URL == "" || e.SecretName == "" {
+			return nil, errors.New("webhook endpoints require name, url and secretName")
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("duplicate webhook endpoint: %s", e.Name)
+		}
+		seen[e.Name] = true
+
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("webhook endpoint %s must use an https URL", e.Name)
+		}
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,150 @@
+// Package analytics reports signups to a Segment-compatible tracking API
+// for growth dashboards. Accounts are identified by a salted pseudonym
+// rather than their DID, and no handle, email or profile data is sent.
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+// SegmentTrackEndpoint is Segment's HTTP tracking API. RudderStack and
+// other Segment-compatible collectors accept the same payload.
+const SegmentTrackEndpoint = "https://api.segment.io/v1/track"
+
+// EventAccountCreated is the analytics name for models.EventAccountCreated.
+const EventAccountCreated = "Account Created"
+
+const library = "user-management"
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Tracker struct {
+	WriteKey   string
+	Salt       string
+	Endpoint   string
+	HTTPClient HTTPClient
+	Retry      retry.Policy
+	now        func() time.Time
+}
+
+func NewTracker(writeKey, salt, endpoint string, client HTTPClient, retryPolicy retry.Policy) *Tracker {
+	if endpoint == "" {
+		endpoint = SegmentTrackEndpoint
+	}
+	return &Tracker{
+		WriteKey:   writeKey,
+		Salt:       salt,
+		Endpoint:   endpoint,
+		HTTPClient: client,
+		Retry:      retryPolicy,
+		now:        time.Now,
+	}
+}
+
+type track struct {
+	UserID     string                 `json:"userId"`
+	Event      string                 `json:"event"`
+	MessageID  string                 `json:"messageId,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties"`
+	Context    map[string]interface{} `json:"context"`
+}
+
+// Publish tracks account.created; other events aren't reported. Accounts
+// that declined data processing aren't tracked at all. The event ID is
+// sent as the message ID, which Segment deduplicates on.
+func (t *Tracker) Publish(ctx context.Context, event models.AccountEvent) error {
+	if event.Event != models.EventAccountCreated {
+		return nil
+	}
+	if consent, ok := models.FindConsent(event.Consents, models.ConsentDataProcessing); ok && !consent.Granted {
+		return nil
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = t.now().UTC()
+	}
+
+	properties := map[string]interface{}{}
+	if event.Tenant != "" {
+		properties["tenant"] = event.Tenant
+	}
+	if consent, ok := models.FindConsent(event.Consents, models.ConsentMarketing); ok {
+		properties["marketingConsent"] = consent.Granted
+	}
+
+	body, err := json.Marshal(track{
+		UserID:     t.Pseudonym(event.DID),
+		Event:      EventAccountCreated,
+		MessageID:  event.ID,
+		Timestamp:  event.OccurredAt,
+		Properties: properties,
+		Context:    map[string]interface{}{"library": map[string]string{"name": library}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics event: %w", err)
+	}
+
+	err = t.Retry.Do(ctx, "analytics.Track", func(ctx context.Context) error {
+		return t.post(ctx, body)
+	})
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"event_id": event.ID,
+		"event":    EventAccountCreated,
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyAnalytics, err)
+		log.WithError(err).Error("Failed to track analytics event")
+		return err
+	}
+
+	log.Info("Analytics event tracked")
+	return nil
+}
+
+// Pseudonym returns the analytics ID for did: an HMAC keyed with the salt,
+// so it is stable across events but can't be reversed from the public DID.
+func (t *Tracker) Pseudonym(did string) string {
+	mac := hmac.New(sha256.New, []byte(t.Salt))
+	mac.Write([]byte(did))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (t *Tracker) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create analytics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(t.WriteKey, "")
+
+	resp, err := t.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("analytics request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if retry.IsRetryableStatus(resp.StatusCode) {
+		return &retry.StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from analytics API: %d", resp.StatusCode)
+	}
+	return nil
+}
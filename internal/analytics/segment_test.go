@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+func TestPublish(t *testing.T) {
+	created := models.NewAccountEvent(models.EventAccountCreated, "did:plc:abc", "alice.shareframe.social", "default")
+	created.Consents = []models.Consent{
+		{Purpose: models.ConsentMarketing, Granted: true},
+		{Purpose: models.ConsentDataProcessing, Granted: true},
+	}
+	declined := created
+	declined.Consents = []models.Consent{{Purpose: models.ConsentDataProcessing, Granted: false}}
+
+	tests := []struct {
+		name          string
+		event         models.AccountEvent
+		status        int
+		httpErr       error
+		expectSent    bool
+		expectedError string
+	}{
+		{name: "Tracked", event: created, status: http.StatusOK, expectSent: true},
+		{name: "Other Event", event: models.NewAccountEvent(models.EventVerificationPending, "did:plc:abc", "alice.shareframe.social", "default")},
+		{name: "Processing Declined", event: declined},
+		{name: "Rejected", event: created, status: http.StatusBadRequest, expectSent: true, expectedError: "unexpected status code from analytics API: 400"},
+		{name: "Request Failed", event: created, httpErr: errors.New("timeout"), expectSent: true, expectedError: "analytics request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			var payload map[string]interface{}
+			tracker := NewTracker("write-key", "salt", "", &mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					req = r
+					body, _ := io.ReadAll(r.Body)
+					_ = json.Unmarshal(body, &payload)
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+				},
+			}, retry.Policy{})
+			tracker.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+			err := tracker.Publish(context.Background(), tt.event)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if !tt.expectSent {
+				assert.Nil(t, req)
+				return
+			}
+
+			assert.Equal(t, SegmentTrackEndpoint, req.URL.String())
+			user, password, _ := req.BasicAuth()
+			assert.Equal(t, "write-key", user)
+			assert.Empty(t, password)
+			assert.Equal(t, EventAccountCreated, payload["event"])
+			assert.Equal(t, tt.event.ID, payload["messageId"])
+			assert.Equal(t, tracker.Pseudonym("did:plc:abc"), payload["userId"])
+			assert.Equal(t, map[string]interface{}{"tenant": "default", "marketingConsent": true}, payload["properties"])
+			assert.NotContains(t, payload, "did")
+		})
+	}
+}
+
+func TestPseudonym(t *testing.T) {
+	tracker := NewTracker("write-key", "salt", "", nil, retry.Policy{})
+	other := NewTracker("write-key", "other-salt", "", nil, retry.Policy{})
+
+	assert.Equal(t, tracker.Pseudonym("did:plc:abc"), tracker.Pseudonym("did:plc:abc"))
+	assert.NotEqual(t, tracker.Pseudonym("did:plc:abc"), tracker.Pseudonym("did:plc:xyz"))
+	assert.NotEqual(t, tracker.Pseudonym("did:plc:abc"), other.Pseudonym("did:plc:abc"))
+}
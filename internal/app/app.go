@@ -0,0 +1,278 @@
+// Package app wires the service together. It loads the settings and
+// tenants and builds the storage, email and other clients once per process,
+// and one instance of each handler on top of them, for the Lambda binary and
+// the commands in cmd/. A request only picks its tenant and opens that
+// tenant's store.
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/handlers"
+	"github.com/ShareFrame/user-management/internal/ingress"
+	"github.com/ShareFrame/user-management/internal/lambdahttp"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"google.golang.org/grpc"
+)
+
+type Options struct {
+	// PrefetchSecrets starts reading the secrets every signup needs as
+	// soon as the container is built, for a Lambda cold start.
+	PrefetchSecrets bool
+	// RecordConfigSnapshot records the configuration of this start for
+	// the drift check. Only the deployed service sets it, so operator
+	// commands don't store the configuration they were run with.
+	RecordConfigSnapshot bool
+}
+
+// snapshotTimeout bounds how long recording the configuration snapshot
+// may hold up a start.
+const snapshotTimeout = 5 * time.Second
+
+// Container holds the shared Services and the handlers built on them.
+// Handlers keep caches between invocations, so everything serving requests
+// in a process takes its handlers from the same Container.
+type Container struct {
+	Services *handlers.Services
+
+	Users        *handlers.UserHandler
+	Claims       *handlers.ClaimHandler
+	Bots         *handlers.BotHandler
+	Avatars      *handlers.AvatarHandler
+	Phone        *handlers.PhoneHandler
+	Referrals    *handlers.ReferralHandler
+	Availability *handlers.AvailabilityHandler
+	Admin        *handlers.AdminHandler
+	Blocklist    *handlers.BlocklistHandler
+	Lifecycle    *handlers.LifecycleHandler
+	Privacy      *handlers.PrivacyHandler
+	Review       *handlers.ReviewHandler
+	Repair       *handlers.RepairHandler
+	DLQ          *handlers.DLQHandler
+	EmailQueue   *handlers.EmailQueueHandler
+	CRM          *handlers.CRMHandler
+	Stats        *handlers.StatsHandler
+}
+
+// New loads the AWS config and the settings, and builds the Container on a
+// Secrets Manager client that caches for SECRET_CACHE_TTL.
+func New(ctx context.Context, opts Options) (*Container, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	prefetch, err := config.LoadSecretPrefetch(ctx, awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret cache configuration: %w", err)
+	}
+
+	secrets := config.NewSecretCache(secretsmanager.NewFromConfig(awsCfg), prefetch.TTL)
+	if opts.PrefetchSecrets {
+		// The reads overlap the rest of init, so a cold container's first
+		// invocation doesn't wait on Secrets Manager for each secret in
+		// turn.
+		secrets.Prefetch(context.Background(), prefetch.Names...)
+	}
+
+	cfg, _, err := config.LoadConfig(ctx, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load application configuration: %w", err)
+	}
+	services := handlers.NewServices(cfg, awsCfg, secrets)
+	if opts.RecordConfigSnapshot {
+		// The snapshot isn't part of building the container, so it gets
+		// its own deadline rather than the caller's.
+		snapshotCtx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		services.RecordConfigSnapshot(snapshotCtx)
+		cancel()
+	}
+	return NewContainer(services), nil
+}
+
+// NewContainer builds the handlers on services.
+func NewContainer(services *handlers.Services) *Container {
+	return &Container{
+		Services:     services,
+		Users:        handlers.NewUserHandler(services),
+		Claims:       handlers.NewClaimHandler(services),
+		Bots:         handlers.NewBotHandler(services),
+		Avatars:      handlers.NewAvatarHandler(services),
+		Phone:        handlers.NewPhoneHandler(services),
+		Referrals:    handlers.NewReferralHandler(services),
+		Availability: handlers.NewAvailabilityHandler(services),
+		Admin:        handlers.NewAdminHandler(services),
+		Blocklist:    handlers.NewBlocklistHandler(services),
+		Lifecycle:    handlers.NewLifecycleHandler(services),
+		Privacy:      handlers.NewPrivacyHandler(services),
+		Review:       handlers.NewReviewHandler(services),
+		Repair:       handlers.NewRepairHandler(services),
+		DLQ:          handlers.NewDLQHandler(services),
+		EmailQueue:   handlers.NewEmailQueueHandler(services),
+		CRM:          handlers.NewCRMHandler(services),
+		Stats:        handlers.NewStatsHandler(services),
+	}
+}
+
+// Lambda returns the handler the Lambda function named by APP_HANDLER
+// runs for integration, one of the lambdahttp integrations. An empty name
+// is the users function. Direct invokes get the handler wrapped in
+// handlers.Recover; HTTP integrations get the handler's HTTP route, so they
+// answer with the same status codes as the HTTP server and refuse operator
+// calls the integration didn't authenticate; with IntegrationAuto the
+// source of each event is detected by internal/ingress. The queue and
+// schedule functions take only their own events.
+func (c *Container) Lambda(name, integration string) (interface{}, error) {
+	if integration == lambdahttp.IntegrationDirect {
+		return c.direct(name)
+	}
+	operation, ok := httpOperations[name]
+	if !ok {
+		handler, err := c.direct(name)
+		if err != nil || integration == lambdahttp.IntegrationAuto {
+			return handler, err
+		}
+		return nil, fmt.Errorf("handler %s is only invoked directly, not over %s", name, integration)
+	}
+	if integration == lambdahttp.IntegrationAuto {
+		return c.detect(name, operation), nil
+	}
+	return lambdahttp.Handler(integration, c.routes().Operation(operation))
+}
+
+// httpOperations are the HTTP operations of the functions that can sit
+// behind an HTTP integration.
+var httpOperations = map[string]string{
+	"":             handlers.OperationCreateAccount,
+	"users":        handlers.OperationCreateAccount,
+	"claims":       handlers.OperationClaimHandle,
+	"bots":         handlers.OperationCreateBot,
+	"avatars":      handlers.OperationAvatar,
+	"phone":        handlers.OperationPhone,
+	"referrals":    handlers.OperationReferral,
+	"availability": handlers.OperationAvailability,
+	"admin":        handlers.OperationAdmin,
+	"blocklist":    handlers.OperationBlocklist,
+	"lifecycle":    handlers.OperationLifecycle,
+	"privacy":      handlers.OperationPrivacy,
+	"review":       handlers.OperationReview,
+}
+
+// detect returns the handler for the function name that takes any event
+// source ingress knows. name must be in httpOperations.
+func (c *Container) detect(name, operation string) interface{} {
+	route := c.routes().Operation(operation)
+	switch name {
+	case "claims":
+		return invoke(operation, route, c.Claims.Handle, nil)
+	case "bots":
+		return invoke(operation, route, c.Bots.Handle, nil)
+	case "avatars":
+		return invoke(operation, route, c.Avatars.Handle, nil)
+	case "phone":
+		return invoke(operation, route, c.Phone.Handle, nil)
+	case "referrals":
+		return invoke(operation, route, c.Referrals.Handle, nil)
+	case "availability":
+		return invoke(operation, route, c.Availability.Handle, nil)
+	case "admin":
+		return invoke(operation, route, c.Admin.Handle, nil)
+	case "blocklist":
+		return invoke(operation, route, c.Blocklist.Handle, nil)
+	case "lifecycle":
+		return invoke(operation, route, c.Lifecycle.Handle, nil)
+	case "privacy":
+		return invoke(operation, route, c.Privacy.Handle, nil)
+	case "review":
+		return invoke(operation, route, c.Review.Handle, nil)
+	}
+	return invoke(operation, route, c.Users.HandleVersioned, func(in *handlers.VersionedUserRequest, req ingress.Request) {
+		// Events that don't say where the caller is keep the address
+		// the front end put in the request.
+		if req.ClientIP != "" {
+			in.ClientIP = req.ClientIP
+		}
+		// SQS redelivers a message under the same ID, so a retried
+		// message resumes its signup.
+		if in.IdempotencyKey == "" && req.Source == ingress.SourceSQS {
+			in.IdempotencyKey = req.ID
+		}
+	})
+}
+
+// invoke is the ingress handler for handle, wrapped in handlers.Recover.
+func invoke[In, Out any](operation string, route http.Handler, handle func(context.Context, In) (Out, error), prepare func(*In, ingress.Request)) interface{} {
+	f := ingress.Function[In, Out]{Handle: handle, HTTP: route, Prepare: prepare}
+	return handlers.Recover(operation, f.Invoke)
+}
+
+// direct returns the handler for direct invokes of the function name.
+func (c *Container) direct(name string) (interface{}, error) {
+	switch name {
+	case "", "users":
+		return handlers.Recover(handlers.OperationCreateAccount, c.Users.HandleVersioned), nil
+	case "blocklist":
+		return handlers.Recover(handlers.OperationBlocklist, c.Blocklist.Handle), nil
+	case "dlq":
+		return handlers.Recover("dlq.redrive", c.DLQ.Handle), nil
+	case "email-queue":
+		return handlers.Recover("email_queue", c.EmailQueue.Handle), nil
+	case "privacy":
+		return handlers.Recover(handlers.OperationPrivacy, c.Privacy.Handle), nil
+	case "crm":
+		return handlers.Recover("crm.sync", c.CRM.Handle), nil
+	case "stats":
+		return handlers.Recover("signup_stats", c.Stats.Handle), nil
+	case "referrals":
+		return handlers.Recover(handlers.OperationReferral, c.Referrals.Handle), nil
+	case "claims":
+		return handlers.Recover(handlers.OperationClaimHandle, c.Claims.Handle), nil
+	case "lifecycle":
+		return handlers.Recover(handlers.OperationLifecycle, c.Lifecycle.Handle), nil
+	case "phone":
+		return handlers.Recover(handlers.OperationPhone, c.Phone.Handle), nil
+	case "review":
+		return handlers.Recover(handlers.OperationReview, c.Review.Handle), nil
+	case "bots":
+		return handlers.Recover(handlers.OperationCreateBot, c.Bots.Handle), nil
+	case "avatars":
+		return handlers.Recover(handlers.OperationAvatar, c.Avatars.Handle), nil
+	case "availability":
+		return handlers.Recover(handlers.OperationAvailability, c.Availability.Handle), nil
+	case "admin":
+		return handlers.Recover(handlers.OperationAdmin, c.Admin.Handle), nil
+	}
+	return nil, fmt.Errorf("unknown handler: %s", name)
+}
+
+// Router serves the Container's handlers over HTTP; see handlers.NewRouter.
+func (c *Container) Router(admin bool) http.Handler {
+	return handlers.NewRouter(c.routes(), admin)
+}
+
+// GRPCServer serves the Container's UserService over gRPC; see
+// handlers.NewGRPCServer.
+func (c *Container) GRPCServer(admin bool) *grpc.Server {
+	return handlers.NewGRPCServer(c.routes(), admin)
+}
+
+func (c *Container) routes() handlers.Routes {
+	return handlers.Routes{
+		Users:        c.Users,
+		Claims:       c.Claims,
+		Bots:         c.Bots,
+		Avatars:      c.Avatars,
+		Phone:        c.Phone,
+		Referrals:    c.Referrals,
+		Availability: c.Availability,
+		Admin:        c.Admin,
+		Blocklist:    c.Blocklist,
+		Lifecycle:    c.Lifecycle,
+		Privacy:      c.Privacy,
+		Review:       c.Review,
+	}
+}
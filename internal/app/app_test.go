@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/handlers"
+	"github.com/ShareFrame/user-management/internal/lambdahttp"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+)
+
+type noSecrets struct{}
+
+func (noSecrets) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	return nil, context.Canceled
+}
+
+func newTestContainer() *Container {
+	return NewContainer(&handlers.Services{Config: &config.Config{}, Secrets: noSecrets{}})
+}
+
+func TestLambda(t *testing.T) {
+	container := newTestContainer()
+
+	for _, name := range []string{"", "users", "blocklist", "dlq", "email-queue", "privacy", "crm", "stats", "referrals", "claims", "lifecycle", "phone", "review", "bots", "avatars", "availability", "admin"} {
+		handler, err := container.Lambda(name, lambdahttp.IntegrationDirect)
+		assert.NoError(t, err, name)
+		assert.NotNil(t, handler, name)
+	}
+
+	_, err := container.Lambda("payments", lambdahttp.IntegrationDirect)
+	assert.EqualError(t, err, "unknown handler: payments")
+}
+
+func TestLambdaHTTPIntegration(t *testing.T) {
+	container := newTestContainer()
+
+	handler, err := container.Lambda("claims", lambdahttp.IntegrationAPIGateway)
+	assert.NoError(t, err)
+	apiGateway, ok := handler.(func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error))
+	assert.True(t, ok)
+	if ok {
+		// Claims only take POST, whatever the path.
+		resp, err := apiGateway(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/prod/claims"})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+
+	handler, err = container.Lambda("", lambdahttp.IntegrationFunctionURL)
+	assert.NoError(t, err)
+	assert.IsType(t, lambdahttp.FunctionURL(nil), handler)
+
+	_, err = container.Lambda("dlq", lambdahttp.IntegrationHTTPAPI)
+	assert.EqualError(t, err, "handler dlq is only invoked directly, not over httpapi")
+	_, err = container.Lambda("payments", lambdahttp.IntegrationHTTPAPI)
+	assert.EqualError(t, err, "unknown handler: payments")
+}
+
+func TestLambdaAuto(t *testing.T) {
+	container := newTestContainer()
+
+	handler, err := container.Lambda("claims", lambdahttp.IntegrationAuto)
+	assert.NoError(t, err)
+	invoke, ok := handler.(func(context.Context, json.RawMessage) (interface{}, error))
+	assert.True(t, ok)
+	if ok {
+		// An API Gateway event goes to the claims route.
+		resp, err := invoke(context.Background(), json.RawMessage(`{"httpMethod":"GET","path":"/claims","requestContext":{}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.(events.APIGatewayProxyResponse).StatusCode)
+	}
+
+	// Queue handlers stay direct.
+	handler, err = container.Lambda("dlq", lambdahttp.IntegrationAuto)
+	assert.NoError(t, err)
+	assert.NotNil(t, handler)
+	_, err = container.Lambda("payments", lambdahttp.IntegrationAuto)
+	assert.EqualError(t, err, "unknown handler: payments")
+}
+
+func TestRouter(t *testing.T) {
+	container := newTestContainer()
+
+	tests := []struct {
+		name   string
+		admin  bool
+		path   string
+		status int
+	}{
+		{name: "Health", path: "/healthz", status: http.StatusOK},
+		{name: "Admin Route Hidden", path: "/admin/review", status: http.StatusNotFound},
+		// The route is there; it only takes POST.
+		{name: "Admin Route Served", admin: true, path: "/admin/review", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			container.Router(tt.admin).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
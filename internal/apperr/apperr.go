@@ -0,0 +1,177 @@
+// Package apperr sorts the service's failures into a few categories that
+// decide whether they are worth retrying and how API responses report them,
+// so neither has to match on error text.
+//
+// A category is attached where an error is created or first wrapped, with
+// Errorf or Wrap. Errors without one are categorized from their validation
+// code or, failing that, from how the retry package classifies them.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"google.golang.org/grpc/codes"
+)
+
+type Category string
+
+const (
+	// Validation is a request the caller has to change.
+	Validation Category = "validation"
+	// Conflict is a request for something that already exists.
+	Conflict Category = "conflict"
+	NotFound Category = "not_found"
+	// Upstream is a failure of the PDS, the database or another dependency.
+	Upstream    Category = "upstream"
+	RateLimited Category = "rate_limited"
+	Internal    Category = "internal"
+)
+
+// Error attaches a category to err without changing its message.
+type Error struct {
+	Category Category
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches category to err. It returns nil for a nil err.
+func Wrap(category Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Category: category, Err: err}
+}
+
+// Errorf is fmt.Errorf with a category.
+func Errorf(category Category, format string, args ...interface{}) error {
+	return &Error{Category: category, Err: fmt.Errorf(format, args...)}
+}
+
+// CategoryOf returns err's category. A validation code is the most specific
+// signal and wins over an attached category, which wins over the retry
+// classification. It returns "" for a nil err.
+func CategoryOf(err error) Category {
+	if err == nil {
+		return ""
+	}
+
+	if code := validate.ErrorCode(err); code != "" {
+		return categoryForCode(code)
+	}
+
+	var categorized *Error
+	if errors.As(err, &categorized) {
+		return categorized.Category
+	}
+
+	if transient(err) {
+		return Upstream
+	}
+	return Internal
+}
+
+func categoryForCode(code string) Category {
+	switch code {
+	case validate.CodeHandleTaken, validate.CodeHandleClaimed, validate.CodeHandleQuarantined, validate.CodeEmailTaken, validate.CodePhoneInUse:
+		return Conflict
+	case validate.CodeRateLimited, validate.CodePhoneCodeLimit:
+		return RateLimited
+	case validate.CodeInternal:
+		return Internal
+	default:
+		return Validation
+	}
+}
+
+// transient reports whether the retry package considers err a temporary
+// failure under its default classes.
+func transient(err error) bool {
+	class := retry.Classify(err)
+	for _, retryable := range retry.DefaultClasses {
+		if class == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether the same request may succeed later:
+// rate-limited requests, and upstream failures that were transient, such
+// as timeouts and 5xx responses. A PDS rejecting the request is upstream
+// but not retryable.
+func IsRetryable(err error) bool {
+	switch CategoryOf(err) {
+	case RateLimited:
+		return true
+	case Upstream:
+		return transient(err)
+	default:
+		return false
+	}
+}
+
+// HTTPStatus is the response status for err.
+func HTTPStatus(err error) int {
+	switch CategoryOf(err) {
+	case Validation:
+		return http.StatusBadRequest
+	case Conflict:
+		return http.StatusConflict
+	case NotFound:
+		return http.StatusNotFound
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case Upstream:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GraphQLCode is the extensions.code for err in a GraphQL error, following
+// the codes Apollo servers use.
+func GraphQLCode(err error) string {
+	switch CategoryOf(err) {
+	case Validation:
+		return "BAD_USER_INPUT"
+	case Conflict:
+		return "CONFLICT"
+	case NotFound:
+		return "NOT_FOUND"
+	case RateLimited:
+		return "RATE_LIMITED"
+	case Upstream:
+		return "UPSTREAM_ERROR"
+	default:
+		return "INTERNAL_SERVER_ERROR"
+	}
+}
+
+// GRPCCode is the status code for err in a gRPC response.
+func GRPCCode(err error) codes.Code {
+	switch CategoryOf(err) {
+	case Validation:
+		return codes.InvalidArgument
+	case Conflict:
+		return codes.AlreadyExists
+	case NotFound:
+		return codes.NotFound
+	case RateLimited:
+		return codes.ResourceExhausted
+	case Upstream:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
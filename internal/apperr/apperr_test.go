@@ -0,0 +1,122 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		category    Category
+		status      int
+		graphQLCode string
+		grpcCode    codes.Code
+		retryable   bool
+	}{
+		{
+			name:        "Validation Code",
+			err:         validate.NewError(validate.CodeInvalidHandle, "invalid handle"),
+			category:    Validation,
+			status:      http.StatusBadRequest,
+			graphQLCode: "BAD_USER_INPUT",
+			grpcCode:    codes.InvalidArgument,
+		},
+		{
+			name:        "Taken Handle",
+			err:         validate.NewError(validate.CodeHandleTaken, "handle taken"),
+			category:    Conflict,
+			status:      http.StatusConflict,
+			graphQLCode: "CONFLICT",
+			grpcCode:    codes.AlreadyExists,
+		},
+		{
+			name:        "Code Wins Over Category",
+			err:         Errorf(Validation, "validation error: %w", validate.NewError(validate.CodeEmailTaken, "email taken")),
+			category:    Conflict,
+			status:      http.StatusConflict,
+			graphQLCode: "CONFLICT",
+			grpcCode:    codes.AlreadyExists,
+		},
+		{
+			name:        "Rate Limited",
+			err:         validate.NewError(validate.CodeRateLimited, "too many signups"),
+			category:    RateLimited,
+			status:      http.StatusTooManyRequests,
+			graphQLCode: "RATE_LIMITED",
+			grpcCode:    codes.ResourceExhausted,
+			retryable:   true,
+		},
+		{
+			name:        "Not Found",
+			err:         Errorf(NotFound, "not found: %w", errors.New("handle is not blocked")),
+			category:    NotFound,
+			status:      http.StatusNotFound,
+			graphQLCode: "NOT_FOUND",
+			grpcCode:    codes.NotFound,
+		},
+		{
+			name:        "Transient Upstream",
+			err:         fmt.Errorf("failed to register user: %w", Wrap(Upstream, &retry.StatusError{StatusCode: http.StatusServiceUnavailable})),
+			category:    Upstream,
+			status:      http.StatusBadGateway,
+			graphQLCode: "UPSTREAM_ERROR",
+			grpcCode:    codes.Unavailable,
+			retryable:   true,
+		},
+		{
+			name:        "Rejected Upstream",
+			err:         Wrap(Upstream, &retry.StatusError{StatusCode: http.StatusForbidden}),
+			category:    Upstream,
+			status:      http.StatusBadGateway,
+			graphQLCode: "UPSTREAM_ERROR",
+			grpcCode:    codes.Unavailable,
+		},
+		{
+			name:        "Uncategorized Timeout",
+			err:         fmt.Errorf("query failed: %w", context.DeadlineExceeded),
+			category:    Upstream,
+			status:      http.StatusBadGateway,
+			graphQLCode: "UPSTREAM_ERROR",
+			grpcCode:    codes.Unavailable,
+			retryable:   true,
+		},
+		{
+			name:        "Uncategorized",
+			err:         errors.New("boom"),
+			category:    Internal,
+			status:      http.StatusInternalServerError,
+			graphQLCode: "INTERNAL_SERVER_ERROR",
+			grpcCode:    codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.category, CategoryOf(tt.err))
+			assert.Equal(t, tt.status, HTTPStatus(tt.err))
+			assert.Equal(t, tt.graphQLCode, GraphQLCode(tt.err))
+			assert.Equal(t, tt.grpcCode, GRPCCode(tt.err))
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestErrorKeepsMessage(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Errorf(Upstream, "request failed: %w", cause)
+
+	assert.EqualError(t, err, "request failed: connection refused")
+	assert.ErrorIs(t, err, cause)
+	assert.Nil(t, Wrap(Internal, nil))
+	assert.Equal(t, Category(""), CategoryOf(nil))
+}
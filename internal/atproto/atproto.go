@@ -2,24 +2,47 @@ package atproto
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
 	"github.com/ShareFrame/user-management/internal/models"
-	"github.com/sirupsen/logrus"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/tracing"
 )
 
 const (
-	CreateSessionEndpoint    = "/xrpc/com.atproto.server.createSession"
-	GetProfileEndpoint       = "/xrpc/app.bsky.actor.getProfile?actor=%s"
-	CreateInviteCodeEndpoint = "/xrpc/com.atproto.server.createInviteCode"
-	RegisterUserEndpoint     = "/xrpc/com.atproto.server.createAccount"
-	useCount                 = 1
-	timeout                  = 15 * time.Second
+	CreateSessionEndpoint     = "/xrpc/com.atproto.server.createSession"
+	GetProfileEndpoint        = "/xrpc/app.bsky.actor.getProfile?actor=%s"
+	CreateInviteCodeEndpoint  = "/xrpc/com.atproto.server.createInviteCode"
+	RegisterUserEndpoint      = "/xrpc/com.atproto.server.createAccount"
+	ResolveHandleEndpoint     = "/xrpc/com.atproto.identity.resolveHandle?handle=%s"
+	GetSessionEndpoint        = "/xrpc/com.atproto.server.getSession"
+	DeleteAccountEndpoint     = "/xrpc/com.atproto.admin.deleteAccount"
+	CreateAppPasswordEndpoint = "/xrpc/com.atproto.server.createAppPassword"
+	UpdateSubjectEndpoint     = "/xrpc/com.atproto.admin.updateSubjectStatus"
+	ListReposEndpoint         = "/xrpc/com.atproto.sync.listRepos"
+	PutRecordEndpoint         = "/xrpc/com.atproto.repo.putRecord"
+	CreateRecordEndpoint      = "/xrpc/com.atproto.repo.createRecord"
+	UploadBlobEndpoint        = "/xrpc/com.atproto.repo.uploadBlob"
+	GetBlobEndpoint           = "/xrpc/com.atproto.sync.getBlob?did=%s&cid=%s"
+	ProfileCollection         = "app.bsky.actor.profile"
+	FollowCollection          = "app.bsky.graph.follow"
+	ShareFrameCollection      = "social.shareframe.profile"
+	ProfileThemeField         = "shareframeTheme"
+	useCount                  = 1
 )
 
 type HTTPClient interface {
@@ -29,17 +52,19 @@ type HTTPClient interface {
 type ATProtocolClient struct {
 	BaseURL    string
 	HTTPClient HTTPClient
+	Retry      retry.Policy
 }
 
-func NewATProtocolClient(baseURL string, client HTTPClient) *ATProtocolClient {
+func NewATProtocolClient(baseURL string, client HTTPClient, retryPolicy retry.Policy) *ATProtocolClient {
 	return &ATProtocolClient{
 		BaseURL:    baseURL,
 		HTTPClient: client,
+		Retry:      retryPolicy,
 	}
 }
 
-func (c *ATProtocolClient) CreateSession(identifier, password string) (*models.SessionResponse, error) {
-	logrus.WithField("identifier", identifier).Info("Attempting to create session")
+func (c *ATProtocolClient) CreateSession(ctx context.Context, identifier, password string) (*models.SessionResponse, error) {
+	logging.FromContext(ctx).WithField("identifier", identifier).Info("Attempting to create session")
 
 	payload := models.SessionRequest{
 		Identifier: identifier,
@@ -48,7 +73,7 @@ func (c *ATProtocolClient) CreateSession(identifier, password string) (*models.S
 
 	data, err := json.Marshal(payload)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal session request payload")
+		logging.FromContext(ctx).WithError(err).Error("Failed to marshal session request payload")
 		return nil, fmt.Errorf("failed to marshal session request: %w", err)
 	}
 
@@ -56,121 +81,216 @@ func (c *ATProtocolClient) CreateSession(identifier, password string) (*models.S
 		"Content-Type": "application/json",
 	}
 
-	resp, err := c.doPost(CreateSessionEndpoint, data, headers)
+	resp, err := c.doPost(ctx, c.Retry, CreateSessionEndpoint, data, headers)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to execute session creation request")
-		return nil, fmt.Errorf("failed to create session request: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to execute session creation request")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to create session request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"status_code": resp.StatusCode,
 			"url":         CreateSessionEndpoint,
 		}).Error("Session creation failed")
-		return nil, fmt.Errorf("failed to create session, status code: %d", resp.StatusCode)
+		return nil, unexpectedStatus(resp, "failed to create session, status code: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to read session response body")
-		return nil, fmt.Errorf("failed to read session response: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to read session response body")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to read session response: %w", err)
 	}
 
 	var session models.SessionResponse
 	if err := json.Unmarshal(body, &session); err != nil {
-		logrus.WithError(err).Error("Failed to unmarshal session response")
-		return nil, fmt.Errorf("failed to parse session response: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to unmarshal session response")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to parse session response: %w", err)
 	}
+	logging.RegisterSecrets(session.AccessJwt)
 
-	logrus.WithField("identifier", identifier).Info("Session created successfully")
+	logging.FromContext(ctx).WithField("identifier", identifier).Info("Session created successfully")
 	return &session, nil
 }
 
-func (c *ATProtocolClient) CreateInviteCode(adminCreds models.AdminCreds) (*models.InviteCodeResponse, error) {
+func (c *ATProtocolClient) CreateInviteCode(ctx context.Context, adminCreds models.AdminCreds) (*models.InviteCodeResponse, error) {
 	data := map[string]int{"useCount": useCount}
 	body, err := json.Marshal(data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal request body for creating invite code")
+		logging.FromContext(ctx).WithError(err).Error("Failed to marshal request body for creating invite code")
 		return nil, fmt.Errorf("failed to marshal body: %w", err)
 	}
 
 	auth := base64.StdEncoding.EncodeToString([]byte(
 		adminCreds.PDSAdminUsername + ":" + adminCreds.PDSAdminPassword))
+	logging.RegisterSecrets(auth)
 	headers := map[string]string{
 		"Authorization": "Basic " + auth,
 		"Content-Type":  "application/json",
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logging.FromContext(ctx).WithFields(logging.Fields{
 		"username": adminCreds.PDSAdminUsername,
 	}).Info("Sending request to create invite code")
 
-	resp, err := c.doPost(CreateInviteCodeEndpoint, body, headers)
+	resp, err := c.doPost(ctx, c.Retry.ForWrite(), CreateInviteCodeEndpoint, body, headers)
 	if err != nil {
-		logrus.WithError(err).Error("Request failed to create invite code")
-		return nil, fmt.Errorf("request failed: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Request failed to create invite code")
+		return nil, apperr.Errorf(apperr.Upstream, "request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"status_code": resp.StatusCode,
 		}).Error("Unexpected status code when creating invite code")
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
 	}
 
 	var inviteCodeResp models.InviteCodeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&inviteCodeResp); err != nil {
-		logrus.WithError(err).Error("Failed to decode response for invite code")
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to decode response for invite code")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to decode response: %w", err)
 	}
 
-	logrus.WithField("invite_code", inviteCodeResp.Code).Info("Successfully created invite code")
+	logging.FromContext(ctx).WithField("invite_code", inviteCodeResp.Code).Info("Successfully created invite code")
 
 	return &inviteCodeResp, nil
 }
 
-func (c *ATProtocolClient) CheckUserExists(handle, token string) (bool, error) {
+func (c *ATProtocolClient) CheckUserExists(ctx context.Context, handle, token string) (bool, error) {
 	url := fmt.Sprintf(GetProfileEndpoint, handle)
-	logrus.WithField("handle", handle).Info("Checking if user exists on PDS")
+	logging.FromContext(ctx).WithField("handle", handle).Info("Checking if user exists on PDS")
 
-	req, err := http.NewRequest("GET", c.BaseURL+url, nil)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to create request for checking user existence")
-		return false, fmt.Errorf("failed to create request: %w", err)
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(ctx, c.Retry, http.MethodGet, url, nil, headers)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to check if user exists")
-		return false, fmt.Errorf("request failed: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to check if user exists")
+		return false, apperr.Errorf(apperr.Upstream, "request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
-		logrus.WithField("handle", handle).Info("User exists on PDS")
+		logging.FromContext(ctx).WithField("handle", handle).Info("User exists on PDS")
 		return true, nil
 	}
 
+	if sessionRejected(resp) {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Warn("Session rejected when checking user existence")
+		return false, apperr.Errorf(apperr.Upstream, "%w: status code %d", ErrSessionRejected, resp.StatusCode)
+	}
+
 	// Their api actually returns Bad Request if the user doesn't exist... disgusting
 	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
-		logrus.WithField("handle", handle).Info("User does not exist on PDS")
+		logging.FromContext(ctx).WithField("handle", handle).Info("User does not exist on PDS")
 		return false, nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logging.FromContext(ctx).WithFields(logging.Fields{
 		"handle":      handle,
 		"status_code": resp.StatusCode,
 	}).Error("Unexpected response when checking user existence")
-	return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return false, unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+}
+
+// ResolveHandle returns the DID a handle points at, or "" when the handle
+// does not resolve. Unlike CheckUserExists it needs no session.
+func (c *ATProtocolClient) ResolveHandle(ctx context.Context, handle string) (string, error) {
+	resp, err := c.do(ctx, c.Retry, http.MethodGet, fmt.Sprintf(ResolveHandleEndpoint, url.QueryEscape(handle)), nil, nil)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("handle", handle).Error("Failed to resolve handle")
+		return "", apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle":      handle,
+			"status_code": resp.StatusCode,
+		}).Error("Unexpected response when resolving handle")
+		return "", unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		DID string `json:"did"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", apperr.Errorf(apperr.Upstream, "failed to decode resolveHandle response: %w", err)
+	}
+	return result.DID, nil
 }
 
-func (c *ATProtocolClient) RegisterUser(handle, email, inviteCode, password string) (models.CreateUserResponse, error) {
+// GetSession returns the DID of the account whose session accessJWT
+// belongs to. It fails with ErrSessionRejected when the PDS doesn't accept
+// the token.
+func (c *ATProtocolClient) GetSession(ctx context.Context, accessJWT string) (string, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessJWT,
+	}
+
+	resp, err := c.do(ctx, c.Retry, http.MethodGet, GetSessionEndpoint, nil, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to check session")
+		return "", apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if sessionRejected(resp) {
+		return "", fmt.Errorf("%w: status code %d", ErrSessionRejected, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected response when checking session")
+		return "", unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		DID string `json:"did"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", apperr.Errorf(apperr.Upstream, "failed to decode getSession response: %w", err)
+	}
+	return result.DID, nil
+}
+
+// ListRepos returns one page of the repositories hosted on the PDS, one
+// per account. Pass the returned cursor to get the next page; it is empty
+// after the last.
+func (c *ATProtocolClient) ListRepos(ctx context.Context, cursor string, limit int) (models.RepoPage, error) {
+	query := url.Values{"limit": {fmt.Sprint(limit)}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	resp, err := c.do(ctx, c.Retry, http.MethodGet, ListReposEndpoint+"?"+query.Encode(), nil, nil)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to list repositories")
+		return models.RepoPage{}, apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected response when listing repositories")
+		return models.RepoPage{}, unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	var page models.RepoPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return models.RepoPage{}, apperr.Errorf(apperr.Upstream, "failed to decode listRepos response: %w", err)
+	}
+	return page, nil
+}
+
+// RegisterUser creates the account. did is empty for a new identity, or the
+// existing DID a custom-domain handle already points to.
+func (c *ATProtocolClient) RegisterUser(ctx context.Context, handle, email, inviteCode, password, did string) (models.CreateUserResponse, error) {
 	if handle == "" || email == "" || inviteCode == "" {
-		logrus.Warn("Missing handle, email, or invite code")
+		logging.FromContext(ctx).Warn("Missing handle, email, or invite code")
 		return models.CreateUserResponse{}, fmt.Errorf("handle, email, and inviteCode are required")
 	}
 
@@ -180,9 +300,12 @@ func (c *ATProtocolClient) RegisterUser(handle, email, inviteCode, password stri
 		"inviteCode": inviteCode,
 		"password":   password,
 	}
+	if did != "" {
+		data["did"] = did
+	}
 	body, err := json.Marshal(data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal request body for registering user")
+		logging.FromContext(ctx).WithError(err).Error("Failed to marshal request body for registering user")
 		return models.CreateUserResponse{}, fmt.Errorf("failed to marshal body: %w", err)
 	}
 
@@ -190,52 +313,423 @@ func (c *ATProtocolClient) RegisterUser(handle, email, inviteCode, password stri
 		"Content-Type": "application/json",
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"handle":     handle,
-		"email":      email,
-		"inviteCode": inviteCode,
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle":      handle,
+		"email":       email,
+		"invite_code": inviteCode,
 	}).Info("Sending request to register user")
 
-	resp, err := c.doPost(RegisterUserEndpoint, body, headers)
+	resp, err := c.doPost(ctx, c.Retry.ForWrite(), RegisterUserEndpoint, body, headers)
 	if err != nil {
-		logrus.WithError(err).Error("Request failed to register user")
-		return models.CreateUserResponse{}, fmt.Errorf("request failed: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Request failed to register user")
+		return models.CreateUserResponse{}, apperr.Errorf(apperr.Upstream, "request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"status_code": resp.StatusCode,
 		}).Error("Unexpected status code when registering user")
-		return models.CreateUserResponse{}, fmt.Errorf("unexpected status code: %s", resp.Status)
+		return models.CreateUserResponse{}, unexpectedStatus(resp, "unexpected status code: %s", resp.Status)
 	}
 
 	var registerResp models.CreateUserResponse
 	if err := json.NewDecoder(resp.Body).Decode(&registerResp); err != nil {
-		logrus.WithError(err).Error("Failed to decode response for registering user")
-		return models.CreateUserResponse{}, fmt.Errorf("failed to decode response: %w", err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to decode response for registering user")
+		return models.CreateUserResponse{}, apperr.Errorf(apperr.Upstream, "failed to decode response: %w", err)
 	}
 
-	logrus.WithField("user_id", registerResp.DID).Info("Successfully registered user")
+	logging.FromContext(ctx).WithField("user_id", registerResp.DID).Info("Successfully registered user")
 
 	return registerResp, nil
 }
 
-func (c *ATProtocolClient) doPost(endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
-	req, err := http.NewRequest("POST", c.BaseURL+endpoint, bytes.NewBuffer(body))
+// CreateAppPassword creates an app password named name on the account
+// whose session accessJWT belongs to, and returns it. The PDS only reveals
+// an app password once.
+func (c *ATProtocolClient) CreateAppPassword(ctx context.Context, accessJWT, name string) (string, error) {
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessJWT,
+		"Content-Type":  "application/json",
+	}
+
+	resp, err := c.doPost(ctx, c.Retry.ForWrite(), CreateAppPasswordEndpoint, body, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Request failed to create app password")
+		return "", apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code when creating app password")
+		return "", unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", apperr.Errorf(apperr.Upstream, "failed to decode createAppPassword response: %w", err)
+	}
+	if result.Password == "" {
+		return "", apperr.Errorf(apperr.Upstream, "createAppPassword response has no password")
+	}
+	logging.RegisterSecrets(result.Password)
+	return result.Password, nil
+}
+
+// PutProfile writes the profile record of the account whose session
+// accessJWT belongs to, replacing any profile it has.
+func (c *ATProtocolClient) PutProfile(ctx context.Context, accessJWT, did string, profile models.ProfileRecord) error {
+	record := map[string]interface{}{"$type": ProfileCollection}
+	if profile.DisplayName != "" {
+		record["displayName"] = profile.DisplayName
+	}
+	if profile.Description != "" {
+		record["description"] = profile.Description
+	}
+	if profile.Pronouns != "" {
+		record["pronouns"] = profile.Pronouns
+	}
+	if profile.Website != "" {
+		record["website"] = profile.Website
+	}
+	if profile.Avatar != nil {
+		record["avatar"] = profile.Avatar
+	}
+	if profile.Theme != nil {
+		record[ProfileThemeField] = profile.Theme
+	}
+	if len(profile.Labels) > 0 {
+		values := make([]map[string]string, len(profile.Labels))
+		for i, label := range profile.Labels {
+			values[i] = map[string]string{"val": label}
+		}
+		record["labels"] = map[string]interface{}{
+			"$type":  "com.atproto.label.defs#selfLabels",
+			"values": values,
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"repo":       did,
+		"collection": ProfileCollection,
+		"rkey":       "self",
+		"record":     record,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessJWT,
+		"Content-Type":  "application/json",
+	}
+
+	resp, err := c.doPost(ctx, c.Retry, PutRecordEndpoint, body, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Request failed to put profile")
+		return apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code when putting profile")
+		return unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Profile record written")
+	return nil
+}
+
+// Follow makes the account did, whose session accessJWT belongs to, follow
+// subject.
+func (c *ATProtocolClient) Follow(ctx context.Context, accessJWT, did, subject string) error {
+	record := map[string]string{
+		"$type":     FollowCollection,
+		"subject":   subject,
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+	}
+	return c.createRecord(ctx, accessJWT, did, FollowCollection, "", record)
+}
+
+// CreateShareFrameProfile creates the social.shareframe.profile record of
+// the account did, whose session accessJWT belongs to. Like the Bluesky
+// profile it is the repo's only record of its kind, under the key "self".
+func (c *ATProtocolClient) CreateShareFrameProfile(ctx context.Context, accessJWT, did string, profile models.ShareFrameProfile) error {
+	profile.Type = ShareFrameCollection
+	return c.createRecord(ctx, accessJWT, did, ShareFrameCollection, "self", profile)
+}
+
+// createRecord adds record to collection in the repo of did, under rkey or
+// under a key the PDS picks when rkey is empty.
+func (c *ATProtocolClient) createRecord(ctx context.Context, accessJWT, did, collection, rkey string, record interface{}) error {
+	request := map[string]interface{}{
+		"repo":       did,
+		"collection": collection,
+		"record":     record,
+	}
+	if rkey != "" {
+		request["rkey"] = rkey
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessJWT,
+		"Content-Type":  "application/json",
+	}
+
+	resp, err := c.doPost(ctx, c.Retry.ForWrite(), CreateRecordEndpoint, body, headers)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create HTTP request")
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		logging.FromContext(ctx).WithError(err).WithField("collection", collection).Error("Request failed to create record")
+		return apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"collection":  collection,
+			"status_code": resp.StatusCode,
+		}).Error("Unexpected status code when creating record")
+		return unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
 	}
+	return nil
+}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+// UploadBlob uploads data to the repo of the account whose session
+// accessJWT belongs to. The blob is only kept if a record refers to it.
+func (c *ATProtocolClient) UploadBlob(ctx context.Context, accessJWT, contentType string, data []byte) (models.BlobRef, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessJWT,
+		"Content-Type":  contentType,
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"endpoint": endpoint,
-		"headers":  headers,
-	}).Debug("Sending POST request")
+	resp, err := c.doPost(ctx, c.Retry, UploadBlobEndpoint, data, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Request failed to upload blob")
+		return models.BlobRef{}, apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
 
-	return c.HTTPClient.Do(req)
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code when uploading blob")
+		return models.BlobRef{}, unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Blob models.BlobRef `json:"blob"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return models.BlobRef{}, apperr.Errorf(apperr.Upstream, "failed to decode uploadBlob response: %w", err)
+	}
+	if result.Blob.Ref.Link == "" {
+		return models.BlobRef{}, apperr.Errorf(apperr.Upstream, "uploadBlob response has no blob")
+	}
+	return result.Blob, nil
+}
+
+// BlobURL is where the PDS serves the blob cid of the account did.
+func (c *ATProtocolClient) BlobURL(did, cid string) string {
+	return c.BaseURL + fmt.Sprintf(GetBlobEndpoint, url.QueryEscape(did), url.QueryEscape(cid))
+}
+
+// DeleteAccount removes the account and its repository from the PDS with the
+// admin credentials. An account the PDS no longer knows counts as deleted,
+// so an erasure that failed after this step can be retried.
+func (c *ATProtocolClient) DeleteAccount(ctx context.Context, adminCreds models.AdminCreds, did string) error {
+	body, err := json.Marshal(map[string]string{"did": did})
+	if err != nil {
+		return fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(
+		adminCreds.PDSAdminUsername + ":" + adminCreds.PDSAdminPassword))
+	logging.RegisterSecrets(auth)
+	headers := map[string]string{
+		"Authorization": "Basic " + auth,
+		"Content-Type":  "application/json",
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Sending request to delete account")
+
+	resp, err := c.doPost(ctx, c.Retry.ForWrite(), DeleteAccountEndpoint, body, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Request failed to delete account")
+		return apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		var xrpcErr struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&xrpcErr) == nil && strings.Contains(strings.ToLower(xrpcErr.Message), "not found") {
+			logging.FromContext(ctx).WithField("did", did).Info("Account already deleted from PDS")
+			return nil
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"did":         did,
+			"status_code": resp.StatusCode,
+		}).Error("Unexpected status code when deleting account")
+		return unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Successfully deleted account from PDS")
+	return nil
+}
+
+// TakedownAccount takes the account's repository down on the PDS with the
+// admin credentials, so it can no longer sign in or be served. The account
+// and its data are kept.
+func (c *ATProtocolClient) TakedownAccount(ctx context.Context, adminCreds models.AdminCreds, did string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"subject": map[string]string{
+			"$type": "com.atproto.admin.defs#repoRef",
+			"did":   did,
+		},
+		"takedown": map[string]bool{"applied": true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(
+		adminCreds.PDSAdminUsername + ":" + adminCreds.PDSAdminPassword))
+	logging.RegisterSecrets(auth)
+	headers := map[string]string{
+		"Authorization": "Basic " + auth,
+		"Content-Type":  "application/json",
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Sending request to take down account")
+
+	resp, err := c.doPost(ctx, c.Retry, UpdateSubjectEndpoint, body, headers)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Request failed to take down account")
+		return apperr.Errorf(apperr.Upstream, "request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"did":         did,
+			"status_code": resp.StatusCode,
+		}).Error("Unexpected status code when taking down account")
+		return unexpectedStatus(resp, "unexpected status code: %d", resp.StatusCode)
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Successfully took down account on PDS")
+	return nil
+}
+
+func (c *ATProtocolClient) doPost(ctx context.Context, policy retry.Policy, endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
+	return c.do(ctx, policy, http.MethodPost, endpoint, body, headers)
+}
+
+// do sends a request under policy: the client's retry policy for reads and
+// idempotent writes, or its ForWrite form for a call such as createAccount
+// or createInviteCode that would be done twice if a retry followed an
+// attempt the PDS had processed. Throttling and server errors are retried;
+// if every attempt fails with such a status, the last response is returned
+// so callers can report the status as before.
+func (c *ATProtocolClient) do(ctx context.Context, policy retry.Policy, method, endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
+	defer metrics.Since(metrics.FromContext(ctx), metrics.PDSLatency, time.Now())
+
+	ctx, seg := tracing.Begin(ctx, c.host(), tracing.NamespaceRemote)
+	seg.SetHTTP(method, c.BaseURL+endpoint)
+
+	var resp *http.Response
+	err := policy.Do(ctx, "atproto "+endpoint, func(ctx context.Context) error {
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, bytes.NewReader(body))
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to create HTTP request")
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		// Only the header names: the values include credentials.
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"method":       method,
+			"endpoint":     endpoint,
+			"header_names": slices.Sorted(maps.Keys(headers)),
+		}).Debug("Sending request")
+
+		r, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if retry.IsRetryableStatus(r.StatusCode) {
+			resp = r
+			return &retry.StatusError{StatusCode: r.StatusCode, Response: r}
+		}
+
+		resp = r
+		return nil
+	})
+
+	var statusErr *retry.StatusError
+	if errors.As(err, &statusErr) && statusErr.Response != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyPDS, statusErr)
+		seg.SetStatus(statusErr.Response.StatusCode)
+		seg.Close(nil)
+		return statusErr.Response, nil
+	}
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyPDS, err)
+		seg.Close(err)
+		return nil, err
+	}
+	seg.SetStatus(resp.StatusCode)
+	seg.Close(nil)
+	return resp, nil
+}
+
+// statusError is a PDS response the client can't use. It keeps the status
+// in the error chain, so a 5xx that outlasted the retry policy is still
+// classified as transient.
+type statusError struct {
+	message string
+	status  *retry.StatusError
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+func (e *statusError) Unwrap() error {
+	return e.status
+}
+
+func unexpectedStatus(resp *http.Response, format string, args ...interface{}) error {
+	return apperr.Wrap(apperr.Upstream, &statusError{
+		message: fmt.Sprintf(format, args...),
+		status:  &retry.StatusError{StatusCode: resp.StatusCode},
+	})
+}
+
+// host names the PDS node on the X-Ray service map.
+func (c *ATProtocolClient) host() string {
+	if u, err := url.Parse(c.BaseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "pds"
 }
@@ -2,12 +2,21 @@ package atproto
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"net"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
 	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/sirupsen/logrus"
 )
 
 type MockHTTPClient struct {
@@ -83,7 +92,7 @@ func TestCreateInviteCode(t *testing.T) {
 				HTTPClient: mockClient,
 			}
 
-			result, err := client.CreateInviteCode(tt.adminCreds)
+			result, err := client.CreateInviteCode(context.Background(), tt.adminCreds)
 
 			if tt.expectedError != "" {
 				if err == nil || err.Error() != tt.expectedError {
@@ -170,7 +179,7 @@ func TestRegisterUser(t *testing.T) {
 				HTTPClient: mockClient,
 			}
 
-			result, err := client.RegisterUser(tt.handle, tt.email, tt.inviteCode, tt.password)
+			result, err := client.RegisterUser(context.Background(), tt.handle, tt.email, tt.inviteCode, tt.password, "")
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -258,3 +267,755 @@ func TestCheckUserExists(t *testing.T) {
 		})
 	}
 }
+
+func TestDoRetriesServerErrors(t *testing.T) {
+	calls := 0
+	client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls < 3 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		},
+	}, retry.Policy{MaxAttempts: 3, RetryableClasses: retry.DefaultClasses})
+
+	err := client.TakedownAccount(context.Background(), models.AdminCreds{PDSAdminUsername: "admin"}, "did:plc:123")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestDoReturnsLastResponseWhenRetriesExhausted(t *testing.T) {
+	calls := 0
+	client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		},
+	}, retry.Policy{MaxAttempts: 2, RetryableClasses: retry.DefaultClasses})
+
+	err := client.TakedownAccount(context.Background(), models.AdminCreds{PDSAdminUsername: "admin"}, "did:plc:123")
+
+	if err == nil || err.Error() != "unexpected status code: 502" {
+		t.Errorf("Expected error %q, got %v", "unexpected status code: 502", err)
+	}
+	if !apperr.IsRetryable(err) {
+		t.Errorf("Expected a retryable upstream error, got category %q", apperr.CategoryOf(err))
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRegisterUserRedactsInviteCode(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.StandardLogger()
+	previousOut, previousHooks := logger.Out, logger.ReplaceHooks(make(logrus.LevelHooks))
+	logger.SetOutput(&out)
+	logger.AddHook(logging.NewRedactor(nil, nil))
+	defer func() {
+		logger.SetOutput(previousOut)
+		logger.ReplaceHooks(previousHooks)
+	}()
+
+	client := &ATProtocolClient{
+		BaseURL: "https://example.com",
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"did": "did:example:123", "handle": "user123"}`))),
+				}, nil
+			},
+		},
+	}
+
+	if _, err := client.RegisterUser(context.Background(), "user123", "user@example.com", "shareframe-app-abcde-fghij", "password", ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Sending request to register user") {
+		t.Fatalf("Expected the register request to be logged, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "shareframe-app-abcde-fghij") {
+		t.Errorf("Expected the invite code to be redacted, got %q", out.String())
+	}
+}
+
+func TestDoLogsOnlyHeaderNames(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.StandardLogger()
+	previousOut, previousLevel := logger.Out, logger.GetLevel()
+	logger.SetOutput(&out)
+	logger.SetLevel(logrus.DebugLevel)
+	defer func() {
+		logger.SetOutput(previousOut)
+		logger.SetLevel(previousLevel)
+	}()
+
+	client := &ATProtocolClient{
+		BaseURL: "https://example.com",
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"did": "did:example:123", "handle": "user123"}`))),
+				}, nil
+			},
+		},
+	}
+
+	if _, err := client.GetSession(context.Background(), "secret-access-jwt"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Authorization") {
+		t.Fatalf("Expected the header names to be logged, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "secret-access-jwt") {
+		t.Errorf("Expected no header values in the log, got %q", out.String())
+	}
+}
+
+func TestWritesOnlyRetriedWhenUnsent(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name          string
+		statuses      []int
+		dialErr       bool
+		expectedCalls int
+		expectedError string
+	}{
+		{name: "Server Error Not Retried", statuses: []int{http.StatusServiceUnavailable}, expectedCalls: 1, expectedError: "unexpected status code: 503"},
+		{name: "Throttled Retried", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, expectedCalls: 2},
+		{name: "Dial Failure Retried", dialErr: true, statuses: []int{http.StatusOK}, expectedCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					calls++
+					if tt.dialErr && calls == 1 {
+						return nil, refused
+					}
+					status := tt.statuses[0]
+					if tt.dialErr {
+						status = tt.statuses[calls-2]
+					} else if calls <= len(tt.statuses) {
+						status = tt.statuses[calls-1]
+					}
+					return &http.Response{
+						StatusCode: status,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{"code": "invite123"}`))),
+					}, nil
+				},
+			}, retry.Policy{MaxAttempts: 3, RetryableClasses: retry.DefaultClasses})
+
+			_, err := client.CreateInviteCode(context.Background(), models.AdminCreds{PDSAdminUsername: "admin"})
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestResolveHandle(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		expectedDID   string
+		expectedError string
+	}{
+		{"Handle Resolves", http.StatusOK, `{"did": "did:plc:123"}`, "did:plc:123", ""},
+		{"Handle Unknown", http.StatusBadRequest, `{"error": "InvalidRequest"}`, "", ""},
+		{"Unexpected Status", http.StatusForbidden, ``, "", "unexpected status code: 403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if got := req.URL.Query().Get("handle"); got != "alice.shareframe.social" {
+						t.Errorf("Expected handle query %q, got %q", "alice.shareframe.social", got)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			did, err := client.ResolveHandle(context.Background(), "alice.shareframe.social")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if did != tt.expectedDID {
+				t.Errorf("Expected DID %q, got %q", tt.expectedDID, did)
+			}
+		})
+	}
+}
+
+func TestGetSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		expectedDID   string
+		expectedError string
+		rejected      bool
+	}{
+		{"Session Valid", http.StatusOK, `{"did": "did:plc:123", "handle": "alice.shareframe.social"}`, "did:plc:123", "", false},
+		{"Token Expired", http.StatusBadRequest, `{"error": "ExpiredToken"}`, "", "session rejected: status code 400", true},
+		{"Token Unknown", http.StatusUnauthorized, `{"error": "AuthenticationRequired"}`, "", "session rejected: status code 401", true},
+		{"Unexpected Status", http.StatusForbidden, ``, "", "unexpected status code: 403", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if got := req.Header.Get("Authorization"); got != "Bearer access-token" {
+						t.Errorf("Expected bearer token, got %q", got)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			did, err := client.GetSession(context.Background(), "access-token")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				if IsSessionRejected(err) != tt.rejected {
+					t.Errorf("Expected IsSessionRejected %v for %v", tt.rejected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if did != tt.expectedDID {
+				t.Errorf("Expected DID %q, got %q", tt.expectedDID, did)
+			}
+		})
+	}
+}
+
+func TestListRepos(t *testing.T) {
+	tests := []struct {
+		name          string
+		cursor        string
+		statusCode    int
+		body          string
+		expectedPage  models.RepoPage
+		expectedError string
+	}{
+		{
+			name:       "First Page",
+			statusCode: http.StatusOK,
+			body:       `{"cursor": "did:plc:b", "repos": [{"did": "did:plc:a", "head": "bafy", "rev": "3k", "active": true}, {"did": "did:plc:b", "active": false}]}`,
+			expectedPage: models.RepoPage{Cursor: "did:plc:b", Repos: []models.Repo{
+				{DID: "did:plc:a", Active: true},
+				{DID: "did:plc:b"},
+			}},
+		},
+		{
+			name:         "Last Page",
+			cursor:       "did:plc:b",
+			statusCode:   http.StatusOK,
+			body:         `{"repos": []}`,
+			expectedPage: models.RepoPage{Repos: []models.Repo{}},
+		},
+		{name: "Unexpected Status", statusCode: http.StatusBadGateway, expectedError: "unexpected status code: 502"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != ListReposEndpoint {
+						t.Errorf("Expected path %q, got %q", ListReposEndpoint, req.URL.Path)
+					}
+					if got := req.URL.Query().Get("cursor"); got != tt.cursor {
+						t.Errorf("Expected cursor %q, got %q", tt.cursor, got)
+					}
+					if got := req.URL.Query().Get("limit"); got != "100" {
+						t.Errorf("Expected limit 100, got %q", got)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			page, err := client.ListRepos(context.Background(), tt.cursor, 100)
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(page, tt.expectedPage) {
+				t.Errorf("Expected page %+v, got %+v", tt.expectedPage, page)
+			}
+		})
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		expectedError string
+	}{
+		{"Deleted", http.StatusOK, ``, ""},
+		{"Already Deleted", http.StatusBadRequest, `{"error": "InvalidRequest", "message": "account not found"}`, ""},
+		{"Bad Request", http.StatusBadRequest, `{"error": "InvalidRequest", "message": "Input/did must be a valid did"}`, "unexpected status code: 400"},
+		{"Unauthorized", http.StatusUnauthorized, ``, "unexpected status code: 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != DeleteAccountEndpoint {
+						t.Errorf("Expected path %q, got %q", DeleteAccountEndpoint, req.URL.Path)
+					}
+					if user, _, ok := req.BasicAuth(); !ok || user != "admin" {
+						t.Errorf("Expected basic auth for admin, got %q", req.Header.Get("Authorization"))
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.DeleteAccount(context.Background(), models.AdminCreds{PDSAdminUsername: "admin", PDSAdminPassword: "password"}, "did:plc:123")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTakedownAccount(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedError string
+	}{
+		{"Taken Down", http.StatusOK, ""},
+		{"Unauthorized", http.StatusUnauthorized, "unexpected status code: 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != UpdateSubjectEndpoint {
+						t.Errorf("Expected path %q, got %q", UpdateSubjectEndpoint, req.URL.Path)
+					}
+					if user, _, ok := req.BasicAuth(); !ok || user != "admin" {
+						t.Errorf("Expected basic auth for admin, got %q", req.Header.Get("Authorization"))
+					}
+					var body struct {
+						Subject struct {
+							DID string `json:"did"`
+						} `json:"subject"`
+						Takedown struct {
+							Applied bool `json:"applied"`
+						} `json:"takedown"`
+					}
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Subject.DID != "did:plc:123" || !body.Takedown.Applied {
+						t.Errorf("Unexpected takedown request: %+v, %v", body, err)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.TakedownAccount(context.Background(), models.AdminCreds{PDSAdminUsername: "admin", PDSAdminPassword: "password"}, "did:plc:123")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateAppPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		expected      string
+		expectedError string
+	}{
+		{"Created", http.StatusOK, `{"name": "ShareFrame", "password": "abcd-efgh-ijkl-mnop", "createdAt": "2026-03-01T12:00:00Z"}`, "abcd-efgh-ijkl-mnop", ""},
+		{"No Password", http.StatusOK, `{"name": "ShareFrame"}`, "", "createAppPassword response has no password"},
+		{"Unauthorized", http.StatusUnauthorized, `{"error": "AuthMissing"}`, "", "unexpected status code: 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != CreateAppPasswordEndpoint {
+						t.Errorf("Expected path %q, got %q", CreateAppPasswordEndpoint, req.URL.Path)
+					}
+					if auth := req.Header.Get("Authorization"); auth != "Bearer access-jwt" {
+						t.Errorf("Expected bearer auth, got %q", auth)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			password, err := client.CreateAppPassword(context.Background(), "access-jwt", "ShareFrame")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if password != tt.expected {
+				t.Errorf("Expected password %q, got %q", tt.expected, password)
+			}
+		})
+	}
+}
+
+func TestPutProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedError string
+	}{
+		{"Written", http.StatusOK, ""},
+		{"Unauthorized", http.StatusUnauthorized, "unexpected status code: 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != PutRecordEndpoint {
+						t.Errorf("Expected path %q, got %q", PutRecordEndpoint, req.URL.Path)
+					}
+					if auth := req.Header.Get("Authorization"); auth != "Bearer access-jwt" {
+						t.Errorf("Expected bearer auth, got %q", auth)
+					}
+					body, _ := io.ReadAll(req.Body)
+					expected := `{"collection":"app.bsky.actor.profile","record":{"$type":"app.bsky.actor.profile",` +
+						`"description":"Coffee roasters","displayName":"Acme","labels":{"$type":"com.atproto.label.defs#selfLabels","values":[{"val":"organization"}]},` +
+						`"website":"https://acme.example"},` +
+						`"repo":"did:plc:acme","rkey":"self"}`
+					if string(body) != expected {
+						t.Errorf("Expected body %s, got %s", expected, body)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.PutProfile(context.Background(), "access-jwt", "did:plc:acme", models.ProfileRecord{
+				DisplayName: "Acme",
+				Description: "Coffee roasters",
+				Website:     "https://acme.example",
+				Labels:      []string{models.LabelOrganization},
+			})
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPutProfileAvatar(t *testing.T) {
+	client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			expected := `{"collection":"app.bsky.actor.profile","record":{"$type":"app.bsky.actor.profile",` +
+				`"avatar":{"$type":"blob","ref":{"$link":"bafkrei"},"mimeType":"image/png","size":42},"displayName":"Alice"},` +
+				`"repo":"did:plc:alice","rkey":"self"}`
+			if string(body) != expected {
+				t.Errorf("Expected body %s, got %s", expected, body)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			}, nil
+		},
+	}, retry.Policy{})
+
+	err := client.PutProfile(context.Background(), "access-jwt", "did:plc:alice", models.ProfileRecord{
+		DisplayName: "Alice",
+		Avatar:      &models.BlobRef{Type: "blob", Ref: models.BlobLink{Link: "bafkrei"}, MimeType: "image/png", Size: 42},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestUploadBlob(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		responseBody  string
+		expectedError string
+	}{
+		{"Uploaded", http.StatusOK, `{"blob":{"$type":"blob","ref":{"$link":"bafkrei"},"mimeType":"image/png","size":4}}`, ""},
+		{"Too Large", http.StatusBadRequest, `{"error":"BlobTooLarge"}`, "unexpected status code: 400"},
+		{"No Blob", http.StatusOK, `{}`, "uploadBlob response has no blob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != UploadBlobEndpoint {
+						t.Errorf("Expected path %q, got %q", UploadBlobEndpoint, req.URL.Path)
+					}
+					if contentType := req.Header.Get("Content-Type"); contentType != "image/png" {
+						t.Errorf("Expected content type image/png, got %q", contentType)
+					}
+					if body, _ := io.ReadAll(req.Body); string(body) != "\x89PNG" {
+						t.Errorf("Expected the image as the body, got %q", body)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(tt.responseBody))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			blob, err := client.UploadBlob(context.Background(), "access-jwt", "image/png", []byte("\x89PNG"))
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if blob.Ref.Link != "bafkrei" || blob.Size != 4 {
+				t.Errorf("Unexpected blob %+v", blob)
+			}
+		})
+	}
+}
+
+func TestBlobURL(t *testing.T) {
+	client := NewATProtocolClient("https://pds.example.com", nil, retry.Policy{})
+
+	got := client.BlobURL("did:plc:alice", "bafkrei")
+
+	expected := "https://pds.example.com/xrpc/com.atproto.sync.getBlob?did=did%3Aplc%3Aalice&cid=bafkrei"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestPutProfileTheme(t *testing.T) {
+	client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			expected := `{"collection":"app.bsky.actor.profile","record":{"$type":"app.bsky.actor.profile","displayName":"Alice",` +
+				`"shareframeTheme":{"settings":{"mode":"dark"},"primaryColor":"#112233","secondaryColor":"#445566"}},` +
+				`"repo":"did:plc:alice","rkey":"self"}`
+			if string(body) != expected {
+				t.Errorf("Expected body %s, got %s", expected, body)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			}, nil
+		},
+	}, retry.Policy{})
+
+	err := client.PutProfile(context.Background(), "access-jwt", "did:plc:alice", models.ProfileRecord{
+		DisplayName: "Alice",
+		Theme:       &models.ProfileTheme{Settings: []byte(`{"mode":"dark"}`), PrimaryColor: "#112233", SecondaryColor: "#445566"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedError string
+	}{
+		{"Followed", http.StatusOK, ""},
+		{"Unknown Subject", http.StatusBadRequest, "unexpected status code: 400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != CreateRecordEndpoint {
+						t.Errorf("Expected path %q, got %q", CreateRecordEndpoint, req.URL.Path)
+					}
+					var body struct {
+						Repo       string            `json:"repo"`
+						Collection string            `json:"collection"`
+						Record     map[string]string `json:"record"`
+					}
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						t.Fatalf("Failed to decode body: %v", err)
+					}
+					if body.Repo != "did:plc:alice" || body.Collection != FollowCollection ||
+						body.Record["$type"] != FollowCollection || body.Record["subject"] != "did:plc:team" || body.Record["createdAt"] == "" {
+						t.Errorf("Unexpected follow record %+v", body)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.Follow(context.Background(), "access-jwt", "did:plc:alice", "did:plc:team")
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateShareFrameProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedError string
+	}{
+		{"Created", http.StatusOK, ""},
+		{"Already Exists", http.StatusBadRequest, "unexpected status code: 400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewATProtocolClient("https://example.com", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != CreateRecordEndpoint {
+						t.Errorf("Expected path %q, got %q", CreateRecordEndpoint, req.URL.Path)
+					}
+					body, _ := io.ReadAll(req.Body)
+					expected := `{"collection":"social.shareframe.profile","record":{"$type":"social.shareframe.profile",` +
+						`"theme":{"mode":"dark"},"primaryColor":"#112233","secondaryColor":"#445566","banner":"https://cdn.example.com/banner.png",` +
+						`"createdAt":"2026-03-01T12:00:00Z"},"repo":"did:plc:alice","rkey":"self"}`
+					if string(body) != expected {
+						t.Errorf("Expected body %s, got %s", expected, body)
+					}
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+					}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.CreateShareFrameProfile(context.Background(), "access-jwt", "did:plc:alice", models.ShareFrameProfile{
+				Theme:          []byte(`{"mode":"dark"}`),
+				PrimaryColor:   "#112233",
+				SecondaryColor: "#445566",
+				Banner:         "https://cdn.example.com/banner.png",
+				CreatedAt:      "2026-03-01T12:00:00Z",
+			})
+
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("Expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
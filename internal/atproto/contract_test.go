@@ -0,0 +1,240 @@
+package atproto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+// The contract tests run the client against a real PDS, to catch it
+// drifting from what the client assumes: the lexicon fields our models
+// read, and quirks such as getProfile answering a missing account with 400.
+// They create and delete accounts, so they only run when
+// PDS_CONTRACT_URL names a disposable PDS:
+//
+//	PDS_CONTRACT_URL=http://localhost:2583 \
+//	PDS_CONTRACT_ADMIN_PASSWORD=admin-password \
+//	PDS_CONTRACT_HANDLE_DOMAIN=.test \
+//	go test ./internal/atproto -run Contract -v
+//
+// PDS_CONTRACT_ADMIN_USERNAME defaults to "admin" and
+// PDS_CONTRACT_HANDLE_DOMAIN to ".test", the PDS's default.
+
+// lexiconOutputs are the fields our response models read from each
+// method's output. The lexicons mark all of them required.
+var lexiconOutputs = map[string][]string{
+	CreateInviteCodeEndpoint: {"code"},
+	RegisterUserEndpoint:     {"accessJwt", "refreshJwt", "handle", "did"},
+	CreateSessionEndpoint:    {"accessJwt", "handle", "did"},
+}
+
+type contractPDS struct {
+	baseURL    string
+	adminCreds models.AdminCreds
+	domain     string
+	client     *ATProtocolClient
+}
+
+func newContractPDS(t *testing.T) *contractPDS {
+	baseURL := os.Getenv("PDS_CONTRACT_URL")
+	if baseURL == "" {
+		t.Skip("PDS_CONTRACT_URL is not set; skipping contract tests against a real PDS")
+	}
+
+	username := os.Getenv("PDS_CONTRACT_ADMIN_USERNAME")
+	if username == "" {
+		username = "admin"
+	}
+	domain := os.Getenv("PDS_CONTRACT_HANDLE_DOMAIN")
+	if domain == "" {
+		domain = ".test"
+	}
+
+	return &contractPDS{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		adminCreds: models.AdminCreds{PDSAdminUsername: username, PDSAdminPassword: os.Getenv("PDS_CONTRACT_ADMIN_PASSWORD")},
+		domain:     domain,
+		client:     NewATProtocolClient(strings.TrimSuffix(baseURL, "/"), &http.Client{Timeout: 30 * time.Second}, retry.Policy{}),
+	}
+}
+
+// newAccount returns a fresh handle, email and password.
+func (p *contractPDS) newAccount(t *testing.T) (string, string, string) {
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatal(err)
+	}
+	id := hex.EncodeToString(suffix)
+	return "ct" + id + p.domain, "contract+" + id + "@example.com", "contract-" + id + "-password"
+}
+
+// post sends a raw XRPC call and decodes the response into a map, so the
+// fields can be checked against the lexicon independently of our models.
+func (p *contractPDS) post(t *testing.T, endpoint string, body interface{}, basicAuth bool) (int, map[string]interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, p.baseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if basicAuth {
+		req.SetBasicAuth(p.adminCreds.PDSAdminUsername, p.adminCreds.PDSAdminPassword)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var output map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&output)
+	return resp.StatusCode, output
+}
+
+func assertLexiconOutput(t *testing.T, endpoint string, output map[string]interface{}, model interface{}) {
+	for _, field := range lexiconOutputs[endpoint] {
+		assert.Contains(t, output, field, "%s output is missing required field %s", endpoint, field)
+	}
+
+	// Round-trip the raw output through our model: every required field
+	// must survive, or the model's JSON tags have drifted.
+	data, _ := json.Marshal(output)
+	assert.NoError(t, json.Unmarshal(data, model))
+	encoded, _ := json.Marshal(model)
+	var decoded map[string]interface{}
+	json.Unmarshal(encoded, &decoded)
+	for _, field := range lexiconOutputs[endpoint] {
+		assert.Equal(t, output[field], decoded[field], "%s field %s does not round-trip through %T", endpoint, field, model)
+	}
+}
+
+func TestContractLexiconOutputs(t *testing.T) {
+	pds := newContractPDS(t)
+	ctx := context.Background()
+	handle, email, password := pds.newAccount(t)
+
+	status, invite := pds.post(t, CreateInviteCodeEndpoint, map[string]int{"useCount": useCount}, true)
+	if !assert.Equal(t, http.StatusOK, status, "createInviteCode: %v", invite) {
+		return
+	}
+	assertLexiconOutput(t, CreateInviteCodeEndpoint, invite, &models.InviteCodeResponse{})
+
+	status, account := pds.post(t, RegisterUserEndpoint, map[string]string{
+		"handle":     handle,
+		"email":      email,
+		"password":   password,
+		"inviteCode": invite["code"].(string),
+	}, false)
+	if !assert.Equal(t, http.StatusOK, status, "createAccount: %v", account) {
+		return
+	}
+	did, _ := account["did"].(string)
+	defer func() {
+		assert.NoError(t, pds.client.DeleteAccount(ctx, pds.adminCreds, did))
+	}()
+	assertLexiconOutput(t, RegisterUserEndpoint, account, &models.CreateUserResponse{})
+
+	status, session := pds.post(t, CreateSessionEndpoint, map[string]string{"identifier": handle, "password": password}, false)
+	if assert.Equal(t, http.StatusOK, status, "createSession: %v", session) {
+		assertLexiconOutput(t, CreateSessionEndpoint, session, &models.SessionResponse{})
+	}
+}
+
+func TestContractAccountLifecycle(t *testing.T) {
+	pds := newContractPDS(t)
+	ctx := context.Background()
+	handle, email, password := pds.newAccount(t)
+
+	invite, err := pds.client.CreateInviteCode(ctx, pds.adminCreds)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user, err := pds.client.RegisterUser(ctx, handle, email, invite.Code, password, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(user.DID, "did:"), "unexpected DID %q", user.DID)
+	assert.Equal(t, handle, user.Handle)
+	assert.NotEmpty(t, user.AccessJWT)
+	assert.NotEmpty(t, user.RefreshJWT)
+
+	// The invite code is single-use.
+	other, otherEmail, _ := pds.newAccount(t)
+	_, err = pds.client.RegisterUser(ctx, other, otherEmail, invite.Code, password, "")
+	assert.Error(t, err)
+
+	session, err := pds.client.CreateSession(ctx, handle, password)
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.DID, session.Did)
+	}
+
+	did, err := pds.client.ResolveHandle(ctx, handle)
+	assert.NoError(t, err)
+	assert.Equal(t, user.DID, did)
+
+	assert.NoError(t, pds.client.DeleteAccount(ctx, pds.adminCreds, user.DID))
+	// Deleting it again succeeds, because the PDS says the account is not
+	// found; erasure retries depend on that.
+	assert.NoError(t, pds.client.DeleteAccount(ctx, pds.adminCreds, user.DID))
+
+	did, err = pds.client.ResolveHandle(ctx, handle)
+	assert.NoError(t, err)
+	assert.Empty(t, did)
+}
+
+func TestContractMissingProfile(t *testing.T) {
+	pds := newContractPDS(t)
+	ctx := context.Background()
+	handle, email, password := pds.newAccount(t)
+
+	invite, err := pds.client.CreateInviteCode(ctx, pds.adminCreds)
+	if !assert.NoError(t, err) {
+		return
+	}
+	user, err := pds.client.RegisterUser(ctx, handle, email, invite.Code, password, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, pds.client.DeleteAccount(ctx, pds.adminCreds, user.DID))
+	}()
+
+	missing, _, _ := pds.newAccount(t)
+	req, _ := http.NewRequest(http.MethodGet, pds.baseURL+"/xrpc/app.bsky.actor.getProfile?actor="+missing, nil)
+	req.Header.Set("Authorization", "Bearer "+user.AccessJWT)
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		t.Skipf("getProfile returned %d; the PDS has no AppView to proxy to", resp.StatusCode)
+	}
+
+	// CheckUserExists treats 400 as "no such account" because that is what
+	// the PDS sends; if this changes, so must it.
+	assert.Contains(t, []int{http.StatusBadRequest, http.StatusNotFound}, resp.StatusCode)
+	exists, err := pds.client.CheckUserExists(ctx, missing, user.AccessJWT)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = pds.client.CheckUserExists(ctx, handle, user.AccessJWT)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,110 @@
+package atproto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+// ErrSessionRejected is returned when the PDS no longer accepts a session's
+// access token, because it expired or was revoked.
+var ErrSessionRejected = errors.New("session rejected")
+
+// xrpcError is the body the PDS sends with a failed call.
+type xrpcError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// sessionRejected reports whether resp turns down the access token it was
+// sent. The PDS answers an expired token with 400 ExpiredToken rather than
+// 401, so the body of a 400 is read to tell the two apart.
+func sessionRejected(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusBadRequest:
+		var body xrpcError
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return false
+		}
+		return body.Error == "ExpiredToken" || body.Error == "InvalidToken"
+	}
+	return false
+}
+
+// IsSessionRejected reports whether err came from a call the PDS refused
+// the session for.
+func IsSessionRejected(err error) bool {
+	if errors.Is(err, ErrSessionRejected) {
+		return true
+	}
+	var status *retry.StatusError
+	return errors.As(err, &status) && status.StatusCode == http.StatusUnauthorized
+}
+
+// SessionCache keeps the sessions of service accounts between invocations,
+// so a warm container signs in once rather than for every signup. Sessions
+// are kept per PDS and identifier. The zero value is ready to use.
+type SessionCache struct {
+	mu       sync.Mutex
+	sessions map[string]*models.SessionResponse
+}
+
+// Session returns the cached session of identifier on c's PDS, signing in
+// when there is none. Callers wait on a sign-in already under way instead
+// of starting their own.
+func (s *SessionCache) Session(ctx context.Context, c *ATProtocolClient, identifier, password string) (*models.SessionResponse, error) {
+	key := c.BaseURL + " " + identifier
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[key]; ok {
+		return session, nil
+	}
+	session, err := c.CreateSession(ctx, identifier, password)
+	if err != nil {
+		return nil, err
+	}
+	if s.sessions == nil {
+		s.sessions = map[string]*models.SessionResponse{}
+	}
+	s.sessions[key] = session
+	return session, nil
+}
+
+// Invalidate forgets session, so the next call signs in again. A session
+// that has already been replaced is left alone.
+func (s *SessionCache) Invalidate(c *ATProtocolClient, identifier string, session *models.SessionResponse) {
+	key := c.BaseURL + " " + identifier
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions[key] == session {
+		delete(s.sessions, key)
+	}
+}
+
+// With runs fn with the access token of identifier's session. When the PDS
+// rejects the cached session, it is dropped and fn runs once more with a
+// fresh one.
+func (s *SessionCache) With(ctx context.Context, c *ATProtocolClient, identifier, password string, fn func(accessJWT string) error) error {
+	session, err := s.Session(ctx, c, identifier, password)
+	if err != nil {
+		return err
+	}
+	err = fn(session.AccessJwt)
+	if !IsSessionRejected(err) {
+		return err
+	}
+	logging.FromContext(ctx).WithError(err).WithField("identifier", identifier).Info("Cached session rejected, signing in again")
+	s.Invalidate(c, identifier, session)
+	if session, err = s.Session(ctx, c, identifier, password); err != nil {
+		return err
+	}
+	return fn(session.AccessJwt)
+}
@@ -0,0 +1,140 @@
+package atproto
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+// sessionPDS signs in with a numbered token each time and accepts only the
+// latest one on getProfile.
+type sessionPDS struct {
+	signIns int
+	// rejection is what getProfile answers a stale token with.
+	rejection *http.Response
+}
+
+func (p *sessionPDS) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "createSession") {
+		p.signIns++
+		body := fmt.Sprintf(`{"accessJwt": "token-%d", "did": "did:plc:util"}`, p.signIns)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+	if req.Header.Get("Authorization") == fmt.Sprintf("Bearer token-%d", p.signIns) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	return p.rejection, nil
+}
+
+func TestSessionCacheReusesSession(t *testing.T) {
+	pds := &sessionPDS{}
+	client := NewATProtocolClient("https://pds.example.com", pds, retry.Policy{})
+	cache := &SessionCache{}
+
+	for i := 0; i < 3; i++ {
+		session, err := cache.Session(context.Background(), client, "util", "password")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if session.AccessJwt != "token-1" {
+			t.Errorf("Expected token-1, got %q", session.AccessJwt)
+		}
+	}
+	if pds.signIns != 1 {
+		t.Errorf("Expected 1 sign-in, got %d", pds.signIns)
+	}
+
+	// Another PDS has its own session.
+	other := NewATProtocolClient("https://other.example.com", pds, retry.Policy{})
+	if _, err := cache.Session(context.Background(), other, "util", "password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if pds.signIns != 2 {
+		t.Errorf("Expected 2 sign-ins, got %d", pds.signIns)
+	}
+}
+
+func TestSessionCacheWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		rejection *http.Response
+	}{
+		{
+			name:      "Unauthorized",
+			rejection: &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(`{"error": "AuthMissing"}`))},
+		},
+		{
+			name:      "Expired Token",
+			rejection: &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(`{"error": "ExpiredToken", "message": "Token has expired"}`))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pds := &sessionPDS{}
+			client := NewATProtocolClient("https://pds.example.com", pds, retry.Policy{})
+			cache := &SessionCache{}
+			if _, err := cache.Session(context.Background(), client, "util", "password"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			// The PDS has moved on from the cached token.
+			pds.signIns++
+			pds.rejection = tt.rejection
+
+			var exists bool
+			err := cache.With(context.Background(), client, "util", "password", func(accessJWT string) error {
+				var err error
+				exists, err = client.CheckUserExists(context.Background(), "alice.shareframe.social", accessJWT)
+				return err
+			})
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !exists {
+				t.Errorf("Expected the check to run again with a fresh session")
+			}
+			if pds.signIns != 3 {
+				t.Errorf("Expected a second sign-in, got %d sign-ins", pds.signIns)
+			}
+		})
+	}
+}
+
+func TestSessionCacheInvalidateKeepsReplacedSession(t *testing.T) {
+	pds := &sessionPDS{}
+	client := NewATProtocolClient("https://pds.example.com", pds, retry.Policy{})
+	cache := &SessionCache{}
+
+	stale, _ := cache.Session(context.Background(), client, "util", "password")
+	cache.Invalidate(client, "util", stale)
+	fresh, _ := cache.Session(context.Background(), client, "util", "password")
+	// A second caller that held the stale session doesn't drop the fresh one.
+	cache.Invalidate(client, "util", stale)
+	current, _ := cache.Session(context.Background(), client, "util", "password")
+
+	if current != fresh {
+		t.Errorf("Expected the fresh session to stay cached")
+	}
+	if pds.signIns != 2 {
+		t.Errorf("Expected 2 sign-ins, got %d", pds.signIns)
+	}
+}
+
+func TestIsSessionRejected(t *testing.T) {
+	if !IsSessionRejected(unexpectedStatus(&http.Response{StatusCode: http.StatusUnauthorized}, "status %d", 401)) {
+		t.Errorf("Expected a 401 to reject the session")
+	}
+	if IsSessionRejected(unexpectedStatus(&http.Response{StatusCode: http.StatusInternalServerError}, "status %d", 500)) {
+		t.Errorf("Expected a 500 not to reject the session")
+	}
+	if !IsSessionRejected(fmt.Errorf("check failed: %w", ErrSessionRejected)) {
+		t.Errorf("Expected ErrSessionRejected to reject the session")
+	}
+}
@@ -0,0 +1,168 @@
+// Package avatar lets a new account upload its profile picture straight to
+// S3 and applies the picture to the account once it is there.
+package avatar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type ObjectStore interface {
+	GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+type Presigner interface {
+	PresignPutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// PDS stores the picture in the account's repo and points its profile at
+// it.
+type PDS interface {
+	UploadBlob(ctx context.Context, accessJWT, contentType string, data []byte) (models.BlobRef, error)
+	PutProfile(ctx context.Context, accessJWT, did string, profile models.ProfileRecord) error
+	BlobURL(did, cid string) string
+}
+
+// contentTypes are the picture formats the PDS accepts for avatars.
+var contentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+}
+
+// Uploads hands out upload links and applies what was uploaded through
+// them. Each account has one upload key, so a new link replaces a picture
+// that was never applied.
+type Uploads struct {
+	Accounts  postgres.AvatarStore
+	Objects   ObjectStore
+	Presigner Presigner
+	PDS       PDS
+	Bucket    string
+	// URLTTL is how long an upload link stays valid.
+	URLTTL time.Duration
+	// MaxBytes bounds the size of a picture.
+	MaxBytes int
+	now      func() time.Time
+}
+
+// Key is the object an account's picture is uploaded to.
+func Key(tenant, did string) string {
+	return fmt.Sprintf("avatars/%s/%s", tenant, did)
+}
+
+// Presign returns a link the account did can PUT its picture to.
+func (u *Uploads) Presign(ctx context.Context, tenant, did string) (models.AvatarUpload, error) {
+	presigned, err := u.Presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(Key(tenant, did)),
+	}, s3.WithPresignExpires(u.URLTTL))
+	if err != nil {
+		return models.AvatarUpload{}, fmt.Errorf("failed to presign avatar upload: %w", err)
+	}
+	return models.AvatarUpload{URL: presigned.URL, ExpiresAt: u.clock().Add(u.URLTTL), MaxBytes: u.MaxBytes}, nil
+}
+
+// Complete pushes the picture uploaded for did to the PDS as the account's
+// avatar and stores its URL as the profile picture. The profile record is
+// written whole, so it is meant to follow signup before the account has
+// edited its profile elsewhere.
+func (u *Uploads) Complete(ctx context.Context, tenant, did, accessJWT string) (models.AvatarResponse, error) {
+	user, err := u.Accounts.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return models.AvatarResponse{}, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return models.AvatarResponse{}, fmt.Errorf("failed to apply avatar: %w", err)
+	}
+
+	key := Key(tenant, did)
+	data, err := u.read(ctx, key)
+	if err != nil {
+		return models.AvatarResponse{}, err
+	}
+	contentType := http.DetectContentType(data)
+	if !contentTypes[contentType] {
+		return models.AvatarResponse{}, apperr.Errorf(apperr.Validation, "validation error: avatar must be a PNG or JPEG image, not %s", contentType)
+	}
+
+	blob, err := u.PDS.UploadBlob(ctx, accessJWT, contentType, data)
+	if err != nil {
+		return models.AvatarResponse{}, fmt.Errorf("failed to upload avatar: %w", err)
+	}
+	profile := models.ProfileRecord{Labels: models.ProfileLabels(user.AccountType), Avatar: &blob}
+	if user.Onboarding != "" {
+		// Keep the theme onboarding wrote to the profile.
+		profile.Theme = user.ProfileTheme()
+	}
+	if user.DisplayName != user.Handle {
+		// The stored display name falls back to the handle, which the
+		// profile doesn't need to repeat.
+		profile.DisplayName = user.DisplayName
+	}
+	if err := u.PDS.PutProfile(ctx, accessJWT, did, profile); err != nil {
+		return models.AvatarResponse{}, fmt.Errorf("failed to apply avatar: %w", err)
+	}
+
+	pictureURL := u.PDS.BlobURL(did, blob.Ref.Link)
+	if err := u.Accounts.SetProfilePicture(ctx, did, pictureURL); err != nil {
+		return models.AvatarResponse{}, fmt.Errorf("failed to store profile picture: %w", err)
+	}
+
+	// The PDS has its own copy now.
+	if _, err := u.Objects.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("key", key).Warn("Failed to delete applied avatar upload")
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Avatar applied")
+	return models.AvatarResponse{ProfilePicture: pictureURL}, nil
+}
+
+// read returns the uploaded picture, refusing one over MaxBytes.
+func (u *Uploads) read(ctx context.Context, key string) ([]byte, error) {
+	object, err := u.Objects.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: no avatar has been uploaded")
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("key", key).Error("Failed to read avatar upload")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to read avatar upload: %w", err)
+	}
+	defer object.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(object.Body, int64(u.MaxBytes)+1))
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Upstream, "failed to read avatar upload: %w", err)
+	}
+	if len(data) > u.MaxBytes {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: avatar exceeds %d bytes", u.MaxBytes)
+	}
+	return data, nil
+}
+
+func (u *Uploads) clock() time.Time {
+	if u.now != nil {
+		return u.now()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,176 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/memory"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+// fakeS3 serves one uploaded object and presigns a fixed URL.
+type fakeS3 struct {
+	objects map[string][]byte
+	getErr  error
+	expires time.Duration
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	data, ok := f.objects[aws.ToString(input.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.ToString(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) PresignPutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	var options s3.PresignOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	f.expires = options.Expires
+	return &v4.PresignedHTTPRequest{URL: "https://avatars.example.com/" + aws.ToString(input.Key) + "?X-Amz-Signature=abc"}, nil
+}
+
+type mockPDS struct {
+	mock.Mock
+}
+
+func (m *mockPDS) UploadBlob(ctx context.Context, accessJWT, contentType string, data []byte) (models.BlobRef, error) {
+	args := m.Called(ctx, accessJWT, contentType, data)
+	return args.Get(0).(models.BlobRef), args.Error(1)
+}
+
+func (m *mockPDS) PutProfile(ctx context.Context, accessJWT, did string, profile models.ProfileRecord) error {
+	return m.Called(ctx, accessJWT, did, profile).Error(0)
+}
+
+func (m *mockPDS) BlobURL(did, cid string) string {
+	return "https://pds.example.com/blob/" + did + "/" + cid
+}
+
+var alice = models.UserRecord{DID: "did:plc:alice", Email: "alice@example.com", Handle: "alice.shareframe.social", DisplayName: "Alice", AccountType: models.AccountTypePerson}
+
+func TestPresign(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	objects := &fakeS3{}
+	uploads := &Uploads{Presigner: objects, Bucket: "avatars", URLTTL: 15 * time.Minute, MaxBytes: 1000, now: func() time.Time { return now }}
+
+	upload, err := uploads.Presign(context.Background(), "shareframe", alice.DID)
+
+	assert.NoError(t, err)
+	assert.Contains(t, upload.URL, Key("shareframe", alice.DID))
+	assert.Equal(t, now.Add(15*time.Minute), upload.ExpiresAt)
+	assert.Equal(t, 1000, upload.MaxBytes)
+	assert.Equal(t, 15*time.Minute, objects.expires)
+}
+
+func TestComplete(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+	objects := &fakeS3{objects: map[string][]byte{Key("shareframe", alice.DID): png}}
+	blob := models.BlobRef{Type: "blob", Ref: models.BlobLink{Link: "bafkrei"}, MimeType: "image/png", Size: len(png)}
+	pds := new(mockPDS)
+	pds.On("UploadBlob", ctx, "access-jwt", "image/png", png).Return(blob, nil)
+	pds.On("PutProfile", ctx, "access-jwt", alice.DID, models.ProfileRecord{DisplayName: "Alice", Avatar: &blob}).Return(nil)
+
+	uploads := &Uploads{Accounts: store, Objects: objects, PDS: pds, Bucket: "avatars", MaxBytes: 1000}
+	resp, err := uploads.Complete(ctx, "shareframe", alice.DID, "access-jwt")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://pds.example.com/blob/did:plc:alice/bafkrei", resp.ProfilePicture)
+	record, _ := store.GetUser(ctx, alice.DID)
+	assert.Equal(t, resp.ProfilePicture, record.ProfilePicture)
+	assert.Empty(t, objects.objects)
+	pds.AssertExpectations(t)
+}
+
+func TestCompleteKeepsSeededProfile(t *testing.T) {
+	ctx := context.Background()
+	org := models.UserRecord{
+		DID:          "did:plc:acme",
+		Email:        "team@acme.example",
+		Handle:       "acme.shareframe.social",
+		DisplayName:  "acme.shareframe.social",
+		PrimaryColor: "#112233",
+		AccountType:  models.AccountTypeOrganization,
+		Onboarding:   models.OnboardingSeeded,
+	}
+	store := memory.New()
+	assert.NoError(t, store.StoreUser(ctx, org))
+	objects := &fakeS3{objects: map[string][]byte{Key("shareframe", org.DID): png}}
+	blob := models.BlobRef{Type: "blob", Ref: models.BlobLink{Link: "bafkrei"}, MimeType: "image/png", Size: len(png)}
+	pds := new(mockPDS)
+	pds.On("UploadBlob", ctx, "access-jwt", "image/png", png).Return(blob, nil)
+	// The display name is only the handle fallback, so it is left off.
+	pds.On("PutProfile", ctx, "access-jwt", org.DID, models.ProfileRecord{
+		Labels: []string{models.LabelOrganization},
+		Avatar: &blob,
+		Theme:  &models.ProfileTheme{PrimaryColor: "#112233"},
+	}).Return(nil)
+
+	uploads := &Uploads{Accounts: store, Objects: objects, PDS: pds, Bucket: "avatars", MaxBytes: 1000}
+	_, err := uploads.Complete(ctx, "shareframe", org.DID, "access-jwt")
+
+	assert.NoError(t, err)
+	pds.AssertExpectations(t)
+}
+
+func TestCompleteFailures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		did      string
+		object   []byte
+		getErr   error
+		category apperr.Category
+	}{
+		{name: "Unknown Account", did: "did:plc:nobody", object: png, category: apperr.NotFound},
+		{name: "Nothing Uploaded", did: alice.DID, category: apperr.NotFound},
+		{name: "Too Large", did: alice.DID, object: append(png, make([]byte, 1000)...), category: apperr.Validation},
+		{name: "Not An Image", did: alice.DID, object: []byte("<html></html>"), category: apperr.Validation},
+		{name: "Read Failed", did: alice.DID, getErr: errors.New("AccessDenied"), category: apperr.Upstream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			assert.NoError(t, store.StoreUser(ctx, alice))
+			objects := &fakeS3{objects: map[string][]byte{}, getErr: tt.getErr}
+			if tt.object != nil {
+				objects.objects[Key("shareframe", tt.did)] = tt.object
+			}
+			pds := new(mockPDS)
+
+			uploads := &Uploads{Accounts: store, Objects: objects, PDS: pds, Bucket: "avatars", MaxBytes: 1000}
+			_, err := uploads.Complete(ctx, "shareframe", tt.did, "access-jwt")
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.category, apperr.CategoryOf(err))
+			pds.AssertNotCalled(t, "UploadBlob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package billing creates the billing provider's records for new accounts,
+// so paid features can find a customer for every account without a
+// backfill, and deletes them when an account is erased.
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const StripeCustomersEndpoint = "https://api.stripe.com/v1/customers"
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Customer is what a customer is created from. DID is stored in the
+// customer's metadata so the two can always be matched up.
+type Customer struct {
+	DID    string
+	Email  string
+	Handle string
+	Tenant string
+}
+
+type StripeClient struct {
+	SecretKey  string
+	Endpoint   string
+	HTTPClient HTTPClient
+	Retry      retry.Policy
+}
+
+func NewStripeClient(secretKey string, client HTTPClient, retryPolicy retry.Policy) *StripeClient {
+	return &StripeClient{
+		SecretKey:  secretKey,
+		Endpoint:   StripeCustomersEndpoint,
+		HTTPClient: client,
+		Retry:      retryPolicy,
+	}
+}
+
+// CreateCustomer creates a Stripe customer for the account and returns its
+// ID. The idempotency key is derived from the DID, so retries, including a
+// re-driven signup within Stripe's 24 hour window, return the same customer
+// instead of creating another.
+func (c *StripeClient) CreateCustomer(ctx context.Context, customer Customer) (string, error) {
+	form := url.Values{}
+	form.Set("email", customer.Email)
+	form.Set("description", customer.Handle)
+	form.Set("metadata[did]", customer.DID)
+	form.Set("metadata[handle]", customer.Handle)
+	if customer.Tenant != "" {
+		form.Set("metadata[tenant]", customer.Tenant)
+	}
+	body := form.Encode()
+
+	var id string
+	err := c.Retry.Do(ctx, "stripe.CreateCustomer", func(ctx context.Context) error {
+		var err error
+		id, err = c.createCustomer(ctx, body, "customer-"+customer.DID)
+		return err
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyStripe, err)
+		return "", err
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":         customer.DID,
+		"customer_id": id,
+	}).Info("Stripe customer created")
+	return id, nil
+}
+
+func (c *StripeClient) createCustomer(ctx context.Context, body, idempotencyKey string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to create Stripe customer")
+		return "", fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code from Stripe")
+		if retry.IsRetryableStatus(resp.StatusCode) {
+			return "", fmt.Errorf("stripe unavailable: %w", &retry.StatusError{StatusCode: resp.StatusCode})
+		}
+		return "", fmt.Errorf("unexpected status code from Stripe: %d", resp.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode Stripe customer: %w", err)
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("failed to decode Stripe customer: no ID in response")
+	}
+	return created.ID, nil
+}
+
+// DeleteCustomer deletes the Stripe customer with id, as part of erasing
+// its account. A customer that is already gone counts as deleted, so a
+// retried erasure goes through.
+func (c *StripeClient) DeleteCustomer(ctx context.Context, id string) error {
+	err := c.Retry.Do(ctx, "stripe.DeleteCustomer", func(ctx context.Context) error {
+		return c.deleteCustomer(ctx, id)
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyStripe, err)
+		return err
+	}
+
+	logging.FromContext(ctx).WithField("customer_id", id).Info("Stripe customer deleted")
+	return nil
+}
+
+func (c *StripeClient) deleteCustomer(ctx context.Context, id string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.Endpoint+"/"+url.PathEscape(id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.SecretKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to delete Stripe customer")
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code from Stripe")
+		if retry.IsRetryableStatus(resp.StatusCode) {
+			return fmt.Errorf("stripe unavailable: %w", &retry.StatusError{StatusCode: resp.StatusCode})
+		}
+		return fmt.Errorf("unexpected status code from Stripe: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+func TestCreateCustomer(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		httpErr       error
+		expectedID    string
+		expectedError string
+	}{
+		{name: "Created", status: http.StatusOK, body: `{"id":"cus_123","object":"customer"}`, expectedID: "cus_123"},
+		{name: "Rejected", status: http.StatusBadRequest, body: `{"error":{"type":"invalid_request_error"}}`, expectedError: "unexpected status code from Stripe: 400"},
+		{name: "Unavailable", status: http.StatusServiceUnavailable, expectedError: "stripe unavailable: unexpected status code: 503"},
+		{name: "Request Failed", httpErr: errors.New("timeout"), expectedError: "stripe request failed: timeout"},
+		{name: "No ID", status: http.StatusOK, body: `{}`, expectedError: "failed to decode Stripe customer: no ID in response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			var form url.Values
+			client := NewStripeClient("sk_test", &mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					req = r
+					body, _ := io.ReadAll(r.Body)
+					form, _ = url.ParseQuery(string(body))
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader([]byte(tt.body)))}, nil
+				},
+			}, retry.Policy{})
+
+			id, err := client.CreateCustomer(context.Background(), Customer{
+				DID:    "did:plc:abc",
+				Email:  "alice@example.com",
+				Handle: "alice.shareframe.social",
+				Tenant: "default",
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+			}
+			assert.Equal(t, "Bearer sk_test", req.Header.Get("Authorization"))
+			assert.Equal(t, "customer-did:plc:abc", req.Header.Get("Idempotency-Key"))
+			assert.Equal(t, "alice@example.com", form.Get("email"))
+			assert.Equal(t, "did:plc:abc", form.Get("metadata[did]"))
+			assert.Equal(t, "default", form.Get("metadata[tenant]"))
+		})
+	}
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		httpErr       error
+		expectedError string
+	}{
+		{name: "Deleted", status: http.StatusOK},
+		{name: "Already Gone", status: http.StatusNotFound},
+		{name: "Rejected", status: http.StatusBadRequest, expectedError: "unexpected status code from Stripe: 400"},
+		{name: "Unavailable", status: http.StatusServiceUnavailable, expectedError: "stripe unavailable: unexpected status code: 503"},
+		{name: "Request Failed", httpErr: errors.New("timeout"), expectedError: "stripe request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			client := NewStripeClient("sk_test", &mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					req = r
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.DeleteCustomer(context.Background(), "cus_123")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, http.MethodDelete, req.Method)
+			assert.Equal(t, StripeCustomersEndpoint+"/cus_123", req.URL.String())
+			assert.Equal(t, "Bearer sk_test", req.Header.Get("Authorization"))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package budget splits the time left in an invocation between the steps of
+// a signup, so one slow dependency can't use up the whole invocation and
+// leave the steps after it to be killed mid-flight.
+package budget
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+)
+
+type Step string
+
+const (
+	StepValidation Step = "validation"
+	StepPDS        Step = "pds"
+	StepDB         Step = "db"
+	StepEmail      Step = "email"
+)
+
+// Steps lists the steps in the order a signup runs them.
+var Steps = []Step{StepValidation, StepPDS, StepDB, StepEmail}
+
+// Budget holds the time reserved for each step. A step may run until the
+// invocation's deadline less what is reserved for the steps after it, so
+// time an earlier step didn't use carries over, but a step can't eat into
+// a later one's share. A nil *Budget leaves every step unbounded.
+type Budget struct {
+	deadline time.Time
+	reserved map[Step]time.Duration
+	now      func() time.Time
+}
+
+// New plans the time left before ctx's deadline, giving each step its share
+// in percent. It returns nil when ctx has no deadline, as outside Lambda.
+func New(ctx context.Context, shares map[Step]int) *Budget {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	b := &Budget{deadline: deadline, reserved: map[Step]time.Duration{}, now: time.Now}
+	remaining := deadline.Sub(b.now())
+	for step, share := range shares {
+		b.reserved[step] = remaining * time.Duration(share) / 100
+	}
+	return b
+}
+
+// Deadline is when step has to finish.
+func (b *Budget) Deadline(step Step) time.Time {
+	deadline := b.deadline
+	after := false
+	for _, s := range Steps {
+		if after {
+			deadline = deadline.Add(-b.reserved[s])
+		}
+		after = after || s == step
+	}
+	return deadline
+}
+
+// Run runs fn with ctx bounded by step's deadline. A step that runs out of
+// time is logged and counted, so the dependency that was slow shows up
+// rather than the step that was killed after it.
+func (b *Budget) Run(ctx context.Context, step Step, fn func(ctx context.Context) error) error {
+	if b == nil {
+		return fn(ctx)
+	}
+
+	deadline := b.Deadline(step)
+	stepCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	start := b.now()
+	err := fn(stepCtx)
+
+	if errors.Is(stepCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"step":    step,
+			"budget":  deadline.Sub(start).String(),
+			"elapsed": b.now().Sub(start).String(),
+		}).Warn("Step exhausted its time budget")
+		metrics.CountWith(metrics.FromContext(ctx), metrics.BudgetExhausted, map[string]string{metrics.DimensionStep: string(step)})
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var shares = map[Step]int{StepValidation: 10, StepPDS: 40, StepDB: 30, StepEmail: 20}
+
+func TestDeadline(t *testing.T) {
+	now := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(10*time.Second))
+	defer cancel()
+
+	b := New(ctx, shares)
+	b.now = func() time.Time { return now }
+	deadline, _ := ctx.Deadline()
+
+	tests := []struct {
+		step     Step
+		reserved time.Duration
+	}{
+		{StepValidation, 9 * time.Second},
+		{StepPDS, 5 * time.Second},
+		{StepDB, 2 * time.Second},
+		{StepEmail, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.step), func(t *testing.T) {
+			assert.WithinDuration(t, deadline.Add(-tt.reserved), b.Deadline(tt.step), 10*time.Millisecond)
+		})
+	}
+}
+
+func TestNoDeadline(t *testing.T) {
+	b := New(context.Background(), shares)
+	assert.Nil(t, b)
+
+	called := false
+	err := b.Run(context.Background(), StepPDS, func(ctx context.Context) error {
+		called = true
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestRunStopsSlowStep(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The database keeps 90% of the second, so the PDS step gets 100ms.
+	b := New(ctx, map[Step]int{StepPDS: 10, StepDB: 90})
+	err := b.Run(ctx, StepPDS, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.NoError(t, ctx.Err(), "the invocation still has time for the database")
+
+	err = b.Run(ctx, StepDB, func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Greater(t, time.Until(deadline), 500*time.Millisecond)
+		return nil
+	})
+	assert.NoError(t, err)
+}
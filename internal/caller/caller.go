@@ -0,0 +1,31 @@
+// Package caller carries who made a request, as authenticated by whatever
+// invoked the service rather than as claimed in the request body, so audit
+// trails name a caller that can't be forged.
+package caller
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+type identityKey struct{}
+
+// With returns a context carrying identity, such as the IAM ARN the
+// integration authenticated.
+func With(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// From returns the caller's identity: the one With put in ctx or, for a
+// direct Lambda invoke with Cognito credentials, the Cognito identity ID.
+// It reports false when the caller wasn't authenticated.
+func From(ctx context.Context) (string, bool) {
+	if identity, ok := ctx.Value(identityKey{}).(string); ok && identity != "" {
+		return identity, true
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.Identity.CognitoIdentityID != "" {
+		return lc.Identity.CognitoIdentityID, true
+	}
+	return "", false
+}
@@ -0,0 +1,36 @@
+package caller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrom(t *testing.T) {
+	cognito := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		Identity: lambdacontext.CognitoIdentity{CognitoIdentityID: "us-east-1:1234"},
+	})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "Authenticated", ctx: With(context.Background(), "arn:aws:iam::123456789012:user/ops"), expected: "arn:aws:iam::123456789012:user/ops"},
+		{name: "Cognito Identity", ctx: cognito, expected: "us-east-1:1234"},
+		{name: "Authenticated Over Cognito", ctx: With(cognito, "arn:aws:iam::123456789012:user/ops"), expected: "arn:aws:iam::123456789012:user/ops"},
+		{name: "Empty Identity", ctx: With(context.Background(), "")},
+		{name: "Unauthenticated", ctx: context.Background()},
+		{name: "Lambda Without Identity", ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			identity, ok := From(test.ctx)
+			assert.Equal(t, test.expected, identity)
+			assert.Equal(t, test.expected != "", ok)
+		})
+	}
+}
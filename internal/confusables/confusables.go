@@ -0,0 +1,58 @@
+// Package confusables reduces strings to a skeleton in the spirit of
+// Unicode TR39 so that visually similar handles compare equal.
+package confusables
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// runeSkeletons maps single characters to the Latin letter they are most
+// often mistaken for.
+var runeSkeletons = map[rune]rune{
+	'0': 'o',
+	'1': 'l',
+	'|': 'l',
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't',
+	'у': 'y', 'х': 'x', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Latin lookalikes
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'ß': 'b',
+}
+
+// sequenceSkeletons are multi-letter lookalikes, applied after runes are
+// mapped so that "rn" written with Cyrillic letters is also caught.
+var sequenceSkeletons = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+)
+
+// Skeleton returns the comparison form of value. Punycode labels are
+// decoded first so internationalized handles compare by what users see.
+func Skeleton(value string) string {
+	if strings.Contains(value, "xn--") {
+		if decoded, err := idna.ToUnicode(value); err == nil {
+			value = decoded
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(value)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if mapped, ok := runeSkeletons[r]; ok {
+			r = mapped
+		}
+		b.WriteRune(r)
+	}
+
+	return sequenceSkeletons.Replace(b.String())
+}
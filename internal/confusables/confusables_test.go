@@ -0,0 +1,33 @@
+package confusables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		equal bool
+	}{
+		{"rn For m", "sharefrarne", "shareframe", true},
+		{"Zero For o", "supp0rt", "support", true},
+		{"One For l", "he11o", "hello", true},
+		{"Cyrillic a", "pаypal", "paypal", true},
+		{"Greek omicron", "gοοgle", "google", true},
+		{"vv For w", "vvhale", "whale", true},
+		{"Accents Dropped", "shareframé", "shareframe", true},
+		{"Case Insensitive", "ShareFrame", "shareframe", true},
+		{"Punycode Decoded", "xn--pypal-4ve", "paypal", true},
+		{"Different Handles", "sunnydays", "shareframe", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, Skeleton(test.left) == Skeleton(test.right))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package crm keeps the community team's CRM in step with verified
+// accounts. Providers sit behind Syncer so another CRM can be added without
+// touching the handler.
+package crm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+// Providers accepted by New.
+const (
+	ProviderHubSpot = "hubspot"
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Syncer creates or updates the CRM contact for an account. Upserts are
+// keyed on the email address, so syncing the same account twice is safe.
+type Syncer interface {
+	UpsertContact(ctx context.Context, contact models.CRMContact) error
+	// DeleteContact permanently deletes the contact with email, as part of
+	// erasing its account. A contact that doesn't exist counts as deleted.
+	DeleteContact(ctx context.Context, email string) error
+}
+
+// ValidProvider reports whether New knows provider.
+func ValidProvider(provider string) bool {
+	return provider == ProviderHubSpot
+}
+
+// New returns the Syncer for provider, authenticated with apiKey.
+func New(provider, apiKey string, client HTTPClient, retryPolicy retry.Policy) (Syncer, error) {
+	switch provider {
+	case ProviderHubSpot:
+		return NewHubSpotClient(apiKey, client, retryPolicy), nil
+	default:
+		return nil, fmt.Errorf("unknown CRM provider %q", provider)
+	}
+}
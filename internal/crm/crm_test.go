@@ -0,0 +1,19 @@
+package crm
+
+import (
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	syncer, err := New(ProviderHubSpot, "pat-123", nil, retry.Policy{})
+	assert.NoError(t, err)
+	assert.IsType(t, &HubSpotClient{}, syncer)
+	assert.True(t, ValidProvider(ProviderHubSpot))
+
+	_, err = New("salesforce", "key", nil, retry.Policy{})
+	assert.EqualError(t, err, `unknown CRM provider "salesforce"`)
+	assert.False(t, ValidProvider("salesforce"))
+}
@@ -0,0 +1,160 @@
+package crm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const (
+	HubSpotUpsertEndpoint = "https://api.hubapi.com/crm/v3/objects/contacts/batch/upsert"
+	// HubSpotDeleteEndpoint deletes a contact for good, as HubSpot's
+	// GDPR deletion, rather than moving it to the recycle bin.
+	HubSpotDeleteEndpoint = "https://api.hubapi.com/crm/v3/objects/contacts/gdpr-delete"
+)
+
+// Contact properties written to HubSpot. Apart from email they are custom
+// properties, which must be created in the HubSpot account before the
+// first sync.
+const (
+	HubSpotPropertyHandle         = "shareframe_handle"
+	HubSpotPropertyDID            = "shareframe_did"
+	HubSpotPropertyTenant         = "shareframe_tenant"
+	HubSpotPropertySignupDate     = "shareframe_signup_date"
+	HubSpotPropertyReferralSource = "shareframe_referral_source"
+)
+
+// hubSpotDate is the format of HubSpot date properties.
+const hubSpotDate = "2006-01-02"
+
+// HubSpotClient upserts and deletes contacts with a HubSpot private app
+// token.
+type HubSpotClient struct {
+	APIKey         string
+	Endpoint       string
+	DeleteEndpoint string
+	HTTPClient     HTTPClient
+	Retry          retry.Policy
+}
+
+func NewHubSpotClient(apiKey string, client HTTPClient, retryPolicy retry.Policy) *HubSpotClient {
+	return &HubSpotClient{
+		APIKey:         apiKey,
+		Endpoint:       HubSpotUpsertEndpoint,
+		DeleteEndpoint: HubSpotDeleteEndpoint,
+		HTTPClient:     client,
+		Retry:          retryPolicy,
+	}
+}
+
+type hubSpotUpsert struct {
+	IDProperty string            `json:"idProperty"`
+	ID         string            `json:"id"`
+	Properties map[string]string `json:"properties"`
+}
+
+// UpsertContact creates the contact, or updates the one with the same
+// email address.
+func (c *HubSpotClient) UpsertContact(ctx context.Context, contact models.CRMContact) error {
+	properties := map[string]string{
+		"email":                   contact.Email,
+		HubSpotPropertyHandle:     contact.Handle,
+		HubSpotPropertyDID:        contact.DID,
+		HubSpotPropertySignupDate: contact.SignupDate.UTC().Format(hubSpotDate),
+	}
+	if contact.Tenant != "" {
+		properties[HubSpotPropertyTenant] = contact.Tenant
+	}
+	if contact.ReferralSource != "" {
+		properties[HubSpotPropertyReferralSource] = contact.ReferralSource
+	}
+
+	body, err := json.Marshal(map[string][]hubSpotUpsert{
+		"inputs": {{IDProperty: "email", ID: contact.Email, Properties: properties}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal HubSpot contact: %w", err)
+	}
+
+	err = c.Retry.Do(ctx, "hubspot.UpsertContact", func(ctx context.Context) error {
+		return c.post(ctx, c.Endpoint, body)
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyCRM, err)
+		logging.FromContext(ctx).WithError(err).WithField("did", contact.DID).Error("Failed to sync HubSpot contact")
+		return err
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":      contact.DID,
+		"provider": ProviderHubSpot,
+	}).Info("CRM contact synced")
+	return nil
+}
+
+type hubSpotDelete struct {
+	IDProperty string `json:"idProperty"`
+	ObjectID   string `json:"objectId"`
+}
+
+func (c *HubSpotClient) DeleteContact(ctx context.Context, email string) error {
+	body, err := json.Marshal(hubSpotDelete{IDProperty: "email", ObjectID: email})
+	if err != nil {
+		return fmt.Errorf("failed to marshal HubSpot deletion: %w", err)
+	}
+
+	err = c.Retry.Do(ctx, "hubspot.DeleteContact", func(ctx context.Context) error {
+		err := c.post(ctx, c.DeleteEndpoint, body)
+		if errors.Is(err, errHubSpotNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyCRM, err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to delete HubSpot contact")
+		return err
+	}
+
+	logging.FromContext(ctx).WithField("provider", ProviderHubSpot).Info("CRM contact deleted")
+	return nil
+}
+
+// errHubSpotNotFound is returned by post when HubSpot has no such object.
+var errHubSpotNotFound = errors.New("unexpected status code from HubSpot: 404")
+
+func (c *HubSpotClient) post(ctx context.Context, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create HubSpot request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("hubspot request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if retry.IsRetryableStatus(resp.StatusCode) {
+		return &retry.StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errHubSpotNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from HubSpot: %d", resp.StatusCode)
+	}
+	return nil
+}
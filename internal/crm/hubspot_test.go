@@ -0,0 +1,133 @@
+package crm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+func TestUpsertContact(t *testing.T) {
+	contact := models.CRMContact{
+		DID:            "did:plc:abc",
+		Email:          "alice@example.com",
+		Handle:         "alice.shareframe.social",
+		Tenant:         "default",
+		ReferralSource: "friend",
+		SignupDate:     time.Date(2026, 1, 2, 23, 30, 0, 0, time.UTC),
+		Verified:       true,
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		httpErr       error
+		expectedError string
+	}{
+		{name: "Upserted", status: http.StatusOK},
+		{name: "Rejected", status: http.StatusBadRequest, expectedError: "unexpected status code from HubSpot: 400"},
+		{name: "Unavailable", status: http.StatusTooManyRequests, expectedError: "unexpected status code: 429"},
+		{name: "Request Failed", httpErr: errors.New("timeout"), expectedError: "hubspot request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			var payload struct {
+				Inputs []hubSpotUpsert `json:"inputs"`
+			}
+			client := NewHubSpotClient("pat-123", &mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					req = r
+					body, _ := io.ReadAll(r.Body)
+					_ = json.Unmarshal(body, &payload)
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.UpsertContact(context.Background(), contact)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "Bearer pat-123", req.Header.Get("Authorization"))
+			assert.Equal(t, []hubSpotUpsert{{
+				IDProperty: "email",
+				ID:         "alice@example.com",
+				Properties: map[string]string{
+					"email":                       "alice@example.com",
+					HubSpotPropertyHandle:         "alice.shareframe.social",
+					HubSpotPropertyDID:            "did:plc:abc",
+					HubSpotPropertyTenant:         "default",
+					HubSpotPropertySignupDate:     "2026-01-02",
+					HubSpotPropertyReferralSource: "friend",
+				},
+			}}, payload.Inputs)
+		})
+	}
+}
+
+func TestDeleteContact(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		httpErr       error
+		expectedError string
+	}{
+		{name: "Deleted", status: http.StatusNoContent},
+		{name: "Already Gone", status: http.StatusNotFound},
+		{name: "Rejected", status: http.StatusBadRequest, expectedError: "unexpected status code from HubSpot: 400"},
+		{name: "Unavailable", status: http.StatusTooManyRequests, expectedError: "unexpected status code: 429"},
+		{name: "Request Failed", httpErr: errors.New("timeout"), expectedError: "hubspot request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			var payload hubSpotDelete
+			client := NewHubSpotClient("pat-123", &mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					req = r
+					body, _ := io.ReadAll(r.Body)
+					_ = json.Unmarshal(body, &payload)
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+				},
+			}, retry.Policy{})
+
+			err := client.DeleteContact(context.Background(), "alice@example.com")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, HubSpotDeleteEndpoint, req.URL.String())
+			assert.Equal(t, "Bearer pat-123", req.Header.Get("Authorization"))
+			assert.Equal(t, hubSpotDelete{IDProperty: "email", ObjectID: "alice@example.com"}, payload)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+)
+
+// ErrRecipientLimited is returned for an email to an address that has
+// already received its hourly quota.
+var ErrRecipientLimited = errors.New("email recipient rate limited")
+
+// LimitedSender caps how many emails each recipient gets per hour, so a
+// signup flow can't be used to flood someone's inbox.
+type LimitedSender struct {
+	Sender  Sender
+	Limiter ratelimit.Limiter
+	Limit   ratelimit.Limit
+}
+
+func NewLimitedSender(sender Sender, limiter ratelimit.Limiter, perHour int) *LimitedSender {
+	return &LimitedSender{
+		Sender:  sender,
+		Limiter: limiter,
+		Limit:   ratelimit.Limit{Max: perHour, Window: time.Hour},
+	}
+}
+
+// Send fails open when the limiter can't be reached. Recipients are keyed
+// by a hash so addresses aren't stored with the counters.
+func (s *LimitedSender) Send(ctx context.Context, msg Message) error {
+	for _, to := range msg.To {
+		key := "email_recipient:" + logging.HashValue(strings.ToLower(to))
+		decision, err := s.Limiter.Allow(ctx, key, s.Limit)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Warn("Skipping email recipient limit")
+			continue
+		}
+		if !decision.Allowed {
+			logging.FromContext(ctx).WithField("recipient", to).Warn("Email recipient rate limited")
+			return fmt.Errorf("%w: retry after %s", ErrRecipientLimited, decision.RetryAfter.Round(time.Second))
+		}
+	}
+	return s.Sender.Send(ctx, msg)
+}
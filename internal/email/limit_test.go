@@ -0,0 +1,52 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSender struct {
+	mock.Mock
+}
+
+func (m *mockSender) Send(ctx context.Context, msg Message) error {
+	return m.Called(ctx, msg).Error(0)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("table unavailable")
+}
+
+func TestLimitedSender(t *testing.T) {
+	ctx := context.Background()
+	msg := Message{From: "hello@shareframe.social", To: []string{"Alice@Example.com"}, Subject: "Welcome"}
+
+	t.Run("Caps Each Recipient", func(t *testing.T) {
+		sender := new(mockSender)
+		sender.On("Send", ctx, mock.Anything).Return(nil)
+		limited := NewLimitedSender(sender, ratelimit.NewMemoryLimiter(), 2)
+
+		assert.NoError(t, limited.Send(ctx, msg))
+		assert.NoError(t, limited.Send(ctx, Message{From: msg.From, To: []string{"alice@example.com"}, Subject: msg.Subject}))
+		err := limited.Send(ctx, msg)
+
+		assert.ErrorIs(t, err, ErrRecipientLimited)
+		sender.AssertNumberOfCalls(t, "Send", 2)
+	})
+
+	t.Run("Fails Open", func(t *testing.T) {
+		sender := new(mockSender)
+		sender.On("Send", ctx, msg).Return(nil)
+		limited := NewLimitedSender(sender, failingLimiter{}, 2)
+
+		assert.NoError(t, limited.Send(ctx, msg))
+		sender.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,109 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const ResendEndpoint = "https://api.resend.com/emails"
+
+// ErrAPIKeyUnavailable is returned by Send when APIKeyFunc fails, before
+// anything is sent.
+var ErrAPIKeyUnavailable = errors.New("failed to retrieve email credentials")
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Message struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	HTML    string   `json:"html"`
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type ResendClient struct {
+	APIKey string
+	// APIKeyFunc, when set, is asked for the key of each email in place of
+	// APIKey, so a long-lived client picks up a rotated key.
+	APIKeyFunc func(ctx context.Context) (string, error)
+	Endpoint   string
+	HTTPClient HTTPClient
+	Retry      retry.Policy
+}
+
+func NewResendClient(apiKey string, client HTTPClient, retryPolicy retry.Policy) *ResendClient {
+	return &ResendClient{
+		APIKey:     apiKey,
+		Endpoint:   ResendEndpoint,
+		HTTPClient: client,
+		Retry:      retryPolicy,
+	}
+}
+
+func (c *ResendClient) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email: %w", err)
+	}
+
+	apiKey := c.APIKey
+	if c.APIKeyFunc != nil {
+		if apiKey, err = c.APIKeyFunc(ctx); err != nil {
+			return fmt.Errorf("%w: %w", ErrAPIKeyUnavailable, err)
+		}
+	}
+
+	defer metrics.Since(metrics.FromContext(ctx), metrics.EmailLatency, time.Now())
+
+	// A retry after Resend accepted the message would send it twice.
+	err = c.Retry.ForWrite().Do(ctx, "resend.Send", func(ctx context.Context) error {
+		return c.send(ctx, apiKey, body)
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyResend, err)
+		return err
+	}
+
+	logging.FromContext(ctx).WithField("subject", msg.Subject).Info("Email sent successfully")
+	return nil
+}
+
+func (c *ResendClient) send(ctx context.Context, apiKey string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create email request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to send email via Resend")
+		return fmt.Errorf("email request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logging.FromContext(ctx).WithField("status_code", resp.StatusCode).Error("Unexpected status code from Resend")
+		if retry.IsRetryableStatus(resp.StatusCode) {
+			return fmt.Errorf("email provider unavailable: %w", &retry.StatusError{StatusCode: resp.StatusCode})
+		}
+		return fmt.Errorf("unexpected status code from email provider: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+func TestResendSend(t *testing.T) {
+	tests := []struct {
+		name          string
+		httpResponse  *http.Response
+		httpError     error
+		expectedError string
+	}{
+		{
+			name: "Successful Send",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":"email-123"}`))),
+			},
+		},
+		{
+			name:          "HTTP Error",
+			httpError:     errors.New("connection reset"),
+			expectedError: "email request failed: connection reset",
+		},
+		{
+			name: "Provider Rejects Request",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusUnprocessableEntity,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"message":"invalid from"}`))),
+			},
+			expectedError: "unexpected status code from email provider: 422",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured *http.Request
+			client := NewResendClient("re_test", &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					captured = req
+					return tt.httpResponse, tt.httpError
+				},
+			}, retry.Policy{})
+
+			err := client.Send(context.Background(), Message{
+				From:    "hello@shareframe.social",
+				To:      []string{"user@example.com"},
+				Subject: "Welcome",
+				HTML:    "<p>hi</p>",
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "Bearer re_test", captured.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestResendSendWithAPIKeyFunc(t *testing.T) {
+	key := "re_first"
+	var authorizations []string
+	client := &ResendClient{
+		APIKeyFunc: func(ctx context.Context) (string, error) { return key, nil },
+		Endpoint:   ResendEndpoint,
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				authorizations = append(authorizations, req.Header.Get("Authorization"))
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			},
+		},
+	}
+	msg := Message{From: "hello@shareframe.social", To: []string{"user@example.com"}, Subject: "Welcome"}
+
+	assert.NoError(t, client.Send(context.Background(), msg))
+	key = "re_rotated"
+	assert.NoError(t, client.Send(context.Background(), msg))
+	assert.Equal(t, []string{"Bearer re_first", "Bearer re_rotated"}, authorizations)
+
+	client.APIKeyFunc = func(ctx context.Context) (string, error) { return "", errors.New("secret not found") }
+	err := client.Send(context.Background(), msg)
+	assert.EqualError(t, err, "failed to retrieve email credentials: secret not found")
+	assert.ErrorIs(t, err, ErrAPIKeyUnavailable)
+	assert.Len(t, authorizations, 2)
+}
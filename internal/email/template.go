@@ -0,0 +1,129 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	EmbeddedSource  = "embedded"
+	s3Scheme        = "s3://"
+	defaultTemplate = "templates/welcome.html"
+)
+
+//go:embed templates/*.html
+var embeddedTemplates embed.FS
+
+type S3API interface {
+	GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Template is a parsed email template defining "subject" and "body" blocks.
+type Template struct {
+	tmpl *template.Template
+}
+
+type TemplateData struct {
+	Handle string
+	DID    string
+	Tenant string
+}
+
+// templates caches parsed templates by source so warm invocations don't
+// re-read the file or S3 object.
+var templates sync.Map
+
+// LoadTemplate resolves a template source: "" or "embedded" for the built-in
+// template, an s3://bucket/key URI, or a local file path.
+func LoadTemplate(ctx context.Context, source string, s3Client S3API) (*Template, error) {
+	if source == "" {
+		source = EmbeddedSource
+	}
+
+	if cached, ok := templates.Load(source); ok {
+		return cached.(*Template), nil
+	}
+
+	raw, err := readSource(ctx, source, s3Client)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("source", source).Error("Failed to read email template")
+		return nil, err
+	}
+
+	tmpl, err := template.New("email").Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", source, err)
+	}
+	if tmpl.Lookup("subject") == nil || tmpl.Lookup("body") == nil {
+		return nil, fmt.Errorf("email template %s must define subject and body", source)
+	}
+
+	loaded := &Template{tmpl: tmpl}
+	templates.Store(source, loaded)
+	logging.FromContext(ctx).WithField("source", source).Info("Loaded email template")
+	return loaded, nil
+}
+
+func readSource(ctx context.Context, source string, s3Client S3API) ([]byte, error) {
+	switch {
+	case source == EmbeddedSource:
+		return embeddedTemplates.ReadFile(defaultTemplate)
+	case strings.HasPrefix(source, s3Scheme):
+		return readS3(ctx, source, s3Client)
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read email template file: %w", err)
+		}
+		return data, nil
+	}
+}
+
+func readS3(ctx context.Context, uri string, s3Client S3API) ([]byte, error) {
+	if s3Client == nil {
+		return nil, fmt.Errorf("no S3 client configured for template %s", uri)
+	}
+
+	bucket, key, found := strings.Cut(strings.TrimPrefix(uri, s3Scheme), "/")
+	if !found || bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 template URI: %s", uri)
+	}
+
+	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email template from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email template from S3: %w", err)
+	}
+	return data, nil
+}
+
+// Render executes the template, returning the subject line and HTML body.
+func (t *Template) Render(data TemplateData) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := t.tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email body: %w", err)
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockS3Client struct {
+	mock.Mock
+}
+
+func (m *mockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) != nil {
+		return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+const stagingTemplate = `{{define "subject"}}[STAGING] Hi {{.Handle}}{{end}}{{define "body"}}<p>staging {{.Handle}}</p>{{end}}`
+
+func TestLoadTemplateEmbedded(t *testing.T) {
+	tmpl, err := LoadTemplate(context.Background(), "", nil)
+	assert.NoError(t, err)
+
+	subject, body, err := tmpl.Render(TemplateData{Handle: "alice.shareframe.social"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to ShareFrame, alice.shareframe.social!", subject)
+	assert.Contains(t, body, "alice.shareframe.social")
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "staging.html")
+	assert.NoError(t, os.WriteFile(path, []byte(stagingTemplate), 0o600))
+
+	tmpl, err := LoadTemplate(context.Background(), path, nil)
+	assert.NoError(t, err)
+
+	subject, _, err := tmpl.Render(TemplateData{Handle: "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, "[STAGING] Hi bob", subject)
+}
+
+func TestLoadTemplateS3(t *testing.T) {
+	tests := []struct {
+		name           string
+		uri            string
+		mockOutput     *s3.GetObjectOutput
+		mockErr        error
+		expectFetch    bool
+		expectedErrMsg string
+	}{
+		{
+			name:        "Successful Fetch",
+			uri:         "s3://templates-bucket/acme/welcome.html",
+			mockOutput:  &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte(stagingTemplate)))},
+			expectFetch: true,
+		},
+		{
+			name:           "Missing Key",
+			uri:            "s3://templates-bucket",
+			expectedErrMsg: "invalid S3 template URI",
+		},
+		{
+			name:           "S3 Error",
+			uri:            "s3://templates-bucket/missing.html",
+			mockErr:        errors.New("NoSuchKey"),
+			expectFetch:    true,
+			expectedErrMsg: "failed to fetch email template from S3: NoSuchKey",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockS3 := new(mockS3Client)
+			if test.expectFetch {
+				mockS3.On("GetObject", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockErr)
+			}
+
+			tmpl, err := LoadTemplate(context.Background(), test.uri, mockS3)
+
+			if test.expectedErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, tmpl)
+			}
+
+			mockS3.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoadTemplateRequiresBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.html")
+	assert.NoError(t, os.WriteFile(path, []byte(`<p>no blocks</p>`), 0o600))
+
+	_, err := LoadTemplate(context.Background(), path, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must define subject and body")
+}
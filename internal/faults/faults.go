@@ -0,0 +1,122 @@
+// Package faults injects failures into calls to the PDS, the database, the
+// email provider and the billing provider, so retries, the email queue and the dead-letter
+// re-drive can be exercised on purpose in test environments. It is wired
+// in only when FAULT_INJECTION is set, which config refuses in production.
+package faults
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+)
+
+// Target is a dependency faults can be injected into.
+type Target string
+
+const (
+	// TargetPDS answers PDS requests with a 500.
+	TargetPDS Target = "pds"
+	// TargetDB fails database statements with a timeout.
+	TargetDB Target = "db"
+	// TargetEmail answers email provider requests with a 500.
+	TargetEmail Target = "email"
+	// TargetBilling answers billing provider requests with a 500.
+	TargetBilling Target = "billing"
+)
+
+// Targets lists every target, as accepted in FAULT_INJECTION.
+var Targets = []Target{TargetPDS, TargetDB, TargetEmail, TargetBilling}
+
+// Injector fails the given percentage of calls to each target. Each call,
+// including each retry, is decided separately, so a low rate mostly tests
+// retries and a high one the paths taken once they're exhausted. A nil
+// *Injector injects nothing.
+type Injector struct {
+	rates map[Target]int
+	// roll returns a number in [0, 100).
+	roll func() int
+}
+
+// NewInjector returns an Injector for rates, or nil when no target has a
+// rate above zero.
+func NewInjector(rates map[Target]int) *Injector {
+	for _, rate := range rates {
+		if rate > 0 {
+			return &Injector{rates: rates, roll: func() int { return rand.Intn(100) }}
+		}
+	}
+	return nil
+}
+
+// fail decides whether this call to target fails.
+func (i *Injector) fail(ctx context.Context, target Target) bool {
+	if i == nil || i.rates[target] <= 0 || i.roll() >= i.rates[target] {
+		return false
+	}
+	logging.FromContext(ctx).WithField("target", target).Warn("Injecting fault")
+	return true
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTP wraps next so a share of its requests get a 500 from target instead
+// of being sent.
+func (i *Injector) HTTP(target Target, next HTTPClient) HTTPClient {
+	if i == nil || i.rates[target] <= 0 {
+		return next
+	}
+	return &faultyHTTPClient{injector: i, target: target, next: next}
+}
+
+type faultyHTTPClient struct {
+	injector *Injector
+	target   Target
+	next     HTTPClient
+}
+
+func (c *faultyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if !c.injector.fail(req.Context(), c.target) {
+		return c.next.Do(req)
+	}
+	body := fmt.Sprintf(`{"error":"InternalServerError","message":"injected %s fault"}`, c.target)
+	return &http.Response{
+		Status:     "500 Internal Server Error",
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		Request:    req,
+	}, nil
+}
+
+type RDSDataAPI interface {
+	ExecuteStatement(ctx context.Context, input *rdsdata.ExecuteStatementInput, opts ...func(*rdsdata.Options)) (*rdsdata.ExecuteStatementOutput, error)
+}
+
+// RDSData wraps next so a share of its statements fail with a timeout
+// instead of running.
+func (i *Injector) RDSData(next RDSDataAPI) RDSDataAPI {
+	if i == nil || i.rates[TargetDB] <= 0 {
+		return next
+	}
+	return &faultyRDSData{injector: i, next: next}
+}
+
+type faultyRDSData struct {
+	injector *Injector
+	next     RDSDataAPI
+}
+
+func (c *faultyRDSData) ExecuteStatement(ctx context.Context, input *rdsdata.ExecuteStatementInput, opts ...func(*rdsdata.Options)) (*rdsdata.ExecuteStatementOutput, error) {
+	if c.injector.fail(ctx, TargetDB) {
+		return nil, fmt.Errorf("injected db fault: %w", context.DeadlineExceeded)
+	}
+	return c.next.ExecuteStatement(ctx, input, opts...)
+}
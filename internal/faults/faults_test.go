@@ -0,0 +1,88 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/stretchr/testify/assert"
+)
+
+type okHTTPClient struct {
+	calls int
+}
+
+func (c *okHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+type okRDSData struct {
+	calls int
+}
+
+func (c *okRDSData) ExecuteStatement(ctx context.Context, input *rdsdata.ExecuteStatementInput, opts ...func(*rdsdata.Options)) (*rdsdata.ExecuteStatementOutput, error) {
+	c.calls++
+	return &rdsdata.ExecuteStatementOutput{}, nil
+}
+
+// injector rolls through rolls in order.
+func injector(rates map[Target]int, rolls ...int) *Injector {
+	i := NewInjector(rates)
+	i.roll = func() int {
+		roll := rolls[0]
+		rolls = rolls[1:]
+		return roll
+	}
+	return i
+}
+
+func TestNewInjectorWithoutRates(t *testing.T) {
+	assert.Nil(t, NewInjector(nil))
+	assert.Nil(t, NewInjector(map[Target]int{TargetPDS: 0}))
+
+	var i *Injector
+	next := &okHTTPClient{}
+	assert.Same(t, next, i.HTTP(TargetPDS, next))
+}
+
+func TestHTTPFaults(t *testing.T) {
+	next := &okHTTPClient{}
+	client := injector(map[Target]int{TargetPDS: 25}, 10, 60).HTTP(TargetPDS, next)
+	req, _ := http.NewRequest(http.MethodGet, "https://pds.example.com", nil)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "injected pds fault")
+	assert.Equal(t, 0, next.calls)
+
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, next.calls)
+}
+
+func TestHTTPFaultsOnlyForTarget(t *testing.T) {
+	next := &okHTTPClient{}
+	i := NewInjector(map[Target]int{TargetPDS: 100})
+
+	assert.Same(t, next, i.HTTP(TargetEmail, next))
+}
+
+func TestRDSDataFaults(t *testing.T) {
+	next := &okRDSData{}
+	client := injector(map[Target]int{TargetDB: 50}, 49, 50).RDSData(next)
+
+	_, err := client.ExecuteStatement(context.Background(), &rdsdata.ExecuteStatementInput{})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 0, next.calls)
+
+	_, err = client.ExecuteStatement(context.Background(), &rdsdata.ExecuteStatementInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, next.calls)
+}
@@ -0,0 +1,219 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+)
+
+// Resolver produces a top-level field's value from its arguments, with
+// variables substituted and enums as strings. The value is shaped by the
+// field's selection through its JSON encoding, so it can be any type the
+// handlers already return.
+type Resolver func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+
+// Schema is the set of top-level fields, by operation type.
+type Schema struct {
+	Query    map[string]Resolver
+	Mutation map[string]Resolver
+	// Extensions, if set, adds to the extensions of a resolver's error
+	// beyond its code.
+	Extensions func(err error) map[string]interface{}
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response. Data is omitted when the request failed
+// before execution, as the spec requires.
+type Response struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []Error                `json:"errors,omitempty"`
+}
+
+// Error is an entry in a response's errors.
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []string               `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Execute runs the request's operation. Top-level fields are resolved in
+// order; a failed field is null in the data and reported in errors.
+func (s *Schema) Execute(ctx context.Context, req Request) Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return requestError("GRAPHQL_PARSE_FAILED", err.Error())
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return requestError("BAD_USER_INPUT", err.Error())
+	}
+	variables, err := op.coerceVariables(req.Variables)
+	if err != nil {
+		return requestError("BAD_USER_INPUT", err.Error())
+	}
+
+	resolvers := s.Query
+	if op.Type == OperationMutation {
+		resolvers = s.Mutation
+	}
+	for _, field := range op.Selections {
+		if field.Name != "__typename" && resolvers[field.Name] == nil {
+			return requestError("GRAPHQL_VALIDATION_FAILED", fmt.Sprintf("cannot query field %q on type %s", field.Name, typeName(op.Type)))
+		}
+	}
+
+	response := Response{Data: map[string]interface{}{}}
+	for _, field := range op.Selections {
+		if field.Name == "__typename" {
+			response.Data[field.Key()] = typeName(op.Type)
+			continue
+		}
+
+		args := make(map[string]interface{}, len(field.Arguments))
+		for name, value := range field.Arguments {
+			args[name] = substitute(value, variables)
+		}
+		value, err := resolvers[field.Name](ctx, args)
+		if err == nil {
+			value, err = project(value, field.Selections)
+		}
+		if err != nil {
+			response.Data[field.Key()] = nil
+			response.Errors = append(response.Errors, s.fieldError(field, err))
+			continue
+		}
+		response.Data[field.Key()] = value
+	}
+	return response
+}
+
+func (s *Schema) fieldError(field *Field, err error) Error {
+	extensions := map[string]interface{}{}
+	if s.Extensions != nil {
+		for key, value := range s.Extensions(err) {
+			extensions[key] = value
+		}
+	}
+	extensions["code"] = apperr.GraphQLCode(err)
+	return Error{Message: err.Error(), Path: []string{field.Key()}, Extensions: extensions}
+}
+
+func requestError(code, message string) Response {
+	return Response{Errors: []Error{{Message: message, Extensions: map[string]interface{}{"code": code}}}}
+}
+
+func typeName(operationType string) string {
+	if operationType == OperationMutation {
+		return "Mutation"
+	}
+	return "Query"
+}
+
+// operation picks the operation to run: the one named, or the only one.
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// coerceVariables applies defaults and checks that non-null variables are
+// given. Values aren't checked against their types; the handlers validate
+// their input anyway.
+func (op *Operation) coerceVariables(given map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(op.Variables))
+	for _, definition := range op.Variables {
+		value, ok := given[definition.Name]
+		if !ok && definition.HasValue {
+			value, ok = substitute(definition.Default, nil), true
+		}
+		if definition.NonNull && value == nil {
+			return nil, fmt.Errorf("variable $%s is required", definition.Name)
+		}
+		if ok {
+			variables[definition.Name] = value
+		}
+	}
+	return variables, nil
+}
+
+// substitute replaces variables in an argument value with their values
+// and enums with their names.
+func substitute(value interface{}, variables map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case Variable:
+		return variables[string(v)]
+	case Enum:
+		return string(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = substitute(item, variables)
+		}
+		return list
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = substitute(item, variables)
+		}
+		return object
+	}
+	return value
+}
+
+// project shapes a resolver's value to the selection: objects keep only
+// the selected fields, under their aliases, and lists are projected item by
+// item. A field without a selection is returned whole.
+func project(value interface{}, selections []*Field) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: failed to encode result: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("internal error: failed to decode result: %w", err)
+	}
+	return selectFields(decoded, selections), nil
+}
+
+func selectFields(value interface{}, selections []*Field) interface{} {
+	if len(selections) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = selectFields(item, selections)
+		}
+		return list
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(selections))
+		for _, field := range selections {
+			if field.Name == "__typename" {
+				object[field.Key()] = "Object"
+				continue
+			}
+			object[field.Key()] = selectFields(v[field.Name], field.Selections)
+		}
+		return object
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	DID     string   `json:"did"`
+	Handle  string   `json:"handle"`
+	Email   string   `json:"email"`
+	Friends []friend `json:"friends,omitempty"`
+}
+
+type friend struct {
+	Handle string `json:"handle"`
+	Since  int    `json:"since"`
+}
+
+func testSchema(calls *[]map[string]interface{}) *Schema {
+	return &Schema{
+		Query: map[string]Resolver{
+			"account": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				*calls = append(*calls, args)
+				if args["handle"] == "nobody" {
+					return nil, apperr.Errorf(apperr.NotFound, "not found: no account for nobody")
+				}
+				return account{
+					DID:     "did:plc:alice",
+					Handle:  "alice.shareframe.social",
+					Email:   "alice@example.com",
+					Friends: []friend{{Handle: "bob.shareframe.social", Since: 2024}},
+				}, nil
+			},
+		},
+		Mutation: map[string]Resolver{
+			"ping": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				*calls = append(*calls, args)
+				return "pong", nil
+			},
+			"fail": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				return nil, errors.New("DB connection failed")
+			},
+		},
+		Extensions: func(err error) map[string]interface{} {
+			return map[string]interface{}{"code": "overridden", "detail": "extra"}
+		},
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       Request
+		expected      Response
+		expectedCalls []map[string]interface{}
+	}{
+		{
+			name: "Selection And Aliases",
+			request: Request{Query: `{
+				me: account(handle: "alice") { did name: handle friends { handle } __typename }
+				__typename
+			}`},
+			expected: Response{Data: map[string]interface{}{
+				"me": map[string]interface{}{
+					"did":        "did:plc:alice",
+					"name":       "alice.shareframe.social",
+					"friends":    []interface{}{map[string]interface{}{"handle": "bob.shareframe.social"}},
+					"__typename": "Object",
+				},
+				"__typename": "Query",
+			}},
+			expectedCalls: []map[string]interface{}{{"handle": "alice"}},
+		},
+		{
+			name: "Variables And Defaults",
+			request: Request{
+				Query:     `query Find($handle: String!, $tenant: String = "beta", $unset: String) { account(handle: $handle, tenant: $tenant, unset: $unset, filter: {sort: ASC, tags: [$handle]}) { did } }`,
+				Variables: map[string]interface{}{"handle": "alice"},
+			},
+			expected: Response{Data: map[string]interface{}{
+				"account": map[string]interface{}{"did": "did:plc:alice"},
+			}},
+			expectedCalls: []map[string]interface{}{{
+				"handle": "alice",
+				"tenant": "beta",
+				"unset":  nil,
+				"filter": map[string]interface{}{"sort": "ASC", "tags": []interface{}{"alice"}},
+			}},
+		},
+		{
+			name:    "Resolver Error",
+			request: Request{Query: `{ account(handle: "nobody") { did } other: account(handle: "alice") { did } }`},
+			expected: Response{
+				Data: map[string]interface{}{
+					"account": nil,
+					"other":   map[string]interface{}{"did": "did:plc:alice"},
+				},
+				Errors: []Error{{
+					Message:    "not found: no account for nobody",
+					Path:       []string{"account"},
+					Extensions: map[string]interface{}{"code": "NOT_FOUND", "detail": "extra"},
+				}},
+			},
+			expectedCalls: []map[string]interface{}{{"handle": "nobody"}, {"handle": "alice"}},
+		},
+		{
+			name:    "Named Operation",
+			request: Request{Query: `query A { account { did } } mutation B { ping fail }`, OperationName: "B"},
+			expected: Response{
+				Data: map[string]interface{}{"ping": "pong", "fail": nil},
+				Errors: []Error{{
+					Message:    "DB connection failed",
+					Path:       []string{"fail"},
+					Extensions: map[string]interface{}{"code": "INTERNAL_SERVER_ERROR", "detail": "extra"},
+				}},
+			},
+			expectedCalls: []map[string]interface{}{{}},
+		},
+		{
+			name:    "Ambiguous Operation",
+			request: Request{Query: `query A { account { did } } mutation B { ping }`},
+			expected: Response{Errors: []Error{{
+				Message:    "operationName is required when the document has several operations",
+				Extensions: map[string]interface{}{"code": "BAD_USER_INPUT"},
+			}}},
+		},
+		{
+			name:    "Unknown Operation",
+			request: Request{Query: `query A { account { did } }`, OperationName: "B"},
+			expected: Response{Errors: []Error{{
+				Message:    `unknown operation "B"`,
+				Extensions: map[string]interface{}{"code": "BAD_USER_INPUT"},
+			}}},
+		},
+		{
+			name:    "Missing Variable",
+			request: Request{Query: `query ($handle: String!) { account(handle: $handle) { did } }`},
+			expected: Response{Errors: []Error{{
+				Message:    "variable $handle is required",
+				Extensions: map[string]interface{}{"code": "BAD_USER_INPUT"},
+			}}},
+		},
+		{
+			name:    "Unknown Field",
+			request: Request{Query: `{ account { did } ping }`},
+			expected: Response{Errors: []Error{{
+				Message:    `cannot query field "ping" on type Query`,
+				Extensions: map[string]interface{}{"code": "GRAPHQL_VALIDATION_FAILED"},
+			}}},
+		},
+		{
+			name:    "Syntax Error",
+			request: Request{Query: `{ account(`},
+			expected: Response{Errors: []Error{{
+				Message:    `syntax error at offset 10: expected name, found "end of document"`,
+				Extensions: map[string]interface{}{"code": "GRAPHQL_PARSE_FAILED"},
+			}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls []map[string]interface{}
+			response := testSchema(&calls).Execute(context.Background(), test.request)
+			assert.Equal(t, test.expected, response)
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
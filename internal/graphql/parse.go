@@ -0,0 +1,535 @@
+// Package graphql serves the subset of GraphQL the account API needs:
+// queries and mutations made of fields with arguments, aliases, variables
+// and nested selections, resolved by plain functions. Fragments,
+// directives, subscriptions and introspection are not supported; results
+// are shaped by projecting each resolver's JSON form onto the selection.
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation types.
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+// Document is a parsed request.
+type Document struct {
+	Operations []*Operation
+}
+
+// Operation is a query or mutation with its variables and selection.
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []VariableDefinition
+	Selections []*Field
+}
+
+// VariableDefinition declares a variable. Only whether the type is
+// non-null matters; the named type is not checked.
+type VariableDefinition struct {
+	Name     string
+	NonNull  bool
+	Default  interface{}
+	HasValue bool
+}
+
+// Field is one selected field. Arguments hold literal values, with
+// variables as Variable.
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]interface{}
+	Selections []*Field
+}
+
+// Key is the name the field's result appears under.
+func (f *Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Variable is a reference to a variable in an argument value.
+type Variable string
+
+// Enum is an enum value in an argument. Resolvers see it as a string.
+type Enum string
+
+// SyntaxError reports where a document could not be parsed.
+type SyntaxError struct {
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Message)
+}
+
+// Parse parses a GraphQL document.
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: lexer{source: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for p.token.kind != tokenEOF {
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+	if len(doc.Operations) == 0 {
+		return nil, &SyntaxError{Offset: 0, Message: "document has no operations"}
+	}
+	return doc, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	offset int
+}
+
+type lexer struct {
+	source string
+	pos    int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	if l.pos >= len(l.source) {
+		return token{kind: tokenEOF, offset: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.source[l.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), offset: start}, nil
+	case c == '.':
+		if strings.HasPrefix(l.source[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokenPunct, value: "...", offset: start}, nil
+		}
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.source[start:l.pos], offset: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	}
+	return token{}, &SyntaxError{Offset: start, Message: fmt.Sprintf("unexpected character %q", c)}
+}
+
+// skipIgnored skips whitespace, commas and comments.
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+	digits := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+	if l.pos == digits {
+		return token{}, &SyntaxError{Offset: start, Message: "expected digit"}
+	}
+
+	kind := tokenInt
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.pos++
+		}
+	}
+	return token{kind: kind, value: l.source[start:l.pos], offset: start}, nil
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	if strings.HasPrefix(l.source[l.pos:], `"""`) {
+		end := strings.Index(l.source[l.pos+3:], `"""`)
+		if end < 0 {
+			return token{}, &SyntaxError{Offset: start, Message: "unterminated block string"}
+		}
+		value := l.source[l.pos+3 : l.pos+3+end]
+		l.pos += end + 6
+		return token{kind: tokenString, value: strings.TrimSpace(value), offset: start}, nil
+	}
+
+	l.pos++
+	for l.pos < len(l.source) {
+		switch l.source[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '\n':
+			return token{}, &SyntaxError{Offset: start, Message: "unterminated string"}
+		case '"':
+			l.pos++
+			// GraphQL string escapes are a subset of JSON's.
+			value, err := strconv.Unquote(strings.ReplaceAll(l.source[start:l.pos], `\/`, `/`))
+			if err != nil {
+				return token{}, &SyntaxError{Offset: start, Message: "invalid string"}
+			}
+			return token{kind: tokenString, value: value, offset: start}, nil
+		default:
+			l.pos++
+		}
+	}
+	return token{}, &SyntaxError{Offset: start, Message: "unterminated string"}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	lexer lexer
+	token token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+	return nil
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.token.kind == tokenPunct && p.token.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.unexpected("expected " + punct)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.unexpected("expected name")
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected(message string) error {
+	found := p.token.value
+	if p.token.kind == tokenEOF {
+		found = "end of document"
+	}
+	return &SyntaxError{Offset: p.token.offset, Message: fmt.Sprintf("%s, found %q", message, found)}
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: OperationQuery}
+	if p.peek("{") {
+		selections, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		op.Selections = selections
+		return op, nil
+	}
+
+	if p.token.kind != tokenName {
+		return nil, p.unexpected("expected operation")
+	}
+	switch p.token.value {
+	case OperationQuery, OperationMutation:
+		op.Type = p.token.value
+	case "fragment":
+		return nil, &SyntaxError{Offset: p.token.offset, Message: "fragments are not supported"}
+	default:
+		return nil, &SyntaxError{Offset: p.token.offset, Message: fmt.Sprintf("%s operations are not supported", p.token.value)}
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.token.kind == tokenName {
+		op.Name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		variables, err := p.variableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.Variables = variables
+	}
+	if p.peek("@") {
+		return nil, &SyntaxError{Offset: p.token.offset, Message: "directives are not supported"}
+	}
+
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = selections
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var definitions []VariableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		nonNull, err := p.typeReference()
+		if err != nil {
+			return nil, err
+		}
+
+		definition := VariableDefinition{Name: name, NonNull: nonNull}
+		if p.peek("=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			value, err := p.value(true)
+			if err != nil {
+				return nil, err
+			}
+			definition.Default, definition.HasValue = value, true
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, p.advance()
+}
+
+// typeReference skips a type and reports whether it is non-null.
+func (p *parser) typeReference() (bool, error) {
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return false, err
+		}
+		if _, err := p.typeReference(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.name(); err != nil {
+		return false, err
+	}
+
+	if p.peek("!") {
+		return true, p.advance()
+	}
+	return false, nil
+}
+
+func (p *parser) selectionSet() ([]*Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []*Field
+	for !p.peek("}") {
+		if p.peek("...") {
+			return nil, &SyntaxError{Offset: p.token.offset, Message: "fragments are not supported"}
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, p.unexpected("expected field")
+	}
+	return fields, p.advance()
+}
+
+func (p *parser) field() (*Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name}
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if field.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+	}
+
+	if p.peek("(") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		field.Arguments = map[string]interface{}{}
+		for !p.peek(")") {
+			argument, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if field.Arguments[argument], err = p.value(false); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("@") {
+		return nil, &SyntaxError{Offset: p.token.offset, Message: "directives are not supported"}
+	}
+
+	if p.peek("{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+// value parses an argument value. Constant values, such as variable
+// defaults, can't refer to variables.
+func (p *parser) value(constant bool) (interface{}, error) {
+	t := p.token
+	switch {
+	case t.kind == tokenPunct && t.value == "$" && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return Variable(name), err
+	case t.kind == tokenInt:
+		n, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, &SyntaxError{Offset: t.offset, Message: "invalid integer"}
+		}
+		return n, p.advance()
+	case t.kind == tokenFloat:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, &SyntaxError{Offset: t.offset, Message: "invalid float"}
+		}
+		return f, p.advance()
+	case t.kind == tokenString:
+		return t.value, p.advance()
+	case t.kind == tokenName:
+		var value interface{}
+		switch t.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = Enum(t.value)
+		}
+		return value, p.advance()
+	case t.kind == tokenPunct && t.value == "[":
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.peek("]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.advance()
+	case t.kind == tokenPunct && t.value == "{":
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, p.advance()
+	}
+	return nil, p.unexpected("expected value")
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# Sign up and look at the result.
+		mutation SignUp($input: UserInput!, $tenant: String = "beta", $tags: [String!]) {
+			user: createUser(input: $input, tenant: $tenant, mode: STRICT) {
+				did
+				handle
+			}
+			claimHandle(input: {handle: "alice", email: "a\"b@example.com", ttl: 1.5e1, days: -3, ok: true, none: null}) {
+				expiresAt
+			}
+		}
+		{ __typename }
+	`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Document{Operations: []*Operation{
+		{
+			Type: OperationMutation,
+			Name: "SignUp",
+			Variables: []VariableDefinition{
+				{Name: "input", NonNull: true},
+				{Name: "tenant", Default: "beta", HasValue: true},
+				{Name: "tags"},
+			},
+			Selections: []*Field{
+				{
+					Alias:      "user",
+					Name:       "createUser",
+					Arguments:  map[string]interface{}{"input": Variable("input"), "tenant": Variable("tenant"), "mode": Enum("STRICT")},
+					Selections: []*Field{{Name: "did"}, {Name: "handle"}},
+				},
+				{
+					Name: "claimHandle",
+					Arguments: map[string]interface{}{"input": map[string]interface{}{
+						"handle": "alice",
+						"email":  `a"b@example.com`,
+						"ttl":    15.0,
+						"days":   int64(-3),
+						"ok":     true,
+						"none":   nil,
+					}},
+					Selections: []*Field{{Name: "expiresAt"}},
+				},
+			},
+		},
+		{
+			Type:       OperationQuery,
+			Selections: []*Field{{Name: "__typename"}},
+		},
+	}}, doc)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Empty", source: "  # nothing\n", expected: "document has no operations"},
+		{name: "Unclosed Selection", source: "{ user", expected: `expected name, found "end of document"`},
+		{name: "Empty Selection", source: "query { }", expected: `expected field, found "}"`},
+		{name: "Fragment", source: "{ user { ...Fields } }", expected: "fragments are not supported"},
+		{name: "Fragment Definition", source: "fragment Fields on User { did }", expected: "fragments are not supported"},
+		{name: "Directive", source: "{ user @skip(if: true) }", expected: "directives are not supported"},
+		{name: "Subscription", source: "subscription { user }", expected: "subscription operations are not supported"},
+		{name: "Unterminated String", source: `{ user(handle: "alice) }`, expected: "unterminated string"},
+		{name: "Variable In Default", source: "query ($a: String = $b) { user }", expected: `expected value, found "$"`},
+		{name: "Bad Character", source: "{ user% }", expected: `unexpected character '%'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.source)
+			assert.ErrorContains(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handleresolver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+)
+
+const (
+	TXTPrefix       = "_atproto."
+	WellKnownPath   = "/.well-known/atproto-did"
+	DefaultCacheTTL = 5 * time.Minute
+
+	txtDIDPrefix = "did="
+	// maxWellKnownSize bounds how much of a well-known response is read; a
+	// DID is well under this.
+	maxWellKnownSize = 2048
+)
+
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type cachedDID struct {
+	did     string
+	expires time.Time
+}
+
+// Resolver proves ownership of custom-domain handles the way the AT
+// Protocol does: the domain must publish the account's DID in an _atproto
+// TXT record or at /.well-known/atproto-did. Successful verifications are
+// cached for CacheTTL so retries and warm invocations skip the lookups.
+type Resolver struct {
+	DNS        TXTResolver
+	HTTPClient HTTPClient
+	CacheTTL   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedDID
+	now   func() time.Time
+}
+
+func NewResolver(dns TXTResolver, httpClient HTTPClient, cacheTTL time.Duration) *Resolver {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	return &Resolver{
+		DNS:        dns,
+		HTTPClient: httpClient,
+		CacheTTL:   cacheTTL,
+		cache:      map[string]cachedDID{},
+		now:        time.Now,
+	}
+}
+
+// VerifyHandle reports whether domain currently points at did.
+func (r *Resolver) VerifyHandle(ctx context.Context, domain, did string) (bool, error) {
+	domain = strings.ToLower(domain)
+	if r.cached(domain) == did {
+		return true, nil
+	}
+
+	resolved, err := r.Resolve(ctx, domain)
+	if err != nil {
+		return false, err
+	}
+	if resolved != did {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"domain":   domain,
+			"expected": did,
+			"resolved": resolved,
+		}).Warn("Custom domain does not point at the requested DID")
+		return false, nil
+	}
+
+	r.mu.Lock()
+	r.cache[domain] = cachedDID{did: did, expires: r.now().Add(r.CacheTTL)}
+	r.mu.Unlock()
+	return true, nil
+}
+
+func (r *Resolver) cached(domain string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.cache[domain]
+	if !ok {
+		return ""
+	}
+	if r.now().After(entry.expires) {
+		delete(r.cache, domain)
+		return ""
+	}
+	return entry.did
+}
+
+// Resolve returns the DID domain publishes, checking DNS before the
+// well-known endpoint. It returns "" when neither method publishes one and
+// an error only when both lookups failed outright.
+func (r *Resolver) Resolve(ctx context.Context, domain string) (string, error) {
+	did, dnsErr := r.resolveDNS(ctx, domain)
+	if did != "" {
+		return did, nil
+	}
+
+	did, httpErr := r.resolveWellKnown(ctx, domain)
+	if did != "" {
+		return did, nil
+	}
+
+	if dnsErr != nil && httpErr != nil {
+		return "", fmt.Errorf("failed to resolve handle %s: %w", domain, errors.Join(dnsErr, httpErr))
+	}
+	return "", nil
+}
+
+func (r *Resolver) resolveDNS(ctx context.Context, domain string) (string, error) {
+	records, err := r.DNS.LookupTXT(ctx, TXTPrefix+domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", nil
+		}
+		logging.FromContext(ctx).WithError(err).WithField("domain", domain).Warn("DNS handle lookup failed")
+		return "", fmt.Errorf("DNS lookup failed: %w", err)
+	}
+
+	for _, record := range records {
+		if did, ok := strings.CutPrefix(strings.TrimSpace(record), txtDIDPrefix); ok {
+			return did, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *Resolver) resolveWellKnown(ctx context.Context, domain string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+domain+WellKnownPath, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create well-known request: %w", err)
+	}
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("domain", domain).Warn("Well-known handle lookup failed")
+		return "", fmt.Errorf("well-known request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	line, err := bufio.NewReader(io.LimitReader(resp.Body, maxWellKnownSize)).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read well-known response: %w", err)
+	}
+
+	did := strings.TrimSpace(line)
+	if !strings.HasPrefix(did, "did:") {
+		return "", nil
+	}
+	return did, nil
+}
@@ -0,0 +1,108 @@
+package handleresolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+type MockTXTResolver struct {
+	LookupFunc func(ctx context.Context, name string) ([]string, error)
+}
+
+func (m *MockTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return m.LookupFunc(ctx, name)
+}
+
+var errNotFound = &net.DNSError{Err: "no such host", IsNotFound: true}
+
+func wellKnown(status int, body string, err error) *MockHTTPClient {
+	return &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+	}}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		txt           []string
+		dnsErr        error
+		httpStatus    int
+		httpBody      string
+		httpErr       error
+		expected      string
+		expectedError string
+	}{
+		{name: "TXT Record", txt: []string{"v=spf1 -all", "did=did:plc:abc123"}, expected: "did:plc:abc123"},
+		{name: "Well-Known Fallback", dnsErr: errNotFound, httpStatus: http.StatusOK, httpBody: "did:plc:abc123\n", expected: "did:plc:abc123"},
+		{name: "Well-Known Not A DID", dnsErr: errNotFound, httpStatus: http.StatusOK, httpBody: "<html>", expected: ""},
+		{name: "Nothing Published", dnsErr: errNotFound, httpStatus: http.StatusNotFound, expected: ""},
+		{name: "DNS Failure Falls Back", dnsErr: errors.New("timeout"), httpStatus: http.StatusOK, httpBody: "did:web:example.com", expected: "did:web:example.com"},
+		{name: "Both Fail", dnsErr: errors.New("timeout"), httpErr: errors.New("connection refused"), expectedError: "failed to resolve handle alice.example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolver := NewResolver(&MockTXTResolver{LookupFunc: func(ctx context.Context, name string) ([]string, error) {
+				assert.Equal(t, "_atproto.alice.example.com", name)
+				return test.txt, test.dnsErr
+			}}, wellKnown(test.httpStatus, test.httpBody, test.httpErr), time.Minute)
+
+			did, err := resolver.Resolve(context.Background(), "alice.example.com")
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, did)
+		})
+	}
+}
+
+func TestVerifyHandleCachesPositiveResults(t *testing.T) {
+	lookups := 0
+	resolver := NewResolver(&MockTXTResolver{LookupFunc: func(ctx context.Context, name string) ([]string, error) {
+		lookups++
+		return []string{"did=did:plc:abc123"}, nil
+	}}, wellKnown(http.StatusNotFound, "", nil), time.Minute)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	ok, err := resolver.VerifyHandle(context.Background(), "Alice.Example.com", "did:plc:abc123")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = resolver.VerifyHandle(context.Background(), "alice.example.com", "did:plc:abc123")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, lookups)
+
+	ok, err = resolver.VerifyHandle(context.Background(), "alice.example.com", "did:plc:other")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 2, lookups)
+
+	now = now.Add(2 * time.Minute)
+	_, err = resolver.VerifyHandle(context.Background(), "alice.example.com", "did:plc:abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, lookups)
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Admin actions.
+const (
+	AdminActionLookup             = "lookup"
+	AdminActionResendVerification = "resend_verification"
+	AdminActionMintInvites        = "mint_invites"
+	AdminActionBootstrap          = "bootstrap"
+	AdminActionQuota              = "quota"
+	AdminActionStats              = "stats"
+)
+
+// maxMintedInvites bounds the invite codes minted in one request.
+const maxMintedInvites = 100
+
+// AdminHandler serves the operator actions on accounts that have no handler
+// of their own: looking an account up, re-sending its verification email,
+// minting invite codes, provisioning the operator accounts and reporting the
+// signup quotas and stats. The admin CLI calls it in-process; it is not
+// deployed as a function.
+type AdminHandler struct {
+	*Services
+	Signups signupRunner
+}
+
+func NewAdminHandler(services *Services) *AdminHandler {
+	return &AdminHandler{
+		Services: services,
+		Signups:  NewUserHandler(services),
+	}
+}
+
+func (h *AdminHandler) Handle(ctx context.Context, req models.AdminRequest) (*models.AdminResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "admin."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"user":   req.User,
+		"tenant": req.Tenant,
+	}).Info("Processing admin request")
+
+	cfg, awsCfg := h.Config, h.AWS
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+
+	switch req.Action {
+	case AdminActionLookup:
+		account, err := findAccount(ctx, store, tenant, req.User)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AdminResponse{Account: &account}, nil
+	case AdminActionResendVerification:
+		account, err := findAccount(ctx, store, tenant, req.User)
+		if err != nil {
+			return nil, err
+		}
+		if account.Verified || (account.Status != models.StatusPending && account.Status != models.StatusPendingReview) {
+			return nil, apperr.Errorf(apperr.Conflict, "conflict: account %s is %s", account.DID, account.Status)
+		}
+		user := models.CreateUserResponse{DID: account.DID, Handle: account.Handle}
+		queued, err := deliverWelcomeEmail(ctx, h.Email, cfg, tenant, s3.NewFromConfig(awsCfg), h.Limiter, h.Stores(""), store, user, account.Email)
+		if errors.Is(err, errEmailNotConfigured) {
+			return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("internal error: %w", err)
+		}
+		return &models.AdminResponse{Account: &account, EmailQueued: queued}, nil
+	case AdminActionMintInvites:
+		codes, err := h.mintInvites(ctx, cfg, tenant, req.Count)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AdminResponse{InviteCodes: codes}, nil
+	case AdminActionBootstrap:
+		accounts, err := h.bootstrap(ctx, cfg, store, tenant, req.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AdminResponse{Bootstrapped: accounts}, nil
+	case AdminActionQuota:
+		quota := signupQuota(cfg, h.Quota)
+		if quota == nil {
+			return nil, apperr.Errorf(apperr.NotFound, "not found: no signup quota is configured")
+		}
+		usage, err := quota.Usage(ctx, req.Domain)
+		if err != nil {
+			return nil, fmt.Errorf("internal error: failed to read signup quotas: %w", err)
+		}
+		return &models.AdminResponse{Quota: &usage}, nil
+	case AdminActionStats:
+		stats, err := listSignupStats(ctx, cfg, store, req.Period, req.Count, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &models.AdminResponse{Stats: stats}, nil
+	default:
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown action %q", req.Action)
+	}
+}
+
+// findAccount loads the account named by identifier. A handle without a
+// domain is taken to be under the tenant's suffix.
+func findAccount(ctx context.Context, store postgres.UserFinder, tenant config.Tenant, identifier string) (models.UserRecord, error) {
+	identifier = strings.TrimPrefix(strings.TrimSpace(identifier), "@")
+	if identifier == "" {
+		return models.UserRecord{}, apperr.Errorf(apperr.Validation, "validation error: user is required")
+	}
+
+	did := identifier
+	if !strings.HasPrefix(identifier, "did:") {
+		if !strings.Contains(identifier, "@") && !strings.Contains(identifier, ".") {
+			identifier += tenant.HandleSuffix
+		}
+		var err error
+		if did, err = store.FindUserDID(ctx, identifier); err != nil {
+			if errors.Is(err, postgres.ErrUserNotFound) {
+				return models.UserRecord{}, apperr.Errorf(apperr.NotFound, "not found: no account for %s", identifier)
+			}
+			return models.UserRecord{}, fmt.Errorf("internal error: %w", err)
+		}
+	}
+
+	account, err := store.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return models.UserRecord{}, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return models.UserRecord{}, fmt.Errorf("internal error: %w", err)
+	}
+	return account, nil
+}
+
+// mintInvites creates count single-use invite codes on the tenant's PDS.
+func (h *AdminHandler) mintInvites(ctx context.Context, cfg *config.Config, tenant config.Tenant, count int) ([]string, error) {
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 || count > maxMintedInvites {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: count must be between 1 and %d", maxMintedInvites)
+	}
+
+	adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.Secrets, tenant.AdminSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: failed to retrieve admin credentials: %w", err)
+	}
+
+	client := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		invite, err := client.CreateInviteCode(ctx, adminCreds)
+		if err != nil {
+			// The codes minted so far are valid, so they are logged for the
+			// operator.
+			logging.FromContext(ctx).WithField("minted", codes).Error("Stopped minting invite codes")
+			return nil, fmt.Errorf("minted %d of %d invite codes: %w", len(codes), count, err)
+		}
+		codes = append(codes, invite.Code)
+	}
+	return codes, nil
+}
+
+// bootstrap signs up each of the configured operator accounts that doesn't
+// exist yet, with a generated password. Their roles come from the role
+// assignments, like any other signup. Running it again reports the
+// existing accounts and retries the ones that failed.
+func (h *AdminHandler) bootstrap(ctx context.Context, cfg *config.Config, store postgres.UserFinder, tenant config.Tenant, tenantID string) ([]models.BootstrappedAccount, error) {
+	if len(cfg.BootstrapAccounts) == 0 {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: BOOTSTRAP_ACCOUNTS is not set")
+	}
+
+	accounts := make([]models.BootstrappedAccount, 0, len(cfg.BootstrapAccounts))
+	for _, operator := range cfg.BootstrapAccounts {
+		role, _ := cfg.ProfileDefaults.RoleAssignments.Role(operator.Email)
+		account := models.BootstrappedAccount{Handle: operator.Handle, Email: operator.Email, Role: role}
+
+		existing, err := findAccount(ctx, store, tenant, operator.Email)
+		if err == nil {
+			account.Handle, account.DID, account.Role, account.Existing = existing.Handle, existing.DID, existing.Role, true
+			accounts = append(accounts, account)
+			continue
+		}
+		if apperr.CategoryOf(err) != apperr.NotFound {
+			return nil, err
+		}
+
+		if account.Password, err = generatePassword(); err != nil {
+			return nil, fmt.Errorf("internal error: failed to generate password: %w", err)
+		}
+		user, err := h.Signups.Handle(ctx, models.UserRequest{
+			Handle:   operator.Handle,
+			Email:    operator.Email,
+			Password: account.Password,
+			Tenant:   tenantID,
+		})
+		if err != nil {
+			// The accounts already created keep their passwords only in
+			// this response, so a failure is reported with the account
+			// rather than failing the action.
+			logging.FromContext(ctx).WithError(err).WithField("handle", operator.Handle).Error("Failed to create operator account")
+			account.Password, account.Error = "", err.Error()
+			accounts = append(accounts, account)
+			continue
+		}
+		account.Handle, account.DID = user.Handle, user.DID
+		accounts = append(accounts, account)
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"did":  user.DID,
+			"role": role,
+		}).Info("Created operator account")
+	}
+	return accounts, nil
+}
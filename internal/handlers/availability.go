@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/handleresolver"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"golang.org/x/sync/errgroup"
+)
+
+// AvailabilityHandler checks a handle and an email address for the signup
+// form before it is submitted. The handle goes through the signup's handle
+// rules, including the blocklist, and is then looked up in storage and on
+// the PDS; the email address goes through the email rules. Both fields are
+// checked at once, and the handle's two lookups run in parallel.
+type AvailabilityHandler struct {
+	*Services
+	pds          pdsClients
+	resolverOnce sync.Once
+	resolver     *handleresolver.Resolver
+}
+
+func NewAvailabilityHandler(services *Services) *AvailabilityHandler {
+	return &AvailabilityHandler{Services: services}
+}
+
+func (h *AvailabilityHandler) Handle(ctx context.Context, req models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "check_availability")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithField("tenant", req.Tenant).Info("Processing availability check")
+
+	if req.Handle == "" && req.Email == "" {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeMissingFields, "handle or email is required"))
+	}
+
+	cfg := h.Config
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	dbClient := h.Stores(tenant.TablePrefix)
+	opts := helper.ValidationOptions{
+		HandleSuffix:        tenant.HandleSuffix,
+		AllowUnicodeHandles: cfg.AllowUnicodeHandles,
+		ProfanityMode:       cfg.ProfanityMode,
+		Blocklist:           dbClient,
+	}
+	if cfg.HandleClaims {
+		opts.HandleClaims = dbClient
+	}
+	if cfg.HandleQuarantine > 0 {
+		opts.HandleQuarantine = dbClient
+	}
+	if cfg.AllowCustomDomainHandles {
+		opts.DomainVerifier = h.domainResolver(cfg)
+	}
+	// The email address is passed with the handle so the handle's claim
+	// holder can see it as available.
+	request := models.UserRequest{Handle: req.Handle, Email: req.Email}
+
+	var resp models.AvailabilityResponse
+	g, gctx := errgroup.WithContext(ctx)
+	if req.Handle != "" {
+		g.Go(func() error {
+			validator := helper.NewFieldValidator(dbClient, opts, helper.FieldHandle)
+			validator.Remove(tenant.DisabledValidationRules...)
+			// A custom domain only has to point at the account by the time
+			// it signs up.
+			validator.Remove(helper.RuleDomainOwnership)
+			var err error
+			resp.Handle, err = h.handleAvailability(gctx, cfg, tenant, dbClient, validator, request, req.Locale)
+			return err
+		})
+	}
+	if req.Email != "" {
+		g.Go(func() error {
+			validator := helper.NewFieldValidator(dbClient, opts, helper.FieldEmail)
+			validator.Remove(tenant.DisabledValidationRules...)
+			validation, err := validator.Validate(gctx, request)
+			if err == nil {
+				resp.Email = &models.FieldAvailability{Value: validation.User.Email, Available: true}
+				return nil
+			}
+			resp.Email, err = unavailable(req.Email, err, req.Locale)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to check availability")
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// handleAvailability validates the handle and then looks it up in storage
+// and on the PDS at the same time. A taken handle comes with suggestions.
+func (h *AvailabilityHandler) handleAvailability(ctx context.Context, cfg *config.Config, tenant config.Tenant, dbClient postgres.Store, validator *helper.Validator, request models.UserRequest, locale string) (*models.FieldAvailability, error) {
+	validation, err := validator.Validate(ctx, request)
+	if err != nil {
+		return unavailable(request.Handle, err, locale)
+	}
+	if len(validation.Flags) > 0 {
+		// Signing up with a flagged handle is held for review rather than
+		// refused, so the handle is still available.
+		logging.FromContext(ctx).WithField("flags", validation.Flags).Info("Available handle would be held for review")
+	}
+	handle := validation.User.Handle
+
+	client := h.pds.client(cfg, tenant)
+	onPDS := func(ctx context.Context, handle string) (bool, error) {
+		did, err := client.ResolveHandle(ctx, handle)
+		return did == "", err
+	}
+	checks := []helper.HandleAvailability{helper.StorageAvailability(dbClient), onPDS}
+	free := make([]bool, len(checks))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, check := range checks {
+		i, check := i, check
+		g.Go(func() error {
+			var err error
+			free[i], err = check(gctx, handle)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: failed to check handle: %w", err)
+	}
+	if free[0] && free[1] {
+		return &models.FieldAvailability{Value: handle, Available: true}, nil
+	}
+
+	code := validate.CodeHandleTaken
+	return &models.FieldAvailability{
+		Value:       handle,
+		Code:        code,
+		Message:     helper.Message(code, locale),
+		Suggestions: helper.SuggestHandles(ctx, handle, tenant.HandleSuffix, helper.AllAvailable(checks...)),
+	}, nil
+}
+
+// unavailable is the result for a value its rules rejected, with the
+// suggestions the rejection carries. Rules that failed to run, rather than
+// rejecting the value, fail the check.
+func unavailable(value string, err error, locale string) (*models.FieldAvailability, error) {
+	code, message := helper.LocalizeError(err, locale)
+	if code == "" || code == validate.CodeInternal {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: %w", err)
+	}
+	return &models.FieldAvailability{Value: value, Code: code, Message: message, Suggestions: helper.Suggestions(err)}, nil
+}
+
+// domainResolver is shared across invocations so verified domains stay
+// cached while the container is warm.
+func (h *AvailabilityHandler) domainResolver(cfg *config.Config) *handleresolver.Resolver {
+	h.resolverOnce.Do(func() {
+		h.resolver = handleresolver.NewResolver(net.DefaultResolver, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.DomainCacheTTL)
+	})
+	return h.resolver
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/avatar"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// AvatarHandler applies the profile picture a new account uploaded through
+// the link in its signup response.
+type AvatarHandler struct {
+	*Services
+}
+
+func NewAvatarHandler(services *Services) *AvatarHandler {
+	return &AvatarHandler{Services: services}
+}
+
+func (h *AvatarHandler) Handle(ctx context.Context, req models.AvatarRequest) (*models.AvatarResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "avatar")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithField("tenant", req.Tenant).Info("Processing avatar upload")
+
+	if req.DID == "" || req.AccessJWT == "" {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeMissingFields, "did and accessJwt are required"))
+	}
+
+	cfg, awsCfg := h.Config, h.AWS
+	if cfg.AvatarBucket == "" {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: avatar uploads are not offered")
+	}
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	uploads := avatarUploads(cfg, awsCfg)
+	uploads.Accounts = h.Stores(tenant.TablePrefix)
+	uploads.PDS = ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+
+	resp, err := uploads.Complete(ctx, tenant.ID, req.DID, req.AccessJWT)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func avatarUploads(cfg *config.Config, awsCfg aws.Config) *avatar.Uploads {
+	s3Client := s3.NewFromConfig(awsCfg)
+	return &avatar.Uploads{
+		Objects:   s3Client,
+		Presigner: s3.NewPresignClient(s3Client),
+		Bucket:    cfg.AvatarBucket,
+		URLTTL:    cfg.AvatarUploadTTL,
+		MaxBytes:  cfg.AvatarMaxBytes,
+	}
+}
+
+// avatarUpload presigns the profile picture upload a signup asked for, or
+// returns nil.
+func avatarUpload(ctx context.Context, cfg *config.Config, awsCfg aws.Config, tenant config.Tenant, did string) *models.AvatarUpload {
+	upload, err := avatarUploads(cfg, awsCfg).Presign(ctx, tenant.ID, did)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Account created without an avatar upload link")
+		return nil
+	}
+	return &upload
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/billing"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// createStripeCustomer creates the account's Stripe customer and stores its
+// ID. The account already exists, so failures are logged rather than
+// returned; accounts left without a customer can be picked up later by
+// their DID.
+func (h *UserHandler) createStripeCustomer(ctx context.Context, cfg *config.Config, tenant config.Tenant, store postgres.BillingStore, user models.CreateUserResponse, email string) {
+	creds, err := helper.RetrieveStripeCreds(ctx, h.Secrets, cfg.StripeSecretName)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to retrieve Stripe credentials")
+		return
+	}
+
+	client := billing.NewStripeClient(creds.SecretKey, newHTTPClient(cfg, faults.TargetBilling), cfg.Retry)
+	customerID, err := client.CreateCustomer(ctx, billing.Customer{
+		DID:    user.DID,
+		Email:  email,
+		Handle: user.Handle,
+		Tenant: tenant.ID,
+	})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without a Stripe customer")
+		return
+	}
+
+	if err := store.SetStripeCustomerID(ctx, user.DID, customerID); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"did":         user.DID,
+			"customer_id": customerID,
+		}).Error("Stripe customer created but not linked to the account")
+	}
+}
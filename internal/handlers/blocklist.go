@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+const (
+	BlocklistActionAdd    = postgres.BlocklistActionAdd
+	BlocklistActionRemove = postgres.BlocklistActionRemove
+	BlocklistActionList   = "list"
+	BlocklistActionAudit  = "audit"
+)
+
+// BlocklistHandler serves the admin operations on a tenant's runtime
+// blocklist. It is deployed as a separate function so only operators can
+// invoke it. Changes are recorded under the caller's authenticated
+// identity (see caller.From), so they are refused without one.
+type BlocklistHandler struct {
+	*Services
+}
+
+func NewBlocklistHandler(services *Services) *BlocklistHandler {
+	return &BlocklistHandler{Services: services}
+}
+
+func (h *BlocklistHandler) Handle(ctx context.Context, req models.BlocklistRequest) (*models.BlocklistResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "blocklist."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	ctx = logging.WithHandle(ctx, req.Handle)
+	actor, _ := caller.From(ctx)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"handle": req.Handle,
+		"actor":  actor,
+		"tenant": req.Tenant,
+	}).Info("Processing blocklist request")
+
+	tenant, err := h.Config.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+
+	ctx = logging.WithTenant(ctx, tenant.ID)
+	return handleBlocklistRequest(ctx, h.Stores(tenant.TablePrefix), tenant.HandleSuffix, req)
+}
+
+func handleBlocklistRequest(ctx context.Context, store postgres.BlocklistStore, suffix string, req models.BlocklistRequest) (*models.BlocklistResponse, error) {
+	handle := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(req.Handle), suffix))
+	actor, _ := caller.From(ctx)
+
+	switch req.Action {
+	case BlocklistActionAdd:
+		if handle == "" || actor == "" {
+			return nil, apperr.Errorf(apperr.Validation, "validation error: handle and an authenticated caller are required")
+		}
+		if err := store.BlockHandle(ctx, handle, req.Reason, actor); err != nil {
+			return nil, fmt.Errorf("internal error: %w", err)
+		}
+		return &models.BlocklistResponse{Handles: []models.BlockedHandle{{
+			Handle:    handle,
+			Reason:    req.Reason,
+			BlockedBy: actor,
+		}}}, nil
+	case BlocklistActionRemove:
+		if handle == "" || actor == "" {
+			return nil, apperr.Errorf(apperr.Validation, "validation error: handle and an authenticated caller are required")
+		}
+		if err := store.UnblockHandle(ctx, handle, req.Reason, actor); err != nil {
+			if errors.Is(err, postgres.ErrHandleNotBlocked) {
+				return nil, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+			}
+			return nil, fmt.Errorf("internal error: %w", err)
+		}
+		return &models.BlocklistResponse{}, nil
+	case BlocklistActionList:
+		handles, err := store.ListBlockedHandles(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("internal error: %w", err)
+		}
+		return &models.BlocklistResponse{Handles: handles}, nil
+	case BlocklistActionAudit:
+		entries, err := store.ListBlocklistAudit(ctx, req.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("internal error: %w", err)
+		}
+		return &models.BlocklistResponse{Audit: entries}, nil
+	default:
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown blocklist action %q", req.Action)
+	}
+}
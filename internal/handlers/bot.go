@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// botAppPasswordName labels the app password a bot is issued in the
+// account's app password list.
+const botAppPasswordName = "ShareFrame bot"
+
+// BotSignupWindow is the period BotSignupsPerDay counts an owner's bots
+// over.
+const BotSignupWindow = 24 * time.Hour
+
+// BotHandler provisions bot accounts for automation. Every bot is owned by
+// an existing person, is labelled as a bot on its profile and stored with
+// the bot role, and is issued an app password rather than a password
+// anyone could sign in to the app with. Bots have no mailbox, so they get
+// an address on BotEmailDomain and no welcome email.
+type BotHandler struct {
+	*Services
+	pds pdsClients
+}
+
+func NewBotHandler(services *Services) *BotHandler {
+	return &BotHandler{Services: services}
+}
+
+func (h *BotHandler) Handle(ctx context.Context, req models.BotRequest) (*models.BotResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "create_bot")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"tenant": req.Tenant,
+		"owner":  req.Owner,
+	}).Info("Processing create bot request")
+
+	if req.Handle == "" || req.Owner == "" {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeMissingFields, "handle and owner are required"))
+	}
+	if !strings.HasPrefix(req.Owner, "did:") {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeUnknownOwner, "owner must be a DID").With("owner", req.Owner))
+	}
+
+	cfg := h.Config
+	if !cfg.BotAccounts {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: bot accounts are not offered")
+	}
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	dbClient := h.Stores(tenant.TablePrefix)
+
+	if err := checkBotOwner(ctx, dbClient, req.Owner); err != nil {
+		return nil, err
+	}
+	if h.Limiter != nil {
+		if err := limitBotSignups(ctx, h.Limiter, req.Owner, cfg.BotSignupsPerDay); err != nil {
+			return nil, err
+		}
+	}
+
+	event, err := botSignup(req, cfg.BotEmailDomain)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: could not prepare bot account: %w", err)
+	}
+
+	validationOpts := helper.ValidationOptions{
+		HandleSuffix:        tenant.HandleSuffix,
+		AllowUnicodeHandles: cfg.AllowUnicodeHandles,
+		ProfanityMode:       cfg.ProfanityMode,
+		Blocklist:           dbClient,
+	}
+	if cfg.HandleClaims {
+		validationOpts.HandleClaims = dbClient
+	}
+	if cfg.HandleQuarantine > 0 {
+		validationOpts.HandleQuarantine = dbClient
+	}
+	validator := helper.NewValidator(dbClient, validationOpts)
+	validator.Remove(tenant.DisabledValidationRules...)
+
+	validation, err := validator.Validate(ctx, event)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Validation error")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	// The validator only knows people and organizations; the type is set
+	// once the bot has passed the checks a person would.
+	event = validation.User
+	event.AccountType, event.Owners = models.AccountTypeBot, []string{req.Owner}
+
+	creds, err := fetchPDSCredentials(ctx, h.Secrets, cfg, tenant)
+	if err != nil {
+		return nil, err
+	}
+	user, err := registerOnPDS(ctx, cfg, &h.pds, tenant, dbClient, creds, event, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	atProtoClient := h.pds.client(cfg, tenant)
+	appPassword, err := atProtoClient.CreateAppPassword(ctx, user.AccessJWT, botAppPasswordName)
+	if err != nil {
+		// A bot without an app password can't be used by anyone, so the
+		// account is removed rather than left behind.
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Failed to create bot app password")
+		h.deleteBot(ctx, atProtoClient, tenant, user.DID)
+		return nil, fmt.Errorf("failed to create bot app password: %w", err)
+	}
+	logging.RegisterSecrets(appPassword)
+
+	record := cfg.ProfileDefaults.NewUserRecord(user, event)
+	record.Role, record.Status = models.RoleBot, models.StatusActive
+	if len(validation.Flags) > 0 {
+		record.Status, record.ReviewFlags = models.StatusPendingReview, validation.Flags
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": user.Handle,
+			"flags":  validation.Flags,
+		}).Warn("Bot created pending review")
+	}
+	if err := dbClient.StoreUser(ctx, record); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to store bot in PostgreSQL")
+		return nil, fmt.Errorf("internal error: failed to store bot data: %w", err)
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":    user.DID,
+		"handle": user.Handle,
+		"owner":  req.Owner,
+	}).Info("Successfully created and stored bot")
+
+	if err := dbClient.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:   postgres.AuditAccountCreated,
+		DID:     user.DID,
+		Handle:  user.Handle,
+		Actor:   req.Owner,
+		Details: map[string]string{"status": record.Status, "account_type": models.AccountTypeBot},
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Bot created without an audit event")
+	}
+
+	publishers := accountEventPublishers(ctx, h.Services)
+	publishBotCreated(ctx, publishers, tenant.ID, user)
+	if record.Status == models.StatusPendingReview {
+		publishReviewRequested(ctx, publishers, tenant.ID, user, record.ReviewFlags)
+	}
+
+	return &models.BotResponse{DID: user.DID, Handle: user.Handle, Owner: req.Owner, AppPassword: appPassword}, nil
+}
+
+// checkBotOwner requires the owner to be a person whose account is in use.
+func checkBotOwner(ctx context.Context, store postgres.Store, did string) error {
+	owner, err := store.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeUnknownOwner, "owner %s has no account", did).With("owner", did))
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to look up bot owner")
+		return apperr.Errorf(apperr.Internal, "internal error: failed to look up owner: %w", err)
+	}
+	if owner.AccountType == models.AccountTypeBot ||
+		(owner.Status != models.StatusVerified && owner.Status != models.StatusActive) {
+		return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeUnknownOwner, "owner %s can't own bots", did).With("owner", did))
+	}
+	return nil
+}
+
+// limitBotSignups allows each owner max bots per BotSignupWindow. The
+// limiter fails open like the other signup limits.
+func limitBotSignups(ctx context.Context, limiter ratelimit.Limiter, owner string, max int) error {
+	decision, err := limiter.Allow(ctx, "bot_signup:"+owner, ratelimit.Limit{Max: max, Window: BotSignupWindow})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to check bot signup limit")
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"owner": owner,
+		"count": decision.Count,
+	}).Warn("Bot signup limit reached")
+	return apperr.Wrap(apperr.RateLimited, validate.NewError(validate.CodeRateLimited, "owner has created %d bots in the last day", max).
+		With("retryAfter", int(math.Ceil(decision.RetryAfter.Seconds()))))
+}
+
+// botSignup turns req into the signup it is validated and registered as.
+// The password is random and never shown; the bot signs in with its app
+// password.
+func botSignup(req models.BotRequest, emailDomain string) (models.UserRequest, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return models.UserRequest{}, err
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return models.UserRequest{}, err
+	}
+	logging.RegisterSecrets(password)
+
+	return models.UserRequest{
+		Handle:      req.Handle,
+		Email:       "bot-" + hex.EncodeToString(b[:]) + "@" + emailDomain,
+		Password:    password,
+		DisplayName: req.DisplayName,
+		Tenant:      req.Tenant,
+	}, nil
+}
+
+func (h *BotHandler) deleteBot(ctx context.Context, client *ATProtocol.ATProtocolClient, tenant config.Tenant, did string) {
+	adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.Secrets, tenant.AdminSecretName)
+	if err == nil {
+		err = client.DeleteAccount(ctx, adminCreds, did)
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to remove bot without an app password")
+	}
+}
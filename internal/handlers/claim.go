@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// ClaimHandler reserves a handle for an email address ahead of signup, for
+// the pre-launch handle claim campaign. Only a signup with the same address
+// can take a claimed handle until the claim expires.
+type ClaimHandler struct {
+	*Services
+}
+
+func NewClaimHandler(services *Services) *ClaimHandler {
+	return &ClaimHandler{Services: services}
+}
+
+func (h *ClaimHandler) Handle(ctx context.Context, req models.HandleClaimRequest) (*models.HandleClaimResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "claim_handle")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithField("tenant", req.Tenant).Info("Processing handle claim")
+
+	if req.Handle == "" || req.Email == "" {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeMissingFields, "handle and email are required"))
+	}
+
+	cfg := h.Config
+	if !cfg.HandleClaims {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: handle claims are not open")
+	}
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	dbClient := h.Stores(tenant.TablePrefix)
+	validationOpts := helper.ValidationOptions{
+		HandleSuffix:        tenant.HandleSuffix,
+		AllowUnicodeHandles: cfg.AllowUnicodeHandles,
+		ProfanityMode:       cfg.ProfanityMode,
+		Blocklist:           dbClient,
+		HandleClaims:        dbClient,
+	}
+	if cfg.HandleQuarantine > 0 {
+		validationOpts.HandleQuarantine = dbClient
+	}
+	validator := helper.NewClaimValidator(dbClient, validationOpts)
+	validator.Remove(tenant.DisabledValidationRules...)
+
+	validation, err := validator.Validate(ctx, models.UserRequest{Handle: req.Handle, Email: req.Email})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Validation error")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	if len(validation.Flags) > 0 {
+		// Flagged handles go to review at signup; a claim can't be reviewed.
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeBlockedHandle, "%v", helper.BlockedHandle))
+	}
+	handle := validation.User.Handle
+
+	taken, err := dbClient.CheckHandleExists(ctx, handle)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: failed to check handle: %w", err)
+	}
+	if taken {
+		return nil, apperr.Wrap(apperr.Conflict, validate.NewError(validate.CodeHandleTaken, "handle is already taken"))
+	}
+
+	claim, ok, err := dbClient.ClaimHandle(ctx, handle, validation.User.Email, cfg.HandleClaimTTL)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: failed to claim handle: %w", err)
+	}
+	if !ok {
+		return nil, apperr.Wrap(apperr.Conflict, validate.NewError(validate.CodeHandleClaimed, "handle was claimed by someone else"))
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle":     handle,
+		"expires_at": claim.ExpiresAt,
+	}).Info("Handle claimed")
+	return &models.HandleClaimResponse{Handle: claim.Handle, ExpiresAt: claim.ExpiresAt}, nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/crm"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// Reasons a CRM sync is skipped, as reported in CRMSyncResponse.Skipped.
+const (
+	CRMSkippedDisabled   = "disabled"
+	CRMSkippedUnverified = "unverified"
+)
+
+// CRMHandler syncs an account to the CRM once its email is verified. It is
+// deployed as a separate function, invoked by the verification flow, so a
+// CRM outage never touches signup; failed syncs are returned as upstream
+// errors for the invoker to retry.
+type CRMHandler struct {
+	*Services
+}
+
+func NewCRMHandler(services *Services) *CRMHandler {
+	return &CRMHandler{Services: services}
+}
+
+func (h *CRMHandler) Handle(ctx context.Context, req models.CRMSyncRequest) (*models.CRMSyncResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "crm.sync")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":    req.DID,
+		"tenant": req.Tenant,
+	}).Info("Processing CRM sync")
+
+	if req.DID == "" {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: did is required")
+	}
+
+	cfg := h.Config
+	if cfg.CRMProvider == "" || cfg.CRMSyncDisabled {
+		logging.FromContext(ctx).Info("CRM sync is disabled; skipping")
+		return &models.CRMSyncResponse{Skipped: CRMSkippedDisabled}, nil
+	}
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+	contact, err := store.GetCRMContact(ctx, req.DID)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("internal error: could not load account: %w", err)
+	}
+	if !contact.Verified {
+		logging.FromContext(ctx).WithField("did", req.DID).Info("Account not verified yet; skipping CRM sync")
+		return &models.CRMSyncResponse{Skipped: CRMSkippedUnverified}, nil
+	}
+	contact.Tenant = tenant.ID
+
+	creds, err := helper.RetrieveCRMCreds(ctx, h.Secrets, cfg.CRMSecretName)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: could not retrieve CRM credentials: %w", err)
+	}
+	syncer, err := crm.New(cfg.CRMProvider, creds.APIKey, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.Retry)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: %w", err)
+	}
+	if err := syncer.UpsertContact(ctx, contact); err != nil {
+		return nil, apperr.Errorf(apperr.Upstream, "failed to sync CRM contact: %w", err)
+	}
+
+	return &models.CRMSyncResponse{Synced: true}, nil
+}
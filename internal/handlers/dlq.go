@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Reasons a dead-lettered signup is filed for review, besides the
+// validation code of a rejected one.
+const (
+	ReviewReasonMalformed        = "malformed_message"
+	ReviewReasonRetriesExhausted = "retries_exhausted"
+)
+
+// signupRunner runs one signup; UserHandler in production.
+type signupRunner interface {
+	Handle(ctx context.Context, event models.UserRequest) (*models.CreateUserResponse, error)
+}
+
+// DLQHandler consumes the signup dead-letter queue. Each message is run
+// through the signup flow again: retryable failures, such as a PDS outage
+// or a rate limit, are left on the queue to be retried, and everything
+// else is filed in the manual-review table and removed. The event source
+// mapping must enable ReportBatchItemFailures.
+type DLQHandler struct {
+	*Services
+	Signups signupRunner
+}
+
+func NewDLQHandler(services *Services) *DLQHandler {
+	return &DLQHandler{
+		Services: services,
+		Signups:  NewUserHandler(services),
+	}
+}
+
+func (h *DLQHandler) Handle(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "dlq.redrive")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+
+	reviews := h.Stores("")
+
+	var response events.SQSEventResponse
+	for _, message := range event.Records {
+		if !h.redrive(ctx, h.Config, reviews, message) {
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: message.MessageId})
+		}
+	}
+	return response, nil
+}
+
+// redrive handles one message and reports whether it can be deleted from
+// the queue.
+func (h *DLQHandler) redrive(ctx context.Context, cfg *config.Config, reviews postgres.ManualReviewStore, message events.SQSMessage) bool {
+	ctx = logging.WithFields(ctx, logging.Fields{"message_id": message.MessageId})
+	attempts, _ := strconv.Atoi(message.Attributes["ApproximateReceiveCount"])
+
+	var req models.UserRequest
+	if err := json.Unmarshal([]byte(message.Body), &req); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Dead-lettered signup is not a valid request")
+		return fileForReview(ctx, reviews, models.FailedSignup{
+			MessageID: message.MessageId,
+			Reason:    ReviewReasonMalformed,
+			Error:     err.Error(),
+			Attempts:  attempts,
+		})
+	}
+	ctx = logging.WithHandle(ctx, req.Handle)
+
+	_, err := h.Signups.Handle(ctx, req)
+	if err == nil {
+		logging.FromContext(ctx).Info("Re-drove dead-lettered signup")
+		return true
+	}
+
+	failed := models.FailedSignup{
+		MessageID: message.MessageId,
+		Tenant:    req.Tenant,
+		Handle:    req.Handle,
+		Email:     req.Email,
+		Error:     err.Error(),
+		Attempts:  attempts,
+	}
+	switch code := validate.ErrorCode(err); {
+	case apperr.IsRetryable(err) && attempts < cfg.DLQMaxReceives:
+		logging.FromContext(ctx).WithError(err).WithField("attempts", attempts).Warn("Dead-lettered signup failed again; leaving it for retry")
+		return false
+	case apperr.IsRetryable(err):
+		failed.Reason = ReviewReasonRetriesExhausted
+	case code != "":
+		failed.Reason = code
+	default:
+		failed.Reason = validate.CodeInternal
+	}
+
+	logging.FromContext(ctx).WithError(err).WithField("reason", failed.Reason).Warn("Dead-lettered signup can't be re-driven")
+	return fileForReview(ctx, reviews, failed)
+}
+
+// fileForReview files signup and reports whether that succeeded; a message
+// that couldn't be filed stays on the queue.
+func fileForReview(ctx context.Context, reviews postgres.ManualReviewStore, signup models.FailedSignup) bool {
+	return reviews.FileForReview(ctx, signup) == nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/email"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// emailQueueBatchSize is how many queued emails one run sends.
+const emailQueueBatchSize = 25
+
+// EmailQueueHandler delivers the emails queued while the email provider was
+// down. It is meant to run on an EventBridge schedule.
+type EmailQueueHandler struct {
+	*Services
+}
+
+func NewEmailQueueHandler(services *Services) *EmailQueueHandler {
+	return &EmailQueueHandler{Services: services}
+}
+
+func (h *EmailQueueHandler) Handle(ctx context.Context, _ events.CloudWatchEvent) (models.EmailQueueResult, error) {
+	ctx = logging.NewRequestContext(ctx, "email_queue")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+
+	if h.Email == nil {
+		logging.FromContext(ctx).Debug("Email not configured, nothing to send")
+		return models.EmailQueueResult{}, nil
+	}
+
+	sender := limitEmails(h.Config, h.Limiter, h.Email)
+	return sendQueuedEmails(ctx, h.Config, h.Stores(""), sender, func(tablePrefix string) postgres.VerificationEmailTracker {
+		return h.Stores(tablePrefix)
+	})
+}
+
+// sendQueuedEmails sends one batch. A failure the retry policy considers
+// transient means the provider is still down, so the rest of the batch is
+// left for the next run.
+func sendQueuedEmails(ctx context.Context, cfg *config.Config, queue postgres.PendingEmailStore, sender email.Sender, users func(tablePrefix string) postgres.VerificationEmailTracker) (models.EmailQueueResult, error) {
+	var result models.EmailQueueResult
+
+	due, err := queue.DueEmails(ctx, cfg.EmailMaxAttempts, emailQueueBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, pending := range due {
+		ctx := logging.WithFields(ctx, logging.Fields{"email_id": pending.ID, "did": pending.DID})
+
+		if err := sender.Send(ctx, pendingMessage(pending)); err != nil {
+			if errors.Is(err, email.ErrAPIKeyUnavailable) {
+				// Nothing was sent, so the email keeps its attempts for a
+				// run that can read the key.
+				logging.FromContext(ctx).WithError(err).Error("Failed to retrieve email credentials")
+				return result, err
+			}
+			result.Failed++
+			if err := queue.RecordEmailFailure(ctx, pending.ID, err.Error()); err != nil {
+				return result, err
+			}
+			if pending.Attempts+1 >= cfg.EmailMaxAttempts {
+				logging.FromContext(ctx).WithError(err).Error("Giving up on queued email")
+			}
+			if cfg.Retry.Retryable(err) {
+				logging.FromContext(ctx).WithError(err).Warn("Email provider still unavailable")
+				break
+			}
+			continue
+		}
+
+		result.Sent++
+		if err := queue.MarkEmailSent(ctx, pending.ID); err != nil {
+			return result, err
+		}
+		if tenant, err := cfg.ResolveTenant(pending.Tenant); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("tenant", pending.Tenant).Warn("Queued email sent for an unknown tenant")
+		} else if err := users(tenant.TablePrefix).SetVerificationEmailPending(ctx, pending.DID, false); err != nil {
+			logging.FromContext(ctx).WithError(err).Warn("Account still marked as waiting for its verification email")
+		}
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"sent":   result.Sent,
+		"failed": result.Failed,
+	}).Info("Processed queued emails")
+	return result, nil
+}
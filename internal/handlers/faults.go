@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+)
+
+// newRDSClient is the Data API client for cfg, failing a share of its
+// statements when FAULT_INJECTION asks for database faults.
+func newRDSClient(cfg *config.Config, awsCfg aws.Config) postgres.RDSDataAPI {
+	return faults.NewInjector(cfg.FaultInjection).RDSData(rdsdata.NewFromConfig(awsCfg))
+}
+
+// newHTTPClient is the client for calls to target, failing a share of its
+// requests when FAULT_INJECTION asks for faults there.
+func newHTTPClient(cfg *config.Config, target faults.Target) faults.HTTPClient {
+	return faults.NewInjector(cfg.FaultInjection).HTTP(target, &http.Client{Timeout: cfg.HTTPTimeout})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/graphql"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// GraphQLHandler serves the public account operations as GraphQL:
+//
+//	mutation {
+//	  createUser(input: {handle: "alice", email: "alice@example.com", password: "..."}) { did handle }
+//	  claimHandle(input: {handle: "alice", email: "alice@example.com"}) { expiresAt }
+//	  sendPhoneCode(input: {did: "did:plc:...", accessJwt: "...", phone: "+14155550123"}) { expiresAt }
+//	  verifyPhone(input: {did: "did:plc:...", accessJwt: "...", code: "042917"}) { verified approved }
+//	  issueReferralCode(did: "did:plc:...") { code }
+//	}
+//	query { referralStats(code: "ALICE-7F3K") { signups rewarded } }
+//
+// Inputs are the JSON request bodies of the matching REST routes. Every
+// operation takes an optional tenant.
+type GraphQLHandler struct {
+	Users     *UserHandler
+	Claims    *ClaimHandler
+	Phone     *PhoneHandler
+	Referrals *ReferralHandler
+}
+
+func NewGraphQLHandler(users *UserHandler, claims *ClaimHandler, phone *PhoneHandler, referrals *ReferralHandler) *GraphQLHandler {
+	return &GraphQLHandler{Users: users, Claims: claims, Phone: phone, Referrals: referrals}
+}
+
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req graphql.Request
+	if err := json.NewDecoder(limitBody(w, r)).Decode(&req); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	// Field errors are part of a successful response, as GraphQL clients
+	// expect; only a request that couldn't run at all is a 400.
+	response := h.schema(clientIP(r), requestLocale(r)).Execute(r.Context(), req)
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, response)
+}
+
+func (h *GraphQLHandler) schema(ip, locale string) *graphql.Schema {
+	return &graphql.Schema{
+		Query: map[string]graphql.Resolver{
+			"referralStats": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.ReferralRequest
+				if err := decodeArgument(args, "", &req); err != nil {
+					return nil, err
+				}
+				req.Action = ReferralActionStats
+				resp, err := h.Referrals.Handle(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Stats, nil
+			},
+		},
+		Mutation: map[string]graphql.Resolver{
+			"createUser": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.UserRequest
+				if err := decodeArgument(args, "input", &req); err != nil {
+					return nil, err
+				}
+				req.ClientIP = ip
+				return h.Users.Handle(ctx, req)
+			},
+			"claimHandle": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.HandleClaimRequest
+				if err := decodeArgument(args, "input", &req); err != nil {
+					return nil, err
+				}
+				return h.Claims.Handle(ctx, req)
+			},
+			"sendPhoneCode": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.PhoneRequest
+				if err := decodeArgument(args, "input", &req); err != nil {
+					return nil, err
+				}
+				req.Action = PhoneActionSend
+				return h.Phone.Handle(ctx, req)
+			},
+			"verifyPhone": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.PhoneRequest
+				if err := decodeArgument(args, "input", &req); err != nil {
+					return nil, err
+				}
+				req.Action = PhoneActionVerify
+				return h.Phone.Handle(ctx, req)
+			},
+			"issueReferralCode": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				var req models.ReferralRequest
+				if err := decodeArgument(args, "", &req); err != nil {
+					return nil, err
+				}
+				req.Action = ReferralActionIssueCode
+				return h.Referrals.Handle(ctx, req)
+			},
+		},
+		Extensions: func(err error) map[string]interface{} {
+			extensions := map[string]interface{}{}
+			if code := validate.ErrorCode(err); code != "" {
+				extensions["validationCode"] = code
+				extensions["errors"] = helper.LocalizeErrors(err, locale)
+			}
+			if suggestions := helper.Suggestions(err); len(suggestions) > 0 {
+				extensions["suggestions"] = suggestions
+			}
+			return extensions
+		},
+	}
+}
+
+// decodeArgument decodes the named argument into v, or all of the
+// arguments when name is empty.
+func decodeArgument(args map[string]interface{}, name string, v interface{}) error {
+	var value interface{} = args
+	label := "arguments"
+	if name != "" {
+		label = name
+		if value = args[name]; value == nil {
+			return apperr.Errorf(apperr.Validation, "validation error: argument %s is required", name)
+		}
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("internal error: failed to encode arguments: %w", err)
+	}
+	if err := json.Unmarshal(encoded, v); err != nil {
+		return apperr.Errorf(apperr.Validation, "validation error: invalid %s: %w", label, err)
+	}
+	return nil
+}
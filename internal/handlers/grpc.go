@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/api/usersv1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCErrorDomain is the domain of the google.rpc.ErrorInfo a failed call
+// carries its validation code in.
+const GRPCErrorDomain = "users.shareframe.social"
+
+// NewGRPCServer serves the UserService of proto/users/v1 for internal
+// callers. Like the /admin routes, GetUser and DeleteUser are operator
+// calls with no authentication of their own, and are refused unless admin
+// is set; the caller is taken from the x-authenticated-user metadata, the
+// CallerHeader of the admin routes.
+func NewGRPCServer(routes Routes, admin bool) *grpc.Server {
+	server := grpc.NewServer()
+	usersv1.RegisterUserServiceServer(server, &userService{routes: routes, admin: admin})
+	return server
+}
+
+type userService struct {
+	usersv1.UnimplementedUserServiceServer
+	routes Routes
+	admin  bool
+}
+
+// CreateUser keeps the client_ip of the request: gRPC callers are internal
+// services relaying a signup, not the person signing up.
+func (s *userService) CreateUser(ctx context.Context, req *usersv1.CreateUserRequest) (*usersv1.CreateUserResponse, error) {
+	in := models.UserRequest{
+		Handle:           req.GetHandle(),
+		Email:            req.GetEmail(),
+		Password:         req.GetPassword(),
+		Tenant:           req.GetTenant(),
+		DisplayName:      req.GetDisplayName(),
+		Locale:           req.GetLocale(),
+		Country:          req.GetCountry(),
+		Timezone:         req.GetTimezone(),
+		Bio:              req.GetBio(),
+		Pronouns:         req.GetPronouns(),
+		Website:          req.GetWebsite(),
+		Location:         req.GetLocation(),
+		DID:              req.GetDid(),
+		InviteCode:       req.GetInviteCode(),
+		ClientIP:         req.GetClientIp(),
+		CaptchaToken:     req.GetCaptchaToken(),
+		ReferralSource:   req.GetReferralSource(),
+		ReferralCode:     req.GetReferralCode(),
+		IdentityProvider: req.GetIdentityProvider(),
+		IDToken:          req.GetIdToken(),
+		AccountType:      req.GetAccountType(),
+		Owners:           req.GetOwners(),
+		AvatarUpload:     req.GetAvatarUpload(),
+		IdempotencyKey:   req.GetIdempotencyKey(),
+	}
+	for _, consent := range req.GetConsents() {
+		in.Consents = append(in.Consents, models.Consent{
+			Purpose:     consent.GetPurpose(),
+			Granted:     consent.GetGranted(),
+			TextVersion: consent.GetTextVersion(),
+		})
+	}
+	if key := incomingHeader(ctx, "idempotency-key"); key != "" {
+		in.IdempotencyKey = key
+	}
+
+	resp, err := Recover(OperationCreateAccount, s.routes.Users.Handle)(ctx, in)
+	if err != nil {
+		return nil, grpcError(err, grpcLocale(ctx, in.Locale))
+	}
+	out := &usersv1.CreateUserResponse{
+		Handle:                  resp.Handle,
+		Did:                     resp.DID,
+		AccessJwt:               resp.AccessJWT,
+		RefreshJwt:              resp.RefreshJWT,
+		SignupToken:             resp.SignupToken,
+		AppPassword:             resp.AppPassword,
+		Status:                  resp.Status,
+		VerificationRequired:    resp.VerificationRequired,
+		VerificationEmailSentAt: timestamp(resp.VerificationEmailSentAt),
+		Onboarding:              resp.Onboarding,
+		NextSteps:               resp.NextSteps,
+	}
+	if upload := resp.AvatarUpload; upload != nil {
+		out.AvatarUpload = &usersv1.AvatarUpload{
+			Url:       upload.URL,
+			ExpiresAt: timestamp(&upload.ExpiresAt),
+			MaxBytes:  int64(upload.MaxBytes),
+		}
+	}
+	return out, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, req *usersv1.GetUserRequest) (*usersv1.User, error) {
+	if !s.admin {
+		return nil, operatorOnly("GetUser")
+	}
+	in := models.AdminRequest{Action: AdminActionLookup, User: req.GetUser(), Tenant: req.GetTenant()}
+	resp, err := Recover(OperationAdmin, s.routes.Admin.Handle)(ctx, in)
+	if err != nil {
+		return nil, grpcError(err, grpcLocale(ctx, ""))
+	}
+	account := resp.Account
+	return &usersv1.User{
+		Did:         account.DID,
+		Handle:      account.Handle,
+		Email:       account.Email,
+		DisplayName: account.DisplayName,
+		Status:      account.Status,
+		Verified:    account.Verified,
+		Role:        account.Role,
+		AccountType: account.AccountType,
+		Locale:      account.Locale,
+		Country:     account.Country,
+		Timezone:    account.Timezone,
+	}, nil
+}
+
+// DeleteUser requests the erasure and confirms it in one call.
+func (s *userService) DeleteUser(ctx context.Context, req *usersv1.DeleteUserRequest) (*usersv1.DeleteUserResponse, error) {
+	if !s.admin {
+		return nil, operatorOnly("DeleteUser")
+	}
+	ctx = caller.With(ctx, strings.TrimSpace(incomingHeader(ctx, strings.ToLower(CallerHeader))))
+	erase := Recover(OperationPrivacy, s.routes.Privacy.Handle)
+	in := models.PrivacyRequest{Action: PrivacyActionErase, DID: req.GetDid(), Tenant: req.GetTenant()}
+	requested, err := erase(ctx, in)
+	if err != nil {
+		return nil, grpcError(err, grpcLocale(ctx, ""))
+	}
+	in.Action, in.Confirmation = PrivacyActionConfirmErase, requested.Confirmation
+	if _, err := erase(ctx, in); err != nil {
+		return nil, grpcError(err, grpcLocale(ctx, ""))
+	}
+	return &usersv1.DeleteUserResponse{}, nil
+}
+
+func operatorOnly(method string) error {
+	return status.Errorf(codes.PermissionDenied, "%s is an operator call; the server was started without admin calls", method)
+}
+
+// grpcError is the status for err, with the status code for its category.
+// Validation failures carry their code and localized message in an
+// ErrorInfo, taken handles their suggestions, and the failed fields a
+// BadRequest with one violation each.
+func grpcError(err error, locale string) error {
+	st := status.New(apperr.GRPCCode(err), err.Error())
+	code, message := helper.LocalizeError(err, locale)
+	if code == "" {
+		return st.Err()
+	}
+
+	info := &errdetails.ErrorInfo{Reason: code, Domain: GRPCErrorDomain, Metadata: map[string]string{"message": message}}
+	if suggestions := helper.Suggestions(err); len(suggestions) > 0 {
+		info.Metadata["suggestions"] = strings.Join(suggestions, ",")
+	}
+	var badRequest errdetails.BadRequest
+	for _, failure := range helper.LocalizeErrors(err, locale) {
+		if failure.Field == "" {
+			continue
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       protoField(failure.Field),
+			Description: failure.Message,
+			Reason:      failure.Code,
+		})
+	}
+
+	details := []protoadapt.MessageV1{info}
+	if len(badRequest.FieldViolations) > 0 {
+		details = append(details, &badRequest)
+	}
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// protoField is the proto name of a JSON field name, e.g. display_name for
+// displayName.
+func protoField(field string) string {
+	var b strings.Builder
+	for _, r := range field {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// grpcLocale is the locale a request asked for, or else the caller's
+// preferred language from the accept-language metadata.
+func grpcLocale(ctx context.Context, locale string) string {
+	if locale != "" {
+		return locale
+	}
+	return firstLanguage(incomingHeader(ctx, "accept-language"))
+}
+
+func incomingHeader(ctx context.Context, name string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
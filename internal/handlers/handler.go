@@ -3,105 +3,504 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
 	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/budget"
+	"github.com/ShareFrame/user-management/internal/handleresolver"
 	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/hibp"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
 	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/oidc"
+	"github.com/ShareFrame/user-management/internal/onboarding"
 	"github.com/ShareFrame/user-management/internal/postgres"
-	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
-	"github.com/sirupsen/logrus"
+	"github.com/ShareFrame/user-management/internal/referral"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/sync/errgroup"
 )
 
 type UserHandler struct {
-	SecretsManagerClient config.SecretsManagerAPI
+	*Services
+	resolverOnce sync.Once
+	resolver     *handleresolver.Resolver
+	identityOnce sync.Once
+	identity     *oidc.Verifier
+	pds          pdsClients
 }
 
-func NewUserHandler(secretsClient config.SecretsManagerAPI) *UserHandler {
-	return &UserHandler{SecretsManagerClient: secretsClient}
+func NewUserHandler(services *Services) *UserHandler {
+	return &UserHandler{Services: services}
 }
 
+// Handle creates an account and emits the signup metrics for the attempt.
 func (h *UserHandler) Handle(ctx context.Context, event models.UserRequest) (*models.CreateUserResponse, error) {
-	logrus.WithField("handle", event.Handle).Info("Processing create account request")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	metrics.Count(recorder, metrics.SignupAttempted)
 
-	cfg, awsCfg, err := config.LoadConfig(ctx, h.SecretsManagerClient)
+	ctx = logging.NewRequestContext(ctx, "create_account")
+	ctx = logging.WithHandle(ctx, event.Handle)
+	user, err := h.createAccount(metrics.WithMetrics(ctx, recorder), event)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to load application configuration")
-		return nil, fmt.Errorf("internal error: failed to load application configuration: %w", err)
+		metrics.CountWith(recorder, metrics.SignupFailed, map[string]string{metrics.DimensionReason: failureReason(err)})
+		return nil, err
 	}
+	if user.Status == models.StatusWaitlisted {
+		metrics.Count(recorder, metrics.SignupWaitlisted)
+		return user, nil
+	}
+	metrics.Count(recorder, metrics.SignupSucceeded)
+	return user, nil
+}
+
+// failureReason is the validation code of a rejected signup, or
+// internal_error for everything else.
+func failureReason(err error) string {
+	if code := validate.ErrorCode(err); code != "" {
+		return code
+	}
+	return validate.CodeInternal
+}
+
+func (h *UserHandler) createAccount(ctx context.Context, event models.UserRequest) (_ *models.CreateUserResponse, err error) {
+	logging.FromContext(ctx).WithField("tenant", event.Tenant).Info("Processing create account request")
 
-	rdsClient := rdsdata.NewFromConfig(awsCfg)
-	dbClient := postgres.NewPostgresDB(rdsClient, cfg.DBClusterARN, cfg.SecretARN, cfg.DatabaseName)
+	cfg, awsCfg := h.Config, h.AWS
 
-	updatedEvent, err := helper.ValidateAndFormatUser(ctx, event, dbClient)
+	tenant, err := cfg.ResolveTenant(event.Tenant)
 	if err != nil {
-		logrus.WithError(err).Warn("Validation error")
-		return nil, fmt.Errorf("validation error: %w", err)
+		logging.FromContext(ctx).WithError(err).WithField("tenant", event.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
 	}
-	event = updatedEvent
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
 
-	adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.SecretsManagerClient)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to retrieve admin credentials")
-		return nil, fmt.Errorf("internal error: could not retrieve admin credentials: %w", err)
+	dbClient := h.Stores(tenant.TablePrefix)
+
+	social := event.IDToken != ""
+	if social {
+		if event, err = h.applySocialIdentity(ctx, cfg, event); err != nil {
+			return nil, err
+		}
+	}
+	if !cfg.Rollout.Admits(event.Email) && !isBootstrapAccount(cfg, event.Email) {
+		logging.FromContext(ctx).WithField("bucket", config.RolloutBucket(event.Email)).Info("Signup put on the waitlist by the rollout gate")
+		return waitlisted(event), nil
 	}
 
-	atProtoClient := ATProtocol.NewATProtocolClient(cfg.AtProtoBaseURL, &http.Client{})
-	logrus.WithField("base_url", cfg.AtProtoBaseURL).Info("Initializing ATProtocol client")
+	validationOpts := helper.ValidationOptions{
+		HandleSuffix:             tenant.HandleSuffix,
+		AllowUnicodeHandles:      cfg.AllowUnicodeHandles,
+		ProfanityMode:            cfg.ProfanityMode,
+		MinPasswordScore:         cfg.MinPasswordScore,
+		Blocklist:                dbClient,
+		RequireProcessingConsent: cfg.RequireProcessingConsent,
+	}
+	if cfg.BreachCheckEnabled {
+		validationOpts.BreachChecker = hibp.NewClient(http.DefaultClient, cfg.BreachCheckTimeout)
+	}
+	if cfg.AllowCustomDomainHandles {
+		validationOpts.DomainVerifier = h.domainResolver(cfg)
+	}
+	if cfg.UserInviteCodes {
+		validationOpts.InviteCodes = dbClient
+	}
+	if cfg.HandleClaims {
+		validationOpts.HandleClaims = dbClient
+	}
+	if cfg.HandleQuarantine > 0 {
+		validationOpts.HandleQuarantine = dbClient
+	}
+	if cfg.OrganizationAccounts {
+		validationOpts.Owners = dbClient
+	}
+	limiter := h.Limiter
+	if cfg.DomainThrottle.Enabled() {
+		validationOpts.DomainThrottle = &helper.DomainThrottleOptions{Counter: dbClient, Limiter: limiter, Throttle: cfg.DomainThrottle}
+	}
+	if cfg.RiskScoring {
+		validationOpts.Risk = h.riskOptions(ctx, cfg, dbClient)
+	}
+	var attempt *signupAttempt
+	if cfg.RiskScoring || cfg.DomainThrottle.Enabled() || cfg.SignupStats {
+		attempt = &signupAttempt{store: dbClient, ip: event.ClientIP, emailDomain: risk.EmailDomain(event.Email)}
+		defer func() { attempt.record(ctx, err) }()
+	}
 
-	inviteCode, err := atProtoClient.CreateInviteCode(adminCreds)
+	progress, err := loadSignupProgress(ctx, dbClient, event.IdempotencyKey)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to generate invite code using AT Protocol")
-		return nil, fmt.Errorf("internal error: failed to generate invite code: %w", err)
+		return nil, err
+	}
+	if err := progress.checkResume(social); err != nil {
+		return nil, err
+	}
+
+	validator := helper.NewValidator(dbClient, validationOpts)
+	validator.Remove(tenant.DisabledValidationRules...)
+	if progress.done(models.SignupStepPDSRegistered) {
+		validator.Remove(helper.ResumeRules...)
 	}
 
-	utilAccountCreds, err := helper.RetrieveUtilAccountCreds(ctx, h.SecretsManagerClient)
+	plan := budget.New(ctx, cfg.ExecutionBudget)
+
+	// The PDS credentials don't depend on the signup, so they are read
+	// while it is validated.
+	var (
+		validation helper.ValidationResult
+		creds      pdsCredentials
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return plan.Run(gctx, budget.StepValidation, func(ctx context.Context) error {
+			var err error
+			if validation, err = validator.Validate(ctx, event); err != nil {
+				logging.FromContext(ctx).WithError(err).Warn("Validation error")
+				return apperr.Errorf(apperr.Validation, "validation error: %w", err)
+			}
+			return nil
+		})
+	})
+	g.Go(func() error {
+		return plan.Run(gctx, budget.StepPDS, func(ctx context.Context) error {
+			var err error
+			creds, err = fetchPDSCredentials(ctx, h.Secrets, cfg, tenant)
+			return err
+		})
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	event = validation.User
+	if err := progress.check(event.Handle); err != nil {
+		return nil, err
+	}
+	// A resumed signup already took its share of the quotas.
+	if quota := signupQuota(cfg, h.Quota); quota != nil && !progress.done(models.SignupStepInviteCreated) && !progress.done(models.SignupStepPDSRegistered) {
+		if err := quota.Take(ctx, event.Email); err != nil {
+			return nil, apperr.Errorf(apperr.RateLimited, "signup quota: %w", err)
+		}
+	}
+
+	var user models.CreateUserResponse
+	err = plan.Run(ctx, budget.StepPDS, func(ctx context.Context) error {
+		if progress.done(models.SignupStepPDSRegistered) {
+			user, err = resumeOnPDS(ctx, h.pds.client(cfg, tenant), progress, event.Password)
+			return err
+		}
+		user, err = registerOnPDS(ctx, cfg, &h.pds, tenant, dbClient, creds, event, progress)
+		return err
+	})
 	if err != nil {
-		logrus.WithError(err).Error("Failed to retrieve util account credentials")
-		return nil, fmt.Errorf("internal error: could not retrieve authentication credentials: %w", err)
+		return nil, err
 	}
 
-	session, err := atProtoClient.CreateSession(utilAccountCreds.Username, utilAccountCreds.Password)
+	// The account exists on the PDS from here on. Storing it must succeed,
+	// and a retry resumes from the progress; every other step only logs its
+	// failure for follow-up, since failing the signup wouldn't undo the
+	// account.
+	record := cfg.ProfileDefaults.NewUserRecord(user, event)
+	if cfg.ShareFrameProfileRecord {
+		createShareFrameProfile(ctx, h.pds.client(cfg, tenant), user, record)
+	}
+	if len(validation.Flags) > 0 {
+		record.Status, record.ReviewFlags = models.StatusPendingReview, validation.Flags
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": user.Handle,
+			"flags":  validation.Flags,
+		}).Warn("Account created pending review")
+	}
+	if social && record.Status == models.StatusPending {
+		// The identity provider has already verified the email address.
+		record.Status, record.Verified = models.StatusVerified, true
+	}
+	err = plan.Run(ctx, budget.StepDB, func(ctx context.Context) error {
+		if progress.done(models.SignupStepStored) {
+			return nil
+		}
+		return dbClient.StoreUser(ctx, record)
+	})
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"username": utilAccountCreds.Username,
-			"error":    err.Error(),
-		}).Error("Failed to authenticate with AT Protocol")
-		return nil, fmt.Errorf("authentication failed for user %s: %w", utilAccountCreds.Username, err)
+		logging.FromContext(ctx).WithError(err).Error("Failed to store user in PostgreSQL")
+		return nil, fmt.Errorf("internal error: failed to store user data: %w", err)
+	}
+	progress.record(ctx, user.Handle, models.SignupStepStored)
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":    user.DID,
+		"handle": user.Handle,
+	}).Info("Successfully created and stored user")
+
+	if err := dbClient.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:   postgres.AuditAccountCreated,
+		DID:     user.DID,
+		Handle:  user.Handle,
+		Actor:   postgres.AuditActorSelf,
+		Details: map[string]string{"status": record.Status, "role": record.Role},
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without an audit event")
 	}
 
-	logrus.Info("Session created successfully")
+	consents := event.Consents
+	if len(consents) > 0 {
+		recordedAt := time.Now().UTC()
+		for i := range consents {
+			consents[i].RecordedAt = recordedAt
+		}
+		if err := dbClient.RecordConsents(ctx, user.DID, consents); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without its consent record")
+		}
+	}
 
-	exists, err := atProtoClient.CheckUserExists(event.Handle, session.AccessJwt)
-	if err != nil {
-		logrus.WithError(err).WithField("handle", event.Handle).Error("Failed to check user existence")
-		return nil, fmt.Errorf("internal error: failed to check if user exists: %w", err)
+	if event.ReferralCode != "" {
+		tracker := referral.NewTracker(dbClient, dbClient)
+		if err := tracker.Capture(ctx, record, event.ReferralCode); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without its referral")
+		} else if record.Verified {
+			if _, _, err := tracker.Verified(ctx, user.DID); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Referral of verified signup not rewarded")
+			}
+		}
+	}
+
+	if cfg.StripeCustomers {
+		h.createStripeCustomer(ctx, cfg, tenant, dbClient, user, event.Email)
+	}
+
+	// Accounts held for review aren't seeded, so they follow nobody before
+	// a moderator has looked at them.
+	if cfg.OnboardingSeeding && record.Status != models.StatusPendingReview {
+		seeder := &onboarding.Seeder{
+			PDS:            h.pds.client(cfg, tenant),
+			Store:          dbClient,
+			StarterFollows: cfg.StarterFollows,
+		}
+		record.Onboarding = seeder.Seed(ctx, user.AccessJWT, record)
+	}
+
+	publishers := accountEventPublishers(ctx, h.Services)
+	publishAccountEvents(ctx, publishers, tenant.ID, user, record, consents)
+	if record.Status == models.StatusPendingReview {
+		publishReviewRequested(ctx, publishers, tenant.ID, user, record.ReviewFlags)
+	}
+
+	var emailSentAt *time.Time
+	plan.Run(ctx, budget.StepEmail, func(ctx context.Context) error {
+		if progress.done(models.SignupStepEmailQueued) {
+			return nil
+		}
+		emailSentAt = h.sendWelcomeEmail(ctx, cfg, tenant, s3.NewFromConfig(awsCfg), limiter, h.Stores(""), dbClient, user, event.Email)
+		progress.record(ctx, user.Handle, models.SignupStepEmailQueued)
+		return nil
+	})
+
+	if social && cfg.SocialCredential == config.SocialCredentialAppPassword {
+		user.AppPassword = h.createAppPassword(ctx, cfg, tenant, user)
+	}
+
+	if event.AvatarUpload && cfg.AvatarBucket != "" {
+		user.AvatarUpload = avatarUpload(ctx, cfg, awsCfg, tenant, user.DID)
+	}
+
+	if tokensEnabled(cfg) {
+		user.SignupToken = h.signupToken(ctx, cfg, awsCfg, tenant, user, record.Verified)
+	}
+
+	user.Status, user.Onboarding = record.Status, record.Onboarding
+	user.VerificationRequired = !record.Verified
+	if user.VerificationRequired {
+		user.VerificationEmailSentAt = emailSentAt
+	}
+	user.NextSteps = nextSteps(cfg, user, record)
+
+	return &user, nil
+}
+
+// waitlisted is the response to a signup the rollout gate turned away.
+func waitlisted(event models.UserRequest) *models.CreateUserResponse {
+	return &models.CreateUserResponse{
+		Handle:    event.Handle,
+		Status:    models.StatusWaitlisted,
+		NextSteps: []string{models.NextStepAwaitLaunch},
+	}
+}
+
+// isBootstrapAccount reports whether email is one of the operator accounts
+// the admin bootstrap provisions, which the rollout gate never holds back.
+func isBootstrapAccount(cfg *config.Config, email string) bool {
+	for _, account := range cfg.BootstrapAccounts {
+		if strings.EqualFold(account.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextSteps lists what the client should show after signup. A held account
+// can only wait for review, unless verifying a phone number clears it.
+func nextSteps(cfg *config.Config, user models.CreateUserResponse, record models.UserRecord) []string {
+	steps := []string{}
+	if record.Status == models.StatusPendingReview {
+		if !cfg.PhoneVerification || !cfg.PhoneClearsRiskReview || !onlyRiskReview(record) {
+			return append(steps, models.NextStepAwaitReview)
+		}
+		steps = append(steps, models.NextStepVerifyPhone)
+	}
+	if user.VerificationRequired {
+		steps = append(steps, models.NextStepVerifyEmail)
+	}
+	if user.AvatarUpload != nil {
+		steps = append(steps, models.NextStepUploadAvatar)
+	}
+	return steps
+}
+
+// createShareFrameProfile writes the account's ShareFrame profile to its
+// repo; the profile is stored with the account either way.
+func createShareFrameProfile(ctx context.Context, client *ATProtocol.ATProtocolClient, user models.CreateUserResponse, record models.UserRecord) {
+	if err := client.CreateShareFrameProfile(ctx, user.AccessJWT, user.DID, record.ShareFrameProfile(time.Now())); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without its ShareFrame profile record")
+	}
+}
+
+// pdsCredentials are the secrets registering an account on the tenant's
+// PDS takes. admin is only read when the service mints invite codes.
+type pdsCredentials struct {
+	admin models.AdminCreds
+	util  models.UtilACcountCreds
+}
+
+// fetchPDSCredentials reads the admin and util account secrets
+// concurrently.
+func fetchPDSCredentials(ctx context.Context, secretsClient config.SecretsManagerAPI, cfg *config.Config, tenant config.Tenant) (pdsCredentials, error) {
+	var creds pdsCredentials
+	g, ctx := errgroup.WithContext(ctx)
+	if !cfg.UserInviteCodes {
+		g.Go(func() error {
+			admin, err := helper.RetrieveAdminCredentials(ctx, secretsClient, tenant.AdminSecretName)
+			if err != nil {
+				logging.FromContext(ctx).WithError(err).Error("Failed to retrieve admin credentials")
+				return fmt.Errorf("internal error: could not retrieve admin credentials: %w", err)
+			}
+			creds.admin = admin
+			return nil
+		})
+	}
+	g.Go(func() error {
+		util, err := helper.RetrieveUtilAccountCreds(ctx, secretsClient, tenant.UtilSecretName)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to retrieve util account credentials")
+			return fmt.Errorf("internal error: could not retrieve authentication credentials: %w", err)
+		}
+		creds.util = util
+		return nil
+	})
+	return creds, g.Wait()
+}
+
+// registerOnPDS creates the account on the tenant's PDS, minting an invite
+// code first unless signups bring their own or progress has one. Both steps
+// are recorded in progress. The invite is minted while the
+// util account's session checks the handle is free; the session is reused
+// from earlier signups until the PDS rejects it. Organizations and bots are
+// labelled as such on their profile.
+func registerOnPDS(ctx context.Context, cfg *config.Config, pds *pdsClients, tenant config.Tenant, dbClient postgres.Store, creds pdsCredentials, event models.UserRequest, progress *signupProgress) (models.CreateUserResponse, error) {
+	atProtoClient := pds.client(cfg, tenant)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"base_url": tenant.PDSBaseURL,
+		"tenant":   tenant.ID,
+	}).Info("Registering account on PDS")
+
+	inviteCode := event.InviteCode
+	if minted := progress.inviteCode(); minted != "" && !cfg.UserInviteCodes {
+		inviteCode = minted
+	}
+	var exists bool
+	g, gctx := errgroup.WithContext(ctx)
+	if !cfg.UserInviteCodes && inviteCode == "" {
+		g.Go(func() error {
+			created, err := atProtoClient.CreateInviteCode(gctx, creds.admin)
+			if err != nil {
+				logging.FromContext(gctx).WithError(err).Error("Failed to generate invite code using AT Protocol")
+				return fmt.Errorf("internal error: failed to generate invite code: %w", err)
+			}
+			inviteCode = created.Code
+			progress.recordInvite(gctx, event.Handle, inviteCode)
+			return nil
+		})
+	}
+	g.Go(func() error {
+		var checkErr error
+		err := pds.sessions.With(gctx, atProtoClient, creds.util.Username, creds.util.Password, func(accessJWT string) error {
+			exists, checkErr = atProtoClient.CheckUserExists(gctx, event.Handle, accessJWT)
+			return checkErr
+		})
+		switch {
+		case err == nil:
+			return nil
+		case err == checkErr:
+			logging.FromContext(gctx).WithError(err).WithField("handle", event.Handle).Error("Failed to check user existence")
+			return fmt.Errorf("internal error: failed to check if user exists: %w", err)
+		default:
+			logging.FromContext(gctx).WithFields(logging.Fields{
+				"username": creds.util.Username,
+				"error":    err.Error(),
+			}).Error("Failed to authenticate with AT Protocol")
+			return fmt.Errorf("authentication failed for user %s: %w", creds.util.Username, err)
+		}
+	})
+	if err := g.Wait(); err != nil {
+		return models.CreateUserResponse{}, err
 	}
 
 	if exists {
-		logrus.WithField("handle", event.Handle).Warn("User already exists on PDS")
-		return nil, fmt.Errorf("user already exists with handle: %s", event.Handle)
+		logging.FromContext(ctx).WithField("handle", event.Handle).Warn("User already exists on PDS")
+		available := helper.AllAvailable(
+			helper.StorageAvailability(dbClient),
+			func(ctx context.Context, handle string) (bool, error) {
+				did, err := atProtoClient.ResolveHandle(ctx, handle)
+				return did == "", err
+			},
+		)
+		return models.CreateUserResponse{}, validate.NewError(validate.CodeHandleTaken, "user already exists with handle: %s", event.Handle).
+			ForField(validate.FieldHandle).
+			With(helper.ParamSuggestions, helper.SuggestHandles(ctx, event.Handle, tenant.HandleSuffix, available))
 	}
 
-	user, err := atProtoClient.RegisterUser(event.Handle, event.Email, inviteCode.Code, event.Password)
+	user, err := atProtoClient.RegisterUser(ctx, event.Handle, event.Email, inviteCode, event.Password, event.DID)
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
 			"handle": event.Handle,
 			"email":  event.Email,
 		}).Error("Failed to register user via AT Protocol")
-		return nil, fmt.Errorf("failed to register user: %w", err)
+		return models.CreateUserResponse{}, fmt.Errorf("failed to register user: %w", err)
 	}
+	progress.recordRegistered(ctx, user)
 
-	if err = dbClient.StoreUser(ctx, user, updatedEvent); err != nil {
-		logrus.WithError(err).Error("Failed to store user in PostgreSQL")
-		return nil, fmt.Errorf("internal error: failed to store user data: %w", err)
+	// The account type is stored even if its label can't be written.
+	if labels := models.ProfileLabels(event.AccountType); len(labels) > 0 {
+		profile := models.ProfileRecord{DisplayName: event.DisplayName, Labels: labels}
+		if err := atProtoClient.PutProfile(ctx, user.AccessJWT, user.DID, profile); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Account created without its profile label")
+		}
 	}
+	return user, nil
+}
 
-	logrus.WithFields(logrus.Fields{
-		"did":    user.DID,
-		"handle": user.Handle,
-	}).Info("Successfully created and stored user")
-
-	return &user, nil
+// domainResolver is shared across invocations so verified domains stay
+// cached while the container is warm.
+func (h *UserHandler) domainResolver(cfg *config.Config) *handleresolver.Resolver {
+	h.resolverOnce.Do(func() {
+		h.resolver = handleresolver.NewResolver(net.DefaultResolver, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.DomainCacheTTL)
+	})
+	return h.resolver
 }
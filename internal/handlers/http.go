@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/pkg/api"
+)
+
+// HTTPHandler exposes UserHandler over plain HTTP so the binary can run as a
+// long-lived local process instead of under the Lambda runtime. It reads
+// request bodies in the schema of Version, v1 unless set.
+type HTTPHandler struct {
+	Users   *UserHandler
+	Version string
+}
+
+func NewHTTPHandler(users *UserHandler) *HTTPHandler {
+	return &HTTPHandler{Users: users, Version: api.Version1}
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(limitBody(w, r)).Decode(&body); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	event, err := decodeUserRequest(body, h.Version)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := checkVersion(event.Version, h.Version); err != nil {
+		writeError(w, err, event.Locale)
+		return
+	}
+	// A request relayed without a source address, as over a Lambda
+	// invoke, keeps the address the caller put in the body.
+	if ip := clientIP(r); ip != "" {
+		event.ClientIP = ip
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		event.IdempotencyKey = key
+	}
+
+	user, err := h.Users.Handle(r.Context(), event)
+	if err != nil {
+		writeError(w, err, event.Locale)
+		return
+	}
+
+	status := http.StatusCreated
+	if user.Status == api.StatusWaitlisted {
+		status = http.StatusAccepted
+	}
+	writeJSON(w, status, user)
+}
+
+// jsonRoute exposes a handler that takes and returns JSON over plain HTTP,
+// answering with status on success. The operations reachable this way have
+// no authentication of their own; whatever fronts the server must provide it.
+func jsonRoute[In, Out any](handle func(context.Context, In) (Out, error), status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req In
+		if err := json.NewDecoder(limitBody(w, r)).Decode(&req); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+
+		resp, err := handle(r.Context(), req)
+		if err != nil {
+			writeError(w, err, requestLocale(r))
+			return
+		}
+
+		writeJSON(w, status, resp)
+	})
+}
+
+// clientIP is the address the request came from. Forwarding headers aren't
+// trusted: local runs have no proxy in front, and in a cluster the load
+// balancer is expected to preserve the client address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestLocale is the caller's preferred language from Accept-Language.
+func requestLocale(r *http.Request) string {
+	return firstLanguage(r.Header.Get("Accept-Language"))
+}
+
+// firstLanguage is the first tag of an Accept-Language value. Only the
+// first is used; the message catalog falls back from a region to its
+// language anyway.
+func firstLanguage(header string) string {
+	tag, _, _ := strings.Cut(header, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	return strings.TrimSpace(tag)
+}
+
+// maxBodyBytes caps the request bodies the HTTP routes read. Signups and
+// operator requests are a few kilobytes; avatars are uploaded to S3.
+const maxBodyBytes = 1 << 20
+
+// limitBody returns r's body, failing reads past maxBodyBytes.
+func limitBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	return http.MaxBytesReader(w, r.Body, maxBodyBytes)
+}
+
+// writeBodyError answers a request whose body couldn't be read: 413 when
+// it was over maxBodyBytes, 400 otherwise.
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+}
+
+// writeError writes err with the status for its category. Validation
+// failures carry their codes and messages in locale, so clients can show
+// them without parsing the error string.
+func writeError(w http.ResponseWriter, err error, locale string) {
+	body := api.ErrorResponse{Detail: err.Error(), Suggestions: helper.Suggestions(err)}
+	if code, message := helper.LocalizeError(err, locale); code != "" {
+		body.Code, body.Message = code, message
+		body.Errors = helper.LocalizeErrors(err, locale)
+	}
+	writeJSON(w, apperr.HTTPStatus(err), body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logging.Default().WithError(err).Error("Failed to write HTTP response")
+	}
+}
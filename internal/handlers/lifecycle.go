@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/email"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/outbox"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/referral"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// LifecycleHandler moves accounts between statuses: the verification flow
+// sends verify, onboarding sends activate, moderators send approve or
+// reject for accounts held for review and operators send suspend. Entering verified
+// announces the account and rewards its referrer; entering active announces
+// it and sends the activation email when one is configured; entering
+// suspended takes the account down on the PDS; entering rejected deletes it
+// from the PDS and releases its handle and email address. The audit trail
+// records the caller's authenticated identity, so events without one are
+// refused.
+type LifecycleHandler struct {
+	*Services
+}
+
+func NewLifecycleHandler(services *Services) *LifecycleHandler {
+	return &LifecycleHandler{Services: services}
+}
+
+func (h *LifecycleHandler) Handle(ctx context.Context, req models.LifecycleRequest) (*models.LifecycleResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "lifecycle."+req.Event)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	actor, _ := caller.From(ctx)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"event":  req.Event,
+		"did":    req.DID,
+		"actor":  actor,
+		"tenant": req.Tenant,
+	}).Info("Processing lifecycle event")
+
+	if req.DID == "" || actor == "" {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: did and an authenticated caller are required")
+	}
+
+	cfg, awsCfg := h.Config, h.AWS
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+	shared := h.Stores("")
+	publishers := accountEventPublishers(ctx, h.Services)
+
+	machine := lifecycle.New(store, store)
+	machine.OnEnter(models.StatusVerified, announce(publishers, models.EventAccountVerified, tenant.ID))
+	machine.OnEnter(models.StatusVerified, func(ctx context.Context, t lifecycle.Transition) error {
+		_, _, err := referral.NewTracker(store, store).Verified(ctx, t.Account.DID)
+		return err
+	})
+	machine.OnEnter(models.StatusActive, announce(publishers, models.EventAccountActivated, tenant.ID))
+	machine.OnEnter(models.StatusActive, func(ctx context.Context, t lifecycle.Transition) error {
+		return h.sendActivationEmail(ctx, cfg, awsCfg, tenant, shared, t.Account)
+	})
+
+	machine.OnEnter(models.StatusSuspended, func(ctx context.Context, t lifecycle.Transition) error {
+		return h.takedown(ctx, cfg, tenant, t.Account.DID)
+	})
+	machine.OnEnter(models.StatusRejected, announce(publishers, models.EventAccountRejected, tenant.ID))
+	machine.OnEnter(models.StatusRejected, func(ctx context.Context, t lifecycle.Transition) error {
+		return releaseRejected(ctx, h.Secrets, cfg, tenant, store, t.Account.DID)
+	})
+
+	transition, changed, err := machine.Fire(ctx, req.DID, req.Event, actor)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LifecycleResponse{DID: req.DID, From: transition.From, To: transition.To, Changed: changed}, nil
+}
+
+// takedown takes a suspended account down on the tenant's PDS.
+func (h *LifecycleHandler) takedown(ctx context.Context, cfg *config.Config, tenant config.Tenant, did string) error {
+	adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.Secrets, tenant.AdminSecretName)
+	if err != nil {
+		return err
+	}
+	client := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	return client.TakedownAccount(ctx, adminCreds, did)
+}
+
+// announce returns a hook publishing event to every subscriber.
+func announce(publishers []outbox.Publisher, event, tenant string) lifecycle.Hook {
+	return func(ctx context.Context, t lifecycle.Transition) error {
+		accountEvent := models.NewAccountEvent(event, t.Account.DID, t.Account.Handle, tenant)
+		var errs []error
+		for _, publisher := range publishers {
+			if err := publisher.Publish(ctx, accountEvent); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// sendActivationEmail renders the activation template, when one is
+// configured, and delivers it, queueing it for the pending email sender if
+// the provider can't take it.
+func (h *LifecycleHandler) sendActivationEmail(ctx context.Context, cfg *config.Config, awsCfg aws.Config, tenant config.Tenant, queue postgres.PendingEmailStore, account models.UserRecord) error {
+	if cfg.ActivationEmailTemplate == "" || tenant.EmailFrom == "" || h.Email == nil {
+		return nil
+	}
+
+	tmpl, err := email.LoadTemplate(ctx, cfg.ActivationEmailTemplate, s3.NewFromConfig(awsCfg))
+	if err != nil {
+		return err
+	}
+	subject, body, err := tmpl.Render(email.TemplateData{Handle: account.Handle, DID: account.DID, Tenant: tenant.ID})
+	if err != nil {
+		return err
+	}
+
+	pending := models.PendingEmail{
+		Tenant:    tenant.ID,
+		DID:       account.DID,
+		From:      tenant.EmailFrom,
+		Recipient: account.Email,
+		Subject:   subject,
+		HTML:      body,
+	}
+
+	sender := limitEmails(cfg, h.Limiter, h.Email)
+	if err := sender.Send(ctx, pendingMessage(pending)); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", account.DID).Error("Failed to send activation email; queueing it")
+		return queue.QueueEmail(ctx, pending)
+	}
+	return nil
+}
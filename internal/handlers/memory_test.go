@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/memory"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/testing/fakepds"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests in this file run the handlers on the memory backend against a
+// fake PDS, so whole requests go through the same wiring as in production.
+
+const utilHandle = "util.shareframe.social"
+
+// testSecrets serves the PDS credentials the signup flow reads.
+type testSecrets map[string]string
+
+func (s testSecrets) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := s[aws.ToString(input.SecretId)]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+// newMemoryServices returns Services on the memory backend for a single
+// tenant on pds. configure, when set, adjusts the config first.
+func newMemoryServices(t *testing.T, pds *fakepds.Server, configure func(*config.Config)) *Services {
+	t.Helper()
+	pds.AddAccount(utilHandle, "util@example.com")
+
+	cfg := &config.Config{
+		StorageBackend: config.BackendMemory,
+		Tenants: map[string]config.Tenant{config.DefaultTenantID: {
+			ID:              config.DefaultTenantID,
+			PDSBaseURL:      pds.URL,
+			HandleSuffix:    config.DefaultHandleSuffix,
+			AdminSecretName: "pds-admin",
+			UtilSecretName:  "pds-util",
+		}},
+		ProfanityMode:   config.ProfanityReject,
+		ProfileDefaults: config.ProfileDefaults{Status: models.StatusPending, Role: models.RoleUser},
+		DLQMaxReceives:  config.DefaultDLQMaxReceives,
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+
+	admin, err := json.Marshal(models.AdminCreds{PDSAdminUsername: fakepds.AdminUsername, PDSAdminPassword: fakepds.AdminPassword})
+	require.NoError(t, err)
+	util, err := json.Marshal(models.UtilACcountCreds{Username: utilHandle, Password: "password"})
+	require.NoError(t, err)
+	return NewServices(cfg, aws.Config{}, testSecrets{"pds-admin": string(admin), "pds-util": string(util)})
+}
+
+// tenantStore is the memory store of the default tenant's tables.
+func tenantStore(services *Services) *memory.Store {
+	return services.Stores("").(*memory.Store)
+}
+
+func signupRequest(handle, email string) models.UserRequest {
+	return models.UserRequest{Handle: handle, Email: email, Password: "Correct-Horse-Battery-42"}
+}
+
+func TestSignupOnMemoryBackend(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+
+	user, err := NewUserHandler(services).Handle(context.Background(), signupRequest("alice", "alice@example.com"))
+
+	require.NoError(t, err)
+	account, ok := pds.Account("alice.shareframe.social")
+	require.True(t, ok)
+	assert.Equal(t, account.DID, user.DID)
+	assert.Equal(t, models.StatusPending, user.Status)
+
+	stored, err := tenantStore(services).GetUser(context.Background(), user.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice.shareframe.social", stored.Handle)
+	assert.Equal(t, "alice@example.com", stored.Email)
+}
+
+func TestConfigSnapshotOnMemoryBackend(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+
+	services.RecordConfigSnapshot(context.Background())
+
+	snapshot, err := services.Snapshots.Latest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, config.BackendMemory, snapshot["storageBackend"])
+}
+
+func TestSignupResumesFromProgress(t *testing.T) {
+	ctx := context.Background()
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+	handler := NewUserHandler(services)
+
+	req := signupRequest("alice", "alice@example.com")
+	req.IdempotencyKey = "signup-1"
+
+	// The PDS goes down after the invite is minted.
+	pds.Set(fakepds.CreateAccount, fakepds.Unavailable(1))
+	_, err := handler.Handle(ctx, req)
+	require.Error(t, err)
+	assert.True(t, apperr.IsRetryable(err), "a PDS outage is retried")
+
+	progress, ok, err := tenantStore(services).SignupProgress(ctx, "signup-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, progress.Done(models.SignupStepInviteCreated))
+	assert.False(t, progress.Done(models.SignupStepPDSRegistered))
+
+	user, err := handler.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pds.Calls(fakepds.CreateInviteCode), "the resumed signup reuses its invite")
+	_, err = tenantStore(services).GetUser(ctx, user.DID)
+	assert.NoError(t, err)
+
+	// The key can't be reused for another handle.
+	other := signupRequest("bob", "bob@example.com")
+	other.IdempotencyKey = "signup-1"
+	_, err = handler.Handle(ctx, other)
+	assert.Equal(t, apperr.Conflict, apperr.CategoryOf(err))
+}
+
+func TestSignupRolloutGate(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, func(cfg *config.Config) {
+		cfg.Rollout = config.Rollout{Enabled: true, Domains: []string{"example.edu"}}
+	})
+	handler := NewUserHandler(services)
+
+	user, err := handler.Handle(context.Background(), signupRequest("alice", "alice@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusWaitlisted, user.Status)
+	assert.Zero(t, pds.Calls(fakepds.CreateAccount))
+	assert.Empty(t, tenantStore(services).Accounts())
+
+	// The gate matches the normalized address.
+	user, err = handler.Handle(context.Background(), signupRequest("bob", "Bob+signup@Example.EDU"))
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusPending, user.Status)
+	assert.Len(t, tenantStore(services).Accounts(), 1)
+}
+
+func TestSignupQuota(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, func(cfg *config.Config) {
+		cfg.SignupQuota = config.SignupQuota{Daily: 1}
+	})
+	handler := NewUserHandler(services)
+
+	_, err := handler.Handle(context.Background(), signupRequest("alice", "alice@example.com"))
+	require.NoError(t, err)
+
+	_, err = handler.Handle(context.Background(), signupRequest("bob", "bob@example.com"))
+	assert.Equal(t, apperr.RateLimited, apperr.CategoryOf(err))
+	assert.Equal(t, 1, pds.Calls(fakepds.CreateAccount))
+	assert.Len(t, tenantStore(services).Accounts(), 1)
+}
+
+func TestPhoneRequiresSessionOfAccount(t *testing.T) {
+	ctx := context.Background()
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, func(cfg *config.Config) {
+		cfg.PhoneVerification = true
+	})
+	alice, err := NewUserHandler(services).Handle(ctx, signupRequest("alice", "alice@example.com"))
+	require.NoError(t, err)
+	pds.AddAccount("bob.shareframe.social", "bob@example.com")
+	bob, err := ATProtocol.NewATProtocolClient(pds.URL, http.DefaultClient, retry.Policy{}).CreateSession(ctx, "bob.shareframe.social", "password")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		accessJWT    string
+		expectedCode string
+	}{
+		{name: "Missing Session", expectedCode: validate.CodeMissingFields},
+		{name: "Unknown Session", accessJWT: "forged", expectedCode: validate.CodeInvalidSession},
+		{name: "Session Of Another Account", accessJWT: bob.AccessJwt, expectedCode: validate.CodeInvalidSession},
+		// The session is accepted; no code was sent yet.
+		{name: "Session Of The Account", accessJWT: alice.AccessJWT, expectedCode: validate.CodeInvalidPhoneCode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewPhoneHandler(services).Handle(ctx, models.PhoneRequest{Action: PhoneActionVerify, DID: alice.DID, AccessJWT: test.accessJWT, Code: "042917"})
+			assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+		})
+	}
+}
+
+func TestBlocklistOnMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+	blocklist := NewBlocklistHandler(services)
+	add := models.BlocklistRequest{Action: BlocklistActionAdd, Handle: "Squatter", Reason: "impersonates staff"}
+
+	// Changes need an authenticated caller.
+	_, err := blocklist.Handle(ctx, add)
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+
+	_, err = blocklist.Handle(caller.With(ctx, "arn:aws:iam::123456789012:user/ops"), add)
+	require.NoError(t, err)
+
+	_, err = NewUserHandler(services).Handle(ctx, signupRequest("squatter", "squatter@example.com"))
+	assert.Equal(t, validate.CodeBlockedHandle, validate.ErrorCode(err))
+	assert.Zero(t, pds.Calls(fakepds.CreateAccount))
+
+	audit, err := blocklist.Handle(ctx, models.BlocklistRequest{Action: BlocklistActionAudit})
+	require.NoError(t, err)
+	require.Len(t, audit.Audit, 1)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/ops", audit.Audit[0].Actor)
+}
+
+func TestBlocklistCallerOverHTTP(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+	routes := Routes{Blocklist: NewBlocklistHandler(services)}
+	post := func(ctx context.Context, h http.Handler, path string, header http.Header, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	ctx := context.Background()
+	add := `{"action":"add","handle":"squatter"}`
+	authenticated := http.Header{CallerHeader: {"ops@example.com"}}
+
+	// Behind a Lambda integration the header is the caller's to set, so it
+	// is ignored and operator calls need the integration's identity.
+	assert.Equal(t, http.StatusForbidden, post(ctx, routes.Operation(OperationBlocklist), "/", authenticated, add))
+	assert.Equal(t, http.StatusForbidden, post(ctx, routes.Operation(OperationBlocklist), "/", http.Header{}, `{"action":"list"}`))
+	assert.Equal(t, http.StatusOK, post(caller.With(ctx, "arn:aws:iam::123456789012:user/ops"), routes.Operation(OperationBlocklist), "/", http.Header{}, `{"action":"list"}`))
+	assert.Equal(t, http.StatusBadRequest, post(ctx, NewRouter(routes, true), "/admin/blocklist", http.Header{}, add))
+	assert.Equal(t, http.StatusOK, post(ctx, NewRouter(routes, true), "/admin/blocklist", authenticated, add))
+
+	changes, err := tenantStore(services).ListBlocklistAudit(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "ops@example.com", changes[0].Actor)
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+	users := NewUserHandler(services)
+	routes := Routes{Users: users}
+	tooLarge := `{"handle":"alice","bio":"` + strings.Repeat("a", maxBodyBytes) + `"}`
+
+	for name, handler := range map[string]http.Handler{
+		"Router":    NewRouter(routes, false),
+		"Operation": routes.Operation(OperationCreateAccount),
+		"Direct":    NewHTTPHandler(users),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tooLarge)))
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		})
+	}
+	assert.Zero(t, pds.Calls(fakepds.CreateAccount))
+}
+
+func TestOperatorActionsNeedCaller(t *testing.T) {
+	ctx := context.Background()
+	pds := fakepds.New()
+	defer pds.Close()
+	services := newMemoryServices(t, pds, nil)
+	user, err := NewUserHandler(services).Handle(ctx, signupRequest("alice", "alice@example.com"))
+	require.NoError(t, err)
+
+	// A body can't name the actor, so each is refused without a caller.
+	_, err = NewLifecycleHandler(services).Handle(ctx, models.LifecycleRequest{Event: lifecycle.EventVerify, DID: user.DID})
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+	_, err = NewReviewHandler(services).Handle(ctx, models.ReviewRequest{Action: ReviewActionApprove, DID: user.DID})
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+	_, err = NewPrivacyHandler(services).Handle(ctx, models.PrivacyRequest{Action: PrivacyActionErase, DID: user.DID})
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+
+	ops := caller.With(ctx, "arn:aws:iam::123456789012:role/billing")
+	transition, err := NewLifecycleHandler(services).Handle(ops, models.LifecycleRequest{Event: lifecycle.EventVerify, DID: user.DID})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusVerified, transition.To)
+
+	events, err := tenantStore(services).ListAuditEvents(ctx, user.DID)
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/billing", events[len(events)-1].Actor)
+}
+
+func TestDLQRedriveOnMemoryBackend(t *testing.T) {
+	body := func(handle string) string {
+		data, _ := json.Marshal(signupRequest(handle, handle+"@example.com"))
+		return string(data)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		receives       string
+		pdsDown        bool
+		expectedRetry  bool
+		expectedReason string
+	}{
+		{name: "Re-driven", body: body("alice"), receives: "1"},
+		{name: "Malformed", body: "{", receives: "1", expectedReason: ReviewReasonMalformed},
+		{name: "Rejected", body: body("squatter"), receives: "1", expectedReason: validate.CodeBlockedHandle},
+		{name: "PDS Down", body: body("alice"), receives: "1", pdsDown: true, expectedRetry: true},
+		{name: "PDS Down Too Often", body: body("alice"), receives: "5", pdsDown: true, expectedReason: ReviewReasonRetriesExhausted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			pds := fakepds.New()
+			defer pds.Close()
+			services := newMemoryServices(t, pds, nil)
+			require.NoError(t, tenantStore(services).BlockHandle(ctx, "squatter", "impersonates staff", "ops@example.com"))
+			if test.pdsDown {
+				pds.Set(fakepds.CreateAccount, fakepds.Unavailable(0))
+			}
+
+			resp, err := NewDLQHandler(services).Handle(ctx, events.SQSEvent{Records: []events.SQSMessage{{
+				MessageId:  "message-1",
+				Body:       test.body,
+				Attributes: map[string]string{"ApproximateReceiveCount": test.receives},
+			}}})
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRetry, len(resp.BatchItemFailures) == 1)
+			reviews := tenantStore(services).Reviews()
+			if test.expectedReason == "" {
+				assert.Empty(t, reviews)
+				return
+			}
+			require.Len(t, reviews, 1)
+			assert.Equal(t, test.expectedReason, reviews[0].Reason)
+			assert.Equal(t, "message-1", reviews[0].MessageID)
+		})
+	}
+}
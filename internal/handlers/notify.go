@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/analytics"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/notify"
+	"github.com/ShareFrame/user-management/internal/outbox"
+	"github.com/ShareFrame/user-management/internal/webhook"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// accountEventPublishers returns a publisher for each configured
+// subscriber: the SNS topic, every partner webhook and the analytics API.
+// Each is wrapped so an event is sent to it at most once. Subscribers whose
+// secret can't be read are skipped.
+func accountEventPublishers(ctx context.Context, services *Services) []outbox.Publisher {
+	cfg, secretsClient := services.Config, services.Secrets
+	shared := services.Stores("")
+
+	var publishers []outbox.Publisher
+	if cfg.AccountEventsTopicARN != "" {
+		publisher := notify.NewPublisher(sns.NewFromConfig(services.AWS), cfg.AccountEventsTopicARN, cfg.Retry)
+		publishers = append(publishers, outbox.Dedupe("sns", publisher, shared))
+	}
+
+	client := &http.Client{Timeout: cfg.HTTPTimeout}
+	for _, e := range cfg.WebhookEndpoints {
+		secret, err := config.RetrieveSecret(ctx, e.SecretName, secretsClient)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("endpoint", e.Name).Error("Failed to retrieve webhook secret")
+			continue
+		}
+		endpoint := webhook.Endpoint{Name: e.Name, URL: e.URL, Secret: secret, Events: e.Events}
+		dispatcher := webhook.NewDispatcher(client, []webhook.Endpoint{endpoint}, cfg.Retry, shared)
+		publishers = append(publishers, outbox.Dedupe("webhook:"+e.Name, dispatcher, shared))
+	}
+
+	if cfg.AnalyticsSecretName != "" {
+		creds, err := helper.RetrieveAnalyticsCreds(ctx, secretsClient, cfg.AnalyticsSecretName)
+		if err == nil && creds.Salt == "" {
+			// Without a salt the pseudonym could be recomputed from the
+			// public DID.
+			err = errors.New("analytics secret has no ANALYTICS_ID_SALT")
+		}
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to retrieve analytics credentials")
+		} else {
+			tracker := analytics.NewTracker(creds.WriteKey, creds.Salt, cfg.AnalyticsEndpoint, client, cfg.Retry)
+			publishers = append(publishers, outbox.Dedupe("analytics", tracker, shared))
+		}
+	}
+
+	return publishers
+}
+
+// publishAccountEvents announces a new account with its consent choices, its
+// preferences and, for organizations, its account type, followed by whether
+// it still needs verifying or, for signups whose email was verified already,
+// that it is verified. The account already exists, so failures are logged
+// rather than returned.
+func publishAccountEvents(ctx context.Context, publishers []outbox.Publisher, tenant string, user models.CreateUserResponse, record models.UserRecord, consents []models.Consent) {
+	events := []string{models.EventAccountCreated, models.EventVerificationPending}
+	if record.Verified {
+		events[1] = models.EventAccountVerified
+	}
+
+	for _, publisher := range publishers {
+		for _, event := range events {
+			accountEvent := models.NewAccountEvent(event, user.DID, user.Handle, tenant)
+			if event == models.EventAccountCreated {
+				accountEvent.Consents = consents
+				accountEvent.Preferences = &record.Preferences
+				if record.AccountType != models.AccountTypePerson {
+					accountEvent.AccountType = record.AccountType
+				}
+			}
+			if err := publisher.Publish(ctx, accountEvent); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("event", event).Warn("Account event not published")
+			}
+		}
+	}
+}
+
+// publishBotCreated announces a bot. Bots have no email address to verify,
+// so no verification event follows as it does for a signup.
+func publishBotCreated(ctx context.Context, publishers []outbox.Publisher, tenant string, user models.CreateUserResponse) {
+	accountEvent := models.NewAccountEvent(models.EventAccountCreated, user.DID, user.Handle, tenant)
+	accountEvent.AccountType = models.AccountTypeBot
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, accountEvent); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("event", accountEvent.Event).Warn("Account event not published")
+		}
+	}
+}
+
+// publishReviewRequested tells subscribers, such as the moderation
+// channel's webhook, that a signup is waiting for review and why. Like
+// publishAccountEvents it only logs failures.
+func publishReviewRequested(ctx context.Context, publishers []outbox.Publisher, tenant string, user models.CreateUserResponse, flags []string) {
+	accountEvent := models.NewAccountEvent(models.EventReviewRequested, user.DID, user.Handle, tenant)
+	accountEvent.ReviewFlags = flags
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, accountEvent); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("event", accountEvent.Event).Warn("Account event not published")
+		}
+	}
+}
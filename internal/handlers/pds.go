@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/ShareFrame/user-management/config"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/faults"
+)
+
+// pdsClients keeps one client per tenant PDS, and the util account's
+// sessions on them, while the container is warm, so a signup reuses the
+// connections and session of the one before it. Clients are built with the
+// config of the first invocation that needs them.
+type pdsClients struct {
+	mu       sync.Mutex
+	clients  map[string]*ATProtocol.ATProtocolClient
+	sessions ATProtocol.SessionCache
+}
+
+func (p *pdsClients) client(cfg *config.Config, tenant config.Tenant) *ATProtocol.ATProtocolClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[tenant.PDSBaseURL]; ok {
+		return client
+	}
+	if p.clients == nil {
+		p.clients = map[string]*ATProtocol.ATProtocolClient{}
+	}
+	client := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	p.clients[tenant.PDSBaseURL] = client
+	return client
+}
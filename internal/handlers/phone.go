@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/phone"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// Phone verification actions.
+const (
+	PhoneActionSend   = "send"
+	PhoneActionVerify = "verify"
+)
+
+// phoneVerificationActor is the audit actor for accounts approved by a
+// verified phone.
+const phoneVerificationActor = "phone_verification"
+
+// PhoneHandler texts a one-time code to an account's phone and checks it
+// back, for callers holding a session of the account. An account held for
+// review only because its signup looked risky is approved once its phone
+// is verified, when PhoneClearsRiskReview is on.
+type PhoneHandler struct {
+	*Services
+}
+
+func NewPhoneHandler(services *Services) *PhoneHandler {
+	return &PhoneHandler{Services: services}
+}
+
+func (h *PhoneHandler) Handle(ctx context.Context, req models.PhoneRequest) (*models.PhoneResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "phone."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"did":    req.DID,
+		"tenant": req.Tenant,
+	}).Info("Processing phone verification")
+
+	if req.DID == "" || req.AccessJWT == "" {
+		return nil, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeMissingFields, "did and accessJwt are required"))
+	}
+	if req.Action != PhoneActionSend && req.Action != PhoneActionVerify {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown action %q", req.Action)
+	}
+
+	cfg, awsCfg := h.Config, h.AWS
+	if !cfg.PhoneVerification {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: phone verification is not enabled")
+	}
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	metrics.FromContext(ctx).SetDimension(metrics.DimensionTenant, tenant.ID)
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	pds := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	if err := checkSession(ctx, pds, req.DID, req.AccessJWT); err != nil {
+		return nil, err
+	}
+
+	store := h.Stores(tenant.TablePrefix)
+	verifier := phone.NewVerifier(store, phone.NewSNSSender(sns.NewFromConfig(awsCfg), cfg.SMSSenderID, cfg.Retry), cfg.PhoneCodeTTL, cfg.PhoneMaxAccounts)
+	verifier.Limiter, verifier.NumberLimit = h.Limiter, cfg.PhoneSendsPerDay
+
+	if req.Action == PhoneActionSend {
+		response, err := verifier.Send(ctx, req.DID, req.Phone)
+		if err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+
+	account, number, err := verifier.Verify(ctx, req.DID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	response := &models.PhoneResponse{Phone: number, Verified: true}
+	if cfg.PhoneClearsRiskReview && onlyRiskReview(account) {
+		if _, _, err := lifecycle.New(store, store).Fire(ctx, req.DID, lifecycle.EventApprove, phoneVerificationActor); err != nil {
+			// The phone stays verified; a moderator can still approve.
+			logging.FromContext(ctx).WithError(err).WithField("did", req.DID).Error("Failed to approve account after phone verification")
+		} else {
+			response.Approved = true
+		}
+	}
+	return response, nil
+}
+
+// checkSession makes sure accessJWT is a session of did on the PDS.
+func checkSession(ctx context.Context, pds *ATProtocol.ATProtocolClient, did, accessJWT string) error {
+	owner, err := pds.GetSession(ctx, accessJWT)
+	if ATProtocol.IsSessionRejected(err) || err == nil && owner != did {
+		logging.FromContext(ctx).WithField("did", did).Warn("Phone verification refused without a session of the account")
+		return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidSession, "accessJwt is not a session of %s", did).ForField(validate.FieldAccessJWT))
+	}
+	return err
+}
+
+// onlyRiskReview reports whether account is held for review because of its
+// signup risk score and nothing else a moderator should look at.
+func onlyRiskReview(account models.UserRecord) bool {
+	return account.Status == models.StatusPendingReview &&
+		len(account.ReviewFlags) == 1 && account.ReviewFlags[0] == helper.FlagSignupRisk
+}
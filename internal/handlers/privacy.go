@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/billing"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/crm"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/privacy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	PrivacyActionExport       = "export"
+	PrivacyActionErase        = "erase"
+	PrivacyActionConfirmErase = "confirm_erase"
+)
+
+// PrivacyHandler serves data subject requests: exports and erasures. Like
+// the blocklist it is deployed as a separate function that only operators
+// can invoke, and the audit trail names the operator by their
+// authenticated identity.
+type PrivacyHandler struct {
+	*Services
+}
+
+func NewPrivacyHandler(services *Services) *PrivacyHandler {
+	return &PrivacyHandler{Services: services}
+}
+
+func (h *PrivacyHandler) Handle(ctx context.Context, req models.PrivacyRequest) (*models.PrivacyResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "privacy."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	actor, _ := caller.From(ctx)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"did":    req.DID,
+		"actor":  actor,
+		"tenant": req.Tenant,
+	}).Info("Processing privacy request")
+
+	if req.DID == "" || actor == "" {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: did and an authenticated caller are required")
+	}
+
+	cfg, awsCfg := h.Config, h.AWS
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+	shared := h.Stores("")
+
+	var resp models.PrivacyResponse
+	switch req.Action {
+	case PrivacyActionExport:
+		resp, err = h.export(ctx, cfg, awsCfg, tenant, store, shared, req)
+	case PrivacyActionErase, PrivacyActionConfirmErase:
+		resp, err = h.erase(ctx, cfg, awsCfg, tenant, store, shared, req)
+	default:
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown privacy action %q", req.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (h *PrivacyHandler) export(ctx context.Context, cfg *config.Config, awsCfg aws.Config, tenant config.Tenant, store, shared postgres.Store, req models.PrivacyRequest) (models.PrivacyResponse, error) {
+	if cfg.PrivacyExportBucket == "" {
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.Internal, "internal error: no privacy export bucket is configured")
+	}
+
+	s3Client := s3.NewFromConfig(awsCfg)
+	exporter := &privacy.Exporter{
+		Accounts:  store,
+		Consents:  store,
+		Emails:    shared,
+		Audit:     store,
+		Objects:   s3Client,
+		Presigner: s3.NewPresignClient(s3Client),
+		Bucket:    cfg.PrivacyExportBucket,
+		URLTTL:    cfg.ExportURLTTL,
+	}
+	actor, _ := caller.From(ctx)
+	return exporter.Export(ctx, tenant.ID, req.DID, actor)
+}
+
+// erase issues the confirmation for an erasure, or carries it out once the
+// confirmation comes back.
+func (h *PrivacyHandler) erase(ctx context.Context, cfg *config.Config, awsCfg aws.Config, tenant config.Tenant, store, shared postgres.Store, req models.PrivacyRequest) (models.PrivacyResponse, error) {
+	if !tokensEnabled(cfg) {
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.Internal, "internal error: erasure confirmations need a token key")
+	}
+	key, err := tokenKey(ctx, cfg, awsCfg, h.Secrets)
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("internal error: could not load token key: %w", err)
+	}
+
+	eraser := &privacy.Eraser{
+		Accounts:        store,
+		Emails:          shared,
+		Audit:           store,
+		Key:             key,
+		ConfirmationTTL: cfg.ErasureConfirmTTL,
+	}
+	if cfg.HandleQuarantine > 0 {
+		eraser.Quarantine = store
+		eraser.QuarantineTTL = cfg.HandleQuarantine
+	}
+	actor, _ := caller.From(ctx)
+	if req.Action == PrivacyActionErase {
+		return eraser.Request(ctx, tenant.ID, req.DID, actor)
+	}
+
+	atProtoClient := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	eraser.DeletePDSAccount = func(ctx context.Context, did string) error {
+		adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.Secrets, tenant.AdminSecretName)
+		if err != nil {
+			return fmt.Errorf("internal error: could not retrieve admin credentials: %w", err)
+		}
+		return atProtoClient.DeleteAccount(ctx, adminCreds, did)
+	}
+	eraser.Reviews, eraser.Billing = shared, store
+	// Contacts and customers made while the integration was on are deleted
+	// as long as its credentials are configured.
+	if cfg.CRMProvider != "" {
+		eraser.DeleteCRMContact = func(ctx context.Context, email string) error {
+			creds, err := helper.RetrieveCRMCreds(ctx, h.Secrets, cfg.CRMSecretName)
+			if err != nil {
+				return fmt.Errorf("internal error: could not retrieve CRM credentials: %w", err)
+			}
+			syncer, err := crm.New(cfg.CRMProvider, creds.APIKey, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.Retry)
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			return syncer.DeleteContact(ctx, email)
+		}
+	}
+	if cfg.StripeSecretName != "" {
+		eraser.DeleteStripeCustomer = func(ctx context.Context, customerID string) error {
+			creds, err := helper.RetrieveStripeCreds(ctx, h.Secrets, cfg.StripeSecretName)
+			if err != nil {
+				return fmt.Errorf("internal error: could not retrieve Stripe credentials: %w", err)
+			}
+			client := billing.NewStripeClient(creds.SecretKey, newHTTPClient(cfg, faults.TargetBilling), cfg.Retry)
+			return client.DeleteCustomer(ctx, customerID)
+		}
+	}
+	eraser.Publishers = accountEventPublishers(ctx, h.Services)
+	return eraser.Confirm(ctx, tenant.ID, req.DID, actor, req.Confirmation)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// signupProgress records the steps of a signup under its idempotency key.
+// A nil *signupProgress, for a signup without a key, records nothing and
+// has done nothing.
+type signupProgress struct {
+	store    postgres.SignupProgressStore
+	progress models.SignupProgress
+}
+
+// loadSignupProgress returns the progress of the signup with key, which
+// is empty unless an earlier invocation recorded some. A signup whose
+// progress can't be read fails rather than risk repeating its steps.
+func loadSignupProgress(ctx context.Context, store postgres.SignupProgressStore, key string) (*signupProgress, error) {
+	if key == "" {
+		return nil, nil
+	}
+	progress, found, err := store.SignupProgress(ctx, key)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: failed to load signup progress: %w", err)
+	}
+	if !found {
+		progress = models.SignupProgress{IdempotencyKey: key}
+	} else {
+		logging.FromContext(ctx).WithField("steps", progress.Steps).Info("Resuming signup")
+	}
+	return &signupProgress{store: store, progress: progress}, nil
+}
+
+func (p *signupProgress) done(step string) bool {
+	return p != nil && p.progress.Done(step)
+}
+
+// check rejects a key that was used for another handle.
+func (p *signupProgress) check(handle string) error {
+	if p == nil || p.progress.Handle == "" || p.progress.Handle == handle {
+		return nil
+	}
+	return apperr.Errorf(apperr.Conflict, "idempotency key %s was used for another signup", p.progress.IdempotencyKey)
+}
+
+// checkResume rejects resuming a social signup that already registered its
+// account. Its password was generated by the invocation that registered
+// it and never stored, so nothing can sign in to the account to finish the
+// signup.
+func (p *signupProgress) checkResume(social bool) error {
+	if !social || !p.done(models.SignupStepPDSRegistered) {
+		return nil
+	}
+	return apperr.Errorf(apperr.Conflict, "conflict: idempotency key %s already created a social account, which can't be resumed", p.progress.IdempotencyKey)
+}
+
+// record marks step done for handle. A step that isn't recorded is only
+// run again on a retry, so failures are logged.
+func (p *signupProgress) record(ctx context.Context, handle, step string) {
+	if p == nil || p.done(step) {
+		return
+	}
+	p.progress.Handle = handle
+	p.progress.Steps = append(p.progress.Steps, step)
+	if err := p.store.SaveSignupProgress(ctx, p.progress); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("step", step).Warn("Signup step not recorded")
+	}
+}
+
+// inviteCode is the invite minted for the signup by an earlier invocation.
+func (p *signupProgress) inviteCode() string {
+	if !p.done(models.SignupStepInviteCreated) {
+		return ""
+	}
+	return p.progress.InviteCode
+}
+
+func (p *signupProgress) recordInvite(ctx context.Context, handle, code string) {
+	if p != nil {
+		p.progress.InviteCode = code
+		p.record(ctx, handle, models.SignupStepInviteCreated)
+	}
+}
+
+func (p *signupProgress) recordRegistered(ctx context.Context, user models.CreateUserResponse) {
+	if p != nil {
+		p.progress.DID = user.DID
+		p.record(ctx, user.Handle, models.SignupStepPDSRegistered)
+	}
+}
+
+// resumeOnPDS signs in to the account an earlier invocation created,
+// instead of registering it again. It needs the password the account was
+// registered with, which is why social signups aren't resumed.
+func resumeOnPDS(ctx context.Context, client *ATProtocol.ATProtocolClient, progress *signupProgress, password string) (models.CreateUserResponse, error) {
+	session, err := client.CreateSession(ctx, progress.progress.DID, password)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", progress.progress.DID).Error("Failed to sign in to the account of a resumed signup")
+		return models.CreateUserResponse{}, fmt.Errorf("failed to resume signup: %w", err)
+	}
+	return models.CreateUserResponse{
+		Handle:     session.Handle,
+		DID:        session.Did,
+		AccessJWT:  session.AccessJwt,
+		RefreshJWT: session.RefreshJwt,
+	}, nil
+}
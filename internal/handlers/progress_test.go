@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignupProgressCheckResume(t *testing.T) {
+	registered := &signupProgress{progress: models.SignupProgress{
+		IdempotencyKey: "key-1",
+		Steps:          []string{models.SignupStepInviteCreated, models.SignupStepPDSRegistered},
+	}}
+	invited := &signupProgress{progress: models.SignupProgress{
+		IdempotencyKey: "key-2",
+		Steps:          []string{models.SignupStepInviteCreated},
+	}}
+
+	tests := []struct {
+		name     string
+		progress *signupProgress
+		social   bool
+		conflict bool
+	}{
+		{name: "No Key", progress: nil, social: true},
+		{name: "Password Signup Resumed", progress: registered},
+		{name: "Social Signup Before Registration", progress: invited, social: true},
+		{name: "Social Signup After Registration", progress: registered, social: true, conflict: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.progress.checkResume(test.social)
+
+			if !test.conflict {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, apperr.Conflict, apperr.CategoryOf(err))
+		})
+	}
+}
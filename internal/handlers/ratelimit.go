@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/email"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+)
+
+// limitEmails applies the per-recipient cap to sender when one is set.
+func limitEmails(cfg *config.Config, limiter ratelimit.Limiter, sender email.Sender) email.Sender {
+	if limiter == nil || cfg.EmailRecipientLimit == 0 {
+		return sender
+	}
+	return email.NewLimitedSender(sender, limiter, cfg.EmailRecipientLimit)
+}
+
+// signupQuota returns the daily signup quotas, or nil when none is set.
+func signupQuota(cfg *config.Config, quota ratelimit.Quota) *helper.SignupQuota {
+	if !cfg.SignupQuota.Enabled() || quota == nil {
+		return nil
+	}
+	return &helper.SignupQuota{Quota: quota, Limits: cfg.SignupQuota}
+}
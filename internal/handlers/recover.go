@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// PanicError is returned in place of a handler that panicked. Lambda
+// reports it with errorType "PanicError", and the request ID lets the
+// caller's report be matched with the stack trace in the logs.
+type PanicError struct {
+	Operation string `json:"operation"`
+	RequestID string `json:"requestId"`
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("internal error: %s request %s failed unexpectedly", e.Operation, e.RequestID)
+}
+
+func (e *PanicError) ErrorCode() string {
+	return validate.CodeInternal
+}
+
+// Recover wraps a Lambda handler so a panic is logged with its stack trace,
+// counted in the PanicCount metric and returned as a PanicError instead of
+// crashing the runtime.
+func Recover[In, Out any](operation string, handler func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, in In) (out Out, err error) {
+		ctx = logging.NewRequestContext(ctx, operation)
+		defer func() {
+			if value := recover(); value != nil {
+				var zero Out
+				out, err = zero, recovered(ctx, operation, value)
+			}
+		}()
+		return handler(ctx, in)
+	}
+}
+
+// RecoverHTTP is Recover for the local HTTP server: a panic becomes a 500
+// response carrying the request ID.
+func RecoverHTTP(operation string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := logging.NewRequestContext(r.Context(), operation)
+		defer func() {
+			if value := recover(); value != nil {
+				err := recovered(ctx, operation, value)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{
+					"error":     err.Error(),
+					"code":      err.ErrorCode(),
+					"requestId": err.RequestID,
+				})
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func recovered(ctx context.Context, operation string, value interface{}) *PanicError {
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"panic": fmt.Sprint(value),
+		"stack": string(debug.Stack()),
+	}).Error("Recovered from panic")
+
+	recorder := metrics.New()
+	metrics.CountWith(recorder, metrics.PanicCount, map[string]string{metrics.DimensionOperation: operation})
+	recorder.Flush()
+
+	return &PanicError{Operation: operation, RequestID: logging.RequestID(ctx)}
+}
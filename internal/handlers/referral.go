@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/referral"
+)
+
+const (
+	ReferralActionIssueCode = "issue_code"
+	ReferralActionVerified  = "verified"
+	ReferralActionStats     = "stats"
+)
+
+// ReferralHandler issues referral codes, rewards referrals once the
+// referred account is verified, and reports how many signups a code
+// drove. The lifecycle handler rewards referrals as accounts are
+// verified; the verified action is for replaying one that failed.
+type ReferralHandler struct {
+	*Services
+}
+
+func NewReferralHandler(services *Services) *ReferralHandler {
+	return &ReferralHandler{Services: services}
+}
+
+func (h *ReferralHandler) Handle(ctx context.Context, req models.ReferralRequest) (*models.ReferralResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "referral."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"did":    req.DID,
+		"code":   req.Code,
+		"tenant": req.Tenant,
+	}).Info("Processing referral request")
+
+	cfg := h.Config
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+	tracker := referral.NewTracker(store, store)
+
+	switch req.Action {
+	case ReferralActionIssueCode:
+		if req.DID == "" {
+			return nil, apperr.Errorf(apperr.Validation, "validation error: did is required")
+		}
+		code, err := tracker.IssueCode(ctx, req.DID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.ReferralResponse{Code: code}, nil
+	case ReferralActionVerified:
+		if req.DID == "" {
+			return nil, apperr.Errorf(apperr.Validation, "validation error: did is required")
+		}
+		rewarded, ok, err := tracker.Verified(ctx, req.DID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return &models.ReferralResponse{}, nil
+		}
+		return &models.ReferralResponse{Referral: &rewarded}, nil
+	case ReferralActionStats:
+		stats, err := tracker.Stats(ctx, req.Code)
+		if err != nil {
+			return nil, err
+		}
+		return &models.ReferralResponse{Stats: &stats}, nil
+	default:
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown referral action %q", req.Action)
+	}
+}
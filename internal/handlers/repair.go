@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/repair"
+)
+
+// RepairHandler runs the account repair job over one tenant. It is run by
+// operators from cmd/repair rather than deployed.
+type RepairHandler struct {
+	*Services
+}
+
+func NewRepairHandler(services *Services) *RepairHandler {
+	return &RepairHandler{Services: services}
+}
+
+func (h *RepairHandler) Handle(ctx context.Context, req models.RepairRequest) (*models.RepairResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "repair")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"tenant":         req.Tenant,
+		"dry_run":        req.DryRun,
+		"delete_orphans": req.DeleteOrphans,
+	}).Info("Processing repair request")
+
+	cfg := h.Config
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	// The utility account lives on the PDS without a users row.
+	utilAccountCreds, err := helper.RetrieveUtilAccountCreds(ctx, h.Secrets, tenant.UtilSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: could not retrieve util account credentials: %w", err)
+	}
+	if utilAccountCreds.DID == "" {
+		return nil, apperr.Errorf(apperr.Internal, "internal error: the util account secret has no DID, so it can't be told apart from orphans")
+	}
+
+	atProtoClient := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	repairer := &repair.Repairer{
+		Users: h.Stores(tenant.TablePrefix),
+		Repos: atProtoClient,
+		DeletePDSAccount: func(ctx context.Context, did string) error {
+			adminCreds, err := helper.RetrieveAdminCredentials(ctx, h.Secrets, tenant.AdminSecretName)
+			if err != nil {
+				return fmt.Errorf("internal error: could not retrieve admin credentials: %w", err)
+			}
+			return atProtoClient.DeleteAccount(ctx, adminCreds, did)
+		},
+		Keep:          []string{utilAccountCreds.DID},
+		BatchSize:     req.BatchSize,
+		DeleteOrphans: req.DeleteOrphans,
+		DryRun:        req.DryRun,
+	}
+
+	resp, err := repairer.Run(ctx)
+	if err != nil {
+		return &resp, fmt.Errorf("repair stopped: %w", err)
+	}
+	return &resp, nil
+}
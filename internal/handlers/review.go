@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+const (
+	ReviewActionList    = "list"
+	ReviewActionApprove = "approve"
+	ReviewActionReject  = "reject"
+)
+
+// ReviewHandler is the moderators' view of signups held for review, the
+// ones abuse scoring or the profanity check flagged. Approving one lets it
+// carry on to verification; rejecting one deletes it from the PDS and
+// releases its handle and email address. The moderation channel hears of
+// new entries through the account.review_requested event. Approvals and
+// rejections need an authenticated moderator, whom the audit trail names.
+type ReviewHandler struct {
+	*Services
+}
+
+func NewReviewHandler(services *Services) *ReviewHandler {
+	return &ReviewHandler{Services: services}
+}
+
+func (h *ReviewHandler) Handle(ctx context.Context, req models.ReviewRequest) (*models.ReviewResponse, error) {
+	ctx = logging.NewRequestContext(ctx, "review."+req.Action)
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+	actor, _ := caller.From(ctx)
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"action": req.Action,
+		"did":    req.DID,
+		"actor":  actor,
+		"tenant": req.Tenant,
+	}).Info("Processing review request")
+
+	var event string
+	switch req.Action {
+	case ReviewActionList:
+	case ReviewActionApprove:
+		event = lifecycle.EventApprove
+	case ReviewActionReject:
+		event = lifecycle.EventReject
+	default:
+		return nil, apperr.Errorf(apperr.Validation, "validation error: unknown review action %q", req.Action)
+	}
+	if event != "" && (req.DID == "" || actor == "") {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: did and an authenticated caller are required")
+	}
+
+	cfg := h.Config
+
+	tenant, err := cfg.ResolveTenant(req.Tenant)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("tenant", req.Tenant).Warn("Failed to resolve tenant")
+		return nil, apperr.Errorf(apperr.Validation, "validation error: %w", err)
+	}
+	ctx = logging.WithTenant(ctx, tenant.ID)
+
+	store := h.Stores(tenant.TablePrefix)
+
+	if event == "" {
+		queue, err := store.ListReviewQueue(ctx, req.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("internal error: could not list review queue: %w", err)
+		}
+		return &models.ReviewResponse{Queue: queue}, nil
+	}
+
+	transition, err := NewLifecycleHandler(h.Services).Handle(ctx, models.LifecycleRequest{
+		Event:  event,
+		DID:    req.DID,
+		Tenant: tenant.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rejecting an account that is already rejected finishes releasing
+	// it, in case that failed the first time.
+	if event == lifecycle.EventReject && !transition.Changed {
+		if err := releaseRejected(ctx, h.Secrets, cfg, tenant, store, req.DID); err != nil {
+			return nil, err
+		}
+	}
+	return &models.ReviewResponse{Transition: transition}, nil
+}
+
+// releaseRejected deletes a rejected account from the tenant's PDS and
+// anonymizes its row, so its handle and email address can be used for a
+// new signup. Both steps can be repeated.
+func releaseRejected(ctx context.Context, secretsClient config.SecretsManagerAPI, cfg *config.Config, tenant config.Tenant, store postgres.ReviewQueueStore, did string) error {
+	adminCreds, err := helper.RetrieveAdminCredentials(ctx, secretsClient, tenant.AdminSecretName)
+	if err != nil {
+		return fmt.Errorf("internal error: could not retrieve admin credentials: %w", err)
+	}
+	client := ATProtocol.NewATProtocolClient(tenant.PDSBaseURL, newHTTPClient(cfg, faults.TargetPDS), cfg.Retry)
+	if err := client.DeleteAccount(ctx, adminCreds, did); err != nil {
+		return fmt.Errorf("internal error: could not delete rejected account from PDS: %w", err)
+	}
+
+	if err := store.ReleaseUser(ctx, did); err != nil {
+		return fmt.Errorf("internal error: could not release rejected account: %w", err)
+	}
+	logging.FromContext(ctx).WithField("did", did).Info("Rejected account released")
+	return nil
+}
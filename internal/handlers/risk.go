@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/risk"
+)
+
+// riskOptions configures abuse scoring. Without a readable captcha secret
+// high-risk signups are flagged for review instead of challenged.
+func (h *UserHandler) riskOptions(ctx context.Context, cfg *config.Config, store helper.SignupVelocityChecker) *helper.RiskOptions {
+	opts := &helper.RiskOptions{
+		Velocity:     store,
+		Window:       cfg.RiskWindow,
+		Limits:       risk.DefaultLimits,
+		ReviewScore:  cfg.RiskReviewScore,
+		CaptchaScore: cfg.RiskCaptchaScore,
+	}
+
+	if cfg.CaptchaSecretName != "" {
+		secret, err := config.RetrieveSecret(ctx, cfg.CaptchaSecretName, h.Secrets)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to retrieve captcha secret; high-risk signups will be flagged instead")
+		} else {
+			opts.Captcha = risk.NewTurnstileClient(secret, &http.Client{Timeout: cfg.HTTPTimeout})
+		}
+	}
+	return opts
+}
+
+// signupAttempt records the outcome of one signup for risk scoring, domain
+// throttling and the signup stats. A nil *signupAttempt, used when all are
+// off, records nothing.
+type signupAttempt struct {
+	store       postgres.SignupAttemptStore
+	ip          string
+	emailDomain string
+}
+
+// record records the attempt as failed with err, or succeeded if it is nil.
+func (a *signupAttempt) record(ctx context.Context, err error) {
+	if a == nil {
+		return
+	}
+	var reason string
+	if err != nil {
+		reason = failureReason(err)
+	}
+	if err := a.store.RecordSignupAttempt(ctx, a.ip, a.emailDomain, reason); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Signup attempt not recorded")
+	}
+}
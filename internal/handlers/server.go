@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/ShareFrame/user-management/internal/graphql"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/openapi"
+	"github.com/ShareFrame/user-management/pkg/api"
+)
+
+// Routes are the handlers NewRouter serves. The operator handlers are only
+// needed when the admin routes are.
+type Routes struct {
+	Users        *UserHandler
+	Claims       *ClaimHandler
+	Bots         *BotHandler
+	Avatars      *AvatarHandler
+	Phone        *PhoneHandler
+	Referrals    *ReferralHandler
+	Availability *AvailabilityHandler
+
+	Admin     *AdminHandler
+	Blocklist *BlocklistHandler
+	Lifecycle *LifecycleHandler
+	Privacy   *PrivacyHandler
+	Review    *ReviewHandler
+}
+
+// NewRouter serves the handlers over plain HTTP, for the standalone server
+// and the Lambda binary's local mode:
+//
+//	GET  /healthz           liveness and readiness
+//	POST /users             create an account (also at /, as before)
+//	POST /claims            reserve a handle
+//	POST /bots              create a bot account for its owner
+//	POST /avatars           apply the profile picture uploaded at signup
+//	POST /phone             send or check a phone verification code
+//	POST /referrals         issue a referral code or read a code's stats
+//	POST /availability      check a handle and an email address before signup
+//	POST /graphql           the same operations as GraphQL
+//	GET  /openapi.json      the OpenAPI document of these routes
+//
+// Every route is also served under each API version's prefix, such as
+// /v1/claims and /v2/claims, with a document of its own at
+// /v1/openapi.json and /v2/openapi.json. The paths without a prefix are
+// v1's, as they were before versioning. A later version serves a route of
+// its own where its schema changed, as /v2/users does; see
+// api.CreateUserRequestV2. Request bodies are checked against the OpenAPI
+// document of their version before the handlers see them.
+//
+// With admin set, the operator routes are added too: /admin/accounts,
+// /admin/blocklist, /admin/lifecycle, /admin/privacy and /admin/review.
+// They have no authentication of their own, so admin must only be set
+// where the server is unreachable from outside. The operator the audit
+// trail names is taken from the CallerHeader an authenticating proxy in
+// front of the server sets.
+func NewRouter(routes Routes, admin bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	createAccount := routes.Operation(OperationCreateAccount)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// "/" matches every path the mux doesn't know, which must not
+		// create accounts.
+		if r.URL.Path != "/" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		createAccount.ServeHTTP(w, r)
+	})
+
+	for _, version := range append([]string{""}, api.Versions...) {
+		spec := routes.Spec(version, admin)
+		mux.HandleFunc(versionPrefix(version)+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, spec)
+		})
+		for _, route := range routes.served(version) {
+			if route.admin && !admin {
+				continue
+			}
+			handler := validated(spec, route.path, route.handler)
+			if route.admin {
+				handler = authenticated(handler)
+			}
+			mux.Handle(route.path, RecoverHTTP(route.operation, handler))
+		}
+	}
+	return mux
+}
+
+// Operations served over HTTP, as they are logged and counted.
+const (
+	OperationCreateAccount = "create_account"
+	OperationClaimHandle   = "claim_handle"
+	OperationCreateBot     = "create_bot"
+	OperationAvatar        = "avatar"
+	OperationPhone         = "phone"
+	OperationReferral      = "referral"
+	OperationAvailability  = "check_availability"
+	OperationGraphQL       = "graphql"
+	OperationAdmin         = "admin"
+	OperationBlocklist     = "blocklist"
+	OperationLifecycle     = "lifecycle"
+	OperationPrivacy       = "privacy"
+	OperationReview        = "review"
+)
+
+// CallerHeader names the operator calling the admin routes NewRouter
+// serves. Operation ignores it: behind a Lambda integration the caller is
+// the IAM identity the integration authenticated.
+const CallerHeader = api.CallerHeader
+
+// authenticated passes the CallerHeader on to next as the caller.
+func authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity := strings.TrimSpace(r.Header.Get(CallerHeader)); identity != "" {
+			r = r.WithContext(caller.With(r.Context(), identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireCaller refuses requests to next that have no authenticated
+// caller.
+func requireCaller(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := caller.From(r.Context()); !ok {
+			writeJSON(w, http.StatusForbidden, api.ErrorResponse{Detail: "forbidden: operator calls need an authenticated caller"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Operation serves the one operation over HTTP whatever the request path,
+// the way NewRouter serves it at its own path, for a Lambda function behind
+// an HTTP integration. A path naming an API version, such as /v2/users or
+// /prod/v2/users, is served in that version, and any other path in v1. It
+// is nil for an operation with no route. Operator operations are refused
+// unless the integration authenticated the caller.
+func (routes Routes) Operation(operation string) http.Handler {
+	versions := map[string]http.Handler{}
+	for _, version := range api.Versions {
+		spec := routes.Spec(version, true)
+		for _, route := range routes.served(version) {
+			if route.operation == operation {
+				handler := validated(spec, route.path, route.handler)
+				if route.admin {
+					handler = requireCaller(handler)
+				}
+				versions[version] = RecoverHTTP(route.operation, handler)
+			}
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := versions[pathVersion(r.URL.Path)]
+		if !ok {
+			handler = versions[api.Version1]
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// pathVersion is the API version a segment of path names, or empty.
+func pathVersion(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if slices.Contains(api.Versions, segment) {
+			return segment
+		}
+	}
+	return ""
+}
+
+// versionPrefix is the path prefix of version's routes; the empty version
+// has none.
+func versionPrefix(version string) string {
+	if version == "" {
+		return ""
+	}
+	return "/" + version
+}
+
+// route is a path NewRouter serves. The summary and the types of its
+// request and response body describe it in the OpenAPI document. since is
+// the API version the route was added in, empty for v1.
+type route struct {
+	path      string
+	since     string
+	operation string
+	handler   http.Handler
+	admin     bool
+	summary   string
+	request   reflect.Type
+	response  reflect.Type
+	status    int
+}
+
+// jsonOperation is the route serving handle with jsonRoute.
+func jsonOperation[In, Out any](path, operation, summary string, handle func(context.Context, In) (Out, error), status int) route {
+	return route{
+		path:      path,
+		operation: operation,
+		handler:   jsonRoute(handle, status),
+		summary:   summary,
+		request:   reflect.TypeOf((*In)(nil)).Elem(),
+		response:  reflect.TypeOf((*Out)(nil)).Elem(),
+		status:    status,
+	}
+}
+
+// operator marks r as one of the admin routes.
+func (r route) operator() route {
+	r.admin = true
+	return r
+}
+
+func (routes Routes) table() []route {
+	users, claims, phone, referrals := routes.Users, routes.Claims, routes.Phone, routes.Referrals
+	return []route{
+		{
+			path:      "/users",
+			operation: OperationCreateAccount,
+			handler:   NewHTTPHandler(users),
+			summary:   "Create an account",
+			request:   reflect.TypeOf(models.UserRequest{}),
+			response:  reflect.TypeOf(models.CreateUserResponse{}),
+			status:    http.StatusCreated,
+		},
+		{
+			path:      "/users",
+			since:     api.Version2,
+			operation: OperationCreateAccount,
+			handler:   &HTTPHandler{Users: users, Version: api.Version2},
+			summary:   "Create an account",
+			request:   reflect.TypeOf(api.CreateUserRequestV2{}),
+			response:  reflect.TypeOf(models.CreateUserResponse{}),
+			status:    http.StatusCreated,
+		},
+		jsonOperation("/claims", OperationClaimHandle, "Reserve a handle", claims.Handle, http.StatusCreated),
+		jsonOperation("/bots", OperationCreateBot, "Create a bot account for its owner", routes.Bots.Handle, http.StatusCreated),
+		jsonOperation("/avatars", OperationAvatar, "Apply the profile picture uploaded at signup", routes.Avatars.Handle, http.StatusOK),
+		jsonOperation("/phone", OperationPhone, "Send or check a phone verification code", phone.Handle, http.StatusOK),
+		jsonOperation("/referrals", OperationReferral, "Issue a referral code or read a code's stats", referrals.Handle, http.StatusOK),
+		jsonOperation("/availability", OperationAvailability, "Check a handle and an email address before signup", routes.Availability.Handle, http.StatusOK),
+		{
+			path:      "/graphql",
+			operation: OperationGraphQL,
+			handler:   NewGraphQLHandler(users, claims, phone, referrals),
+			summary:   "Run a GraphQL operation",
+			request:   reflect.TypeOf(graphql.Request{}),
+			response:  reflect.TypeOf(graphql.Response{}),
+			status:    http.StatusOK,
+		},
+
+		jsonOperation("/admin/accounts", OperationAdmin, "Look an account up, re-send its verification email, mint invite codes or report the signup quotas and stats", routes.Admin.Handle, http.StatusOK).operator(),
+		jsonOperation("/admin/blocklist", OperationBlocklist, "Edit or list the handle blocklist", routes.Blocklist.Handle, http.StatusOK).operator(),
+		jsonOperation("/admin/lifecycle", OperationLifecycle, "Move an account along its lifecycle", routes.Lifecycle.Handle, http.StatusOK).operator(),
+		jsonOperation("/admin/privacy", OperationPrivacy, "Export or erase an account's data", routes.Privacy.Handle, http.StatusOK).operator(),
+		jsonOperation("/admin/review", OperationReview, "Work the review queue", routes.Review.Handle, http.StatusOK).operator(),
+	}
+}
+
+// served is the route table of version, at the paths with its prefix: the
+// latest route for each path added in version or before it. The empty
+// version is v1 at the paths without a prefix.
+func (routes Routes) served(version string) []route {
+	prefix := versionPrefix(version)
+	if version == "" {
+		version = api.Version1
+	}
+	var served []route
+	at := map[string]int{}
+	for _, route := range routes.table() {
+		since := route.since
+		if since == "" {
+			since = api.Version1
+		}
+		if slices.Index(api.Versions, since) > slices.Index(api.Versions, version) {
+			continue
+		}
+		route.path = prefix + route.path
+		if i, ok := at[route.path]; ok {
+			served[i] = route
+			continue
+		}
+		at[route.path] = len(served)
+		served = append(served, route)
+	}
+	return served
+}
+
+// Spec is the OpenAPI document of the routes NewRouter serves for version
+// with admin; the empty version is the routes without a prefix. It only
+// depends on the route table, so the zero Routes has it too.
+func (routes Routes) Spec(version string, admin bool) *openapi.Document {
+	described := []openapi.Route{
+		{Path: "/healthz", Method: http.MethodGet, OperationID: "health", Summary: "Liveness and readiness", Tag: "health", Status: http.StatusOK},
+	}
+	for _, route := range routes.served(version) {
+		if route.admin && !admin {
+			continue
+		}
+		tag := "accounts"
+		if route.admin {
+			tag = "admin"
+		}
+		described = append(described, openapi.Route{
+			Path:        route.path,
+			Method:      http.MethodPost,
+			OperationID: route.operation,
+			Summary:     route.summary,
+			Tag:         tag,
+			Request:     route.request,
+			Response:    route.response,
+			Status:      route.status,
+		})
+	}
+	return openapi.New(openapi.Info{
+		Title:       "ShareFrame User Creation",
+		Description: "Account signup and management. Request bodies with unknown fields or values of the wrong type are rejected with the invalid_request code.",
+		Version:     specVersions[version],
+	}, described)
+}
+
+// specVersions are the versions of the OpenAPI documents, by API version.
+var specVersions = map[string]string{
+	"":           "1.0.0",
+	api.Version1: "1.0.0",
+	api.Version2: "2.0.0",
+}
+
+// validated checks request bodies against the route at path in spec before
+// next decodes them, answering failures like a handler's validation
+// errors.
+func validated(spec *openapi.Document, path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(limitBody(w, r))
+		if err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if err := spec.ValidateRequest(path, body); err != nil {
+			writeError(w, apperr.Wrap(apperr.Validation, err), requestLocale(r))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/email"
+	"github.com/ShareFrame/user-management/internal/faults"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/memory"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/internal/snapshots"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Services are the settings and clients every handler runs on. app.New
+// builds them once per process and hands the same Services to each
+// handler, so a request only resolves its tenant and opens that tenant's
+// store.
+type Services struct {
+	Config  *config.Config
+	AWS     aws.Config
+	Secrets config.SecretsManagerAPI
+	// Stores opens the tables of the tenant with tablePrefix, or the shared
+	// tables for "".
+	Stores func(tablePrefix string) postgres.Store
+	// Email sends through Resend. It is nil when no RESEND_SECRET_NAME is
+	// configured.
+	Email email.Sender
+	// Limiter and Quota keep the rate limit and signup quota counters. They
+	// are nil when no RATE_LIMIT_TABLE is configured, except on the memory
+	// backend, which counts in memory.
+	Limiter ratelimit.Limiter
+	Quota   ratelimit.Quota
+	// Snapshots keeps the configuration snapshot of each cold start. It is
+	// nil when no CONFIG_SNAPSHOT_TABLE is configured, except on the memory
+	// backend.
+	Snapshots snapshots.Store
+}
+
+// NewServices builds the storage, counters and email sender cfg
+// configures.
+func NewServices(cfg *config.Config, awsCfg aws.Config, secrets config.SecretsManagerAPI) *Services {
+	services := &Services{
+		Config:  cfg,
+		AWS:     awsCfg,
+		Secrets: secrets,
+		Stores:  postgresStores(cfg, newRDSClient(cfg, awsCfg)),
+	}
+	if cfg.StorageBackend == config.BackendMemory {
+		services.Stores = memoryStores()
+		services.Limiter, services.Quota = ratelimit.NewMemoryLimiter(), ratelimit.NewMemoryQuota()
+		services.Snapshots = snapshots.NewMemoryStore()
+	} else {
+		dynamo := dynamodb.NewFromConfig(awsCfg)
+		if cfg.RateLimitTable != "" {
+			services.Limiter = ratelimit.NewDynamoLimiter(dynamo, cfg.RateLimitTable, cfg.RateLimitShards)
+			services.Quota = ratelimit.NewDynamoQuota(dynamo, cfg.RateLimitTable)
+		}
+		if cfg.ConfigSnapshotTable != "" {
+			services.Snapshots = snapshots.NewDynamoStore(dynamo, cfg.ConfigSnapshotTable)
+		}
+	}
+	if cfg.EmailSecretName != "" {
+		services.Email = &email.ResendClient{
+			// The key is read through the secret cache for each email,
+			// so a rotation is picked up once the cached copy expires.
+			APIKeyFunc: func(ctx context.Context) (string, error) {
+				creds, err := helper.RetrieveEmailCreds(ctx, secrets, cfg.EmailSecretName)
+				return creds.APIKey, err
+			},
+			Endpoint:   email.ResendEndpoint,
+			HTTPClient: newHTTPClient(cfg, faults.TargetEmail),
+			Retry:      cfg.Retry,
+		}
+	}
+	return services
+}
+
+func postgresStores(cfg *config.Config, rdsClient postgres.RDSDataAPI) func(string) postgres.Store {
+	return func(tablePrefix string) postgres.Store {
+		return postgres.NewPostgresDB(rdsClient, cfg, tablePrefix)
+	}
+}
+
+// memoryStores returns a Stores that keeps one memory.Store per table
+// prefix, for as long as the returned func is in use.
+func memoryStores() func(tablePrefix string) postgres.Store {
+	var mu sync.Mutex
+	stores := map[string]*memory.Store{}
+	return func(tablePrefix string) postgres.Store {
+		mu.Lock()
+		defer mu.Unlock()
+		if stores[tablePrefix] == nil {
+			stores[tablePrefix] = memory.New()
+		}
+		return stores[tablePrefix]
+	}
+}
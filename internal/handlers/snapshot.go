@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/snapshots"
+)
+
+// RecordConfigSnapshot logs the sanitized effective configuration and
+// flags any keys that changed since the snapshot stored by the previous
+// cold start. Without a Snapshots store the snapshot is only logged.
+// Failures are logged only; a missing snapshot must never block signups.
+func (s *Services) RecordConfigSnapshot(ctx context.Context) {
+	recordConfigSnapshot(ctx, s.Config, s.Snapshots)
+}
+
+func recordConfigSnapshot(ctx context.Context, cfg *config.Config, store snapshots.Store) {
+	current := cfg.Snapshot()
+	logging.FromContext(ctx).WithField("config", current).Info("Effective configuration snapshot")
+	if store == nil {
+		return
+	}
+
+	previous, err := store.Latest(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Skipping configuration drift check")
+		return
+	}
+
+	if previous != nil {
+		drift := config.DiffSnapshots(previous, current)
+		if len(drift) == 0 {
+			logging.FromContext(ctx).Info("Configuration unchanged since previous snapshot")
+			return
+		}
+		logging.FromContext(ctx).WithField("changed_keys", drift).Warn("Configuration drift detected since previous snapshot")
+	}
+
+	if err := store.Put(ctx, current); err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to persist configuration snapshot")
+	}
+}
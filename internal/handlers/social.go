@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/oidc"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// appPasswordName labels the app password handed out to social signups in
+// the account's app password list.
+const appPasswordName = "ShareFrame sign-in"
+
+// generatedPasswordAttempts bounds the draws needed for a generated
+// password to meet the password rules; one almost always does.
+const generatedPasswordAttempts = 10
+
+// applySocialIdentity checks a social signup's identity token and fills in
+// what a password signup would have supplied: the email address the
+// provider verified, in place of any the client sent, and a random
+// password nobody is shown.
+func (h *UserHandler) applySocialIdentity(ctx context.Context, cfg *config.Config, event models.UserRequest) (models.UserRequest, error) {
+	identity, err := h.identityVerifier(cfg).Verify(ctx, event.IdentityProvider, event.IDToken)
+	if apperr.CategoryOf(err) == apperr.Upstream {
+		return event, err
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("provider", event.IdentityProvider).Warn("Identity token rejected")
+		return event, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidIDToken, "identity token rejected: %v", err).ForField(validate.FieldIDToken))
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return event, fmt.Errorf("internal error: could not generate password: %w", err)
+	}
+	logging.RegisterSecrets(password)
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"provider": identity.Provider,
+		"email":    identity.Email,
+	}).Info("Identity token verified")
+
+	event.Email = identity.Email
+	event.Password, event.PasswordConfirm = password, ""
+	return event, nil
+}
+
+func (h *UserHandler) identityVerifier(cfg *config.Config) *oidc.Verifier {
+	h.identityOnce.Do(func() {
+		h.identity = oidc.NewVerifier([]oidc.Provider{
+			oidc.Google(cfg.GoogleClientIDs),
+			oidc.Apple(cfg.AppleClientIDs),
+		}, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.Retry)
+	})
+	return h.identity
+}
+
+// createAppPassword gives a social signup an app password for clients that
+// sign in over the AT Protocol, or returns empty.
+func (h *UserHandler) createAppPassword(ctx context.Context, cfg *config.Config, tenant config.Tenant, user models.CreateUserResponse) string {
+	password, err := h.pds.client(cfg, tenant).CreateAppPassword(ctx, user.AccessJWT, appPasswordName)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Social signup created without an app password")
+		return ""
+	}
+	return password
+}
+
+// generatePassword returns 192 random bits as four dash-separated groups,
+// redrawn in the rare case they lack a digit or a letter case the password
+// rules require.
+func generatePassword() (string, error) {
+	var b [24]byte
+	for i := 0; i < generatedPasswordAttempts; i++ {
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		encoded := base64.RawURLEncoding.EncodeToString(b[:])
+		password := strings.Join([]string{encoded[:8], encoded[8:16], encoded[16:24], encoded[24:]}, "-")
+		if validate.Password(password) == nil {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("no generated password met the password rules")
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// statsPeriods are the periods the signup stats are kept for.
+var statsPeriods = []string{models.StatsPeriodHour, models.StatsPeriodDay}
+
+// defaultStatsCounts is how many periods the stats admin action returns
+// when not asked for a number: a day of hours or a week of days.
+var defaultStatsCounts = map[string]int{models.StatsPeriodHour: 24, models.StatsPeriodDay: 7}
+
+// maxStatsPeriods bounds the periods returned in one request.
+const maxStatsPeriods = 744
+
+// StatsHandler keeps the signup stats up to date. Subscribed to the account
+// events topic, it recomputes the hour and day of each account.created
+// event. An EventBridge schedule invokes it with an event that has no
+// records, and it recomputes the last SIGNUP_STATS_WINDOW of every tenant,
+// which is what brings later verifications into a period's conversion.
+type StatsHandler struct {
+	*Services
+	now func() time.Time
+}
+
+func NewStatsHandler(services *Services) *StatsHandler {
+	return &StatsHandler{Services: services, now: time.Now}
+}
+
+// statsWindow is a span of one tenant's signups to recompute the stats of.
+type statsWindow struct {
+	tenant   config.Tenant
+	from, to time.Time
+}
+
+func (h *StatsHandler) Handle(ctx context.Context, event events.SNSEvent) (models.SignupStatsResult, error) {
+	ctx = logging.NewRequestContext(ctx, "signup_stats")
+	recorder := metrics.New()
+	defer recorder.Flush()
+	ctx = metrics.WithMetrics(ctx, recorder)
+
+	cfg := h.Config
+	if !cfg.SignupStats {
+		logging.FromContext(ctx).Debug("Signup stats not enabled, nothing to aggregate")
+		return models.SignupStatsResult{}, nil
+	}
+
+	return aggregateSignupStats(ctx, h.statsWindows(ctx, cfg, event), func(tablePrefix string) postgres.SignupStatsStore {
+		return h.Stores(tablePrefix)
+	})
+}
+
+// statsWindows returns what event asks to recompute: the whole stats window
+// of each tenant for a scheduled run, or the hour of each new account.
+func (h *StatsHandler) statsWindows(ctx context.Context, cfg *config.Config, event events.SNSEvent) []statsWindow {
+	now := h.now().UTC()
+	if len(event.Records) == 0 {
+		ids := make([]string, 0, len(cfg.Tenants))
+		for id := range cfg.Tenants {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		windows := make([]statsWindow, 0, len(ids))
+		for _, id := range ids {
+			windows = append(windows, statsWindow{tenant: cfg.Tenants[id], from: now.Add(-cfg.SignupStatsWindow), to: now})
+		}
+		return windows
+	}
+
+	type hour struct {
+		tenant string
+		start  time.Time
+	}
+	seen := map[hour]bool{}
+	var windows []statsWindow
+	for _, record := range event.Records {
+		var accountEvent models.AccountEvent
+		if err := json.Unmarshal([]byte(record.SNS.Message), &accountEvent); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("message_id", record.SNS.MessageID).Warn("Skipping account event that can't be read")
+			continue
+		}
+		if accountEvent.Event != models.EventAccountCreated {
+			continue
+		}
+		tenant, err := cfg.ResolveTenant(accountEvent.Tenant)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("tenant", accountEvent.Tenant).Warn("Skipping account event for an unknown tenant")
+			continue
+		}
+		at := accountEvent.OccurredAt
+		if at.IsZero() {
+			at = now
+		}
+		at = at.UTC().Truncate(time.Hour)
+
+		if !seen[hour{tenant.ID, at}] {
+			seen[hour{tenant.ID, at}] = true
+			windows = append(windows, statsWindow{tenant: tenant, from: at, to: at})
+		}
+	}
+	return windows
+}
+
+// aggregateSignupStats recomputes the hourly and daily stats of each
+// window. A failure is returned, so the invoker retries the whole run;
+// recomputing a period twice is harmless.
+func aggregateSignupStats(ctx context.Context, windows []statsWindow, stores func(tablePrefix string) postgres.SignupStatsStore) (models.SignupStatsResult, error) {
+	var result models.SignupStatsResult
+	for _, window := range windows {
+		ctx := logging.WithTenant(ctx, window.tenant.ID)
+		store := stores(window.tenant.TablePrefix)
+		for _, period := range statsPeriods {
+			periods, err := store.AggregateSignupStats(ctx, period, window.from, window.to)
+			if err != nil {
+				return result, fmt.Errorf("failed to aggregate %s signup stats for tenant %s: %w", period, window.tenant.ID, err)
+			}
+			result.Periods += periods
+		}
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"windows": len(windows),
+		"periods": result.Periods,
+	}).Info("Aggregated signup stats")
+	return result, nil
+}
+
+// listSignupStats returns the last count periods of the stats, the current
+// one included. The period defaults to a day.
+func listSignupStats(ctx context.Context, cfg *config.Config, store postgres.SignupStatsStore, period string, count int, now time.Time) ([]models.SignupStats, error) {
+	if !cfg.SignupStats {
+		return nil, apperr.Errorf(apperr.NotFound, "not found: signup stats are not enabled")
+	}
+	if period == "" {
+		period = models.StatsPeriodDay
+	}
+	length := postgres.StatsPeriodLength(period)
+	if length == 0 {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: period must be %s or %s", models.StatsPeriodHour, models.StatsPeriodDay)
+	}
+	if count == 0 {
+		count = defaultStatsCounts[period]
+	}
+	if count < 0 || count > maxStatsPeriods {
+		return nil, apperr.Errorf(apperr.Validation, "validation error: count must be between 1 and %d", maxStatsPeriods)
+	}
+
+	since := now.UTC().Truncate(length).Add(-time.Duration(count-1) * length)
+	stats, err := store.ListSignupStats(ctx, period, since)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: failed to read signup stats: %w", err)
+	}
+	return stats, nil
+}
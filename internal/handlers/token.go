@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/helper"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/token"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// tokensEnabled reports whether a token key is configured, so signup
+// responses carry a signed token and erasures can be confirmed.
+func tokensEnabled(cfg *config.Config) bool {
+	return cfg.TokenKMSKeyID != "" || cfg.ResponseTokenSecretName != ""
+}
+
+// tokenKey prefers the asymmetric KMS key, which never leaves KMS and
+// lets other services verify tokens with just the public key, over the
+// shared HMAC secret.
+func tokenKey(ctx context.Context, cfg *config.Config, awsCfg aws.Config, secretsClient config.SecretsManagerAPI) (token.Key, error) {
+	if cfg.TokenKMSKeyID != "" {
+		return token.NewKMS(kms.NewFromConfig(awsCfg), cfg.TokenKMSKeyID, cfg.TokenKMSPreviousKeyIDs...), nil
+	}
+
+	key, err := helper.RetrieveSigningKey(ctx, secretsClient, cfg.ResponseTokenSecretName)
+	if err != nil {
+		return nil, err
+	}
+	return token.NewHMAC(key.KeyID, []byte(key.Secret)), nil
+}
+
+// signupToken signs the token attesting a finished signup, or returns
+// empty, in which case callers fall back to querying.
+func (h *UserHandler) signupToken(ctx context.Context, cfg *config.Config, awsCfg aws.Config, tenant config.Tenant, user models.CreateUserResponse, verified bool) string {
+	key, err := tokenKey(ctx, cfg, awsCfg, h.Secrets)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Signup response sent without a token")
+		return ""
+	}
+
+	signed, err := token.Encode(ctx, key, token.SignupClaims{
+		Registered: token.NewRegistered(token.PurposeSignup, user.DID, time.Now(), cfg.ResponseTokenTTL),
+		Handle:     user.Handle,
+		Tenant:     tenant.ID,
+		Verified:   verified,
+	})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Signup response sent without a token")
+		return ""
+	}
+	return signed
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/api"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// VersionedUserRequest is a signup request in any API version, as direct
+// invokes and queued signups send it. Its version field picks the schema
+// the rest of the payload is read with, and a payload without one is a v1
+// request. A v2 request is read into its v1 form; see
+// api.CreateUserRequestV2.
+type VersionedUserRequest struct {
+	models.UserRequest
+}
+
+func (r *VersionedUserRequest) UnmarshalJSON(data []byte) error {
+	req, err := decodeUserRequest(data, "")
+	if err != nil {
+		return err
+	}
+	r.UserRequest = req
+	return nil
+}
+
+// HandleVersioned creates an account from a request in any API version.
+func (h *UserHandler) HandleVersioned(ctx context.Context, event VersionedUserRequest) (*models.CreateUserResponse, error) {
+	if err := checkVersion(event.Version, ""); err != nil {
+		return nil, err
+	}
+	return h.Handle(ctx, event.UserRequest)
+}
+
+// decodeUserRequest reads a signup request in the schema of its version
+// field, or of version when it has none.
+func decodeUserRequest(data []byte, version string) (models.UserRequest, error) {
+	var envelope struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return models.UserRequest{}, err
+	}
+	if envelope.Version == "" {
+		envelope.Version = version
+	}
+
+	if envelope.Version == api.Version2 {
+		var req api.CreateUserRequestV2
+		if err := json.Unmarshal(data, &req); err != nil {
+			return models.UserRequest{}, err
+		}
+		return req.V1(), nil
+	}
+	var req models.UserRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return models.UserRequest{}, err
+	}
+	req.Version = envelope.Version
+	return req, nil
+}
+
+// checkVersion rejects a request in a version the service doesn't serve,
+// or, when served is set, in any version but served.
+func checkVersion(version, served string) error {
+	switch {
+	case served != "" && version != served:
+		return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidRequest,
+			"%s request sent to a %s path", version, served).ForField(validate.FieldVersion).With("supported", []string{served}))
+	case version != "" && !slices.Contains(api.Versions, version):
+		return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidRequest,
+			"unknown API version %s", version).ForField(validate.FieldVersion).With("supported", api.Versions))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/email"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+)
+
+// errEmailNotConfigured is returned for tenants that send no email.
+var errEmailNotConfigured = errors.New("email is not configured for the tenant")
+
+// sendWelcomeEmail renders the tenant's welcome template and delivers it,
+// returning when it was sent. The account already exists at this point, so
+// failures are logged rather than returned to the caller. When the provider
+// can't take the email it is queued for the pending email sender, the
+// account is marked as waiting for its verification email and nil is
+// returned, as it is when the email isn't sent at all.
+func (h *UserHandler) sendWelcomeEmail(ctx context.Context, cfg *config.Config, tenant config.Tenant, s3Client email.S3API, limiter ratelimit.Limiter, queue postgres.PendingEmailStore, users postgres.VerificationEmailTracker, user models.CreateUserResponse, recipient string) *time.Time {
+	queued, err := deliverWelcomeEmail(ctx, h.Email, cfg, tenant, s3Client, limiter, queue, users, user, recipient)
+	if errors.Is(err, errEmailNotConfigured) {
+		logging.FromContext(ctx).WithField("tenant", tenant.ID).Debug("Email not configured for tenant, skipping welcome email")
+		return nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to send welcome email")
+		return nil
+	}
+	if queued {
+		return nil
+	}
+	sentAt := time.Now().UTC()
+	return &sentAt
+}
+
+// deliverWelcomeEmail sends the welcome email, queueing it when the
+// provider can't take it, and reports whether it was queued.
+func deliverWelcomeEmail(ctx context.Context, provider email.Sender, cfg *config.Config, tenant config.Tenant, s3Client email.S3API, limiter ratelimit.Limiter, queue postgres.PendingEmailStore, users postgres.VerificationEmailTracker, user models.CreateUserResponse, recipient string) (bool, error) {
+	if tenant.EmailFrom == "" || provider == nil {
+		return false, errEmailNotConfigured
+	}
+
+	source := tenant.EmailTemplateSource
+	if source == "" {
+		source = cfg.EmailTemplateSource
+	}
+
+	tmpl, err := email.LoadTemplate(ctx, source, s3Client)
+	if err != nil {
+		return false, fmt.Errorf("failed to load welcome email template: %w", err)
+	}
+
+	subject, body, err := tmpl.Render(email.TemplateData{Handle: user.Handle, DID: user.DID, Tenant: tenant.ID})
+	if err != nil {
+		return false, fmt.Errorf("failed to render welcome email: %w", err)
+	}
+
+	pending := models.PendingEmail{
+		Tenant:    tenant.ID,
+		DID:       user.DID,
+		From:      tenant.EmailFrom,
+		Recipient: recipient,
+		Subject:   subject,
+		HTML:      body,
+	}
+
+	sender := limitEmails(cfg, limiter, provider)
+	if err := sender.Send(ctx, pendingMessage(pending)); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", user.DID).Error("Failed to send welcome email; queueing it")
+		queueEmail(ctx, queue, users, pending)
+		return true, nil
+	}
+	return false, nil
+}
+
+// queueEmail keeps email for the pending email sender. Failures are only
+// logged: the account exists and the user can ask for the email again.
+func queueEmail(ctx context.Context, queue postgres.PendingEmailStore, users postgres.VerificationEmailTracker, pending models.PendingEmail) {
+	if err := queue.QueueEmail(ctx, pending); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", pending.DID).Error("Welcome email lost")
+		return
+	}
+	if err := users.SetVerificationEmailPending(ctx, pending.DID, true); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", pending.DID).Warn("Account not marked as waiting for its verification email")
+	}
+}
+
+func pendingMessage(pending models.PendingEmail) email.Message {
+	return email.Message{
+		From:    pending.From,
+		To:      []string{pending.Recipient},
+		Subject: pending.Subject,
+		HTML:    pending.HTML,
+	}
+}
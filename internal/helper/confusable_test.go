@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateConfusableHandles(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		handle       string
+		similar      bool
+		exact        bool
+		expectedCode string
+	}{
+		{"Resembles Reserved Handle", "sharefrarne", false, false, validate.CodeConfusableHandle},
+		{"Digit Substitution", "supp0rt", false, false, validate.CodeConfusableHandle},
+		{"Resembles Existing Handle", "a1ice", true, false, validate.CodeConfusableHandle},
+		{"Exact Existing Handle Left To PDS", "alice", true, true, ""},
+		{"Unrelated Handle", "sunnydays", false, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := new(mockPostgresClient)
+			user := models.UserRequest{Handle: test.handle, Email: "user@example.com", Password: "Valid@123"}
+			handle := test.handle + PDS_Suffix
+			mockDB.On("HandleSkeletonExists", ctx, handle).Return(test.similar, nil).Maybe()
+			mockDB.On("CheckHandleExists", ctx, handle).Return(test.exact, nil).Maybe()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil).Maybe()
+
+			_, err := ValidateAndFormatUser(ctx, user, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+			if test.expectedCode != "" {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,165 +2,110 @@ package helper
 
 import (
 	"context"
-	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
-	"regexp"
-	"strings"
 
 	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/logging"
 	"github.com/ShareFrame/user-management/internal/models"
 	"github.com/ShareFrame/user-management/internal/postgres"
-	"github.com/sirupsen/logrus"
+	"github.com/ShareFrame/user-management/pkg/validate"
 )
 
-//go:embed blocked_usernames.json
-var blockedUsernamesData []byte
-var blockedUsernames []string
-
 const (
-	PDS_Suffix     = ".shareframe.social"
-	PasswordError  = "password must be at least 8 characters long and include at least one uppercase letter, one lowercase letter, one digit, and one special character"
-	MissingFields  = "handle, email, and password are required fields"
-	EmailTaken     = "email is already registered"
-	InvalidHandle  = "handle can only include letters and numbers"
-	BlockedHandle  = "handle is not allowed"
-	HandleTooShort = "handle must be at least 3 characters long"
-	HandleTooLong  = "handle cannot exceed 18 characters"
-	QueryTimeout   = 3
-)
-
-var (
-	emailRegex       = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	handleRegex      = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
-	upperCaseRegex   = regexp.MustCompile(`[A-Z]`)
-	lowerCaseRegex   = regexp.MustCompile(`[a-z]`)
-	digitRegex       = regexp.MustCompile(`\d`)
-	specialCharRegex = regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{}|;:'",.<>?/\\]`)
+	PDS_Suffix    = config.DefaultHandleSuffix
+	MissingFields = "handle, email, and password are required fields"
+	EmailTaken    = "email is already registered"
+	BlockedHandle = "handle is not allowed"
+
+	PasswordBreached = "password has appeared in a known data breach; choose a different one"
+	PasswordMismatch = "password confirmation does not match"
+	ConfusableHandle = "handle is too similar to an existing handle"
+	CaptchaRequired  = "complete the captcha to finish signing up"
+	DomainThrottled  = "too many accounts were created with this email domain recently; try again later"
+
+	SignupQuotaReached = "today's signups are used up; try again tomorrow"
+	DomainQuotaReached = "today's signups with this email domain are used up; try again tomorrow"
 )
 
-func init() {
-	if err := json.Unmarshal(blockedUsernamesData, &blockedUsernames); err != nil {
-		logrus.Fatalf("Failed to parse blocked usernames JSON: %v", err)
-	}
-}
-
-func ValidateAndFormatUser(ctx context.Context, event models.UserRequest, dbClient postgres.PostgresDBService) (models.UserRequest, error) {
-	if event.Handle == "" || event.Email == "" || event.Password == "" {
-		logrus.Warn("Validation failed: missing required fields")
-		return models.UserRequest{}, fmt.Errorf("%v", MissingFields)
-	}
-
-	baseHandle := strings.TrimSuffix(event.Handle, PDS_Suffix)
-
-	if err := ValidateHandle(baseHandle); err != nil {
-		logrus.WithField("handle", baseHandle).Warnf("Validation failed: %v", err)
-		return models.UserRequest{}, err
-	}
-	event.Handle = EnsureHandleSuffix(baseHandle)
-
-	if err := ValidateEmail(event.Email); err != nil {
-		logrus.WithField("email", event.Email).Warnf("Validation failed: %v", err)
-		return models.UserRequest{}, err
-	}
-
-	if err := ValidatePassword(event.Password); err != nil {
-		logrus.WithError(err).Warn("Validation failed: invalid password")
-		return models.UserRequest{}, fmt.Errorf("password validation failed: %w", err)
-	}
-
-	exists, err := dbClient.CheckEmailExists(ctx, event.Email)
-	if err != nil {
-		logrus.WithError(err).Error("Database error: failed to check email existence")
-		return models.UserRequest{}, fmt.Errorf("internal error: failed to check email")
-	}
-	if exists {
-		logrus.WithField("email", event.Email).Warn("Validation failed: email already taken")
-		return models.UserRequest{}, fmt.Errorf("%v", EmailTaken)
-	}
-
-	logrus.Info("User request validated successfully")
-	return event, nil
+// ValidateAndFormatUser runs the default rules for opts. Callers that need
+// to change the rule set should build a Validator instead.
+func ValidateAndFormatUser(ctx context.Context, event models.UserRequest, dbClient postgres.PostgresDBService, opts ValidationOptions) (ValidationResult, error) {
+	return NewValidator(dbClient, opts).Validate(ctx, event)
 }
 
-
+// ValidateHandle checks the handle format and rejects reserved handles that
+// nobody may claim.
 func ValidateHandle(handle string) error {
-	if len(handle) < 3 {
-		return fmt.Errorf("handle must be at least 3 characters long: %v", handle)
-	}
-	if len(handle) > 18 {
-		return fmt.Errorf("handle cannot exceed 18 characters: %v", handle)
+	if err := validate.Handle(handle); err != nil {
+		return err
 	}
-	for _, blocked := range blockedUsernames {
-		if strings.EqualFold(handle, blocked) {
-			return fmt.Errorf("provided handle is not allowed: %v", BlockedHandle)
-		}
-	}
-	if !handleRegex.MatchString(handle) {
-		return fmt.Errorf("provided handle is invalid: %v", InvalidHandle)	
+	if status, _ := CheckReservation(handle); status == ReservationBlocked {
+		return validate.NewError(validate.CodeBlockedHandle, "provided handle is not allowed: %v", BlockedHandle)
 	}
 	return nil
 }
 
-func EnsureHandleSuffix(handle string) string {
-    if strings.HasSuffix(handle, PDS_Suffix) {
-        return handle
-    }
-    return handle + PDS_Suffix
-}
-
-
-func ValidateEmail(email string) error {
-	if !emailRegex.MatchString(email) {
-		return fmt.Errorf("invalid email format")
-	}
-	return nil
-}
-
-func ValidatePassword(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf(PasswordError)
-	}
-
-	if !upperCaseRegex.MatchString(password) ||
-		!lowerCaseRegex.MatchString(password) ||
-		!digitRegex.MatchString(password) ||
-		!specialCharRegex.MatchString(password) {
-		return fmt.Errorf(PasswordError)
+func appendFlag(flags []string, flag string) []string {
+	for _, existing := range flags {
+		if existing == flag {
+			return flags
+		}
 	}
-
-	return nil
+	return append(flags, flag)
 }
 
-func retrieveCredentials[T any](ctx context.Context, secretEnvVar string, secretsManagerClient config.SecretsManagerAPI) (T, error) {
+func retrieveCredentials[T any](ctx context.Context, secretName string, secretsManagerClient config.SecretsManagerAPI) (T, error) {
 	var creds T
-	secretName := os.Getenv(secretEnvVar)
 
 	input, err := config.RetrieveSecret(ctx, secretName, secretsManagerClient)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"secret_name": secretName,
 		}).WithError(err).Error("Failed to retrieve credentials from Secrets Manager")
 		return creds, fmt.Errorf("error retrieving credentials from Secrets Manager (%s): %w", secretName, err)
 	}
 
 	if err := json.Unmarshal([]byte(input), &creds); err != nil {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"secret_name":  secretName,
 			"secret_value": input,
 		}).WithError(err).Error("Failed to unmarshal credentials")
 		return creds, fmt.Errorf("invalid credentials format: %w", err)
 	}
 
-	logrus.WithField("credential_type", fmt.Sprintf("%T", creds)).Info("Successfully retrieved credentials")
+	if holder, ok := any(creds).(models.SecretHolder); ok {
+		logging.RegisterSecrets(holder.Secrets()...)
+	}
+
+	logging.FromContext(ctx).WithField("credential_type", fmt.Sprintf("%T", creds)).Info("Successfully retrieved credentials")
 	return creds, nil
 }
 
-func RetrieveAdminCredentials(ctx context.Context, secretsManagerClient config.SecretsManagerAPI) (models.AdminCreds, error) {
-	return retrieveCredentials[models.AdminCreds](ctx, "PDS_ADMIN_SECRET_NAME", secretsManagerClient)
+func RetrieveAdminCredentials(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.AdminCreds, error) {
+	return retrieveCredentials[models.AdminCreds](ctx, secretName, secretsManagerClient)
+}
+
+func RetrieveUtilAccountCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.UtilACcountCreds, error) {
+	return retrieveCredentials[models.UtilACcountCreds](ctx, secretName, secretsManagerClient)
+}
+
+func RetrieveEmailCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.EmailCreds, error) {
+	return retrieveCredentials[models.EmailCreds](ctx, secretName, secretsManagerClient)
+}
+
+func RetrieveStripeCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.StripeCreds, error) {
+	return retrieveCredentials[models.StripeCreds](ctx, secretName, secretsManagerClient)
+}
+
+func RetrieveCRMCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.CRMCreds, error) {
+	return retrieveCredentials[models.CRMCreds](ctx, secretName, secretsManagerClient)
+}
+
+func RetrieveAnalyticsCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.AnalyticsCreds, error) {
+	return retrieveCredentials[models.AnalyticsCreds](ctx, secretName, secretsManagerClient)
 }
 
-func RetrieveUtilAccountCreds(ctx context.Context, secretsManagerClient config.SecretsManagerAPI) (models.UtilACcountCreds, error) {
-	return retrieveCredentials[models.UtilACcountCreds](ctx, "PDS_UTIL_ACCOUNT_CREDS", secretsManagerClient)
+func RetrieveSigningKey(ctx context.Context, secretsManagerClient config.SecretsManagerAPI, secretName string) (models.SigningKey, error) {
+	return retrieveCredentials[models.SigningKey](ctx, secretName, secretsManagerClient)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ShareFrame/user-management/internal/models"
 	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,13 +18,31 @@ type mockPostgresClient struct {
 
 var _ postgres.PostgresDBService = (*mockPostgresClient)(nil)
 
+// newMockPostgresClient returns a mock where no stored handle resembles the
+// one being validated, which is what most tests want.
+func newMockPostgresClient() *mockPostgresClient {
+	m := new(mockPostgresClient)
+	m.On("HandleSkeletonExists", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	return m
+}
+
 func (m *mockPostgresClient) CheckEmailExists(ctx context.Context, email string) (bool, error) {
 	args := m.Called(ctx, email)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *mockPostgresClient) StoreUser(ctx context.Context, user models.CreateUserResponse, event models.UserRequest) error {
-	args := m.Called(ctx, user, event)
+func (m *mockPostgresClient) CheckHandleExists(ctx context.Context, handle string) (bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPostgresClient) HandleSkeletonExists(ctx context.Context, handle string) (bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPostgresClient) StoreUser(ctx context.Context, record models.UserRecord) error {
+	args := m.Called(ctx, record)
 	return args.Error(0)
 }
 
@@ -40,51 +59,6 @@ func (m *mockSecretsManagerClient) GetSecretValue(ctx context.Context,
 	return nil, args.Error(1)
 }
 
-func TestValidatePassword(t *testing.T) {
-	tests := []struct {
-		name        string
-		password    string
-		expectedErr string
-	}{
-		{"Valid Password", "Strong@123", ""},
-		{"Too Short", "Short1!", PasswordError},
-		{"No Uppercase", "weakpassword1!", PasswordError},
-		{"No Lowercase", "WEAKPASSWORD1!", PasswordError},
-		{"No Digit", "NoDigits!!", PasswordError},
-		{"No Special Character", "NoSpecial1", PasswordError},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			err := ValidatePassword(test.password)
-			if test.expectedErr != "" {
-				assert.Error(t, err)
-				assert.Equal(t, test.expectedErr, err.Error())
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
-func TestEnsureHandleSuffix(t *testing.T) {
-	tests := []struct {
-		name     string
-		handle   string
-		expected string
-	}{
-		{"Already Has Suffix", "username.shareframe.social", "username.shareframe.social"},
-		{"Missing Suffix", "username", "username.shareframe.social"},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			result := EnsureHandleSuffix(test.handle)
-			assert.Equal(t, test.expected, result)
-		})
-	}
-}
-
 func TestValidateHandle(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -92,9 +66,10 @@ func TestValidateHandle(t *testing.T) {
 		expectedErr string
 	}{
 		{"Valid Handle", "validuser", ""},
-		{"Too Short", "ab", HandleTooShort},
-		{"Too Long", "thisisaverylonghandle", HandleTooLong},
-		{"Contains Special Characters", "invalid@handle", InvalidHandle},
+		{"Too Short", "ab", validate.HandleTooShort},
+		{"Too Long", "thisisaverylonghandle", validate.HandleTooLong},
+		{"Contains Special Characters", "invalid@handle", validate.InvalidHandle},
+		{"Blocked Handle", "admin", BlockedHandle},
 	}
 
 	for _, test := range tests {
@@ -110,31 +85,6 @@ func TestValidateHandle(t *testing.T) {
 	}
 }
 
-func TestValidateEmail(t *testing.T) {
-	tests := []struct {
-		name        string
-		email       string
-		expectedErr string
-	}{
-		{"Valid Email", "user@example.com", ""},
-		{"Missing @", "userexample.com", "invalid email format"},
-		{"Missing domain", "user@", "invalid email format"},
-		{"Invalid TLD", "user@example.c", "invalid email format"},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			err := ValidateEmail(test.email)
-			if test.expectedErr != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), test.expectedErr)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
 func TestValidateAndFormatUser(t *testing.T) {
 	ctx := context.Background()
 
@@ -150,22 +100,22 @@ func TestValidateAndFormatUser(t *testing.T) {
 		{"Missing Handle", models.UserRequest{Handle: "", Email: "user@example.com", Password: "Valid@123"}, false, nil, false, MissingFields},
 		{"Missing Email", models.UserRequest{Handle: "validuser", Email: "", Password: "Valid@123"}, false, nil, false, MissingFields},
 		{"Missing Password", models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: ""}, false, nil, false, MissingFields},
-		{"Invalid Handle", models.UserRequest{Handle: "inv@lid", Email: "user@example.com", Password: "Valid@123"}, false, nil, false, InvalidHandle},
+		{"Invalid Handle", models.UserRequest{Handle: "inv@lid", Email: "user@example.com", Password: "Valid@123"}, false, nil, false, validate.InvalidHandle},
 		{"Invalid Email", models.UserRequest{Handle: "validuser", Email: "invalid-email", Password: "Valid@123"}, false, nil, false, "invalid email format"},
-		{"Invalid Password", models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "weak"}, false, nil, false, PasswordError},
+		{"Invalid Password", models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "weak"}, false, nil, false, validate.PasswordError},
 		{"Email Already Exists", models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123"}, true, nil, true, EmailTaken},
 		{"DB Check Failure", models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123"}, false, assert.AnError, true, "internal error: failed to check email"},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			mockDB := new(mockPostgresClient)
+			mockDB := newMockPostgresClient()
 
 			if test.expectCheckEmail {
 				mockDB.On("CheckEmailExists", ctx, test.user.Email).Return(test.mockEmailExists, test.mockEmailErr)
 			}
 
-			_, err := ValidateAndFormatUser(ctx, test.user, mockDB)
+			_, err := ValidateAndFormatUser(ctx, test.user, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
 
 			if test.expectedErr != "" {
 				assert.Error(t, err)
@@ -194,7 +144,7 @@ func TestRetrieveAdminCredentials(t *testing.T) {
 		SecretString: &mockSecretValue,
 	}, nil)
 
-	creds, err := RetrieveAdminCredentials(ctx, mockSecretsManager)
+	creds, err := RetrieveAdminCredentials(ctx, mockSecretsManager, "admin-secret")
 	assert.NoError(t, err)
 	assert.Equal(t, "jwtsecret", creds.PDSJWTSecret)
 	assert.Equal(t, "admin", creds.PDSAdminUsername)
@@ -210,9 +160,40 @@ func TestRetrieveUtilAccountCreds(t *testing.T) {
 		SecretString: &mockSecretValue,
 	}, nil)
 
-	creds, err := RetrieveUtilAccountCreds(ctx, mockSecretsManager)
+	creds, err := RetrieveUtilAccountCreds(ctx, mockSecretsManager, "util-secret")
 	assert.NoError(t, err)
 	assert.Equal(t, "util-user", creds.Username)
 	assert.Equal(t, "util-pass", creds.Password)
 	assert.Equal(t, "did:example:123", creds.DID)
 }
+
+func TestRetrieveSigningKey(t *testing.T) {
+	mockSecretsManager := new(mockSecretsManagerClient)
+	ctx := context.Background()
+
+	mockSecretValue := `{"kid":"2026-10","secret":"signing-secret"}`
+	mockSecretsManager.On("GetSecretValue", ctx, mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+		SecretString: &mockSecretValue,
+	}, nil)
+
+	key, err := RetrieveSigningKey(ctx, mockSecretsManager, "token-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10", key.KeyID)
+	assert.Equal(t, "signing-secret", key.Secret)
+}
+
+func TestValidateAndFormatUserCanonicalizesEmail(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+
+	result, err := ValidateAndFormatUser(ctx, models.UserRequest{
+		Handle:   "validuser",
+		Email:    " User <user@EXAMPLE.com> ",
+		Password: "Valid@123",
+	}, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", result.User.Email)
+	mockDB.AssertExpectations(t)
+}
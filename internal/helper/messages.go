@@ -0,0 +1,92 @@
+package helper
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+const DefaultLocale = "en"
+
+//go:embed messages/*.json
+var messageFiles embed.FS
+
+// catalog maps a lowercase locale to code -> message.
+var catalog = map[string]map[string]string{}
+
+func init() {
+	entries, err := messageFiles.ReadDir("messages")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read message catalog: %v", err))
+	}
+
+	for _, entry := range entries {
+		data, err := messageFiles.ReadFile(path.Join("messages", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("Failed to read message catalog %s: %v", entry.Name(), err))
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("Failed to parse message catalog %s: %v", entry.Name(), err))
+		}
+		catalog[strings.ToLower(strings.TrimSuffix(entry.Name(), ".json"))] = messages
+	}
+}
+
+// Message returns the message for code in locale, falling back from a
+// regional locale ("pt-BR") to its language ("pt") and then to English.
+// It returns "" for unknown codes.
+func Message(code, locale string) string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	language, _, _ := strings.Cut(locale, "-")
+
+	for _, candidate := range []string{locale, language, DefaultLocale} {
+		if message, ok := catalog[candidate][code]; ok {
+			return message
+		}
+	}
+	return ""
+}
+
+// LocalizedError is one entry in an API error response.
+type LocalizedError = api.FieldError
+
+// LocalizeErrors translates every coded failure in err, expanding
+// validate.ValidationErrors into one entry per failure.
+func LocalizeErrors(err error, locale string) []LocalizedError {
+	var all validate.ValidationErrors
+	if !errors.As(err, &all) {
+		all = validate.ValidationErrors{err}
+	}
+
+	var localized []LocalizedError
+	for _, e := range all {
+		code, message := LocalizeError(e, locale)
+		if code == "" {
+			continue
+		}
+		entry := LocalizedError{Code: code, Message: message}
+		if verr, ok := validate.AsValidationError(e); ok {
+			entry.Field, entry.Params = verr.Field, verr.Params
+		}
+		localized = append(localized, entry)
+	}
+	return localized
+}
+
+// LocalizeError returns the code and translated message for a validation
+// error. Errors without a code are returned with empty values.
+func LocalizeError(err error, locale string) (string, string) {
+	code := validate.ErrorCode(err)
+	if code == "" {
+		return "", ""
+	}
+	return code, Message(code, locale)
+}
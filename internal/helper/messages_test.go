@@ -0,0 +1,77 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCatalogsAreComplete(t *testing.T) {
+	for locale, messages := range catalog {
+		for code := range catalog[DefaultLocale] {
+			assert.NotEmpty(t, messages[code], "locale %s is missing %s", locale, code)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		locale   string
+		expected string
+	}{
+		{"English", validate.CodeEmailTaken, "en", "This email address is already registered."},
+		{"Spanish", validate.CodeEmailTaken, "es", "Esta dirección de correo ya está registrada."},
+		{"Regional Falls Back To Language", validate.CodeEmailTaken, "pt-BR", "Este endereço de e-mail já está cadastrado."},
+		{"Underscore Locale", validate.CodeEmailTaken, "fr_CA", "Cette adresse e-mail est déjà enregistrée."},
+		{"Unknown Locale Falls Back To English", validate.CodeEmailTaken, "xx", "This email address is already registered."},
+		{"Empty Locale", validate.CodeEmailTaken, "", "This email address is already registered."},
+		{"Unknown Code", "no_such_code", "es", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Message(test.code, test.locale))
+		})
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "ab", Email: "user@example.com", Password: "Valid@123"}, newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix})
+	code, message := LocalizeError(fmt.Errorf("validation error: %w", err), "de")
+	assert.Equal(t, validate.CodeHandleTooShort, code)
+	assert.Equal(t, "Der Handle muss mindestens 3 Zeichen lang sein.", message)
+
+	code, message = LocalizeError(validate.PasswordStrength("Password1!", 3, nil), "en")
+	assert.Equal(t, validate.CodePasswordTooWeak, code)
+	assert.Equal(t, "This password is too easy to guess.", message)
+
+	code, message = LocalizeError(fmt.Errorf("internal error: boom"), "es")
+	assert.Empty(t, code)
+	assert.Empty(t, message)
+}
+
+func TestLocalizeErrorsCarriesFieldAndParams(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "ab", Email: "bad", Password: "Valid@123"}, newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix})
+	localized := LocalizeErrors(err, "en")
+
+	assert.Equal(t, []LocalizedError{
+		{Field: FieldHandle, Code: validate.CodeHandleTooShort, Message: Message(validate.CodeHandleTooShort, "en"), Params: map[string]interface{}{"min": validate.MinHandleLength}},
+		{Field: FieldEmail, Code: validate.CodeInvalidEmail, Message: Message(validate.CodeInvalidEmail, "en")},
+	}, localized)
+
+	_, err = ValidateAndFormatUser(ctx, models.UserRequest{Handle: "validuser"}, newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix})
+	localized = LocalizeErrors(err, "en")
+	assert.Len(t, localized, 1)
+	assert.Empty(t, localized[0].Field)
+	assert.Equal(t, []string{FieldEmail, FieldPassword}, localized[0].Params["fields"])
+}
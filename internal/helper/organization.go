@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// Organization rules. RuleAccountType checks the account type and the size
+// of the owners list, RuleOrganizationEmail keeps organizations off shared
+// mailbox providers and RuleOrganizationOwners looks the owners up.
+const (
+	RuleAccountType        = "account_type"
+	RuleOrganizationEmail  = "organization_email"
+	RuleOrganizationOwners = "organization_owners"
+)
+
+// MaxOrganizationOwners bounds the owners list of an organization.
+const MaxOrganizationOwners = 10
+
+// OwnerResolver finds the accounts named as an organization's owners.
+type OwnerResolver interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	FindUserDID(ctx context.Context, identifier string) (string, error)
+}
+
+func isOrganization(req models.UserRequest) bool {
+	return req.AccountType == models.AccountTypeOrganization
+}
+
+// checkAccountType accepts an empty type as a person. Organizations need
+// one to MaxOrganizationOwners owners and are only accepted where owners
+// can be looked up.
+func (v *Validator) checkAccountType(ctx context.Context, s *Submission) error {
+	switch s.Request.AccountType {
+	case "", models.AccountTypePerson:
+		if len(s.Request.Owners) > 0 {
+			return validate.NewError(validate.CodeInvalidAccountType, "only organization accounts have owners").
+				ForField(FieldOwners)
+		}
+		return nil
+	case models.AccountTypeOrganization:
+	default:
+		return validate.NewError(validate.CodeInvalidAccountType, "unknown account type: %q", s.Request.AccountType)
+	}
+
+	if v.opts.Owners == nil {
+		return validate.NewError(validate.CodeInvalidAccountType, "organization accounts can't be created here")
+	}
+	if len(s.Request.Owners) == 0 {
+		return validate.NewError(validate.CodeOwnersRequired, "organization accounts need at least one owner").
+			ForField(FieldOwners)
+	}
+	if len(s.Request.Owners) > MaxOrganizationOwners {
+		return validate.NewError(validate.CodeTooManyOwners, "organization accounts can have at most %d owners", MaxOrganizationOwners).
+			ForField(FieldOwners).With("max", MaxOrganizationOwners)
+	}
+	return nil
+}
+
+// checkOrganizationEmail requires an organization to sign up with an
+// address on its own domain rather than a shared or disposable mailbox
+// provider. Confirming the address, as every account does, then proves the
+// organization controls the domain.
+func (v *Validator) checkOrganizationEmail(ctx context.Context, s *Submission) error {
+	if !isOrganization(s.Request) {
+		return nil
+	}
+	domain := risk.EmailDomain(s.Request.Email)
+	if risk.IsCommonProvider(domain) || risk.IsDisposable(domain) {
+		return validate.NewError(validate.CodeOrganizationEmail, "organization accounts need an email address on their own domain, not %s", domain).
+			With("domain", domain)
+	}
+	return nil
+}
+
+// checkOrganizationOwners replaces each owner with the DID of their account,
+// dropping repeats. Owners must be people whose email address is verified.
+func (v *Validator) checkOrganizationOwners(ctx context.Context, s *Submission) error {
+	if !isOrganization(s.Request) {
+		return nil
+	}
+
+	dids := make([]string, 0, len(s.Request.Owners))
+	for _, identifier := range s.Request.Owners {
+		owner, err := v.findOwner(ctx, strings.TrimSpace(identifier))
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			return unknownOwner(identifier)
+		}
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Database error: failed to look up organization owner")
+			return validate.NewError(validate.CodeInternal, "internal error: failed to check owners")
+		}
+		if owner.AccountType == models.AccountTypeOrganization || !owner.Verified ||
+			(owner.Status != models.StatusVerified && owner.Status != models.StatusActive) {
+			return unknownOwner(identifier)
+		}
+		if !containsString(dids, owner.DID) {
+			dids = append(dids, owner.DID)
+		}
+	}
+	s.Request.Owners = dids
+	return nil
+}
+
+func (v *Validator) findOwner(ctx context.Context, identifier string) (models.UserRecord, error) {
+	did := identifier
+	if !strings.HasPrefix(identifier, "did:") {
+		var err error
+		if did, err = v.opts.Owners.FindUserDID(ctx, identifier); err != nil {
+			return models.UserRecord{}, err
+		}
+	}
+	return v.opts.Owners.GetUser(ctx, did)
+}
+
+func unknownOwner(identifier string) error {
+	return validate.NewError(validate.CodeUnknownOwner, "owner %s has no verified personal account", identifier).
+		With("owner", identifier)
+}
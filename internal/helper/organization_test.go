@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/memory"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func ownerStore(t *testing.T) *memory.Store {
+	store := memory.New()
+	for _, record := range []models.UserRecord{
+		{DID: "did:plc:alice", Handle: "alice" + PDS_Suffix, Email: "alice@example.com", Status: models.StatusActive, Verified: true, AccountType: models.AccountTypePerson},
+		{DID: "did:plc:bob", Handle: "bob" + PDS_Suffix, Email: "bob@example.com", Status: models.StatusPending},
+		{DID: "did:plc:other", Handle: "other" + PDS_Suffix, Email: "team@other.example", Status: models.StatusActive, Verified: true, AccountType: models.AccountTypeOrganization},
+	} {
+		assert.NoError(t, store.StoreUser(context.Background(), record))
+	}
+	return store
+}
+
+func TestValidatorOrganization(t *testing.T) {
+	ctx := context.Background()
+	org := models.UserRequest{
+		Handle:      "acmecommunityfoundation",
+		Email:       "team@acme.example",
+		Password:    "Valid@123",
+		DisplayName: "Acme Community Foundation",
+		AccountType: models.AccountTypeOrganization,
+		Owners:      []string{"did:plc:alice"},
+	}
+	longHandle := strings.Repeat("a", validate.MaxOrganizationHandleLength+1)
+	tooMany := make([]string, MaxOrganizationOwners+1)
+	for i := range tooMany {
+		tooMany[i] = "did:plc:alice"
+	}
+	with := func(change func(req *models.UserRequest)) models.UserRequest {
+		req := org
+		change(&req)
+		return req
+	}
+
+	tests := []struct {
+		name           string
+		request        models.UserRequest
+		expectedCode   string
+		expectedOwners []string
+	}{
+		{name: "Organization", request: org, expectedOwners: []string{"did:plc:alice"}},
+		{
+			name:           "Owners By Handle And Email",
+			request:        with(func(req *models.UserRequest) { req.Owners = []string{"alice" + PDS_Suffix, "Alice@Example.com"} }),
+			expectedOwners: []string{"did:plc:alice"},
+		},
+		{name: "Unknown Type", request: with(func(req *models.UserRequest) { req.AccountType = "company" }), expectedCode: validate.CodeInvalidAccountType},
+		{
+			name:         "Person With Owners",
+			request:      with(func(req *models.UserRequest) { req.AccountType = models.AccountTypePerson }),
+			expectedCode: validate.CodeInvalidAccountType,
+		},
+		{name: "No Owners", request: with(func(req *models.UserRequest) { req.Owners = nil }), expectedCode: validate.CodeOwnersRequired},
+		{name: "Too Many Owners", request: with(func(req *models.UserRequest) { req.Owners = tooMany }), expectedCode: validate.CodeTooManyOwners},
+		{name: "Unknown Owner", request: with(func(req *models.UserRequest) { req.Owners = []string{"did:plc:nobody"} }), expectedCode: validate.CodeUnknownOwner},
+		{name: "Unverified Owner", request: with(func(req *models.UserRequest) { req.Owners = []string{"bob" + PDS_Suffix} }), expectedCode: validate.CodeUnknownOwner},
+		{name: "Organization Owner", request: with(func(req *models.UserRequest) { req.Owners = []string{"did:plc:other"} }), expectedCode: validate.CodeUnknownOwner},
+		{name: "Shared Mailbox", request: with(func(req *models.UserRequest) { req.Email = "acme@gmail.com" }), expectedCode: validate.CodeOrganizationEmail},
+		{name: "No Display Name", request: with(func(req *models.UserRequest) { req.DisplayName = "" }), expectedCode: validate.CodeDisplayNameRequired},
+		{name: "Handle Too Long", request: with(func(req *models.UserRequest) { req.Handle = longHandle }), expectedCode: validate.CodeHandleTooLong},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil).Maybe()
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, Owners: ownerStore(t)})
+			result, err := v.Validate(ctx, test.request)
+
+			if test.expectedCode != "" {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOwners, result.User.Owners)
+		})
+	}
+}
+
+func TestValidatorOrganizationNotOffered(t *testing.T) {
+	ctx := context.Background()
+	v := NewValidator(newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix})
+
+	_, err := v.Validate(ctx, models.UserRequest{
+		Handle:      "acme",
+		Email:       "team@acme.example",
+		Password:    "Valid@123",
+		DisplayName: "Acme",
+		AccountType: models.AccountTypeOrganization,
+		Owners:      []string{"did:plc:alice"},
+	})
+
+	assert.Equal(t, validate.CodeInvalidAccountType, validate.ErrorCode(err))
+}
@@ -0,0 +1,83 @@
+package helper
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//go:embed profanity_words.json
+var profanityWordsData []byte
+var profanityWords []string
+
+const (
+	ProfaneHandle      = "handle contains inappropriate language"
+	ProfaneDisplayName = "display name contains inappropriate language"
+
+	// FlagProfanity marks a request that passed validation but should be
+	// reviewed because the profanity filter is in flag mode.
+	FlagProfanity = "profanity"
+)
+
+// leetspeak maps common character substitutions back to the letter they
+// stand in for before matching against the word list.
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+}
+
+func init() {
+	if err := json.Unmarshal(profanityWordsData, &profanityWords); err != nil {
+		panic(fmt.Sprintf("Failed to parse profanity words JSON: %v", err))
+	}
+}
+
+// normalizeForProfanity lowercases value, undoes leetspeak and drops
+// separators so "F.u_c-k" and "fuuuck" both reduce to a matchable form.
+// It returns the plain and the repeat-collapsed variants.
+func normalizeForProfanity(value string) (string, string) {
+	var plain strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if mapped, ok := leetspeak[r]; ok {
+			r = mapped
+		}
+		if unicode.IsLetter(r) {
+			plain.WriteRune(r)
+		}
+	}
+
+	var collapsed strings.Builder
+	var last rune
+	for _, r := range plain.String() {
+		if r != last {
+			collapsed.WriteRune(r)
+		}
+		last = r
+	}
+
+	return plain.String(), collapsed.String()
+}
+
+// ContainsProfanity reports whether value contains a word from the
+// profanity list once leetspeak and separators are normalized away.
+func ContainsProfanity(value string) bool {
+	plain, collapsed := normalizeForProfanity(value)
+	for _, word := range profanityWords {
+		if strings.Contains(plain, word) || strings.Contains(collapsed, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainsProfanity(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{"Clean Handle", "sunnydays", false},
+		{"Plain Word", "shithead", true},
+		{"Mixed Case", "BullShit", true},
+		{"Leetspeak", "5h1tp0st", true},
+		{"Separators", "f.u_c-k", true},
+		{"Repeated Letters", "fuuuuck", true},
+		{"Innocent Substring", "classic", false},
+		{"Peacock", "peacock", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ContainsProfanity(test.value))
+		})
+	}
+}
+
+func TestValidateAndFormatUserProfanity(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		user          models.UserRequest
+		mode          string
+		expectedErr   string
+		expectedFlags []string
+	}{
+		{
+			name:        "Reject Profane Handle",
+			user:        models.UserRequest{Handle: "shithead", Email: "user@example.com", Password: "Valid@123"},
+			mode:        config.ProfanityReject,
+			expectedErr: ProfaneHandle,
+		},
+		{
+			name:        "Reject Profane Display Name",
+			user:        models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", DisplayName: "B1tch"},
+			mode:        config.ProfanityReject,
+			expectedErr: ProfaneDisplayName,
+		},
+		{
+			name:          "Flag Instead Of Reject",
+			user:          models.UserRequest{Handle: "shithead", Email: "user@example.com", Password: "Valid@123", DisplayName: "sh1t"},
+			mode:          config.ProfanityFlag,
+			expectedFlags: []string{FlagProfanity},
+		},
+		{
+			name: "Clean Request Has No Flags",
+			user: models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", DisplayName: "Valid User"},
+			mode: config.ProfanityFlag,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			if test.expectedErr == "" {
+				mockDB.On("CheckEmailExists", ctx, test.user.Email).Return(false, nil)
+			}
+
+			result, err := ValidateAndFormatUser(ctx, test.user, mockDB, ValidationOptions{
+				HandleSuffix:  PDS_Suffix,
+				ProfanityMode: test.mode,
+			})
+
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedFlags, result.Flags)
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// Keys of the signup quota counters. A domain's key ends in the domain.
+const (
+	quotaKeySignups = "signup_quota"
+	quotaKeyDomain  = "signup_quota_domain:"
+)
+
+// SignupQuota enforces the daily signup quotas in Limits on the counters in
+// Quota.
+type SignupQuota struct {
+	Quota  ratelimit.Quota
+	Limits config.SignupQuota
+}
+
+// domainLimit returns the daily cap for domain, or 0 when it has none.
+// Like the domain throttle, large mailbox providers are only capped by an
+// override.
+func (q *SignupQuota) domainLimit(domain string) int {
+	if limit, ok := q.Limits.DomainOverrides[domain]; ok {
+		return limit
+	}
+	if risk.IsCommonProvider(domain) {
+		return 0
+	}
+	return q.Limits.DomainDaily
+}
+
+// Take uses one signup of today's quotas for email, the domain's before the
+// global one, and fails with CodeRateLimited once either is used up. A
+// signup the global quota refuses has still used its domain's share, and a
+// signup that fails later keeps its share too. A counter that can't be
+// updated lets the signup through, as the domain throttle does.
+func (q *SignupQuota) Take(ctx context.Context, email string) error {
+	domain := risk.EmailDomain(email)
+	if limit := q.domainLimit(domain); domain != "" && limit > 0 {
+		if err := q.take(ctx, quotaKeyDomain+domain, limit, DomainQuotaReached); err != nil {
+			return err.ForField(validate.FieldEmail)
+		}
+	}
+	if q.Limits.Daily > 0 {
+		if err := q.take(ctx, quotaKeySignups, q.Limits.Daily, SignupQuotaReached); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *SignupQuota) take(ctx context.Context, key string, limit int, message string) *validate.ValidationError {
+	usage, err := q.Quota.Take(ctx, key, limit)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("quota", key).Warn("Skipping signup quota")
+		return nil
+	}
+	if usage.Allowed {
+		return nil
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"quota": key,
+		"limit": limit,
+	}).Warn("Signup quota used up")
+	return validate.NewError(validate.CodeRateLimited, "%v", message).
+		With("retryAfter", int(time.Until(usage.ResetsAt).Seconds()))
+}
+
+// Usage reports today's use of the global quota and, when domain is set, of
+// the domain's, given with or without its "@".
+func (q *SignupQuota) Usage(ctx context.Context, domain string) (models.SignupQuotaUsage, error) {
+	var report models.SignupQuotaUsage
+	if q.Limits.Daily > 0 {
+		count, resetsAt, err := q.count(ctx, quotaKeySignups, q.Limits.Daily)
+		if err != nil {
+			return models.SignupQuotaUsage{}, err
+		}
+		report.Global, report.ResetsAt = count, resetsAt
+	}
+	domain = strings.ToLower(strings.TrimPrefix(domain, "@"))
+	if domain == "" {
+		return report, nil
+	}
+
+	report.Domain = &models.QuotaCount{Domain: domain}
+	if limit := q.domainLimit(domain); limit > 0 {
+		count, resetsAt, err := q.count(ctx, quotaKeyDomain+domain, limit)
+		if err != nil {
+			return models.SignupQuotaUsage{}, err
+		}
+		count.Domain = domain
+		report.Domain, report.ResetsAt = count, resetsAt
+	}
+	return report, nil
+}
+
+func (q *SignupQuota) count(ctx context.Context, key string, limit int) (*models.QuotaCount, time.Time, error) {
+	usage, err := q.Quota.Used(ctx, key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return &models.QuotaCount{Limit: limit, Used: usage.Used, Remaining: max(limit-usage.Used, 0)}, usage.ResetsAt, nil
+}
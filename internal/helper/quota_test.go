@@ -0,0 +1,123 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockQuota struct {
+	mock.Mock
+}
+
+func (m *mockQuota) Take(ctx context.Context, key string, max int) (ratelimit.Usage, error) {
+	args := m.Called(ctx, key, max)
+	return args.Get(0).(ratelimit.Usage), args.Error(1)
+}
+
+func (m *mockQuota) Used(ctx context.Context, key string) (ratelimit.Usage, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(ratelimit.Usage), args.Error(1)
+}
+
+func TestSignupQuotaTake(t *testing.T) {
+	ctx := context.Background()
+	limits := config.SignupQuota{
+		Daily:           3,
+		DomainDaily:     2,
+		DomainOverrides: map[string]int{"example.edu": 3, "partner.example": 0},
+	}
+
+	tests := []struct {
+		name          string
+		emails        []string
+		expectedCode  string
+		expectedField string
+	}{
+		{name: "Under Quotas", emails: []string{"a@bots.example", "b@bots.example"}},
+		{name: "Domain Quota Used Up", emails: []string{"a@bots.example", "b@bots.example", "c@bots.example"}, expectedCode: validate.CodeRateLimited, expectedField: FieldEmail},
+		{name: "Override Raises Domain Quota", emails: []string{"a@example.edu", "b@example.edu", "c@example.edu"}},
+		{name: "Override Exempts Domain", emails: []string{"a@partner.example", "b@partner.example", "c@partner.example", "d@partner.example"}, expectedCode: validate.CodeRateLimited},
+		{name: "Common Provider Exempt From Domain Quota", emails: []string{"a@gmail.com", "b@gmail.com", "c@gmail.com"}},
+		{name: "Global Quota Used Up", emails: []string{"a@gmail.com", "b@bots.example", "c@example.edu", "d@gmail.com"}, expectedCode: validate.CodeRateLimited},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quota := &SignupQuota{Quota: ratelimit.NewMemoryQuota(), Limits: limits}
+
+			var err error
+			for _, email := range test.emails {
+				err = quota.Take(ctx, email)
+			}
+
+			if test.expectedCode != "" {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, test.expectedField, verr.Field)
+				assert.Greater(t, verr.Params["retryAfter"], 0)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSignupQuotaTakeFailsOpen(t *testing.T) {
+	ctx := context.Background()
+	quota := new(mockQuota)
+	quota.On("Take", ctx, mock.Anything, mock.Anything).Return(ratelimit.Usage{}, errors.New("DynamoDB unavailable"))
+
+	err := (&SignupQuota{Quota: quota, Limits: config.SignupQuota{Daily: 1, DomainDaily: 1}}).Take(ctx, "user@bots.example")
+
+	assert.NoError(t, err)
+	quota.AssertNumberOfCalls(t, "Take", 2)
+}
+
+func TestSignupQuotaUsage(t *testing.T) {
+	ctx := context.Background()
+	quota := &SignupQuota{
+		Quota:  ratelimit.NewMemoryQuota(),
+		Limits: config.SignupQuota{Daily: 10, DomainDaily: 4},
+	}
+	for _, email := range []string{"a@bots.example", "b@bots.example", "c@gmail.com"} {
+		assert.NoError(t, quota.Take(ctx, email))
+	}
+
+	tests := []struct {
+		name                string
+		domain              string
+		expectDomain        bool
+		expectedDomainUsed  int
+		expectedDomainLimit int
+	}{
+		{name: "Global Only"},
+		{name: "Capped Domain", domain: "@Bots.Example", expectDomain: true, expectedDomainUsed: 2, expectedDomainLimit: 4},
+		{name: "Uncapped Domain", domain: "gmail.com", expectDomain: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usage, err := quota.Usage(ctx, test.domain)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, usage.Global.Used)
+			assert.Equal(t, 7, usage.Global.Remaining)
+			assert.False(t, usage.ResetsAt.IsZero())
+			if !test.expectDomain {
+				assert.Nil(t, usage.Domain)
+				return
+			}
+			assert.Equal(t, test.expectedDomainUsed, usage.Domain.Used)
+			assert.Equal(t, test.expectedDomainLimit, usage.Domain.Limit)
+			assert.Equal(t, test.expectedDomainLimit-test.expectedDomainUsed, usage.Domain.Remaining)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package helper
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/confusables"
+	"github.com/ShareFrame/user-management/internal/models"
+)
+
+//go:embed reserved_handles.json
+var reservedHandlesData []byte
+
+// reservedHandles maps a lowercased handle to its category name.
+var reservedHandles = map[string]string{}
+
+// reservedSkeletons maps the confusables skeleton of each reserved handle
+// back to the handle.
+var reservedSkeletons = map[string]string{}
+
+// protectedSkeletons maps the skeleton of each protected handle back to the
+// handle, for the similarity check.
+var protectedSkeletons = map[string]string{}
+var reservedCategories map[string]models.ReservedHandleCategory
+
+// ReservationStatus says whether a handle can be claimed freely.
+type ReservationStatus string
+
+const (
+	ReservationAvailable        ReservationStatus = "available"
+	ReservationBlocked          ReservationStatus = "blocked"
+	ReservationRequiresApproval ReservationStatus = "requires_approval"
+
+	PolicyBlock    = "block"
+	PolicyApproval = "approval"
+
+	// FlagReservedHandle marks a request for a handle that an admin has to
+	// release before the account is treated as active.
+	FlagReservedHandle = "reserved_handle"
+)
+
+// The lists are compiled in, so a broken one fails this package's tests
+// long before a deploy; init panics rather than exiting so the cause keeps
+// its stack trace.
+func init() {
+	if err := json.Unmarshal(reservedHandlesData, &reservedCategories); err != nil {
+		panic(fmt.Sprintf("Failed to parse reserved handles JSON: %v", err))
+	}
+
+	for name, category := range reservedCategories {
+		if category.Policy != PolicyBlock && category.Policy != PolicyApproval {
+			panic(fmt.Sprintf("Reserved handle category %s has unknown policy %q", name, category.Policy))
+		}
+		for _, handle := range category.Handles {
+			key := strings.ToLower(handle)
+			// A handle listed in several categories takes the strictest policy.
+			if existing, ok := reservedHandles[key]; ok && reservedCategories[existing].Policy == PolicyBlock {
+				continue
+			}
+			reservedHandles[key] = name
+			reservedSkeletons[confusables.Skeleton(key)] = key
+		}
+		for _, handle := range category.Protected {
+			key := strings.ToLower(handle)
+			if reservedHandles[key] == "" {
+				panic(fmt.Sprintf("Protected handle %s is not reserved in category %s", handle, name))
+			}
+			protectedSkeletons[confusables.Skeleton(key)] = key
+		}
+	}
+}
+
+// CheckReservation returns the reservation status of handle and the category
+// that reserved it, if any.
+func CheckReservation(handle string) (ReservationStatus, string) {
+	name, ok := reservedHandles[strings.ToLower(handle)]
+	if !ok {
+		return ReservationAvailable, ""
+	}
+
+	if reservedCategories[name].Policy == PolicyBlock {
+		return ReservationBlocked, name
+	}
+	return ReservationRequiresApproval, name
+}
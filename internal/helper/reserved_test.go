@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckReservation(t *testing.T) {
+	tests := []struct {
+		name             string
+		handle           string
+		expectedStatus   ReservationStatus
+		expectedCategory string
+	}{
+		{"Unreserved", "sunnydays", ReservationAvailable, ""},
+		{"System Route", "settings", ReservationBlocked, "system"},
+		{"Case Insensitive", "ADMIN", ReservationBlocked, "system"},
+		{"Offensive", "hitler", ReservationBlocked, "offensive"},
+		{"Staff", "support", ReservationRequiresApproval, "staff"},
+		{"Brand", "google", ReservationRequiresApproval, "brands"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, category := CheckReservation(test.handle)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedCategory, category)
+		})
+	}
+}
+
+func TestValidateAndFormatUserReservedHandles(t *testing.T) {
+	ctx := context.Background()
+
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckHandleExists", ctx, mock.Anything).Return(false, nil)
+	_, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "admin", Email: "user@example.com", Password: "Valid@123"}, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+	assert.ErrorContains(t, err, BlockedHandle)
+
+	mockDB.On("CheckEmailExists", ctx, "brand@example.com").Return(false, nil)
+	result, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "google", Email: "brand@example.com", Password: "Valid@123"}, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+	assert.NoError(t, err)
+	assert.Equal(t, ReservationRequiresApproval, result.Reservation)
+	assert.Equal(t, "brands", result.ReservedCategory)
+	assert.Equal(t, []string{FlagReservedHandle}, result.Flags)
+	mockDB.AssertExpectations(t)
+}
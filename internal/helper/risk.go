@@ -0,0 +1,74 @@
+package helper
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// FlagSignupRisk marks accounts created despite a high abuse score.
+const FlagSignupRisk = "signup_risk"
+
+// RuleSignupRisk scores the signup for abuse once everything else passed.
+const RuleSignupRisk = "signup_risk"
+
+// SignupVelocityChecker reports recent signup activity around a request.
+type SignupVelocityChecker interface {
+	SignupVelocity(ctx context.Context, ip, emailDomain string, window time.Duration) (risk.Velocity, error)
+}
+
+// RiskOptions configures abuse scoring. Signups scoring ReviewScore or more
+// are flagged for review; those scoring CaptchaScore or more must solve a
+// captcha, or are flagged when no Captcha verifier is configured.
+type RiskOptions struct {
+	Velocity     SignupVelocityChecker
+	Captcha      risk.CaptchaVerifier
+	Window       time.Duration
+	Limits       risk.Limits
+	ReviewScore  int
+	CaptchaScore int
+}
+
+// checkSignupRisk fails open: when the history or the captcha provider
+// can't be reached the signup is let through, flagged if it needed a
+// captcha, rather than blocking everyone.
+func (v *Validator) checkSignupRisk(ctx context.Context, s *Submission) error {
+	opts := v.opts.Risk
+	domain := risk.EmailDomain(s.Request.Email)
+
+	velocity, err := opts.Velocity.SignupVelocity(ctx, s.Request.ClientIP, domain, opts.Window)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Skipping signup risk scoring")
+		return nil
+	}
+
+	assessment := risk.Score(risk.Signals{EmailDomain: domain, Velocity: velocity}, opts.Limits)
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"risk_score":   assessment.Score,
+		"risk_reasons": assessment.Reasons,
+	})
+
+	if assessment.Score >= opts.CaptchaScore && opts.Captcha != nil {
+		solved, err := opts.Captcha.VerifyCaptcha(ctx, s.Request.CaptchaToken, s.Request.ClientIP)
+		if err != nil {
+			log.WithError(err).Error("Captcha verification failed; flagging signup for review")
+			s.Flag(FlagSignupRisk)
+			return nil
+		}
+		if !solved {
+			log.Info("High-risk signup needs a captcha")
+			return validate.NewError(validate.CodeCaptchaRequired, "%v", CaptchaRequired).ForField(validate.FieldCaptchaToken)
+		}
+		log.Info("High-risk signup solved the captcha")
+		return nil
+	}
+
+	if assessment.Score >= opts.ReviewScore {
+		log.Warn("High-risk signup flagged for review")
+		s.Flag(FlagSignupRisk)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockVelocity struct {
+	mock.Mock
+}
+
+func (m *mockVelocity) SignupVelocity(ctx context.Context, ip, emailDomain string, window time.Duration) (risk.Velocity, error) {
+	args := m.Called(ctx, ip, emailDomain, window)
+	return args.Get(0).(risk.Velocity), args.Error(1)
+}
+
+type mockCaptcha struct {
+	mock.Mock
+}
+
+func (m *mockCaptcha) VerifyCaptcha(ctx context.Context, token, remoteIP string) (bool, error) {
+	args := m.Called(ctx, token, remoteIP)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestValidatorSignupRisk(t *testing.T) {
+	ctx := context.Background()
+	const ip = "203.0.113.7"
+	quiet := risk.Velocity{IPAttempts: 1}
+	// 40 + 10: flagged, no captcha.
+	busy := risk.Velocity{IPAttempts: 8, IPFailures: 2}
+	// 40 + 40 + 30 with a disposable email: over the captcha threshold.
+	abusive := risk.Velocity{IPAttempts: 9, IPFailures: 6}
+
+	tests := []struct {
+		name          string
+		email         string
+		velocity      risk.Velocity
+		velocityErr   error
+		withCaptcha   bool
+		captchaToken  string
+		solved        bool
+		captchaErr    error
+		expectCaptcha bool
+		expectedCode  string
+		expectFlag    bool
+	}{
+		{name: "Low Risk", email: "user@example.com", velocity: quiet},
+		{name: "Flagged For Review", email: "user@example.com", velocity: busy, withCaptcha: true, expectFlag: true},
+		{name: "Captcha Required", email: "user@mailinator.com", velocity: abusive, withCaptcha: true, expectCaptcha: true, expectedCode: validate.CodeCaptchaRequired},
+		{name: "Captcha Solved", email: "user@mailinator.com", velocity: abusive, withCaptcha: true, captchaToken: "token", solved: true, expectCaptcha: true},
+		{name: "Captcha Provider Down", email: "user@mailinator.com", velocity: abusive, withCaptcha: true, captchaToken: "token", captchaErr: errors.New("timeout"), expectCaptcha: true, expectFlag: true},
+		{name: "No Captcha Configured", email: "user@mailinator.com", velocity: abusive, expectFlag: true},
+		{name: "History Unavailable", email: "user@mailinator.com", velocityErr: errors.New("DB connection failed")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil).Maybe()
+			velocity := new(mockVelocity)
+			velocity.On("SignupVelocity", ctx, ip, risk.EmailDomain(test.email), time.Hour).Return(test.velocity, test.velocityErr)
+
+			opts := &RiskOptions{Velocity: velocity, Window: time.Hour, Limits: risk.DefaultLimits, ReviewScore: 50, CaptchaScore: 80}
+			captcha := new(mockCaptcha)
+			if test.withCaptcha {
+				opts.Captcha = captcha
+			}
+			if test.expectCaptcha {
+				captcha.On("VerifyCaptcha", ctx, test.captchaToken, ip).Return(test.solved, test.captchaErr)
+			}
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, Risk: opts})
+			assert.Equal(t, RuleSignupRisk, ruleNames(v)[len(v.Rules)-1])
+
+			result, err := v.Validate(ctx, models.UserRequest{
+				Handle:       "validuser",
+				Email:        test.email,
+				Password:     "Valid@123",
+				ClientIP:     ip,
+				CaptchaToken: test.captchaToken,
+			})
+
+			if test.expectedCode != "" {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, FieldCaptchaToken, verr.Field)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectFlag, containsString(result.Flags, FlagSignupRisk))
+			}
+			velocity.AssertExpectations(t)
+			captcha.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package helper
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ShareFrame/user-management/internal/confusables"
+)
+
+const ProtectedHandle = "handle is too similar to a protected handle"
+
+// maxSimilarityDistance is how many edits away from a protected handle of
+// the given length a handle still counts as a typosquat. Short names get
+// less room so ordinary words near them stay available.
+func maxSimilarityDistance(length int) int {
+	switch {
+	case length >= 6:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ResemblesProtectedHandle returns the protected handle that handle is a
+// near-miss spelling of, comparing confusables skeletons so lookalike
+// characters count as the letter they imitate. Exact matches are left to
+// the reservation check.
+func ResemblesProtectedHandle(handle string) (string, bool) {
+	skeleton := confusables.Skeleton(strings.ToLower(handle))
+	for protectedSkeleton, protected := range protectedSkeletons {
+		if strings.EqualFold(handle, protected) {
+			continue
+		}
+		limit := maxSimilarityDistance(utf8.RuneCountInString(protectedSkeleton))
+		if limit > 0 && editDistance(skeleton, protectedSkeleton, limit) <= limit {
+			return protected, true
+		}
+	}
+	return "", false
+}
+
+// editDistance returns the Levenshtein distance between a and b, or limit+1
+// as soon as it is known to exceed limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
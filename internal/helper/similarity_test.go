@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		limit    int
+		expected int
+	}{
+		{"support", "support", 2, 0},
+		{"suport", "support", 2, 1},
+		{"supprot", "support", 2, 2},
+		{"kitten", "sitting", 5, 3},
+		{"kitten", "sitting", 2, 3},
+		{"a", "support", 2, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"/"+test.b, func(t *testing.T) {
+			assert.Equal(t, test.expected, editDistance(test.a, test.b, test.limit))
+		})
+	}
+}
+
+func TestResemblesProtectedHandle(t *testing.T) {
+	tests := []struct {
+		name              string
+		handle            string
+		expectedProtected string
+		expected          bool
+	}{
+		{"Missing Letter", "suport", "support", true},
+		{"Transposed Letters", "supprot", "support", true},
+		{"Doubled Letter", "adminn", "admin", true},
+		{"Brand Typo", "sharefame", "shareframe", true},
+		{"Lookalike And Typo", "m0derat0rr", "moderator", true},
+		{"Exact Match Left To Reservations", "support", "", false},
+		{"Lookalike Plural", "adrnins", "admin", true},
+		{"Unrelated", "sunnydays", "", false},
+		{"Two Edits From Short Name", "adrian", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			protected, ok := ResemblesProtectedHandle(test.handle)
+			assert.Equal(t, test.expected, ok)
+			assert.Equal(t, test.expectedProtected, protected)
+		})
+	}
+}
+
+func TestValidateAndFormatUserRejectsTyposquats(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "supp0rtt", Email: "user@example.com", Password: "Valid@123"},
+		newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix})
+
+	assert.Equal(t, validate.CodeConfusableHandle, validate.ErrorCode(err))
+	assert.ErrorContains(t, err, ProtectedHandle)
+}
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateAndFormatUserPasswordScore(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+
+	_, err := ValidateAndFormatUser(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Password1!"}, mockDB, ValidationOptions{
+		HandleSuffix:     PDS_Suffix,
+		MinPasswordScore: 3,
+	})
+
+	weak, ok := validate.AsValidationError(err)
+	assert.True(t, ok)
+	assert.Equal(t, validate.CodePasswordTooWeak, weak.Code)
+	assert.Equal(t, FieldPassword, weak.Field)
+	assert.Equal(t, 3, weak.Params["minScore"])
+	mockDB.AssertNotCalled(t, "CheckEmailExists", ctx, "user@example.com")
+}
+
+type mockBreachChecker struct {
+	mock.Mock
+}
+
+func (m *mockBreachChecker) Breached(ctx context.Context, password string) (bool, error) {
+	args := m.Called(ctx, password)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestValidateAndFormatUserBreachCheck(t *testing.T) {
+	ctx := context.Background()
+	user := models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123"}
+
+	tests := []struct {
+		name        string
+		breached    bool
+		checkErr    error
+		expectedErr string
+	}{
+		{"Breached Password Rejected", true, nil, PasswordBreached},
+		{"Clean Password Accepted", false, nil, ""},
+		{"Checker Failure Fails Open", false, errors.New("timeout"), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			checker := new(mockBreachChecker)
+			checker.On("Breached", ctx, user.Password).Return(test.breached, test.checkErr)
+			if test.expectedErr == "" {
+				mockDB.On("CheckEmailExists", ctx, user.Email).Return(false, nil)
+			}
+
+			_, err := ValidateAndFormatUser(ctx, user, mockDB, ValidationOptions{
+				HandleSuffix:  PDS_Suffix,
+				BreachChecker: checker,
+			})
+
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			checker.AssertExpectations(t)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+const (
+	MaxHandleSuggestions = 5
+	// maxSuggestionChecks bounds the storage and PDS lookups spent on one
+	// failed signup.
+	maxSuggestionChecks = 15
+	maxHandleLength     = 18
+)
+
+// HandleAvailability reports whether a fully-qualified handle is free.
+type HandleAvailability func(ctx context.Context, handle string) (bool, error)
+
+// ParamSuggestions is the validation error param holding fully-qualified
+// alternatives to a taken or blocked handle that were free at the time of
+// the check.
+const ParamSuggestions = "suggestions"
+
+// Suggestions returns the alternative handles carried by err, if any.
+func Suggestions(err error) []string {
+	verr, ok := validate.AsValidationError(err)
+	if !ok {
+		return nil
+	}
+	suggestions, _ := verr.Params[ParamSuggestions].([]string)
+	return suggestions
+}
+
+var handleAffixes = []struct{ prefix, suffix string }{
+	{"the", ""},
+	{"", "hq"},
+	{"its", ""},
+	{"real", ""},
+	{"", "app"},
+	{"hey", ""},
+}
+
+var handleSynonyms = map[string][]string{
+	"art":    {"arts", "studio", "design"},
+	"dev":    {"code", "builds", "hacks"},
+	"food":   {"eats", "kitchen", "bites"},
+	"game":   {"play", "gamer", "games"},
+	"music":  {"tunes", "beats", "sound"},
+	"news":   {"daily", "times", "updates"},
+	"photo":  {"pics", "snaps", "shots"},
+	"travel": {"trips", "journeys", "roam"},
+	"video":  {"clips", "films", "reels"},
+}
+
+// StorageAvailability checks handles against the users table.
+func StorageAvailability(dbClient postgres.PostgresDBService) HandleAvailability {
+	return func(ctx context.Context, handle string) (bool, error) {
+		exists, err := dbClient.CheckHandleExists(ctx, handle)
+		return !exists, err
+	}
+}
+
+// AllAvailable combines checks; a handle is available only if every check
+// says so.
+func AllAvailable(checks ...HandleAvailability) HandleAvailability {
+	return func(ctx context.Context, handle string) (bool, error) {
+		for _, check := range checks {
+			available, err := check(ctx, handle)
+			if err != nil || !available {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// SuggestHandles returns up to MaxHandleSuggestions fully-qualified
+// alternatives to base that pass validation and are reported available.
+func SuggestHandles(ctx context.Context, base, suffix string, available HandleAvailability) []string {
+	base = strings.ToLower(strings.TrimSuffix(base, suffix))
+
+	var suggestions []string
+	checks := 0
+	for _, candidate := range handleCandidates(base) {
+		if len(suggestions) == MaxHandleSuggestions || checks == maxSuggestionChecks {
+			break
+		}
+		if ValidateHandle(candidate) != nil || ContainsProfanity(candidate) {
+			continue
+		}
+		if status, _ := CheckReservation(candidate); status != ReservationAvailable {
+			continue
+		}
+
+		checks++
+		handle := validate.EnsureHandleSuffix(candidate, suffix)
+		ok, err := available(ctx, handle)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("handle", handle).Warn("Failed to check suggested handle")
+			continue
+		}
+		if ok {
+			suggestions = append(suggestions, handle)
+		}
+	}
+
+	return suggestions
+}
+
+func handleCandidates(base string) []string {
+	seen := map[string]bool{base: true}
+	var candidates []string
+	add := func(candidate string) {
+		if !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	words := make([]string, 0, len(handleSynonyms))
+	for word := range handleSynonyms {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	for _, word := range words {
+		if strings.Contains(base, word) {
+			for _, synonym := range handleSynonyms[word] {
+				add(strings.Replace(base, word, synonym, 1))
+			}
+		}
+	}
+
+	for _, affix := range handleAffixes {
+		room := maxHandleLength - len(affix.prefix) - len(affix.suffix)
+		add(affix.prefix + truncate(base, room) + affix.suffix)
+	}
+
+	for i := 0; i < 4; i++ {
+		digits := fmt.Sprintf("%d", 10+rand.Intn(990))
+		add(truncate(base, maxHandleLength-len(digits)) + digits)
+	}
+
+	return candidates
+}
+
+func truncate(value string, length int) string {
+	if length < 0 {
+		return ""
+	}
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestHandles(t *testing.T) {
+	ctx := context.Background()
+	taken := map[string]bool{"thephoto" + PDS_Suffix: true}
+
+	var checked []string
+	suggestions := SuggestHandles(ctx, "photo"+PDS_Suffix, PDS_Suffix, func(ctx context.Context, handle string) (bool, error) {
+		checked = append(checked, handle)
+		return !taken[handle], nil
+	})
+
+	assert.Len(t, suggestions, MaxHandleSuggestions)
+	assert.Equal(t, []string{"pics" + PDS_Suffix, "snaps" + PDS_Suffix, "shots" + PDS_Suffix, "photohq" + PDS_Suffix, "itsphoto" + PDS_Suffix}, suggestions)
+	assert.Contains(t, checked, "thephoto"+PDS_Suffix)
+	for _, suggestion := range suggestions {
+		assert.NoError(t, ValidateHandle(strings.TrimSuffix(suggestion, PDS_Suffix)))
+	}
+}
+
+func TestSuggestHandlesRespectsLengthAndErrors(t *testing.T) {
+	ctx := context.Background()
+	base := "abcdefghijklmnopqr"
+
+	suggestions := SuggestHandles(ctx, base, PDS_Suffix, func(ctx context.Context, handle string) (bool, error) {
+		if strings.HasPrefix(handle, "the") {
+			return false, errors.New("lookup failed")
+		}
+		return true, nil
+	})
+
+	assert.NotEmpty(t, suggestions)
+	for _, suggestion := range suggestions {
+		assert.False(t, strings.HasPrefix(suggestion, "the"))
+		assert.LessOrEqual(t, len(strings.TrimSuffix(suggestion, PDS_Suffix)), maxHandleLength)
+	}
+}
+
+func TestAllAvailable(t *testing.T) {
+	ctx := context.Background()
+	free := func(ctx context.Context, handle string) (bool, error) { return true, nil }
+	taken := func(ctx context.Context, handle string) (bool, error) { return false, nil }
+
+	available, err := AllAvailable(free, free)(ctx, "alice")
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	available, err = AllAvailable(free, taken)(ctx, "alice")
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+func TestSuggestions(t *testing.T) {
+	err := validate.NewError(validate.CodeBlockedHandle, "provided handle is not allowed")
+	assert.Empty(t, Suggestions(err))
+
+	err.With(ParamSuggestions, []string{"theadmin", "adminhq"})
+	assert.Equal(t, []string{"theadmin", "adminhq"}, Suggestions(fmt.Errorf("validation error: %w", err)))
+	assert.Equal(t, "provided handle is not allowed", err.Error())
+
+	assert.Empty(t, Suggestions(errors.New("internal error")))
+}
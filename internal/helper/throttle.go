@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// RuleDomainThrottle rejects signups from an email domain that has reached
+// its hourly limit.
+const RuleDomainThrottle = "domain_throttle"
+
+// DomainThrottleWindow is the period domain signup limits apply to.
+const DomainThrottleWindow = time.Hour
+
+// DomainSignupCounter counts recent successful signups with an email
+// domain.
+type DomainSignupCounter interface {
+	DomainSignups(ctx context.Context, emailDomain string, window time.Duration) (int, error)
+}
+
+// DomainThrottleOptions configures per-domain signup limits. With a
+// Limiter, signups that pass validation are counted there; otherwise
+// Counter counts the successful signups already recorded.
+type DomainThrottleOptions struct {
+	Counter  DomainSignupCounter
+	Limiter  ratelimit.Limiter
+	Throttle config.DomainThrottle
+}
+
+// limitFor returns the hourly limit for domain, or 0 when it isn't
+// throttled. Large mailbox providers are only throttled by an override,
+// since their volume says nothing about a single actor.
+func (o *DomainThrottleOptions) limitFor(domain string) int {
+	if limit, ok := o.Throttle.Overrides[domain]; ok {
+		return limit
+	}
+	if risk.IsCommonProvider(domain) {
+		return 0
+	}
+	return o.Throttle.Limit
+}
+
+// check reports the domain's recent signups and whether it is over limit.
+func (o *DomainThrottleOptions) check(ctx context.Context, domain string, limit int) (int, bool, error) {
+	if o.Limiter != nil {
+		decision, err := o.Limiter.Allow(ctx, "signup_domain:"+domain, ratelimit.Limit{Max: limit, Window: DomainThrottleWindow})
+		return decision.Count, !decision.Allowed, err
+	}
+	signups, err := o.Counter.DomainSignups(ctx, domain, DomainThrottleWindow)
+	return signups, signups >= limit, err
+}
+
+// checkDomainThrottle fails open when the count can't be read. Concurrent
+// signups can overshoot the limit slightly, which is fine for slowing a
+// bot wave down.
+func (v *Validator) checkDomainThrottle(ctx context.Context, s *Submission) error {
+	domain := risk.EmailDomain(s.Request.Email)
+	limit := v.opts.DomainThrottle.limitFor(domain)
+	if domain == "" || limit == 0 {
+		return nil
+	}
+
+	signups, throttled, err := v.opts.DomainThrottle.check(ctx, domain, limit)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Skipping email domain throttle")
+		return nil
+	}
+
+	if throttled {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"email_domain": domain,
+			"signups":      signups,
+			"limit":        limit,
+		}).Warn("Email domain throttled")
+		return validate.NewError(validate.CodeRateLimited, "%v", DomainThrottled).
+			ForField(validate.FieldEmail).
+			With("retryAfter", int(DomainThrottleWindow.Seconds()))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDomainCounter struct {
+	mock.Mock
+}
+
+func (m *mockDomainCounter) DomainSignups(ctx context.Context, emailDomain string, window time.Duration) (int, error) {
+	args := m.Called(ctx, emailDomain, window)
+	return args.Int(0), args.Error(1)
+}
+
+func TestValidatorDomainThrottle(t *testing.T) {
+	ctx := context.Background()
+	throttle := config.DomainThrottle{
+		Limit:     50,
+		Overrides: map[string]int{"example.edu": 500, "partner.example": 0},
+	}
+
+	tests := []struct {
+		name         string
+		email        string
+		signups      int
+		countErr     error
+		expectCount  bool
+		expectedCode string
+	}{
+		{name: "Under Limit", email: "user@bots.example", signups: 49, expectCount: true},
+		{name: "At Limit", email: "user@bots.example", signups: 50, expectCount: true, expectedCode: validate.CodeRateLimited},
+		{name: "Override Raises Limit", email: "user@example.edu", signups: 120, expectCount: true},
+		{name: "Override Exempts Domain", email: "user@partner.example"},
+		{name: "Common Provider Exempt", email: "user@gmail.com"},
+		{name: "Count Unavailable", email: "user@bots.example", countErr: errors.New("DB connection failed"), expectCount: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil)
+			counter := new(mockDomainCounter)
+			if test.expectCount {
+				domain := test.email[len("user@"):]
+				counter.On("DomainSignups", ctx, domain, DomainThrottleWindow).Return(test.signups, test.countErr)
+			}
+
+			v := NewValidator(mockDB, ValidationOptions{
+				HandleSuffix:   PDS_Suffix,
+				DomainThrottle: &DomainThrottleOptions{Counter: counter, Throttle: throttle},
+			})
+			assert.Contains(t, ruleNames(v), RuleDomainThrottle)
+
+			_, err := v.Validate(ctx, models.UserRequest{
+				Handle:   "validuser",
+				Email:    test.email,
+				Password: "Valid@123",
+			})
+
+			if test.expectedCode != "" {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, FieldEmail, verr.Field)
+				assert.Equal(t, 3600, verr.Params["retryAfter"])
+			} else {
+				assert.NoError(t, err)
+			}
+			counter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidatorDomainThrottleWithLimiter(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil)
+	counter := new(mockDomainCounter)
+
+	v := NewValidator(mockDB, ValidationOptions{
+		HandleSuffix: PDS_Suffix,
+		DomainThrottle: &DomainThrottleOptions{
+			Counter:  counter,
+			Limiter:  ratelimit.NewMemoryLimiter(),
+			Throttle: config.DomainThrottle{Limit: 2},
+		},
+	})
+	request := models.UserRequest{Handle: "validuser", Email: "user@bots.example", Password: "Valid@123"}
+
+	_, first := v.Validate(ctx, request)
+	_, second := v.Validate(ctx, request)
+	_, third := v.Validate(ctx, request)
+
+	assert.NoError(t, first)
+	assert.NoError(t, second)
+	assert.Equal(t, validate.CodeRateLimited, validate.ErrorCode(third))
+	counter.AssertNotCalled(t, "DomainSignups", mock.Anything, mock.Anything, mock.Anything)
+}
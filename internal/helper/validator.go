@@ -0,0 +1,827 @@
+package helper
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/confusables"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// Rule names for the built-in rules, in the order DefaultRules runs them.
+const (
+	RuleRequired         = "required"
+	RuleHandle           = "handle"
+	RuleBlocklist        = "blocklist"
+	RuleConfusable       = "confusable"
+	RuleSimilarity       = "protected_similarity"
+	RuleProfanity        = "profanity"
+	RuleDisplayName      = "display_name"
+	RuleBio              = "bio"
+	RulePronouns         = "pronouns"
+	RuleWebsite          = "website"
+	RuleLocation         = "location"
+	RuleEmail            = "email"
+	RuleLocale           = "locale"
+	RuleCountry          = "country"
+	RuleTimezone         = "timezone"
+	RuleConsent          = "consent"
+	RuleReferralSource   = "referral_source"
+	RulePassword         = "password"
+	RulePasswordConfirm  = "password_confirm"
+	RulePasswordStrength = "password_strength"
+	RulePasswordBreach   = "password_breach"
+	RuleEmailUnique      = "email_unique"
+	// RuleConfusableExisting compares against stored handles, so it runs
+	// with the remote rules.
+	RuleConfusableExisting = "confusable_existing"
+	// RuleRuntimeBlocklist checks the admin-managed blocklist in storage.
+	RuleRuntimeBlocklist = "runtime_blocklist"
+	// RuleDomainOwnership verifies custom-domain handles over DNS or HTTPS.
+	RuleDomainOwnership = "domain_ownership"
+	// RuleHandleClaim rejects handles someone else has claimed ahead of
+	// signing up.
+	RuleHandleClaim = "handle_claim"
+	// RuleHandleQuarantine rejects handles of recently deleted accounts.
+	RuleHandleQuarantine = "handle_quarantine"
+	// RuleInviteCode checks the format of a user-supplied invite code and
+	// RuleInviteCodeExists looks it up in the invites table.
+	RuleInviteCode       = "invite_code"
+	RuleInviteCodeExists = "invite_code_exists"
+)
+
+// ValidationOptions carries the per-tenant and per-deployment settings that
+// change how a signup request is validated.
+type ValidationOptions struct {
+	HandleSuffix        string
+	AllowUnicodeHandles bool
+	// ProfanityMode is config.ProfanityReject or config.ProfanityFlag.
+	ProfanityMode string
+	// MinPasswordScore is the lowest accepted zxcvbn score; 0 skips scoring.
+	MinPasswordScore int
+	// BreachChecker, when set, rejects passwords found in known breaches.
+	BreachChecker BreachChecker
+	// Blocklist, when set, rejects handles admins have blocked at runtime.
+	Blocklist BlocklistChecker
+	// DomainVerifier, when set, allows handles on the user's own domain
+	// once the domain is shown to point at the DID in the request.
+	DomainVerifier DomainVerifier
+	// InviteCodes, when set, makes an invite code required and checks it
+	// before the PDS is called.
+	InviteCodes InviteCodeChecker
+	// DomainThrottle, when set, limits signups per email domain per hour.
+	DomainThrottle *DomainThrottleOptions
+	// Risk, when set, scores signups for abuse after every other rule.
+	Risk *RiskOptions
+	// RequireProcessingConsent rejects signups that don't grant
+	// data processing consent.
+	RequireProcessingConsent bool
+	// HandleClaims, when set, keeps claimed handles for the email address
+	// that claimed them.
+	HandleClaims HandleClaimChecker
+	// HandleQuarantine, when set, keeps the handles of deleted accounts
+	// from being registered until they are released.
+	HandleQuarantine HandleQuarantineChecker
+	// Owners, when set, allows organization accounts and looks up their
+	// owners.
+	Owners OwnerResolver
+}
+
+// BreachChecker looks a password up in a corpus of breached passwords.
+type BreachChecker interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+// DomainVerifier reports whether a domain currently resolves to did.
+type DomainVerifier interface {
+	VerifyHandle(ctx context.Context, domain, did string) (bool, error)
+}
+
+// InviteCodeChecker reports whether an invite code can still be redeemed.
+type InviteCodeChecker interface {
+	InviteCodeAvailable(ctx context.Context, code string) (bool, error)
+}
+
+// HandleClaimChecker returns the unexpired claim on a handle, if any.
+type HandleClaimChecker interface {
+	ActiveHandleClaim(ctx context.Context, handle string) (models.HandleClaim, bool, error)
+}
+
+// HandleQuarantineChecker returns when a quarantined handle is released, if
+// it is still in quarantine.
+type HandleQuarantineChecker interface {
+	QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error)
+}
+
+// BlocklistChecker reports whether a handle is on the runtime blocklist.
+type BlocklistChecker interface {
+	IsHandleBlocked(ctx context.Context, handle string) (bool, error)
+}
+
+// ValidationResult is the normalized request plus any review flags raised
+// by checks that are configured to flag rather than reject.
+type ValidationResult struct {
+	User  models.UserRequest
+	Flags []string
+	// Reservation and ReservedCategory describe whether the handle is on the
+	// reserved list; blocked handles never reach a result.
+	Reservation      ReservationStatus
+	ReservedCategory string
+}
+
+// Submission is the request as it moves through the rules. Rules may
+// rewrite Request and record flags on Result.
+type Submission struct {
+	Request models.UserRequest
+	// BaseHandle is the handle without the tenant suffix, in the form
+	// registered with the PDS (punycode for internationalized handles).
+	BaseHandle string
+	// DisplayHandle is the handle as the user will see it.
+	DisplayHandle string
+	// CustomDomain is set when the handle is the user's own domain rather
+	// than a name under the tenant suffix.
+	CustomDomain bool
+	Result       ValidationResult
+}
+
+// Flag records a review flag once.
+func (s *Submission) Flag(flag string) {
+	s.Result.Flags = appendFlag(s.Result.Flags, flag)
+}
+
+// Request fields that rules report against. Once a rule for a field fails,
+// later rules for the same field are skipped since they'd only repeat it.
+const (
+	FieldHandle          = validate.FieldHandle
+	FieldDisplayName     = validate.FieldDisplayName
+	FieldBio             = validate.FieldBio
+	FieldPronouns        = validate.FieldPronouns
+	FieldWebsite         = validate.FieldWebsite
+	FieldLocation        = validate.FieldLocation
+	FieldEmail           = validate.FieldEmail
+	FieldPassword        = validate.FieldPassword
+	FieldPasswordConfirm = validate.FieldPasswordConfirm
+	FieldInviteCode      = validate.FieldInviteCode
+	FieldLocale          = validate.FieldLocale
+	FieldCountry         = validate.FieldCountry
+	FieldTimezone        = validate.FieldTimezone
+	FieldCaptchaToken    = validate.FieldCaptchaToken
+	FieldConsents        = validate.FieldConsents
+	FieldAccountType     = validate.FieldAccountType
+	FieldOwners          = validate.FieldOwners
+)
+
+// Rule is one named validation step. A failing rule with no Field stops
+// validation immediately; otherwise failures are collected per field.
+// Remote rules call other services and only run once every local rule has
+// passed, so a request with typos doesn't cost a database or network trip.
+type Rule struct {
+	Name   string
+	Field  string
+	Remote bool
+	Check  func(ctx context.Context, s *Submission) error
+}
+
+// Validator runs an ordered list of rules over a signup request. Callers can
+// add or remove rules to tailor validation per tenant or per test.
+type Validator struct {
+	Rules    []Rule
+	opts     ValidationOptions
+	dbClient postgres.PostgresDBService
+}
+
+// NewValidator returns a Validator with DefaultRules for opts.
+func NewValidator(dbClient postgres.PostgresDBService, opts ValidationOptions) *Validator {
+	v := &Validator{opts: opts, dbClient: dbClient}
+	v.Rules = v.DefaultRules()
+	return v
+}
+
+// DefaultRules returns the built-in rules. The breach, runtime blocklist,
+// handle claim, handle quarantine, domain ownership, organization owners,
+// invite code, domain throttle and signup risk rules are only included
+// when their checker is configured.
+func (v *Validator) DefaultRules() []Rule {
+	rules := []Rule{
+		{Name: RuleRequired, Check: v.checkRequired},
+		{Name: RuleAccountType, Field: FieldAccountType, Check: v.checkAccountType},
+		{Name: RuleHandle, Field: FieldHandle, Check: v.checkHandle},
+		{Name: RuleBlocklist, Field: FieldHandle, Check: v.checkBlocklist},
+		{Name: RuleConfusable, Field: FieldHandle, Check: v.checkConfusable},
+		{Name: RuleSimilarity, Field: FieldHandle, Check: v.checkSimilarity},
+		{Name: RuleProfanity, Field: FieldHandle, Check: v.checkProfanity},
+		{Name: RuleDisplayName, Field: FieldDisplayName, Check: v.checkDisplayName},
+		{Name: RuleBio, Field: FieldBio, Check: v.checkBio},
+		{Name: RulePronouns, Field: FieldPronouns, Check: v.checkPronouns},
+		{Name: RuleWebsite, Field: FieldWebsite, Check: v.checkWebsite},
+		{Name: RuleLocation, Field: FieldLocation, Check: v.checkLocation},
+		{Name: RuleEmail, Field: FieldEmail, Check: v.checkEmail},
+		{Name: RuleOrganizationEmail, Field: FieldEmail, Check: v.checkOrganizationEmail},
+		{Name: RuleLocale, Field: FieldLocale, Check: v.checkLocale},
+		{Name: RuleCountry, Field: FieldCountry, Check: v.checkCountry},
+		{Name: RuleTimezone, Field: FieldTimezone, Check: v.checkTimezone},
+		{Name: RuleConsent, Field: FieldConsents, Check: v.checkConsent},
+		{Name: RuleReferralSource, Check: v.checkReferralSource},
+		{Name: RulePassword, Field: FieldPassword, Check: v.checkPassword},
+		{Name: RulePasswordConfirm, Field: FieldPasswordConfirm, Check: v.checkPasswordConfirm},
+		{Name: RulePasswordStrength, Field: FieldPassword, Check: v.checkPasswordStrength},
+	}
+	if v.opts.InviteCodes != nil {
+		rules = append(rules, Rule{Name: RuleInviteCode, Field: FieldInviteCode, Check: v.checkInviteCode})
+	}
+	if v.opts.BreachChecker != nil {
+		rules = append(rules, Rule{Name: RulePasswordBreach, Field: FieldPassword, Remote: true, Check: v.checkPasswordBreach})
+	}
+	if v.opts.Blocklist != nil {
+		rules = append(rules, Rule{Name: RuleRuntimeBlocklist, Field: FieldHandle, Remote: true, Check: v.checkRuntimeBlocklist})
+	}
+	if v.opts.HandleClaims != nil {
+		rules = append(rules, Rule{Name: RuleHandleClaim, Field: FieldHandle, Remote: true, Check: v.checkHandleClaim})
+	}
+	if v.opts.HandleQuarantine != nil {
+		rules = append(rules, Rule{Name: RuleHandleQuarantine, Field: FieldHandle, Remote: true, Check: v.checkHandleQuarantine})
+	}
+	if v.opts.DomainVerifier != nil {
+		rules = append(rules, Rule{Name: RuleDomainOwnership, Field: FieldHandle, Remote: true, Check: v.checkDomainOwnership})
+	}
+	rules = append(rules,
+		Rule{Name: RuleConfusableExisting, Field: FieldHandle, Remote: true, Check: v.checkConfusableExisting},
+		Rule{Name: RuleEmailUnique, Field: FieldEmail, Remote: true, Check: v.checkEmailUnique},
+	)
+	if v.opts.Owners != nil {
+		rules = append(rules, Rule{Name: RuleOrganizationOwners, Field: FieldOwners, Remote: true, Check: v.checkOrganizationOwners})
+	}
+	if v.opts.InviteCodes != nil {
+		rules = append(rules, Rule{Name: RuleInviteCodeExists, Field: FieldInviteCode, Remote: true, Check: v.checkInviteCodeExists})
+	}
+	if v.opts.DomainThrottle != nil {
+		rules = append(rules, Rule{Name: RuleDomainThrottle, Field: FieldEmail, Remote: true, Check: v.checkDomainThrottle})
+	}
+	if v.opts.Risk != nil {
+		rules = append(rules, Rule{Name: RuleSignupRisk, Field: FieldCaptchaToken, Remote: true, Check: v.checkSignupRisk})
+	}
+	return rules
+}
+
+// ClaimRules are the rules a handle claim is checked with: those about the
+// handle and the email address, leaving out everything only a full signup
+// has.
+var ClaimRules = []string{
+	RuleHandle, RuleBlocklist, RuleConfusable, RuleSimilarity, RuleProfanity, RuleEmail,
+	RuleRuntimeBlocklist, RuleHandleClaim, RuleHandleQuarantine, RuleConfusableExisting, RuleEmailUnique,
+}
+
+// NewFieldValidator returns a Validator with only the ClaimRules for opts
+// that check field, FieldHandle or FieldEmail, so one field can be checked
+// on its own.
+func NewFieldValidator(dbClient postgres.PostgresDBService, opts ValidationOptions, field string) *Validator {
+	v := NewClaimValidator(dbClient, opts)
+	kept := v.Rules[:0]
+	for _, rule := range v.Rules {
+		if rule.Field == field {
+			kept = append(kept, rule)
+		}
+	}
+	v.Rules = kept
+	return v
+}
+
+// ResumeRules are the rules a signup resumed after its account was created
+// skips: they would find the account, or the invite and captcha it used,
+// and reject the signup that made them.
+var ResumeRules = []string{RuleEmailUnique, RuleInviteCodeExists, RuleDomainThrottle, RuleSignupRisk}
+
+// NewClaimValidator returns a Validator with only the ClaimRules for opts.
+func NewClaimValidator(dbClient postgres.PostgresDBService, opts ValidationOptions) *Validator {
+	v := NewValidator(dbClient, opts)
+	kept := v.Rules[:0]
+	for _, rule := range v.Rules {
+		if containsString(ClaimRules, rule.Name) {
+			kept = append(kept, rule)
+		}
+	}
+	v.Rules = kept
+	return v
+}
+
+// Remove drops the named rules.
+func (v *Validator) Remove(names ...string) {
+	kept := v.Rules[:0]
+	for _, rule := range v.Rules {
+		if !containsString(names, rule.Name) {
+			kept = append(kept, rule)
+		}
+	}
+	v.Rules = kept
+}
+
+// InsertBefore adds rule ahead of the named rule, or at the end if no rule
+// has that name.
+func (v *Validator) InsertBefore(name string, rule Rule) {
+	for i, existing := range v.Rules {
+		if existing.Name == name {
+			v.Rules = append(v.Rules[:i], append([]Rule{rule}, v.Rules[i:]...)...)
+			return
+		}
+	}
+	v.Rules = append(v.Rules, rule)
+}
+
+// Append adds rule at the end.
+func (v *Validator) Append(rule Rule) {
+	v.Rules = append(v.Rules, rule)
+}
+
+// Validate runs every rule and returns all field failures together. A single
+// failure is returned as-is; several are returned as validate.ValidationErrors.
+func (v *Validator) Validate(ctx context.Context, event models.UserRequest) (ValidationResult, error) {
+	s := &Submission{Request: event}
+	failedFields := map[string]bool{}
+	var errs validate.ValidationErrors
+
+	for _, rule := range v.Rules {
+		if failedFields[rule.Field] || (rule.Remote && len(errs) > 0) {
+			continue
+		}
+		if err := rule.Check(ctx, s); err != nil {
+			if verr, ok := validate.AsValidationError(err); ok && verr.Field == "" {
+				verr.Field = rule.Field
+			}
+			logging.FromContext(ctx).WithField("rule", rule.Name).Warnf("Validation failed: %v", err)
+			errs = append(errs, err)
+			if rule.Field == "" {
+				break
+			}
+			failedFields[rule.Field] = true
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+	case 1:
+		return ValidationResult{}, errs[0]
+	default:
+		return ValidationResult{}, errs
+	}
+
+	logging.FromContext(ctx).Info("User request validated successfully")
+	s.Result.User = s.Request
+	return s.Result, nil
+}
+
+func (v *Validator) checkRequired(ctx context.Context, s *Submission) error {
+	if s.Request.Handle == "" || s.Request.Email == "" || s.Request.Password == "" {
+		return validate.NewError(validate.CodeMissingFields, "%v", MissingFields).
+			With("fields", missingFields(s.Request))
+	}
+	return nil
+}
+
+func (v *Validator) checkHandle(ctx context.Context, s *Submission) error {
+	// v2 requests give the full handle; a bare name is a v1 habit the
+	// client has to drop.
+	if s.Request.Version == models.APIVersion2 && !strings.Contains(s.Request.Handle, ".") {
+		return validate.NewError(validate.CodeInvalidHandle, "handle must be the full handle, such as %s",
+			validate.EnsureHandleSuffix(s.Request.Handle, v.opts.HandleSuffix))
+	}
+	if v.isCustomDomain(s.Request.Handle) {
+		domain := strings.ToLower(strings.TrimSuffix(s.Request.Handle, "."))
+		if err := validate.DomainHandle(domain); err != nil {
+			return err
+		}
+		s.CustomDomain = true
+		s.BaseHandle, s.DisplayHandle, s.Request.Handle = domain, domain, domain
+		return nil
+	}
+
+	s.BaseHandle = strings.TrimSuffix(s.Request.Handle, v.opts.HandleSuffix)
+	s.DisplayHandle = s.BaseHandle
+
+	if v.opts.AllowUnicodeHandles && !validate.IsASCII(s.BaseHandle) {
+		display, ascii, err := validate.NormalizeUnicodeHandle(s.BaseHandle)
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"display_handle": display,
+			"dns_handle":     ascii,
+		}).Info("Normalized internationalized handle")
+		s.DisplayHandle, s.BaseHandle = display, ascii
+	} else if isOrganization(s.Request) {
+		if err := validate.OrganizationHandle(s.BaseHandle); err != nil {
+			return err
+		}
+	} else if err := validate.Handle(s.BaseHandle); err != nil {
+		return err
+	}
+
+	s.Request.Handle = validate.EnsureHandleSuffix(s.BaseHandle, v.opts.HandleSuffix)
+	return nil
+}
+
+// isCustomDomain reports whether handle names the user's own domain. Dotted
+// handles are only treated that way when domain verification is enabled;
+// otherwise they fail the ordinary format check.
+func (v *Validator) isCustomDomain(handle string) bool {
+	return v.opts.DomainVerifier != nil &&
+		strings.Contains(handle, ".") &&
+		!strings.HasSuffix(handle, v.opts.HandleSuffix)
+}
+
+// checkDomainOwnership requires a custom domain to publish the DID the user
+// is registering with, the same proof the PDS checks for handle updates.
+func (v *Validator) checkDomainOwnership(ctx context.Context, s *Submission) error {
+	if !s.CustomDomain {
+		return nil
+	}
+	if !strings.HasPrefix(s.Request.DID, "did:") {
+		return validate.NewError(validate.CodeDomainNotVerified, "custom domain handles require the did the domain points to")
+	}
+
+	verified, err := v.opts.DomainVerifier.VerifyHandle(ctx, s.Request.Handle, s.Request.DID)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("handle", s.Request.Handle).Error("Failed to verify custom domain handle")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to verify handle domain")
+	}
+	if !verified {
+		return validate.NewError(validate.CodeDomainNotVerified, "domain %s does not point to %s", s.Request.Handle, s.Request.DID).
+			With("domain", s.Request.Handle).
+			With("did", s.Request.DID)
+	}
+	return nil
+}
+
+func (v *Validator) checkBlocklist(ctx context.Context, s *Submission) error {
+	s.Result.Reservation, s.Result.ReservedCategory = CheckReservation(s.DisplayHandle)
+
+	switch s.Result.Reservation {
+	case ReservationBlocked:
+		return blockedHandleError(SuggestHandles(ctx, s.BaseHandle, v.opts.HandleSuffix, StorageAvailability(v.dbClient)))
+	case ReservationRequiresApproval:
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle":   s.Request.Handle,
+			"category": s.Result.ReservedCategory,
+		}).Warn("Reserved handle requires admin approval")
+		s.Flag(FlagReservedHandle)
+	}
+	return nil
+}
+
+func (v *Validator) checkRuntimeBlocklist(ctx context.Context, s *Submission) error {
+	blocked, err := v.opts.Blocklist.IsHandleBlocked(ctx, s.DisplayHandle)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check blocklist")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check handle")
+	}
+	if !blocked {
+		return nil
+	}
+
+	logging.FromContext(ctx).WithField("handle", s.Request.Handle).Warn("Handle is on the runtime blocklist")
+	return blockedHandleError(SuggestHandles(ctx, s.BaseHandle, v.opts.HandleSuffix, StorageAvailability(v.dbClient)))
+}
+
+// checkHandleClaim lets a claimed handle through only for the email
+// address that claimed it.
+func (v *Validator) checkHandleClaim(ctx context.Context, s *Submission) error {
+	claim, ok, err := v.opts.HandleClaims.ActiveHandleClaim(ctx, s.Request.Handle)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check handle claim")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check handle")
+	}
+	if !ok || claim.NormalizedEmail == postgres.NormalizeEmail(s.Request.Email) {
+		return nil
+	}
+	return validate.NewError(validate.CodeHandleClaimed, "handle is claimed until %s", claim.ExpiresAt.Format(time.RFC3339)).
+		With(ParamSuggestions, SuggestHandles(ctx, s.BaseHandle, v.opts.HandleSuffix, StorageAvailability(v.dbClient)))
+}
+
+// checkHandleQuarantine rejects the handle of a recently deleted account
+// until its quarantine is over.
+func (v *Validator) checkHandleQuarantine(ctx context.Context, s *Submission) error {
+	releasedAt, ok, err := v.opts.HandleQuarantine.QuarantinedHandle(ctx, s.Request.Handle)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check handle quarantine")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check handle")
+	}
+	if !ok {
+		return nil
+	}
+	return validate.NewError(validate.CodeHandleQuarantined, "handle is quarantined until %s", releasedAt.Format(time.RFC3339)).
+		With(ParamSuggestions, SuggestHandles(ctx, s.BaseHandle, v.opts.HandleSuffix, StorageAvailability(v.dbClient)))
+}
+
+func blockedHandleError(suggestions []string) error {
+	return validate.NewError(validate.CodeBlockedHandle, "provided handle is not allowed: %v", BlockedHandle).
+		With(ParamSuggestions, suggestions)
+}
+
+// checkConfusable rejects handles that only differ from a reserved handle by
+// lookalike characters, such as "sharefrarne" for "shareframe".
+func (v *Validator) checkConfusable(ctx context.Context, s *Submission) error {
+	reserved, ok := reservedSkeletons[confusables.Skeleton(s.DisplayHandle)]
+	if !ok || strings.EqualFold(reserved, s.DisplayHandle) {
+		return nil
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle":    s.Request.Handle,
+		"resembles": reserved,
+	}).Warn("Handle resembles a reserved handle")
+	return validate.NewError(validate.CodeConfusableHandle, "%v", ConfusableHandle)
+}
+
+// checkSimilarity rejects typosquats of protected handles, such as
+// "suport" or "adminn".
+func (v *Validator) checkSimilarity(ctx context.Context, s *Submission) error {
+	protected, ok := ResemblesProtectedHandle(s.DisplayHandle)
+	if !ok {
+		return nil
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle":    s.Request.Handle,
+		"resembles": protected,
+	}).Warn("Handle is a near-miss of a protected handle")
+	return validate.NewError(validate.CodeConfusableHandle, "%v", ProtectedHandle)
+}
+
+// checkConfusableExisting rejects handles that look like a registered one.
+// An exact match is left to the PDS existence check, which suggests
+// alternatives.
+func (v *Validator) checkConfusableExisting(ctx context.Context, s *Submission) error {
+	similar, err := v.dbClient.HandleSkeletonExists(ctx, s.Request.Handle)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check handle skeleton")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check handle")
+	}
+	if !similar {
+		return nil
+	}
+
+	exact, err := v.dbClient.CheckHandleExists(ctx, s.Request.Handle)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check handle existence")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check handle")
+	}
+	if exact {
+		return nil
+	}
+	return validate.NewError(validate.CodeConfusableHandle, "%v", ConfusableHandle)
+}
+
+func (v *Validator) checkProfanity(ctx context.Context, s *Submission) error {
+	return v.screenProfanity(ctx, s, s.DisplayHandle, validate.CodeProfaneHandle, ProfaneHandle)
+}
+
+// checkDisplayName cleans up the display name and validates it on its own
+// terms; it is only defaulted to the handle when the record is built.
+// Organizations must give their name.
+func (v *Validator) checkDisplayName(ctx context.Context, s *Submission) error {
+	s.Request.DisplayName = validate.NormalizeDisplayName(s.Request.DisplayName)
+	if s.Request.DisplayName == "" {
+		if isOrganization(s.Request) {
+			return validate.NewError(validate.CodeDisplayNameRequired, "organization accounts need a display name")
+		}
+		return nil
+	}
+	if err := validate.DisplayName(s.Request.DisplayName); err != nil {
+		return err
+	}
+	return v.screenProfanity(ctx, s, s.Request.DisplayName, validate.CodeProfaneDisplayName, ProfaneDisplayName)
+}
+
+func (v *Validator) checkBio(ctx context.Context, s *Submission) error {
+	s.Request.Bio = validate.NormalizeBio(s.Request.Bio)
+	return validate.Bio(s.Request.Bio)
+}
+
+func (v *Validator) checkPronouns(ctx context.Context, s *Submission) error {
+	s.Request.Pronouns = validate.NormalizeDisplayName(s.Request.Pronouns)
+	return validate.Pronouns(s.Request.Pronouns)
+}
+
+// checkWebsite canonicalizes the optional website so it is stored as a
+// full URL.
+func (v *Validator) checkWebsite(ctx context.Context, s *Submission) error {
+	if strings.TrimSpace(s.Request.Website) == "" {
+		s.Request.Website = ""
+		return nil
+	}
+	website, err := validate.Website(s.Request.Website)
+	if err != nil {
+		return err
+	}
+	s.Request.Website = website
+	return nil
+}
+
+func (v *Validator) checkLocation(ctx context.Context, s *Submission) error {
+	s.Request.Location = validate.NormalizeDisplayName(s.Request.Location)
+	return validate.Location(s.Request.Location)
+}
+
+// screenProfanity rejects or flags value according to the profanity mode.
+func (v *Validator) screenProfanity(ctx context.Context, s *Submission, value, code, message string) error {
+	if !ContainsProfanity(value) {
+		return nil
+	}
+	if v.opts.ProfanityMode == config.ProfanityFlag {
+		logging.FromContext(ctx).WithField("handle", s.Request.Handle).Warnf("Flagged for review: %v", message)
+		s.Flag(FlagProfanity)
+		return nil
+	}
+	return validate.NewError(code, "%v", message)
+}
+
+func (v *Validator) checkEmail(ctx context.Context, s *Submission) error {
+	s.Request.Email = validate.CanonicalizeEmail(s.Request.Email)
+	return validate.Email(s.Request.Email)
+}
+
+// checkLocale canonicalizes the optional locale so it is stored in one form.
+func (v *Validator) checkLocale(ctx context.Context, s *Submission) error {
+	if s.Request.Locale == "" {
+		return nil
+	}
+	locale, err := validate.Locale(s.Request.Locale)
+	if err != nil {
+		return err
+	}
+	s.Request.Locale = locale
+	return nil
+}
+
+func (v *Validator) checkCountry(ctx context.Context, s *Submission) error {
+	if s.Request.Country == "" {
+		return nil
+	}
+	country, err := validate.Country(s.Request.Country)
+	if err != nil {
+		return err
+	}
+	s.Request.Country = country
+	return nil
+}
+
+func (v *Validator) checkTimezone(ctx context.Context, s *Submission) error {
+	if s.Request.Timezone == "" {
+		return nil
+	}
+	timezone, err := validate.Timezone(s.Request.Timezone)
+	if err != nil {
+		return err
+	}
+	s.Request.Timezone = timezone
+	return nil
+}
+
+// maxConsentTextVersion bounds the consent text ID, which is an identifier
+// such as "marketing-2026-01", not the text itself.
+const maxConsentTextVersion = 64
+
+// checkConsent accepts each known purpose at most once, with the version of
+// the text that was shown. Any time the client sent is dropped; the service
+// stamps the choice when it is stored.
+func (v *Validator) checkConsent(ctx context.Context, s *Submission) error {
+	seen := map[string]bool{}
+	var consents []models.Consent
+	for _, consent := range s.Request.Consents {
+		consent.TextVersion = strings.TrimSpace(consent.TextVersion)
+		switch {
+		case consent.Purpose != models.ConsentMarketing && consent.Purpose != models.ConsentDataProcessing:
+			return validate.NewError(validate.CodeInvalidConsent, "unknown consent purpose: %q", consent.Purpose)
+		case seen[consent.Purpose]:
+			return validate.NewError(validate.CodeInvalidConsent, "consent given twice for %s", consent.Purpose)
+		case consent.TextVersion == "" || len(consent.TextVersion) > maxConsentTextVersion:
+			return validate.NewError(validate.CodeInvalidConsent, "consent for %s needs the version of its text", consent.Purpose)
+		}
+		seen[consent.Purpose] = true
+		consent.RecordedAt = time.Time{}
+		consents = append(consents, consent)
+	}
+	s.Request.Consents = consents
+
+	if v.opts.RequireProcessingConsent {
+		if consent, _ := models.FindConsent(s.Request.Consents, models.ConsentDataProcessing); !consent.Granted {
+			return validate.NewError(validate.CodeConsentRequired, "data processing consent is required")
+		}
+	}
+	return nil
+}
+
+// maxReferralSource bounds the referral source, which is a tag such as
+// "friend" or "spring-campaign" rather than free text.
+const maxReferralSource = 64
+
+// checkReferralSource normalizes the referral source to a lowercase tag of
+// letters, digits, dots, dashes and underscores, with spaces turned into
+// dashes. It is only used for reporting, so anything else is dropped
+// rather than failing the signup.
+func (v *Validator) checkReferralSource(ctx context.Context, s *Submission) error {
+	source := strings.ToLower(strings.TrimSpace(s.Request.ReferralSource))
+	source = strings.Join(strings.Fields(source), "-")
+	for _, r := range source {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			source = ""
+			break
+		}
+	}
+	if len(source) > maxReferralSource {
+		source = ""
+	}
+	s.Request.ReferralSource = source
+	return nil
+}
+
+func (v *Validator) checkPassword(ctx context.Context, s *Submission) error {
+	return validate.Password(s.Request.Password)
+}
+
+// checkPasswordConfirm rejects a confirmation that doesn't match. Clients
+// that don't send one skip the check.
+func (v *Validator) checkPasswordConfirm(ctx context.Context, s *Submission) error {
+	if s.Request.PasswordConfirm == "" || s.Request.PasswordConfirm == s.Request.Password {
+		return nil
+	}
+	return validate.NewError(validate.CodePasswordMismatch, "%v", PasswordMismatch)
+}
+
+func (v *Validator) checkPasswordStrength(ctx context.Context, s *Submission) error {
+	emailUser, _, _ := strings.Cut(s.Request.Email, "@")
+	userInputs := []string{s.DisplayHandle, emailUser, s.Request.DisplayName}
+	return validate.PasswordStrength(s.Request.Password, v.opts.MinPasswordScore, userInputs)
+}
+
+func (v *Validator) checkPasswordBreach(ctx context.Context, s *Submission) error {
+	breached, err := v.opts.BreachChecker.Breached(ctx, s.Request.Password)
+	if err != nil {
+		// Fail open: an unavailable breach service must not block signups.
+		logging.FromContext(ctx).WithError(err).Warn("Breached-password check failed; skipping")
+		return nil
+	}
+	if breached {
+		return validate.NewError(validate.CodePasswordBreached, "%v", PasswordBreached)
+	}
+	return nil
+}
+
+func (v *Validator) checkEmailUnique(ctx context.Context, s *Submission) error {
+	exists, err := v.dbClient.CheckEmailExists(ctx, s.Request.Email)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check email existence")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check email")
+	}
+	if exists {
+		return validate.NewError(validate.CodeEmailTaken, "%v", EmailTaken)
+	}
+	return nil
+}
+
+// missingFields lists the required fields absent from req.
+func missingFields(req models.UserRequest) []string {
+	var missing []string
+	for field, value := range map[string]string{
+		FieldHandle:   req.Handle,
+		FieldEmail:    req.Email,
+		FieldPassword: req.Password,
+	} {
+		if value == "" {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (v *Validator) checkInviteCode(ctx context.Context, s *Submission) error {
+	s.Request.InviteCode = validate.NormalizeInviteCode(s.Request.InviteCode)
+	return validate.InviteCode(s.Request.InviteCode)
+}
+
+func (v *Validator) checkInviteCodeExists(ctx context.Context, s *Submission) error {
+	available, err := v.opts.InviteCodes.InviteCodeAvailable(ctx, s.Request.InviteCode)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Database error: failed to check invite code")
+		return validate.NewError(validate.CodeInternal, "internal error: failed to check invite code")
+	}
+	if !available {
+		return validate.NewError(validate.CodeInvalidInviteCode, "%v", validate.InvalidInviteCode)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,654 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func ruleNames(v *Validator) []string {
+	var names []string
+	for _, rule := range v.Rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
+
+func TestNewValidatorDefaultRules(t *testing.T) {
+	v := NewValidator(newMockPostgresClient(), ValidationOptions{})
+	assert.Equal(t, []string{
+		RuleRequired, RuleAccountType, RuleHandle, RuleBlocklist, RuleConfusable, RuleSimilarity, RuleProfanity, RuleDisplayName, RuleBio, RulePronouns,
+		RuleWebsite, RuleLocation, RuleEmail, RuleOrganizationEmail, RuleLocale, RuleCountry, RuleTimezone, RuleConsent, RuleReferralSource, RulePassword, RulePasswordConfirm, RulePasswordStrength, RuleConfusableExisting, RuleEmailUnique,
+	}, ruleNames(v))
+
+	v = NewValidator(newMockPostgresClient(), ValidationOptions{BreachChecker: new(mockBreachChecker)})
+	assert.Contains(t, ruleNames(v), RulePasswordBreach)
+}
+
+func TestValidatorRemove(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+
+	v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+	v.Remove(RuleProfanity, RuleEmailUnique)
+
+	result, err := v.Validate(ctx, models.UserRequest{Handle: "shithead", Email: "user@example.com", Password: "Valid@123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "shithead"+PDS_Suffix, result.User.Handle)
+	mockDB.AssertNotCalled(t, "CheckEmailExists", mock.Anything, mock.Anything)
+}
+
+func TestValidatorInsertBefore(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	errCorporate := errors.New("only corporate addresses may sign up")
+
+	v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+	v.InsertBefore(RuleEmailUnique, Rule{
+		Name: "corporate_email",
+		Check: func(ctx context.Context, s *Submission) error {
+			if s.Request.Email != "user@corp.example" {
+				return errCorporate
+			}
+			s.Flag("corporate")
+			return nil
+		},
+	})
+
+	_, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123"})
+	assert.ErrorIs(t, err, errCorporate)
+	mockDB.AssertNotCalled(t, "CheckEmailExists", mock.Anything, mock.Anything)
+
+	mockDB.On("CheckEmailExists", ctx, "user@corp.example").Return(false, nil)
+	result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@corp.example", Password: "Valid@123"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"corporate"}, result.Flags)
+	mockDB.AssertExpectations(t)
+}
+
+func TestValidatorAggregatesFieldErrors(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+
+	_, err := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix}).
+		Validate(ctx, models.UserRequest{Handle: "ab", Email: "not-an-email", Password: "weak"})
+
+	var errs validate.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 3)
+	assert.Equal(t, validate.CodeHandleTooShort, validate.ErrorCode(errs[0]))
+	assert.Equal(t, validate.CodeInvalidEmail, validate.ErrorCode(errs[1]))
+	assert.Equal(t, validate.CodePasswordRequirements, validate.ErrorCode(errs[2]))
+	assert.Equal(t, validate.CodeHandleTooShort, validate.ErrorCode(err))
+	mockDB.AssertNotCalled(t, "CheckEmailExists", mock.Anything, mock.Anything)
+
+	localized := LocalizeErrors(err, "es")
+	assert.Len(t, localized, 3)
+	assert.Equal(t, "Introduce una dirección de correo válida.", localized[1].Message)
+}
+
+func TestValidatorSkipsLaterRulesForFailedField(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewValidator(newMockPostgresClient(), ValidationOptions{HandleSuffix: PDS_Suffix, MinPasswordScore: 3}).
+		Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "short"})
+
+	assert.Equal(t, validate.CodePasswordRequirements, validate.ErrorCode(err))
+	var errs validate.ValidationErrors
+	assert.False(t, errors.As(err, &errs))
+}
+
+func TestValidatorPasswordConfirm(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		confirm      string
+		expectedCode string
+	}{
+		{name: "Omitted"},
+		{name: "Matches", confirm: "Valid@123"},
+		{name: "Mismatch", confirm: "Valid@124", expectedCode: validate.CodePasswordMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			if test.expectedCode == "" {
+				mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+			}
+
+			_, err := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix}).Validate(ctx, models.UserRequest{
+				Handle:          "validuser",
+				Email:           "user@example.com",
+				Password:        "Valid@123",
+				PasswordConfirm: test.confirm,
+			})
+
+			if test.expectedCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			verr, ok := validate.AsValidationError(err)
+			assert.True(t, ok)
+			assert.Equal(t, test.expectedCode, verr.Code)
+			assert.Equal(t, FieldPasswordConfirm, verr.Field)
+			mockDB.AssertNotCalled(t, "CheckEmailExists", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestValidatorLocaleCountryAndTimezone(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+	v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+	result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", Locale: "pt_br", Country: "br", Timezone: "America/Sao_Paulo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "pt-BR", result.User.Locale)
+	assert.Equal(t, "BR", result.User.Country)
+	assert.Equal(t, "America/Sao_Paulo", result.User.Timezone)
+
+	_, err = v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", Locale: "xx", Country: "ZZ", Timezone: "Mars/Olympus_Mons"})
+	var errs validate.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 3)
+	assert.Equal(t, validate.CodeInvalidLocale, validate.ErrorCode(errs[0]))
+	assert.Equal(t, validate.CodeInvalidCountry, validate.ErrorCode(errs[1]))
+	assert.Equal(t, validate.CodeInvalidTimezone, validate.ErrorCode(errs[2]))
+}
+
+func TestValidatorProfileFields(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+	v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+	result, err := v.Validate(ctx, models.UserRequest{
+		Handle: "validuser", Email: "user@example.com", Password: "Valid@123",
+		Bio: " Photographer\nLisbon ", Pronouns: " she/her ", Website: "Alice.Example", Location: "Lisbon,  Portugal",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Photographer\nLisbon", result.User.Bio)
+	assert.Equal(t, "she/her", result.User.Pronouns)
+	assert.Equal(t, "https://alice.example", result.User.Website)
+	assert.Equal(t, "Lisbon, Portugal", result.User.Location)
+
+	_, err = v.Validate(ctx, models.UserRequest{
+		Handle: "validuser", Email: "user@example.com", Password: "Valid@123",
+		Bio: strings.Repeat("a", validate.MaxBioLength+1), Pronouns: strings.Repeat("a", validate.MaxPronounsLength+1),
+		Website: "ftp://alice.example", Location: strings.Repeat("a", validate.MaxLocationLength+1),
+	})
+	var errs validate.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 4)
+	assert.Equal(t, validate.CodeBioTooLong, validate.ErrorCode(errs[0]))
+	assert.Equal(t, validate.CodePronounsTooLong, validate.ErrorCode(errs[1]))
+	assert.Equal(t, validate.CodeInvalidWebsite, validate.ErrorCode(errs[2]))
+	assert.Equal(t, validate.CodeLocationTooLong, validate.ErrorCode(errs[3]))
+}
+
+func TestValidatorConsent(t *testing.T) {
+	ctx := context.Background()
+	marketing := models.Consent{Purpose: models.ConsentMarketing, Granted: false, TextVersion: "marketing-2026-01"}
+	processing := models.Consent{Purpose: models.ConsentDataProcessing, Granted: true, TextVersion: " terms-2026-01 "}
+
+	tests := []struct {
+		name         string
+		consents     []models.Consent
+		require      bool
+		expectedCode string
+	}{
+		{name: "No Choices", consents: nil},
+		{name: "Choices Recorded", consents: []models.Consent{marketing, processing}, require: true},
+		{name: "Processing Required", consents: []models.Consent{marketing}, require: true, expectedCode: validate.CodeConsentRequired},
+		{name: "Processing Declined", consents: []models.Consent{{Purpose: models.ConsentDataProcessing, TextVersion: "terms-2026-01"}}, require: true, expectedCode: validate.CodeConsentRequired},
+		{name: "Unknown Purpose", consents: []models.Consent{{Purpose: "profiling", Granted: true, TextVersion: "v1"}}, expectedCode: validate.CodeInvalidConsent},
+		{name: "Repeated Purpose", consents: []models.Consent{marketing, marketing}, expectedCode: validate.CodeInvalidConsent},
+		{name: "Missing Text Version", consents: []models.Consent{{Purpose: models.ConsentMarketing, Granted: true}}, expectedCode: validate.CodeInvalidConsent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, RequireProcessingConsent: test.require})
+
+			result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", Consents: test.consents})
+
+			if test.expectedCode != "" {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, FieldConsents, verr.Field)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, result.User.Consents, len(test.consents))
+			for _, consent := range result.User.Consents {
+				assert.Equal(t, strings.TrimSpace(consent.TextVersion), consent.TextVersion)
+			}
+		})
+	}
+}
+
+func TestValidatorReferralSource(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "None", source: "", expected: ""},
+		{name: "Tag", source: "friend", expected: "friend"},
+		{name: "Normalized", source: "  Spring  Campaign ", expected: "spring-campaign"},
+		{name: "Unusable Characters", source: "<script>", expected: ""},
+		{name: "Too Long", source: strings.Repeat("a", maxReferralSource+1), expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+			result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", ReferralSource: test.source})
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.User.ReferralSource)
+		})
+	}
+}
+
+type mockBlocklist struct {
+	mock.Mock
+}
+
+func (m *mockBlocklist) IsHandleBlocked(ctx context.Context, handle string) (bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestValidatorRuntimeBlocklist(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		blocked      bool
+		checkErr     error
+		expectedCode string
+	}{
+		{name: "Not Blocked"},
+		{name: "Blocked At Runtime", blocked: true, expectedCode: validate.CodeBlockedHandle},
+		{name: "Store Unavailable", checkErr: errors.New("DB connection failed"), expectedCode: validate.CodeInternal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil).Maybe()
+			mockDB.On("CheckHandleExists", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+			blocklist := new(mockBlocklist)
+			blocklist.On("IsHandleBlocked", ctx, "spammer").Return(test.blocked, test.checkErr)
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, Blocklist: blocklist})
+			assert.Contains(t, ruleNames(v), RuleRuntimeBlocklist)
+
+			_, err := v.Validate(ctx, models.UserRequest{Handle: "spammer", Email: "user@example.com", Password: "Valid@123"})
+
+			if test.expectedCode == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			}
+			blocklist.AssertExpectations(t)
+		})
+	}
+}
+
+type mockHandleClaims struct {
+	mock.Mock
+}
+
+func (m *mockHandleClaims) ActiveHandleClaim(ctx context.Context, handle string) (models.HandleClaim, bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(models.HandleClaim), args.Bool(1), args.Error(2)
+}
+
+func TestValidatorHandleClaim(t *testing.T) {
+	ctx := context.Background()
+	claim := models.HandleClaim{Handle: "claimed" + PDS_Suffix, NormalizedEmail: "owner@example.com", ExpiresAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name         string
+		email        string
+		claimed      bool
+		checkErr     error
+		expectedCode string
+	}{
+		{name: "Not Claimed", email: "user@example.com"},
+		{name: "Claimed By Signup", email: "Owner+launch@example.com", claimed: true},
+		{name: "Claimed By Someone Else", email: "user@example.com", claimed: true, expectedCode: validate.CodeHandleClaimed},
+		{name: "Store Unavailable", email: "user@example.com", checkErr: errors.New("DB connection failed"), expectedCode: validate.CodeInternal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil).Maybe()
+			mockDB.On("CheckHandleExists", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+			claims := new(mockHandleClaims)
+			claims.On("ActiveHandleClaim", ctx, "claimed"+PDS_Suffix).Return(claim, test.claimed, test.checkErr)
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, HandleClaims: claims})
+			_, err := v.Validate(ctx, models.UserRequest{Handle: "claimed", Email: test.email, Password: "Valid@123"})
+
+			if test.expectedCode == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			}
+			claims.AssertExpectations(t)
+		})
+	}
+}
+
+type mockHandleQuarantine struct {
+	mock.Mock
+}
+
+func (m *mockHandleQuarantine) QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
+func TestValidatorHandleQuarantine(t *testing.T) {
+	ctx := context.Background()
+	releasedAt := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		quarantined  bool
+		checkErr     error
+		expectedCode string
+	}{
+		{name: "Not Quarantined"},
+		{name: "Quarantined", quarantined: true, expectedCode: validate.CodeHandleQuarantined},
+		{name: "Store Unavailable", checkErr: errors.New("DB connection failed"), expectedCode: validate.CodeInternal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, mock.Anything).Return(false, nil).Maybe()
+			mockDB.On("CheckHandleExists", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+			quarantine := new(mockHandleQuarantine)
+			quarantine.On("QuarantinedHandle", ctx, "departed"+PDS_Suffix).Return(releasedAt, test.quarantined, test.checkErr)
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, HandleQuarantine: quarantine})
+			_, err := v.Validate(ctx, models.UserRequest{Handle: "departed", Email: "user@example.com", Password: "Valid@123"})
+
+			if test.expectedCode == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			}
+			quarantine.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewClaimValidator(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil)
+	claims := new(mockHandleClaims)
+	claims.On("ActiveHandleClaim", ctx, "validuser"+PDS_Suffix).Return(models.HandleClaim{}, false, nil)
+
+	v := NewClaimValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, HandleClaims: claims})
+	assert.Equal(t, []string{
+		RuleHandle, RuleBlocklist, RuleConfusable, RuleSimilarity, RuleProfanity, RuleEmail,
+		RuleHandleClaim, RuleConfusableExisting, RuleEmailUnique,
+	}, ruleNames(v))
+
+	result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com"})
+	assert.NoError(t, err, "a claim needs no password")
+	assert.Equal(t, "validuser"+PDS_Suffix, result.User.Handle)
+}
+
+func TestNewFieldValidator(t *testing.T) {
+	ctx := context.Background()
+	mockDB := newMockPostgresClient()
+	claims := new(mockHandleClaims)
+	opts := ValidationOptions{HandleSuffix: PDS_Suffix, HandleClaims: claims}
+
+	v := NewFieldValidator(mockDB, opts, FieldHandle)
+	assert.Equal(t, []string{
+		RuleHandle, RuleBlocklist, RuleConfusable, RuleSimilarity, RuleProfanity, RuleHandleClaim, RuleConfusableExisting,
+	}, ruleNames(v))
+
+	v = NewFieldValidator(mockDB, opts, FieldEmail)
+	assert.Equal(t, []string{RuleEmail, RuleEmailUnique}, ruleNames(v))
+
+	mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(true, nil)
+	_, err := v.Validate(ctx, models.UserRequest{Email: "user@example.com"})
+	assert.Equal(t, validate.CodeEmailTaken, validate.ErrorCode(err))
+}
+
+func TestValidateAndFormatUserUnicodeHandles(t *testing.T) {
+	ctx := context.Background()
+	user := models.UserRequest{Handle: "münchen", Email: "user@example.com", Password: "Valid@123"}
+
+	mockDB := newMockPostgresClient()
+	_, err := ValidateAndFormatUser(ctx, user, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+	assert.ErrorContains(t, err, validate.InvalidHandle)
+
+	mockDB.On("CheckEmailExists", ctx, user.Email).Return(false, nil)
+	result, err := ValidateAndFormatUser(ctx, user, mockDB, ValidationOptions{
+		HandleSuffix:        PDS_Suffix,
+		AllowUnicodeHandles: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "xn--mnchen-3ya"+PDS_Suffix, result.User.Handle)
+	mockDB.AssertExpectations(t)
+}
+
+func TestValidateAndFormatUserDisplayName(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		displayName  string
+		expected     string
+		expectedCode string
+	}{
+		{name: "Optional", displayName: "", expected: ""},
+		{name: "Normalized", displayName: " Jane\u202e  Doe ", expected: "Jane Doe"},
+		{name: "Too Long", displayName: strings.Repeat("a", validate.MaxDisplayNameLength+1), expectedCode: validate.CodeDisplayNameTooLong},
+		{name: "Profane", displayName: "B1tch", expectedCode: validate.CodeProfaneDisplayName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			user := models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", DisplayName: test.displayName}
+			if test.expectedCode == "" {
+				mockDB.On("CheckEmailExists", ctx, user.Email).Return(false, nil)
+			}
+
+			result, err := ValidateAndFormatUser(ctx, user, mockDB, ValidationOptions{HandleSuffix: PDS_Suffix})
+
+			if test.expectedCode != "" {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result.User.DisplayName)
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+type mockDomainVerifier struct {
+	mock.Mock
+}
+
+func (m *mockDomainVerifier) VerifyHandle(ctx context.Context, domain, did string) (bool, error) {
+	args := m.Called(ctx, domain, did)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestValidatorCustomDomainHandles(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		handle       string
+		did          string
+		verified     bool
+		verifyErr    error
+		expectVerify bool
+		expectedCode string
+	}{
+		{name: "Verified Domain", handle: "Alice.Example.com", did: "did:plc:abc123", verified: true, expectVerify: true},
+		{name: "Domain Points Elsewhere", handle: "alice.example.com", did: "did:plc:abc123", expectVerify: true, expectedCode: validate.CodeDomainNotVerified},
+		{name: "Missing DID", handle: "alice.example.com", expectedCode: validate.CodeDomainNotVerified},
+		{name: "Lookup Failure", handle: "alice.example.com", did: "did:plc:abc123", verifyErr: errors.New("timeout"), expectVerify: true, expectedCode: validate.CodeInternal},
+		{name: "Invalid Domain", handle: "alice..example.com", did: "did:plc:abc123", expectedCode: validate.CodeInvalidDomainHandle},
+		{name: "Tenant Suffix Is Not A Custom Domain", handle: "alice" + PDS_Suffix},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil).Maybe()
+			verifier := new(mockDomainVerifier)
+			if test.expectVerify {
+				verifier.On("VerifyHandle", ctx, "alice.example.com", test.did).Return(test.verified, test.verifyErr)
+			}
+
+			result, err := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, DomainVerifier: verifier}).
+				Validate(ctx, models.UserRequest{Handle: test.handle, Email: "user@example.com", Password: "Valid@123", DID: test.did})
+
+			if test.expectedCode != "" {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, strings.ToLower(test.handle), result.User.Handle)
+			}
+			verifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidatorHandleVersions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		version      string
+		handle       string
+		expectedCode string
+	}{
+		{name: "v1 Bare Name", handle: "validuser"},
+		{name: "v1 Full Handle", version: models.APIVersion1, handle: "validuser" + PDS_Suffix},
+		{name: "v2 Full Handle", version: models.APIVersion2, handle: "validuser" + PDS_Suffix},
+		{name: "v2 Bare Name", version: models.APIVersion2, handle: "validuser", expectedCode: validate.CodeInvalidHandle},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil).Maybe()
+
+			result, err := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix}).Validate(ctx, models.UserRequest{
+				Handle:   test.handle,
+				Email:    "user@example.com",
+				Password: "Valid@123",
+				Version:  test.version,
+			})
+
+			if test.expectedCode != "" {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, FieldHandle, verr.Field)
+				assert.Contains(t, verr.Error(), "validuser"+PDS_Suffix)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "validuser"+PDS_Suffix, result.User.Handle)
+		})
+	}
+}
+
+type mockInviteCodes struct {
+	mock.Mock
+}
+
+func (m *mockInviteCodes) InviteCodeAvailable(ctx context.Context, code string) (bool, error) {
+	args := m.Called(ctx, code)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestValidatorInviteCodes(t *testing.T) {
+	ctx := context.Background()
+	const code = "shareframe-app-abcde-fghij"
+
+	tests := []struct {
+		name         string
+		inviteCode   string
+		available    bool
+		checkErr     error
+		expectLookup bool
+		expectedCode string
+	}{
+		{name: "Available Code", inviteCode: code, available: true, expectLookup: true},
+		{name: "Normalized Before Lookup", inviteCode: " ShareFrame-App-ABCDE-FGHIJ ", available: true, expectLookup: true},
+		{name: "Missing Code", expectedCode: validate.CodeInvalidInviteCode},
+		{name: "Malformed Code Skips Lookup", inviteCode: "letmein", expectedCode: validate.CodeInvalidInviteCode},
+		{name: "Unknown Or Spent Code", inviteCode: code, expectLookup: true, expectedCode: validate.CodeInvalidInviteCode},
+		{name: "Store Unavailable", inviteCode: code, checkErr: errors.New("DB connection failed"), expectLookup: true, expectedCode: validate.CodeInternal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockDB := newMockPostgresClient()
+			mockDB.On("CheckEmailExists", ctx, "user@example.com").Return(false, nil).Maybe()
+			invites := new(mockInviteCodes)
+			if test.expectLookup {
+				invites.On("InviteCodeAvailable", ctx, code).Return(test.available, test.checkErr)
+			}
+
+			v := NewValidator(mockDB, ValidationOptions{HandleSuffix: PDS_Suffix, InviteCodes: invites})
+			assert.Contains(t, ruleNames(v), RuleInviteCode)
+			assert.Contains(t, ruleNames(v), RuleInviteCodeExists)
+
+			result, err := v.Validate(ctx, models.UserRequest{Handle: "validuser", Email: "user@example.com", Password: "Valid@123", InviteCode: test.inviteCode})
+
+			if test.expectedCode == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, code, result.User.InviteCode)
+			} else {
+				verr, ok := validate.AsValidationError(err)
+				assert.True(t, ok)
+				assert.Equal(t, test.expectedCode, verr.Code)
+				assert.Equal(t, FieldInviteCode, verr.Field)
+			}
+			invites.AssertExpectations(t)
+			if !test.expectLookup {
+				invites.AssertNotCalled(t, "InviteCodeAvailable", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+)
+
+const RangeEndpoint = "https://api.pwnedpasswords.com/range/"
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client checks passwords against the Have I Been Pwned range API. Only the
+// first five hex characters of the SHA-1 hash leave the process.
+type Client struct {
+	Endpoint   string
+	HTTPClient HTTPClient
+	Timeout    time.Duration
+}
+
+func NewClient(client HTTPClient, timeout time.Duration) *Client {
+	return &Client{
+		Endpoint:   RangeEndpoint,
+		HTTPClient: client,
+		Timeout:    timeout,
+	}
+}
+
+// Breached reports whether password appears in the breach corpus.
+func (c *Client) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Endpoint+prefix, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create breach check request: %w", err)
+	}
+	// Padding hides the real number of matches for the prefix from observers.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("breach check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code from breach check: %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || candidate != suffix {
+			continue
+		}
+		// Padding entries have a count of zero.
+		if count == "0" {
+			return false, nil
+		}
+		logging.FromContext(ctx).WithField("count", count).Info("Password found in breach corpus")
+		return true, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breach check response: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,68 @@
+package hibp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestBreached(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		httpErr       error
+		expected      bool
+		expectedError string
+	}{
+		{"Found", http.StatusOK, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n" + passwordSuffix + ":9545824\r\n", nil, true, ""},
+		{"Not Found", http.StatusOK, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n", nil, false, ""},
+		{"Padding Entry", http.StatusOK, passwordSuffix + ":0\r\n", nil, false, ""},
+		{"Server Error", http.StatusServiceUnavailable, "", nil, false, "unexpected status code from breach check: 503"},
+		{"Network Error", 0, "", errors.New("connection reset"), false, "breach check request failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewClient(&MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, RangeEndpoint+"5BAA6", req.URL.String())
+					assert.Equal(t, "true", req.Header.Get("Add-Padding"))
+					if test.httpErr != nil {
+						return nil, test.httpErr
+					}
+					return &http.Response{
+						StatusCode: test.statusCode,
+						Body:       io.NopCloser(bytes.NewBufferString(test.body)),
+					}, nil
+				},
+			}, time.Second)
+
+			breached, err := client.Breached(context.Background(), "password")
+
+			assert.Equal(t, test.expected, breached)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package ingress takes the raw payload a Lambda function is invoked with,
+// works out which event source sent it and normalizes it into Requests
+// before dispatching them, so one function can be invoked directly, behind
+// API Gateway or a function URL, as an AppSync resolver or from an SQS
+// queue.
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/lambdahttp"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Source is the kind of event a payload came from.
+type Source string
+
+const (
+	// SourceDirect is a direct invoke whose payload is the request.
+	SourceDirect Source = "direct"
+	// SourceAPIGateway is an API Gateway REST API proxy integration.
+	SourceAPIGateway Source = "apigateway"
+	// SourceHTTPAPI is an API Gateway HTTP API or a function URL, which
+	// share payload format 2.0.
+	SourceHTTPAPI Source = "httpapi"
+	// SourceAppSync is an AppSync direct Lambda resolver.
+	SourceAppSync Source = "appsync"
+	// SourceSQS is a batch of SQS messages, each a request.
+	SourceSQS Source = "sqs"
+)
+
+// Request is one request normalized from the event that carried it.
+type Request struct {
+	Source Source
+	// ID is the SQS message ID of a queued request.
+	ID string
+	// Body is the request as JSON.
+	Body json.RawMessage
+	// ClientIP and Locale describe the caller when the event says who it
+	// is.
+	ClientIP string
+	Locale   string
+	// HTTP is the request API Gateway and function URL events carry. They
+	// are answered by the function's HTTP route.
+	HTTP *lambdahttp.Request
+}
+
+// probe holds the fields Detect tells the event sources apart by.
+type probe struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	HTTPMethod     string `json:"httpMethod"`
+	Version        string `json:"version"`
+	RequestContext *struct {
+		HTTP *struct {
+			Method string `json:"method"`
+		} `json:"http"`
+	} `json:"requestContext"`
+	Arguments json.RawMessage `json:"arguments"`
+	Info      *struct {
+		FieldName string `json:"fieldName"`
+	} `json:"info"`
+}
+
+// Detect returns the source of payload. Anything that isn't recognizably
+// an event is taken to be a direct invoke.
+func Detect(payload json.RawMessage) Source {
+	var p probe
+	if json.Unmarshal(payload, &p) != nil {
+		return SourceDirect
+	}
+	switch {
+	case len(p.Records) > 0 && p.Records[0].EventSource == "aws:sqs":
+		return SourceSQS
+	case p.HTTPMethod != "" && p.RequestContext != nil:
+		return SourceAPIGateway
+	case p.Version == "2.0" && p.RequestContext != nil && p.RequestContext.HTTP != nil:
+		return SourceHTTPAPI
+	case p.Info != nil && p.Info.FieldName != "" && p.Arguments != nil:
+		return SourceAppSync
+	}
+	return SourceDirect
+}
+
+// appSyncEvent is the part of an AppSync direct Lambda resolver event a
+// request is read from.
+type appSyncEvent struct {
+	Arguments map[string]json.RawMessage `json:"arguments"`
+	Identity  *struct {
+		SourceIP []string `json:"sourceIp"`
+	} `json:"identity"`
+	Request struct {
+		Headers map[string]string `json:"headers"`
+	} `json:"request"`
+}
+
+// Normalize detects the source of payload and returns its requests: one
+// for every source but SQS, which has one per message.
+func Normalize(payload json.RawMessage) (Source, []Request, error) {
+	source := Detect(payload)
+	switch source {
+	case SourceAPIGateway:
+		var event events.APIGatewayProxyRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return source, nil, fmt.Errorf("failed to decode API Gateway event: %w", err)
+		}
+		req, err := lambdahttp.FromAPIGateway(event)
+		return source, []Request{httpRequest(source, req)}, err
+
+	case SourceHTTPAPI:
+		var event events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return source, nil, fmt.Errorf("failed to decode HTTP API event: %w", err)
+		}
+		req, err := lambdahttp.FromHTTPAPI(event)
+		return source, []Request{httpRequest(source, req)}, err
+
+	case SourceAppSync:
+		var event appSyncEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return source, nil, fmt.Errorf("failed to decode AppSync event: %w", err)
+		}
+		req := Request{Source: source, Locale: header(event.Request.Headers, "Accept-Language")}
+		// Mutations take their request as an input argument, as in the
+		// service's own GraphQL schema; otherwise the arguments are the
+		// request.
+		req.Body = event.Arguments["input"]
+		if req.Body == nil || len(event.Arguments) > 1 {
+			arguments, err := json.Marshal(event.Arguments)
+			if err != nil {
+				return source, nil, fmt.Errorf("failed to encode AppSync arguments: %w", err)
+			}
+			req.Body = arguments
+		}
+		if event.Identity != nil && len(event.Identity.SourceIP) > 0 {
+			req.ClientIP = event.Identity.SourceIP[0]
+		}
+		return source, []Request{req}, nil
+
+	case SourceSQS:
+		var event events.SQSEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return source, nil, fmt.Errorf("failed to decode SQS event: %w", err)
+		}
+		requests := make([]Request, len(event.Records))
+		for i, message := range event.Records {
+			requests[i] = Request{Source: source, ID: message.MessageId, Body: json.RawMessage(message.Body)}
+		}
+		return source, requests, nil
+	}
+	return source, []Request{{Source: source, Body: payload}}, nil
+}
+
+func httpRequest(source Source, req lambdahttp.Request) Request {
+	return Request{
+		Source:   source,
+		Body:     req.Body,
+		ClientIP: req.SourceIP,
+		Locale:   req.Header.Get("Accept-Language"),
+		HTTP:     &req,
+	}
+}
+
+// header looks name up in headers whatever its case.
+func header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// Function is a handler taking In and returning Out, with its HTTP route.
+type Function[In, Out any] struct {
+	Handle func(context.Context, In) (Out, error)
+	// HTTP answers API Gateway and function URL events, with the status
+	// codes and error bodies the HTTP server uses.
+	HTTP http.Handler
+	// Prepare, when set, copies what a request knows about its caller
+	// into the decoded input.
+	Prepare func(in *In, req Request)
+}
+
+// Invoke is the Lambda handler for the function: it normalizes payload and
+// answers in the shape its source expects. Direct invokes and AppSync
+// resolvers get Out or the handler's error; an SQS batch gets the messages
+// that failed, as partial batch failures.
+func (f Function[In, Out]) Invoke(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	source, requests, err := Normalize(payload)
+	if err != nil {
+		if source == SourceAPIGateway || source == SourceHTTPAPI {
+			return badRequest(source), nil
+		}
+		return nil, apperr.Errorf(apperr.Validation, "invalid %s event: %w", source, err)
+	}
+
+	switch source {
+	case SourceAPIGateway:
+		return lambdahttp.Serve(ctx, f.HTTP, *requests[0].HTTP).APIGateway(), nil
+	case SourceHTTPAPI:
+		// A function URL takes the HTTP API response as it is.
+		return lambdahttp.Serve(ctx, f.HTTP, *requests[0].HTTP).HTTPAPI(), nil
+	case SourceSQS:
+		return f.batch(ctx, requests), nil
+	}
+	return f.handle(ctx, requests[0])
+}
+
+// batch handles the requests of an SQS batch and reports the messages
+// that failed, so only they are received again; the rest are deleted. A
+// message that keeps failing goes to the queue's dead-letter queue, whose
+// handler sorts retryable failures from those needing review. The event
+// source mapping must enable ReportBatchItemFailures.
+func (f Function[In, Out]) batch(ctx context.Context, requests []Request) events.SQSEventResponse {
+	ctx = logging.NewRequestContext(ctx, "sqs.batch")
+	response := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+	for _, req := range requests {
+		ctx := logging.WithFields(ctx, logging.Fields{"message_id": req.ID})
+		if _, err := f.handle(ctx, req); err != nil {
+			logging.FromContext(ctx).WithError(err).
+				WithField("retryable", apperr.IsRetryable(err)).
+				Warn("Queued request failed; leaving it on the queue")
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: req.ID})
+		}
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"messages": len(requests),
+		"failed":   len(response.BatchItemFailures),
+	}).Info("Handled SQS batch")
+	return response
+}
+
+// handle decodes req and runs the handler on it.
+func (f Function[In, Out]) handle(ctx context.Context, req Request) (Out, error) {
+	var in In
+	decoder := json.NewDecoder(bytes.NewReader(req.Body))
+	if err := decoder.Decode(&in); err != nil {
+		var zero Out
+		return zero, apperr.Errorf(apperr.Validation, "invalid request body: %w", err)
+	}
+	if f.Prepare != nil {
+		f.Prepare(&in, req)
+	}
+	return f.Handle(ctx, in)
+}
+
+func badRequest(source Source) interface{} {
+	if source == SourceAPIGateway {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}
+	}
+	return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusBadRequest}
+}
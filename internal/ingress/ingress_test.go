@@ -0,0 +1,188 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	apiGatewayPayload = `{"httpMethod":"POST","path":"/users","headers":{"Accept-Language":"de"},` +
+		`"requestContext":{"identity":{"sourceIp":"203.0.113.7"}},"body":"{\"handle\":\"alice\"}"}`
+	httpAPIPayload = `{"version":"2.0","rawPath":"/users","requestContext":{"http":{"method":"POST","sourceIp":"203.0.113.8"}},` +
+		`"body":"eyJoYW5kbGUiOiJhbGljZSJ9","isBase64Encoded":true}`
+	appSyncPayload = `{"arguments":{"input":{"handle":"alice"}},"identity":{"sourceIp":["203.0.113.9"]},` +
+		`"request":{"headers":{"accept-language":"pt-BR"}},"info":{"fieldName":"createUser","parentTypeName":"Mutation"}}`
+	sqsPayload = `{"Records":[{"messageId":"m1","eventSource":"aws:sqs","body":"{\"handle\":\"alice\"}"},` +
+		`{"messageId":"m2","eventSource":"aws:sqs","body":"{\"handle\":\"bob\"}"}]}`
+	directPayload = `{"handle":"alice","clientIp":"198.51.100.1"}`
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected Source
+	}{
+		{"API Gateway", apiGatewayPayload, SourceAPIGateway},
+		{"HTTP API", httpAPIPayload, SourceHTTPAPI},
+		{"AppSync", appSyncPayload, SourceAppSync},
+		{"SQS", sqsPayload, SourceSQS},
+		{"Direct", directPayload, SourceDirect},
+		{"Other Records", `{"Records":[{"eventSource":"aws:sns"}]}`, SourceDirect},
+		{"Not An Object", `"alice"`, SourceDirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Detect(json.RawMessage(tt.payload)))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected []Request
+	}{
+		{
+			name:     "AppSync",
+			payload:  appSyncPayload,
+			expected: []Request{{Source: SourceAppSync, Body: json.RawMessage(`{"handle":"alice"}`), ClientIP: "203.0.113.9", Locale: "pt-BR"}},
+		},
+		{
+			name:     "AppSync Without Input",
+			payload:  `{"arguments":{"code":"abc","did":"did:plc:alice"},"info":{"fieldName":"referralStats"}}`,
+			expected: []Request{{Source: SourceAppSync, Body: json.RawMessage(`{"code":"abc","did":"did:plc:alice"}`)}},
+		},
+		{
+			name:    "SQS",
+			payload: sqsPayload,
+			expected: []Request{
+				{Source: SourceSQS, ID: "m1", Body: json.RawMessage(`{"handle":"alice"}`)},
+				{Source: SourceSQS, ID: "m2", Body: json.RawMessage(`{"handle":"bob"}`)},
+			},
+		},
+		{
+			name:     "Direct",
+			payload:  directPayload,
+			expected: []Request{{Source: SourceDirect, Body: json.RawMessage(directPayload)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, requests, err := Normalize(json.RawMessage(tt.payload))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, requests)
+		})
+	}
+}
+
+func TestNormalizeHTTP(t *testing.T) {
+	source, requests, err := Normalize(json.RawMessage(apiGatewayPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceAPIGateway, source)
+	assert.Equal(t, `{"handle":"alice"}`, string(requests[0].Body))
+	assert.Equal(t, "203.0.113.7", requests[0].ClientIP)
+	assert.Equal(t, "de", requests[0].Locale)
+	assert.Equal(t, http.MethodPost, requests[0].HTTP.Method)
+
+	source, requests, err = Normalize(json.RawMessage(httpAPIPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceHTTPAPI, source)
+	assert.Equal(t, `{"handle":"alice"}`, string(requests[0].Body))
+	assert.Equal(t, "203.0.113.8", requests[0].ClientIP)
+}
+
+type signup struct {
+	Handle   string `json:"handle"`
+	ClientIP string `json:"clientIp"`
+}
+
+// newFunction returns a Function that records what it handled and fails
+// for the handle "bob".
+func newFunction(handled *[]signup) Function[signup, *signup] {
+	return Function[signup, *signup]{
+		Handle: func(ctx context.Context, in signup) (*signup, error) {
+			*handled = append(*handled, in)
+			if in.Handle == "bob" {
+				return nil, apperr.Errorf(apperr.Conflict, "handle taken")
+			}
+			return &in, nil
+		},
+		HTTP: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}),
+		Prepare: func(in *signup, req Request) {
+			if req.ClientIP != "" {
+				in.ClientIP = req.ClientIP
+			}
+		},
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	ctx := context.Background()
+	var handled []signup
+	f := newFunction(&handled)
+
+	resp, err := f.Invoke(ctx, json.RawMessage(directPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, &signup{Handle: "alice", ClientIP: "198.51.100.1"}, resp)
+
+	resp, err = f.Invoke(ctx, json.RawMessage(appSyncPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, &signup{Handle: "alice", ClientIP: "203.0.113.9"}, resp)
+
+	resp, err = f.Invoke(ctx, json.RawMessage(apiGatewayPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.(events.APIGatewayProxyResponse).StatusCode)
+
+	resp, err = f.Invoke(ctx, json.RawMessage(httpAPIPayload))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.(events.APIGatewayV2HTTPResponse).StatusCode)
+
+	// The HTTP events went to the HTTP route.
+	assert.Len(t, handled, 2)
+}
+
+func TestInvokeSQS(t *testing.T) {
+	var handled []signup
+	f := newFunction(&handled)
+	payload := `{"Records":[` +
+		`{"messageId":"m1","eventSource":"aws:sqs","body":"{\"handle\":\"alice\"}"},` +
+		`{"messageId":"m2","eventSource":"aws:sqs","body":"{\"handle\":\"bob\"}"},` +
+		`{"messageId":"m3","eventSource":"aws:sqs","body":"not json"},` +
+		`{"messageId":"m4","eventSource":"aws:sqs","body":"{\"handle\":\"carol\"}"}]}`
+
+	resp, err := f.Invoke(context.Background(), json.RawMessage(payload))
+
+	// One bad message doesn't stop the rest of the batch, and only the
+	// failures are received again.
+	assert.NoError(t, err)
+	assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{
+		{ItemIdentifier: "m2"},
+		{ItemIdentifier: "m3"},
+	}}, resp)
+	assert.Equal(t, []signup{{Handle: "alice"}, {Handle: "bob"}, {Handle: "carol"}}, handled)
+}
+
+func TestInvokeInvalid(t *testing.T) {
+	var handled []signup
+	f := newFunction(&handled)
+
+	_, err := f.Invoke(context.Background(), json.RawMessage(`[1, 2]`))
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+
+	resp, err := f.Invoke(context.Background(), json.RawMessage(`{"httpMethod":"POST","requestContext":{},"body":"%","isBase64Encoded":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+	assert.Empty(t, handled)
+}
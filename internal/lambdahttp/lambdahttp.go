@@ -0,0 +1,300 @@
+// Package lambdahttp serves an http.Handler from the Lambda events of HTTP
+// integrations: API Gateway REST API proxy integrations, HTTP APIs and
+// function URLs. The handlers the HTTP server runs answer them with the
+// same status codes and bodies, so the core logic has one response path
+// whichever way it is invoked.
+package lambdahttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// How a Lambda function is invoked.
+const (
+	// IntegrationAuto tells the sources apart by each event, as
+	// internal/ingress does.
+	IntegrationAuto = "auto"
+	// IntegrationDirect is a direct invoke with the request as the
+	// payload, answered with the response object or an error.
+	IntegrationDirect = "direct"
+	// IntegrationAPIGateway is an API Gateway REST API proxy integration.
+	IntegrationAPIGateway = "apigateway"
+	// IntegrationHTTPAPI is an API Gateway HTTP API with payload format
+	// 2.0.
+	IntegrationHTTPAPI = "httpapi"
+	// IntegrationFunctionURL is a Lambda function URL.
+	IntegrationFunctionURL = "url"
+)
+
+// ParseIntegration checks an integration name, such as LAMBDA_INTEGRATION.
+// Empty is IntegrationAuto.
+func ParseIntegration(integration string) (string, error) {
+	switch integration = strings.TrimSpace(integration); integration {
+	case "":
+		return IntegrationAuto, nil
+	case IntegrationAuto, IntegrationDirect, IntegrationAPIGateway, IntegrationHTTPAPI, IntegrationFunctionURL:
+		return integration, nil
+	}
+	return "", fmt.Errorf("invalid Lambda integration %q", integration)
+}
+
+// Handler returns the Lambda handler serving h for integration, which
+// must be one of the HTTP integrations.
+func Handler(integration string, h http.Handler) (interface{}, error) {
+	switch integration {
+	case IntegrationAPIGateway:
+		return APIGateway(h), nil
+	case IntegrationHTTPAPI:
+		return HTTPAPI(h), nil
+	case IntegrationFunctionURL:
+		return FunctionURL(h), nil
+	}
+	return nil, fmt.Errorf("no HTTP adapter for Lambda integration %q", integration)
+}
+
+// APIGateway serves h from API Gateway REST API proxy integration events.
+func APIGateway(h http.Handler) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		req, err := FromAPIGateway(event)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+		}
+		return Serve(ctx, h, req).APIGateway(), nil
+	}
+}
+
+// HTTPAPI serves h from API Gateway HTTP API events with payload format
+// 2.0.
+func HTTPAPI(h http.Handler) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		req, err := FromHTTPAPI(event)
+		if err != nil {
+			return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusBadRequest}, nil
+		}
+		return Serve(ctx, h, req).HTTPAPI(), nil
+	}
+}
+
+// FunctionURL serves h from function URL events.
+func FunctionURL(h http.Handler) func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	return func(ctx context.Context, event events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		req, err := FromFunctionURL(event)
+		if err != nil {
+			return events.LambdaFunctionURLResponse{StatusCode: http.StatusBadRequest}, nil
+		}
+		return Serve(ctx, h, req).FunctionURL(), nil
+	}
+}
+
+// Request is the HTTP request an event carries.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+	// SourceIP is the caller's address as the integration saw it.
+	SourceIP string
+	// Caller is the IAM ARN the integration authenticated the caller as,
+	// or empty without IAM authorization.
+	Caller string
+}
+
+// FromAPIGateway is the request of a REST API proxy integration event. It
+// fails when the body can't be decoded.
+func FromAPIGateway(event events.APIGatewayProxyRequest) (Request, error) {
+	header := http.Header{}
+	for name, value := range event.Headers {
+		header.Set(name, value)
+	}
+	for name, values := range event.MultiValueHeaders {
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	query := url.Values{}
+	for name, value := range event.QueryStringParameters {
+		query.Set(name, value)
+	}
+	for name, values := range event.MultiValueQueryStringParameters {
+		query[name] = values
+	}
+
+	body, err := decodeBody(event.Body, event.IsBase64Encoded)
+	return Request{
+		Method:   event.HTTPMethod,
+		Path:     event.Path,
+		Query:    query.Encode(),
+		Header:   header,
+		Body:     body,
+		SourceIP: event.RequestContext.Identity.SourceIP,
+		Caller:   event.RequestContext.Identity.UserArn,
+	}, err
+}
+
+// FromHTTPAPI is the request of an HTTP API event with payload format 2.0.
+func FromHTTPAPI(event events.APIGatewayV2HTTPRequest) (Request, error) {
+	body, err := decodeBody(event.Body, event.IsBase64Encoded)
+	req := Request{
+		Method:   event.RequestContext.HTTP.Method,
+		Path:     event.RawPath,
+		Query:    event.RawQueryString,
+		Header:   v2Header(event.Headers, event.Cookies),
+		Body:     body,
+		SourceIP: event.RequestContext.HTTP.SourceIP,
+	}
+	if authorizer := event.RequestContext.Authorizer; authorizer != nil && authorizer.IAM != nil {
+		req.Caller = authorizer.IAM.UserARN
+	}
+	return req, err
+}
+
+// FromFunctionURL is the request of a function URL event.
+func FromFunctionURL(event events.LambdaFunctionURLRequest) (Request, error) {
+	body, err := decodeBody(event.Body, event.IsBase64Encoded)
+	req := Request{
+		Method:   event.RequestContext.HTTP.Method,
+		Path:     event.RawPath,
+		Query:    event.RawQueryString,
+		Header:   v2Header(event.Headers, event.Cookies),
+		Body:     body,
+		SourceIP: event.RequestContext.HTTP.SourceIP,
+	}
+	if authorizer := event.RequestContext.Authorizer; authorizer != nil && authorizer.IAM != nil {
+		req.Caller = authorizer.IAM.UserARN
+	}
+	return req, err
+}
+
+func decodeBody(body string, encoded bool) ([]byte, error) {
+	if !encoded {
+		return []byte(body), nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode request body: %w", err)
+	}
+	return decoded, nil
+}
+
+// v2Header is the header of a payload format 2.0 event, whose cookies are
+// sent apart from the other headers.
+func v2Header(headers map[string]string, cookies []string) http.Header {
+	header := http.Header{}
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	if len(cookies) > 0 {
+		header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+	return header
+}
+
+// Serve runs h on req and returns what it answered. The handlers find
+// req.Caller with caller.From.
+func Serve(ctx context.Context, h http.Handler, req Request) *Response {
+	if req.Caller != "" {
+		ctx = caller.With(ctx, req.Caller)
+	}
+	path := req.Path
+	if path == "" {
+		path = "/"
+	}
+	resp := &Response{Headers: http.Header{}}
+	r, err := http.NewRequestWithContext(ctx, req.Method, path, bytes.NewReader(req.Body))
+	if err != nil {
+		resp.StatusCode = http.StatusBadRequest
+		return resp
+	}
+	r.URL.RawQuery = req.Query
+	if req.Header != nil {
+		r.Header = req.Header
+	}
+	r.Host = r.Header.Get("Host")
+	if req.SourceIP != "" {
+		// The handlers take the caller's address from RemoteAddr, as
+		// for a direct connection.
+		r.RemoteAddr = net.JoinHostPort(req.SourceIP, "0")
+	}
+
+	h.ServeHTTP(resp, r)
+	if resp.StatusCode == 0 {
+		resp.StatusCode = http.StatusOK
+	}
+	return resp
+}
+
+// Response is what a handler answered. It is an http.ResponseWriter.
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       bytes.Buffer
+}
+
+func (r *Response) APIGateway() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode:        r.StatusCode,
+		Headers:           singleValued(r.Headers),
+		MultiValueHeaders: r.Headers,
+		Body:              r.Body.String(),
+	}
+}
+
+func (r *Response) HTTPAPI() events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: r.StatusCode,
+		Headers:    singleValued(r.Headers),
+		Body:       r.Body.String(),
+		Cookies:    r.Headers.Values("Set-Cookie"),
+	}
+}
+
+func (r *Response) FunctionURL() events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{
+		StatusCode: r.StatusCode,
+		Headers:    singleValued(r.Headers),
+		Body:       r.Body.String(),
+		Cookies:    r.Headers.Values("Set-Cookie"),
+	}
+}
+
+// singleValued joins repeated headers with commas, except Set-Cookie,
+// which can't be joined and is only kept in multi-value fields.
+func singleValued(header http.Header) map[string]string {
+	single := make(map[string]string, len(header))
+	for name, values := range header {
+		if name == "Set-Cookie" {
+			continue
+		}
+		single[name] = strings.Join(values, ",")
+	}
+	return single
+}
+
+func (r *Response) Header() http.Header {
+	return r.Headers
+}
+
+func (r *Response) WriteHeader(status int) {
+	if r.StatusCode == 0 {
+		r.StatusCode = status
+	}
+}
+
+func (r *Response) Write(p []byte) (int, error) {
+	if r.StatusCode == 0 {
+		r.StatusCode = http.StatusOK
+	}
+	return r.Body.Write(p)
+}
@@ -0,0 +1,143 @@
+package lambdahttp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/caller"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// echo answers with what it was sent, and 201 for POST.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	w.WriteHeader(status)
+	identity, _ := caller.From(r.Context())
+	json.NewEncoder(w).Encode(map[string]string{
+		"method":   r.Method,
+		"path":     r.URL.Path,
+		"query":    r.URL.RawQuery,
+		"body":     string(body),
+		"remote":   r.RemoteAddr,
+		"language": r.Header.Get("Accept-Language"),
+		"cookie":   r.Header.Get("Cookie"),
+		"caller":   identity,
+	})
+})
+
+func decode(t *testing.T, body string) map[string]string {
+	var fields map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(body), &fields))
+	return fields
+}
+
+func TestAPIGateway(t *testing.T) {
+	resp, err := APIGateway(echo)(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:                      http.MethodPost,
+		Path:                            "/users",
+		Headers:                         map[string]string{"accept-language": "pt-BR"},
+		MultiValueQueryStringParameters: map[string][]string{"dryRun": {"true"}},
+		Body:                            base64.StdEncoding.EncodeToString([]byte(`{"handle":"alice"}`)),
+		IsBase64Encoded:                 true,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{SourceIP: "203.0.113.7", UserArn: "arn:aws:iam::123456789012:user/ops"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+	assert.NotContains(t, resp.Headers, "Set-Cookie")
+	assert.Equal(t, []string{"a=1", "b=2"}, resp.MultiValueHeaders["Set-Cookie"])
+	assert.Equal(t, map[string]string{
+		"method":   http.MethodPost,
+		"path":     "/users",
+		"query":    "dryRun=true",
+		"body":     `{"handle":"alice"}`,
+		"remote":   "203.0.113.7:0",
+		"language": "pt-BR",
+		"cookie":   "",
+		"caller":   "arn:aws:iam::123456789012:user/ops",
+	}, decode(t, resp.Body))
+}
+
+func TestAPIGatewayInvalidBody(t *testing.T) {
+	resp, err := APIGateway(echo)(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:      http.MethodPost,
+		Body:            "not base64!",
+		IsBase64Encoded: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHTTPAPI(t *testing.T) {
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath:        "/claims",
+		RawQueryString: "a=b",
+		Headers:        map[string]string{"accept-language": "de"},
+		Cookies:        []string{"session=1", "theme=dark"},
+		Body:           `{}`,
+	}
+	event.RequestContext.HTTP.Method = http.MethodGet
+	event.RequestContext.HTTP.SourceIP = "2001:db8::1"
+	event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+		IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{UserARN: "arn:aws:iam::123456789012:role/ops"},
+	}
+
+	resp, err := HTTPAPI(echo)(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"a=1", "b=2"}, resp.Cookies)
+	fields := decode(t, resp.Body)
+	assert.Equal(t, "[2001:db8::1]:0", fields["remote"])
+	assert.Equal(t, "session=1; theme=dark", fields["cookie"])
+	assert.Equal(t, "a=b", fields["query"])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ops", fields["caller"])
+}
+
+func TestFunctionURL(t *testing.T) {
+	event := events.LambdaFunctionURLRequest{Body: `{"did":"did:plc:alice"}`}
+	event.RequestContext.HTTP.Method = http.MethodPost
+	event.RequestContext.HTTP.SourceIP = "198.51.100.2"
+
+	resp, err := FunctionURL(echo)(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	fields := decode(t, resp.Body)
+	assert.Equal(t, "/", fields["path"])
+	assert.Equal(t, `{"did":"did:plc:alice"}`, fields["body"])
+	assert.Equal(t, "198.51.100.2:0", fields["remote"])
+	assert.Empty(t, fields["caller"], "without IAM authorization the caller is unknown")
+}
+
+func TestParseIntegration(t *testing.T) {
+	integration, err := ParseIntegration("")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationAuto, integration)
+
+	integration, err = ParseIntegration(" httpapi ")
+	assert.NoError(t, err)
+	assert.Equal(t, IntegrationHTTPAPI, integration)
+
+	_, err = ParseIntegration("alb")
+	assert.EqualError(t, err, `invalid Lambda integration "alb"`)
+
+	_, err = Handler(IntegrationDirect, echo)
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+// Package lifecycle moves accounts through their statuses. New accounts
+// start pending, become verified once the holder confirms their email
+// address and active once onboarding is done; accounts held for review must
+// be approved back to pending first, or are rejected. Operators can suspend
+// any account that isn't erased or rejected. Every other move is refused,
+// and hooks registered for a status run after an account enters it, to send
+// email or announce the change.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// Events that move an account between statuses.
+const (
+	EventVerify   = "verify"
+	EventActivate = "activate"
+	EventApprove  = "approve"
+	EventReject   = "reject"
+	EventSuspend  = "suspend"
+)
+
+// transitions maps each event to the status it moves an account to, by the
+// status the account is in.
+var transitions = map[string]map[string]string{
+	EventVerify:   {models.StatusPending: models.StatusVerified},
+	EventActivate: {models.StatusVerified: models.StatusActive},
+	EventApprove:  {models.StatusPendingReview: models.StatusPending},
+	EventReject:   {models.StatusPendingReview: models.StatusRejected},
+	EventSuspend: {
+		models.StatusPending:       models.StatusSuspended,
+		models.StatusPendingReview: models.StatusSuspended,
+		models.StatusVerified:      models.StatusSuspended,
+		models.StatusActive:        models.StatusSuspended,
+	},
+}
+
+// auditEvents names the audit trail entry recorded on entering a status.
+var auditEvents = map[string]string{
+	models.StatusPending:   postgres.AuditAccountApproved,
+	models.StatusVerified:  postgres.AuditAccountVerified,
+	models.StatusActive:    postgres.AuditAccountActivated,
+	models.StatusSuspended: postgres.AuditAccountSuspended,
+	models.StatusRejected:  postgres.AuditAccountRejected,
+}
+
+// Next returns the status event moves an account in from to, and false if
+// the event isn't allowed from there.
+func Next(event, from string) (string, bool) {
+	to, ok := transitions[event][from]
+	return to, ok
+}
+
+// Verified reports whether an account in status has confirmed its email
+// address.
+func Verified(status string) bool {
+	return status == models.StatusVerified || status == models.StatusActive
+}
+
+// Transition is one move of an account between statuses. Account is the
+// stored account as of the move.
+type Transition struct {
+	Event   string
+	From    string
+	To      string
+	Actor   string
+	Account models.UserRecord
+}
+
+// Hook runs after an account enters a status. The move is already stored,
+// so a failing hook is logged rather than undoing it.
+type Hook func(ctx context.Context, t Transition) error
+
+// Machine applies events to stored accounts.
+type Machine struct {
+	Accounts postgres.AccountStatusStore
+	Audit    postgres.AuditStore
+	hooks    map[string][]Hook
+}
+
+func New(accounts postgres.AccountStatusStore, audit postgres.AuditStore) *Machine {
+	return &Machine{Accounts: accounts, Audit: audit, hooks: map[string][]Hook{}}
+}
+
+// OnEnter registers hook to run, after those already registered, whenever
+// an account enters status.
+func (m *Machine) OnEnter(status string, hook Hook) {
+	m.hooks[status] = append(m.hooks[status], hook)
+}
+
+// Fire applies event to the account. It reports false, without running
+// any hooks, when the account is already in the status the event leads
+// to, so a retried event is harmless.
+func (m *Machine) Fire(ctx context.Context, did, event, actor string) (Transition, bool, error) {
+	if _, ok := transitions[event]; !ok {
+		return Transition{}, false, apperr.Errorf(apperr.Validation, "validation error: unknown lifecycle event %q", event)
+	}
+
+	account, err := m.Accounts.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return Transition{}, false, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return Transition{}, false, fmt.Errorf("internal error: could not load account: %w", err)
+	}
+
+	transition := Transition{
+		Event:   event,
+		From:    account.Status,
+		Actor:   actor,
+		Account: account,
+	}
+
+	to, ok := Next(event, account.Status)
+	if !ok {
+		if leadsTo(event, account.Status) {
+			transition.To = account.Status
+			return transition, false, nil
+		}
+		return Transition{}, false, apperr.Errorf(apperr.Conflict, "conflict: cannot %s an account that is %s", event, account.Status)
+	}
+	transition.To = to
+
+	verified := Verified(to)
+	if to == models.StatusSuspended {
+		// Suspension doesn't undo the holder's confirmation of their
+		// address.
+		verified = account.Verified
+	}
+	if err := m.Accounts.TransitionStatus(ctx, did, account.Status, to, verified); err != nil {
+		if errors.Is(err, postgres.ErrStatusChanged) {
+			return Transition{}, false, apperr.Errorf(apperr.Conflict, "conflict: %w", err)
+		}
+		return Transition{}, false, fmt.Errorf("internal error: could not change account status: %w", err)
+	}
+	transition.Account.Status, transition.Account.Verified = to, verified
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":   did,
+		"event": event,
+		"from":  transition.From,
+		"to":    to,
+	})
+	log.Info("Account status changed")
+
+	if err := m.Audit.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:   auditEvents[to],
+		DID:     did,
+		Handle:  account.Handle,
+		Actor:   actor,
+		Details: map[string]string{"from": transition.From, "to": to},
+	}); err != nil {
+		log.WithError(err).Error("Account status changed without an audit event")
+	}
+
+	for _, hook := range m.hooks[to] {
+		if err := hook(ctx, transition); err != nil {
+			log.WithError(err).Warn("Lifecycle hook failed")
+		}
+	}
+
+	return transition, true, nil
+}
+
+// leadsTo reports whether event moves some account into status.
+func leadsTo(event, status string) bool {
+	for _, to := range transitions[event] {
+		if to == status {
+			return true
+		}
+	}
+	return false
+}
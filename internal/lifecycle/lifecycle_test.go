@@ -0,0 +1,201 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAccounts struct {
+	mock.Mock
+}
+
+func (m *mockAccounts) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.UserRecord), args.Error(1)
+}
+
+func (m *mockAccounts) TransitionStatus(ctx context.Context, did, from, to string, verified bool) error {
+	return m.Called(ctx, did, from, to, verified).Error(0)
+}
+
+type mockAudit struct {
+	mock.Mock
+}
+
+func (m *mockAudit) RecordAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		event    string
+		from     string
+		expected string
+		allowed  bool
+	}{
+		{event: EventVerify, from: models.StatusPending, expected: models.StatusVerified, allowed: true},
+		{event: EventActivate, from: models.StatusVerified, expected: models.StatusActive, allowed: true},
+		{event: EventApprove, from: models.StatusPendingReview, expected: models.StatusPending, allowed: true},
+		{event: EventReject, from: models.StatusPendingReview, expected: models.StatusRejected, allowed: true},
+		{event: EventReject, from: models.StatusPending},
+		{event: EventApprove, from: models.StatusRejected},
+		{event: EventActivate, from: models.StatusPending},
+		{event: EventVerify, from: models.StatusPendingReview},
+		{event: EventVerify, from: models.StatusErased},
+		{event: EventSuspend, from: models.StatusActive, expected: models.StatusSuspended, allowed: true},
+		{event: EventSuspend, from: models.StatusPendingReview, expected: models.StatusSuspended, allowed: true},
+		{event: EventSuspend, from: models.StatusErased},
+		{event: "reinstate", from: models.StatusSuspended},
+	}
+
+	for _, test := range tests {
+		t.Run(test.event+" from "+test.from, func(t *testing.T) {
+			to, ok := Next(test.event, test.from)
+			assert.Equal(t, test.allowed, ok)
+			assert.Equal(t, test.expected, to)
+		})
+	}
+}
+
+func TestFire(t *testing.T) {
+	ctx := context.Background()
+	pending := models.UserRecord{DID: "did:plc:alice", Handle: "alice.shareframe.social", Status: models.StatusPending}
+
+	tests := []struct {
+		name            string
+		event           string
+		account         models.UserRecord
+		loadErr         error
+		transitionErr   error
+		auditErr        error
+		expectedTo      string
+		expectedChanged bool
+		expectedHooks   int
+		expectedErr     apperr.Category
+	}{
+		{
+			name:            "Verified",
+			event:           EventVerify,
+			account:         pending,
+			expectedTo:      models.StatusVerified,
+			expectedChanged: true,
+			expectedHooks:   1,
+		},
+		{
+			name:            "Audit Failure Still Runs Hooks",
+			event:           EventVerify,
+			account:         pending,
+			auditErr:        errors.New("DB connection failed"),
+			expectedTo:      models.StatusVerified,
+			expectedChanged: true,
+			expectedHooks:   1,
+		},
+		{
+			name:       "Already Verified",
+			event:      EventVerify,
+			account:    models.UserRecord{DID: "did:plc:alice", Status: models.StatusVerified},
+			expectedTo: models.StatusVerified,
+		},
+		{
+			name:        "Not Allowed",
+			event:       EventActivate,
+			account:     pending,
+			expectedErr: apperr.Conflict,
+		},
+		{
+			name:          "Raced",
+			event:         EventVerify,
+			account:       pending,
+			transitionErr: postgres.ErrStatusChanged,
+			expectedErr:   apperr.Conflict,
+		},
+		{
+			name:        "Unknown Account",
+			event:       EventVerify,
+			loadErr:     postgres.ErrUserNotFound,
+			expectedErr: apperr.NotFound,
+		},
+		{
+			name:        "Unknown Event",
+			event:       "reinstate",
+			expectedErr: apperr.Validation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			accounts := new(mockAccounts)
+			audit := new(mockAudit)
+			accounts.On("GetUser", ctx, "did:plc:alice").Return(test.account, test.loadErr)
+			accounts.On("TransitionStatus", ctx, "did:plc:alice", test.account.Status, mock.Anything, mock.Anything).Return(test.transitionErr)
+			audit.On("RecordAuditEvent", ctx, mock.Anything).Return(test.auditErr)
+
+			machine := New(accounts, audit)
+			var entered []Transition
+			machine.OnEnter(models.StatusVerified, func(ctx context.Context, transition Transition) error {
+				entered = append(entered, transition)
+				return errors.New("SNS unavailable")
+			})
+			machine.OnEnter(models.StatusActive, func(ctx context.Context, transition Transition) error {
+				t.Error("hook for another status ran")
+				return nil
+			})
+
+			transition, changed, err := machine.Fire(ctx, "did:plc:alice", test.event, "self")
+
+			if test.expectedErr != "" {
+				assert.Equal(t, test.expectedErr, apperr.CategoryOf(err))
+				if test.transitionErr == nil {
+					accounts.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				}
+				audit.AssertNotCalled(t, "RecordAuditEvent", mock.Anything, mock.Anything)
+				assert.Empty(t, entered)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedChanged, changed)
+			assert.Equal(t, test.account.Status, transition.From)
+			assert.Equal(t, test.expectedTo, transition.To)
+			assert.Len(t, entered, test.expectedHooks)
+			if !changed {
+				accounts.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			accounts.AssertCalled(t, "TransitionStatus", ctx, "did:plc:alice", models.StatusPending, models.StatusVerified, true)
+			assert.True(t, entered[0].Account.Verified)
+			audit.AssertCalled(t, "RecordAuditEvent", ctx, mock.MatchedBy(func(event models.AuditEvent) bool {
+				return event.Event == postgres.AuditAccountVerified && event.Actor == "self" &&
+					event.Details["from"] == models.StatusPending && event.Details["to"] == models.StatusVerified
+			}))
+		})
+	}
+}
+
+func TestFireSuspendKeepsVerified(t *testing.T) {
+	ctx := context.Background()
+	accounts := new(mockAccounts)
+	audit := new(mockAudit)
+	accounts.On("GetUser", ctx, "did:plc:alice").Return(models.UserRecord{DID: "did:plc:alice", Status: models.StatusActive, Verified: true}, nil)
+	accounts.On("TransitionStatus", ctx, "did:plc:alice", models.StatusActive, models.StatusSuspended, true).Return(nil)
+	audit.On("RecordAuditEvent", ctx, mock.MatchedBy(func(event models.AuditEvent) bool {
+		return event.Event == postgres.AuditAccountSuspended && event.Actor == "ops@shareframe.social"
+	})).Return(nil)
+
+	transition, changed, err := New(accounts, audit).Fire(ctx, "did:plc:alice", EventSuspend, "ops@shareframe.social")
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.StatusSuspended, transition.To)
+	assert.True(t, transition.Account.Verified)
+	accounts.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
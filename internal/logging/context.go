@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Fields every request logger carries, so lines from any package can be
+// correlated.
+const (
+	FieldRequestID  = "request_id"
+	FieldOperation  = "operation"
+	FieldTenant     = "tenant"
+	FieldHandleHash = "handle_hash"
+)
+
+type loggerKey struct{}
+
+// FromContext returns the request logger carried by ctx, or Default
+// outside a request.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return logger
+	}
+	return Default()
+}
+
+// WithFields returns a context whose logger adds fields to the one already
+// in ctx.
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithFields(fields))
+}
+
+// NewRequestContext starts the logger for one request. The request ID is
+// Lambda's when running under Lambda and a random one otherwise. Requests
+// picked by debug sampling log at debug level. Inside a request already
+// started, as when one handler runs another, only the operation changes.
+func NewRequestContext(ctx context.Context, operation string) context.Context {
+	if RequestID(ctx) != "" {
+		return WithFields(ctx, Fields{FieldOperation: operation})
+	}
+
+	requestID := ""
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		requestID = lc.AwsRequestID
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+
+	fields := Fields{
+		FieldRequestID: requestID,
+		FieldOperation: operation,
+	}
+	logger, debug := requestLogger()
+	if debug {
+		fields[FieldDebugSampled] = true
+	}
+	return WithLogger(ctx, newLogger(logger, fields))
+}
+
+// RequestID returns the ID of the request in ctx, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := FromContext(ctx).Fields()[FieldRequestID].(string)
+	return id
+}
+
+// WithTenant adds the tenant to the request logger.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return WithFields(ctx, Fields{FieldTenant: tenant})
+}
+
+// WithHandle adds a hash of handle to the request logger. The hash lets
+// every line about one signup be found without logging the handle itself.
+func WithHandle(ctx context.Context, handle string) context.Context {
+	if handle == "" {
+		return ctx
+	}
+	return WithFields(ctx, Fields{FieldHandleHash: HashValue(handle)})
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		Default().WithError(err).Warn("Failed to generate request id")
+	}
+	return hex.EncodeToString(b[:])
+}
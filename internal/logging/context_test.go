@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	entry := FromContext(context.Background())
+
+	assert.NotNil(t, entry)
+	assert.Empty(t, entry.Fields())
+	assert.Empty(t, RequestID(context.Background()))
+}
+
+func TestNewRequestContext(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		wantRequestID string
+	}{
+		{
+			name:          "Lambda Request ID",
+			ctx:           lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-123"}),
+			wantRequestID: "req-123",
+		},
+		{
+			name: "Generated Request ID",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewRequestContext(tt.ctx, "create_account")
+			data := FromContext(ctx).Fields()
+
+			assert.Equal(t, "create_account", data[FieldOperation])
+			assert.Equal(t, data[FieldRequestID], RequestID(ctx))
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, data[FieldRequestID])
+			} else {
+				assert.Len(t, data[FieldRequestID], 32)
+			}
+		})
+	}
+}
+
+func TestRequestFieldsAccumulate(t *testing.T) {
+	ctx := NewRequestContext(context.Background(), "blocklist.add")
+	ctx = WithTenant(ctx, "default")
+	ctx = WithHandle(ctx, "alice")
+	ctx = WithHandle(ctx, "")
+	ctx = WithFields(ctx, logrus.Fields{"step": "store"})
+
+	data := FromContext(ctx).Fields()
+	assert.Equal(t, "blocklist.add", data[FieldOperation])
+	assert.Equal(t, "default", data[FieldTenant])
+	assert.Equal(t, HashValue("alice"), data[FieldHandleHash])
+	assert.Equal(t, "store", data["step"])
+}
+
+func TestNewRequestContextNested(t *testing.T) {
+	outer := NewRequestContext(context.Background(), "dlq.redrive")
+	outer = WithFields(outer, logrus.Fields{"message_id": "msg-1"})
+
+	inner := NewRequestContext(outer, "create_account")
+
+	data := FromContext(inner).Fields()
+	assert.Equal(t, RequestID(outer), data[FieldRequestID])
+	assert.Equal(t, "create_account", data[FieldOperation])
+	assert.Equal(t, "msg-1", data["message_id"])
+}
+
+func TestWithFieldsDoesNotChangeParent(t *testing.T) {
+	parent := NewRequestContext(context.Background(), "create_account")
+	_ = WithTenant(parent, "default")
+
+	assert.NotContains(t, FromContext(parent).Fields(), FieldTenant)
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldDebugSampled marks the lines of a request chosen for debug logging,
+// so sampled requests can be found and counted.
+const FieldDebugSampled = "debug_sampled"
+
+var (
+	debugSampleRate float64
+	// sampled decides whether one request is logged at debug level.
+	sampled = func(rate float64) bool { return rand.Float64() < rate }
+)
+
+// Configure sets the log level from LOG_LEVEL (default info) and the share
+// of requests, between 0 and 1, that log at debug level whatever the level
+// from LOG_DEBUG_SAMPLE_RATE. Sampling keeps the debug output of a few full
+// requests without flooding production with it.
+func Configure(level, sampleRate string) error {
+	parsedLevel := logrus.InfoLevel
+	if level = strings.TrimSpace(level); level != "" {
+		var err error
+		if parsedLevel, err = logrus.ParseLevel(level); err != nil {
+			return fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	rate := 0.0
+	if sampleRate = strings.TrimSpace(sampleRate); sampleRate != "" {
+		var err error
+		if rate, err = strconv.ParseFloat(sampleRate, 64); err != nil {
+			return fmt.Errorf("invalid debug sample rate %q: %w", sampleRate, err)
+		}
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid debug sample rate %q: must be between 0 and 1", sampleRate)
+		}
+	}
+
+	logrus.SetLevel(parsedLevel)
+	debugSampleRate = rate
+	return nil
+}
+
+// requestLogger returns the logger for a new request: the standard logger,
+// or for a sampled request a copy of it that logs at debug level.
+func requestLogger() (*logrus.Logger, bool) {
+	std := logrus.StandardLogger()
+	if debugSampleRate <= 0 || std.IsLevelEnabled(logrus.DebugLevel) || !sampled(debugSampleRate) {
+		return std, false
+	}
+	return &logrus.Logger{
+		Out:          std.Out,
+		Hooks:        std.Hooks,
+		Formatter:    std.Formatter,
+		ReportCaller: std.ReportCaller,
+		Level:        logrus.DebugLevel,
+		ExitFunc:     std.ExitFunc,
+	}, true
+}
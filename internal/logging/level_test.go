@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func restoreLogging(t *testing.T) {
+	level, rate, sample := logrus.GetLevel(), debugSampleRate, sampled
+	t.Cleanup(func() {
+		logrus.SetLevel(level)
+		debugSampleRate, sampled = rate, sample
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      string
+		sampleRate string
+		wantLevel  logrus.Level
+		wantRate   float64
+		wantErr    string
+	}{
+		{name: "Defaults", wantLevel: logrus.InfoLevel},
+		{name: "Level And Rate", level: "warn", sampleRate: "0.01", wantLevel: logrus.WarnLevel, wantRate: 0.01},
+		{name: "Uppercase Level", level: " DEBUG ", wantLevel: logrus.DebugLevel},
+		{name: "Unknown Level", level: "verbose", wantErr: "invalid log level"},
+		{name: "Rate Not A Number", sampleRate: "1%", wantErr: "invalid debug sample rate"},
+		{name: "Rate Out Of Range", sampleRate: "1.5", wantErr: "must be between 0 and 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreLogging(t)
+
+			err := Configure(tt.level, tt.sampleRate)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantLevel, logrus.GetLevel())
+			assert.Equal(t, tt.wantRate, debugSampleRate)
+		})
+	}
+}
+
+func TestNewRequestContextDebugSampling(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		rate      string
+		sampled   bool
+		wantDebug bool
+	}{
+		{name: "Sampled Request", level: "info", rate: "0.01", sampled: true, wantDebug: true},
+		{name: "Unsampled Request", level: "info", rate: "0.01", sampled: false},
+		{name: "Sampling Off", level: "info", sampled: true},
+		{name: "Debug Already Enabled", level: "debug", rate: "0.01", sampled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreLogging(t)
+			assert.NoError(t, Configure(tt.level, tt.rate))
+			sampled = func(float64) bool { return tt.sampled }
+
+			entry := FromContext(NewRequestContext(context.Background(), "create_account")).(*logrusLogger).entry
+
+			assert.Equal(t, tt.wantDebug || tt.level == "debug", entry.Logger.IsLevelEnabled(logrus.DebugLevel))
+			if tt.wantDebug {
+				assert.Equal(t, true, entry.Data[FieldDebugSampled])
+				assert.NotSame(t, logrus.StandardLogger(), entry.Logger)
+			} else {
+				assert.NotContains(t, entry.Data, FieldDebugSampled)
+				assert.Same(t, logrus.StandardLogger(), entry.Logger)
+			}
+		})
+	}
+}
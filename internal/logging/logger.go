@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Fields are the structured fields of a log line. logrus.Fields literals
+// are accepted as well.
+type Fields = map[string]interface{}
+
+// Logger is what the service's packages log through. FromContext returns
+// the request's Logger, or one can be put on a context with WithLogger.
+// There is no Fatal: library code returns errors and leaves exiting to
+// main.
+type Logger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields Fields) Logger
+	WithError(err error) Logger
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	// Fields returns the fields added to the logger so far.
+	Fields() Fields
+}
+
+const (
+	BackendLogrus = "logrus"
+	BackendSlog   = "slog"
+)
+
+// ConfigureBackend picks where log lines go from LOG_BACKEND: logrus
+// (default) or slog, which writes JSON lines to stdout.
+func ConfigureBackend(backend string) error {
+	switch strings.TrimSpace(backend) {
+	case "", BackendLogrus:
+		slogHandler.Store(nil)
+	case BackendSlog:
+		UseSlog(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	default:
+		return fmt.Errorf("invalid log backend %q", backend)
+	}
+	return nil
+}
+
+// slogHandler, when set by UseSlog, receives log lines in place of logrus.
+var slogHandler atomic.Pointer[slog.Handler]
+
+// UseSlog sends log lines to handler instead of logrus's standard logger.
+// The level and debug sampling from Configure still decide which lines
+// are logged, and the hooks added to logrus, such as the scrubber and the
+// redactor, still run on every line first, so handler should accept debug
+// records.
+func UseSlog(handler slog.Handler) {
+	slogHandler.Store(&handler)
+}
+
+// WithLogger returns a context carrying logger, for code that brings its
+// own.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Default returns the logger for lines logged outside a request.
+func Default() Logger {
+	return newLogger(logrus.StandardLogger(), nil)
+}
+
+// newLogger returns a Logger on the configured backend that logs at the
+// level of logger.
+func newLogger(logger *logrus.Logger, fields Fields) Logger {
+	if handler := slogHandler.Load(); handler != nil {
+		return &slogLogger{logger: slog.New(*handler), level: logger, fields: copyFields(fields)}
+	}
+	return &logrusLogger{entry: logrus.NewEntry(logger).WithFields(fields)}
+}
+
+func copyFields(fields Fields) Fields {
+	copied := make(Fields, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+	return copied
+}
+
+// logrusLogger is the Logger on logrus.
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+func (l *logrusLogger) WithField(key string, value interface{}) Logger {
+	return &logrusLogger{entry: l.entry.WithField(key, value)}
+}
+
+func (l *logrusLogger) WithFields(fields Fields) Logger {
+	return &logrusLogger{entry: l.entry.WithFields(fields)}
+}
+
+func (l *logrusLogger) WithError(err error) Logger {
+	return &logrusLogger{entry: l.entry.WithError(err)}
+}
+
+func (l *logrusLogger) Debug(args ...interface{}) { l.entry.Debug(args...) }
+func (l *logrusLogger) Info(args ...interface{})  { l.entry.Info(args...) }
+func (l *logrusLogger) Warn(args ...interface{})  { l.entry.Warn(args...) }
+func (l *logrusLogger) Error(args ...interface{}) { l.entry.Error(args...) }
+
+func (l *logrusLogger) Debugf(format string, args ...interface{}) { l.entry.Debugf(format, args...) }
+func (l *logrusLogger) Infof(format string, args ...interface{})  { l.entry.Infof(format, args...) }
+func (l *logrusLogger) Warnf(format string, args ...interface{})  { l.entry.Warnf(format, args...) }
+func (l *logrusLogger) Errorf(format string, args ...interface{}) { l.entry.Errorf(format, args...) }
+
+func (l *logrusLogger) Fields() Fields {
+	return copyFields(l.entry.Data)
+}
+
+// slogLogger is the Logger on log/slog. Fields are kept until a line is
+// logged so the logrus hooks can rewrite them.
+type slogLogger struct {
+	logger *slog.Logger
+	// level decides which lines are logged and holds the hooks to run.
+	level  *logrus.Logger
+	fields Fields
+}
+
+func (l *slogLogger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+func (l *slogLogger) WithFields(fields Fields) Logger {
+	merged := copyFields(l.fields)
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &slogLogger{logger: l.logger, level: l.level, fields: merged}
+}
+
+func (l *slogLogger) WithError(err error) Logger {
+	return l.WithField(logrus.ErrorKey, err)
+}
+
+func (l *slogLogger) Debug(args ...interface{}) { l.log(logrus.DebugLevel, fmt.Sprint(args...)) }
+func (l *slogLogger) Info(args ...interface{})  { l.log(logrus.InfoLevel, fmt.Sprint(args...)) }
+func (l *slogLogger) Warn(args ...interface{})  { l.log(logrus.WarnLevel, fmt.Sprint(args...)) }
+func (l *slogLogger) Error(args ...interface{}) { l.log(logrus.ErrorLevel, fmt.Sprint(args...)) }
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.log(logrus.DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.log(logrus.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.log(logrus.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.log(logrus.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Fields() Fields {
+	return copyFields(l.fields)
+}
+
+// slogLevels maps the levels the Logger logs at to slog's.
+var slogLevels = map[logrus.Level]slog.Level{
+	logrus.DebugLevel: slog.LevelDebug,
+	logrus.InfoLevel:  slog.LevelInfo,
+	logrus.WarnLevel:  slog.LevelWarn,
+	logrus.ErrorLevel: slog.LevelError,
+}
+
+func (l *slogLogger) log(level logrus.Level, message string) {
+	if !l.level.IsLevelEnabled(level) {
+		return
+	}
+
+	entry := &logrus.Entry{Logger: l.level, Data: copyFields(l.fields), Level: level, Message: message}
+	if err := l.level.Hooks.Fire(level, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fire log hook: %v\n", err)
+	}
+
+	attrs := make([]slog.Attr, 0, len(entry.Data))
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	l.logger.LogAttrs(context.Background(), slogLevels[level], entry.Message, attrs...)
+}
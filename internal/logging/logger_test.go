@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// useSlog routes log lines to a JSON handler writing to the returned buffer
+// until the test ends.
+func useSlog(t *testing.T) *bytes.Buffer {
+	var out bytes.Buffer
+	UseSlog(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	t.Cleanup(func() { slogHandler.Store(nil) })
+	return &out
+}
+
+func TestSlogLogger(t *testing.T) {
+	out := useSlog(t)
+	scrubber := NewScrubber()
+	scrubber.Register("hunter2-password")
+	level := &logrus.Logger{Level: logrus.InfoLevel, Hooks: logrus.LevelHooks{}}
+	level.AddHook(scrubber)
+	level.AddHook(NewRedactor(nil, nil))
+
+	logger := newLogger(level, Fields{FieldRequestID: "req-1"})
+	logger.WithField("email", "alice@example.com").
+		WithError(errors.New("login failed for hunter2-password")).
+		Warn("Sign-in rejected")
+	logger.Debug("Not logged at info")
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "Sign-in rejected", line["msg"])
+	assert.Equal(t, "req-1", line[FieldRequestID])
+	assert.Equal(t, "a***@example.com", line["email"])
+	assert.Equal(t, "login failed for "+Redacted, line["error"])
+}
+
+func TestSlogLoggerFields(t *testing.T) {
+	useSlog(t)
+
+	ctx := NewRequestContext(context.Background(), "create_account")
+	ctx = WithTenant(ctx, "shareframe")
+
+	assert.IsType(t, &slogLogger{}, FromContext(ctx))
+	assert.NotEmpty(t, RequestID(ctx))
+	assert.Equal(t, "shareframe", FromContext(ctx).Fields()[FieldTenant])
+}
+
+func TestConfigureBackend(t *testing.T) {
+	t.Cleanup(func() { slogHandler.Store(nil) })
+
+	assert.NoError(t, ConfigureBackend("slog"))
+	assert.IsType(t, &slogLogger{}, Default())
+	assert.NoError(t, ConfigureBackend(""))
+	assert.IsType(t, &logrusLogger{}, Default())
+	assert.Error(t, ConfigureBackend("zap"))
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := Default().WithField("job", "repair")
+	ctx := WithLogger(context.Background(), logger)
+
+	assert.Same(t, logger, FromContext(ctx))
+}
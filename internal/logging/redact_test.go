@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(r *Redactor) (*logrus.Logger, *bytes.Buffer) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(r)
+	return logger, &out
+}
+
+func decode(t *testing.T, out *bytes.Buffer) map[string]interface{} {
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	return line
+}
+
+func TestRedactorDefaultFields(t *testing.T) {
+	logger, out := newTestLogger(NewRedactor(nil, nil))
+
+	logger.WithFields(logrus.Fields{
+		"email":       "alice@example.com",
+		"handle":      "alice.shareframe.social",
+		"phone":       "+15555550100",
+		"invite_code": "shareframe-app-abcde-fghij",
+		"headers":     map[string]string{"Authorization": "Bearer token"},
+		"tenant":      "acme",
+	}).Info("Processing create account request")
+
+	line := decode(t, out)
+	assert.Equal(t, "a***@example.com", line["email"])
+	assert.Equal(t, HashValue("alice.shareframe.social"), line["handle"])
+	assert.Equal(t, HashValue("+15555550100"), line["phone"])
+	assert.Equal(t, redacted, line["invite_code"])
+	assert.Equal(t, redacted, line["headers"])
+	assert.Equal(t, "acme", line["tenant"])
+}
+
+func TestRedactorMasksEmailsInMessagesAndErrors(t *testing.T) {
+	logger, out := newTestLogger(NewRedactor(nil, nil))
+
+	logger.WithError(errors.New("duplicate key for bob@example.org")).
+		Errorf("Failed to notify %s", "carol@example.net")
+
+	line := decode(t, out)
+	assert.Equal(t, "duplicate key for b***@example.org", line["error"])
+	assert.Equal(t, "Failed to notify c***@example.net", line["msg"])
+}
+
+func TestRedactorExtraAndAllowedFields(t *testing.T) {
+	logger, out := newTestLogger(NewRedactor(ParseFieldList(" postcode, ,address"), ParseFieldList("handle")))
+
+	logger.WithFields(logrus.Fields{
+		"postcode": "SW1A 1AA",
+		"handle":   "alice",
+	}).Info("Updated profile")
+
+	line := decode(t, out)
+	assert.Equal(t, HashValue("SW1A 1AA"), line["postcode"])
+	assert.Equal(t, "alice", line["handle"])
+}
+
+func TestRedactorLeavesCallerEntryUntouched(t *testing.T) {
+	logger, _ := newTestLogger(NewRedactor(nil, nil))
+
+	entry := logger.WithField("email", "alice@example.com")
+	entry.Info("first")
+
+	assert.Equal(t, "alice@example.com", entry.Data["email"])
+}
+
+func TestMaskValue(t *testing.T) {
+	assert.Equal(t, "a***@example.com", MaskValue("alice@example.com"))
+	assert.Equal(t, "é***", MaskValue("élodie"))
+	assert.Equal(t, "***@example.com", MaskValue("@example.com"))
+}
+
+func TestInstallHooks(t *testing.T) {
+	std := logrus.StandardLogger()
+	hooks, out, formatter := std.ReplaceHooks(logrus.LevelHooks{}), std.Out, std.Formatter
+	t.Cleanup(func() {
+		std.ReplaceHooks(hooks)
+		std.SetOutput(out)
+		std.SetFormatter(formatter)
+	})
+	var buf bytes.Buffer
+	std.SetOutput(&buf)
+	std.SetFormatter(&logrus.JSONFormatter{})
+	RegisterSecrets("install-hooks-secret")
+
+	InstallHooks("member_id", "handle")
+	logrus.WithFields(logrus.Fields{
+		"member_id": "m-1234",
+		"handle":    "alice.shareframe.social",
+		"token":     "install-hooks-secret",
+	}).Info("hooked")
+
+	line := decode(t, &buf)
+	assert.Equal(t, Redacted, line["token"])
+	assert.NotEqual(t, "m-1234", line["member_id"])
+	assert.Equal(t, "alice.shareframe.social", line["handle"])
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces secret values in log entries.
+const Redacted = "[REDACTED]"
+
+// minSecretLength keeps short values, which would match ordinary text,
+// from being scrubbed.
+const minSecretLength = 6
+
+// maxSecrets bounds how many values a long-lived process remembers. Session
+// tokens are registered on every login, so the oldest are dropped first.
+const maxSecrets = 256
+
+// Scrubber is a logrus hook that replaces known secret values wherever they
+// appear in an entry's message or fields. Unlike Redactor it doesn't depend
+// on field names, so it also catches secrets that end up inside headers,
+// error text or a whole secret document logged by mistake.
+type Scrubber struct {
+	mu       sync.RWMutex
+	secrets  []string
+	seen     map[string]bool
+	replacer *strings.Replacer
+}
+
+func NewScrubber() *Scrubber {
+	return &Scrubber{seen: map[string]bool{}}
+}
+
+var defaultScrubber = NewScrubber()
+
+// DefaultScrubber returns the scrubber RegisterSecrets adds to, for
+// installing as a hook.
+func DefaultScrubber() *Scrubber {
+	return defaultScrubber
+}
+
+// RegisterSecrets adds values loaded at runtime, such as passwords, API keys
+// and tokens, to the default scrubber.
+func RegisterSecrets(values ...string) {
+	defaultScrubber.Register(values...)
+}
+
+// Register adds values to scrub.
+func (s *Scrubber) Register(values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := false
+	for _, value := range values {
+		if len(value) < minSecretLength || s.seen[value] {
+			continue
+		}
+		s.seen[value] = true
+		s.secrets = append(s.secrets, value)
+		added = true
+	}
+	if !added {
+		return
+	}
+
+	for len(s.secrets) > maxSecrets {
+		delete(s.seen, s.secrets[0])
+		s.secrets = s.secrets[1:]
+	}
+
+	// strings.Replacer tries old strings in argument order at each
+	// position, so longer secrets go first in case one contains another.
+	ordered := append([]string{}, s.secrets...)
+	sort.SliceStable(ordered, func(i, j int) bool { return len(ordered[i]) > len(ordered[j]) })
+	pairs := make([]string, 0, 2*len(ordered))
+	for _, secret := range ordered {
+		pairs = append(pairs, secret, Redacted)
+	}
+	s.replacer = strings.NewReplacer(pairs...)
+}
+
+// Scrub returns text with every registered secret replaced.
+func (s *Scrubber) Scrub(text string) string {
+	s.mu.RLock()
+	replacer := s.replacer
+	s.mu.RUnlock()
+	if replacer == nil {
+		return text
+	}
+	return replacer.Replace(text)
+}
+
+func (s *Scrubber) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire rewrites string and error fields in place. Other values are
+// replaced by their scrubbed text only when printing them would reveal a
+// secret, as with a map of request headers.
+func (s *Scrubber) Fire(entry *logrus.Entry) error {
+	s.mu.RLock()
+	empty := s.replacer == nil
+	s.mu.RUnlock()
+	if empty {
+		return nil
+	}
+
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			entry.Data[key] = s.Scrub(v)
+		case error:
+			if text := v.Error(); s.Scrub(text) != text {
+				entry.Data[key] = s.Scrub(text)
+			}
+		default:
+			if text := fmt.Sprint(v); s.Scrub(text) != text {
+				entry.Data[key] = s.Scrub(text)
+			}
+		}
+	}
+	entry.Message = s.Scrub(entry.Message)
+	return nil
+}
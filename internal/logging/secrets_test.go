@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubberFire(t *testing.T) {
+	s := NewScrubber()
+	s.Register("hunter2-admin", "sk_live_abc123", "short", "")
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "login with hunter2-admin failed"
+	entry.Data = logrus.Fields{
+		"secret_value": `{"RESEND_APIKEY":"sk_live_abc123"}`,
+		"headers":      map[string]string{"Authorization": "Bearer sk_live_abc123"},
+		"error":        fmt.Errorf("auth failed: %w", errors.New("bad key sk_live_abc123")),
+		"status_code":  401,
+		"note":         "short",
+	}
+
+	assert.NoError(t, s.Fire(entry))
+
+	assert.Equal(t, "login with [REDACTED] failed", entry.Message)
+	assert.Equal(t, `{"RESEND_APIKEY":"[REDACTED]"}`, entry.Data["secret_value"])
+	assert.Equal(t, "map[Authorization:Bearer [REDACTED]]", entry.Data["headers"])
+	assert.Equal(t, "auth failed: bad key [REDACTED]", entry.Data["error"])
+	assert.Equal(t, 401, entry.Data["status_code"])
+	assert.Equal(t, "short", entry.Data["note"])
+}
+
+func TestScrubberLongestSecretFirst(t *testing.T) {
+	s := NewScrubber()
+	s.Register("secret", "secret-and-more")
+
+	assert.Equal(t, "x [REDACTED] y", s.Scrub("x secret-and-more y"))
+}
+
+func TestScrubberDropsOldestSecrets(t *testing.T) {
+	s := NewScrubber()
+	for i := 0; i <= maxSecrets; i++ {
+		s.Register(fmt.Sprintf("token-%04d", i))
+	}
+
+	assert.Equal(t, "token-0000", s.Scrub("token-0000"))
+	assert.Equal(t, Redacted, s.Scrub(fmt.Sprintf("token-%04d", maxSecrets)))
+}
+
+func TestScrubberWithoutSecrets(t *testing.T) {
+	s := NewScrubber()
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "nothing to hide"
+
+	assert.NoError(t, s.Fire(entry))
+	assert.Equal(t, "nothing to hide", entry.Message)
+}
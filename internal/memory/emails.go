@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/models"
+)
+
+// pendingEmail is a queued email. s.emails keeps them in the order they
+// were queued.
+type pendingEmail struct {
+	email models.PendingEmail
+	sent  bool
+}
+
+func (s *Store) QueueEmail(ctx context.Context, email models.PendingEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextEmail++
+	email.ID, email.Attempts = s.nextEmail, 0
+	s.emails = append(s.emails, &pendingEmail{email: email})
+	return nil
+}
+
+// DueEmails returns the oldest unsent emails that have been tried fewer
+// than maxAttempts times.
+func (s *Store) DueEmails(ctx context.Context, maxAttempts, limit int) ([]models.PendingEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []models.PendingEmail{}
+	for _, pending := range s.emails {
+		if len(due) == limit {
+			break
+		}
+		if !pending.sent && pending.email.Attempts < maxAttempts {
+			due = append(due, pending.email)
+		}
+	}
+	return due, nil
+}
+
+func (s *Store) MarkEmailSent(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pending := s.findEmailByID(id); pending != nil {
+		pending.sent = true
+		pending.email.Attempts++
+	}
+	return nil
+}
+
+// RecordEmailFailure counts the attempt; the reason is not kept.
+func (s *Store) RecordEmailFailure(ctx context.Context, id int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pending := s.findEmailByID(id); pending != nil {
+		pending.email.Attempts++
+	}
+	return nil
+}
+
+// ListEmails returns the emails kept for the account, sent or not, in the
+// order they were queued.
+func (s *Store) ListEmails(ctx context.Context, did string) ([]models.PendingEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	emails := []models.PendingEmail{}
+	for _, pending := range s.emails {
+		if pending.email.DID == did {
+			emails = append(emails, pending.email)
+		}
+	}
+	return emails, nil
+}
+
+func (s *Store) DeleteEmails(ctx context.Context, did string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.emails[:0]
+	for _, pending := range s.emails {
+		if pending.email.DID != did {
+			kept = append(kept, pending)
+		}
+	}
+	s.emails = kept
+	return nil
+}
+
+// SetVerificationEmailPending is a no-op for an unknown DID, as the UPDATE
+// it stands in for is.
+func (s *Store) SetVerificationEmailPending(ctx context.Context, did string, pending bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a, ok := s.accounts[did]; ok {
+		a.verificationEmailPending = pending
+	}
+	return nil
+}
+
+// findEmailByID returns the queued email with id. The caller holds s.mu.
+func (s *Store) findEmailByID(id int) *pendingEmail {
+	for _, pending := range s.emails {
+		if pending.email.ID == id {
+			return pending
+		}
+	}
+	return nil
+}
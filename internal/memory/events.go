@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/models"
+)
+
+type outboxKey struct {
+	eventID, subscriber string
+}
+
+func (s *Store) ClaimEvent(ctx context.Context, eventID, subscriber string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := outboxKey{eventID, subscriber}
+	if s.published[key] {
+		return false, nil
+	}
+	s.published[key] = true
+	return true, nil
+}
+
+func (s *Store) ReleaseEvent(ctx context.Context, eventID, subscriber string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.published, outboxKey{eventID, subscriber})
+	return nil
+}
+
+func (s *Store) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhooks = append(s.webhooks, delivery)
+	return nil
+}
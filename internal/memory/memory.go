@@ -0,0 +1,656 @@
+// Package memory keeps the service's tables in process memory, behind the
+// same store interfaces as the postgres package, for tests, local tools and
+// the memory storage backend. Writes have the semantics of the SQL they
+// stand in for: accounts are unique by DID, handle and normalized email,
+// status transitions and handle claims are conditional, and reads return
+// copies. Nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/confusables"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// timestamp is the format Postgres renders timestamps to text in, which
+// the stores return them as.
+const timestamp = "2006-01-02 15:04:05.999999-07"
+
+// ErrDuplicate is returned by StoreUser for an account whose DID, handle or
+// email is already taken, as a unique constraint would.
+var ErrDuplicate = errors.New("duplicate key value violates unique constraint")
+
+var _ postgres.Store = (*Store)(nil)
+
+type account struct {
+	record        models.UserRecord
+	phone         string
+	phoneVerified bool
+	createdAt     time.Time
+	// verificationEmailPending and stripeCustomerID stand in for columns
+	// no store interface reads back.
+	verificationEmailPending bool
+	stripeCustomerID         string
+}
+
+type phoneCode struct {
+	codeHash  string
+	attempts  int
+	sentAt    time.Time
+	expiresAt time.Time
+}
+
+// Store is an in-memory account store. The zero value is not usable; call
+// New. It is safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	accounts map[string]*account
+	audit    []models.AuditEvent
+	blocked  map[string]models.BlockedHandle
+	changes  []models.BlocklistAuditEntry
+	claims   map[string]models.HandleClaim
+	released map[string]time.Time
+	codes    map[string]phoneCode
+	consents map[string][]models.Consent
+	reviews  []models.FailedSignup
+
+	attempts  []signupAttempt
+	progress  map[string]models.SignupProgress
+	stats     map[statsKey]models.SignupStats
+	emails    []*pendingEmail
+	nextEmail int
+	published map[outboxKey]bool
+	webhooks  []models.WebhookDelivery
+
+	referralCodes map[string]string
+	referrals     map[string]models.Referral
+
+	now func() time.Time
+}
+
+func New() *Store {
+	return &Store{
+		accounts: map[string]*account{},
+		blocked:  map[string]models.BlockedHandle{},
+		claims:   map[string]models.HandleClaim{},
+		released: map[string]time.Time{},
+		codes:    map[string]phoneCode{},
+		consents: map[string][]models.Consent{},
+
+		progress:  map[string]models.SignupProgress{},
+		stats:     map[statsKey]models.SignupStats{},
+		published: map[outboxKey]bool{},
+
+		referralCodes: map[string]string{},
+		referrals:     map[string]models.Referral{},
+
+		now: time.Now,
+	}
+}
+
+// Accounts returns every stored account, ordered by handle.
+func (s *Store) Accounts() []models.UserRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]models.UserRecord, 0, len(s.accounts))
+	for _, a := range s.accounts {
+		records = append(records, copyRecord(a.record))
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Handle < records[j].Handle })
+	return records
+}
+
+func (s *Store) StoreUser(ctx context.Context, record models.UserRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[record.DID]; ok || s.findHandle(record.Handle) != nil || s.findEmail(record.Email) != nil {
+		return fmt.Errorf("failed to store user: %w", ErrDuplicate)
+	}
+	record.Email = validate.CanonicalizeEmail(record.Email)
+	s.accounts[record.DID] = &account{record: copyRecord(record), createdAt: s.now().UTC()}
+
+	logging.FromContext(ctx).WithField("handle", record.Handle).Info("User successfully stored in memory")
+	return nil
+}
+
+func (s *Store) CheckEmailExists(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.findEmail(email) != nil, nil
+}
+
+func (s *Store) CheckHandleExists(ctx context.Context, handle string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.findHandle(handle) != nil, nil
+}
+
+func (s *Store) HandleSkeletonExists(ctx context.Context, handle string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	skeleton := confusables.Skeleton(handle)
+	for _, a := range s.accounts {
+		if confusables.Skeleton(a.record.Handle) == skeleton {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return models.UserRecord{}, postgres.ErrUserNotFound
+	}
+	return copyRecord(a.record), nil
+}
+
+func (s *Store) FindUserDID(ctx context.Context, identifier string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findHandle(identifier)
+	if strings.Contains(identifier, "@") {
+		a = s.findEmail(identifier)
+	}
+	if a == nil {
+		return "", postgres.ErrUserNotFound
+	}
+	return a.record.DID, nil
+}
+
+func (s *Store) TransitionStatus(ctx context.Context, did, from, to string, verified bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok || a.record.Status != from {
+		return postgres.ErrStatusChanged
+	}
+	a.record.Status, a.record.Verified = to, verified
+	return nil
+}
+
+func (s *Store) SetProfilePicture(ctx context.Context, did, pictureURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return postgres.ErrUserNotFound
+	}
+	a.record.ProfilePicture = pictureURL
+	return nil
+}
+
+func (s *Store) SetOnboardingState(ctx context.Context, did, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return postgres.ErrUserNotFound
+	}
+	a.record.Onboarding = state
+	return nil
+}
+
+func (s *Store) RecordAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.RequestID == "" {
+		event.RequestID = logging.RequestID(ctx)
+	}
+	event.Details = copyDetails(event.Details)
+	event.OccurredAt = s.now().UTC().Format(timestamp)
+	s.audit = append(s.audit, event)
+	return nil
+}
+
+// ListAuditEvents returns the account's audit trail, oldest first.
+func (s *Store) ListAuditEvents(ctx context.Context, did string) ([]models.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []models.AuditEvent{}
+	for _, event := range s.audit {
+		if event.DID == did {
+			event.Details = copyDetails(event.Details)
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *Store) IsHandleBlocked(ctx context.Context, handle string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.blocked[strings.ToLower(handle)]
+	return ok, nil
+}
+
+func (s *Store) BlockHandle(ctx context.Context, handle, reason, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handle = strings.ToLower(handle)
+	at := s.now().UTC().Format(timestamp)
+	s.blocked[handle] = models.BlockedHandle{Handle: handle, Reason: reason, BlockedBy: actor, BlockedAt: at}
+	s.changes = append(s.changes, models.BlocklistAuditEntry{Handle: handle, Action: postgres.BlocklistActionAdd, Actor: actor, Reason: reason, ChangedAt: at})
+	return nil
+}
+
+func (s *Store) UnblockHandle(ctx context.Context, handle, reason, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handle = strings.ToLower(handle)
+	if _, ok := s.blocked[handle]; !ok {
+		return fmt.Errorf("failed to unblock %s: %w", handle, postgres.ErrHandleNotBlocked)
+	}
+	delete(s.blocked, handle)
+	s.changes = append(s.changes, models.BlocklistAuditEntry{
+		Handle:    handle,
+		Action:    postgres.BlocklistActionRemove,
+		Actor:     actor,
+		Reason:    reason,
+		ChangedAt: s.now().UTC().Format(timestamp),
+	})
+	return nil
+}
+
+func (s *Store) ListBlockedHandles(ctx context.Context) ([]models.BlockedHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handles := make([]models.BlockedHandle, 0, len(s.blocked))
+	for _, blocked := range s.blocked {
+		handles = append(handles, blocked)
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i].Handle < handles[j].Handle })
+	return handles, nil
+}
+
+// ListBlocklistAudit returns the latest limit changes, newest first.
+func (s *Store) ListBlocklistAudit(ctx context.Context, limit int) ([]models.BlocklistAuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		limit = 100
+	}
+	entries := make([]models.BlocklistAuditEntry, 0, limit)
+	for i := len(s.changes) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, s.changes[i])
+	}
+	return entries, nil
+}
+
+func (s *Store) ClaimHandle(ctx context.Context, handle, email string, ttl time.Duration) (models.HandleClaim, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	normalizedEmail := postgres.NormalizeEmail(email)
+	claim, held := s.claims[handle]
+	if held && claim.ExpiresAt.After(now) {
+		if claim.NormalizedEmail != normalizedEmail {
+			return models.HandleClaim{}, false, nil
+		}
+	} else {
+		claim.ExpiresAt = now.Add(ttl).UTC()
+	}
+	claim.Handle, claim.Email, claim.NormalizedEmail = handle, email, normalizedEmail
+	s.claims[handle] = claim
+
+	for other, earlier := range s.claims {
+		if other != handle && earlier.NormalizedEmail == normalizedEmail {
+			delete(s.claims, other)
+		}
+	}
+	return claim, true, nil
+}
+
+func (s *Store) ActiveHandleClaim(ctx context.Context, handle string) (models.HandleClaim, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claim, ok := s.claims[handle]
+	if !ok || !claim.ExpiresAt.After(s.now()) {
+		return models.HandleClaim{}, false, nil
+	}
+	return claim, true, nil
+}
+
+func (s *Store) QuarantineHandle(ctx context.Context, handle, did string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.released[handle] = s.now().Add(ttl).UTC()
+	return nil
+}
+
+func (s *Store) QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	releasedAt, ok := s.released[handle]
+	if !ok || !releasedAt.After(s.now()) {
+		return time.Time{}, false, nil
+	}
+	return releasedAt, true, nil
+}
+
+func (s *Store) VerifiedPhoneAccounts(ctx context.Context, phone, did string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, a := range s.accounts {
+		if a.record.DID != did && a.phone == phone && a.phoneVerified {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) StartPhoneVerification(ctx context.Context, did, phone, codeHash string, ttl, cooldown time.Duration) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if code, ok := s.codes[did]; ok && code.sentAt.After(now.Add(-cooldown)) {
+		return time.Time{}, false, nil
+	}
+	a, ok := s.accounts[did]
+	if !ok {
+		return time.Time{}, false, postgres.ErrUserNotFound
+	}
+
+	expiresAt := now.Add(ttl).UTC()
+	s.codes[did] = phoneCode{codeHash: codeHash, sentAt: now, expiresAt: expiresAt}
+	a.phone, a.phoneVerified = phone, false
+	return expiresAt, true, nil
+}
+
+func (s *Store) PendingPhoneCode(ctx context.Context, did string) (models.PhoneCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, ok := s.codes[did]
+	a, exists := s.accounts[did]
+	if !ok || !exists || !code.expiresAt.After(s.now()) {
+		return models.PhoneCode{}, postgres.ErrPhoneCodeNotFound
+	}
+	return models.PhoneCode{DID: did, Phone: a.phone, CodeHash: code.codeHash, Attempts: code.attempts}, nil
+}
+
+func (s *Store) RecordPhoneAttempt(ctx context.Context, did string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code, ok := s.codes[did]; ok {
+		code.attempts++
+		s.codes[did] = code
+	}
+	return nil
+}
+
+func (s *Store) ConfirmPhone(ctx context.Context, did string) (models.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return models.UserRecord{}, postgres.ErrUserNotFound
+	}
+	a.phoneVerified = true
+	delete(s.codes, did)
+
+	record := models.UserRecord{DID: a.record.DID, Handle: a.record.Handle, Status: a.record.Status}
+	record.ReviewFlags = append([]string(nil), a.record.ReviewFlags...)
+	return record, nil
+}
+
+func (s *Store) SetStripeCustomerID(ctx context.Context, did, customerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return postgres.ErrUserNotFound
+	}
+	a.stripeCustomerID = customerID
+	return nil
+}
+
+func (s *Store) StripeCustomerID(ctx context.Context, did string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return "", postgres.ErrUserNotFound
+	}
+	return a.stripeCustomerID, nil
+}
+
+func (s *Store) GetCRMContact(ctx context.Context, did string) (models.CRMContact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok || a.record.Status == models.StatusErased {
+		return models.CRMContact{}, postgres.ErrUserNotFound
+	}
+	return models.CRMContact{
+		DID:            a.record.DID,
+		Email:          a.record.Email,
+		Handle:         a.record.Handle,
+		ReferralSource: a.record.ReferralSource,
+		SignupDate:     a.createdAt,
+		Verified:       a.record.Verified,
+	}, nil
+}
+
+func (s *Store) RecordConsents(ctx context.Context, did string, consents []models.Consent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.consents[did] = append(s.consents[did], consents...)
+	return nil
+}
+
+// ListConsents returns the account's consents in the order they were
+// recorded.
+func (s *Store) ListConsents(ctx context.Context, did string) ([]models.Consent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	consents := append([]models.Consent{}, s.consents[did]...)
+	sort.SliceStable(consents, func(i, j int) bool { return consents[i].RecordedAt.Before(consents[j].RecordedAt) })
+	return consents, nil
+}
+
+// ListReviewQueue returns the accounts pending review, oldest first. A
+// limit of 0 or less returns the first 50.
+func (s *Store) ListReviewQueue(ctx context.Context, limit int) ([]models.ReviewItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		limit = 50
+	}
+	var pending []*account
+	for _, a := range s.accounts {
+		if a.record.Status == models.StatusPendingReview {
+			pending = append(pending, a)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].createdAt.Before(pending[j].createdAt) })
+
+	queue := make([]models.ReviewItem, 0, len(pending))
+	for _, a := range pending {
+		if len(queue) == limit {
+			break
+		}
+		queue = append(queue, models.ReviewItem{
+			DID:         a.record.DID,
+			Handle:      a.record.Handle,
+			Email:       a.record.Email,
+			DisplayName: a.record.DisplayName,
+			ReviewFlags: copyList(a.record.ReviewFlags),
+			CreatedAt:   a.createdAt.Format(timestamp),
+		})
+	}
+	return queue, nil
+}
+
+func (s *Store) ReleaseUser(ctx context.Context, did string) error {
+	return s.anonymize(did, models.StatusRejected)
+}
+
+func (s *Store) AnonymizeUser(ctx context.Context, did string) error {
+	return s.anonymize(did, models.StatusErased)
+}
+
+// anonymize clears the account's personal fields, as
+// postgres.AnonymizeUser does, and leaves it in status.
+func (s *Store) anonymize(did, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[did]
+	if !ok {
+		return postgres.ErrUserNotFound
+	}
+	placeholder := postgres.ErasedPlaceholder(did)
+	a.record = models.UserRecord{
+		DID:         did,
+		Email:       placeholder + "@erased.invalid",
+		Handle:      placeholder,
+		Status:      status,
+		Verified:    a.record.Verified,
+		Role:        a.record.Role,
+		AccountType: a.record.AccountType,
+		Preferences: a.record.Preferences,
+	}
+	a.phone, a.phoneVerified = "", false
+	delete(s.codes, did)
+	return nil
+}
+
+// ListStaleUsers returns the accounts whose verified flag disagrees with
+// their status. Accounts are always stored at postgres.UserSchemaVersion,
+// so that is the only way one can be stale.
+func (s *Store) ListStaleUsers(ctx context.Context, after string, limit int) ([]models.UserRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stale []models.UserRecord
+	for did, a := range s.accounts {
+		if did <= after {
+			continue
+		}
+		switch a.record.Status {
+		case models.StatusPending, models.StatusVerified, models.StatusActive:
+			if a.record.Verified != (a.record.Status != models.StatusPending) {
+				stale = append(stale, models.UserRecord{DID: did, Email: a.record.Email, Handle: a.record.Handle, Status: a.record.Status, Verified: a.record.Verified})
+			}
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].DID < stale[j].DID })
+	if len(stale) > limit {
+		stale = stale[:limit]
+	}
+	return stale, nil
+}
+
+func (s *Store) RepairUser(ctx context.Context, record models.UserRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[record.DID]
+	if !ok || a.record.Status != record.Status {
+		return postgres.ErrStatusChanged
+	}
+	a.record.Verified = record.Verified
+	return nil
+}
+
+func (s *Store) ExistingDIDs(ctx context.Context, dids []string) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[string]bool, len(dids))
+	for _, did := range dids {
+		if _, ok := s.accounts[did]; ok {
+			existing[did] = true
+		}
+	}
+	return existing, nil
+}
+
+// findHandle returns the account with handle, ignoring case. The caller
+// holds s.mu.
+func (s *Store) findHandle(handle string) *account {
+	for _, a := range s.accounts {
+		if strings.EqualFold(a.record.Handle, handle) {
+			return a
+		}
+	}
+	return nil
+}
+
+// findEmail returns the account whose email is the same mailbox as email.
+// The caller holds s.mu.
+func (s *Store) findEmail(email string) *account {
+	normalized := postgres.NormalizeEmail(email)
+	for _, a := range s.accounts {
+		if postgres.NormalizeEmail(a.record.Email) == normalized {
+			return a
+		}
+	}
+	return nil
+}
+
+func copyRecord(record models.UserRecord) models.UserRecord {
+	record.ReviewFlags = copyList(record.ReviewFlags)
+	record.Owners = copyList(record.Owners)
+	return record
+}
+
+func copyList(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return append([]string(nil), list...)
+}
+
+func copyDetails(details map[string]string) map[string]string {
+	if details == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(details))
+	for key, value := range details {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,421 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+var alice = models.UserRecord{
+	DID:         "did:plc:alice",
+	Email:       "Alice.Smith+signup@googlemail.com",
+	Handle:      "alice.shareframe.social",
+	Status:      models.StatusPendingReview,
+	ReviewFlags: []string{"signup_risk"},
+}
+
+func TestStoreUser(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		record      models.UserRecord
+		expectedErr error
+	}{
+		{name: "New Account", record: models.UserRecord{DID: "did:plc:bob", Email: "bob@example.com", Handle: "bob.shareframe.social"}},
+		{name: "Same DID", record: models.UserRecord{DID: alice.DID, Email: "other@example.com", Handle: "other.shareframe.social"}, expectedErr: ErrDuplicate},
+		{name: "Same Handle", record: models.UserRecord{DID: "did:plc:bob", Email: "bob@example.com", Handle: "ALICE.shareframe.social"}, expectedErr: ErrDuplicate},
+		{name: "Same Mailbox", record: models.UserRecord{DID: "did:plc:bob", Email: "alicesmith@gmail.com", Handle: "bob.shareframe.social"}, expectedErr: ErrDuplicate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := New()
+			assert.NoError(t, store.StoreUser(ctx, alice))
+
+			err := store.StoreUser(ctx, test.record)
+
+			assert.ErrorIs(t, err, test.expectedErr)
+			if test.expectedErr != nil {
+				assert.Len(t, store.Accounts(), 1)
+				return
+			}
+			assert.Len(t, store.Accounts(), 2)
+		})
+	}
+}
+
+func TestLookups(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+
+	exists, err := store.CheckEmailExists(ctx, "alicesmith@gmail.com")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = store.CheckHandleExists(ctx, "Alice.ShareFrame.Social")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = store.HandleSkeletonExists(ctx, "a1ice.shareframe.social")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = store.CheckHandleExists(ctx, "bob.shareframe.social")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	did, err := store.FindUserDID(ctx, "alice.smith@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.DID, did)
+	_, err = store.FindUserDID(ctx, "bob.shareframe.social")
+	assert.ErrorIs(t, err, postgres.ErrUserNotFound)
+
+	account, err := store.GetUser(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice.Smith+signup@googlemail.com", account.Email)
+
+	// Reads are copies.
+	account.ReviewFlags[0] = "changed"
+	account, _ = store.GetUser(ctx, alice.DID)
+	assert.Equal(t, []string{"signup_risk"}, account.ReviewFlags)
+	_, err = store.GetUser(ctx, "did:plc:bob")
+	assert.ErrorIs(t, err, postgres.ErrUserNotFound)
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+	machine := lifecycle.New(store, store)
+
+	transition, changed, err := machine.Fire(ctx, alice.DID, lifecycle.EventApprove, "moderator")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, models.StatusPending, transition.To)
+
+	_, _, err = machine.Fire(ctx, alice.DID, lifecycle.EventSuspend, "moderator")
+	assert.NoError(t, err)
+	account, _ := store.GetUser(ctx, alice.DID)
+	assert.Equal(t, models.StatusSuspended, account.Status)
+
+	assert.ErrorIs(t, store.TransitionStatus(ctx, alice.DID, models.StatusPending, models.StatusVerified, true), postgres.ErrStatusChanged)
+
+	events, err := store.ListAuditEvents(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, postgres.AuditAccountApproved, events[0].Event)
+	assert.Equal(t, postgres.AuditAccountSuspended, events[1].Event)
+}
+
+func TestConcurrentSignups(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- store.StoreUser(ctx, models.UserRecord{
+				DID:    fmt.Sprintf("did:plc:%d", i),
+				Email:  fmt.Sprintf("user%d@example.com", i),
+				Handle: "contested.shareframe.social",
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	stored := 0
+	for err := range errs {
+		if err == nil {
+			stored++
+		}
+	}
+	assert.Equal(t, 1, stored)
+}
+
+func TestBlocklist(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	store.now = func() time.Time { return time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC) }
+
+	assert.NoError(t, store.BlockHandle(ctx, "Squatter", "impersonates staff", "ops"))
+	assert.NoError(t, store.BlockHandle(ctx, "abuser", "spam", "ops"))
+	blocked, err := store.IsHandleBlocked(ctx, "SQUATTER")
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	assert.NoError(t, store.UnblockHandle(ctx, "abuser", "appeal", "ops"))
+	assert.ErrorIs(t, store.UnblockHandle(ctx, "abuser", "again", "ops"), postgres.ErrHandleNotBlocked)
+
+	handles, err := store.ListBlockedHandles(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BlockedHandle{{Handle: "squatter", Reason: "impersonates staff", BlockedBy: "ops", BlockedAt: "2026-02-01 12:00:00+00"}}, handles)
+
+	audit, err := store.ListBlocklistAudit(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, audit, 2)
+	assert.Equal(t, postgres.BlocklistActionRemove, audit[0].Action)
+	assert.Equal(t, "abuser", audit[1].Handle)
+}
+
+func TestClaimHandle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	store := New()
+	store.now = func() time.Time { return now }
+
+	claim, ok, err := store.ClaimHandle(ctx, "alice", "alice@example.com", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Hour), claim.ExpiresAt)
+
+	// Someone else can't take it while it's held.
+	_, ok, err = store.ClaimHandle(ctx, "alice", "mallory@example.com", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Claiming it again doesn't extend it.
+	now = now.Add(30 * time.Minute)
+	claim, ok, _ = store.ClaimHandle(ctx, "alice", "Alice+again@example.com", time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Minute), claim.ExpiresAt)
+
+	// A new claim by the same address releases the old one.
+	_, ok, _ = store.ClaimHandle(ctx, "alice2", "alice@example.com", time.Hour)
+	assert.True(t, ok)
+	_, active, _ := store.ActiveHandleClaim(ctx, "alice")
+	assert.False(t, active)
+
+	// Expired claims are free to take.
+	now = now.Add(2 * time.Hour)
+	_, active, _ = store.ActiveHandleClaim(ctx, "alice2")
+	assert.False(t, active)
+	_, ok, _ = store.ClaimHandle(ctx, "alice2", "mallory@example.com", time.Hour)
+	assert.True(t, ok)
+}
+
+func TestQuarantineHandle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	store := New()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.QuarantineHandle(ctx, "alice", alice.DID, time.Hour))
+	releasedAt, ok, err := store.QuarantinedHandle(ctx, "alice")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Hour), releasedAt)
+
+	// The handle is released once the period is over.
+	now = now.Add(2 * time.Hour)
+	_, ok, _ = store.QuarantinedHandle(ctx, "alice")
+	assert.False(t, ok)
+}
+
+func TestSetProfilePicture(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+
+	assert.NoError(t, store.SetProfilePicture(ctx, alice.DID, "https://pds.example.com/avatar"))
+	record, err := store.GetUser(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://pds.example.com/avatar", record.ProfilePicture)
+
+	assert.ErrorIs(t, store.SetProfilePicture(ctx, "did:plc:nobody", "https://pds.example.com/avatar"), postgres.ErrUserNotFound)
+}
+
+func TestSetOnboardingState(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+
+	assert.NoError(t, store.SetOnboardingState(ctx, alice.DID, models.OnboardingSeeded))
+	record, err := store.GetUser(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OnboardingSeeded, record.Onboarding)
+
+	assert.ErrorIs(t, store.SetOnboardingState(ctx, "did:plc:nobody", models.OnboardingSeeded), postgres.ErrUserNotFound)
+}
+
+func TestPhoneVerification(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	store := New()
+	store.now = func() time.Time { return now }
+	assert.NoError(t, store.StoreUser(ctx, alice))
+	assert.NoError(t, store.StoreUser(ctx, models.UserRecord{DID: "did:plc:bob", Email: "bob@example.com", Handle: "bob.shareframe.social"}))
+
+	_, _, err := store.StartPhoneVerification(ctx, "did:plc:nobody", "+14155550123", "hash", 10*time.Minute, time.Minute)
+	assert.ErrorIs(t, err, postgres.ErrUserNotFound)
+
+	expiresAt, started, err := store.StartPhoneVerification(ctx, alice.DID, "+14155550123", "hash", 10*time.Minute, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, started)
+	assert.Equal(t, now.Add(10*time.Minute), expiresAt)
+
+	_, started, _ = store.StartPhoneVerification(ctx, alice.DID, "+14155550123", "hash2", 10*time.Minute, time.Minute)
+	assert.False(t, started)
+
+	assert.NoError(t, store.RecordPhoneAttempt(ctx, alice.DID))
+	code, err := store.PendingPhoneCode(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PhoneCode{DID: alice.DID, Phone: "+14155550123", CodeHash: "hash", Attempts: 1}, code)
+
+	account, err := store.ConfirmPhone(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserRecord{DID: alice.DID, Handle: alice.Handle, Status: alice.Status, ReviewFlags: alice.ReviewFlags}, account)
+	_, err = store.PendingPhoneCode(ctx, alice.DID)
+	assert.ErrorIs(t, err, postgres.ErrPhoneCodeNotFound)
+
+	count, err := store.VerifiedPhoneAccounts(ctx, "+14155550123", "did:plc:bob")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, _ = store.VerifiedPhoneAccounts(ctx, "+14155550123", alice.DID)
+	assert.Equal(t, 0, count)
+}
+
+func TestAnonymizeUser(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+	_, _, err := store.StartPhoneVerification(ctx, alice.DID, "+14155550123", "hash", 10*time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.AnonymizeUser(ctx, alice.DID))
+	_, err = store.PendingPhoneCode(ctx, alice.DID)
+	assert.ErrorIs(t, err, postgres.ErrPhoneCodeNotFound)
+	record, err := store.GetUser(ctx, alice.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusErased, record.Status)
+	assert.Equal(t, postgres.ErasedPlaceholder(alice.DID), record.Handle)
+
+	// The handle and mailbox can be registered again.
+	assert.NoError(t, store.StoreUser(ctx, models.UserRecord{DID: "did:plc:alice2", Email: alice.Email, Handle: alice.Handle}))
+	_, err = store.GetCRMContact(ctx, alice.DID)
+	assert.ErrorIs(t, err, postgres.ErrUserNotFound)
+	assert.ErrorIs(t, store.ReleaseUser(ctx, "did:plc:nobody"), postgres.ErrUserNotFound)
+}
+
+func TestPendingEmails(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	for _, did := range []string{alice.DID, "did:plc:bob"} {
+		assert.NoError(t, store.QueueEmail(ctx, models.PendingEmail{DID: did, Recipient: did + "@example.com"}))
+	}
+	due, err := store.DueEmails(ctx, 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	assert.NoError(t, store.MarkEmailSent(ctx, due[0].ID))
+	assert.NoError(t, store.RecordEmailFailure(ctx, due[1].ID, "provider down"))
+	due, _ = store.DueEmails(ctx, 2, 10)
+	assert.Equal(t, []models.PendingEmail{{ID: 2, DID: "did:plc:bob", Recipient: "did:plc:bob@example.com", Attempts: 1}}, due)
+
+	// An email out of attempts is no longer due.
+	assert.NoError(t, store.RecordEmailFailure(ctx, due[0].ID, "provider down"))
+	due, _ = store.DueEmails(ctx, 2, 10)
+	assert.Empty(t, due)
+
+	assert.NoError(t, store.DeleteEmails(ctx, alice.DID))
+	emails, _ := store.ListEmails(ctx, alice.DID)
+	assert.Empty(t, emails)
+	emails, _ = store.ListEmails(ctx, "did:plc:bob")
+	assert.Len(t, emails, 1)
+}
+
+func TestReferrals(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	assert.NoError(t, store.StoreUser(ctx, alice))
+
+	code, err := store.IssueReferralCode(ctx, alice.DID, "ALICE1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ALICE1", code)
+	code, _ = store.IssueReferralCode(ctx, alice.DID, "ALICE2")
+	assert.Equal(t, "ALICE1", code)
+
+	owner, err := store.ReferralCodeOwner(ctx, "ALICE1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReferralCodeOwner{DID: alice.DID, NormalizedEmail: "alicesmith@gmail.com", Status: alice.Status}, owner)
+	_, err = store.ReferralCodeOwner(ctx, "ALICE2")
+	assert.ErrorIs(t, err, postgres.ErrReferralCodeNotFound)
+
+	bob := models.Referral{ReferredDID: "did:plc:bob", Code: "ALICE1", ReferrerDID: alice.DID, Status: models.ReferralPending}
+	assert.NoError(t, store.RecordReferral(ctx, bob))
+	assert.NoError(t, store.RecordReferral(ctx, models.Referral{ReferredDID: "did:plc:bob", Code: "OTHER", Status: models.ReferralPending}))
+
+	rewarded, ok, err := store.RewardReferral(ctx, "did:plc:bob")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ALICE1", rewarded.Code)
+	_, ok, _ = store.RewardReferral(ctx, "did:plc:bob")
+	assert.False(t, ok)
+
+	stats, err := store.ReferralStats(ctx, "ALICE1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReferralStats{Code: "ALICE1", Signups: 1, Rewarded: 1}, stats)
+}
+
+func TestSignupStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 2, 1, 12, 30, 0, 0, time.UTC)
+	store := New()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.StoreUser(ctx, models.UserRecord{DID: "did:plc:bob", Email: "bob@example.com", Handle: "bob.shareframe.social", Verified: true}))
+	assert.NoError(t, store.StoreUser(ctx, alice))
+	assert.NoError(t, store.RecordSignupAttempt(ctx, "203.0.113.7", "example.com", "blocked_handle"))
+	assert.NoError(t, store.RecordSignupAttempt(ctx, "203.0.113.7", "example.com", ""))
+
+	periods, err := store.AggregateSignupStats(ctx, models.StatsPeriodHour, now, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, periods)
+
+	stats, err := store.ListSignupStats(ctx, models.StatsPeriodHour, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SignupStats{{
+		Period:           models.StatsPeriodHour,
+		Start:            now.Truncate(time.Hour),
+		Signups:          2,
+		Verified:         1,
+		VerificationRate: 0.5,
+		FailedAttempts:   1,
+		FailureReasons:   map[string]int{"blocked_handle": 1},
+	}}, stats)
+
+	velocity, err := store.SignupVelocity(ctx, "203.0.113.7", "example.com", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, velocity.IPAttempts)
+	assert.Equal(t, 1, velocity.IPFailures)
+	assert.Equal(t, 1, velocity.DomainSignups)
+}
+
+func TestDeleteFiledSignups(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	for i, signup := range []models.FailedSignup{
+		{Handle: "alice", Email: "Alice@Example.com"},
+		{Handle: "alice.shareframe.social", Email: "other@example.com"},
+		{Handle: "bob", Email: "bob@example.com"},
+	} {
+		signup.MessageID = fmt.Sprintf("message-%d", i)
+		assert.NoError(t, store.FileForReview(ctx, signup))
+	}
+
+	assert.NoError(t, store.DeleteFiledSignups(ctx, alice.Handle, "alice@example.com"))
+
+	reviews := store.Reviews()
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "bob", reviews[0].Handle)
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// IssueReferralCode keeps the code an account already has.
+func (s *Store) IssueReferralCode(ctx context.Context, did, code string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for existing, owner := range s.referralCodes {
+		if owner == did {
+			return existing, nil
+		}
+	}
+	s.referralCodes[code] = did
+	return code, nil
+}
+
+func (s *Store) ReferralCodeOwner(ctx context.Context, code string) (models.ReferralCodeOwner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	did, ok := s.referralCodes[code]
+	a, exists := s.accounts[did]
+	if !ok || !exists {
+		return models.ReferralCodeOwner{}, postgres.ErrReferralCodeNotFound
+	}
+	return models.ReferralCodeOwner{
+		DID:             did,
+		NormalizedEmail: postgres.NormalizeEmail(a.record.Email),
+		Status:          a.record.Status,
+	}, nil
+}
+
+// RecordReferral keeps the first referral recorded for an account.
+func (s *Store) RecordReferral(ctx context.Context, referral models.Referral) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.referrals[referral.ReferredDID]; !ok {
+		s.referrals[referral.ReferredDID] = referral
+	}
+	return nil
+}
+
+func (s *Store) RewardReferral(ctx context.Context, referredDID string) (models.Referral, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	referral, ok := s.referrals[referredDID]
+	if !ok || referral.Status != models.ReferralPending {
+		return models.Referral{}, false, nil
+	}
+	referral.Status, referral.RewardedAt = models.ReferralRewarded, s.now().UTC()
+	s.referrals[referredDID] = referral
+	return referral, true, nil
+}
+
+func (s *Store) ReferralStats(ctx context.Context, code string) (models.ReferralStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := models.ReferralStats{Code: code}
+	for _, referral := range s.referrals {
+		if referral.Code != code {
+			continue
+		}
+		stats.Signups++
+		switch referral.Status {
+		case models.ReferralPending:
+			stats.Pending++
+		case models.ReferralRewarded:
+			stats.Rewarded++
+		case models.ReferralRejected:
+			stats.Rejected++
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/risk"
+)
+
+type signupAttempt struct {
+	ip, emailDomain, failureReason string
+	at                             time.Time
+}
+
+type statsKey struct {
+	period string
+	start  time.Time
+}
+
+func (s *Store) RecordSignupAttempt(ctx context.Context, ip, emailDomain, failureReason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = append(s.attempts, signupAttempt{ip: ip, emailDomain: emailDomain, failureReason: failureReason, at: s.now()})
+	return nil
+}
+
+func (s *Store) SignupVelocity(ctx context.Context, ip, emailDomain string, window time.Duration) (risk.Velocity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var velocity risk.Velocity
+	since := s.now().Add(-window)
+	for _, attempt := range s.attempts {
+		if !attempt.at.After(since) {
+			continue
+		}
+		if ip != "" && attempt.ip == ip {
+			velocity.IPAttempts++
+			if attempt.failureReason != "" {
+				velocity.IPFailures++
+			}
+		}
+		if emailDomain != "" && attempt.emailDomain == emailDomain && attempt.failureReason == "" {
+			velocity.DomainSignups++
+		}
+	}
+	return velocity, nil
+}
+
+func (s *Store) DomainSignups(ctx context.Context, emailDomain string, window time.Duration) (int, error) {
+	velocity, err := s.SignupVelocity(ctx, "", emailDomain, window)
+	return velocity.DomainSignups, err
+}
+
+func (s *Store) SignupProgress(ctx context.Context, key string) (models.SignupProgress, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress, ok := s.progress[key]
+	progress.Steps = copyList(progress.Steps)
+	return progress, ok, nil
+}
+
+func (s *Store) SaveSignupProgress(ctx context.Context, progress models.SignupProgress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress.Steps = copyList(progress.Steps)
+	s.progress[progress.IdempotencyKey] = progress
+	return nil
+}
+
+// FileForReview stores a message filed twice once.
+func (s *Store) FileForReview(ctx context.Context, signup models.FailedSignup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, filed := range s.reviews {
+		if filed.MessageID == signup.MessageID {
+			return nil
+		}
+	}
+	s.reviews = append(s.reviews, signup)
+	return nil
+}
+
+func (s *Store) DeleteFiledSignups(ctx context.Context, handle, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.reviews[:0]
+	for _, filed := range s.reviews {
+		if !strings.EqualFold(filed.Email, email) && !strings.EqualFold(filed.Handle, handle) {
+			kept = append(kept, filed)
+		}
+	}
+	s.reviews = kept
+	return nil
+}
+
+// Reviews returns the signups filed for manual review, in the order they
+// were filed.
+func (s *Store) Reviews() []models.FailedSignup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.FailedSignup(nil), s.reviews...)
+}
+
+// InviteCodeAvailable reports every code available. The store keeps no
+// invites table, so the PDS, which is the authority on codes anyway, is
+// left to refuse a bad one.
+func (s *Store) InviteCodeAvailable(ctx context.Context, code string) (bool, error) {
+	return true, nil
+}
+
+// AggregateSignupStats recomputes the periods from the one from falls in
+// to the one to falls in, replacing what they held before.
+func (s *Store) AggregateSignupStats(ctx context.Context, period string, from, to time.Time) (int, error) {
+	length := postgres.StatsPeriodLength(period)
+	if length == 0 {
+		return 0, fmt.Errorf("failed to aggregate signup stats: unknown period %q", period)
+	}
+	from = from.UTC().Truncate(length)
+	to = to.UTC().Truncate(length).Add(length)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counted := map[time.Time]*models.SignupStats{}
+	entry := func(at time.Time) *models.SignupStats {
+		start := at.UTC().Truncate(length)
+		if counted[start] == nil {
+			counted[start] = &models.SignupStats{Period: period, Start: start}
+		}
+		return counted[start]
+	}
+	for _, a := range s.accounts {
+		if a.createdAt.Before(from) || !a.createdAt.Before(to) {
+			continue
+		}
+		stats := entry(a.createdAt)
+		stats.Signups++
+		if a.record.Verified {
+			stats.Verified++
+		}
+	}
+	for _, attempt := range s.attempts {
+		if attempt.failureReason == "" || attempt.at.Before(from) || !attempt.at.Before(to) {
+			continue
+		}
+		stats := entry(attempt.at)
+		stats.FailedAttempts++
+		if stats.FailureReasons == nil {
+			stats.FailureReasons = map[string]int{}
+		}
+		stats.FailureReasons[attempt.failureReason]++
+	}
+
+	for start, stats := range counted {
+		if stats.Signups > 0 {
+			stats.VerificationRate = float64(stats.Verified) / float64(stats.Signups)
+		}
+		s.stats[statsKey{period, start}] = *stats
+	}
+	return len(counted), nil
+}
+
+// ListSignupStats returns the periods that start at or after since, oldest
+// first.
+func (s *Store) ListSignupStats(ctx context.Context, period string, since time.Time) ([]models.SignupStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []models.SignupStats{}
+	for key, stats := range s.stats {
+		if key.period == period && !key.start.Before(since.UTC()) {
+			stats.FailureReasons = copyCounts(stats.FailureReasons)
+			list = append(list, stats)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+	return list, nil
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(counts))
+	for key, count := range counts {
+		copied[key] = count
+	}
+	return copied
+}
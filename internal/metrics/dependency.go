@@ -0,0 +1,43 @@
+package metrics
+
+import "github.com/ShareFrame/user-management/internal/retry"
+
+// DependencyFailure counts failed calls to a dependency, by dependency and
+// error class, so an outage can be told apart from a bug at a glance.
+const DependencyFailure = "DependencyFailure"
+
+const (
+	DimensionDependency = "Dependency"
+	DimensionErrorClass = "ErrorClass"
+)
+
+// Dependencies, as they appear in the Dependency dimension.
+const (
+	DependencyPDS              = "PDS"
+	DependencyPostgres         = "Postgres"
+	DependencyDynamoDB         = "DynamoDB"
+	DependencyResend           = "Resend"
+	DependencySecretsManager   = "SecretsManager"
+	DependencyKMS              = "KMS"
+	DependencyStripe           = "Stripe"
+	DependencyAnalytics        = "Analytics"
+	DependencyCRM              = "CRM"
+	DependencyIdentityProvider = "IdentityProvider"
+	DependencySMS              = "SMS"
+)
+
+// DependencyFailed counts a call to dependency that failed on the
+// dependency's side: a network error, timeout, throttle or server error,
+// after any retries. Failures the request itself caused, such as a rejected
+// statement, point at our code rather than the dependency and aren't
+// counted.
+func DependencyFailed(m Metrics, dependency string, err error) {
+	class := retry.Classify(err)
+	if class == "" || class == retry.ClassOther {
+		return
+	}
+	m.Counter(DependencyFailure, 1, map[string]string{
+		DimensionDependency: dependency,
+		DimensionErrorClass: string(class),
+	})
+}
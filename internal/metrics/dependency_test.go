@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingMetrics struct {
+	Noop
+	counters []map[string]string
+}
+
+func (m *countingMetrics) Counter(name string, value float64, dimensions map[string]string) {
+	m.counters = append(m.counters, dimensions)
+}
+
+func TestDependencyFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected []map[string]string
+	}{
+		{
+			name:     "Server Error",
+			err:      &retry.StatusError{StatusCode: http.StatusServiceUnavailable},
+			expected: []map[string]string{{DimensionDependency: DependencyPDS, DimensionErrorClass: "server"}},
+		},
+		{
+			name:     "Timeout",
+			err:      context.DeadlineExceeded,
+			expected: []map[string]string{{DimensionDependency: DependencyPDS, DimensionErrorClass: "timeout"}},
+		},
+		{name: "Rejected Request", err: &retry.StatusError{StatusCode: http.StatusBadRequest}},
+		{name: "Our Error", err: errors.New("failed to marshal body")},
+		{name: "No Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &countingMetrics{}
+			DependencyFailed(m, DependencyPDS, tt.err)
+			assert.Equal(t, tt.expected, m.counters)
+		})
+	}
+}
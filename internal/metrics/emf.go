@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+)
+
+const DefaultNamespace = "ShareFrame/UserManagement"
+
+const (
+	UnitCount        = "Count"
+	UnitMilliseconds = "Milliseconds"
+	UnitNone         = "None"
+)
+
+// Metric names emitted by the signup path.
+const (
+	SignupAttempted = "SignupAttempted"
+	SignupSucceeded = "SignupSucceeded"
+	SignupFailed    = "SignupFailed"
+	// SignupWaitlisted counts signups the rollout gate turned away.
+	SignupWaitlisted = "SignupWaitlisted"
+	PDSLatency       = "PDSLatency"
+	DBLatency        = "DBLatency"
+	EmailLatency     = "EmailLatency"
+	// PanicCount counts handler panics, by operation, for alarming.
+	PanicCount = "PanicCount"
+	// BudgetExhausted counts signup steps that ran out of their share of
+	// the invocation, by step.
+	BudgetExhausted = "BudgetExhausted"
+)
+
+const (
+	DimensionTenant = "Tenant"
+	// DimensionReason is added to SignupFailed so failures can be graphed
+	// by cause.
+	DimensionReason = "Reason"
+	// DimensionOperation names the handler a metric came from.
+	DimensionOperation = "Operation"
+	DimensionStep      = "Step"
+)
+
+type metric struct {
+	name       string
+	unit       string
+	values     []float64
+	dimensions []string
+}
+
+// Recorder is the EMF backend. It collects the metrics for one invocation
+// and writes them as a single EMF document on Flush; EMF documents are plain
+// JSON log lines that CloudWatch turns into metrics, so Lambda needs no
+// agent or API calls. A nil *Recorder discards everything.
+type Recorder struct {
+	namespace  string
+	out        io.Writer
+	now        func() time.Time
+	mu         sync.Mutex
+	dimensions map[string]string
+	// values holds every dimension value, shared and metric-specific, as
+	// they appear at the top level of the document.
+	values  map[string]string
+	metrics map[string]*metric
+	order   []string
+}
+
+func NewRecorder(namespace string, out io.Writer) *Recorder {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &Recorder{
+		namespace:  namespace,
+		out:        out,
+		now:        time.Now,
+		dimensions: map[string]string{},
+		values:     map[string]string{},
+		metrics:    map[string]*metric{},
+	}
+}
+
+// SetDimension sets a dimension shared by every metric in the document.
+func (r *Recorder) SetDimension(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dimensions[key] = value
+	r.values[key] = value
+}
+
+// Counter adds value to the named counter.
+func (r *Recorder) Counter(name string, value float64, dimensions map[string]string) {
+	r.add(name, UnitCount, value, dimensions, false)
+}
+
+// Histogram records one sample. Repeated samples for the same metric are
+// reported together and CloudWatch keeps each one.
+func (r *Recorder) Histogram(name, unit string, value float64, dimensions map[string]string) {
+	r.add(name, unit, value, dimensions, false)
+}
+
+// Gauge keeps only the last value set before Flush.
+func (r *Recorder) Gauge(name string, value float64, dimensions map[string]string) {
+	r.add(name, UnitNone, value, dimensions, true)
+}
+
+func (r *Recorder) add(name, unit string, value float64, dimensions map[string]string, replace bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []string
+	for key, value := range dimensions {
+		r.values[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	m, ok := r.metrics[name]
+	if !ok {
+		m = &metric{name: name, unit: unit}
+		r.metrics[name] = m
+		r.order = append(r.order, name)
+	}
+	if replace {
+		m.values = m.values[:0]
+	}
+	m.values = append(m.values, value)
+	m.dimensions = keys
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// Flush writes everything recorded so far as one EMF line and resets the
+// recorder. Metrics with extra dimensions get their own directive so they
+// don't split the shared ones.
+func (r *Recorder) Flush() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.order) == 0 {
+		return
+	}
+
+	shared := make([]string, 0, len(r.dimensions))
+	for key := range r.dimensions {
+		shared = append(shared, key)
+	}
+	sort.Strings(shared)
+
+	document := map[string]interface{}{}
+	for key, value := range r.values {
+		document[key] = value
+	}
+
+	directives := map[string]*emfDirective{}
+	var directiveOrder []string
+	for _, name := range r.order {
+		m := r.metrics[name]
+
+		dimensions := append(append([]string{}, shared...), m.dimensions...)
+		key := strings.Join(dimensions, ",")
+		directive, ok := directives[key]
+		if !ok {
+			directive = &emfDirective{Namespace: r.namespace, Dimensions: [][]string{dimensions}}
+			directives[key] = directive
+			directiveOrder = append(directiveOrder, key)
+		}
+		directive.Metrics = append(directive.Metrics, emfMetric{Name: m.name, Unit: m.unit})
+
+		if len(m.values) == 1 {
+			document[m.name] = m.values[0]
+		} else {
+			document[m.name] = m.values
+		}
+	}
+
+	metadata := emfMetadata{Timestamp: r.now().UnixMilli()}
+	for _, key := range directiveOrder {
+		metadata.CloudWatchMetrics = append(metadata.CloudWatchMetrics, *directives[key])
+	}
+	document["_aws"] = metadata
+
+	line, err := json.Marshal(document)
+	if err != nil {
+		logging.Default().WithError(err).Error("Failed to encode metrics")
+		return
+	}
+	if _, err := r.out.Write(append(line, '\n')); err != nil {
+		logging.Default().WithError(err).Error("Failed to write metrics")
+	}
+
+	r.metrics = map[string]*metric{}
+	r.order = nil
+	for key := range r.values {
+		if _, ok := r.dimensions[key]; !ok {
+			delete(r.values, key)
+		}
+	}
+}
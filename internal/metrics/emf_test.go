@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderFlush(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRecorder("", &out)
+	r.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	r.SetDimension(DimensionTenant, "acme")
+	Count(r, SignupAttempted)
+	Duration(r, PDSLatency, 120*time.Millisecond)
+	Duration(r, PDSLatency, 80*time.Millisecond)
+	CountWith(r, SignupFailed, map[string]string{DimensionReason: "handle_taken"})
+	r.Flush()
+
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &document))
+	assert.Equal(t, "acme", document[DimensionTenant])
+	assert.Equal(t, "handle_taken", document[DimensionReason])
+	assert.Equal(t, 1.0, document[SignupAttempted])
+	assert.Equal(t, []interface{}{120.0, 80.0}, document[PDSLatency])
+	assert.Equal(t, 1.0, document[SignupFailed])
+
+	var metadata emfMetadata
+	raw, _ := json.Marshal(document["_aws"])
+	assert.NoError(t, json.Unmarshal(raw, &metadata))
+	assert.Equal(t, int64(1700000000000), metadata.Timestamp)
+	assert.Equal(t, []emfDirective{
+		{
+			Namespace:  DefaultNamespace,
+			Dimensions: [][]string{{DimensionTenant}},
+			Metrics:    []emfMetric{{Name: SignupAttempted, Unit: UnitCount}, {Name: PDSLatency, Unit: UnitMilliseconds}},
+		},
+		{
+			Namespace:  DefaultNamespace,
+			Dimensions: [][]string{{DimensionTenant, DimensionReason}},
+			Metrics:    []emfMetric{{Name: SignupFailed, Unit: UnitCount}},
+		},
+	}, metadata.CloudWatchMetrics)
+}
+
+func TestRecorderFlushResets(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRecorder("Test", &out)
+
+	r.Flush()
+	assert.Empty(t, out.String(), "nothing recorded, nothing written")
+
+	r.SetDimension(DimensionTenant, "acme")
+	CountWith(r, SignupFailed, map[string]string{DimensionReason: "invalid_email"})
+	r.Flush()
+	out.Reset()
+
+	Count(r, SignupSucceeded)
+	r.Flush()
+
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &document))
+	assert.Equal(t, "acme", document[DimensionTenant])
+	assert.NotContains(t, document, DimensionReason)
+	assert.NotContains(t, document, SignupFailed)
+}
+
+func TestRecorderGaugeKeepsLastValue(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRecorder("Test", &out)
+
+	r.Gauge("QueueDepth", 4, nil)
+	r.Gauge("QueueDepth", 2, nil)
+	r.Flush()
+
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &document))
+	assert.Equal(t, 2.0, document["QueueDepth"])
+}
+
+func TestNilRecorderIsNoop(t *testing.T) {
+	var r *Recorder
+
+	assert.NotPanics(t, func() {
+		r.SetDimension(DimensionTenant, "acme")
+		Count(r, SignupAttempted)
+		Since(r, DBLatency, time.Now())
+		r.Flush()
+	})
+}
@@ -0,0 +1,126 @@
+// Package metrics records the service's business and latency metrics. The
+// backend is chosen at startup: CloudWatch embedded metric format (EMF) on
+// Lambda, StatsD for self-hosted deployments, or none.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Backends accepted by Configure.
+const (
+	BackendEMF    = "emf"
+	BackendStatsD = "statsd"
+	BackendNone   = "none"
+)
+
+const DefaultStatsDAddress = "127.0.0.1:8125"
+
+// Metrics collects the metrics for one invocation. Backends may send them
+// as they are recorded or hold them until Flush.
+type Metrics interface {
+	// SetDimension sets a dimension shared by every metric recorded after
+	// it, such as the tenant.
+	SetDimension(key, value string)
+	// Counter adds value to the named counter.
+	Counter(name string, value float64, dimensions map[string]string)
+	// Histogram records one sample of a distribution, such as a latency.
+	Histogram(name, unit string, value float64, dimensions map[string]string)
+	// Gauge sets the current value of a level, such as a queue depth.
+	Gauge(name string, value float64, dimensions map[string]string)
+	Flush()
+}
+
+// newMetrics builds the Metrics for one invocation; Configure replaces it.
+var newMetrics = func() Metrics {
+	return NewRecorder(DefaultNamespace, os.Stdout)
+}
+
+// Configure selects the backend from METRICS_BACKEND: emf (the default),
+// statsd or none. namespace is the EMF namespace, or the StatsD prefix with
+// "/" turned into ".". StatsD metrics are sent over UDP to statsdAddress,
+// DefaultStatsDAddress if empty, with dimensions as DogStatsD tags, which
+// the Datadog agent, Telegraf and Prometheus's statsd_exporter all accept.
+func Configure(backend, namespace, statsdAddress string) error {
+	if namespace = strings.TrimSpace(namespace); namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "", BackendEMF:
+		newMetrics = func() Metrics { return NewRecorder(namespace, os.Stdout) }
+	case BackendStatsD:
+		if statsdAddress = strings.TrimSpace(statsdAddress); statsdAddress == "" {
+			statsdAddress = DefaultStatsDAddress
+		}
+		conn, err := net.Dial("udp", statsdAddress)
+		if err != nil {
+			return fmt.Errorf("invalid StatsD address %q: %w", statsdAddress, err)
+		}
+		prefix := strings.ReplaceAll(namespace, "/", ".")
+		newMetrics = func() Metrics { return NewStatsD(prefix, conn) }
+	case BackendNone:
+		newMetrics = func() Metrics { return Noop{} }
+	default:
+		return fmt.Errorf("invalid metrics backend %q", backend)
+	}
+	return nil
+}
+
+// New returns the Metrics for one invocation from the configured backend.
+func New() Metrics {
+	return newMetrics()
+}
+
+// Noop discards everything.
+type Noop struct{}
+
+func (Noop) SetDimension(string, string)                          {}
+func (Noop) Counter(string, float64, map[string]string)           {}
+func (Noop) Histogram(string, string, float64, map[string]string) {}
+func (Noop) Gauge(string, float64, map[string]string)             {}
+func (Noop) Flush()                                               {}
+
+// Count adds one to the named counter.
+func Count(m Metrics, name string) {
+	m.Counter(name, 1, nil)
+}
+
+// CountWith adds one to the named counter under extra dimensions, such as
+// the reason a signup failed.
+func CountWith(m Metrics, name string, dimensions map[string]string) {
+	m.Counter(name, 1, dimensions)
+}
+
+// Duration records one latency sample in milliseconds.
+func Duration(m Metrics, name string, d time.Duration) {
+	m.Histogram(name, UnitMilliseconds, float64(d)/float64(time.Millisecond), nil)
+}
+
+// Since records the time elapsed since start, for use with defer.
+func Since(m Metrics, name string, start time.Time) {
+	Duration(m, name, time.Since(start))
+}
+
+type contextKey struct{}
+
+// WithMetrics returns a context that carries m, so clients deep in the call
+// chain can record latencies without it being threaded through every
+// signature.
+func WithMetrics(ctx context.Context, m Metrics) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the Metrics carried by ctx, or Noop outside an
+// instrumented request.
+func FromContext(ctx context.Context) Metrics {
+	if m, ok := ctx.Value(contextKey{}).(Metrics); ok {
+		return m
+	}
+	return Noop{}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	defer func(original func() Metrics) { newMetrics = original }(newMetrics)
+
+	tests := []struct {
+		name        string
+		backend     string
+		expected    Metrics
+		expectedErr string
+	}{
+		{"Default", "", &Recorder{}, ""},
+		{"EMF", "EMF", &Recorder{}, ""},
+		{"StatsD", "statsd", &StatsD{}, ""},
+		{"None", "none", Noop{}, ""},
+		{"Unknown", "prometheus", nil, `invalid metrics backend "prometheus"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Configure(tt.backend, "", "127.0.0.1:8125")
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.IsType(t, tt.expected, New())
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Noop{}, FromContext(context.Background()))
+
+	r := NewRecorder("Test", &bytes.Buffer{})
+	assert.Same(t, r, FromContext(WithMetrics(context.Background(), r)))
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+)
+
+// maxPacketSize keeps each StatsD datagram under a typical MTU, so batches
+// aren't fragmented or dropped on the way to the agent.
+const maxPacketSize = 1432
+
+// StatsD is the StatsD backend. Metrics are buffered as DogStatsD lines and
+// written on Flush, one datagram per Write on out, so a UDP connection sends
+// each batch as a single packet. Shared dimensions are added to every line
+// as tags.
+type StatsD struct {
+	prefix string
+	out    io.Writer
+	mu     sync.Mutex
+	tags   map[string]string
+	lines  []string
+}
+
+func NewStatsD(prefix string, out io.Writer) *StatsD {
+	return &StatsD{prefix: prefix, out: out, tags: map[string]string{}}
+}
+
+func (s *StatsD) SetDimension(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[key] = value
+}
+
+func (s *StatsD) Counter(name string, value float64, dimensions map[string]string) {
+	s.add(name, value, "c", dimensions)
+}
+
+// Histogram sends milliseconds as a StatsD timer and anything else as a
+// DogStatsD histogram.
+func (s *StatsD) Histogram(name, unit string, value float64, dimensions map[string]string) {
+	kind := "h"
+	if unit == UnitMilliseconds {
+		kind = "ms"
+	}
+	s.add(name, value, kind, dimensions)
+}
+
+func (s *StatsD) Gauge(name string, value float64, dimensions map[string]string) {
+	s.add(name, value, "g", dimensions)
+}
+
+func (s *StatsD) add(name string, value float64, kind string, dimensions map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var line strings.Builder
+	if s.prefix != "" {
+		line.WriteString(s.prefix)
+		line.WriteByte('.')
+	}
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	line.WriteByte('|')
+	line.WriteString(kind)
+
+	tags := make(map[string]string, len(s.tags)+len(dimensions))
+	for key, value := range s.tags {
+		tags[key] = value
+	}
+	for key, value := range dimensions {
+		tags[key] = value
+	}
+	if len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i == 0 {
+				line.WriteString("|#")
+			} else {
+				line.WriteByte(',')
+			}
+			line.WriteString(key + ":" + tags[key])
+		}
+	}
+	s.lines = append(s.lines, line.String())
+}
+
+// Flush writes the buffered lines in packets of at most maxPacketSize bytes.
+func (s *StatsD) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var packet strings.Builder
+	send := func() {
+		if packet.Len() == 0 {
+			return
+		}
+		if _, err := io.WriteString(s.out, packet.String()); err != nil {
+			logging.Default().WithError(err).Warn("Failed to send StatsD metrics")
+		}
+		packet.Reset()
+	}
+	for _, line := range s.lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxPacketSize {
+			send()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	send()
+	s.lines = nil
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type packetWriter struct {
+	packets []string
+}
+
+func (w *packetWriter) Write(p []byte) (int, error) {
+	w.packets = append(w.packets, string(p))
+	return len(p), nil
+}
+
+func TestStatsDFlush(t *testing.T) {
+	out := &packetWriter{}
+	s := NewStatsD("ShareFrame.UserManagement", out)
+
+	s.SetDimension(DimensionTenant, "acme")
+	Count(s, SignupAttempted)
+	Duration(s, PDSLatency, 120*time.Millisecond)
+	CountWith(s, SignupFailed, map[string]string{DimensionReason: "handle_taken"})
+	s.Gauge("QueueDepth", 2.5, nil)
+	s.Histogram("PayloadSize", "Bytes", 512, nil)
+	s.Flush()
+
+	assert.Equal(t, []string{strings.Join([]string{
+		"ShareFrame.UserManagement.SignupAttempted:1|c|#Tenant:acme",
+		"ShareFrame.UserManagement.PDSLatency:120|ms|#Tenant:acme",
+		"ShareFrame.UserManagement.SignupFailed:1|c|#Reason:handle_taken,Tenant:acme",
+		"ShareFrame.UserManagement.QueueDepth:2.5|g|#Tenant:acme",
+		"ShareFrame.UserManagement.PayloadSize:512|h|#Tenant:acme",
+	}, "\n")}, out.packets)
+
+	s.Flush()
+	assert.Len(t, out.packets, 1, "nothing recorded, nothing sent")
+}
+
+func TestStatsDFlushSplitsPackets(t *testing.T) {
+	out := &packetWriter{}
+	s := NewStatsD("", out)
+
+	for i := 0; i < 100; i++ {
+		Count(s, SignupAttempted)
+	}
+	s.Flush()
+
+	assert.Greater(t, len(out.packets), 1)
+	lines := 0
+	for _, packet := range out.packets {
+		assert.LessOrEqual(t, len(packet), maxPacketSize)
+		lines += len(strings.Split(packet, "\n"))
+	}
+	assert.Equal(t, 100, lines)
+}
@@ -1,30 +1,244 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+)
+
+// SecretHolder is implemented by credentials whose values must never be
+// logged.
+type SecretHolder interface {
+	Secrets() []string
+}
+
 type AdminCreds struct {
 	PDSJWTSecret     string `json:"PDS_JWT_SECRET"`
 	PDSAdminPassword string `json:"PDS_ADMIN_PASSWORD"`
 	PDSAdminUsername string `json:"PDS_ADMIN_USERNAME"`
 }
 
+func (c AdminCreds) Secrets() []string {
+	return []string{c.PDSJWTSecret, c.PDSAdminPassword}
+}
+
 type EmailCreds struct {
 	APIKey string `json:"RESEND_APIKEY"`
 }
 
-type UserRequest struct {
-	Handle   string `json:"handle"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+func (c EmailCreds) Secrets() []string {
+	return []string{c.APIKey}
+}
+
+type StripeCreds struct {
+	SecretKey string `json:"STRIPE_SECRET_KEY"`
+}
+
+func (c StripeCreds) Secrets() []string {
+	return []string{c.SecretKey}
+}
+
+type CRMCreds struct {
+	APIKey string `json:"CRM_API_KEY"`
+}
+
+func (c CRMCreds) Secrets() []string {
+	return []string{c.APIKey}
+}
+
+// AnalyticsCreds holds the analytics write key and the salt accounts are
+// pseudonymized with, so analytics IDs can't be matched back to DIDs.
+type AnalyticsCreds struct {
+	WriteKey string `json:"ANALYTICS_WRITE_KEY"`
+	Salt     string `json:"ANALYTICS_ID_SALT"`
+}
+
+func (c AnalyticsCreds) Secrets() []string {
+	return []string{c.WriteKey, c.Salt}
+}
+
+// SigningKey is a shared secret for signing tokens. KeyID goes in each
+// token's header so keys can be rotated.
+type SigningKey struct {
+	KeyID  string `json:"kid"`
+	Secret string `json:"secret"`
+}
+
+func (k SigningKey) Secrets() []string {
+	return []string{k.Secret}
+}
+
+// UserRequest is the signup request. It is published in pkg/api.
+type UserRequest = api.CreateUserRequest
+
+// API versions a UserRequest can be in.
+const (
+	APIVersion1 = api.Version1
+	APIVersion2 = api.Version2
+)
+
+// Account types. Organizations are validated with their own rules and bots
+// are only created through the bot provisioning path; both are labelled on
+// their PDS profile.
+const (
+	AccountTypePerson       = api.AccountTypePerson
+	AccountTypeOrganization = api.AccountTypeOrganization
+	AccountTypeBot          = api.AccountTypeBot
+)
+
+// Self-labels on the PDS profiles of organizations and bots.
+const (
+	LabelOrganization = "organization"
+	LabelBot          = "bot"
+)
+
+// Account roles. New accounts get the configured default role, "user"
+// unless changed, or the one assigned to their email address; bots always
+// get RoleBot.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	RoleBot       = "bot"
+)
+
+// BotRequest provisions a bot account run by the account Owner, a DID.
+type BotRequest struct {
+	Handle      string `json:"handle"`
+	Owner       string `json:"owner"`
+	DisplayName string `json:"displayName,omitempty"`
+	Tenant      string `json:"tenant,omitempty"`
+}
+
+// BotResponse is a new bot account. AppPassword is its only credential and
+// is shown once.
+type BotResponse struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	Owner       string `json:"owner"`
+	AppPassword string `json:"appPassword"`
+}
+
+// ProfileRecord is what the service writes to an account's
+// app.bsky.actor.profile record. Description is the bio and Labels are
+// self-labels.
+type ProfileRecord struct {
+	DisplayName string
+	Description string
+	Pronouns    string
+	Website     string
+	Labels      []string
+	Avatar      *BlobRef
+	Theme       *ProfileTheme
+}
+
+// ShareFrameProfile is the social.shareframe.profile record, which keeps
+// the ShareFrame-specific profile in the account's own repo.
+type ShareFrameProfile struct {
+	Type           string          `json:"$type"`
+	Theme          json.RawMessage `json:"theme,omitempty"`
+	PrimaryColor   string          `json:"primaryColor,omitempty"`
+	SecondaryColor string          `json:"secondaryColor,omitempty"`
+	Banner         string          `json:"banner,omitempty"`
+	CreatedAt      string          `json:"createdAt"`
+}
+
+// ProfileTheme is ShareFrame's own addition to the profile record, so
+// ShareFrame clients can style the profile without a second lookup.
+type ProfileTheme struct {
+	Settings       json.RawMessage `json:"settings,omitempty"`
+	PrimaryColor   string          `json:"primaryColor,omitempty"`
+	SecondaryColor string          `json:"secondaryColor,omitempty"`
+}
+
+// BlobRef refers to a blob uploaded to a PDS, in the form records embed it.
+type BlobRef struct {
+	Type     string   `json:"$type"`
+	Ref      BlobLink `json:"ref"`
+	MimeType string   `json:"mimeType"`
+	Size     int      `json:"size"`
+}
+
+// BlobLink is the CID of a blob.
+type BlobLink struct {
+	Link string `json:"$link"`
+}
+
+// ProfileLabels are the self-labels the profile of an account of
+// accountType carries: none for people.
+func ProfileLabels(accountType string) []string {
+	switch accountType {
+	case AccountTypeOrganization:
+		return []string{LabelOrganization}
+	case AccountTypeBot:
+		return []string{LabelBot}
+	}
+	return nil
+}
+
+// Consent purposes a signup can record.
+const (
+	ConsentMarketing      = api.ConsentMarketing
+	ConsentDataProcessing = api.ConsentDataProcessing
+)
+
+type Consent = api.Consent
+
+// FindConsent returns the choice recorded for purpose, if any.
+func FindConsent(consents []Consent, purpose string) (Consent, bool) {
+	for _, consent := range consents {
+		if consent.Purpose == purpose {
+			return consent, true
+		}
+	}
+	return Consent{}, false
 }
 
 type InviteCodeResponse struct {
 	Code string `json:"code"`
 }
 
-type CreateUserResponse struct {
-	Handle     string `json:"handle"`
-	DID        string `json:"did"`
-	AccessJWT  string `json:"accessJwt"`
-	RefreshJWT string `json:"refreshJwt"`
+type CreateUserResponse = api.CreateUserResponse
+
+// Next steps of a signup response.
+const (
+	NextStepAwaitReview  = api.NextStepAwaitReview
+	NextStepVerifyEmail  = api.NextStepVerifyEmail
+	NextStepVerifyPhone  = api.NextStepVerifyPhone
+	NextStepUploadAvatar = api.NextStepUploadAvatar
+	NextStepAwaitLaunch  = api.NextStepAwaitLaunch
+)
+
+type AvatarUpload = api.AvatarUpload
+
+// AvatarRequest applies the picture uploaded for DID. AccessJWT is the
+// account's session from the signup response; the picture is pushed to the
+// PDS under it, so only the account itself can apply one.
+type AvatarRequest struct {
+	DID       string `json:"did"`
+	AccessJWT string `json:"accessJwt"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// AvatarResponse is the account's new profile picture URL.
+type AvatarResponse struct {
+	ProfilePicture string `json:"profilePicture"`
+}
+
+// RepoPage is a page of com.atproto.sync.listRepos.
+type RepoPage struct {
+	Cursor string `json:"cursor,omitempty"`
+	Repos  []Repo `json:"repos"`
+}
+
+// Repo is one account's repository on the PDS.
+type Repo struct {
+	DID    string `json:"did"`
+	Active bool   `json:"active"`
 }
 
 type UtilACcountCreds struct {
@@ -33,17 +247,640 @@ type UtilACcountCreds struct {
 	DID      string `json:"did"`
 }
 
+func (c UtilACcountCreds) Secrets() []string {
+	return []string{c.Password}
+}
+
 type SessionRequest struct {
 	Identifier string `json:"identifier"`
 	Password   string `json:"password"`
 }
 
 type SessionResponse struct {
-	AccessJwt string `json:"accessJwt"`
-	Did       string `json:"did"`
+	AccessJwt  string `json:"accessJwt"`
+	RefreshJwt string `json:"refreshJwt"`
+	Did        string `json:"did"`
+	Handle     string `json:"handle"`
+}
+
+// ReservedHandleCategory is one group in reserved_handles.json. Policy is
+// "block" for handles nobody may claim or "approval" for handles an admin
+// can release to the right owner. Protected names the handles whose
+// near-miss spellings are rejected too, to stop typosquatting.
+type ReservedHandleCategory struct {
+	Policy    string   `json:"policy"`
+	Handles   []string `json:"handles"`
+	Protected []string `json:"protected,omitempty"`
+}
+
+type UserRecord struct {
+	DID            string `json:"did"`
+	Email          string `json:"email"`
+	Handle         string `json:"handle"`
+	DisplayName    string `json:"displayName"`
+	Status         string `json:"status"`
+	Verified       bool   `json:"verified"`
+	Role           string `json:"role"`
+	ProfilePicture string `json:"profilePicture"`
+	ProfileBanner  string `json:"profileBanner"`
+	Theme          string `json:"theme"`
+	PrimaryColor   string `json:"primaryColor"`
+	SecondaryColor string `json:"secondaryColor"`
+	Locale         string `json:"locale,omitempty"`
+	Country        string `json:"country,omitempty"`
+	Timezone       string `json:"timezone,omitempty"`
+	Bio            string `json:"bio,omitempty"`
+	Pronouns       string `json:"pronouns,omitempty"`
+	Website        string `json:"website,omitempty"`
+	Location       string `json:"location,omitempty"`
+	ReferralSource string `json:"referralSource,omitempty"`
+	// ReviewFlags say why an account is pending review.
+	ReviewFlags []string `json:"reviewFlags,omitempty"`
+	// AccountType is empty for accounts stored before account types.
+	AccountType string   `json:"accountType,omitempty"`
+	Owners      []string `json:"owners,omitempty"`
+	// Onboarding is empty until the account has been seeded after signup.
+	Onboarding string `json:"onboarding,omitempty"`
+	// Preferences are written with defaults at signup. They are zero for
+	// accounts stored before preferences.
+	Preferences Preferences `json:"preferences"`
+}
+
+// Preferences are the account's notification, privacy and content filter
+// settings.
+type Preferences struct {
+	Notifications  NotificationPreferences `json:"notifications"`
+	Privacy        PrivacyPreferences      `json:"privacy"`
+	ContentFilters ContentFilters          `json:"contentFilters"`
+}
+
+// NotificationPreferences say what the account is notified about and how.
+type NotificationPreferences struct {
+	Email    bool `json:"email"`
+	Push     bool `json:"push"`
+	Mentions bool `json:"mentions"`
+	Replies  bool `json:"replies"`
+	Follows  bool `json:"follows"`
+	Likes    bool `json:"likes"`
+	// Marketing follows the marketing consent given at signup.
+	Marketing bool `json:"marketing"`
+}
+
+// PrivacyPreferences say who can find and reach the account.
+type PrivacyPreferences struct {
+	// Discoverable lists the account in search and suggestions.
+	Discoverable bool `json:"discoverable"`
+	// FindableByEmail lets people who know the email address find the
+	// account.
+	FindableByEmail bool `json:"findableByEmail"`
+	// DirectMessages is who may message the account: one of the
+	// DirectMessages constants.
+	DirectMessages string `json:"directMessages"`
+}
+
+// ContentFilters say how labelled content is shown: FilterShow, FilterWarn
+// or FilterHide for each kind.
+type ContentFilters struct {
+	Adult   string `json:"adult"`
+	Graphic string `json:"graphic"`
+	Spam    string `json:"spam"`
+}
+
+// Who may send an account direct messages.
+const (
+	DirectMessagesEveryone  = "everyone"
+	DirectMessagesFollowing = "following"
+	DirectMessagesNobody    = "nobody"
+)
+
+// Content filter settings.
+const (
+	FilterShow = "show"
+	FilterWarn = "warn"
+	FilterHide = "hide"
+)
+
+// DefaultPreferences are the built-in preferences of a new account:
+// notifications for direct interactions, discoverable in search but not
+// by email address, messages from followed accounts only, and adult
+// content and spam hidden.
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Notifications: NotificationPreferences{
+			Email:    true,
+			Push:     true,
+			Mentions: true,
+			Replies:  true,
+			Follows:  true,
+		},
+		Privacy: PrivacyPreferences{
+			Discoverable:   true,
+			DirectMessages: DirectMessagesFollowing,
+		},
+		ContentFilters: ContentFilters{
+			Adult:   FilterHide,
+			Graphic: FilterWarn,
+			Spam:    FilterHide,
+		},
+	}
+}
+
+// Onboarding states. A partly seeded account has its profile or some of
+// its starter follows missing.
+const (
+	OnboardingSeeded  = "seeded"
+	OnboardingPartial = "partial"
+)
+
+// ShareFrameProfile is the account's social.shareframe.profile record,
+// created at createdAt.
+func (u UserRecord) ShareFrameProfile(createdAt time.Time) ShareFrameProfile {
+	profile := ShareFrameProfile{
+		PrimaryColor:   u.PrimaryColor,
+		SecondaryColor: u.SecondaryColor,
+		Banner:         u.ProfileBanner,
+		CreatedAt:      createdAt.UTC().Format(time.RFC3339),
+	}
+	if u.Theme != "" && u.Theme != "{}" {
+		profile.Theme = json.RawMessage(u.Theme)
+	}
+	return profile
+}
+
+// ProfileTheme is the account's ShareFrame theme as carried on its
+// profile.
+func (u UserRecord) ProfileTheme() *ProfileTheme {
+	theme := &ProfileTheme{PrimaryColor: u.PrimaryColor, SecondaryColor: u.SecondaryColor}
+	if u.Theme != "" && u.Theme != "{}" {
+		theme.Settings = json.RawMessage(u.Theme)
+	}
+	return theme
+}
+
+// Account statuses. internal/lifecycle holds the allowed transitions
+// between them.
+const (
+	StatusPending       = api.StatusPending
+	StatusVerified      = api.StatusVerified
+	StatusActive        = api.StatusActive
+	StatusPendingReview = api.StatusPendingReview
+	StatusErased        = api.StatusErased
+	StatusSuspended     = api.StatusSuspended
+	StatusRejected      = api.StatusRejected
+	StatusWaitlisted    = api.StatusWaitlisted
+)
+
+// BlockedHandle is a handle an admin added to the runtime blocklist.
+type BlockedHandle struct {
 	Handle    string `json:"handle"`
+	Reason    string `json:"reason"`
+	BlockedBy string `json:"blockedBy"`
+	BlockedAt string `json:"blockedAt"`
+}
+
+// BlocklistAuditEntry records one change to the runtime blocklist.
+type BlocklistAuditEntry struct {
+	Handle    string `json:"handle"`
+	Action    string `json:"action"`
+	Actor     string `json:"actor"`
+	Reason    string `json:"reason,omitempty"`
+	ChangedAt string `json:"changedAt"`
+}
+
+// AuditEvent is one entry in the account lifecycle audit trail. Entries are
+// only ever appended; Details holds event-specific values such as the
+// previous handle of a handle change.
+type AuditEvent struct {
+	Event      string            `json:"event"`
+	DID        string            `json:"did"`
+	Handle     string            `json:"handle"`
+	Actor      string            `json:"actor"`
+	RequestID  string            `json:"requestId"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt string            `json:"occurredAt,omitempty"`
+}
+
+// FailedSignup is a signup from the dead-letter queue that can't be
+// re-driven and needs a person to look at it. The request's password is
+// never kept.
+type FailedSignup struct {
+	MessageID string `json:"messageId"`
+	Tenant    string `json:"tenant,omitempty"`
+	Handle    string `json:"handle"`
+	Email     string `json:"email"`
+	Reason    string `json:"reason"`
+	Error     string `json:"error"`
+	Attempts  int    `json:"attempts"`
+}
+
+// PendingEmail is a rendered email waiting to be sent because the email
+// provider was unavailable when the account was created.
+type PendingEmail struct {
+	ID        int    `json:"id"`
+	Tenant    string `json:"tenant"`
+	DID       string `json:"did"`
+	From      string `json:"from"`
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	HTML      string `json:"html"`
+	Attempts  int    `json:"attempts"`
+}
+
+// EmailQueueResult summarizes one run of the pending email sender.
+type EmailQueueResult struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+}
+
+// Account lifecycle events announced to other systems.
+const (
+	EventAccountCreated      = "account.created"
+	EventVerificationPending = "account.verification_pending"
+	EventAccountVerified     = "account.verified"
+	EventAccountActivated    = "account.activated"
+	EventAccountDeleted      = "account.deleted"
+	// EventReviewRequested is sent when a signup is held for review, for
+	// the moderation channel, and EventAccountRejected when a moderator
+	// turns it down.
+	EventReviewRequested = "account.review_requested"
+	EventAccountRejected = "account.rejected"
+)
+
+// AccountEventVersion is bumped when an event could legitimately happen
+// again for the same account and must not be taken for a repeat.
+const AccountEventVersion = 1
+
+// AccountEvent is the notification sent to subscribers for each account
+// lifecycle event.
+type AccountEvent struct {
+	// ID is the same every time the same event is published, so
+	// subscribers can drop repeats.
+	ID         string    `json:"id"`
+	Version    int       `json:"version"`
+	Event      string    `json:"event"`
+	DID        string    `json:"did"`
+	Handle     string    `json:"handle"`
+	Tenant     string    `json:"tenant,omitempty"`
+	RequestID  string    `json:"requestId,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Consents is set on account.created, so the email and analytics
+	// pipelines can respect opt-outs from the first message.
+	Consents []Consent `json:"consents,omitempty"`
+	// ReviewFlags is set on account.review_requested and says why the
+	// signup was held.
+	ReviewFlags []string `json:"reviewFlags,omitempty"`
+	// AccountType is set on account.created for organizations and bots.
+	AccountType string `json:"accountType,omitempty"`
+	// Preferences is set on account.created with the preferences the
+	// account starts with.
+	Preferences *Preferences `json:"preferences,omitempty"`
+}
+
+// NewAccountEvent returns event for the account did with its deterministic
+// ID.
+func NewAccountEvent(event, did, handle, tenant string) AccountEvent {
+	return AccountEvent{
+		ID:      AccountEventID(did, event, AccountEventVersion),
+		Version: AccountEventVersion,
+		Event:   event,
+		DID:     did,
+		Handle:  handle,
+		Tenant:  tenant,
+	}
+}
+
+// AccountEventID derives an event's ID from the account, the event and its
+// version.
+func AccountEventID(did, event string, version int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", did, event, version)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// WebhookDelivery is the outcome of sending one event to one webhook
+// endpoint, after any retries.
+type WebhookDelivery struct {
+	Endpoint   string `json:"endpoint"`
+	Event      string `json:"event"`
+	DID        string `json:"did"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Delivered  bool   `json:"delivered"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BlocklistRequest is an admin operation on the runtime blocklist. Action is
+// "add", "remove", "list" or "audit". Who made a change is the caller the
+// request was authenticated as, never a field of the request.
+type BlocklistRequest struct {
+	Action string `json:"action"`
+	Handle string `json:"handle,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type BlocklistResponse struct {
+	Handles []BlockedHandle       `json:"handles,omitempty"`
+	Audit   []BlocklistAuditEntry `json:"audit,omitempty"`
+}
+
+// DataExport is everything held on one account, as handed to the account
+// holder in answer to a data access request.
+type DataExport struct {
+	ExportedAt  time.Time      `json:"exportedAt"`
+	Tenant      string         `json:"tenant"`
+	User        UserRecord     `json:"user"`
+	AuditEvents []AuditEvent   `json:"auditEvents"`
+	Consents    []Consent      `json:"consents"`
+	Emails      []PendingEmail `json:"emails"`
+}
+
+// CRMContact is what the CRM is told about an account.
+type CRMContact struct {
+	DID            string
+	Email          string
+	Handle         string
+	Tenant         string
+	ReferralSource string
+	SignupDate     time.Time
+	Verified       bool
+}
+
+// CRMSyncRequest asks for one account to be synced to the CRM, sent once
+// the account's email is verified.
+type CRMSyncRequest struct {
+	DID    string `json:"did"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// CRMSyncResponse says whether the contact was synced and, if it wasn't,
+// why not.
+type CRMSyncResponse struct {
+	Synced  bool   `json:"synced"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// HandleClaim reserves a handle for an email address until ExpiresAt, so
+// only a signup with that address can take it.
+type HandleClaim struct {
+	Handle          string    `json:"handle"`
+	Email           string    `json:"email"`
+	NormalizedEmail string    `json:"-"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
+// Signup steps recorded in SignupProgress, in the order they run.
+const (
+	SignupStepInviteCreated = "invite_created"
+	SignupStepPDSRegistered = "pds_registered"
+	SignupStepStored        = "stored"
+	SignupStepEmailQueued   = "email_queued"
+)
+
+// SignupProgress is how far the signup with an idempotency key got.
+// InviteCode is the invite minted for it and DID the account created on
+// the PDS, once those steps are done.
+type SignupProgress struct {
+	IdempotencyKey string
+	Handle         string
+	InviteCode     string
+	DID            string
+	Steps          []string
+}
+
+// Done reports whether step has been recorded.
+func (p SignupProgress) Done(step string) bool {
+	for _, done := range p.Steps {
+		if done == step {
+			return true
+		}
+	}
+	return false
+}
+
+// The availability check and handle claim types are defined in pkg/api.
+type (
+	AvailabilityRequest  = api.AvailabilityRequest
+	AvailabilityResponse = api.AvailabilityResponse
+	FieldAvailability    = api.FieldAvailability
+	HandleClaimRequest   = api.HandleClaimRequest
+	HandleClaimResponse  = api.HandleClaimResponse
+)
+
+// Referral states. A referral is pending until the referred account
+// verifies, when its referrer is rewarded; referrals that fail the fraud
+// checks are kept as rejected, with the reason, for review.
+const (
+	ReferralPending  = "pending"
+	ReferralRewarded = "rewarded"
+	ReferralRejected = "rejected"
+)
+
+// Referral links a new account to the account whose code it signed up
+// with.
+type Referral struct {
+	ReferredDID string    `json:"referredDid"`
+	Code        string    `json:"code"`
+	ReferrerDID string    `json:"referrerDid"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason,omitempty"`
+	RewardedAt  time.Time `json:"rewardedAt,omitempty"`
+}
+
+// ReferralCodeOwner is the account a referral code belongs to, with what
+// the self-referral checks compare against.
+type ReferralCodeOwner struct {
+	DID             string
+	NormalizedEmail string
+	Status          string
+}
+
+// ReferralStats counts the signups a code drove, by state.
+type ReferralStats struct {
+	Code     string `json:"code"`
+	Signups  int    `json:"signups"`
+	Pending  int    `json:"pending"`
+	Rewarded int    `json:"rewarded"`
+	Rejected int    `json:"rejected"`
+}
+
+// ReferralRequest is a referral operation. Action is "issue_code" or
+// "verified", with DID, or "stats", with Code.
+type ReferralRequest struct {
+	Action string `json:"action"`
+	DID    string `json:"did,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type ReferralResponse struct {
+	Code     string         `json:"code,omitempty"`
+	Referral *Referral      `json:"referral,omitempty"`
+	Stats    *ReferralStats `json:"stats,omitempty"`
+}
+
+type (
+	LifecycleRequest  = api.LifecycleRequest
+	LifecycleResponse = api.LifecycleResponse
+)
+
+type AdminRequest = api.AdminRequest
+
+// AdminResponse carries the account an action was applied to, whether a
+// re-sent email had to be queued, any minted invite codes and the signup
+// quotas or stats an operator asked for.
+type AdminResponse struct {
+	Account      *UserRecord           `json:"account,omitempty"`
+	EmailQueued  bool                  `json:"emailQueued,omitempty"`
+	InviteCodes  []string              `json:"inviteCodes,omitempty"`
+	Bootstrapped []BootstrappedAccount `json:"bootstrapped,omitempty"`
+	Quota        *SignupQuotaUsage     `json:"quota,omitempty"`
+	Stats        []SignupStats         `json:"stats,omitempty"`
+}
+
+// Periods the signup stats are rolled up by.
+const (
+	StatsPeriodHour = "hour"
+	StatsPeriodDay  = "day"
+)
+
+// SignupStats are the signups in one hour or UTC day. Verified counts the
+// accounts created in the period that have verified their email since, so
+// a period's conversion keeps rising for a while after it ends.
+// FailureReasons breaks the failed attempts down by validation code, with
+// internal_error for the rest.
+type SignupStats struct {
+	Period           string         `json:"period"`
+	Start            time.Time      `json:"start"`
+	Signups          int            `json:"signups"`
+	Verified         int            `json:"verified"`
+	VerificationRate float64        `json:"verificationRate"`
+	FailedAttempts   int            `json:"failedAttempts"`
+	FailureReasons   map[string]int `json:"failureReasons,omitempty"`
+}
+
+// SignupStatsResult summarizes one run of the signup stats aggregation.
+type SignupStatsResult struct {
+	Periods int `json:"periods"`
+}
+
+// SignupQuotaUsage is how much of today's signup quotas is used, as the
+// quota action reports it. Global is only set when there is a daily quota,
+// and Domain when the request named an email domain.
+type SignupQuotaUsage struct {
+	ResetsAt time.Time   `json:"resetsAt"`
+	Global   *QuotaCount `json:"global,omitempty"`
+	Domain   *QuotaCount `json:"domain,omitempty"`
+}
+
+// QuotaCount is the use of one daily quota. A domain without a cap has a
+// Limit of 0 and isn't counted.
+type QuotaCount struct {
+	Domain    string `json:"domain,omitempty"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining int    `json:"remaining"`
+}
+
+// BootstrappedAccount is an operator account the bootstrap action
+// provisioned, found already there, or failed to create with Error.
+// Password is only set for accounts it created and can't be retrieved
+// again.
+type BootstrappedAccount struct {
+	Handle   string `json:"handle"`
+	Email    string `json:"email"`
+	DID      string `json:"did,omitempty"`
+	Role     string `json:"role"`
+	Password string `json:"password,omitempty"`
+	Existing bool   `json:"existing,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PhoneCode is the one-time code last sent to an account's phone.
+type PhoneCode struct {
+	DID      string
+	Phone    string
+	CodeHash string
+	Attempts int
+}
+
+// PhoneRequest is a phone verification step. Action is "send", with
+// Phone, or "verify", with the Code that was sent. AccessJWT is a session
+// of the account, as returned at signup, proving the caller owns it.
+type PhoneRequest struct {
+	Action    string `json:"action"`
+	DID       string `json:"did"`
+	AccessJWT string `json:"accessJwt"`
+	Phone     string `json:"phone,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// PhoneResponse reports where a code was sent, or that the phone is now
+// verified and whether that took the account out of review.
+type PhoneResponse struct {
+	Phone     string     `json:"phone"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Verified  bool       `json:"verified"`
+	Approved  bool       `json:"approved,omitempty"`
+}
+
+type (
+	PrivacyRequest  = api.PrivacyRequest
+	PrivacyResponse = api.PrivacyResponse
+)
+
+// RepairRequest runs the account repair job over a tenant. Stored accounts
+// written before the current schema are brought up to date, and PDS
+// accounts with no stored account are reported, or deleted with
+// DeleteOrphans. DryRun reports what would change without changing it.
+type RepairRequest struct {
+	Tenant        string `json:"tenant,omitempty"`
+	BatchSize     int    `json:"batchSize,omitempty"`
+	DryRun        bool   `json:"dryRun,omitempty"`
+	DeleteOrphans bool   `json:"deleteOrphans,omitempty"`
+}
+
+type RepairResponse struct {
+	DryRun bool `json:"dryRun,omitempty"`
+	// Stale counts the stored accounts that needed repair, and Repaired
+	// those that were.
+	Stale    int `json:"stale"`
+	Repaired int `json:"repaired"`
+	// Orphans are the DIDs of PDS accounts with no stored account, and
+	// Deleted counts those removed from the PDS.
+	Orphans []string        `json:"orphans,omitempty"`
+	Deleted int             `json:"deleted"`
+	Failed  []RepairFailure `json:"failed,omitempty"`
+}
+
+// RepairFailure is an account the repair job could not fix.
+type RepairFailure struct {
+	DID   string `json:"did"`
+	Error string `json:"error"`
+}
+
+// ReviewRequest is a moderator action on the queue of signups held for
+// review. Action is "list", "approve" or "reject"; approve and reject need
+// the DID.
+type ReviewRequest struct {
+	Action string `json:"action"`
+	DID    string `json:"did,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type ReviewResponse struct {
+	Queue      []ReviewItem       `json:"queue,omitempty"`
+	Transition *LifecycleResponse `json:"transition,omitempty"`
 }
 
-type BlockedUsernames struct {
-	Generic []string `json:"generic"`
+// ReviewItem is a signup waiting in the review queue.
+type ReviewItem struct {
+	DID         string   `json:"did"`
+	Handle      string   `json:"handle"`
+	Email       string   `json:"email"`
+	DisplayName string   `json:"displayName,omitempty"`
+	ReviewFlags []string `json:"reviewFlags,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
 }
@@ -0,0 +1,92 @@
+// Package notify publishes account lifecycle notifications to SNS for
+// consumers that subscribe to a topic rather than reading the event bus.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// EventAttribute carries the event name as a message attribute, so
+// subscribers can filter on it without parsing the body. EventIDAttribute
+// carries the event's ID for deduplication. MarketingConsentAttribute is
+// "true" or "false" on events that carry a marketing choice, so marketing
+// subscriptions can filter out opt-outs.
+const (
+	EventAttribute            = "event"
+	EventIDAttribute          = "event_id"
+	MarketingConsentAttribute = "marketing_consent"
+)
+
+type SNSAPI interface {
+	Publish(ctx context.Context, input *sns.PublishInput, opts ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+type Publisher struct {
+	Client   SNSAPI
+	TopicARN string
+	Retry    retry.Policy
+	now      func() time.Time
+}
+
+func NewPublisher(client SNSAPI, topicARN string, retryPolicy retry.Policy) *Publisher {
+	return &Publisher{Client: client, TopicARN: topicARN, Retry: retryPolicy, now: time.Now}
+}
+
+// Publish sends event to the topic, filling in the time and the request ID
+// from ctx.
+func (p *Publisher) Publish(ctx context.Context, event models.AccountEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = p.now().UTC()
+	}
+	if event.RequestID == "" {
+		event.RequestID = logging.RequestID(ctx)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account event: %w", err)
+	}
+
+	attributes := map[string]types.MessageAttributeValue{
+		EventAttribute: {DataType: aws.String("String"), StringValue: aws.String(event.Event)},
+	}
+	if event.ID != "" {
+		attributes[EventIDAttribute] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(event.ID)}
+	}
+	if consent, ok := models.FindConsent(event.Consents, models.ConsentMarketing); ok {
+		attributes[MarketingConsentAttribute] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(strconv.FormatBool(consent.Granted))}
+	}
+
+	input := &sns.PublishInput{
+		TopicArn:          aws.String(p.TopicARN),
+		Message:           aws.String(string(body)),
+		MessageAttributes: attributes,
+	}
+
+	ctx, seg := tracing.Begin(ctx, "SNS", tracing.NamespaceAWS)
+	seg.SetAWS("Publish")
+	err = p.Retry.Do(ctx, "sns.Publish", func(ctx context.Context) error {
+		_, err := p.Client.Publish(ctx, input)
+		return err
+	})
+	seg.Close(err)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event", event.Event).Error("Failed to publish account event")
+		return fmt.Errorf("failed to publish account event: %w", err)
+	}
+
+	logging.FromContext(ctx).WithField("event", event.Event).Info("Published account event")
+	return nil
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSNSClient struct {
+	mock.Mock
+}
+
+func (m *mockSNSClient) Publish(ctx context.Context, input *sns.PublishInput, opts ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	args := m.Called(ctx, input)
+	if output, ok := args.Get(0).(*sns.PublishOutput); ok {
+		return output, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestPublish(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Published"},
+		{
+			name:        "SNS Error",
+			mockError:   errors.New("topic not found"),
+			expectedErr: "failed to publish account event: topic not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockSNSClient)
+			publisher := NewPublisher(client, "arn:aws:sns:us-east-1:123456789012:account-events", retry.Policy{})
+			publisher.now = func() time.Time { return now }
+
+			var published models.AccountEvent
+			client.On("Publish", mock.Anything, mock.MatchedBy(func(input *sns.PublishInput) bool {
+				if aws.ToString(input.TopicArn) != publisher.TopicARN {
+					return false
+				}
+				if aws.ToString(input.MessageAttributes[EventAttribute].StringValue) != models.EventAccountCreated {
+					return false
+				}
+				if aws.ToString(input.MessageAttributes[EventIDAttribute].StringValue) != "evt-1" {
+					return false
+				}
+				return json.Unmarshal([]byte(aws.ToString(input.Message)), &published) == nil
+			})).Return(&sns.PublishOutput{}, tt.mockError)
+
+			err := publisher.Publish(context.Background(), models.AccountEvent{
+				ID:     "evt-1",
+				Event:  models.EventAccountCreated,
+				DID:    "did:example:123",
+				Handle: "alice.shareframe.social",
+				Tenant: "default",
+			})
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.AccountEvent{
+					ID:         "evt-1",
+					Event:      models.EventAccountCreated,
+					DID:        "did:example:123",
+					Handle:     "alice.shareframe.social",
+					Tenant:     "default",
+					OccurredAt: now,
+				}, published)
+			}
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPublishMarketingConsentAttribute(t *testing.T) {
+	tests := []struct {
+		name     string
+		consents []models.Consent
+		expected string
+	}{
+		{name: "Opted In", consents: []models.Consent{{Purpose: models.ConsentMarketing, Granted: true, TextVersion: "v1"}}, expected: "true"},
+		{name: "Opted Out", consents: []models.Consent{{Purpose: models.ConsentMarketing, TextVersion: "v1"}}, expected: "false"},
+		{name: "No Choice", consents: []models.Consent{{Purpose: models.ConsentDataProcessing, Granted: true, TextVersion: "v1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockSNSClient)
+			publisher := NewPublisher(client, "arn:aws:sns:us-east-1:123456789012:account-events", retry.Policy{})
+
+			client.On("Publish", mock.Anything, mock.MatchedBy(func(input *sns.PublishInput) bool {
+				attribute, ok := input.MessageAttributes[MarketingConsentAttribute]
+				if tt.expected == "" {
+					return !ok
+				}
+				return aws.ToString(attribute.StringValue) == tt.expected
+			})).Return(&sns.PublishOutput{}, nil)
+
+			event := models.NewAccountEvent(models.EventAccountCreated, "did:example:123", "alice.shareframe.social", "default")
+			event.Consents = tt.consents
+			assert.NoError(t, publisher.Publish(context.Background(), event))
+			client.AssertExpectations(t)
+		})
+	}
+}
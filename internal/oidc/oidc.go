@@ -0,0 +1,357 @@
+// Package oidc verifies the identity tokens issued by the social sign-in
+// providers ShareFrame accepts, so a signup can take its email address from
+// Google or Apple instead of asking for it. A token must be RS256, signed
+// by a key the provider publishes, issued by the provider to one of our
+// client IDs, unexpired, and carry a verified email address.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+const (
+	GoogleKeysURL = "https://www.googleapis.com/oauth2/v3/certs"
+	AppleKeysURL  = "https://appleid.apple.com/auth/keys"
+)
+
+// DefaultKeysTTL is how long a provider's signing keys are cached. A token
+// signed with a key that isn't cached fetches them again sooner.
+const DefaultKeysTTL = time.Hour
+
+const (
+	algorithmRS256 = "RS256"
+	// clockSkew is how far a token's timestamps may be off our clock.
+	clockSkew = time.Minute
+	// minRefetch stops tokens naming unknown keys from making us fetch the
+	// provider's keys on every request.
+	minRefetch = time.Minute
+	// maxKeysSize bounds how much of a key set response is read.
+	maxKeysSize = 64 << 10
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrMalformed       = errors.New("malformed identity token")
+	ErrSignature       = errors.New("identity token signature is invalid")
+	ErrAudience        = errors.New("identity token was not issued to this service")
+	ErrExpired         = errors.New("identity token has expired")
+	ErrEmailUnverified = errors.New("identity token has no verified email address")
+)
+
+// Provider is a sign-in provider and the client IDs we are registered with
+// it under; its tokens' aud must be one of them.
+type Provider struct {
+	Name      string
+	Issuers   []string
+	KeysURL   string
+	ClientIDs []string
+}
+
+// Google returns the Google provider for clientIDs.
+func Google(clientIDs []string) Provider {
+	return Provider{
+		Name:      ProviderGoogle,
+		Issuers:   []string{"https://accounts.google.com", "accounts.google.com"},
+		KeysURL:   GoogleKeysURL,
+		ClientIDs: clientIDs,
+	}
+}
+
+// Apple returns the Sign in with Apple provider for clientIDs, the app
+// bundle IDs and services IDs.
+func Apple(clientIDs []string) Provider {
+	return Provider{
+		Name:      ProviderApple,
+		Issuers:   []string{"https://appleid.apple.com"},
+		KeysURL:   AppleKeysURL,
+		ClientIDs: clientIDs,
+	}
+}
+
+// Identity is what a verified token says about the user.
+type Identity struct {
+	Provider string
+	Subject  string
+	Email    string
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type cachedKeys struct {
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// Verifier checks identity tokens against the configured providers,
+// caching their signing keys for KeysTTL so warm invocations skip the
+// fetch.
+type Verifier struct {
+	Providers  map[string]Provider
+	HTTPClient HTTPClient
+	Retry      retry.Policy
+	KeysTTL    time.Duration
+
+	mu   sync.Mutex
+	keys map[string]cachedKeys
+	now  func() time.Time
+}
+
+func NewVerifier(providers []Provider, client HTTPClient, retryPolicy retry.Policy) *Verifier {
+	v := &Verifier{
+		Providers:  map[string]Provider{},
+		HTTPClient: client,
+		Retry:      retryPolicy,
+		KeysTTL:    DefaultKeysTTL,
+		keys:       map[string]cachedKeys{},
+		now:        time.Now,
+	}
+	for _, provider := range providers {
+		if len(provider.ClientIDs) > 0 {
+			v.Providers[provider.Name] = provider
+		}
+	}
+	return v
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Email         string   `json:"email"`
+	EmailVerified flag     `json:"email_verified"`
+}
+
+// audience is the aud claim, which may be a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// flag is a boolean claim, which Apple sends as the string "true".
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*f = flag(b)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*f = flag(s == "true")
+	return nil
+}
+
+// Verify checks token as issued by the named provider and returns the
+// identity it asserts.
+func (v *Verifier) Verify(ctx context.Context, providerName, token string) (Identity, error) {
+	provider, ok := v.Providers[providerName]
+	if !ok {
+		return Identity{}, ErrUnknownProvider
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, ErrMalformed
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Identity{}, err
+	}
+	if h.Algorithm != algorithmRS256 || h.KeyID == "" {
+		return Identity{}, ErrSignature
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, ErrMalformed
+	}
+
+	key, err := v.key(ctx, provider, h.KeyID)
+	if err != nil {
+		return Identity{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return Identity{}, ErrSignature
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return Identity{}, err
+	}
+	if !contains(provider.Issuers, c.Issuer) || !audienceMatches(c.Audience, provider.ClientIDs) {
+		return Identity{}, ErrAudience
+	}
+	now := v.now()
+	if now.Add(-clockSkew).Unix() >= c.ExpiresAt || now.Add(clockSkew).Unix() < c.IssuedAt {
+		return Identity{}, ErrExpired
+	}
+	if c.Subject == "" || c.Email == "" || !bool(c.EmailVerified) {
+		return Identity{}, ErrEmailUnverified
+	}
+
+	return Identity{Provider: provider.Name, Subject: c.Subject, Email: c.Email}, nil
+}
+
+// key returns the provider's signing key keyID, fetching the provider's
+// keys when they are stale or don't include it.
+func (v *Verifier) key(ctx context.Context, provider Provider, keyID string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	cached, ok := v.keys[provider.Name]
+	age := v.now().Sub(cached.fetched)
+	if ok && age < v.KeysTTL {
+		if key, found := cached.keys[keyID]; found {
+			return key, nil
+		}
+		if age < minRefetch {
+			return nil, ErrSignature
+		}
+	}
+
+	var keys map[string]*rsa.PublicKey
+	err := v.Retry.Do(ctx, "oidc.FetchKeys", func(ctx context.Context) error {
+		var err error
+		keys, err = v.fetchKeys(ctx, provider.KeysURL)
+		return err
+	})
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyIdentityProvider, err)
+		logging.FromContext(ctx).WithError(err).WithField("provider", provider.Name).Error("Failed to fetch identity provider keys")
+		return nil, apperr.Errorf(apperr.Upstream, "failed to fetch %s signing keys: %w", provider.Name, err)
+	}
+	v.keys[provider.Name] = cachedKeys{keys: keys, fetched: v.now()}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"provider": provider.Name,
+		"keys":     len(keys),
+	}).Info("Fetched identity provider keys")
+
+	key, found := keys[keyID]
+	if !found {
+		return nil, ErrSignature
+	}
+	return key, nil
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context, url string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key set request: %w", err)
+	}
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("key set request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if retry.IsRetryableStatus(resp.StatusCode) {
+		return nil, &retry.StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from key set: %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxKeysSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || jwk.KeyID == "" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 {
+			continue
+		}
+		keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
+
+func audienceMatches(aud audience, clientIDs []string) bool {
+	for _, a := range aud {
+		if contains(clientIDs, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
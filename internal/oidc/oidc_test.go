@@ -0,0 +1,240 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+var (
+	signingKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	testNow       = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func keySet(keys map[string]*rsa.PublicKey) []byte {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			KeyType: "RSA",
+			KeyID:   kid,
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	body, _ := json.Marshal(set)
+	return body
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, h map[string]string, c map[string]interface{}) string {
+	headerJSON, _ := json.Marshal(h)
+	claimsJSON, _ := json.Marshal(c)
+	input := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func googleClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":            "https://accounts.google.com",
+		"sub":            "1234567890",
+		"aud":            "web-client.apps.googleusercontent.com",
+		"exp":            testNow.Add(time.Hour).Unix(),
+		"iat":            testNow.Unix(),
+		"email":          "alice@gmail.com",
+		"email_verified": true,
+	}
+}
+
+func newTestVerifier(fetches *int) *Verifier {
+	v := NewVerifier([]Provider{
+		Google([]string{"web-client.apps.googleusercontent.com"}),
+		Apple([]string{"social.shareframe.app"}),
+		Google(nil),
+	}, &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*fetches++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(keySet(map[string]*rsa.PublicKey{"key-1": &signingKey.PublicKey}))),
+			}, nil
+		},
+	}, retry.Policy{})
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	rs256 := map[string]string{"alg": "RS256", "kid": "key-1"}
+
+	tests := []struct {
+		name        string
+		provider    string
+		key         *rsa.PrivateKey
+		header      map[string]string
+		claims      func() map[string]interface{}
+		expected    Identity
+		expectedErr error
+	}{
+		{
+			name:     "Google",
+			provider: ProviderGoogle,
+			claims:   googleClaims,
+			expected: Identity{Provider: ProviderGoogle, Subject: "1234567890", Email: "alice@gmail.com"},
+		},
+		{
+			name:     "Apple",
+			provider: ProviderApple,
+			claims: func() map[string]interface{} {
+				c := googleClaims()
+				c["iss"] = "https://appleid.apple.com"
+				c["aud"] = []string{"social.shareframe.app"}
+				c["email"] = "abc123@privaterelay.appleid.com"
+				c["email_verified"] = "true"
+				return c
+			},
+			expected: Identity{Provider: ProviderApple, Subject: "1234567890", Email: "abc123@privaterelay.appleid.com"},
+		},
+		{
+			name:        "Unknown Provider",
+			provider:    "facebook",
+			claims:      googleClaims,
+			expectedErr: ErrUnknownProvider,
+		},
+		{
+			name:        "Wrong Key",
+			provider:    ProviderGoogle,
+			key:         otherKey,
+			claims:      googleClaims,
+			expectedErr: ErrSignature,
+		},
+		{
+			name:        "Unsigned",
+			provider:    ProviderGoogle,
+			header:      map[string]string{"alg": "none", "kid": "key-1"},
+			claims:      googleClaims,
+			expectedErr: ErrSignature,
+		},
+		{
+			name:        "Unknown Key",
+			provider:    ProviderGoogle,
+			header:      map[string]string{"alg": "RS256", "kid": "key-2"},
+			claims:      googleClaims,
+			expectedErr: ErrSignature,
+		},
+		{
+			name:     "Other Client",
+			provider: ProviderGoogle,
+			claims: func() map[string]interface{} {
+				c := googleClaims()
+				c["aud"] = "someone-else.apps.googleusercontent.com"
+				return c
+			},
+			expectedErr: ErrAudience,
+		},
+		{
+			name:     "Other Issuer",
+			provider: ProviderApple,
+			claims: func() map[string]interface{} {
+				c := googleClaims()
+				c["aud"] = "social.shareframe.app"
+				return c
+			},
+			expectedErr: ErrAudience,
+		},
+		{
+			name:     "Expired",
+			provider: ProviderGoogle,
+			claims: func() map[string]interface{} {
+				c := googleClaims()
+				c["exp"] = testNow.Add(-2 * time.Minute).Unix()
+				return c
+			},
+			expectedErr: ErrExpired,
+		},
+		{
+			name:     "Unverified Email",
+			provider: ProviderGoogle,
+			claims: func() map[string]interface{} {
+				c := googleClaims()
+				c["email_verified"] = false
+				return c
+			},
+			expectedErr: ErrEmailUnverified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, header := tt.key, tt.header
+			if key == nil {
+				key = signingKey
+			}
+			if header == nil {
+				header = rs256
+			}
+
+			var fetches int
+			identity, err := newTestVerifier(&fetches).Verify(ctx, tt.provider, sign(t, key, header, tt.claims()))
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, identity)
+		})
+	}
+}
+
+func TestVerifyCachesKeys(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	v := newTestVerifier(&fetches)
+	token := sign(t, signingKey, map[string]string{"alg": "RS256", "kid": "key-1"}, googleClaims())
+	unknown := sign(t, signingKey, map[string]string{"alg": "RS256", "kid": "key-2"}, googleClaims())
+
+	_, err := v.Verify(ctx, ProviderGoogle, token)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, ProviderGoogle, token)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, ProviderGoogle, unknown)
+	assert.ErrorIs(t, err, ErrSignature)
+	assert.Equal(t, 1, fetches)
+
+	v.now = func() time.Time { return testNow.Add(2 * time.Minute) }
+	_, err = v.Verify(ctx, ProviderGoogle, unknown)
+	assert.ErrorIs(t, err, ErrSignature)
+	assert.Equal(t, 2, fetches)
+
+	_, err = v.Verify(ctx, "malformed", "not-a-token")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = v.Verify(ctx, ProviderGoogle, "not-a-token")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
@@ -0,0 +1,77 @@
+// Package onboarding seeds a new account so it doesn't start out blank: a
+// profile record with its display name, bio, pronouns, website and
+// ShareFrame theme, and follows of a starter list of accounts.
+package onboarding
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// PDS writes to the new account's repo under its own session.
+type PDS interface {
+	PutProfile(ctx context.Context, accessJWT, did string, profile models.ProfileRecord) error
+	Follow(ctx context.Context, accessJWT, did, subject string) error
+}
+
+// Seeder seeds accounts on the PDS and records the outcome in Store.
+type Seeder struct {
+	PDS   PDS
+	Store postgres.OnboardingStore
+	// StarterFollows are the DIDs every new account follows.
+	StarterFollows []string
+}
+
+// Seed seeds the account stored as record using the session accessJWT
+// from its registration, and returns the onboarding state it stored.
+// Failures are logged and leave the account partly seeded.
+func (s *Seeder) Seed(ctx context.Context, accessJWT string, record models.UserRecord) string {
+	state := models.OnboardingSeeded
+
+	profile := models.ProfileRecord{
+		Description: record.Bio,
+		Pronouns:    record.Pronouns,
+		Website:     record.Website,
+		Labels:      models.ProfileLabels(record.AccountType),
+		Theme:       record.ProfileTheme(),
+	}
+	if record.DisplayName != record.Handle {
+		// The stored display name falls back to the handle, which the
+		// profile doesn't need to repeat.
+		profile.DisplayName = record.DisplayName
+	}
+	if err := s.PDS.PutProfile(ctx, accessJWT, record.DID, profile); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", record.DID).Error("Account seeded without its profile")
+		state = models.OnboardingPartial
+	}
+
+	followed := 0
+	for _, subject := range s.StarterFollows {
+		if subject == record.DID {
+			continue
+		}
+		if err := s.PDS.Follow(ctx, accessJWT, record.DID, subject); err != nil {
+			logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+				"did":     record.DID,
+				"subject": subject,
+			}).Warn("Account seeded without a starter follow")
+			state = models.OnboardingPartial
+			continue
+		}
+		followed++
+	}
+
+	if err := s.Store.SetOnboardingState(ctx, record.DID, state); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", record.DID).Error("Failed to record onboarding state")
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":      record.DID,
+		"state":    state,
+		"followed": followed,
+	}).Info("Account seeded")
+	return state
+}
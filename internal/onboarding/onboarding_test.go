@@ -0,0 +1,101 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/memory"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPDS struct {
+	mock.Mock
+}
+
+func (m *mockPDS) PutProfile(ctx context.Context, accessJWT, did string, profile models.ProfileRecord) error {
+	return m.Called(ctx, accessJWT, did, profile).Error(0)
+}
+
+func (m *mockPDS) Follow(ctx context.Context, accessJWT, did, subject string) error {
+	return m.Called(ctx, accessJWT, did, subject).Error(0)
+}
+
+var alice = models.UserRecord{
+	DID:            "did:plc:alice",
+	Email:          "alice@example.com",
+	Handle:         "alice.shareframe.social",
+	DisplayName:    "Alice",
+	Bio:            "Photographer in Lisbon",
+	Pronouns:       "she/her",
+	Website:        "https://alice.example",
+	Theme:          `{"mode":"dark"}`,
+	PrimaryColor:   "#112233",
+	SecondaryColor: "#445566",
+	AccountType:    models.AccountTypePerson,
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	expectedProfile := models.ProfileRecord{
+		DisplayName: "Alice",
+		Description: "Photographer in Lisbon",
+		Pronouns:    "she/her",
+		Website:     "https://alice.example",
+		Theme:       &models.ProfileTheme{Settings: []byte(`{"mode":"dark"}`), PrimaryColor: "#112233", SecondaryColor: "#445566"},
+	}
+
+	tests := []struct {
+		name          string
+		profileErr    error
+		followErr     error
+		expectedState string
+	}{
+		{name: "Seeded", expectedState: models.OnboardingSeeded},
+		{name: "Profile Failed", profileErr: errors.New("unexpected status code: 500"), expectedState: models.OnboardingPartial},
+		{name: "Follow Failed", followErr: errors.New("unexpected status code: 400"), expectedState: models.OnboardingPartial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			assert.NoError(t, store.StoreUser(ctx, alice))
+			pds := new(mockPDS)
+			pds.On("PutProfile", ctx, "access-jwt", alice.DID, expectedProfile).Return(tt.profileErr)
+			pds.On("Follow", ctx, "access-jwt", alice.DID, "did:plc:team").Return(nil)
+			pds.On("Follow", ctx, "access-jwt", alice.DID, "did:plc:news").Return(tt.followErr)
+
+			// Alice is on the starter list herself but doesn't follow
+			// herself.
+			seeder := &Seeder{PDS: pds, Store: store, StarterFollows: []string{"did:plc:team", alice.DID, "did:plc:news"}}
+			state := seeder.Seed(ctx, "access-jwt", alice)
+
+			assert.Equal(t, tt.expectedState, state)
+			record, _ := store.GetUser(ctx, alice.DID)
+			assert.Equal(t, tt.expectedState, record.Onboarding)
+			pds.AssertExpectations(t)
+			pds.AssertNotCalled(t, "Follow", ctx, "access-jwt", alice.DID, alice.DID)
+		})
+	}
+}
+
+func TestSeedOrganization(t *testing.T) {
+	ctx := context.Background()
+	org := models.UserRecord{DID: "did:plc:acme", Handle: "acme.shareframe.social", DisplayName: "acme.shareframe.social", Theme: "{}", AccountType: models.AccountTypeOrganization}
+	store := memory.New()
+	assert.NoError(t, store.StoreUser(ctx, org))
+	pds := new(mockPDS)
+	// The display name is only the handle fallback, so it is left off.
+	pds.On("PutProfile", ctx, "access-jwt", org.DID, models.ProfileRecord{
+		Labels: []string{models.LabelOrganization},
+		Theme:  &models.ProfileTheme{},
+	}).Return(nil)
+
+	seeder := &Seeder{PDS: pds, Store: store}
+	state := seeder.Seed(ctx, "access-jwt", org)
+
+	assert.Equal(t, models.OnboardingSeeded, state)
+	pds.AssertExpectations(t)
+}
@@ -0,0 +1,271 @@
+// Package openapi describes the service's REST routes as an OpenAPI 3
+// document and checks request bodies against it. The document is generated
+// from the route table and the Go types each route takes and returns, so it
+// can't drift from what the handlers decode; client teams generate their
+// SDKs from it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+)
+
+// Version is the OpenAPI version of the documents New returns.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document, with the parts of the specification
+// the service's routes use.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema. An object generated from a struct is closed:
+// Closed sets additionalProperties to false, while AdditionalProperties
+// describes the values of a map.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"-"`
+	Closed               bool               `json:"-"`
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	if s.Closed {
+		out.AdditionalProperties = false
+	} else if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	}
+	return json.Marshal(out)
+}
+
+// Route is a route to describe. Request is the type of its JSON body, nil
+// for a route without one; Response is the type it answers Status with.
+// Failures are answered with an api.ErrorResponse.
+type Route struct {
+	Path        string
+	Method      string
+	OperationID string
+	Summary     string
+	Tag         string
+	Request     reflect.Type
+	Response    reflect.Type
+	Status      int
+}
+
+const refPrefix = "#/components/schemas/"
+
+// New returns the document describing routes.
+func New(info Info, routes []Route) *Document {
+	g := &generator{names: map[reflect.Type]string{}, schemas: map[string]*Schema{}}
+	doc := &Document{OpenAPI: Version, Info: info, Paths: map[string]*PathItem{}}
+	errorSchema := g.schema(reflect.TypeOf(api.ErrorResponse{}))
+
+	for _, route := range routes {
+		op := &Operation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Responses:   map[string]*Response{},
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(g.schema(route.Request))}
+		}
+		success := &Response{Description: http.StatusText(route.Status)}
+		if route.Response != nil {
+			success.Content = jsonContent(g.schema(route.Response))
+		}
+		op.Responses[strconv.Itoa(route.Status)] = success
+		op.Responses["default"] = &Response{Description: "Failure", Content: jsonContent(errorSchema)}
+
+		item := doc.Paths[route.Path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[route.Path] = item
+		}
+		if route.Method == http.MethodGet {
+			item.Get = op
+		} else {
+			item.Post = op
+		}
+	}
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// resolve follows a schema's reference to the component it names.
+func (d *Document) resolve(schema *Schema) *Schema {
+	if schema.Ref == "" {
+		return schema
+	}
+	if resolved, ok := d.Components.Schemas[strings.TrimPrefix(schema.Ref, refPrefix)]; ok {
+		return resolved
+	}
+	return &Schema{}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator builds schemas, adding one component per named struct type.
+type generator struct {
+	names   map[reflect.Type]string
+	schemas map[string]*Schema
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return &Schema{Ref: refPrefix + g.component(t)}
+	}
+	return &Schema{}
+}
+
+// component names the component for t, adding it the first time. The
+// request and response types of pkg/api and internal/models go by their
+// own names; other types, such as graphql.Request, and types whose name is
+// taken are qualified by their package.
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken || (pkg != "api" && pkg != "models") {
+		name = pkg + "." + name
+	}
+	g.names[t] = name
+	// Reserve the name before generating the fields, which may refer
+	// back to t.
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.object(t)
+	return name
+}
+
+// object is the closed object schema of a struct's JSON fields.
+func (g *generator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, Closed: true}
+	g.fields(t, schema.Properties)
+	return schema
+}
+
+// fields adds the JSON fields of t to properties, following
+// encoding/json: embedded structs without a name contribute their fields.
+func (g *generator) fields(t reflect.Type, properties map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+	}
+}
@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Handle   string    `json:"handle"`
+	Age      int       `json:"age,omitempty"`
+	Consents []consent `json:"consents,omitempty"`
+	Labels   map[string]string
+	Secret   string `json:"-"`
+	embedded
+}
+
+type embedded struct {
+	Referrer string `json:"referrer,omitempty"`
+}
+
+type consent struct {
+	Purpose    string    `json:"purpose"`
+	Granted    bool      `json:"granted"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+type signupResult struct {
+	DID string `json:"did"`
+}
+
+func testDocument() *Document {
+	return New(Info{Title: "Test", Version: "1"}, []Route{
+		{Path: "/healthz", Method: http.MethodGet, OperationID: "health", Status: http.StatusOK},
+		{
+			Path:        "/signup",
+			Method:      http.MethodPost,
+			OperationID: "signup",
+			Summary:     "Sign up",
+			Tag:         "accounts",
+			Request:     reflect.TypeOf(signup{}),
+			Response:    reflect.TypeOf(&signupResult{}),
+			Status:      http.StatusCreated,
+		},
+	})
+}
+
+func TestNew(t *testing.T) {
+	doc := testDocument()
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.NotNil(t, doc.Paths["/healthz"].Get)
+	assert.Nil(t, doc.Paths["/healthz"].Get.RequestBody)
+
+	signup := doc.Paths["/signup"].Post
+	assert.Equal(t, []string{"accounts"}, signup.Tags)
+	assert.Equal(t, "#/components/schemas/openapi.signup", signup.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/openapi.signupResult", signup.Responses["201"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/ErrorResponse", signup.Responses["default"].Content["application/json"].Schema.Ref)
+
+	schema := doc.Components.Schemas["openapi.signup"]
+	assert.True(t, schema.Closed)
+	assert.ElementsMatch(t, []string{"handle", "age", "consents", "Labels", "referrer"}, keys(schema.Properties))
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, schema.Properties["age"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["Labels"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, doc.Components.Schemas["openapi.consent"].Properties["recordedAt"])
+}
+
+func TestSchemaJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+		want   string
+	}{
+		{
+			name:   "Closed object",
+			schema: &Schema{Type: "object", Properties: map[string]*Schema{"handle": {Type: "string"}}, Closed: true},
+			want:   `{"type":"object","properties":{"handle":{"type":"string"}},"additionalProperties":false}`,
+		},
+		{
+			name:   "Map",
+			schema: &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}},
+			want:   `{"type":"object","additionalProperties":{"type":"integer"}}`,
+		},
+		{
+			name:   "Any value",
+			schema: &Schema{},
+			want:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := json.Marshal(tt.schema)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(out))
+		})
+	}
+}
+
+func keys(m map[string]*Schema) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
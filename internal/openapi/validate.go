@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+// Problems a request body can have, as the "problem" param of its
+// failures.
+const (
+	ProblemUnknownField = "unknown_field"
+	ProblemWrongType    = "wrong_type"
+)
+
+// ValidateRequest checks the JSON body of a POST to path against the
+// operation's request schema. Every unknown field and value of the wrong
+// type is a failure, as a validate.ValidationErrors of CodeInvalidRequest
+// failures reported against the field's path, such as "consents[0].granted".
+// A body that isn't JSON is left for the handler to reject, and so is a
+// path with no request schema.
+func (d *Document) ValidateRequest(path string, body []byte) error {
+	item := d.Paths[path]
+	if item == nil || item.Post == nil || item.Post.RequestBody == nil {
+		return nil
+	}
+	schema := item.Post.RequestBody.Content["application/json"].Schema
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	var failures validate.ValidationErrors
+	d.check(schema, value, "", &failures)
+	if len(failures) == 0 {
+		return nil
+	}
+	return failures
+}
+
+// check adds a failure to failures for every part of value that doesn't
+// match schema. Null is accepted anywhere, as encoding/json leaves the
+// field unset.
+func (d *Document) check(schema *Schema, value interface{}, path string, failures *validate.ValidationErrors) {
+	schema = d.resolve(schema)
+	if value == nil || schema.Type == "" {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "object" {
+			break
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field := join(path, name)
+			switch property, ok := schema.Properties[name]; {
+			case ok:
+				d.check(property, v[name], field, failures)
+			case schema.AdditionalProperties != nil:
+				d.check(schema.AdditionalProperties, v[name], field, failures)
+			case schema.Closed:
+				*failures = append(*failures, validate.NewError(validate.CodeInvalidRequest, "unknown field %s", field).
+					ForField(field).With("problem", ProblemUnknownField))
+			}
+		}
+		return
+	case []interface{}:
+		if schema.Type != "array" {
+			break
+		}
+		for i, item := range v {
+			d.check(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), failures)
+		}
+		return
+	case string:
+		if schema.Type == "string" {
+			return
+		}
+	case bool:
+		if schema.Type == "boolean" {
+			return
+		}
+	case json.Number:
+		if schema.Type == "number" {
+			return
+		}
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil && schema.Type == "integer" {
+			return
+		}
+	}
+
+	field := path
+	if field == "" {
+		field = "body"
+	}
+	*failures = append(*failures, validate.NewError(validate.CodeInvalidRequest, "%s must be of type %s", field, schema.Type).
+		ForField(path).With("problem", ProblemWrongType).With("expected", schema.Type))
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		fields []string
+	}{
+		{
+			name: "Valid",
+			path: "/signup",
+			body: `{"handle":"alice","age":30,"consents":[{"purpose":"marketing","granted":true,"recordedAt":null}],"Labels":{"a":"b"},"referrer":"bob"}`,
+		},
+		{
+			name:   "Unknown fields",
+			path:   "/signup",
+			body:   `{"handle":"alice","code":"ABC","consents":[{"purpose":"marketing","granted":true,"text":"x"}]}`,
+			fields: []string{"code", "consents[0].text"},
+		},
+		{
+			name:   "Wrong types",
+			path:   "/signup",
+			body:   `{"handle":5,"age":1.5,"consents":{"purpose":"marketing"},"Labels":{"a":true}}`,
+			fields: []string{"Labels.a", "age", "consents", "handle"},
+		},
+		{
+			name:   "Body that isn't an object",
+			path:   "/signup",
+			body:   `["alice"]`,
+			fields: []string{""},
+		},
+		{
+			name: "Body that isn't JSON",
+			path: "/signup",
+			body: `handle=alice`,
+		},
+		{
+			name: "Route without a request body",
+			path: "/healthz",
+			body: `{"anything":true}`,
+		},
+	}
+
+	doc := testDocument()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateRequest(tt.path, []byte(tt.body))
+
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var failures validate.ValidationErrors
+			if assert.ErrorAs(t, err, &failures) {
+				var fields []string
+				for _, failure := range failures {
+					verr, _ := validate.AsValidationError(failure)
+					assert.Equal(t, validate.CodeInvalidRequest, verr.Code)
+					fields = append(fields, verr.Field)
+				}
+				assert.Equal(t, tt.fields, fields)
+			}
+		})
+	}
+}
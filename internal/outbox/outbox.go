@@ -0,0 +1,62 @@
+// Package outbox keeps account events from being published twice to the
+// same subscriber when a signup is retried.
+package outbox
+
+import (
+	"context"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// Publisher sends account events to one subscriber.
+type Publisher interface {
+	Publish(ctx context.Context, event models.AccountEvent) error
+}
+
+// Deduplicator publishes each event ID to its subscriber at most once. An
+// event is claimed in the outbox before it is sent and released again if
+// sending fails, so a retry can still deliver it.
+type Deduplicator struct {
+	Subscriber string
+	Next       Publisher
+	Store      postgres.EventOutbox
+}
+
+func Dedupe(subscriber string, next Publisher, store postgres.EventOutbox) *Deduplicator {
+	return &Deduplicator{Subscriber: subscriber, Next: next, Store: store}
+}
+
+// Publish sends event unless it was already sent. If the outbox can't be
+// reached the event is sent anyway: a duplicate consumers can drop by ID is
+// better than a lost event.
+func (d *Deduplicator) Publish(ctx context.Context, event models.AccountEvent) error {
+	if event.ID == "" {
+		return d.Next.Publish(ctx, event)
+	}
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"event_id":   event.ID,
+		"event":      event.Event,
+		"subscriber": d.Subscriber,
+	})
+
+	claimed, err := d.Store.ClaimEvent(ctx, event.ID, d.Subscriber)
+	if err != nil {
+		log.WithError(err).Warn("Outbox unavailable; publishing without deduplication")
+		return d.Next.Publish(ctx, event)
+	}
+	if !claimed {
+		log.Info("Event already published; skipping")
+		return nil
+	}
+
+	if err := d.Next.Publish(ctx, event); err != nil {
+		if releaseErr := d.Store.ReleaseEvent(ctx, event.ID, d.Subscriber); releaseErr != nil {
+			log.WithError(releaseErr).Error("Failed to release unpublished event; it won't be retried")
+		}
+		return err
+	}
+	return nil
+}
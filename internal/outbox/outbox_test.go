@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOutbox struct {
+	mock.Mock
+}
+
+func (m *mockOutbox) ClaimEvent(ctx context.Context, eventID, subscriber string) (bool, error) {
+	args := m.Called(ctx, eventID, subscriber)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockOutbox) ReleaseEvent(ctx context.Context, eventID, subscriber string) error {
+	args := m.Called(ctx, eventID, subscriber)
+	return args.Error(0)
+}
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event models.AccountEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func TestAccountEventID(t *testing.T) {
+	id := models.AccountEventID("did:example:123", models.EventAccountCreated, 1)
+
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, models.NewAccountEvent(models.EventAccountCreated, "did:example:123", "alice", "").ID)
+	assert.NotEqual(t, id, models.AccountEventID("did:example:123", models.EventAccountCreated, 2))
+	assert.NotEqual(t, id, models.AccountEventID("did:example:123", models.EventAccountDeleted, 1))
+	assert.NotEqual(t, id, models.AccountEventID("did:example:456", models.EventAccountCreated, 1))
+}
+
+func TestDeduplicatorPublish(t *testing.T) {
+	event := models.NewAccountEvent(models.EventAccountCreated, "did:example:123", "alice.shareframe.social", "default")
+
+	tests := []struct {
+		name        string
+		claimed     bool
+		claimErr    error
+		publishErr  error
+		wantPublish bool
+		wantRelease bool
+		wantErr     string
+	}{
+		{name: "First Publish", claimed: true, wantPublish: true},
+		{name: "Already Published", claimed: false},
+		{name: "Outbox Unavailable", claimErr: errors.New("DB connection failed"), wantPublish: true},
+		{
+			name:        "Publish Fails",
+			claimed:     true,
+			publishErr:  errors.New("topic not found"),
+			wantPublish: true,
+			wantRelease: true,
+			wantErr:     "topic not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(mockOutbox)
+			next := new(mockPublisher)
+
+			store.On("ClaimEvent", mock.Anything, event.ID, "sns").Return(tt.claimed, tt.claimErr)
+			if tt.wantPublish {
+				next.On("Publish", mock.Anything, event).Return(tt.publishErr)
+			}
+			if tt.wantRelease {
+				store.On("ReleaseEvent", mock.Anything, event.ID, "sns").Return(nil)
+			}
+
+			err := Dedupe("sns", next, store).Publish(context.Background(), event)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			store.AssertExpectations(t)
+			next.AssertExpectations(t)
+		})
+	}
+}
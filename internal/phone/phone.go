@@ -0,0 +1,239 @@
+// Package phone verifies that an account holder can receive SMS at a phone
+// number, by texting a one-time code and checking it back. A verified
+// phone is a second signal, next to the signup risk score, that an account
+// belongs to a person, and a number can only back a few accounts.
+package phone
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/pkg/validate"
+)
+
+const (
+	// codeDigits is the length of a code.
+	codeDigits = 6
+	// DefaultCooldown is how long an account waits between codes.
+	DefaultCooldown = time.Minute
+	// DefaultMaxAttempts is how many wrong guesses a code survives.
+	DefaultMaxAttempts = 5
+	// NumberWindow is the period NumberLimit counts a number's codes over.
+	NumberWindow = 24 * time.Hour
+)
+
+// Sender delivers a text message to a phone number in E.164 form.
+type Sender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// Verifier sends codes and checks them.
+type Verifier struct {
+	Store  postgres.PhoneStore
+	Sender Sender
+	// TTL is how long a code can be used.
+	TTL      time.Duration
+	Cooldown time.Duration
+	// MaxAttempts is how many wrong guesses a code survives.
+	MaxAttempts int
+	// MaxAccounts is how many accounts may have verified the same number;
+	// zero means no limit.
+	MaxAccounts int
+	// Limiter, when set, caps the codes texted to one number at
+	// NumberLimit a NumberWindow, whichever accounts ask for them.
+	Limiter     ratelimit.Limiter
+	NumberLimit int
+	// newCode returns a fresh code.
+	newCode func() (string, error)
+}
+
+func NewVerifier(store postgres.PhoneStore, sender Sender, ttl time.Duration, maxAccounts int) *Verifier {
+	return &Verifier{
+		Store:       store,
+		Sender:      sender,
+		TTL:         ttl,
+		Cooldown:    DefaultCooldown,
+		MaxAttempts: DefaultMaxAttempts,
+		MaxAccounts: maxAccounts,
+		newCode:     randomCode,
+	}
+}
+
+// NormalizeNumber returns number in E.164 form, dropping the spaces,
+// dashes, dots and parentheses people write numbers with. It returns ""
+// for anything that isn't an international number.
+func NormalizeNumber(number string) string {
+	number = strings.TrimSpace(number)
+	if !strings.HasPrefix(number, "+") {
+		return ""
+	}
+
+	var digits strings.Builder
+	for _, r := range number[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return ""
+		}
+	}
+
+	normalized := digits.String()
+	if len(normalized) < 8 || len(normalized) > 15 || normalized[0] == '0' {
+		return ""
+	}
+	return "+" + normalized
+}
+
+// Send texts a new code to number for did and sets it as the account's
+// phone, unverified until the code comes back.
+func (v *Verifier) Send(ctx context.Context, did, number string) (models.PhoneResponse, error) {
+	phone := NormalizeNumber(number)
+	if phone == "" {
+		return models.PhoneResponse{}, apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidPhone, "phone number must be in international format").ForField(validate.FieldPhone))
+	}
+
+	if err := v.checkAccount(ctx, did); err != nil {
+		return models.PhoneResponse{}, err
+	}
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":   did,
+		"phone": phone,
+	})
+	if v.MaxAccounts > 0 {
+		count, err := v.Store.VerifiedPhoneAccounts(ctx, phone, did)
+		if err != nil {
+			return models.PhoneResponse{}, err
+		}
+		if count >= v.MaxAccounts {
+			log.WithField("accounts", count).Warn("Phone number already backs too many accounts")
+			return models.PhoneResponse{}, apperr.Wrap(apperr.Conflict, validate.NewError(validate.CodePhoneInUse, "phone number is already in use").ForField(validate.FieldPhone))
+		}
+	}
+
+	if err := v.limitNumber(ctx, phone); err != nil {
+		return models.PhoneResponse{}, err
+	}
+
+	code, err := v.newCode()
+	if err != nil {
+		return models.PhoneResponse{}, fmt.Errorf("failed to generate phone code: %w", err)
+	}
+
+	expiresAt, started, err := v.Store.StartPhoneVerification(ctx, did, phone, hashCode(did, code), v.TTL, v.Cooldown)
+	if err != nil {
+		return models.PhoneResponse{}, err
+	}
+	if !started {
+		return models.PhoneResponse{}, apperr.Wrap(apperr.RateLimited, validate.NewError(validate.CodePhoneCodeLimit, "a code was sent recently; wait before asking for another").ForField(validate.FieldPhone))
+	}
+
+	message := fmt.Sprintf("Your ShareFrame verification code is %s. It expires in %d minutes.", code, int(v.TTL.Minutes()))
+	if err := v.Sender.Send(ctx, phone, message); err != nil {
+		return models.PhoneResponse{}, err
+	}
+
+	log.Info("Phone code sent")
+	return models.PhoneResponse{Phone: phone, ExpiresAt: &expiresAt}, nil
+}
+
+// Verify checks code against the last one sent to did and, if it matches,
+// marks the phone verified. It returns the account as it now stands and
+// the number that was verified.
+func (v *Verifier) Verify(ctx context.Context, did, code string) (models.UserRecord, string, error) {
+	pending, err := v.Store.PendingPhoneCode(ctx, did)
+	if errors.Is(err, postgres.ErrPhoneCodeNotFound) {
+		return models.UserRecord{}, "", invalidCode()
+	}
+	if err != nil {
+		return models.UserRecord{}, "", err
+	}
+
+	log := logging.FromContext(ctx).WithField("did", did)
+	if pending.Attempts >= v.MaxAttempts {
+		log.WithField("attempts", pending.Attempts).Warn("Phone code attempts exhausted")
+		return models.UserRecord{}, "", apperr.Wrap(apperr.RateLimited, validate.NewError(validate.CodePhoneCodeLimit, "too many wrong codes; ask for a new one").ForField(validate.FieldPhoneCode))
+	}
+
+	expected := hashCode(did, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(pending.CodeHash)) != 1 {
+		if err := v.Store.RecordPhoneAttempt(ctx, did); err != nil {
+			log.WithError(err).Warn("Failed to record phone code attempt")
+		}
+		return models.UserRecord{}, "", invalidCode()
+	}
+
+	account, err := v.Store.ConfirmPhone(ctx, did)
+	if err != nil {
+		return models.UserRecord{}, "", err
+	}
+	log.Info("Phone verified")
+	return account, pending.Phone, nil
+}
+
+// limitNumber counts a code sent to phone against NumberLimit. The limiter
+// fails open like the other limits.
+func (v *Verifier) limitNumber(ctx context.Context, phone string) error {
+	if v.Limiter == nil || v.NumberLimit == 0 {
+		return nil
+	}
+	decision, err := v.Limiter.Allow(ctx, "phone_send:"+phone, ratelimit.Limit{Max: v.NumberLimit, Window: NumberWindow})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to check phone send limit")
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"phone": phone,
+		"count": decision.Count,
+	}).Warn("Phone send limit reached")
+	return apperr.Wrap(apperr.RateLimited, validate.NewError(validate.CodePhoneCodeLimit, "too many codes were sent to this number today").ForField(validate.FieldPhone))
+}
+
+func (v *Verifier) checkAccount(ctx context.Context, did string) error {
+	account, err := v.Store.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) || err == nil && (account.Status == models.StatusErased || account.Status == models.StatusRejected) {
+		return apperr.Errorf(apperr.NotFound, "account %s not found", did)
+	}
+	return err
+}
+
+func invalidCode() error {
+	return apperr.Wrap(apperr.Validation, validate.NewError(validate.CodeInvalidPhoneCode, "code is wrong or has expired").ForField(validate.FieldPhoneCode))
+}
+
+// hashCode binds a code to its account, so a stored hash can't be matched
+// against another account's code.
+func hashCode(did, code string) string {
+	sum := sha256.Sum256([]byte(did + "|" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
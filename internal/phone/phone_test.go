@@ -0,0 +1,281 @@
+package phone
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/ratelimit"
+	"github.com/ShareFrame/user-management/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStore struct {
+	mock.Mock
+}
+
+func (m *mockStore) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.UserRecord), args.Error(1)
+}
+
+func (m *mockStore) VerifiedPhoneAccounts(ctx context.Context, phone, did string) (int, error) {
+	args := m.Called(ctx, phone, did)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockStore) StartPhoneVerification(ctx context.Context, did, phone, codeHash string, ttl, cooldown time.Duration) (time.Time, bool, error) {
+	args := m.Called(ctx, did, phone, codeHash, ttl, cooldown)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
+func (m *mockStore) PendingPhoneCode(ctx context.Context, did string) (models.PhoneCode, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.PhoneCode), args.Error(1)
+}
+
+func (m *mockStore) RecordPhoneAttempt(ctx context.Context, did string) error {
+	return m.Called(ctx, did).Error(0)
+}
+
+func (m *mockStore) ConfirmPhone(ctx context.Context, did string) (models.UserRecord, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.UserRecord), args.Error(1)
+}
+
+type mockSender struct {
+	mock.Mock
+}
+
+func (m *mockSender) Send(ctx context.Context, phone, message string) error {
+	return m.Called(ctx, phone, message).Error(0)
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "+14155550123", expected: "+14155550123"},
+		{input: " +1 (415) 555-0123 ", expected: "+14155550123"},
+		{input: "+44 20.7946.0958", expected: "+442079460958"},
+		{input: "4155550123"},
+		{input: "+0123456789"},
+		{input: "+1234567"},
+		{input: "+1234567890123456"},
+		{input: "+1 415 CALL NOW"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeNumber(test.input))
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Date(2026, 2, 1, 0, 10, 0, 0, time.UTC)
+	alice := models.UserRecord{DID: "did:plc:alice", Status: models.StatusPendingReview}
+
+	tests := []struct {
+		name         string
+		number       string
+		account      models.UserRecord
+		accountErr   error
+		accounts     int
+		started      bool
+		sendErr      error
+		expectSend   bool
+		expectedCode string
+		expectedErr  apperr.Category
+	}{
+		{
+			name:       "Sent",
+			number:     "+1 415 555 0123",
+			account:    alice,
+			accounts:   2,
+			started:    true,
+			expectSend: true,
+		},
+		{
+			name:         "Invalid Number",
+			number:       "415 555 0123",
+			expectedCode: validate.CodeInvalidPhone,
+			expectedErr:  apperr.Validation,
+		},
+		{
+			name:        "Unknown Account",
+			number:      "+14155550123",
+			accountErr:  postgres.ErrUserNotFound,
+			expectedErr: apperr.NotFound,
+		},
+		{
+			name:        "Erased Account",
+			number:      "+14155550123",
+			account:     models.UserRecord{DID: "did:plc:alice", Status: models.StatusErased},
+			expectedErr: apperr.NotFound,
+		},
+		{
+			name:        "Rejected Account",
+			number:      "+14155550123",
+			account:     models.UserRecord{DID: "did:plc:alice", Status: models.StatusRejected},
+			expectedErr: apperr.NotFound,
+		},
+		{
+			name:         "Number Backs Too Many Accounts",
+			number:       "+14155550123",
+			account:      alice,
+			accounts:     3,
+			expectedCode: validate.CodePhoneInUse,
+			expectedErr:  apperr.Conflict,
+		},
+		{
+			name:         "Cooling Down",
+			number:       "+14155550123",
+			account:      alice,
+			expectedCode: validate.CodePhoneCodeLimit,
+			expectedErr:  apperr.RateLimited,
+		},
+		{
+			name:        "SMS Failure",
+			number:      "+14155550123",
+			account:     alice,
+			started:     true,
+			sendErr:     apperr.Wrap(apperr.Upstream, errors.New("failed to send SMS: throttled")),
+			expectSend:  true,
+			expectedErr: apperr.Upstream,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := new(mockStore)
+			sender := new(mockSender)
+			store.On("GetUser", ctx, "did:plc:alice").Return(test.account, test.accountErr)
+			store.On("VerifiedPhoneAccounts", ctx, "+14155550123", "did:plc:alice").Return(test.accounts, nil)
+			store.On("StartPhoneVerification", ctx, "did:plc:alice", "+14155550123", hashCode("did:plc:alice", "042917"), 10*time.Minute, DefaultCooldown).
+				Return(expiresAt, test.started, nil)
+			sender.On("Send", ctx, "+14155550123", "Your ShareFrame verification code is 042917. It expires in 10 minutes.").Return(test.sendErr)
+
+			verifier := NewVerifier(store, sender, 10*time.Minute, 3)
+			verifier.newCode = func() (string, error) { return "042917", nil }
+
+			response, err := verifier.Send(ctx, "did:plc:alice", test.number)
+
+			if test.expectSend {
+				sender.AssertExpectations(t)
+			} else {
+				sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if test.expectedErr != "" {
+				assert.Equal(t, test.expectedErr, apperr.CategoryOf(err))
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.PhoneResponse{Phone: "+14155550123", ExpiresAt: &expiresAt}, response)
+		})
+	}
+}
+
+func TestSendNumberLimit(t *testing.T) {
+	ctx := context.Background()
+	store := new(mockStore)
+	sender := new(mockSender)
+	for _, did := range []string{"did:plc:alice", "did:plc:bob"} {
+		store.On("GetUser", ctx, did).Return(models.UserRecord{DID: did, Status: models.StatusPendingReview}, nil)
+		store.On("VerifiedPhoneAccounts", ctx, "+14155550123", did).Return(0, nil)
+		store.On("StartPhoneVerification", ctx, did, "+14155550123", mock.Anything, 10*time.Minute, DefaultCooldown).
+			Return(time.Now(), true, nil)
+	}
+	sender.On("Send", ctx, "+14155550123", mock.Anything).Return(nil)
+
+	verifier := NewVerifier(store, sender, 10*time.Minute, 3)
+	verifier.Limiter, verifier.NumberLimit = ratelimit.NewMemoryLimiter(), 1
+
+	_, err := verifier.Send(ctx, "did:plc:alice", "+14155550123")
+	assert.NoError(t, err)
+
+	// The limit is per number, so another account can't get around it.
+	_, err = verifier.Send(ctx, "did:plc:bob", "+1 415 555 0123")
+	assert.Equal(t, apperr.RateLimited, apperr.CategoryOf(err))
+	assert.Equal(t, validate.CodePhoneCodeLimit, validate.ErrorCode(err))
+	sender.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	pending := models.PhoneCode{DID: "did:plc:alice", Phone: "+14155550123", CodeHash: hashCode("did:plc:alice", "042917")}
+	confirmed := models.UserRecord{DID: "did:plc:alice", Status: models.StatusPendingReview, ReviewFlags: []string{"signup_risk"}}
+
+	tests := []struct {
+		name         string
+		code         string
+		pending      models.PhoneCode
+		pendingErr   error
+		expectRecord bool
+		expectedCode string
+	}{
+		{
+			name:    "Verified",
+			code:    " 042917 ",
+			pending: pending,
+		},
+		{
+			name:         "Wrong Code",
+			code:         "000000",
+			pending:      pending,
+			expectRecord: true,
+			expectedCode: validate.CodeInvalidPhoneCode,
+		},
+		{
+			name:         "No Code Pending",
+			code:         "042917",
+			pendingErr:   postgres.ErrPhoneCodeNotFound,
+			expectedCode: validate.CodeInvalidPhoneCode,
+		},
+		{
+			name:         "Attempts Exhausted",
+			code:         "042917",
+			pending:      models.PhoneCode{DID: pending.DID, Phone: pending.Phone, CodeHash: pending.CodeHash, Attempts: DefaultMaxAttempts},
+			expectedCode: validate.CodePhoneCodeLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := new(mockStore)
+			store.On("PendingPhoneCode", ctx, "did:plc:alice").Return(test.pending, test.pendingErr)
+			store.On("RecordPhoneAttempt", ctx, "did:plc:alice").Return(errors.New("DB connection failed"))
+			store.On("ConfirmPhone", ctx, "did:plc:alice").Return(confirmed, nil)
+
+			account, phone, err := NewVerifier(store, new(mockSender), 10*time.Minute, 3).Verify(ctx, "did:plc:alice", test.code)
+
+			if test.expectRecord {
+				store.AssertCalled(t, "RecordPhoneAttempt", ctx, "did:plc:alice")
+			} else {
+				store.AssertNotCalled(t, "RecordPhoneAttempt", mock.Anything, mock.Anything)
+			}
+			if test.expectedCode != "" {
+				assert.Equal(t, test.expectedCode, validate.ErrorCode(err))
+				store.AssertNotCalled(t, "ConfirmPhone", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, confirmed, account)
+			assert.Equal(t, "+14155550123", phone)
+		})
+	}
+}
+
+func TestRandomCode(t *testing.T) {
+	code, err := randomCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, codeDigits)
+}
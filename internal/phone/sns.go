@@ -0,0 +1,69 @@
+package phone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// SMS attributes SNS reads off a direct publish to a phone number.
+const (
+	SMSTypeAttribute  = "AWS.SNS.SMS.SMSType"
+	SenderIDAttribute = "AWS.SNS.SMS.SenderID"
+	smsTransactional  = "Transactional"
+)
+
+type SNSAPI interface {
+	Publish(ctx context.Context, input *sns.PublishInput, opts ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSSender texts through SNS as transactional messages, which SNS
+// delivers ahead of promotional ones. SenderID, where carriers support
+// it, is shown in place of a number.
+type SNSSender struct {
+	Client   SNSAPI
+	SenderID string
+	Retry    retry.Policy
+}
+
+func NewSNSSender(client SNSAPI, senderID string, retryPolicy retry.Policy) *SNSSender {
+	return &SNSSender{Client: client, SenderID: senderID, Retry: retryPolicy}
+}
+
+func (s *SNSSender) Send(ctx context.Context, phone, message string) error {
+	attributes := map[string]types.MessageAttributeValue{
+		SMSTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(smsTransactional)},
+	}
+	if s.SenderID != "" {
+		attributes[SenderIDAttribute] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(s.SenderID)}
+	}
+
+	input := &sns.PublishInput{
+		PhoneNumber:       aws.String(phone),
+		Message:           aws.String(message),
+		MessageAttributes: attributes,
+	}
+
+	ctx, seg := tracing.Begin(ctx, "SNS", tracing.NamespaceAWS)
+	seg.SetAWS("Publish")
+	// A retry after SNS accepted the message would text the code twice.
+	err := s.Retry.ForWrite().Do(ctx, "sns.PublishSMS", func(ctx context.Context) error {
+		_, err := s.Client.Publish(ctx, input)
+		return err
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencySMS, err)
+		logging.FromContext(ctx).WithError(err).WithField("phone", phone).Error("Failed to send SMS")
+		return apperr.Wrap(apperr.Upstream, fmt.Errorf("failed to send SMS: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package phone
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSNSClient struct {
+	mock.Mock
+}
+
+func (m *mockSNSClient) Publish(ctx context.Context, input *sns.PublishInput, opts ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	args := m.Called(ctx, input)
+	if output, ok := args.Get(0).(*sns.PublishOutput); ok {
+		return output, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestSNSSend(t *testing.T) {
+	tests := []struct {
+		name        string
+		senderID    string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Sent", senderID: "ShareFrame"},
+		{name: "No Sender ID"},
+		{
+			name:        "SNS Error",
+			mockError:   errors.New("opted out"),
+			expectedErr: "failed to send SMS: opted out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockSNSClient)
+			client.On("Publish", mock.Anything, mock.MatchedBy(func(input *sns.PublishInput) bool {
+				senderID, hasSenderID := input.MessageAttributes[SenderIDAttribute]
+				return aws.ToString(input.PhoneNumber) == "+14155550123" &&
+					input.TopicArn == nil &&
+					aws.ToString(input.MessageAttributes[SMSTypeAttribute].StringValue) == "Transactional" &&
+					hasSenderID == (tt.senderID != "") &&
+					aws.ToString(senderID.StringValue) == tt.senderID
+			})).Return(&sns.PublishOutput{}, tt.mockError)
+
+			err := NewSNSSender(client, tt.senderID, retry.Policy{}).Send(context.Background(), "+14155550123", "Your code is 042917.")
+
+			client.AssertExpectations(t)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Equal(t, apperr.Upstream, apperr.CategoryOf(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// UserFinder looks accounts up by what operators are given in support
+// requests: a DID, a handle or an email address.
+type UserFinder interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	// FindUserDID returns the DID of the account with identifier as its
+	// handle or, if it contains an @, its email address. It returns
+	// ErrUserNotFound if there is none.
+	FindUserDID(ctx context.Context, identifier string) (string, error)
+}
+
+func (p *PostgresDB) FindUserDID(ctx context.Context, identifier string) (string, error) {
+	query := fmt.Sprintf(`SELECT did FROM %s WHERE LOWER(handle) = LOWER(:handle) LIMIT 1`, p.table(UsersTable))
+	params := []types.SqlParameter{newSQLParam("handle", identifier)}
+	if strings.Contains(identifier, "@") {
+		query = fmt.Sprintf(`SELECT did FROM %s WHERE normalized_email = :normalized_email LIMIT 1`, p.table(UsersTable))
+		params = []types.SqlParameter{newSQLParam("normalized_email", NormalizeEmail(identifier))}
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("identifier", identifier).Error("Failed to find user")
+		return "", fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if result == nil {
+		return "", fmt.Errorf("failed to find user: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return "", ErrUserNotFound
+	}
+	return stringColumns(result.Records[0], 1)[0], nil
+}
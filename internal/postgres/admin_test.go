@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFindUserDID(t *testing.T) {
+	ctx := context.Background()
+	found := &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberStringValue{Value: "did:plc:alice"}}}}
+
+	tests := []struct {
+		name        string
+		identifier  string
+		param       string
+		value       string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:       "By Handle",
+			identifier: "Alice.shareframe.social",
+			param:      "handle",
+			value:      "Alice.shareframe.social",
+			mockOutput: found,
+			expected:   "did:plc:alice",
+		},
+		{
+			name:       "By Email",
+			identifier: "Alice+support@example.com",
+			param:      "normalized_email",
+			value:      "alice@example.com",
+			mockOutput: found,
+			expected:   "did:plc:alice",
+		},
+		{
+			name:        "Not Found",
+			identifier:  "nobody.shareframe.social",
+			param:       "handle",
+			value:       "nobody.shareframe.social",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrUserNotFound.Error(),
+		},
+		{
+			name:        "Database Error",
+			identifier:  "alice.shareframe.social",
+			param:       "handle",
+			value:       "alice.shareframe.social",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to find user: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				value, _ := sqlParam(input, test.param).(*types.FieldMemberStringValue)
+				return value != nil && value.Value == test.value
+			})).Return(test.mockOutput, test.mockError)
+
+			did, err := db.FindUserDID(ctx, test.identifier)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, did)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const SignupAttemptsTable = "signup_attempts"
+
+// SignupAttemptStore keeps a short history of signup attempts for abuse
+// scoring and the signup stats.
+type SignupAttemptStore interface {
+	// RecordSignupAttempt records an attempt that failed for failureReason,
+	// or succeeded when it is empty.
+	RecordSignupAttempt(ctx context.Context, ip, emailDomain, failureReason string) error
+	SignupVelocity(ctx context.Context, ip, emailDomain string, window time.Duration) (risk.Velocity, error)
+	DomainSignups(ctx context.Context, emailDomain string, window time.Duration) (int, error)
+}
+
+func (p *PostgresDB) RecordSignupAttempt(ctx context.Context, ip, emailDomain, failureReason string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (ip, email_domain, succeeded, failure_reason, attempted_at)
+		VALUES (:ip, :email_domain, :succeeded, :failure_reason, NOW())`, p.table(SignupAttemptsTable))
+
+	params := []types.SqlParameter{
+		nullableSQLParam("ip", ip),
+		nullableSQLParam("email_domain", emailDomain),
+		newSQLParam("succeeded", failureReason == ""),
+		nullableSQLParam("failure_reason", failureReason),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to record signup attempt")
+		return fmt.Errorf("failed to record signup attempt: %w", err)
+	}
+
+	return nil
+}
+
+// SignupVelocity counts the attempts from ip, the failures among them, and
+// the successful signups with emailDomain within window.
+func (p *PostgresDB) SignupVelocity(ctx context.Context, ip, emailDomain string, window time.Duration) (risk.Velocity, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*) FILTER (WHERE ip = :ip),
+			COUNT(*) FILTER (WHERE ip = :ip AND NOT succeeded),
+			COUNT(*) FILTER (WHERE email_domain = :email_domain AND succeeded)
+		FROM %s
+		WHERE attempted_at > NOW() - CAST(:window AS INTERVAL)
+			AND (ip = :ip OR email_domain = :email_domain)`, p.table(SignupAttemptsTable))
+
+	params := []types.SqlParameter{
+		nullableSQLParam("ip", ip),
+		nullableSQLParam("email_domain", emailDomain),
+		newSQLParam("window", intervalParam(window)),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to load signup velocity")
+		return risk.Velocity{}, fmt.Errorf("failed to load signup velocity: %w", err)
+	}
+
+	if result == nil || len(result.Records) == 0 || len(result.Records[0]) < 3 {
+		return risk.Velocity{}, fmt.Errorf("failed to load signup velocity: unexpected response")
+	}
+
+	row := result.Records[0]
+	return risk.Velocity{
+		IPAttempts:    longValue(row[0]),
+		IPFailures:    longValue(row[1]),
+		DomainSignups: longValue(row[2]),
+	}, nil
+}
+
+// DomainSignups counts the successful signups with emailDomain within
+// window.
+func (p *PostgresDB) DomainSignups(ctx context.Context, emailDomain string, window time.Duration) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s
+		WHERE email_domain = :email_domain AND succeeded
+			AND attempted_at > NOW() - CAST(:window AS INTERVAL)`, p.table(SignupAttemptsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("email_domain", emailDomain),
+		newSQLParam("window", intervalParam(window)),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to count domain signups")
+		return 0, fmt.Errorf("failed to count domain signups: %w", err)
+	}
+
+	if result == nil || len(result.Records) == 0 || len(result.Records[0]) == 0 {
+		return 0, fmt.Errorf("failed to count domain signups: unexpected response")
+	}
+
+	return longValue(result.Records[0][0]), nil
+}
+
+// intervalParam formats window for a CAST(... AS INTERVAL).
+func intervalParam(window time.Duration) string {
+	return fmt.Sprintf("%d seconds", int(window.Seconds()))
+}
+
+func longValue(field types.Field) int {
+	if v, ok := field.(*types.FieldMemberLongValue); ok {
+		return int(v.Value)
+	}
+	return 0
+}
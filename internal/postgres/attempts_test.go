@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/risk"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordSignupAttempt(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		_, ipNull := sqlParam(input, "ip").(*types.FieldMemberIsNull)
+		succeeded, _ := sqlParam(input, "succeeded").(*types.FieldMemberBooleanValue)
+		reason, _ := sqlParam(input, "failure_reason").(*types.FieldMemberStringValue)
+		return ipNull && succeeded != nil && !succeeded.Value && reason != nil && reason.Value == "handle_taken"
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.RecordSignupAttempt(ctx, "", "example.com", "handle_taken")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSignupVelocity(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    risk.Velocity
+		expectedErr string
+	}{
+		{
+			name: "Counts Returned",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{{
+					&types.FieldMemberLongValue{Value: 4},
+					&types.FieldMemberLongValue{Value: 2},
+					&types.FieldMemberLongValue{Value: 17},
+				}},
+			},
+			expected: risk.Velocity{IPAttempts: 4, IPFailures: 2, DomainSignups: 17},
+		},
+		{
+			name:        "Unexpected Response",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: "failed to load signup velocity: unexpected response",
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to load signup velocity: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				window, _ := sqlParam(input, "window").(*types.FieldMemberStringValue)
+				return window != nil && window.Value == "3600 seconds"
+			})).Return(test.mockOutput, test.mockError)
+
+			velocity, err := db.SignupVelocity(ctx, "203.0.113.7", "example.com", time.Hour)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, velocity)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDomainSignups(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    int
+		expectedErr string
+	}{
+		{
+			name: "Count Returned",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 37}}},
+			},
+			expected: 37,
+		},
+		{
+			name:        "Unexpected Response",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: "failed to count domain signups: unexpected response",
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to count domain signups: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				domain, _ := sqlParam(input, "email_domain").(*types.FieldMemberStringValue)
+				return domain != nil && domain.Value == "bots.example"
+			})).Return(test.mockOutput, test.mockError)
+
+			signups, err := db.DomainSignups(ctx, "bots.example", time.Hour)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, signups)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const AuditEventsTable = "audit_events"
+
+// Account lifecycle events recorded in the audit trail.
+const (
+	AuditAccountCreated   = "account.created"
+	AuditAccountVerified  = "account.verified"
+	AuditAccountActivated = "account.activated"
+	AuditAccountApproved  = "account.approved"
+	AuditAccountSuspended = "account.suspended"
+	AuditAccountRejected  = "account.rejected"
+	AuditAccountDeleted   = "account.deleted"
+	AuditHandleChanged    = "account.handle_changed"
+	AuditDataExported     = "account.data_exported"
+	AuditErasureRequested = "account.erasure_requested"
+)
+
+// AuditActorSelf is the actor of events the account holder caused
+// themselves, such as signing up.
+const AuditActorSelf = "self"
+
+// AuditStore appends account lifecycle events to a table kept apart from
+// the application logs for compliance review. There is deliberately no way
+// to update or delete an entry; the table's grants should allow INSERT and
+// SELECT only.
+type AuditStore interface {
+	RecordAuditEvent(ctx context.Context, event models.AuditEvent) error
+}
+
+// RecordAuditEvent appends event with the database's current time. A
+// missing request ID is taken from the request logger in ctx.
+func (p *PostgresDB) RecordAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	if event.RequestID == "" {
+		event.RequestID = logging.RequestID(ctx)
+	}
+
+	details := ""
+	if len(event.Details) > 0 {
+		data, err := json.Marshal(event.Details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit event details: %w", err)
+		}
+		details = string(data)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (event, did, handle, actor, request_id, details, occurred_at)
+		VALUES (:event, :did, :handle, :actor, :request_id, CAST(:details AS JSONB), NOW())`,
+		p.table(AuditEventsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("event", event.Event),
+		newSQLParam("did", event.DID),
+		newSQLParam("handle", event.Handle),
+		newSQLParam("actor", event.Actor),
+		nullableSQLParam("request_id", event.RequestID),
+		nullableSQLParam("details", details),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"event": event.Event,
+			"did":   event.DID,
+		}).Error("Failed to record audit event")
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
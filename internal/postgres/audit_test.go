@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordAuditEvent(t *testing.T) {
+	requestCtx := logging.NewRequestContext(
+		lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-123"}),
+		"create_account",
+	)
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		event         models.AuditEvent
+		mockError     error
+		wantRequestID string
+		wantDetails   string
+		expectedErr   string
+	}{
+		{
+			name: "Request ID From Context",
+			ctx:  requestCtx,
+			event: models.AuditEvent{
+				Event:   AuditAccountCreated,
+				DID:     "did:example:123",
+				Handle:  "alice.shareframe.social",
+				Actor:   AuditActorSelf,
+				Details: map[string]string{"status": "active"},
+			},
+			wantRequestID: "req-123",
+			wantDetails:   `{"status":"active"}`,
+		},
+		{
+			name: "Explicit Request ID Without Details",
+			ctx:  requestCtx,
+			event: models.AuditEvent{
+				Event:     AuditAccountSuspended,
+				DID:       "did:example:123",
+				Handle:    "alice.shareframe.social",
+				Actor:     "admin@shareframe.social",
+				RequestID: "req-456",
+			},
+			wantRequestID: "req-456",
+		},
+		{
+			name:        "Database Error",
+			ctx:         context.Background(),
+			event:       models.AuditEvent{Event: AuditAccountDeleted, DID: "did:example:123", Actor: AuditActorSelf},
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to record audit event: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				if test.mockError != nil {
+					return true
+				}
+				event, _ := sqlParam(input, "event").(*types.FieldMemberStringValue)
+				if event == nil || event.Value != test.event.Event {
+					return false
+				}
+				requestID, _ := sqlParam(input, "request_id").(*types.FieldMemberStringValue)
+				if requestID == nil || requestID.Value != test.wantRequestID {
+					return false
+				}
+				if test.wantDetails == "" {
+					_, isNull := sqlParam(input, "details").(*types.FieldMemberIsNull)
+					return isNull
+				}
+				details, _ := sqlParam(input, "details").(*types.FieldMemberStringValue)
+				return details != nil && details.Value == test.wantDetails
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.RecordAuditEvent(test.ctx, test.event)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// AvatarStore reads an account and stores the profile picture uploaded for
+// it.
+type AvatarStore interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	SetProfilePicture(ctx context.Context, did, pictureURL string) error
+}
+
+func (p *PostgresDB) SetProfilePicture(ctx context.Context, did, pictureURL string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET profile_picture = :profile_picture, modified_at = NOW()
+		WHERE did = :did`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("profile_picture", pictureURL),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to store profile picture")
+		return fmt.Errorf("failed to store profile picture: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to store profile picture: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetProfilePicture(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:       "Stored",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to store profile picture: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				picture, _ := sqlParam(input, "profile_picture").(*types.FieldMemberStringValue)
+				return picture != nil && picture.Value == "https://pds.example.com/avatar"
+			})).Return(test.mockOutput, test.mockError)
+
+			err := db.SetProfilePicture(ctx, "did:example:123", "https://pds.example.com/avatar")
+
+			switch {
+			case errors.Is(test.expectedErr, ErrUserNotFound):
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
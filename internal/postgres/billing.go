@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// BillingStore links an account to its billing provider customer.
+type BillingStore interface {
+	SetStripeCustomerID(ctx context.Context, did, customerID string) error
+	// StripeCustomerID returns "" for an account without a customer.
+	StripeCustomerID(ctx context.Context, did string) (string, error)
+}
+
+func (p *PostgresDB) SetStripeCustomerID(ctx context.Context, did, customerID string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET stripe_customer_id = :customer_id, modified_at = NOW()
+		WHERE did = :did`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("customer_id", customerID),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to store Stripe customer ID")
+		return fmt.Errorf("failed to store Stripe customer ID: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to store Stripe customer ID: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (p *PostgresDB) StripeCustomerID(ctx context.Context, did string) (string, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(stripe_customer_id, '') FROM %s WHERE did = :did`, p.table(UsersTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to load Stripe customer ID")
+		return "", fmt.Errorf("failed to load Stripe customer ID: %w", err)
+	}
+
+	if result == nil {
+		return "", fmt.Errorf("failed to load Stripe customer ID: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return "", ErrUserNotFound
+	}
+	return stringColumns(result.Records[0], 1)[0], nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetStripeCustomerID(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:       "Stored",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to store Stripe customer ID: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				id, _ := sqlParam(input, "customer_id").(*types.FieldMemberStringValue)
+				return id != nil && id.Value == "cus_123"
+			})).Return(test.mockOutput, test.mockError)
+
+			err := db.SetStripeCustomerID(ctx, "did:example:123", "cus_123")
+
+			switch {
+			case errors.Is(test.expectedErr, ErrUserNotFound):
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStripeCustomerID(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedID  string
+		expectedErr error
+	}{
+		{
+			name: "Linked",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{
+				&types.FieldMemberStringValue{Value: "cus_123"},
+			}}},
+			expectedID: "cus_123",
+		},
+		{
+			name: "No Customer",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{
+				&types.FieldMemberStringValue{Value: ""},
+			}}},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to load Stripe customer ID: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockError)
+
+			id, err := db.StripeCustomerID(ctx, "did:example:123")
+
+			switch {
+			case errors.Is(test.expectedErr, ErrUserNotFound):
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedID, id)
+			}
+		})
+	}
+}
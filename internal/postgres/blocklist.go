@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const (
+	BlockedHandlesTable = "blocked_handles"
+	BlocklistAuditTable = "blocklist_audit"
+
+	BlocklistActionAdd    = "add"
+	BlocklistActionRemove = "remove"
+
+	defaultAuditLimit = 100
+)
+
+// ErrHandleNotBlocked is returned when removing a handle that isn't on the
+// runtime blocklist.
+var ErrHandleNotBlocked = errors.New("handle is not on the blocklist")
+
+// BlocklistStore holds the handles admins block at runtime, on top of the
+// reserved list compiled into the binary. Every change is written to an
+// audit table in the same statement, so the audit trail can't drift from
+// the list.
+type BlocklistStore interface {
+	IsHandleBlocked(ctx context.Context, handle string) (bool, error)
+	BlockHandle(ctx context.Context, handle, reason, actor string) error
+	UnblockHandle(ctx context.Context, handle, reason, actor string) error
+	ListBlockedHandles(ctx context.Context) ([]models.BlockedHandle, error)
+	ListBlocklistAudit(ctx context.Context, limit int) ([]models.BlocklistAuditEntry, error)
+}
+
+func (p *PostgresDB) IsHandleBlocked(ctx context.Context, handle string) (bool, error) {
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE handle = :handle LIMIT 1`, p.table(BlockedHandlesTable))
+	params := []types.SqlParameter{newSQLParam("handle", strings.ToLower(handle))}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithField("handle", handle).Errorf("Error checking blocklist: %v", err)
+		return false, fmt.Errorf("failed to check blocklist: %w", err)
+	}
+
+	if result == nil {
+		return false, fmt.Errorf("failed to check blocklist: unexpected nil response")
+	}
+
+	return len(result.Records) > 0, nil
+}
+
+// BlockHandle adds handle to the blocklist, or replaces the reason if it is
+// already there.
+func (p *PostgresDB) BlockHandle(ctx context.Context, handle, reason, actor string) error {
+	query := fmt.Sprintf(`
+		WITH changed AS (
+			INSERT INTO %s (handle, reason, blocked_by, blocked_at)
+			VALUES (:handle, :reason, :actor, NOW())
+			ON CONFLICT (handle) DO UPDATE SET reason = EXCLUDED.reason, blocked_by = EXCLUDED.blocked_by, blocked_at = EXCLUDED.blocked_at
+			RETURNING handle
+		)
+		INSERT INTO %s (handle, action, actor, reason, changed_at)
+		SELECT handle, :action, :actor, :reason, NOW() FROM changed`,
+		p.table(BlockedHandlesTable), p.table(BlocklistAuditTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("handle", strings.ToLower(handle)),
+		newSQLParam("reason", reason),
+		newSQLParam("actor", actor),
+		newSQLParam("action", BlocklistActionAdd),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": handle,
+			"actor":  actor,
+		}).Errorf("Failed to block handle: %v", err)
+		return fmt.Errorf("failed to block handle: %w", err)
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle": handle,
+		"actor":  actor,
+	}).Info("Handle added to blocklist")
+	return nil
+}
+
+// UnblockHandle removes handle from the blocklist. It returns
+// ErrHandleNotBlocked if there was nothing to remove.
+func (p *PostgresDB) UnblockHandle(ctx context.Context, handle, reason, actor string) error {
+	query := fmt.Sprintf(`
+		WITH changed AS (
+			DELETE FROM %s WHERE handle = :handle RETURNING handle
+		)
+		INSERT INTO %s (handle, action, actor, reason, changed_at)
+		SELECT handle, :action, :actor, :reason, NOW() FROM changed`,
+		p.table(BlockedHandlesTable), p.table(BlocklistAuditTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("handle", strings.ToLower(handle)),
+		newSQLParam("reason", reason),
+		newSQLParam("actor", actor),
+		newSQLParam("action", BlocklistActionRemove),
+	}
+
+	result, err := p.executeWrite(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": handle,
+			"actor":  actor,
+		}).Errorf("Failed to unblock handle: %v", err)
+		return fmt.Errorf("failed to unblock handle: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to unblock handle: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return fmt.Errorf("failed to unblock %s: %w", handle, ErrHandleNotBlocked)
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle": handle,
+		"actor":  actor,
+	}).Info("Handle removed from blocklist")
+	return nil
+}
+
+func (p *PostgresDB) ListBlockedHandles(ctx context.Context) ([]models.BlockedHandle, error) {
+	query := fmt.Sprintf(`SELECT handle, reason, blocked_by, blocked_at::text FROM %s ORDER BY handle`, p.table(BlockedHandlesTable))
+
+	result, err := p.execute(ctx, query, nil)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to list blocked handles")
+		return nil, fmt.Errorf("failed to list blocked handles: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list blocked handles: unexpected nil response")
+	}
+
+	handles := make([]models.BlockedHandle, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 4)
+		handles = append(handles, models.BlockedHandle{
+			Handle:    columns[0],
+			Reason:    columns[1],
+			BlockedBy: columns[2],
+			BlockedAt: columns[3],
+		})
+	}
+	return handles, nil
+}
+
+// ListBlocklistAudit returns the most recent blocklist changes, newest first.
+// A limit of 0 or less returns the last 100.
+func (p *PostgresDB) ListBlocklistAudit(ctx context.Context, limit int) ([]models.BlocklistAuditEntry, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	query := fmt.Sprintf(`SELECT handle, action, actor, reason, changed_at::text FROM %s ORDER BY changed_at DESC LIMIT :limit`, p.table(BlocklistAuditTable))
+	params := []types.SqlParameter{newSQLParam("limit", limit)}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to list blocklist audit trail")
+		return nil, fmt.Errorf("failed to list blocklist audit: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list blocklist audit: unexpected nil response")
+	}
+
+	entries := make([]models.BlocklistAuditEntry, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 5)
+		entries = append(entries, models.BlocklistAuditEntry{
+			Handle:    columns[0],
+			Action:    columns[1],
+			Actor:     columns[2],
+			Reason:    columns[3],
+			ChangedAt: columns[4],
+		})
+	}
+	return entries, nil
+}
+
+// stringColumns flattens the first n columns of a result row to strings,
+// with "" for NULLs and non-string columns.
+func stringColumns(row []types.Field, n int) []string {
+	columns := make([]string, n)
+	for i, field := range row {
+		if i == len(columns) {
+			break
+		}
+		if value, ok := field.(*types.FieldMemberStringValue); ok {
+			columns[i] = value.Value
+		}
+	}
+	return columns
+}
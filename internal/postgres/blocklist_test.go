@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIsHandleBlocked(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    bool
+		expectedErr string
+	}{
+		{
+			name:       "Handle Blocked",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 1}}}},
+			expected:   true,
+		},
+		{
+			name:       "Handle Not Blocked",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+			expected:   false,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to check blocklist: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				value := input.Parameters[0].Value.(*types.FieldMemberStringValue)
+				return value.Value == "spammer"
+			})).Return(test.mockOutput, test.mockError)
+
+			blocked, err := db.IsHandleBlocked(ctx, "Spammer")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, blocked)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockHandleWritesAudit(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "acme_")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		sql := aws.ToString(input.Sql)
+		return strings.Contains(sql, "INSERT INTO acme_blocked_handles") &&
+			strings.Contains(sql, "INSERT INTO acme_blocklist_audit")
+	})).Return(&rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1}, nil)
+
+	err := db.BlockHandle(ctx, "Spammer", "impersonation", "alice")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUnblockHandle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:       "Handle Removed",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Handle Not Blocked",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrHandleNotBlocked,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).
+				Return(test.mockOutput, test.mockError)
+
+			err := db.UnblockHandle(ctx, "spammer", "appeal granted", "alice")
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestListBlockedHandles(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "spammer"},
+			&types.FieldMemberIsNull{Value: true},
+			&types.FieldMemberStringValue{Value: "alice"},
+			&types.FieldMemberStringValue{Value: "2025-01-02 03:04:05+00"},
+		}},
+	}, nil)
+
+	handles, err := db.ListBlockedHandles(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BlockedHandle{{
+		Handle:    "spammer",
+		BlockedBy: "alice",
+		BlockedAt: "2025-01-02 03:04:05+00",
+	}}, handles)
+}
+
+func TestListBlocklistAuditDefaultsLimit(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		limit, ok := input.Parameters[0].Value.(*types.FieldMemberLongValue)
+		return ok && limit.Value == defaultAuditLimit
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "spammer"},
+			&types.FieldMemberStringValue{Value: BlocklistActionAdd},
+			&types.FieldMemberStringValue{Value: "alice"},
+			&types.FieldMemberStringValue{Value: "impersonation"},
+			&types.FieldMemberStringValue{Value: "2025-01-02 03:04:05+00"},
+		}},
+	}, nil)
+
+	entries, err := db.ListBlocklistAudit(ctx, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BlocklistAuditEntry{{
+		Handle:    "spammer",
+		Action:    BlocklistActionAdd,
+		Actor:     "alice",
+		Reason:    "impersonation",
+		ChangedAt: "2025-01-02 03:04:05+00",
+	}}, entries)
+	mockClient.AssertExpectations(t)
+}
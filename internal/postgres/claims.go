@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const HandleClaimsTable = "handle_claims"
+
+// HandleClaimStore keeps handles reserved for an email address before the
+// account exists. Claims lapse at their expiry without any cleanup.
+type HandleClaimStore interface {
+	// ClaimHandle reserves handle for email for ttl, replacing any other
+	// claim the address holds. It reports false if someone else holds an
+	// unexpired claim on the handle. Claiming a handle again doesn't
+	// extend the claim.
+	ClaimHandle(ctx context.Context, handle, email string, ttl time.Duration) (models.HandleClaim, bool, error)
+	// ActiveHandleClaim returns the unexpired claim on handle, if any.
+	ActiveHandleClaim(ctx context.Context, handle string) (models.HandleClaim, bool, error)
+}
+
+func (p *PostgresDB) ClaimHandle(ctx context.Context, handle, email string, ttl time.Duration) (models.HandleClaim, bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s AS c (handle, email, normalized_email, claimed_at, expires_at)
+		VALUES (:handle, :email, :normalized_email, NOW(), NOW() + CAST(:ttl AS INTERVAL))
+		ON CONFLICT (handle) DO UPDATE SET
+			email = EXCLUDED.email,
+			normalized_email = EXCLUDED.normalized_email,
+			claimed_at = CASE WHEN c.expires_at > NOW() THEN c.claimed_at ELSE EXCLUDED.claimed_at END,
+			expires_at = CASE WHEN c.expires_at > NOW() THEN c.expires_at ELSE EXCLUDED.expires_at END
+		WHERE c.expires_at <= NOW() OR c.normalized_email = EXCLUDED.normalized_email
+		RETURNING expires_at::text`, p.table(HandleClaimsTable))
+
+	normalizedEmail := NormalizeEmail(email)
+	params := []types.SqlParameter{
+		newSQLParam("handle", handle),
+		newSQLParam("email", email),
+		newSQLParam("normalized_email", normalizedEmail),
+		newSQLParam("ttl", intervalParam(ttl)),
+	}
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"handle": handle,
+		"email":  email,
+	})
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		log.WithError(err).Error("Failed to claim handle")
+		return models.HandleClaim{}, false, fmt.Errorf("failed to claim handle: %w", err)
+	}
+
+	if result == nil {
+		return models.HandleClaim{}, false, fmt.Errorf("failed to claim handle: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.HandleClaim{}, false, nil
+	}
+
+	expiresAt, err := time.Parse(postgresTimestamp, stringColumns(result.Records[0], 1)[0])
+	if err != nil {
+		return models.HandleClaim{}, false, fmt.Errorf("failed to parse claim expiry: %w", err)
+	}
+
+	release := fmt.Sprintf(`DELETE FROM %s WHERE normalized_email = :normalized_email AND handle <> :handle`, p.table(HandleClaimsTable))
+	if _, err := p.execute(ctx, release, params[:3]); err != nil {
+		// The new claim stands; the old one just lapses at its expiry.
+		log.WithError(err).Warn("Failed to release earlier handle claims")
+	}
+
+	return models.HandleClaim{
+		Handle:          handle,
+		Email:           email,
+		NormalizedEmail: normalizedEmail,
+		ExpiresAt:       expiresAt.UTC(),
+	}, true, nil
+}
+
+func (p *PostgresDB) ActiveHandleClaim(ctx context.Context, handle string) (models.HandleClaim, bool, error) {
+	query := fmt.Sprintf(`
+		SELECT handle, email, normalized_email, expires_at::text FROM %s
+		WHERE handle = :handle AND expires_at > NOW()`, p.table(HandleClaimsTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("handle", handle)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("handle", handle).Error("Failed to load handle claim")
+		return models.HandleClaim{}, false, fmt.Errorf("failed to load handle claim: %w", err)
+	}
+
+	if result == nil {
+		return models.HandleClaim{}, false, fmt.Errorf("failed to load handle claim: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.HandleClaim{}, false, nil
+	}
+
+	columns := stringColumns(result.Records[0], 4)
+	expiresAt, err := time.Parse(postgresTimestamp, columns[3])
+	if err != nil {
+		return models.HandleClaim{}, false, fmt.Errorf("failed to parse claim expiry: %w", err)
+	}
+	return models.HandleClaim{
+		Handle:          columns[0],
+		Email:           columns[1],
+		NormalizedEmail: columns[2],
+		ExpiresAt:       expiresAt.UTC(),
+	}, true, nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClaimHandle(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := "2026-02-01 00:00:00+00"
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		claimed     bool
+		wantCalls   int
+		expectedErr string
+	}{
+		{
+			name:       "Claimed",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberStringValue{Value: expiresAt}}}},
+			claimed:    true,
+			wantCalls:  2,
+		},
+		{
+			name:       "Held By Someone Else",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+			wantCalls:  1,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			wantCalls:   1,
+			expectedErr: "failed to claim handle: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				email, _ := sqlParam(input, "normalized_email").(*types.FieldMemberStringValue)
+				return email != nil && email.Value == "alice@example.com"
+			})).Return(test.mockOutput, test.mockError)
+
+			claim, claimed, err := db.ClaimHandle(ctx, "alice.shareframe.social", "Alice+launch@example.com", 30*24*time.Hour)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.claimed, claimed)
+			}
+			if test.claimed {
+				assert.Equal(t, models.HandleClaim{
+					Handle:          "alice.shareframe.social",
+					Email:           "Alice+launch@example.com",
+					NormalizedEmail: "alice@example.com",
+					ExpiresAt:       time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+				}, claim)
+			}
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", test.wantCalls)
+		})
+	}
+}
+
+func TestActiveHandleClaim(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "alice.shareframe.social"},
+			&types.FieldMemberStringValue{Value: "Alice+launch@example.com"},
+			&types.FieldMemberStringValue{Value: "alice@example.com"},
+			&types.FieldMemberStringValue{Value: "2026-02-01 00:00:00+00"},
+		}},
+	}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{}, nil).Once()
+
+	claim, ok, err := db.ActiveHandleClaim(ctx, "alice.shareframe.social")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", claim.NormalizedEmail)
+
+	_, ok, err = db.ActiveHandleClaim(ctx, "bob.shareframe.social")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
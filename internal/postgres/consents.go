@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const ConsentsTable = "consents"
+
+// ConsentStore keeps the history of each account's consent choices. A
+// change of mind is a new row, so the table always shows what the user had
+// agreed to at any point.
+type ConsentStore interface {
+	RecordConsents(ctx context.Context, did string, consents []models.Consent) error
+	ListConsents(ctx context.Context, did string) ([]models.Consent, error)
+}
+
+func (p *PostgresDB) RecordConsents(ctx context.Context, did string, consents []models.Consent) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (did, purpose, granted, text_version, recorded_at)
+		VALUES (:did, :purpose, :granted, :text_version, CAST(:recorded_at AS TIMESTAMPTZ))`,
+		p.table(ConsentsTable))
+
+	for _, consent := range consents {
+		params := []types.SqlParameter{
+			newSQLParam("did", did),
+			newSQLParam("purpose", consent.Purpose),
+			newSQLParam("granted", consent.Granted),
+			newSQLParam("text_version", consent.TextVersion),
+			newSQLParam("recorded_at", consent.RecordedAt.UTC().Format(time.RFC3339Nano)),
+		}
+
+		if _, err := p.executeWrite(ctx, query, params); err != nil {
+			logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+				"did":     did,
+				"purpose": consent.Purpose,
+			}).Error("Failed to record consent")
+			return fmt.Errorf("failed to record consent: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListConsents returns every choice recorded for the account, oldest first.
+func (p *PostgresDB) ListConsents(ctx context.Context, did string) ([]models.Consent, error) {
+	query := fmt.Sprintf(`
+		SELECT purpose, granted::text, text_version, recorded_at::text FROM %s
+		WHERE did = :did ORDER BY recorded_at`, p.table(ConsentsTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to list consents")
+		return nil, fmt.Errorf("failed to list consents: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list consents: unexpected nil response")
+	}
+
+	consents := make([]models.Consent, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 4)
+		recordedAt, err := time.Parse(postgresTimestamp, columns[3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse consent time %q: %w", columns[3], err)
+		}
+		consents = append(consents, models.Consent{
+			Purpose:     columns[0],
+			Granted:     columns[1] == "true",
+			TextVersion: columns[2],
+			RecordedAt:  recordedAt,
+		})
+	}
+	return consents, nil
+}
+
+// postgresTimestamp is how a TIMESTAMPTZ reads when cast to text.
+const postgresTimestamp = "2006-01-02 15:04:05.999999-07"
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordConsents(t *testing.T) {
+	ctx := context.Background()
+	recordedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	consents := []models.Consent{
+		{Purpose: models.ConsentMarketing, Granted: false, TextVersion: "marketing-2026-01", RecordedAt: recordedAt},
+		{Purpose: models.ConsentDataProcessing, Granted: true, TextVersion: "terms-2026-01", RecordedAt: recordedAt},
+	}
+
+	tests := []struct {
+		name        string
+		mockError   error
+		wantCalls   int
+		expectedErr string
+	}{
+		{name: "One Row Per Choice", wantCalls: 2},
+		{name: "Database Error", mockError: errors.New("DB connection failed"), wantCalls: 1, expectedErr: "failed to record consent: DB connection failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				at, _ := sqlParam(input, "recorded_at").(*types.FieldMemberStringValue)
+				return at != nil && at.Value == "2026-01-02T03:04:05Z"
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.RecordConsents(ctx, "did:example:123", consents)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", test.wantCalls)
+		})
+	}
+}
+
+func TestListConsents(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: models.ConsentMarketing},
+			&types.FieldMemberStringValue{Value: "true"},
+			&types.FieldMemberStringValue{Value: "marketing-2026-01"},
+			&types.FieldMemberStringValue{Value: "2026-01-02 03:04:05.123+00"},
+		}},
+	}, nil)
+
+	consents, err := db.ListConsents(ctx, "did:example:123")
+
+	assert.NoError(t, err)
+	assert.Len(t, consents, 1)
+	assert.Equal(t, models.ConsentMarketing, consents[0].Purpose)
+	assert.True(t, consents[0].Granted)
+	assert.True(t, time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.UTC).Equal(consents[0].RecordedAt))
+}
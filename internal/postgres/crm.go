@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// CRMContactStore reads what the CRM is sent about an account.
+type CRMContactStore interface {
+	GetCRMContact(ctx context.Context, did string) (models.CRMContact, error)
+}
+
+func (p *PostgresDB) GetCRMContact(ctx context.Context, did string) (models.CRMContact, error) {
+	query := fmt.Sprintf(`
+		SELECT did, email, handle, referral_source, created_at::text, verified::text
+		FROM %s WHERE did = :did AND status <> :erased`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("erased", models.StatusErased),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to load CRM contact")
+		return models.CRMContact{}, fmt.Errorf("failed to load CRM contact: %w", err)
+	}
+
+	if result == nil {
+		return models.CRMContact{}, fmt.Errorf("failed to load CRM contact: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.CRMContact{}, ErrUserNotFound
+	}
+
+	columns := stringColumns(result.Records[0], 6)
+	signupDate, err := time.Parse(postgresTimestamp, columns[4])
+	if err != nil {
+		return models.CRMContact{}, fmt.Errorf("failed to parse signup time %q: %w", columns[4], err)
+	}
+	return models.CRMContact{
+		DID:            columns[0],
+		Email:          columns[1],
+		Handle:         columns[2],
+		ReferralSource: columns[3],
+		SignupDate:     signupDate.UTC(),
+		Verified:       columns[5] == "true",
+	}, nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCRMContact(t *testing.T) {
+	ctx := context.Background()
+	row := func(referral types.Field, createdAt string) [][]types.Field {
+		return [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "did:example:123"},
+			&types.FieldMemberStringValue{Value: "alice@example.com"},
+			&types.FieldMemberStringValue{Value: "alice.shareframe.social"},
+			referral,
+			&types.FieldMemberStringValue{Value: createdAt},
+			&types.FieldMemberStringValue{Value: "true"},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    models.CRMContact
+		expectedErr string
+	}{
+		{
+			name:       "Found",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: row(&types.FieldMemberStringValue{Value: "friend"}, "2026-01-02 03:04:05.123+01")},
+			expected: models.CRMContact{
+				DID:            "did:example:123",
+				Email:          "alice@example.com",
+				Handle:         "alice.shareframe.social",
+				ReferralSource: "friend",
+				SignupDate:     time.Date(2026, 1, 2, 2, 4, 5, 123000000, time.UTC),
+				Verified:       true,
+			},
+		},
+		{
+			name:       "No Referral Source",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: row(&types.FieldMemberIsNull{Value: true}, "2026-01-02 03:04:05+00")},
+			expected: models.CRMContact{
+				DID:        "did:example:123",
+				Email:      "alice@example.com",
+				Handle:     "alice.shareframe.social",
+				SignupDate: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				Verified:   true,
+			},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrUserNotFound.Error(),
+		},
+		{
+			name:        "Bad Timestamp",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{Records: row(&types.FieldMemberIsNull{Value: true}, "yesterday")},
+			expectedErr: `failed to parse signup time "yesterday": parsing time "yesterday" as "2006-01-02 15:04:05.999999-07": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to load CRM contact: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				status, _ := sqlParam(input, "erased").(*types.FieldMemberStringValue)
+				return status != nil && status.Value == models.StatusErased
+			})).Return(test.mockOutput, test.mockError)
+
+			contact, err := db.GetCRMContact(ctx, "did:example:123")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, contact)
+			}
+		})
+	}
+}
@@ -2,31 +2,32 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/confusables"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
 	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/ShareFrame/user-management/pkg/validate"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
 	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
-	"github.com/sirupsen/logrus"
 )
 
-const (
-	DefaultStatus   = "active"
-	DefaultVerified = false
-	DefaultRole     = "user"
-	DefaultPicture  = ""
-	DefaultBanner   = ""
-	DefaultTheme    = "{}"
-	DefaultColor1   = "#FFFFFF"
-	DefaultColor2   = "#000000"
-	QueryTimeout    = 3 * time.Second
-)
+const UsersTable = "users"
 
 type PostgresDBService interface {
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
-	StoreUser(ctx context.Context, user models.CreateUserResponse, event models.UserRequest) error
+	CheckHandleExists(ctx context.Context, handle string) (bool, error)
+	HandleSkeletonExists(ctx context.Context, handle string) (bool, error)
+	StoreUser(ctx context.Context, record models.UserRecord) error
 }
 
 type RDSDataAPI interface {
@@ -38,96 +39,158 @@ type PostgresDB struct {
 	DBClusterARN string
 	SecretARN    string
 	DatabaseName string
+	TablePrefix  string
+	QueryTimeout time.Duration
+	Retry        retry.Policy
 }
 
-func NewPostgresDB(client RDSDataAPI, dbClusterARN, secretARN, database string) *PostgresDB {
+// NewPostgresDB builds a client for the cluster described by cfg. The table
+// prefix scopes every table name to a tenant; pass "" for shared tables.
+func NewPostgresDB(client RDSDataAPI, cfg *config.Config, tablePrefix string) *PostgresDB {
 	return &PostgresDB{
 		Client:       client,
-		DBClusterARN: dbClusterARN,
-		SecretARN:    secretARN,
-		DatabaseName: database,
+		DBClusterARN: cfg.DBClusterARN,
+		SecretARN:    cfg.SecretARN,
+		DatabaseName: cfg.DatabaseName,
+		TablePrefix:  tablePrefix,
+		QueryTimeout: cfg.QueryTimeout,
+		Retry:        cfg.Retry,
 	}
 }
 
-func (p *PostgresDB) StoreUser(ctx context.Context, user models.CreateUserResponse, event models.UserRequest) error {
-	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
-	defer cancel()
+// execute runs a single statement under the configured retry policy. Each
+// attempt gets its own query timeout. Only statements that are safe to run
+// twice go through execute; the rest use executeWrite.
+func (p *PostgresDB) execute(ctx context.Context, query string, params []types.SqlParameter) (*rdsdata.ExecuteStatementOutput, error) {
+	return p.executeWith(ctx, p.Retry, query, params)
+}
 
-	query := `
-		INSERT INTO users 
-		(did, email, handle, created_at, modified_at, status, verified, role, display_name, profile_picture, profile_banner, theme, primary_color, secondary_color) 
-		VALUES 
-		(:did, :email, :handle, NOW(), NOW(), :status, :verified, :role, :display_name, :profile_picture, :profile_banner, CAST(:theme AS JSONB), :primary_color, :secondary_color)`
+// executeWrite runs a statement that must not run twice, such as an INSERT
+// without a conflict clause or an increment. It is only retried when the
+// statement never reached the database: a timeout or server error may come
+// after the write committed.
+func (p *PostgresDB) executeWrite(ctx context.Context, query string, params []types.SqlParameter) (*rdsdata.ExecuteStatementOutput, error) {
+	return p.executeWith(ctx, p.Retry.ForWrite(), query, params)
+}
 
-	params := []types.SqlParameter{
-		newSQLParam("did", user.DID),
-		newSQLParam("email", event.Email),
-		newSQLParam("handle", user.Handle),
-		newSQLParam("status", DefaultStatus),
-		newSQLParam("verified", DefaultVerified),
-		newSQLParam("role", DefaultRole),
-		newSQLParam("display_name", user.Handle),
-		newSQLParam("profile_picture", DefaultPicture),
-		newSQLParam("profile_banner", DefaultBanner),
-		newSQLParam("theme", DefaultTheme),
-		newSQLParam("primary_color", DefaultColor1),
-		newSQLParam("secondary_color", DefaultColor2),
+func (p *PostgresDB) executeWith(ctx context.Context, policy retry.Policy, query string, params []types.SqlParameter) (*rdsdata.ExecuteStatementOutput, error) {
+	timeout := p.QueryTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultQueryTimeout
 	}
 
-	result, err := p.Client.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
-		ResourceArn: aws.String(p.DBClusterARN),
-		SecretArn:   aws.String(p.SecretARN),
-		Database:    aws.String(p.DatabaseName),
-		Sql:         aws.String(query),
-		Parameters:  params,
+	defer metrics.Since(metrics.FromContext(ctx), metrics.DBLatency, time.Now())
+
+	ctx, seg := tracing.Begin(ctx, "RDSDataService", tracing.NamespaceAWS)
+	seg.SetAWS("ExecuteStatement")
+
+	var result *rdsdata.ExecuteStatementOutput
+	err := policy.Do(ctx, "postgres.ExecuteStatement", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var err error
+		result, err = p.Client.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
+			ResourceArn: aws.String(p.DBClusterARN),
+			SecretArn:   aws.String(p.SecretARN),
+			Database:    aws.String(p.DatabaseName),
+			Sql:         aws.String(query),
+			Parameters:  params,
+		})
+		return err
 	})
 
+	seg.Close(err)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"email":  event.Email,
-			"handle": user.Handle,
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyPostgres, err)
+	}
+	return result, apperr.Wrap(apperr.Upstream, err)
+}
+
+// table returns the tenant-scoped name for one of our tables.
+func (p *PostgresDB) table(name string) string {
+	return p.TablePrefix + name
+}
+
+func (p *PostgresDB) StoreUser(ctx context.Context, record models.UserRecord) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s
+		(did, email, normalized_email, handle, handle_skeleton, created_at, modified_at, status, verified, role, display_name, profile_picture, profile_banner, theme, primary_color, secondary_color, locale, country, timezone, bio, pronouns, website, location, referral_source, review_flags, account_type, owners, preferences, schema_version) 
+		VALUES 
+		(:did, :email, :normalized_email, :handle, :handle_skeleton, NOW(), NOW(), :status, :verified, :role, :display_name, :profile_picture, :profile_banner, CAST(:theme AS JSONB), :primary_color, :secondary_color, :locale, :country, :timezone, :bio, :pronouns, :website, :location, :referral_source, :review_flags, :account_type, :owners, CAST(:preferences AS JSONB), :schema_version)`, p.table(UsersTable))
+
+	preferences, err := json.Marshal(record.Preferences)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+
+	params := []types.SqlParameter{
+		newSQLParam("did", record.DID),
+		newSQLParam("email", validate.CanonicalizeEmail(record.Email)),
+		newSQLParam("normalized_email", NormalizeEmail(record.Email)),
+		newSQLParam("handle", record.Handle),
+		newSQLParam("handle_skeleton", confusables.Skeleton(record.Handle)),
+		newSQLParam("status", record.Status),
+		newSQLParam("verified", record.Verified),
+		newSQLParam("role", record.Role),
+		newSQLParam("display_name", record.DisplayName),
+		newSQLParam("profile_picture", record.ProfilePicture),
+		newSQLParam("profile_banner", record.ProfileBanner),
+		newSQLParam("theme", record.Theme),
+		newSQLParam("primary_color", record.PrimaryColor),
+		newSQLParam("secondary_color", record.SecondaryColor),
+		nullableSQLParam("locale", record.Locale),
+		nullableSQLParam("country", record.Country),
+		nullableSQLParam("timezone", record.Timezone),
+		nullableSQLParam("bio", record.Bio),
+		nullableSQLParam("pronouns", record.Pronouns),
+		nullableSQLParam("website", record.Website),
+		nullableSQLParam("location", record.Location),
+		nullableSQLParam("referral_source", record.ReferralSource),
+		nullableSQLParam("review_flags", strings.Join(record.ReviewFlags, ",")),
+		nullableSQLParam("account_type", record.AccountType),
+		nullableSQLParam("owners", strings.Join(record.Owners, ",")),
+		newSQLParam("preferences", string(preferences)),
+		newSQLParam("schema_version", UserSchemaVersion),
+	}
+
+	result, err := p.executeWrite(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"email":  record.Email,
+			"handle": record.Handle,
 		}).Errorf("Failed to store user: %v", err)
 		return fmt.Errorf("failed to store user in PostgreSQL: %w", err)
 	}
 
 	if result == nil {
-		logrus.WithFields(logrus.Fields{
-			"email":  event.Email,
-			"handle": user.Handle,
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"email":  record.Email,
+			"handle": record.Handle,
 		}).Error("ExecuteStatement returned nil response")
 		return fmt.Errorf("failed to store user in PostgreSQL: unexpected nil response")
 	}
 
-	logrus.Infof("User %s successfully stored in PostgreSQL", user.Handle)
+	logging.FromContext(ctx).WithField("handle", record.Handle).Info("User successfully stored in PostgreSQL")
 	return nil
 }
 
-
-
+// CheckEmailExists matches on the normalized address so plus-tags, case and
+// Gmail dots can't be used to register the same mailbox twice.
 func (p *PostgresDB) CheckEmailExists(ctx context.Context, email string) (bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
-	defer cancel()
-
-	query := `SELECT 1 FROM users WHERE email = :email LIMIT 1`
-	params := []types.SqlParameter{newSQLParam("email", email)}
-
-	result, err := p.Client.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
-		ResourceArn: aws.String(p.DBClusterARN),
-		SecretArn:   aws.String(p.SecretARN),
-		Database:    aws.String(p.DatabaseName),
-		Sql:         aws.String(query),
-		Parameters:  params,
-	})
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE normalized_email = :normalized_email LIMIT 1`, p.table(UsersTable))
+	params := []types.SqlParameter{newSQLParam("normalized_email", NormalizeEmail(email))}
 
+	result, err := p.execute(ctx, query, params)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"email": email,
 		}).Errorf("Error checking email existence: %v", err)
 		return false, fmt.Errorf("failed to check email existence: %w", err)
 	}
 
 	if result == nil {
-		logrus.WithFields(logrus.Fields{
+		logging.FromContext(ctx).WithFields(logging.Fields{
 			"email": email,
 		}).Error("ExecuteStatement returned nil response")
 		return false, fmt.Errorf("failed to check email existence: unexpected nil response")
@@ -136,6 +199,63 @@ func (p *PostgresDB) CheckEmailExists(ctx context.Context, email string) (bool,
 	return len(result.Records) > 0, nil
 }
 
+func (p *PostgresDB) CheckHandleExists(ctx context.Context, handle string) (bool, error) {
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE LOWER(handle) = LOWER(:handle) LIMIT 1`, p.table(UsersTable))
+	params := []types.SqlParameter{newSQLParam("handle", handle)}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": handle,
+		}).Errorf("Error checking handle existence: %v", err)
+		return false, fmt.Errorf("failed to check handle existence: %w", err)
+	}
+
+	if result == nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": handle,
+		}).Error("ExecuteStatement returned nil response")
+		return false, fmt.Errorf("failed to check handle existence: unexpected nil response")
+	}
+
+	return len(result.Records) > 0, nil
+}
+
+// HandleSkeletonExists reports whether a stored handle looks the same as
+// handle once both are reduced to their confusables skeleton.
+func (p *PostgresDB) HandleSkeletonExists(ctx context.Context, handle string) (bool, error) {
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE handle_skeleton = :handle_skeleton LIMIT 1`, p.table(UsersTable))
+	params := []types.SqlParameter{newSQLParam("handle_skeleton", confusables.Skeleton(handle))}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"handle": handle,
+		}).Errorf("Error checking handle skeleton: %v", err)
+		return false, fmt.Errorf("failed to check handle skeleton: %w", err)
+	}
+
+	if result == nil {
+		return false, fmt.Errorf("failed to check handle skeleton: unexpected nil response")
+	}
+
+	return len(result.Records) > 0, nil
+}
+
+// NormalizeEmail reduces an address to the mailbox it delivers to for
+// duplicate detection; see validate.NormalizeEmail. The canonical address
+// is still used for delivery.
+func NormalizeEmail(email string) string {
+	return validate.NormalizeEmail(email)
+}
+
+// nullableSQLParam binds an empty string as NULL.
+func nullableSQLParam(name, value string) types.SqlParameter {
+	if value == "" {
+		return types.SqlParameter{Name: aws.String(name), Value: &types.FieldMemberIsNull{Value: true}}
+	}
+	return newSQLParam(name, value)
+}
 
 func newSQLParam(name string, value interface{}) types.SqlParameter {
 	switch v := value.(type) {
@@ -143,8 +263,10 @@ func newSQLParam(name string, value interface{}) types.SqlParameter {
 		return types.SqlParameter{Name: aws.String(name), Value: &types.FieldMemberStringValue{Value: v}}
 	case bool:
 		return types.SqlParameter{Name: aws.String(name), Value: &types.FieldMemberBooleanValue{Value: v}}
+	case int:
+		return types.SqlParameter{Name: aws.String(name), Value: &types.FieldMemberLongValue{Value: int64(v)}}
 	default:
-		logrus.Warnf("Unsupported SQL parameter type for %s", name)
+		logging.Default().Warnf("Unsupported SQL parameter type for %s", name)
 		return types.SqlParameter{}
 	}
 }
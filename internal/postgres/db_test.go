@@ -3,15 +3,26 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/ShareFrame/user-management/config"
 	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
 	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var testConfig = &config.Config{
+	DBClusterARN: "test-cluster",
+	SecretARN:    "test-secret",
+	DatabaseName: "test-db",
+}
+
 type mockRDSClient struct {
 	mock.Mock
 }
@@ -24,17 +35,27 @@ func (m *mockRDSClient) ExecuteStatement(ctx context.Context, input *rdsdata.Exe
 	return nil, args.Error(1)
 }
 
+// sqlParam returns the value bound to the named parameter.
+func sqlParam(input *rdsdata.ExecuteStatementInput, name string) types.Field {
+	for _, p := range input.Parameters {
+		if aws.ToString(p.Name) == name {
+			return p.Value
+		}
+	}
+	return nil
+}
+
 func TestStoreUser(t *testing.T) {
 	mockClient := new(mockRDSClient)
 	ctx := context.Background()
 
-	user := models.CreateUserResponse{
+	record := models.UserRecord{
 		DID:    "did:example:123",
+		Email:  "test@example.com",
 		Handle: "testuser",
-	}
-
-	event := models.UserRequest{
-		Email: "test@example.com",
+		Status: "active",
+		Role:   "user",
+		Theme:  "{}",
 	}
 
 	tests := []struct {
@@ -76,7 +97,7 @@ func TestStoreUser(t *testing.T) {
 					Return(test.mockOutput, nil)
 			}
 
-			err := db.StoreUser(ctx, user, event)
+			err := db.StoreUser(ctx, record)
 
 			if test.expectedErr != "" {
 				assert.Error(t, err, "Expected an error but got nil")
@@ -90,6 +111,73 @@ func TestStoreUser(t *testing.T) {
 	}
 }
 
+func TestStoreUserLocaleAndCountry(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	param := func(input *rdsdata.ExecuteStatementInput, name string) types.Field {
+		for _, p := range input.Parameters {
+			if aws.ToString(p.Name) == name {
+				return p.Value
+			}
+		}
+		return nil
+	}
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		locale, ok := param(input, "locale").(*types.FieldMemberStringValue)
+		_, countryNull := param(input, "country").(*types.FieldMemberIsNull)
+		return ok && locale.Value == "pt-BR" && countryNull
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.StoreUser(ctx, models.UserRecord{DID: "did:example:123", Email: "test@example.com", Handle: "testuser", Theme: "{}", Locale: "pt-BR"})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestStoreUserPreferences(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		preferences, _ := sqlParam(input, "preferences").(*types.FieldMemberStringValue)
+		return preferences != nil && strings.Contains(preferences.Value, `"directMessages":"following"`) &&
+			strings.Contains(*input.Sql, "CAST(:preferences AS JSONB)")
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.StoreUser(ctx, models.UserRecord{DID: "did:example:123", Email: "test@example.com", Handle: "testuser", Theme: "{}", Preferences: models.DefaultPreferences()})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestStoreUserOrganization(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		accountType, _ := sqlParam(input, "account_type").(*types.FieldMemberStringValue)
+		owners, _ := sqlParam(input, "owners").(*types.FieldMemberStringValue)
+		return accountType != nil && accountType.Value == models.AccountTypeOrganization &&
+			owners != nil && owners.Value == "did:plc:alice,did:plc:bob"
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.StoreUser(ctx, models.UserRecord{
+		DID:         "did:plc:acme",
+		Email:       "team@acme.example",
+		Handle:      "acme",
+		Theme:       "{}",
+		AccountType: models.AccountTypeOrganization,
+		Owners:      []string{"did:plc:alice", "did:plc:bob"},
+	})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
 
 func TestCheckEmailExists(t *testing.T) {
 	mockClient := new(mockRDSClient)
@@ -161,3 +249,165 @@ func TestCheckEmailExists(t *testing.T) {
 	}
 }
 
+func TestCheckHandleExists(t *testing.T) {
+	mockClient := new(mockRDSClient)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		mockOutput   *rdsdata.ExecuteStatementOutput
+		mockError    error
+		expectedBool bool
+		expectedErr  string
+	}{
+		{
+			name: "Handle Exists",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{
+					{&types.FieldMemberStringValue{Value: "1"}},
+				},
+			},
+			expectedBool: true,
+		},
+		{
+			name: "Handle Does Not Exist",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{},
+			},
+			expectedBool: false,
+		},
+		{
+			name:        "Database Connection Failure",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to check handle existence: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient.ExpectedCalls = nil
+
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).
+				Return(test.mockOutput, test.mockError)
+
+			result, err := db.CheckHandleExists(ctx, "alice.shareframe.social")
+
+			assert.Equal(t, test.expectedBool, result)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedErr)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"Lowercases", "User@Example.com", "user@example.com"},
+		{"Strips Plus Tag", "user+signup@example.com", "user@example.com"},
+		{"Gmail Dots And Tag", "First.Last+tag@Gmail.com", "firstlast@gmail.com"},
+		{"Googlemail Alias", "first.last@googlemail.com", "firstlast@gmail.com"},
+		{"Keeps Dots Elsewhere", "first.last@example.com", "first.last@example.com"},
+		{"Trims Whitespace", "  user@example.com ", "user@example.com"},
+		{"Strips Display Name", "User <user+tag@example.com>", "user@example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeEmail(test.email))
+		})
+	}
+}
+
+func TestCheckEmailExistsUsesNormalizedEmail(t *testing.T) {
+	mockClient := new(mockRDSClient)
+	ctx := context.Background()
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		value, ok := input.Parameters[0].Value.(*types.FieldMemberStringValue)
+		return ok && *input.Parameters[0].Name == "normalized_email" && value.Value == "user@gmail.com"
+	})).Return(&rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 1}}}}, nil)
+
+	exists, err := db.CheckEmailExists(ctx, "U.ser+promo@Gmail.com")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleSkeletonExists(t *testing.T) {
+	mockClient := new(mockRDSClient)
+	ctx := context.Background()
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		value, ok := input.Parameters[0].Value.(*types.FieldMemberStringValue)
+		return ok && value.Value == "shareframe.shareframe.social"
+	})).Return(&rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 1}}}}, nil).Once()
+
+	exists, err := db.HandleSkeletonExists(ctx, "sharefrarne.shareframe.social")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(nil, errors.New("DB connection failed")).Once()
+	_, err = db.HandleSkeletonExists(ctx, "alice.shareframe.social")
+	assert.ErrorContains(t, err, "failed to check handle skeleton")
+	mockClient.AssertExpectations(t)
+}
+
+func TestWritesOnlyRetriedWhenUnsent(t *testing.T) {
+	ctx := context.Background()
+	cfg := *testConfig
+	cfg.Retry = retry.Policy{MaxAttempts: 2, RetryableClasses: retry.DefaultClasses}
+
+	serverError := &smithy.GenericAPIError{Code: "InternalServerErrorException", Fault: smithy.FaultServer}
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException"}
+
+	tests := []struct {
+		name          string
+		firstError    error
+		write         bool
+		expectedCalls int
+	}{
+		{name: "Read Retried After Server Error", firstError: serverError, expectedCalls: 2},
+		{name: "Write Not Retried After Server Error", firstError: serverError, write: true, expectedCalls: 1},
+		{name: "Write Retried When Throttled", firstError: throttled, write: true, expectedCalls: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, &cfg, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(nil, test.firstError).Once()
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+				NumberOfRecordsUpdated: 1,
+				Records:                [][]types.Field{{&types.FieldMemberLongValue{Value: 0}}},
+			}, nil).Once()
+
+			var err error
+			if test.write {
+				err = db.StoreUser(ctx, models.UserRecord{DID: "did:plc:123", Email: "test@example.com", Handle: "test"})
+			} else {
+				_, err = db.CheckEmailExists(ctx, "test@example.com")
+			}
+
+			if test.expectedCalls == 1 {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", test.expectedCalls)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const PendingEmailsTable = "pending_emails"
+
+// PendingEmailStore queues emails the provider couldn't take, for the
+// pending email sender to deliver later.
+type PendingEmailStore interface {
+	QueueEmail(ctx context.Context, email models.PendingEmail) error
+	// DueEmails returns the oldest unsent emails that have been tried fewer
+	// than maxAttempts times.
+	DueEmails(ctx context.Context, maxAttempts, limit int) ([]models.PendingEmail, error)
+	MarkEmailSent(ctx context.Context, id int) error
+	RecordEmailFailure(ctx context.Context, id int, reason string) error
+}
+
+// VerificationEmailTracker marks accounts whose verification email hasn't
+// been delivered yet.
+type VerificationEmailTracker interface {
+	SetVerificationEmailPending(ctx context.Context, did string, pending bool) error
+}
+
+func (p *PostgresDB) QueueEmail(ctx context.Context, email models.PendingEmail) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (tenant, did, sender, recipient, subject, html, attempts, queued_at)
+		VALUES (:tenant, :did, :sender, :recipient, :subject, :html, 0, NOW())`,
+		p.table(PendingEmailsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("tenant", email.Tenant),
+		newSQLParam("did", email.DID),
+		newSQLParam("sender", email.From),
+		newSQLParam("recipient", email.Recipient),
+		newSQLParam("subject", email.Subject),
+		newSQLParam("html", email.HTML),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", email.DID).Error("Failed to queue email")
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) DueEmails(ctx context.Context, maxAttempts, limit int) ([]models.PendingEmail, error) {
+	query := fmt.Sprintf(`
+		SELECT id, tenant, did, sender, recipient, subject, html, attempts FROM %s
+		WHERE sent_at IS NULL AND attempts < :max_attempts
+		ORDER BY queued_at LIMIT :limit`, p.table(PendingEmailsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("max_attempts", maxAttempts),
+		newSQLParam("limit", limit),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to load pending emails")
+		return nil, fmt.Errorf("failed to load pending emails: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to load pending emails: unexpected nil response")
+	}
+
+	emails := make([]models.PendingEmail, 0, len(result.Records))
+	for _, row := range result.Records {
+		if len(row) < 8 {
+			return nil, fmt.Errorf("failed to load pending emails: unexpected response")
+		}
+		columns := stringColumns(row, 8)
+		emails = append(emails, models.PendingEmail{
+			ID:        longValue(row[0]),
+			Tenant:    columns[1],
+			DID:       columns[2],
+			From:      columns[3],
+			Recipient: columns[4],
+			Subject:   columns[5],
+			HTML:      columns[6],
+			Attempts:  longValue(row[7]),
+		})
+	}
+	return emails, nil
+}
+
+func (p *PostgresDB) MarkEmailSent(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`UPDATE %s SET sent_at = NOW(), attempts = attempts + 1 WHERE id = :id`, p.table(PendingEmailsTable))
+
+	if _, err := p.executeWrite(ctx, query, []types.SqlParameter{newSQLParam("id", id)}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("email_id", id).Error("Failed to mark email sent")
+		return fmt.Errorf("failed to mark email sent: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) RecordEmailFailure(ctx context.Context, id int, reason string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET attempts = attempts + 1, last_error = :last_error, last_attempt_at = NOW()
+		WHERE id = :id`, p.table(PendingEmailsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("id", id),
+		newSQLParam("last_error", reason),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("email_id", id).Error("Failed to record email failure")
+		return fmt.Errorf("failed to record email failure: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) SetVerificationEmailPending(ctx context.Context, did string, pending bool) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET verification_email_pending = :pending, modified_at = NOW()
+		WHERE did = :did`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("pending", pending),
+	}
+
+	if _, err := p.execute(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"did":     did,
+			"pending": pending,
+		}).Error("Failed to update verification email status")
+		return fmt.Errorf("failed to update verification email status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQueueEmail(t *testing.T) {
+	ctx := context.Background()
+	email := models.PendingEmail{
+		Tenant:    "default",
+		DID:       "did:plc:alice",
+		From:      "hello@shareframe.social",
+		Recipient: "alice@example.com",
+		Subject:   "Welcome",
+		HTML:      "<p>Welcome</p>",
+	}
+
+	tests := []struct {
+		name        string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Queued"},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to queue email: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				recipient, _ := sqlParam(input, "recipient").(*types.FieldMemberStringValue)
+				return strings.Contains(*input.Sql, PendingEmailsTable) && recipient != nil && recipient.Value == "alice@example.com"
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.QueueEmail(ctx, email)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDueEmails(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    []models.PendingEmail
+		expectedErr string
+	}{
+		{
+			name: "Emails Returned",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{{
+					&types.FieldMemberLongValue{Value: 7},
+					&types.FieldMemberStringValue{Value: "default"},
+					&types.FieldMemberStringValue{Value: "did:plc:alice"},
+					&types.FieldMemberStringValue{Value: "hello@shareframe.social"},
+					&types.FieldMemberStringValue{Value: "alice@example.com"},
+					&types.FieldMemberStringValue{Value: "Welcome"},
+					&types.FieldMemberStringValue{Value: "<p>Welcome</p>"},
+					&types.FieldMemberLongValue{Value: 2},
+				}},
+			},
+			expected: []models.PendingEmail{{
+				ID:        7,
+				Tenant:    "default",
+				DID:       "did:plc:alice",
+				From:      "hello@shareframe.social",
+				Recipient: "alice@example.com",
+				Subject:   "Welcome",
+				HTML:      "<p>Welcome</p>",
+				Attempts:  2,
+			}},
+		},
+		{
+			name:       "Queue Empty",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+			expected:   []models.PendingEmail{},
+		},
+		{
+			name: "Short Row",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 7}}},
+			},
+			expectedErr: "failed to load pending emails: unexpected response",
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to load pending emails: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				maxAttempts, _ := sqlParam(input, "max_attempts").(*types.FieldMemberLongValue)
+				return maxAttempts != nil && maxAttempts.Value == 10
+			})).Return(test.mockOutput, test.mockError)
+
+			emails, err := db.DueEmails(ctx, 10, 25)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, emails)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailStatusUpdates(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		call        func(db *PostgresDB) error
+		table       string
+		mockError   error
+		expectedErr string
+	}{
+		{
+			name:  "Mark Sent",
+			call:  func(db *PostgresDB) error { return db.MarkEmailSent(ctx, 7) },
+			table: PendingEmailsTable,
+		},
+		{
+			name:        "Mark Sent Fails",
+			call:        func(db *PostgresDB) error { return db.MarkEmailSent(ctx, 7) },
+			table:       PendingEmailsTable,
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to mark email sent: DB connection failed",
+		},
+		{
+			name:  "Record Failure",
+			call:  func(db *PostgresDB) error { return db.RecordEmailFailure(ctx, 7, "provider unavailable") },
+			table: PendingEmailsTable,
+		},
+		{
+			name:  "Mark Account Pending",
+			call:  func(db *PostgresDB) error { return db.SetVerificationEmailPending(ctx, "did:plc:alice", true) },
+			table: "tenant_" + UsersTable,
+		},
+		{
+			name:        "Mark Account Pending Fails",
+			call:        func(db *PostgresDB) error { return db.SetVerificationEmailPending(ctx, "did:plc:alice", true) },
+			table:       "tenant_" + UsersTable,
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to update verification email status: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "tenant_")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				return strings.Contains(*input.Sql, test.table)
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := test.call(db)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
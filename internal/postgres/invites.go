@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const InvitesTable = "invites"
+
+// InviteCodeAvailable reports whether code is in the invites table and can
+// still be redeemed. The PDS remains the authority and consumes the code;
+// this only lets a mistyped or spent code fail before the PDS is called.
+func (p *PostgresDB) InviteCodeAvailable(ctx context.Context, code string) (bool, error) {
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE code = :code AND NOT disabled AND uses < max_uses LIMIT 1`, p.table(InvitesTable))
+	params := []types.SqlParameter{newSQLParam("code", code)}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error checking invite code")
+		return false, fmt.Errorf("failed to check invite code: %w", err)
+	}
+
+	if result == nil {
+		return false, fmt.Errorf("failed to check invite code: unexpected nil response")
+	}
+
+	return len(result.Records) > 0, nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInviteCodeAvailable(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    bool
+		expectedErr string
+	}{
+		{
+			name:       "Code Available",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 1}}}},
+			expected:   true,
+		},
+		{
+			name:       "Unknown Or Spent Code",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+			expected:   false,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to check invite code: DB connection failed",
+		},
+		{
+			name:        "Nil Response",
+			expectedErr: "failed to check invite code: unexpected nil response",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "acme_")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				value := input.Parameters[0].Value.(*types.FieldMemberStringValue)
+				return strings.Contains(aws.ToString(input.Sql), "FROM acme_invites") && value.Value == "shareframe-app-abcde-fghij"
+			})).Return(test.mockOutput, test.mockError)
+
+			available, err := db.InviteCodeAvailable(ctx, "shareframe-app-abcde-fghij")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, available)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
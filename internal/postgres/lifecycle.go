@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// ErrStatusChanged is returned when an account is no longer in the status a
+// transition started from, because another request moved it first.
+var ErrStatusChanged = errors.New("account status changed")
+
+// AccountStatusStore reads an account and moves it between lifecycle
+// statuses.
+type AccountStatusStore interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	// TransitionStatus moves the account from one status to another,
+	// setting the verified flag to match, only if it is still in from.
+	TransitionStatus(ctx context.Context, did, from, to string, verified bool) error
+}
+
+func (p *PostgresDB) TransitionStatus(ctx context.Context, did, from, to string, verified bool) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = :to, verified = :verified, modified_at = NOW()
+		WHERE did = :did AND status = :from`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("from", from),
+		newSQLParam("to", to),
+		newSQLParam("verified", verified),
+	}
+
+	result, err := p.executeWrite(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"did":  did,
+			"from": from,
+			"to":   to,
+		}).Error("Failed to change account status")
+		return fmt.Errorf("failed to change account status: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to change account status: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
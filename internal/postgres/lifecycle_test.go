@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionStatus(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:       "Moved",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Status Changed",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrStatusChanged,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to change account status: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				from, _ := sqlParam(input, "from").(*types.FieldMemberStringValue)
+				to, _ := sqlParam(input, "to").(*types.FieldMemberStringValue)
+				verified, _ := sqlParam(input, "verified").(*types.FieldMemberBooleanValue)
+				return from != nil && from.Value == "pending" &&
+					to != nil && to.Value == "verified" &&
+					verified != nil && verified.Value
+			})).Return(test.mockOutput, test.mockError)
+
+			err := db.TransitionStatus(ctx, "did:example:123", "pending", "verified", true)
+
+			switch {
+			case errors.Is(test.expectedErr, ErrStatusChanged):
+				assert.ErrorIs(t, err, ErrStatusChanged)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// OnboardingStore records how far an account got through being seeded after
+// signup.
+type OnboardingStore interface {
+	SetOnboardingState(ctx context.Context, did, state string) error
+}
+
+func (p *PostgresDB) SetOnboardingState(ctx context.Context, did, state string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET onboarding = :onboarding, modified_at = NOW()
+		WHERE did = :did`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("onboarding", state),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to store onboarding state")
+		return fmt.Errorf("failed to store onboarding state: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to store onboarding state: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetOnboardingState(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:       "Stored",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to store onboarding state: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				state, _ := sqlParam(input, "onboarding").(*types.FieldMemberStringValue)
+				return state != nil && state.Value == models.OnboardingSeeded
+			})).Return(test.mockOutput, test.mockError)
+
+			err := db.SetOnboardingState(ctx, "did:example:123", models.OnboardingSeeded)
+
+			switch {
+			case errors.Is(test.expectedErr, ErrUserNotFound):
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const PublishedEventsTable = "published_events"
+
+// EventOutbox records which events each subscriber has been sent, so a
+// retried signup doesn't announce the same event twice.
+type EventOutbox interface {
+	// ClaimEvent marks the event as being published to subscriber and
+	// reports false if it already was.
+	ClaimEvent(ctx context.Context, eventID, subscriber string) (bool, error)
+	// ReleaseEvent undoes a claim whose publish failed, so a retry can send
+	// it.
+	ReleaseEvent(ctx context.Context, eventID, subscriber string) error
+}
+
+func (p *PostgresDB) ClaimEvent(ctx context.Context, eventID, subscriber string) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (event_id, subscriber, published_at)
+		VALUES (:event_id, :subscriber, NOW())
+		ON CONFLICT (event_id, subscriber) DO NOTHING
+		RETURNING event_id`, p.table(PublishedEventsTable))
+
+	result, err := p.executeWrite(ctx, query, outboxParams(eventID, subscriber))
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"event_id":   eventID,
+			"subscriber": subscriber,
+		}).Error("Failed to claim event")
+		return false, fmt.Errorf("failed to claim event: %w", err)
+	}
+
+	if result == nil {
+		return false, fmt.Errorf("failed to claim event: unexpected nil response")
+	}
+
+	return len(result.Records) > 0, nil
+}
+
+func (p *PostgresDB) ReleaseEvent(ctx context.Context, eventID, subscriber string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE event_id = :event_id AND subscriber = :subscriber`, p.table(PublishedEventsTable))
+
+	if _, err := p.execute(ctx, query, outboxParams(eventID, subscriber)); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"event_id":   eventID,
+			"subscriber": subscriber,
+		}).Error("Failed to release event")
+		return fmt.Errorf("failed to release event: %w", err)
+	}
+
+	return nil
+}
+
+func outboxParams(eventID, subscriber string) []types.SqlParameter {
+	return []types.SqlParameter{
+		newSQLParam("event_id", eventID),
+		newSQLParam("subscriber", subscriber),
+	}
+}
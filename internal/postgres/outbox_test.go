@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClaimEvent(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		mockOutput   *rdsdata.ExecuteStatementOutput
+		mockError    error
+		expectedBool bool
+		expectedErr  string
+	}{
+		{
+			name: "First Publish",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{{&types.FieldMemberStringValue{Value: "abc"}}},
+			},
+			expectedBool: true,
+		},
+		{
+			name:         "Already Published",
+			mockOutput:   &rdsdata.ExecuteStatementOutput{},
+			expectedBool: false,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to claim event: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).
+				Return(test.mockOutput, test.mockError)
+
+			claimed, err := db.ClaimEvent(ctx, "abc", "sns")
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedBool, claimed)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReleaseEvent(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		subscriber, _ := sqlParam(input, "subscriber").(*types.FieldMemberStringValue)
+		return subscriber != nil && subscriber.Value == "webhook:analytics"
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.ReleaseEvent(ctx, "abc", "webhook:analytics")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const PhoneCodesTable = "phone_codes"
+
+// PhoneStore keeps each account's phone number and the one-time code last
+// sent to it. Codes are stored hashed and lapse at their expiry.
+type PhoneStore interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	// VerifiedPhoneAccounts counts the accounts other than did that have
+	// verified phone.
+	VerifiedPhoneAccounts(ctx context.Context, phone, did string) (int, error)
+	// StartPhoneVerification stores a new code for did and sets its phone,
+	// unverified, returning when the code expires. It reports false,
+	// storing nothing, if the last code was sent less than cooldown ago.
+	StartPhoneVerification(ctx context.Context, did, phone, codeHash string, ttl, cooldown time.Duration) (time.Time, bool, error)
+	// PendingPhoneCode returns ErrPhoneCodeNotFound if did has no
+	// unexpired code.
+	PendingPhoneCode(ctx context.Context, did string) (models.PhoneCode, error)
+	RecordPhoneAttempt(ctx context.Context, did string) error
+	// ConfirmPhone marks did's phone verified, discards its code and
+	// returns the account's status and review flags.
+	ConfirmPhone(ctx context.Context, did string) (models.UserRecord, error)
+}
+
+// ErrPhoneCodeNotFound is returned when an account has no unexpired code.
+var ErrPhoneCodeNotFound = errors.New("phone code not found")
+
+func (p *PostgresDB) VerifiedPhoneAccounts(ctx context.Context, phone, did string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s
+		WHERE phone = :phone AND phone_verified AND did <> :did`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("phone", phone),
+		newSQLParam("did", did),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("phone", phone).Error("Failed to count accounts for phone")
+		return 0, fmt.Errorf("failed to count accounts for phone: %w", err)
+	}
+
+	if result == nil || len(result.Records) == 0 || len(result.Records[0]) == 0 {
+		return 0, fmt.Errorf("failed to count accounts for phone: unexpected response")
+	}
+	return longValue(result.Records[0][0]), nil
+}
+
+func (p *PostgresDB) StartPhoneVerification(ctx context.Context, did, phone, codeHash string, ttl, cooldown time.Duration) (time.Time, bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s AS c (did, code_hash, attempts, sent_at, expires_at)
+		VALUES (:did, :code_hash, 0, NOW(), NOW() + CAST(:ttl AS INTERVAL))
+		ON CONFLICT (did) DO UPDATE SET
+			code_hash = EXCLUDED.code_hash,
+			attempts = 0,
+			sent_at = EXCLUDED.sent_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE c.sent_at <= NOW() - CAST(:cooldown AS INTERVAL)
+		RETURNING expires_at::text`, p.table(PhoneCodesTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("code_hash", codeHash),
+		newSQLParam("ttl", intervalParam(ttl)),
+		newSQLParam("cooldown", intervalParam(cooldown)),
+	}
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"did":   did,
+		"phone": phone,
+	})
+	result, err := p.executeWrite(ctx, query, params)
+	if err != nil {
+		log.WithError(err).Error("Failed to store phone code")
+		return time.Time{}, false, fmt.Errorf("failed to store phone code: %w", err)
+	}
+
+	if result == nil {
+		return time.Time{}, false, fmt.Errorf("failed to store phone code: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	expiresAt, err := time.Parse(postgresTimestamp, stringColumns(result.Records[0], 1)[0])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse phone code expiry: %w", err)
+	}
+
+	update := fmt.Sprintf(`
+		UPDATE %s SET phone = :phone, phone_verified = FALSE, modified_at = NOW()
+		WHERE did = :did`, p.table(UsersTable))
+	result, err = p.execute(ctx, update, []types.SqlParameter{newSQLParam("did", did), newSQLParam("phone", phone)})
+	if err != nil {
+		log.WithError(err).Error("Failed to store phone number")
+		return time.Time{}, false, fmt.Errorf("failed to store phone number: %w", err)
+	}
+	if result == nil {
+		return time.Time{}, false, fmt.Errorf("failed to store phone number: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return time.Time{}, false, ErrUserNotFound
+	}
+
+	return expiresAt.UTC(), true, nil
+}
+
+func (p *PostgresDB) PendingPhoneCode(ctx context.Context, did string) (models.PhoneCode, error) {
+	query := fmt.Sprintf(`
+		SELECT c.did, COALESCE(u.phone, ''), c.code_hash, c.attempts
+		FROM %s c JOIN %s u ON u.did = c.did
+		WHERE c.did = :did AND c.expires_at > NOW()`, p.table(PhoneCodesTable), p.table(UsersTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to load phone code")
+		return models.PhoneCode{}, fmt.Errorf("failed to load phone code: %w", err)
+	}
+
+	if result == nil {
+		return models.PhoneCode{}, fmt.Errorf("failed to load phone code: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.PhoneCode{}, ErrPhoneCodeNotFound
+	}
+
+	row := result.Records[0]
+	columns := stringColumns(row, 3)
+	code := models.PhoneCode{DID: columns[0], Phone: columns[1], CodeHash: columns[2]}
+	if len(row) > 3 {
+		code.Attempts = longValue(row[3])
+	}
+	return code, nil
+}
+
+func (p *PostgresDB) RecordPhoneAttempt(ctx context.Context, did string) error {
+	query := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1 WHERE did = :did`, p.table(PhoneCodesTable))
+
+	if _, err := p.executeWrite(ctx, query, []types.SqlParameter{newSQLParam("did", did)}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to record phone code attempt")
+		return fmt.Errorf("failed to record phone code attempt: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresDB) ConfirmPhone(ctx context.Context, did string) (models.UserRecord, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET phone_verified = TRUE, modified_at = NOW()
+		WHERE did = :did
+		RETURNING did, handle, status, COALESCE(review_flags, '')`, p.table(UsersTable))
+
+	params := []types.SqlParameter{newSQLParam("did", did)}
+
+	log := logging.FromContext(ctx).WithField("did", did)
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		log.WithError(err).Error("Failed to confirm phone")
+		return models.UserRecord{}, fmt.Errorf("failed to confirm phone: %w", err)
+	}
+
+	if result == nil {
+		return models.UserRecord{}, fmt.Errorf("failed to confirm phone: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.UserRecord{}, ErrUserNotFound
+	}
+
+	discard := fmt.Sprintf(`DELETE FROM %s WHERE did = :did`, p.table(PhoneCodesTable))
+	if _, err := p.execute(ctx, discard, params); err != nil {
+		// The phone is verified; the spent code just lapses at its expiry.
+		log.WithError(err).Warn("Failed to discard phone code")
+	}
+
+	columns := stringColumns(result.Records[0], 4)
+	account := models.UserRecord{DID: columns[0], Handle: columns[1], Status: columns[2]}
+	if columns[3] != "" {
+		account.ReviewFlags = strings.Split(columns[3], ",")
+	}
+	return account, nil
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func isPhoneCodeStatement(input *rdsdata.ExecuteStatementInput) bool {
+	return strings.Contains(aws.ToString(input.Sql), PhoneCodesTable)
+}
+
+func TestStartPhoneVerification(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := "2026-02-01 00:10:00+00"
+
+	tests := []struct {
+		name        string
+		codeOutput  *rdsdata.ExecuteStatementOutput
+		codeErr     error
+		userOutput  *rdsdata.ExecuteStatementOutput
+		started     bool
+		wantCalls   int
+		expectedErr string
+	}{
+		{
+			name:       "Started",
+			codeOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberStringValue{Value: expiresAt}}}},
+			userOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+			started:    true,
+			wantCalls:  2,
+		},
+		{
+			name:       "Cooling Down",
+			codeOutput: &rdsdata.ExecuteStatementOutput{},
+			wantCalls:  1,
+		},
+		{
+			name:        "Unknown Account",
+			codeOutput:  &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{&types.FieldMemberStringValue{Value: expiresAt}}}},
+			userOutput:  &rdsdata.ExecuteStatementOutput{},
+			wantCalls:   2,
+			expectedErr: ErrUserNotFound.Error(),
+		},
+		{
+			name:        "Database Error",
+			codeErr:     errors.New("DB connection failed"),
+			wantCalls:   1,
+			expectedErr: "failed to store phone code: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				cooldown, _ := sqlParam(input, "cooldown").(*types.FieldMemberStringValue)
+				return isPhoneCodeStatement(input) && cooldown != nil && cooldown.Value == "60 seconds"
+			})).Return(test.codeOutput, test.codeErr)
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				phone, _ := sqlParam(input, "phone").(*types.FieldMemberStringValue)
+				return !isPhoneCodeStatement(input) && phone != nil && phone.Value == "+14155550123"
+			})).Return(test.userOutput, nil)
+
+			expires, started, err := db.StartPhoneVerification(ctx, "did:plc:alice", "+14155550123", "hash", 10*time.Minute, time.Minute)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.started, started)
+			}
+			if test.started {
+				assert.Equal(t, time.Date(2026, 2, 1, 0, 10, 0, 0, time.UTC), expires)
+			}
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", test.wantCalls)
+		})
+	}
+}
+
+func TestPendingPhoneCode(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "did:plc:alice"},
+			&types.FieldMemberStringValue{Value: "+14155550123"},
+			&types.FieldMemberStringValue{Value: "hash"},
+			&types.FieldMemberLongValue{Value: 2},
+		}},
+	}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{}, nil).Once()
+
+	code, err := db.PendingPhoneCode(ctx, "did:plc:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PhoneCode{DID: "did:plc:alice", Phone: "+14155550123", CodeHash: "hash", Attempts: 2}, code)
+
+	_, err = db.PendingPhoneCode(ctx, "did:plc:bob")
+	assert.ErrorIs(t, err, ErrPhoneCodeNotFound)
+}
+
+func TestVerifiedPhoneAccounts(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		did, _ := sqlParam(input, "did").(*types.FieldMemberStringValue)
+		return did != nil && did.Value == "did:plc:alice"
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{&types.FieldMemberLongValue{Value: 3}}},
+	}, nil)
+
+	count, err := db.VerifiedPhoneAccounts(ctx, "+14155550123", "did:plc:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestConfirmPhone(t *testing.T) {
+	ctx := context.Background()
+	row := [][]types.Field{{
+		&types.FieldMemberStringValue{Value: "did:plc:alice"},
+		&types.FieldMemberStringValue{Value: "alice.shareframe.social"},
+		&types.FieldMemberStringValue{Value: models.StatusPendingReview},
+		&types.FieldMemberStringValue{Value: "signup_risk,profanity"},
+	}}
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		discardErr  error
+		expected    models.UserRecord
+		expectedErr error
+	}{
+		{
+			name:       "Confirmed",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: row},
+			expected: models.UserRecord{
+				DID:         "did:plc:alice",
+				Handle:      "alice.shareframe.social",
+				Status:      models.StatusPendingReview,
+				ReviewFlags: []string{"signup_risk", "profanity"},
+			},
+		},
+		{
+			name:       "Code Left To Lapse",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: row},
+			discardErr: errors.New("DB connection failed"),
+			expected: models.UserRecord{
+				DID:         "did:plc:alice",
+				Handle:      "alice.shareframe.social",
+				Status:      models.StatusPendingReview,
+				ReviewFlags: []string{"signup_risk", "profanity"},
+			},
+		},
+		{
+			name:        "Unknown Account",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				return !isPhoneCodeStatement(input)
+			})).Return(test.mockOutput, nil)
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(isPhoneCodeStatement)).
+				Return(&rdsdata.ExecuteStatementOutput{}, test.discardErr)
+
+			account, err := db.ConfirmPhone(ctx, "did:plc:alice")
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				mockClient.AssertNumberOfCalls(t, "ExecuteStatement", 1)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, account)
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", 2)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// ErrUserNotFound is returned when no stored account has the requested DID.
+var ErrUserNotFound = errors.New("user not found")
+
+// PrivacyStore reads and erases what a tenant's tables hold on one account,
+// for data subject access and erasure requests.
+type PrivacyStore interface {
+	GetUser(ctx context.Context, did string) (models.UserRecord, error)
+	ListAuditEvents(ctx context.Context, did string) ([]models.AuditEvent, error)
+	// AnonymizeUser overwrites every personal field of the account's row.
+	// The row itself is kept so the DID is never reissued.
+	AnonymizeUser(ctx context.Context, did string) error
+}
+
+// EmailDataStore reads and deletes the emails kept for one account in the
+// shared pending email table.
+type EmailDataStore interface {
+	ListEmails(ctx context.Context, did string) ([]models.PendingEmail, error)
+	DeleteEmails(ctx context.Context, did string) error
+}
+
+func (p *PostgresDB) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT did, email, handle, display_name, status, verified::text, role, profile_picture, profile_banner,
+		theme::text, primary_color, secondary_color, locale, country, timezone, account_type, owners, onboarding,
+		bio, pronouns, website, location, preferences::text
+		FROM %s WHERE did = :did`, p.table(UsersTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to load user")
+		return models.UserRecord{}, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	if result == nil {
+		return models.UserRecord{}, fmt.Errorf("failed to load user: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.UserRecord{}, ErrUserNotFound
+	}
+
+	columns := stringColumns(result.Records[0], 23)
+	user := models.UserRecord{
+		DID:            columns[0],
+		Email:          columns[1],
+		Handle:         columns[2],
+		DisplayName:    columns[3],
+		Status:         columns[4],
+		Verified:       columns[5] == "true",
+		Role:           columns[6],
+		ProfilePicture: columns[7],
+		ProfileBanner:  columns[8],
+		Theme:          columns[9],
+		PrimaryColor:   columns[10],
+		SecondaryColor: columns[11],
+		Locale:         columns[12],
+		Country:        columns[13],
+		Timezone:       columns[14],
+		AccountType:    columns[15],
+		Onboarding:     columns[17],
+		Bio:            columns[18],
+		Pronouns:       columns[19],
+		Website:        columns[20],
+		Location:       columns[21],
+	}
+	if columns[16] != "" {
+		user.Owners = strings.Split(columns[16], ",")
+	}
+	if columns[22] != "" {
+		if err := json.Unmarshal([]byte(columns[22]), &user.Preferences); err != nil {
+			return models.UserRecord{}, fmt.Errorf("failed to decode preferences: %w", err)
+		}
+	}
+	return user, nil
+}
+
+// ListAuditEvents returns the account's audit trail, oldest first.
+func (p *PostgresDB) ListAuditEvents(ctx context.Context, did string) ([]models.AuditEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT event, did, handle, actor, request_id, details::text, occurred_at::text FROM %s
+		WHERE did = :did ORDER BY occurred_at`, p.table(AuditEventsTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to list audit events")
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list audit events: unexpected nil response")
+	}
+
+	events := make([]models.AuditEvent, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 7)
+		event := models.AuditEvent{
+			Event:      columns[0],
+			DID:        columns[1],
+			Handle:     columns[2],
+			Actor:      columns[3],
+			RequestID:  columns[4],
+			OccurredAt: columns[6],
+		}
+		if columns[5] != "" {
+			if err := json.Unmarshal([]byte(columns[5]), &event.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit event details: %w", err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// AnonymizeUser replaces the email address and handle with placeholders
+// derived from the DID, so unique constraints still hold, clears the
+// profile and phone number and deletes any pending phone code. It is a
+// no-op for an account that is already anonymized.
+func (p *PostgresDB) AnonymizeUser(ctx context.Context, did string) error {
+	return p.anonymizeUser(ctx, did, models.StatusErased)
+}
+
+// anonymizeUser anonymizes the account's row and leaves it in status. The
+// phone code goes in the same statement, so the number it was sent to
+// isn't kept apart from the account.
+func (p *PostgresDB) anonymizeUser(ctx context.Context, did, status string) error {
+	placeholder := ErasedPlaceholder(did)
+	query := fmt.Sprintf(`
+		WITH codes AS (DELETE FROM %s WHERE did = :did)
+		UPDATE %s SET email = :email, normalized_email = :email, handle = :handle, handle_skeleton = :handle,
+		display_name = '', profile_picture = '', profile_banner = '', locale = NULL, country = NULL, timezone = NULL,
+		bio = NULL, pronouns = NULL, website = NULL, location = NULL,
+		phone = NULL, phone_verified = FALSE, status = :status, modified_at = NOW()
+		WHERE did = :did`, p.table(PhoneCodesTable), p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", did),
+		newSQLParam("email", placeholder+"@erased.invalid"),
+		newSQLParam("handle", placeholder),
+		newSQLParam("status", status),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to anonymize user")
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to anonymize user: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ErasedPlaceholder is what an erased account's handle and email local part
+// become. It is unique per account without revealing the DID.
+func ErasedPlaceholder(did string) string {
+	sum := sha256.Sum256([]byte(did))
+	return "erased-" + hex.EncodeToString(sum[:8])
+}
+
+func (p *PostgresDB) ListEmails(ctx context.Context, did string) ([]models.PendingEmail, error) {
+	query := fmt.Sprintf(`
+		SELECT id, tenant, did, sender, recipient, subject, html, attempts FROM %s
+		WHERE did = :did ORDER BY queued_at`, p.table(PendingEmailsTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to list emails")
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list emails: unexpected nil response")
+	}
+
+	emails := make([]models.PendingEmail, 0, len(result.Records))
+	for _, row := range result.Records {
+		if len(row) < 8 {
+			return nil, fmt.Errorf("failed to list emails: unexpected response")
+		}
+		columns := stringColumns(row, 8)
+		emails = append(emails, models.PendingEmail{
+			ID:        longValue(row[0]),
+			Tenant:    columns[1],
+			DID:       columns[2],
+			From:      columns[3],
+			Recipient: columns[4],
+			Subject:   columns[5],
+			HTML:      columns[6],
+			Attempts:  longValue(row[7]),
+		})
+	}
+	return emails, nil
+}
+
+func (p *PostgresDB) DeleteEmails(ctx context.Context, did string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE did = :did`, p.table(PendingEmailsTable))
+
+	if _, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("did", did)}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to delete emails")
+		return fmt.Errorf("failed to delete emails: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func stringField(value string) types.Field {
+	if value == "" {
+		return &types.FieldMemberIsNull{Value: true}
+	}
+	return &types.FieldMemberStringValue{Value: value}
+}
+
+func TestGetUser(t *testing.T) {
+	ctx := context.Background()
+	row := []types.Field{}
+	for _, value := range []string{
+		"did:example:123", "alice@example.com", "alice.shareframe.social", "Alice", "active", "true", "user",
+		"", "", "{}", "#000000", "#ffffff", "en-GB", "", "", "organization", "did:plc:alice,did:plc:bob", "seeded",
+		"Photographer", "she/her", "https://alice.example", "", `{"privacy":{"discoverable":true,"directMessages":"everyone"}}`,
+	} {
+		row = append(row, stringField(value))
+	}
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    models.UserRecord
+		expectedErr error
+	}{
+		{
+			name:       "Found",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{row}},
+			expected: models.UserRecord{
+				DID:            "did:example:123",
+				Email:          "alice@example.com",
+				Handle:         "alice.shareframe.social",
+				DisplayName:    "Alice",
+				Status:         "active",
+				Verified:       true,
+				Role:           "user",
+				Theme:          "{}",
+				PrimaryColor:   "#000000",
+				SecondaryColor: "#ffffff",
+				Locale:         "en-GB",
+				AccountType:    models.AccountTypeOrganization,
+				Owners:         []string{"did:plc:alice", "did:plc:bob"},
+				Onboarding:     models.OnboardingSeeded,
+				Bio:            "Photographer",
+				Pronouns:       "she/her",
+				Website:        "https://alice.example",
+				Preferences:    models.Preferences{Privacy: models.PrivacyPreferences{Discoverable: true, DirectMessages: models.DirectMessagesEveryone}},
+			},
+		},
+		{
+			name:        "Not Found",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: errors.New("failed to load user: DB connection failed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockError)
+
+			user, err := db.GetUser(ctx, "did:example:123")
+
+			switch {
+			case errors.Is(test.expectedErr, ErrUserNotFound):
+				assert.ErrorIs(t, err, ErrUserNotFound)
+			case test.expectedErr != nil:
+				assert.EqualError(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, user)
+			}
+		})
+	}
+}
+
+func TestListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "tenant_")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		return strings.Contains(*input.Sql, "FROM tenant_audit_events")
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			stringField(AuditAccountCreated),
+			stringField("did:example:123"),
+			stringField("alice.shareframe.social"),
+			stringField(AuditActorSelf),
+			stringField("req-123"),
+			stringField(`{"status": "active"}`),
+			stringField("2025-01-02 03:04:05+00"),
+		}},
+	}, nil)
+
+	events, err := db.ListAuditEvents(ctx, "did:example:123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{{
+		Event:      AuditAccountCreated,
+		DID:        "did:example:123",
+		Handle:     "alice.shareframe.social",
+		Actor:      AuditActorSelf,
+		RequestID:  "req-123",
+		Details:    map[string]string{"status": "active"},
+		OccurredAt: "2025-01-02 03:04:05+00",
+	}}, events)
+}
+
+func TestAnonymizeUser(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		expectedErr error
+	}{
+		{
+			name:       "Anonymized",
+			mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1},
+		},
+		{
+			name:        "Not Found",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 0},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				email, _ := sqlParam(input, "email").(*types.FieldMemberStringValue)
+				handle, _ := sqlParam(input, "handle").(*types.FieldMemberStringValue)
+				return email != nil && handle != nil &&
+					email.Value == handle.Value+"@erased.invalid" &&
+					!strings.Contains(handle.Value, "did:example:123") &&
+					strings.Contains(*input.Sql, "DELETE FROM "+PhoneCodesTable)
+			})).Return(test.mockOutput, nil)
+
+			err := db.AnonymizeUser(ctx, "did:example:123")
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestErasedPlaceholder(t *testing.T) {
+	assert.Equal(t, ErasedPlaceholder("did:example:123"), ErasedPlaceholder("did:example:123"))
+	assert.NotEqual(t, ErasedPlaceholder("did:example:123"), ErasedPlaceholder("did:example:456"))
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const SignupProgressTable = "signup_progress"
+
+// SignupProgressStore keeps how far each signup with an idempotency key
+// got, so a retry can resume it.
+type SignupProgressStore interface {
+	// SignupProgress returns the progress recorded under key, if any.
+	SignupProgress(ctx context.Context, key string) (models.SignupProgress, bool, error)
+	// SaveSignupProgress replaces the progress recorded under its key.
+	SaveSignupProgress(ctx context.Context, progress models.SignupProgress) error
+}
+
+func (p *PostgresDB) SignupProgress(ctx context.Context, key string) (models.SignupProgress, bool, error) {
+	query := fmt.Sprintf(`
+		SELECT handle, invite_code, did, steps::text FROM %s
+		WHERE idempotency_key = :idempotency_key`, p.table(SignupProgressTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("idempotency_key", key)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to load signup progress")
+		return models.SignupProgress{}, false, fmt.Errorf("failed to load signup progress: %w", err)
+	}
+
+	if result == nil {
+		return models.SignupProgress{}, false, fmt.Errorf("failed to load signup progress: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.SignupProgress{}, false, nil
+	}
+
+	columns := stringColumns(result.Records[0], 4)
+	progress := models.SignupProgress{
+		IdempotencyKey: key,
+		Handle:         columns[0],
+		InviteCode:     columns[1],
+		DID:            columns[2],
+	}
+	if err := json.Unmarshal([]byte(columns[3]), &progress.Steps); err != nil {
+		return models.SignupProgress{}, false, fmt.Errorf("failed to parse signup steps: %w", err)
+	}
+	return progress, true, nil
+}
+
+func (p *PostgresDB) SaveSignupProgress(ctx context.Context, progress models.SignupProgress) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (idempotency_key, handle, invite_code, did, steps, updated_at)
+		VALUES (:idempotency_key, :handle, :invite_code, :did, CAST(:steps AS JSONB), NOW())
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			handle = EXCLUDED.handle,
+			invite_code = EXCLUDED.invite_code,
+			did = EXCLUDED.did,
+			steps = EXCLUDED.steps,
+			updated_at = EXCLUDED.updated_at`, p.table(SignupProgressTable))
+
+	steps := progress.Steps
+	if steps == nil {
+		steps = []string{}
+	}
+	encoded, err := json.Marshal(steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode signup steps: %w", err)
+	}
+	params := []types.SqlParameter{
+		newSQLParam("idempotency_key", progress.IdempotencyKey),
+		newSQLParam("handle", progress.Handle),
+		nullableSQLParam("invite_code", progress.InviteCode),
+		nullableSQLParam("did", progress.DID),
+		newSQLParam("steps", string(encoded)),
+	}
+
+	if _, err := p.execute(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("steps", steps).Error("Failed to save signup progress")
+		return fmt.Errorf("failed to save signup progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSignupProgress(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "alice.shareframe.social"},
+			&types.FieldMemberStringValue{Value: "invite-123"},
+			&types.FieldMemberIsNull{Value: true},
+			&types.FieldMemberStringValue{Value: `["invite_created"]`},
+		}},
+	}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(nil, errors.New("DB connection failed")).Once()
+
+	progress, ok, err := db.SignupProgress(ctx, "key-1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, models.SignupProgress{
+		IdempotencyKey: "key-1",
+		Handle:         "alice.shareframe.social",
+		InviteCode:     "invite-123",
+		Steps:          []string{models.SignupStepInviteCreated},
+	}, progress)
+	assert.True(t, progress.Done(models.SignupStepInviteCreated))
+	assert.False(t, progress.Done(models.SignupStepPDSRegistered))
+
+	_, ok, err = db.SignupProgress(ctx, "key-2")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = db.SignupProgress(ctx, "key-3")
+	assert.EqualError(t, err, "failed to load signup progress: DB connection failed")
+}
+
+func TestSaveSignupProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		progress    models.SignupProgress
+		steps       string
+		mockError   error
+		expectedErr string
+	}{
+		{
+			name: "Registered",
+			progress: models.SignupProgress{
+				IdempotencyKey: "key-1",
+				Handle:         "alice.shareframe.social",
+				InviteCode:     "invite-123",
+				DID:            "did:plc:alice",
+				Steps:          []string{models.SignupStepInviteCreated, models.SignupStepPDSRegistered},
+			},
+			steps: `["invite_created","pds_registered"]`,
+		},
+		{
+			name:     "No Steps",
+			progress: models.SignupProgress{IdempotencyKey: "key-1", Handle: "alice.shareframe.social"},
+			steps:    `[]`,
+		},
+		{
+			name:        "Database Error",
+			progress:    models.SignupProgress{IdempotencyKey: "key-1"},
+			steps:       `[]`,
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to save signup progress: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				steps, _ := sqlParam(input, "steps").(*types.FieldMemberStringValue)
+				_, nullDID := sqlParam(input, "did").(*types.FieldMemberIsNull)
+				return steps != nil && steps.Value == test.steps && nullDID == (test.progress.DID == "")
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.SaveSignupProgress(context.Background(), test.progress)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertNumberOfCalls(t, "ExecuteStatement", 1)
+		})
+	}
+}
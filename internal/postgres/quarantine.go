@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const ReleasedHandlesTable = "released_handles"
+
+// HandleQuarantineStore keeps the handles of deleted accounts out of reach
+// for a while, so nobody can sign up as a user who has only just left.
+// Handles come back on their own once released_at passes.
+type HandleQuarantineStore interface {
+	// QuarantineHandle holds handle back for ttl from now. Quarantining
+	// a handle again starts the period over.
+	QuarantineHandle(ctx context.Context, handle, did string, ttl time.Duration) error
+	// QuarantinedHandle returns when handle is released, if it is still
+	// in quarantine.
+	QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error)
+}
+
+func (p *PostgresDB) QuarantineHandle(ctx context.Context, handle, did string, ttl time.Duration) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (handle, did, deleted_at, released_at)
+		VALUES (:handle, :did, NOW(), NOW() + CAST(:ttl AS INTERVAL))
+		ON CONFLICT (handle) DO UPDATE SET
+			did = EXCLUDED.did,
+			deleted_at = EXCLUDED.deleted_at,
+			released_at = EXCLUDED.released_at`, p.table(ReleasedHandlesTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("handle", handle),
+		newSQLParam("did", did),
+		newSQLParam("ttl", intervalParam(ttl)),
+	}
+	if _, err := p.execute(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"handle": handle,
+			"did":    did,
+		}).Error("Failed to quarantine handle")
+		return fmt.Errorf("failed to quarantine handle: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresDB) QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error) {
+	query := fmt.Sprintf(`
+		SELECT released_at::text FROM %s
+		WHERE handle = :handle AND released_at > NOW()`, p.table(ReleasedHandlesTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("handle", handle)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("handle", handle).Error("Failed to load handle quarantine")
+		return time.Time{}, false, fmt.Errorf("failed to load handle quarantine: %w", err)
+	}
+
+	if result == nil {
+		return time.Time{}, false, fmt.Errorf("failed to load handle quarantine: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	releasedAt, err := time.Parse(postgresTimestamp, stringColumns(result.Records[0], 1)[0])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse handle release time: %w", err)
+	}
+	return releasedAt.UTC(), true, nil
+}
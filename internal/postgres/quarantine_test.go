@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQuarantineHandle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Quarantined"},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to quarantine handle: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				handle, _ := sqlParam(input, "handle").(*types.FieldMemberStringValue)
+				did, _ := sqlParam(input, "did").(*types.FieldMemberStringValue)
+				return handle != nil && handle.Value == "alice.shareframe.social" && did != nil && did.Value == "did:plc:alice"
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.QuarantineHandle(ctx, "alice.shareframe.social", "did:plc:alice", 90*24*time.Hour)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQuarantinedHandle(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{&types.FieldMemberStringValue{Value: "2026-05-01 00:00:00+00"}}},
+	}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{}, nil).Once()
+
+	releasedAt, ok, err := db.QuarantinedHandle(ctx, "alice.shareframe.social")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), releasedAt)
+
+	_, ok, err = db.QuarantinedHandle(ctx, "bob.shareframe.social")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const (
+	ReferralCodesTable = "referral_codes"
+	ReferralsTable     = "referrals"
+)
+
+// ReferralStore keeps each account's referral code and the signups the
+// codes drove.
+type ReferralStore interface {
+	// IssueReferralCode stores code for did unless it already has one, and
+	// returns the code the account ends up with.
+	IssueReferralCode(ctx context.Context, did, code string) (string, error)
+	// ReferralCodeOwner returns ErrReferralCodeNotFound for unknown codes.
+	ReferralCodeOwner(ctx context.Context, code string) (models.ReferralCodeOwner, error)
+	RecordReferral(ctx context.Context, referral models.Referral) error
+	// RewardReferral marks the pending referral of referredDID rewarded and
+	// returns it, or reports false if there is none pending.
+	RewardReferral(ctx context.Context, referredDID string) (models.Referral, bool, error)
+	ReferralStats(ctx context.Context, code string) (models.ReferralStats, error)
+}
+
+// ErrReferralCodeNotFound is returned for a referral code nobody owns.
+var ErrReferralCodeNotFound = errors.New("referral code not found")
+
+func (p *PostgresDB) IssueReferralCode(ctx context.Context, did, code string) (string, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (code, did, created_at)
+		VALUES (:code, :did, NOW())
+		ON CONFLICT (did) DO UPDATE SET did = EXCLUDED.did
+		RETURNING code`, p.table(ReferralCodesTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("code", code),
+		newSQLParam("did", did),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to issue referral code")
+		return "", fmt.Errorf("failed to issue referral code: %w", err)
+	}
+
+	if result == nil || len(result.Records) == 0 {
+		return "", fmt.Errorf("failed to issue referral code: unexpected response")
+	}
+	return stringColumns(result.Records[0], 1)[0], nil
+}
+
+func (p *PostgresDB) ReferralCodeOwner(ctx context.Context, code string) (models.ReferralCodeOwner, error) {
+	query := fmt.Sprintf(`
+		SELECT c.did, u.normalized_email, u.status
+		FROM %s c JOIN %s u ON u.did = c.did
+		WHERE c.code = :code`, p.table(ReferralCodesTable), p.table(UsersTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("code", code)})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("code", code).Error("Failed to look up referral code")
+		return models.ReferralCodeOwner{}, fmt.Errorf("failed to look up referral code: %w", err)
+	}
+
+	if result == nil {
+		return models.ReferralCodeOwner{}, fmt.Errorf("failed to look up referral code: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.ReferralCodeOwner{}, ErrReferralCodeNotFound
+	}
+
+	columns := stringColumns(result.Records[0], 3)
+	return models.ReferralCodeOwner{
+		DID:             columns[0],
+		NormalizedEmail: columns[1],
+		Status:          columns[2],
+	}, nil
+}
+
+// RecordReferral keeps the first referral recorded for an account, so a
+// retried signup can't switch it to another code.
+func (p *PostgresDB) RecordReferral(ctx context.Context, referral models.Referral) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (referred_did, code, referrer_did, status, reason, created_at)
+		VALUES (:referred_did, :code, :referrer_did, :status, :reason, NOW())
+		ON CONFLICT (referred_did) DO NOTHING`, p.table(ReferralsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("referred_did", referral.ReferredDID),
+		newSQLParam("code", referral.Code),
+		newSQLParam("referrer_did", referral.ReferrerDID),
+		newSQLParam("status", referral.Status),
+		nullableSQLParam("reason", referral.Reason),
+	}
+
+	if _, err := p.execute(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logging.Fields{
+			"did":  referral.ReferredDID,
+			"code": referral.Code,
+		}).Error("Failed to record referral")
+		return fmt.Errorf("failed to record referral: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresDB) RewardReferral(ctx context.Context, referredDID string) (models.Referral, bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = :rewarded, rewarded_at = NOW()
+		WHERE referred_did = :referred_did AND status = :pending
+		RETURNING code, referrer_did, rewarded_at::text`, p.table(ReferralsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("referred_did", referredDID),
+		newSQLParam("rewarded", models.ReferralRewarded),
+		newSQLParam("pending", models.ReferralPending),
+	}
+
+	result, err := p.executeWrite(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", referredDID).Error("Failed to reward referral")
+		return models.Referral{}, false, fmt.Errorf("failed to reward referral: %w", err)
+	}
+
+	if result == nil {
+		return models.Referral{}, false, fmt.Errorf("failed to reward referral: unexpected nil response")
+	}
+	if len(result.Records) == 0 {
+		return models.Referral{}, false, nil
+	}
+
+	columns := stringColumns(result.Records[0], 3)
+	referral := models.Referral{
+		ReferredDID: referredDID,
+		Code:        columns[0],
+		ReferrerDID: columns[1],
+		Status:      models.ReferralRewarded,
+	}
+	if rewardedAt, err := time.Parse(postgresTimestamp, columns[2]); err == nil {
+		referral.RewardedAt = rewardedAt.UTC()
+	}
+	return referral, true, nil
+}
+
+func (p *PostgresDB) ReferralStats(ctx context.Context, code string) (models.ReferralStats, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = :pending),
+			COUNT(*) FILTER (WHERE status = :rewarded),
+			COUNT(*) FILTER (WHERE status = :rejected)
+		FROM %s WHERE code = :code`, p.table(ReferralsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("code", code),
+		newSQLParam("pending", models.ReferralPending),
+		newSQLParam("rewarded", models.ReferralRewarded),
+		newSQLParam("rejected", models.ReferralRejected),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("code", code).Error("Failed to load referral stats")
+		return models.ReferralStats{}, fmt.Errorf("failed to load referral stats: %w", err)
+	}
+
+	if result == nil || len(result.Records) == 0 || len(result.Records[0]) < 4 {
+		return models.ReferralStats{}, fmt.Errorf("failed to load referral stats: unexpected response")
+	}
+
+	row := result.Records[0]
+	return models.ReferralStats{
+		Code:     code,
+		Signups:  longValue(row[0]),
+		Pending:  longValue(row[1]),
+		Rewarded: longValue(row[2]),
+		Rejected: longValue(row[3]),
+	}, nil
+}
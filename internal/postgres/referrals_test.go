@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIssueReferralCode(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{&types.FieldMemberStringValue{Value: "OLDCODE2"}}},
+	}, nil)
+
+	code, err := db.IssueReferralCode(ctx, "did:example:123", "NEWCODE2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "OLDCODE2", code)
+}
+
+func TestReferralCodeOwner(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    models.ReferralCodeOwner
+		expectedErr string
+	}{
+		{
+			name: "Found",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{
+				&types.FieldMemberStringValue{Value: "did:example:123"},
+				&types.FieldMemberStringValue{Value: "alice@example.com"},
+				&types.FieldMemberStringValue{Value: "active"},
+			}}},
+			expected: models.ReferralCodeOwner{DID: "did:example:123", NormalizedEmail: "alice@example.com", Status: "active"},
+		},
+		{
+			name:        "Unknown Code",
+			mockOutput:  &rdsdata.ExecuteStatementOutput{},
+			expectedErr: ErrReferralCodeNotFound.Error(),
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to look up referral code: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockError)
+
+			owner, err := db.ReferralCodeOwner(ctx, "ABCD2345")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, owner)
+			}
+		})
+	}
+}
+
+func TestRecordReferral(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		_, noReason := sqlParam(input, "reason").(*types.FieldMemberIsNull)
+		status, _ := sqlParam(input, "status").(*types.FieldMemberStringValue)
+		return noReason && status != nil && status.Value == models.ReferralPending
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	err := db.RecordReferral(ctx, models.Referral{ReferredDID: "did:example:456", Code: "ABCD2345", ReferrerDID: "did:example:123", Status: models.ReferralPending})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRewardReferral(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    models.Referral
+		rewarded    bool
+		expectedErr string
+	}{
+		{
+			name: "Rewarded",
+			mockOutput: &rdsdata.ExecuteStatementOutput{Records: [][]types.Field{{
+				&types.FieldMemberStringValue{Value: "ABCD2345"},
+				&types.FieldMemberStringValue{Value: "did:example:123"},
+				&types.FieldMemberStringValue{Value: "2026-01-02 03:04:05+00"},
+			}}},
+			expected: models.Referral{
+				ReferredDID: "did:example:456",
+				Code:        "ABCD2345",
+				ReferrerDID: "did:example:123",
+				Status:      models.ReferralRewarded,
+				RewardedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			rewarded: true,
+		},
+		{
+			name:       "Nothing Pending",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to reward referral: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+			mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(test.mockOutput, test.mockError)
+
+			referral, rewarded, err := db.RewardReferral(ctx, "did:example:456")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.rewarded, rewarded)
+			assert.Equal(t, test.expected, referral)
+		})
+	}
+}
+
+func TestReferralStats(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberLongValue{Value: 5},
+			&types.FieldMemberLongValue{Value: 2},
+			&types.FieldMemberLongValue{Value: 2},
+			&types.FieldMemberLongValue{Value: 1},
+		}},
+	}, nil)
+
+	stats, err := db.ReferralStats(ctx, "ABCD2345")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReferralStats{Code: "ABCD2345", Signups: 5, Pending: 2, Rewarded: 2, Rejected: 1}, stats)
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/confusables"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// UserSchemaVersion is the version of the users row StoreUser writes. Rows
+// from before version 2 may lack normalized_email or handle_skeleton, or
+// have a verified flag that disagrees with their status.
+const UserSchemaVersion = 2
+
+// RepairStore finds and fixes users rows written before the current
+// schema version.
+type RepairStore interface {
+	// ListStaleUsers returns up to limit rows, ordered by DID and after the
+	// DID after, that are older than UserSchemaVersion or are missing a
+	// column it fills in.
+	ListStaleUsers(ctx context.Context, after string, limit int) ([]models.UserRecord, error)
+	// RepairUser recomputes the row's derived columns from its email and
+	// handle, sets verified and stamps it with UserSchemaVersion. It
+	// returns ErrStatusChanged if the row's status is no longer
+	// record.Status.
+	RepairUser(ctx context.Context, record models.UserRecord) error
+	// ExistingDIDs reports which of dids have a users row.
+	ExistingDIDs(ctx context.Context, dids []string) (map[string]bool, error)
+}
+
+func (p *PostgresDB) ListStaleUsers(ctx context.Context, after string, limit int) ([]models.UserRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT did, email, handle, status, verified::text FROM %s
+		WHERE did > :after AND (
+			schema_version IS NULL OR schema_version < :schema_version
+			OR normalized_email IS NULL OR handle_skeleton IS NULL
+			OR (status IN (:pending, :verified_status, :active) AND verified IS DISTINCT FROM (status IN (:verified_status, :active)))
+		)
+		ORDER BY did LIMIT :limit`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("after", after),
+		newSQLParam("schema_version", UserSchemaVersion),
+		newSQLParam("pending", models.StatusPending),
+		newSQLParam("verified_status", models.StatusVerified),
+		newSQLParam("active", models.StatusActive),
+		newSQLParam("limit", limit),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("after", after).Error("Failed to list stale users")
+		return nil, fmt.Errorf("failed to list stale users: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list stale users: unexpected nil response")
+	}
+
+	records := make([]models.UserRecord, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 5)
+		records = append(records, models.UserRecord{
+			DID:      columns[0],
+			Email:    columns[1],
+			Handle:   columns[2],
+			Status:   columns[3],
+			Verified: columns[4] == "true",
+		})
+	}
+	return records, nil
+}
+
+func (p *PostgresDB) RepairUser(ctx context.Context, record models.UserRecord) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET normalized_email = :normalized_email, handle_skeleton = :handle_skeleton,
+		verified = :verified, schema_version = :schema_version, modified_at = NOW()
+		WHERE did = :did AND status = :status`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("did", record.DID),
+		newSQLParam("status", record.Status),
+		newSQLParam("normalized_email", NormalizeEmail(record.Email)),
+		newSQLParam("handle_skeleton", confusables.Skeleton(record.Handle)),
+		newSQLParam("verified", record.Verified),
+		newSQLParam("schema_version", UserSchemaVersion),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", record.DID).Error("Failed to repair user")
+		return fmt.Errorf("failed to repair user: %w", err)
+	}
+
+	if result == nil {
+		return fmt.Errorf("failed to repair user: unexpected nil response")
+	}
+	if result.NumberOfRecordsUpdated == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+func (p *PostgresDB) ExistingDIDs(ctx context.Context, dids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(dids))
+	if len(dids) == 0 {
+		return existing, nil
+	}
+
+	query := fmt.Sprintf(`SELECT did FROM %s WHERE did = ANY(string_to_array(:dids, ','))`, p.table(UsersTable))
+
+	result, err := p.execute(ctx, query, []types.SqlParameter{newSQLParam("dids", strings.Join(dids, ","))})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to look up DIDs")
+		return nil, fmt.Errorf("failed to look up DIDs: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to look up DIDs: unexpected nil response")
+	}
+
+	for _, row := range result.Records {
+		existing[stringColumns(row, 1)[0]] = true
+	}
+	return existing, nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStoreUserStampsSchemaVersion(t *testing.T) {
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		version, ok := sqlParam(input, "schema_version").(*types.FieldMemberLongValue)
+		return ok && version.Value == UserSchemaVersion
+	})).Return(&rdsdata.ExecuteStatementOutput{}, nil)
+
+	assert.NoError(t, db.StoreUser(context.Background(), models.UserRecord{DID: "did:plc:alice", Email: "alice@example.com", Handle: "alice"}))
+}
+
+func TestListStaleUsers(t *testing.T) {
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		after, _ := sqlParam(input, "after").(*types.FieldMemberStringValue)
+		limit, _ := sqlParam(input, "limit").(*types.FieldMemberLongValue)
+		return after != nil && after.Value == "did:plc:a" && limit != nil && limit.Value == 50
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{
+			&types.FieldMemberStringValue{Value: "did:plc:b"},
+			&types.FieldMemberStringValue{Value: "bob@example.com"},
+			&types.FieldMemberStringValue{Value: "bob.shareframe.social"},
+			&types.FieldMemberStringValue{Value: models.StatusVerified},
+			&types.FieldMemberIsNull{Value: true},
+		}},
+	}, nil)
+
+	records, err := db.ListStaleUsers(context.Background(), "did:plc:a", 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UserRecord{{
+		DID:    "did:plc:b",
+		Email:  "bob@example.com",
+		Handle: "bob.shareframe.social",
+		Status: models.StatusVerified,
+	}}, records)
+}
+
+func TestRepairUser(t *testing.T) {
+	ctx := context.Background()
+	record := models.UserRecord{DID: "did:plc:b", Email: "B.o.b+x@gmail.com", Handle: "bob.shareframe.social", Status: models.StatusVerified, Verified: true}
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expectedErr error
+	}{
+		{name: "Repaired", mockOutput: &rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1}},
+		{name: "Status Changed", mockOutput: &rdsdata.ExecuteStatementOutput{}, expectedErr: ErrStatusChanged},
+		{name: "Database Error", mockError: errors.New("db down"), expectedErr: errors.New("db down")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				email, _ := sqlParam(input, "normalized_email").(*types.FieldMemberStringValue)
+				verified, _ := sqlParam(input, "verified").(*types.FieldMemberBooleanValue)
+				return email != nil && email.Value == "bob@gmail.com" && verified != nil && verified.Value
+			})).Return(test.mockOutput, test.mockError)
+
+			err := db.RepairUser(ctx, record)
+
+			switch {
+			case errors.Is(test.expectedErr, ErrStatusChanged):
+				assert.ErrorIs(t, err, ErrStatusChanged)
+			case test.expectedErr != nil:
+				assert.ErrorContains(t, err, test.expectedErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExistingDIDs(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	existing, err := db.ExistingDIDs(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, existing)
+	mockClient.AssertNotCalled(t, "ExecuteStatement", mock.Anything, mock.Anything)
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		dids, _ := sqlParam(input, "dids").(*types.FieldMemberStringValue)
+		return dids != nil && dids.Value == "did:plc:a,did:plc:b"
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{{&types.FieldMemberStringValue{Value: "did:plc:b"}}},
+	}, nil)
+
+	existing, err = db.ExistingDIDs(ctx, []string{"did:plc:a", "did:plc:b"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"did:plc:b": true}, existing)
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const ManualReviewTable = "signup_manual_review"
+
+const defaultReviewQueueLimit = 50
+
+// ManualReviewStore holds failed signups that re-driving can't fix.
+type ManualReviewStore interface {
+	FileForReview(ctx context.Context, signup models.FailedSignup) error
+	// DeleteFiledSignups deletes the filed signups made with email or for
+	// handle, when their account is erased.
+	DeleteFiledSignups(ctx context.Context, handle, email string) error
+}
+
+// FileForReview records signup for manual review. A message filed twice,
+// as when SQS redelivers it after the first attempt, is stored once.
+func (p *PostgresDB) FileForReview(ctx context.Context, signup models.FailedSignup) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (message_id, tenant, handle, email, reason, error, attempts, filed_at)
+		VALUES (:message_id, :tenant, :handle, :email, :reason, :error, :attempts, NOW())
+		ON CONFLICT (message_id) DO NOTHING`,
+		p.table(ManualReviewTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("message_id", signup.MessageID),
+		nullableSQLParam("tenant", signup.Tenant),
+		newSQLParam("handle", signup.Handle),
+		newSQLParam("email", signup.Email),
+		newSQLParam("reason", signup.Reason),
+		newSQLParam("error", signup.Error),
+		newSQLParam("attempts", signup.Attempts),
+	}
+
+	if _, err := p.execute(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("message_id", signup.MessageID).Error("Failed to file signup for manual review")
+		return fmt.Errorf("failed to file signup for review: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFiledSignups matches email and handle case-insensitively, as they
+// were filed straight from the request.
+func (p *PostgresDB) DeleteFiledSignups(ctx context.Context, handle, email string) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s WHERE LOWER(email) = LOWER(:email) OR LOWER(handle) = LOWER(:handle)`,
+		p.table(ManualReviewTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("handle", handle),
+		newSQLParam("email", email),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to delete filed signups")
+		return fmt.Errorf("failed to delete filed signups: %w", err)
+	}
+	return nil
+}
+
+// ReviewQueueStore reads the accounts held for review and releases the
+// ones a moderator rejected.
+type ReviewQueueStore interface {
+	ListReviewQueue(ctx context.Context, limit int) ([]models.ReviewItem, error)
+	// ReleaseUser anonymizes a rejected account like an erasure, so its
+	// handle and email address can be registered again, and leaves it
+	// rejected.
+	ReleaseUser(ctx context.Context, did string) error
+}
+
+// ListReviewQueue returns the accounts pending review, oldest first. A
+// limit of 0 or less returns the first 50.
+func (p *PostgresDB) ListReviewQueue(ctx context.Context, limit int) ([]models.ReviewItem, error) {
+	if limit <= 0 {
+		limit = defaultReviewQueueLimit
+	}
+
+	query := fmt.Sprintf(`
+		SELECT did, handle, email, display_name, COALESCE(review_flags, ''), created_at::text FROM %s
+		WHERE status = :status ORDER BY created_at LIMIT :limit`, p.table(UsersTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("status", models.StatusPendingReview),
+		newSQLParam("limit", limit),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to list review queue")
+		return nil, fmt.Errorf("failed to list review queue: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list review queue: unexpected nil response")
+	}
+
+	queue := make([]models.ReviewItem, 0, len(result.Records))
+	for _, row := range result.Records {
+		columns := stringColumns(row, 6)
+		item := models.ReviewItem{
+			DID:         columns[0],
+			Handle:      columns[1],
+			Email:       columns[2],
+			DisplayName: columns[3],
+			CreatedAt:   columns[5],
+		}
+		if columns[4] != "" {
+			item.ReviewFlags = strings.Split(columns[4], ",")
+		}
+		queue = append(queue, item)
+	}
+	return queue, nil
+}
+
+func (p *PostgresDB) ReleaseUser(ctx context.Context, did string) error {
+	return p.anonymizeUser(ctx, did, models.StatusRejected)
+}
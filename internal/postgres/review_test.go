@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFileForReview(t *testing.T) {
+	ctx := context.Background()
+	signup := models.FailedSignup{
+		MessageID: "msg-1",
+		Handle:    "alice",
+		Email:     "alice@example.com",
+		Reason:    "handle_taken",
+		Error:     "user already exists with handle: alice",
+		Attempts:  2,
+	}
+
+	tests := []struct {
+		name        string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Filed"},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to file signup for review: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				attempts, _ := sqlParam(input, "attempts").(*types.FieldMemberLongValue)
+				_, tenantNull := sqlParam(input, "tenant").(*types.FieldMemberIsNull)
+				return attempts != nil && attempts.Value == 2 && tenantNull
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.FileForReview(ctx, signup)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteFiledSignups(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		mockError   error
+		expectedErr string
+	}{
+		{name: "Deleted"},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to delete filed signups: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				handle, _ := sqlParam(input, "handle").(*types.FieldMemberStringValue)
+				email, _ := sqlParam(input, "email").(*types.FieldMemberStringValue)
+				return strings.Contains(*input.Sql, "DELETE FROM "+ManualReviewTable) &&
+					handle != nil && handle.Value == "alice.shareframe.social" &&
+					email != nil && email.Value == "alice@example.com"
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.DeleteFiledSignups(ctx, "alice.shareframe.social", "alice@example.com")
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		status, _ := sqlParam(input, "status").(*types.FieldMemberStringValue)
+		limit, _ := sqlParam(input, "limit").(*types.FieldMemberLongValue)
+		return status != nil && status.Value == models.StatusPendingReview && limit != nil && limit.Value == defaultReviewQueueLimit
+	})).Return(&rdsdata.ExecuteStatementOutput{
+		Records: [][]types.Field{
+			{
+				&types.FieldMemberStringValue{Value: "did:plc:alice"},
+				&types.FieldMemberStringValue{Value: "alice.shareframe.social"},
+				&types.FieldMemberStringValue{Value: "alice@example.com"},
+				&types.FieldMemberStringValue{Value: "Alice"},
+				&types.FieldMemberStringValue{Value: "signup_risk,profanity"},
+				&types.FieldMemberStringValue{Value: "2026-02-01 12:00:00+00"},
+			},
+			{
+				&types.FieldMemberStringValue{Value: "did:plc:bob"},
+				&types.FieldMemberStringValue{Value: "bob.shareframe.social"},
+				&types.FieldMemberStringValue{Value: "bob@example.com"},
+				&types.FieldMemberIsNull{Value: true},
+				&types.FieldMemberStringValue{Value: ""},
+				&types.FieldMemberStringValue{Value: "2026-02-01 13:00:00+00"},
+			},
+		},
+	}, nil)
+
+	queue, err := db.ListReviewQueue(ctx, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ReviewItem{
+		{DID: "did:plc:alice", Handle: "alice.shareframe.social", Email: "alice@example.com", DisplayName: "Alice", ReviewFlags: []string{"signup_risk", "profanity"}, CreatedAt: "2026-02-01 12:00:00+00"},
+		{DID: "did:plc:bob", Handle: "bob.shareframe.social", Email: "bob@example.com", CreatedAt: "2026-02-01 13:00:00+00"},
+	}, queue)
+}
+
+func TestReleaseUser(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRDSClient)
+	db := NewPostgresDB(mockClient, testConfig, "")
+
+	mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+		status, _ := sqlParam(input, "status").(*types.FieldMemberStringValue)
+		handle, _ := sqlParam(input, "handle").(*types.FieldMemberStringValue)
+		return status != nil && status.Value == models.StatusRejected && handle != nil && handle.Value == ErasedPlaceholder("did:plc:alice")
+	})).Return(&rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: 1}, nil).Once()
+	mockClient.On("ExecuteStatement", mock.Anything, mock.Anything).Return(&rdsdata.ExecuteStatementOutput{}, nil).Once()
+
+	assert.NoError(t, db.ReleaseUser(ctx, "did:plc:alice"))
+	assert.ErrorIs(t, db.ReleaseUser(ctx, "did:plc:nobody"), ErrUserNotFound)
+}
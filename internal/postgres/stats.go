@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const SignupStatsTable = "signup_stats"
+
+// statsTimestamp is how a period start, a UTC TIMESTAMP, reads when cast to
+// text.
+const statsTimestamp = "2006-01-02 15:04:05"
+
+// SignupStatsStore rolls the accounts and signup attempts up into the
+// signup stats and reads them back.
+type SignupStatsStore interface {
+	// AggregateSignupStats recomputes the stats of the periods from the
+	// one from falls in to the one to falls in, and returns how many of
+	// them had anything to count.
+	AggregateSignupStats(ctx context.Context, period string, from, to time.Time) (int, error)
+	// ListSignupStats returns the stats of the periods that start at or
+	// after since, oldest first.
+	ListSignupStats(ctx context.Context, period string, since time.Time) ([]models.SignupStats, error)
+}
+
+// StatsPeriodLength is the length of period, or 0 for an unknown period.
+func StatsPeriodLength(period string) time.Duration {
+	switch period {
+	case models.StatsPeriodHour:
+		return time.Hour
+	case models.StatsPeriodDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// AggregateSignupStats rewrites each period's row from scratch, so running
+// it again over the same periods, or over one a late verification changed,
+// is safe.
+func (p *PostgresDB) AggregateSignupStats(ctx context.Context, period string, from, to time.Time) (int, error) {
+	length := StatsPeriodLength(period)
+	if length == 0 {
+		return 0, fmt.Errorf("failed to aggregate signup stats: unknown period %q", period)
+	}
+	// Whole periods only, or a partial count would overwrite a full one.
+	from = from.UTC().Truncate(length)
+	to = to.UTC().Truncate(length).Add(length)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (period, period_start, signups, verified, failed_attempts, failure_reasons, updated_at)
+		SELECT :period, period_start, COALESCE(signups.total, 0), COALESCE(signups.verified, 0),
+			COALESCE(failures.total, 0), COALESCE(failures.reasons, '{}'::jsonb), NOW()
+		FROM (
+			SELECT date_trunc(:period, created_at AT TIME ZONE 'UTC') AS period_start,
+				COUNT(*) AS total, COUNT(*) FILTER (WHERE verified) AS verified
+			FROM %s
+			WHERE created_at >= CAST(:from AS TIMESTAMPTZ) AND created_at < CAST(:to AS TIMESTAMPTZ)
+			GROUP BY 1
+		) signups
+		FULL JOIN (
+			SELECT period_start, SUM(attempts) AS total, jsonb_object_agg(reason, attempts) AS reasons
+			FROM (
+				SELECT date_trunc(:period, attempted_at AT TIME ZONE 'UTC') AS period_start,
+					failure_reason AS reason, COUNT(*) AS attempts
+				FROM %s
+				WHERE NOT succeeded AND failure_reason IS NOT NULL
+					AND attempted_at >= CAST(:from AS TIMESTAMPTZ) AND attempted_at < CAST(:to AS TIMESTAMPTZ)
+				GROUP BY 1, 2
+			) reasons
+			GROUP BY period_start
+		) failures USING (period_start)
+		ON CONFLICT (period, period_start) DO UPDATE SET
+			signups = EXCLUDED.signups,
+			verified = EXCLUDED.verified,
+			failed_attempts = EXCLUDED.failed_attempts,
+			failure_reasons = EXCLUDED.failure_reasons,
+			updated_at = EXCLUDED.updated_at`,
+		p.table(SignupStatsTable), p.table(UsersTable), p.table(SignupAttemptsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("period", period),
+		newSQLParam("from", from.Format(time.RFC3339Nano)),
+		newSQLParam("to", to.Format(time.RFC3339Nano)),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("period", period).Error("Failed to aggregate signup stats")
+		return 0, fmt.Errorf("failed to aggregate signup stats: %w", err)
+	}
+
+	if result == nil {
+		return 0, nil
+	}
+	return int(result.NumberOfRecordsUpdated), nil
+}
+
+func (p *PostgresDB) ListSignupStats(ctx context.Context, period string, since time.Time) ([]models.SignupStats, error) {
+	query := fmt.Sprintf(`
+		SELECT period_start::text, signups, verified, failed_attempts, failure_reasons::text FROM %s
+		WHERE period = :period AND period_start >= CAST(:since AS TIMESTAMP)
+		ORDER BY period_start`, p.table(SignupStatsTable))
+
+	params := []types.SqlParameter{
+		newSQLParam("period", period),
+		newSQLParam("since", since.UTC().Format(statsTimestamp)),
+	}
+
+	result, err := p.execute(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("period", period).Error("Failed to list signup stats")
+		return nil, fmt.Errorf("failed to list signup stats: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to list signup stats: unexpected nil response")
+	}
+
+	stats := make([]models.SignupStats, 0, len(result.Records))
+	for _, row := range result.Records {
+		if len(row) < 5 {
+			return nil, fmt.Errorf("failed to list signup stats: unexpected response")
+		}
+		columns := stringColumns(row, 5)
+		start, err := time.Parse(statsTimestamp, columns[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signup stats period %q: %w", columns[0], err)
+		}
+		entry := models.SignupStats{
+			Period:         period,
+			Start:          start,
+			Signups:        longValue(row[1]),
+			Verified:       longValue(row[2]),
+			FailedAttempts: longValue(row[3]),
+		}
+		if entry.Signups > 0 {
+			entry.VerificationRate = float64(entry.Verified) / float64(entry.Signups)
+		}
+		if columns[4] != "" {
+			if err := json.Unmarshal([]byte(columns[4]), &entry.FailureReasons); err != nil {
+				return nil, fmt.Errorf("failed to parse signup failure reasons: %w", err)
+			}
+		}
+		stats = append(stats, entry)
+	}
+	return stats, nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAggregateSignupStats(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 16, 11, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		period       string
+		mockError    error
+		expectedFrom string
+		expectedTo   string
+		expected     int
+		expectedErr  string
+	}{
+		{name: "Hourly", period: models.StatsPeriodHour, expectedFrom: "2026-10-16T09:00:00Z", expectedTo: "2026-10-16T12:00:00Z", expected: 3},
+		{name: "Daily", period: models.StatsPeriodDay, expectedFrom: "2026-10-16T00:00:00Z", expectedTo: "2026-10-17T00:00:00Z", expected: 1},
+		{name: "Unknown Period", period: "week", expectedErr: `unknown period "week"`},
+		{name: "Database Error", period: models.StatsPeriodHour, expectedFrom: "2026-10-16T09:00:00Z", expectedTo: "2026-10-16T12:00:00Z", mockError: errors.New("DB connection failed"), expectedErr: "failed to aggregate signup stats: DB connection failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			if test.expectedFrom != "" {
+				mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+					from, _ := sqlParam(input, "from").(*types.FieldMemberStringValue)
+					to, _ := sqlParam(input, "to").(*types.FieldMemberStringValue)
+					return from != nil && from.Value == test.expectedFrom && to != nil && to.Value == test.expectedTo
+				})).Return(&rdsdata.ExecuteStatementOutput{NumberOfRecordsUpdated: int64(test.expected)}, test.mockError)
+			}
+
+			periods, err := db.AggregateSignupStats(ctx, test.period, from, to)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, periods)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListSignupStats(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mockOutput  *rdsdata.ExecuteStatementOutput
+		mockError   error
+		expected    []models.SignupStats
+		expectedErr string
+	}{
+		{
+			name: "Stats Found",
+			mockOutput: &rdsdata.ExecuteStatementOutput{
+				Records: [][]types.Field{
+					{
+						&types.FieldMemberStringValue{Value: "2026-10-15 00:00:00"},
+						&types.FieldMemberLongValue{Value: 40},
+						&types.FieldMemberLongValue{Value: 30},
+						&types.FieldMemberLongValue{Value: 5},
+						&types.FieldMemberStringValue{Value: `{"handle_taken": 4, "internal_error": 1}`},
+					},
+					{
+						&types.FieldMemberStringValue{Value: "2026-10-16 00:00:00"},
+						&types.FieldMemberLongValue{Value: 0},
+						&types.FieldMemberLongValue{Value: 0},
+						&types.FieldMemberLongValue{Value: 0},
+						&types.FieldMemberStringValue{Value: `{}`},
+					},
+				},
+			},
+			expected: []models.SignupStats{
+				{
+					Period:           models.StatsPeriodDay,
+					Start:            since,
+					Signups:          40,
+					Verified:         30,
+					VerificationRate: 0.75,
+					FailedAttempts:   5,
+					FailureReasons:   map[string]int{"handle_taken": 4, "internal_error": 1},
+				},
+				{
+					Period:         models.StatsPeriodDay,
+					Start:          since.Add(24 * time.Hour),
+					FailureReasons: map[string]int{},
+				},
+			},
+		},
+		{
+			name:       "No Stats",
+			mockOutput: &rdsdata.ExecuteStatementOutput{},
+			expected:   []models.SignupStats{},
+		},
+		{
+			name:        "Database Error",
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to list signup stats: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				since, _ := sqlParam(input, "since").(*types.FieldMemberStringValue)
+				return since != nil && since.Value == "2026-10-15 00:00:00"
+			})).Return(test.mockOutput, test.mockError)
+
+			stats, err := db.ListSignupStats(ctx, models.StatsPeriodDay, since)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, stats)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
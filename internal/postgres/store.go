@@ -0,0 +1,39 @@
+package postgres
+
+import "context"
+
+// Store is everything the service keeps in the database for one set of
+// tables, a tenant's or the shared ones. The handlers only use a Store, so
+// another backend can stand in for Postgres.
+type Store interface {
+	PostgresDBService
+	UserFinder
+	SignupAttemptStore
+	AuditStore
+	AvatarStore
+	BillingStore
+	BlocklistStore
+	HandleClaimStore
+	ConsentStore
+	CRMContactStore
+	PendingEmailStore
+	VerificationEmailTracker
+	AccountStatusStore
+	OnboardingStore
+	EventOutbox
+	PhoneStore
+	PrivacyStore
+	EmailDataStore
+	SignupProgressStore
+	HandleQuarantineStore
+	ReferralStore
+	RepairStore
+	ManualReviewStore
+	ReviewQueueStore
+	SignupStatsStore
+	WebhookDeliveryLog
+
+	InviteCodeAvailable(ctx context.Context, code string) (bool, error)
+}
+
+var _ Store = (*PostgresDB)(nil)
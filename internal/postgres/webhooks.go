@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+const WebhookDeliveriesTable = "webhook_deliveries"
+
+// WebhookDeliveryLog keeps a per-endpoint record of every webhook delivery,
+// so partners' missed events can be traced and replayed.
+type WebhookDeliveryLog interface {
+	RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+func (p *PostgresDB) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (endpoint, event, did, attempts, status_code, delivered, error, request_id, delivered_at)
+		VALUES (:endpoint, :event, :did, :attempts, :status_code, :delivered, :error, :request_id, NOW())`,
+		p.table(WebhookDeliveriesTable))
+
+	// No status code means the request never got a response.
+	statusCode := nullableSQLParam("status_code", "")
+	if delivery.StatusCode != 0 {
+		statusCode = newSQLParam("status_code", delivery.StatusCode)
+	}
+
+	params := []types.SqlParameter{
+		newSQLParam("endpoint", delivery.Endpoint),
+		newSQLParam("event", delivery.Event),
+		newSQLParam("did", delivery.DID),
+		newSQLParam("attempts", delivery.Attempts),
+		statusCode,
+		newSQLParam("delivered", delivery.Delivered),
+		nullableSQLParam("error", delivery.Error),
+		nullableSQLParam("request_id", logging.RequestID(ctx)),
+	}
+
+	if _, err := p.executeWrite(ctx, query, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("endpoint", delivery.Endpoint).Error("Failed to record webhook delivery")
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		delivery       models.WebhookDelivery
+		mockError      error
+		wantStatusNull bool
+		expectedErr    string
+	}{
+		{
+			name:     "Delivered",
+			delivery: models.WebhookDelivery{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 1, StatusCode: 204, Delivered: true},
+		},
+		{
+			name:           "No Response",
+			delivery:       models.WebhookDelivery{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 3, Error: "connection refused"},
+			wantStatusNull: true,
+		},
+		{
+			name:        "Database Error",
+			delivery:    models.WebhookDelivery{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 1},
+			mockError:   errors.New("DB connection failed"),
+			expectedErr: "failed to record webhook delivery: DB connection failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockClient := new(mockRDSClient)
+			db := NewPostgresDB(mockClient, testConfig, "")
+
+			mockClient.On("ExecuteStatement", mock.Anything, mock.MatchedBy(func(input *rdsdata.ExecuteStatementInput) bool {
+				_, statusNull := sqlParam(input, "status_code").(*types.FieldMemberIsNull)
+				return statusNull == test.wantStatusNull || test.mockError != nil
+			})).Return(&rdsdata.ExecuteStatementOutput{}, test.mockError)
+
+			err := db.RecordWebhookDelivery(ctx, test.delivery)
+
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/outbox"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/token"
+)
+
+// ErrConfirmation is returned when an erasure is confirmed with a token that
+// is missing, expired or was issued for another account.
+var ErrConfirmation = errors.New("invalid erasure confirmation")
+
+type erasureClaims struct {
+	token.Registered
+	Tenant string `json:"tenant"`
+}
+
+// Eraser deletes an account in two steps. Request issues a short-lived
+// confirmation token for the account holder; Confirm checks it, deletes the
+// account from the PDS, its CRM contact and Stripe customer and any signup
+// filed for manual review with its email address or handle, and then
+// anonymizes what we store, phone codes included. Every step can be
+// repeated, so a confirmation that failed part way is simply retried.
+//
+// The audit trail is kept: it is append-only and retained as the record
+// that the erasure happened. So is the Stripe customer ID, which names a
+// deleted customer by then. With Quarantine set, the account's handle is
+// held back for QuarantineTTL, so nobody can take it over straight away.
+type Eraser struct {
+	Accounts postgres.PrivacyStore
+	Emails   postgres.EmailDataStore
+	Audit    postgres.AuditStore
+	Reviews  postgres.ManualReviewStore
+	Billing  postgres.BillingStore
+	// DeletePDSAccount removes the account from the tenant's PDS.
+	DeletePDSAccount func(ctx context.Context, did string) error
+	// DeleteCRMContact and DeleteStripeCustomer remove the account from
+	// the CRM and from Stripe. Each is nil when that integration is off.
+	DeleteCRMContact     func(ctx context.Context, email string) error
+	DeleteStripeCustomer func(ctx context.Context, customerID string) error
+	Publishers           []outbox.Publisher
+	Quarantine           postgres.HandleQuarantineStore
+	QuarantineTTL        time.Duration
+	Key                  token.Key
+	ConfirmationTTL      time.Duration
+	now                  func() time.Time
+}
+
+// Request returns the token that confirms erasing did.
+func (e *Eraser) Request(ctx context.Context, tenant, did, actor string) (models.PrivacyResponse, error) {
+	user, err := e.user(ctx, did)
+	if err != nil {
+		return models.PrivacyResponse{}, err
+	}
+
+	now := e.clock()
+	confirmation, err := token.Encode(ctx, e.Key, erasureClaims{
+		Registered: token.NewRegistered(token.PurposeAccountDeletion, did, now, e.ConfirmationTTL),
+		Tenant:     tenant,
+	})
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to sign erasure confirmation: %w", err)
+	}
+
+	if err := e.Audit.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:  postgres.AuditErasureRequested,
+		DID:    did,
+		Handle: user.Handle,
+		Actor:  actor,
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Erasure requested without an audit event")
+	}
+
+	return models.PrivacyResponse{Confirmation: confirmation, ExpiresAt: now.Add(e.ConfirmationTTL)}, nil
+}
+
+// Confirm erases did if confirmation is a valid token from Request for the
+// same account and tenant.
+func (e *Eraser) Confirm(ctx context.Context, tenant, did, actor, confirmation string) (models.PrivacyResponse, error) {
+	var claims erasureClaims
+	if err := token.Decode(ctx, e.Key, confirmation, token.PurposeAccountDeletion, e.clock(), &claims); err != nil {
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.Validation, "validation error: %w: %w", ErrConfirmation, err)
+	}
+	if claims.Subject != did || claims.Tenant != tenant {
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.Validation, "validation error: %w: issued for another account", ErrConfirmation)
+	}
+
+	user, err := e.user(ctx, did)
+	if err != nil {
+		return models.PrivacyResponse{}, err
+	}
+
+	// The handle goes into quarantine before the PDS frees it. A retry
+	// after the row was anonymized no longer knows the handle, but it was
+	// quarantined the first time.
+	if e.Quarantine != nil && user.Status != models.StatusErased {
+		if err := e.Quarantine.QuarantineHandle(ctx, user.Handle, did, e.QuarantineTTL); err != nil {
+			return models.PrivacyResponse{}, fmt.Errorf("failed to erase user data: %w", err)
+		}
+	}
+	if err := e.DeletePDSAccount(ctx, did); err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to delete account from PDS: %w", err)
+	}
+	// Like the handle, the email address is only known until the row is
+	// anonymized, so what is found by it goes first.
+	if user.Status != models.StatusErased {
+		if err := e.eraseElsewhere(ctx, user); err != nil {
+			return models.PrivacyResponse{}, err
+		}
+	}
+	if err := e.Accounts.AnonymizeUser(ctx, did); err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to erase user data: %w", err)
+	}
+	if err := e.Emails.DeleteEmails(ctx, did); err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to erase user data: %w", err)
+	}
+
+	// The data is gone by now, so the audit entry and the event are logged
+	// on failure rather than failing the erasure.
+	if err := e.Audit.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:   postgres.AuditAccountDeleted,
+		DID:     did,
+		Actor:   actor,
+		Details: map[string]string{"reason": "erasure_request"},
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Account erased without an audit event")
+	}
+	for _, publisher := range e.Publishers {
+		if err := publisher.Publish(ctx, models.NewAccountEvent(models.EventAccountDeleted, did, user.Handle, tenant)); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("did", did).Warn("Account event not published")
+		}
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Erased user data")
+	return models.PrivacyResponse{Erased: true}, nil
+}
+
+// eraseElsewhere deletes the copies of the account kept outside its row:
+// filed signups, the CRM contact and the Stripe customer.
+func (e *Eraser) eraseElsewhere(ctx context.Context, user models.UserRecord) error {
+	if err := e.Reviews.DeleteFiledSignups(ctx, user.Handle, user.Email); err != nil {
+		return fmt.Errorf("failed to erase user data: %w", err)
+	}
+	if e.DeleteCRMContact != nil {
+		if err := e.DeleteCRMContact(ctx, user.Email); err != nil {
+			return fmt.Errorf("failed to delete CRM contact: %w", err)
+		}
+	}
+	if e.DeleteStripeCustomer != nil {
+		customerID, err := e.Billing.StripeCustomerID(ctx, user.DID)
+		if err != nil {
+			return fmt.Errorf("failed to erase user data: %w", err)
+		}
+		if customerID != "" {
+			if err := e.DeleteStripeCustomer(ctx, customerID); err != nil {
+				return fmt.Errorf("failed to delete Stripe customer: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Eraser) user(ctx context.Context, did string) (models.UserRecord, error) {
+	user, err := e.Accounts.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return models.UserRecord{}, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return models.UserRecord{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	return user, nil
+}
+
+func (e *Eraser) clock() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now().UTC()
+}
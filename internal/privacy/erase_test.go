@@ -0,0 +1,306 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/outbox"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event models.AccountEvent) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+type mockReviews struct {
+	mock.Mock
+}
+
+func (m *mockReviews) FileForReview(ctx context.Context, signup models.FailedSignup) error {
+	return m.Called(ctx, signup).Error(0)
+}
+
+func (m *mockReviews) DeleteFiledSignups(ctx context.Context, handle, email string) error {
+	return m.Called(ctx, handle, email).Error(0)
+}
+
+type mockBilling struct {
+	mock.Mock
+}
+
+func (m *mockBilling) SetStripeCustomerID(ctx context.Context, did, customerID string) error {
+	return m.Called(ctx, did, customerID).Error(0)
+}
+
+func (m *mockBilling) StripeCustomerID(ctx context.Context, did string) (string, error) {
+	args := m.Called(ctx, did)
+	return args.String(0), args.Error(1)
+}
+
+func newEraser(now time.Time) (*Eraser, *mockAccounts, *mockEmails, *mockAudit, *[]string) {
+	accounts := new(mockAccounts)
+	emails := new(mockEmails)
+	audit := new(mockAudit)
+	reviews := new(mockReviews)
+	reviews.On("DeleteFiledSignups", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	var deleted []string
+	return &Eraser{
+		Accounts: accounts,
+		Emails:   emails,
+		Audit:    audit,
+		Reviews:  reviews,
+		DeletePDSAccount: func(ctx context.Context, did string) error {
+			deleted = append(deleted, did)
+			return nil
+		},
+		Key:             token.NewHMAC("2026-10", []byte("secret")),
+		ConfirmationTTL: time.Hour,
+		now:             func() time.Time { return now },
+	}, accounts, emails, audit, &deleted
+}
+
+func TestEraseRequestAndConfirm(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	eraser, accounts, emails, audit, deleted := newEraser(now)
+
+	accounts.On("GetUser", ctx, alice.DID).Return(alice, nil)
+	accounts.On("AnonymizeUser", ctx, alice.DID).Return(nil)
+	emails.On("DeleteEmails", ctx, alice.DID).Return(nil)
+	audit.On("RecordAuditEvent", ctx, mock.MatchedBy(func(event models.AuditEvent) bool {
+		return event.Event == postgres.AuditErasureRequested
+	})).Return(nil).Once()
+	audit.On("RecordAuditEvent", ctx, mock.MatchedBy(func(event models.AuditEvent) bool {
+		return event.Event == postgres.AuditAccountDeleted && event.Handle == ""
+	})).Return(errors.New("DB connection failed")).Once()
+	publisher := new(mockPublisher)
+	publisher.On("Publish", ctx, models.NewAccountEvent(models.EventAccountDeleted, alice.DID, alice.Handle, "shareframe")).Return(nil)
+	eraser.Publishers = []outbox.Publisher{publisher}
+
+	requested, err := eraser.Request(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, requested.Confirmation)
+	assert.Equal(t, now.Add(time.Hour), requested.ExpiresAt)
+	assert.Empty(t, *deleted, "nothing is deleted before confirmation")
+
+	resp, err := eraser.Confirm(ctx, "shareframe", alice.DID, "dpo@shareframe.social", requested.Confirmation)
+
+	assert.NoError(t, err, "a failed audit write doesn't fail the erasure")
+	assert.True(t, resp.Erased)
+	assert.Equal(t, []string{alice.DID}, *deleted)
+	accounts.AssertExpectations(t)
+	emails.AssertExpectations(t)
+	audit.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestEraseRejectsConfirmation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	issue := func(purpose, did, tenant string) string {
+		signed, err := token.Encode(ctx, token.NewHMAC("2026-10", []byte("secret")), erasureClaims{
+			Registered: token.NewRegistered(purpose, did, now, time.Hour),
+			Tenant:     tenant,
+		})
+		assert.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name         string
+		confirmation string
+		now          time.Time
+	}{
+		{"Missing", "", now},
+		{"Expired", issue(token.PurposeAccountDeletion, alice.DID, "shareframe"), now.Add(2 * time.Hour)},
+		{"Other Account", issue(token.PurposeAccountDeletion, "did:example:456", "shareframe"), now},
+		{"Other Tenant", issue(token.PurposeAccountDeletion, alice.DID, "other"), now},
+		{"Signup Token", issue(token.PurposeSignup, alice.DID, "shareframe"), now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eraser, _, _, _, deleted := newEraser(tt.now)
+
+			_, err := eraser.Confirm(ctx, "shareframe", alice.DID, "dpo@shareframe.social", tt.confirmation)
+
+			assert.ErrorIs(t, err, ErrConfirmation)
+			assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+			assert.Empty(t, *deleted)
+		})
+	}
+}
+
+type mockQuarantine struct {
+	mock.Mock
+}
+
+func (m *mockQuarantine) QuarantineHandle(ctx context.Context, handle, did string, ttl time.Duration) error {
+	return m.Called(ctx, handle, did, ttl).Error(0)
+}
+
+func (m *mockQuarantine) QuarantinedHandle(ctx context.Context, handle string) (time.Time, bool, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
+func TestEraseQuarantinesHandle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	erased := alice
+	erased.Handle, erased.Status = "erased-did:example:123", models.StatusErased
+
+	tests := []struct {
+		name           string
+		user           models.UserRecord
+		quarantineErr  error
+		wantQuarantine bool
+		wantDeleted    bool
+		expectedErr    string
+	}{
+		{name: "Quarantined", user: alice, wantQuarantine: true, wantDeleted: true},
+		{name: "Retried After Anonymizing", user: erased, wantDeleted: true},
+		{
+			name:           "Store Unavailable",
+			user:           alice,
+			quarantineErr:  errors.New("DB connection failed"),
+			wantQuarantine: true,
+			expectedErr:    "failed to erase user data: DB connection failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eraser, accounts, emails, audit, deleted := newEraser(now)
+			quarantine := new(mockQuarantine)
+			eraser.Quarantine = quarantine
+			eraser.QuarantineTTL = 90 * 24 * time.Hour
+			accounts.On("GetUser", ctx, alice.DID).Return(tt.user, nil)
+			accounts.On("AnonymizeUser", ctx, alice.DID).Return(nil).Maybe()
+			emails.On("DeleteEmails", ctx, alice.DID).Return(nil).Maybe()
+			audit.On("RecordAuditEvent", ctx, mock.Anything).Return(nil)
+			if tt.wantQuarantine {
+				quarantine.On("QuarantineHandle", ctx, alice.Handle, alice.DID, 90*24*time.Hour).Return(tt.quarantineErr)
+			}
+
+			requested, err := eraser.Request(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+			assert.NoError(t, err)
+			_, err = eraser.Confirm(ctx, "shareframe", alice.DID, "dpo@shareframe.social", requested.Confirmation)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDeleted, len(*deleted) == 1, "the PDS account is deleted after the handle is quarantined")
+			quarantine.AssertExpectations(t)
+			if !tt.wantQuarantine {
+				quarantine.AssertNotCalled(t, "QuarantineHandle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestEraseStopsWhenPDSFails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	eraser, accounts, _, audit, _ := newEraser(now)
+	eraser.DeletePDSAccount = func(ctx context.Context, did string) error {
+		return errors.New("PDS unavailable")
+	}
+	accounts.On("GetUser", ctx, alice.DID).Return(alice, nil)
+	audit.On("RecordAuditEvent", ctx, mock.Anything).Return(nil)
+
+	requested, err := eraser.Request(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+	assert.NoError(t, err)
+
+	_, err = eraser.Confirm(ctx, "shareframe", alice.DID, "dpo@shareframe.social", requested.Confirmation)
+
+	assert.ErrorContains(t, err, "failed to delete account from PDS")
+	accounts.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
+
+func TestEraseDeletesCopiesElsewhere(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	erased := alice
+	erased.Handle, erased.Email, erased.Status = "erased-1", "erased-1@erased.invalid", models.StatusErased
+
+	tests := []struct {
+		name           string
+		user           models.UserRecord
+		integrations   bool
+		customerID     string
+		crmErr         error
+		wantCRM        []string
+		wantStripe     []string
+		wantReviews    bool
+		wantAnonymized bool
+		expectedErr    string
+	}{
+		{name: "Deleted Everywhere", user: alice, integrations: true, customerID: "cus_123", wantCRM: []string{alice.Email}, wantStripe: []string{"cus_123"}, wantReviews: true, wantAnonymized: true},
+		{name: "No Stripe Customer", user: alice, integrations: true, wantCRM: []string{alice.Email}, wantReviews: true, wantAnonymized: true},
+		{name: "Integrations Off", user: alice, wantReviews: true, wantAnonymized: true},
+		{name: "CRM Unavailable", user: alice, integrations: true, crmErr: errors.New("HubSpot unavailable"), wantCRM: []string{alice.Email}, wantReviews: true, expectedErr: "failed to delete CRM contact: HubSpot unavailable"},
+		{name: "Retried After Anonymizing", user: erased, integrations: true, customerID: "cus_123", wantAnonymized: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eraser, accounts, emails, audit, _ := newEraser(now)
+			reviews, billing := new(mockReviews), new(mockBilling)
+			eraser.Reviews, eraser.Billing = reviews, billing
+			var crmDeleted, stripeDeleted []string
+			if tt.integrations {
+				eraser.DeleteCRMContact = func(ctx context.Context, email string) error {
+					crmDeleted = append(crmDeleted, email)
+					return tt.crmErr
+				}
+				eraser.DeleteStripeCustomer = func(ctx context.Context, customerID string) error {
+					stripeDeleted = append(stripeDeleted, customerID)
+					return nil
+				}
+			}
+			accounts.On("GetUser", ctx, alice.DID).Return(tt.user, nil)
+			accounts.On("AnonymizeUser", ctx, alice.DID).Return(nil).Maybe()
+			emails.On("DeleteEmails", ctx, alice.DID).Return(nil).Maybe()
+			audit.On("RecordAuditEvent", ctx, mock.Anything).Return(nil)
+			reviews.On("DeleteFiledSignups", ctx, alice.Handle, alice.Email).Return(nil).Maybe()
+			billing.On("StripeCustomerID", ctx, alice.DID).Return(tt.customerID, nil).Maybe()
+
+			requested, err := eraser.Request(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+			assert.NoError(t, err)
+			_, err = eraser.Confirm(ctx, "shareframe", alice.DID, "dpo@shareframe.social", requested.Confirmation)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCRM, crmDeleted)
+			assert.Equal(t, tt.wantStripe, stripeDeleted)
+			if tt.wantReviews {
+				reviews.AssertCalled(t, "DeleteFiledSignups", ctx, alice.Handle, alice.Email)
+			} else {
+				reviews.AssertNotCalled(t, "DeleteFiledSignups", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.wantAnonymized {
+				accounts.AssertCalled(t, "AnonymizeUser", ctx, alice.DID)
+			} else {
+				accounts.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package privacy answers data subject requests: exporting everything held
+// on an account, and erasing it once the holder has confirmed.
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type ObjectStore interface {
+	PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+type Presigner interface {
+	PresignGetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// Exporter writes an account's data to S3 as one JSON document and hands
+// back a presigned link to it, so the bundle never passes through the
+// operator who asked for it.
+type Exporter struct {
+	Accounts  postgres.PrivacyStore
+	Consents  postgres.ConsentStore
+	Emails    postgres.EmailDataStore
+	Audit     postgres.AuditStore
+	Objects   ObjectStore
+	Presigner Presigner
+	Bucket    string
+	// URLTTL is how long the link stays valid. A link presigned with
+	// temporary credentials stops working when they expire, whichever is
+	// sooner.
+	URLTTL time.Duration
+	now    func() time.Time
+}
+
+// Export collects the account did's storage record, audit trail, consent
+// history and emails and returns a link to the bundle.
+func (e *Exporter) Export(ctx context.Context, tenant, did, actor string) (models.PrivacyResponse, error) {
+	user, err := e.Accounts.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to export user data: %w", err)
+	}
+	events, err := e.Accounts.ListAuditEvents(ctx, did)
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to export user data: %w", err)
+	}
+	consents, err := e.Consents.ListConsents(ctx, did)
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to export user data: %w", err)
+	}
+	emails, err := e.Emails.ListEmails(ctx, did)
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to export user data: %w", err)
+	}
+
+	now := e.clock()
+	body, err := json.MarshalIndent(models.DataExport{
+		ExportedAt:  now,
+		Tenant:      tenant,
+		User:        user,
+		AuditEvents: events,
+		Consents:    consents,
+		Emails:      emails,
+	}, "", "  ")
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to encode user data: %w", err)
+	}
+
+	key := fmt.Sprintf("exports/%s/%s/%d.json", tenant, did, now.Unix())
+	if _, err := e.Objects.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(e.Bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(body),
+		ContentType:          aws.String("application/json"),
+		ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to upload data export")
+		return models.PrivacyResponse{}, apperr.Errorf(apperr.Upstream, "failed to upload data export: %w", err)
+	}
+
+	presigned, err := e.Presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(e.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(e.URLTTL))
+	if err != nil {
+		return models.PrivacyResponse{}, fmt.Errorf("failed to presign data export: %w", err)
+	}
+
+	if err := e.Audit.RecordAuditEvent(ctx, models.AuditEvent{
+		Event:   postgres.AuditDataExported,
+		DID:     did,
+		Handle:  user.Handle,
+		Actor:   actor,
+		Details: map[string]string{"key": key},
+	}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Data exported without an audit event")
+	}
+
+	logging.FromContext(ctx).WithField("did", did).Info("Exported user data")
+	return models.PrivacyResponse{ExportURL: presigned.URL, ExpiresAt: now.Add(e.URLTTL)}, nil
+}
+
+func (e *Exporter) clock() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,172 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAccounts struct {
+	mock.Mock
+}
+
+func (m *mockAccounts) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.UserRecord), args.Error(1)
+}
+
+func (m *mockAccounts) ListAuditEvents(ctx context.Context, did string) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func (m *mockAccounts) AnonymizeUser(ctx context.Context, did string) error {
+	return m.Called(ctx, did).Error(0)
+}
+
+func (m *mockAccounts) RecordConsents(ctx context.Context, did string, consents []models.Consent) error {
+	return m.Called(ctx, did, consents).Error(0)
+}
+
+func (m *mockAccounts) ListConsents(ctx context.Context, did string) ([]models.Consent, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).([]models.Consent), args.Error(1)
+}
+
+type mockEmails struct {
+	mock.Mock
+}
+
+func (m *mockEmails) ListEmails(ctx context.Context, did string) ([]models.PendingEmail, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).([]models.PendingEmail), args.Error(1)
+}
+
+func (m *mockEmails) DeleteEmails(ctx context.Context, did string) error {
+	return m.Called(ctx, did).Error(0)
+}
+
+type mockAudit struct {
+	mock.Mock
+}
+
+func (m *mockAudit) RecordAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+// fakeS3 keeps the last uploaded object and presigns a fixed URL.
+type fakeS3 struct {
+	key     string
+	body    []byte
+	putErr  error
+	expires time.Duration
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	f.key = aws.ToString(input.Key)
+	f.body, _ = io.ReadAll(input.Body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) PresignGetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	var options s3.PresignOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	f.expires = options.Expires
+	return &v4.PresignedHTTPRequest{URL: "https://exports.example.com/" + aws.ToString(input.Key) + "?X-Amz-Signature=abc"}, nil
+}
+
+var alice = models.UserRecord{DID: "did:example:123", Email: "alice@example.com", Handle: "alice.shareframe.social", Status: "active"}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []models.AuditEvent{{Event: postgres.AuditAccountCreated, DID: alice.DID, Actor: postgres.AuditActorSelf}}
+	consents := []models.Consent{{Purpose: models.ConsentMarketing, Granted: true, TextVersion: "marketing-v1", RecordedAt: now.Add(-time.Hour)}}
+	emails := []models.PendingEmail{{ID: 7, DID: alice.DID, Recipient: alice.Email, Subject: "Welcome"}}
+
+	accounts := new(mockAccounts)
+	accounts.On("GetUser", ctx, alice.DID).Return(alice, nil)
+	accounts.On("ListAuditEvents", ctx, alice.DID).Return(events, nil)
+	accounts.On("ListConsents", ctx, alice.DID).Return(consents, nil)
+	emailStore := new(mockEmails)
+	emailStore.On("ListEmails", ctx, alice.DID).Return(emails, nil)
+	audit := new(mockAudit)
+	audit.On("RecordAuditEvent", ctx, mock.MatchedBy(func(event models.AuditEvent) bool {
+		return event.Event == postgres.AuditDataExported && event.Actor == "dpo@shareframe.social"
+	})).Return(nil)
+	objects := &fakeS3{}
+
+	exporter := &Exporter{
+		Accounts:  accounts,
+		Consents:  accounts,
+		Emails:    emailStore,
+		Audit:     audit,
+		Objects:   objects,
+		Presigner: objects,
+		Bucket:    "exports",
+		URLTTL:    time.Hour,
+		now:       func() time.Time { return now },
+	}
+
+	resp, err := exporter.Export(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exports/shareframe/did:example:123/1767323045.json", objects.key)
+	assert.Contains(t, resp.ExportURL, objects.key)
+	assert.Equal(t, now.Add(time.Hour), resp.ExpiresAt)
+	assert.Equal(t, time.Hour, objects.expires)
+
+	var bundle models.DataExport
+	assert.NoError(t, json.Unmarshal(objects.body, &bundle))
+	assert.Equal(t, models.DataExport{ExportedAt: now, Tenant: "shareframe", User: alice, AuditEvents: events, Consents: consents, Emails: emails}, bundle)
+	audit.AssertExpectations(t)
+}
+
+func TestExportFailures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		userErr  error
+		putErr   error
+		category apperr.Category
+	}{
+		{name: "Unknown Account", userErr: postgres.ErrUserNotFound, category: apperr.NotFound},
+		{name: "Upload Failed", putErr: errors.New("AccessDenied"), category: apperr.Upstream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := new(mockAccounts)
+			accounts.On("GetUser", ctx, alice.DID).Return(alice, tt.userErr)
+			accounts.On("ListAuditEvents", ctx, alice.DID).Return([]models.AuditEvent{}, nil)
+			accounts.On("ListConsents", ctx, alice.DID).Return([]models.Consent{}, nil)
+			emailStore := new(mockEmails)
+			emailStore.On("ListEmails", ctx, alice.DID).Return([]models.PendingEmail{}, nil)
+			objects := &fakeS3{putErr: tt.putErr}
+
+			exporter := &Exporter{Accounts: accounts, Consents: accounts, Emails: emailStore, Audit: new(mockAudit), Objects: objects, Presigner: objects, URLTTL: time.Hour}
+			_, err := exporter.Export(ctx, "shareframe", alice.DID, "dpo@shareframe.social")
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.category, apperr.CategoryOf(err))
+		})
+	}
+}
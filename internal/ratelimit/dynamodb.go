@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDB attribute names. The table's partition key is pk (string), its
+// sort key shard (number), and TTL must be enabled on expires_at.
+const (
+	attrKey     = "pk"
+	attrShard   = "shard"
+	attrHits    = "hits"
+	attrExpires = "expires_at"
+)
+
+// DynamoDBAPI is the part of the DynamoDB client the limiter uses.
+type DynamoDBAPI interface {
+	UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// NewDynamoLimiter returns a Limiter whose counters live in table. Each
+// counter is split over shards items, written at random, so a hot key
+// doesn't throttle on a single item; reads sum the shards.
+func NewDynamoLimiter(client DynamoDBAPI, table string, shards int) *SlidingWindow {
+	if shards < 1 {
+		shards = 1
+	}
+	return &SlidingWindow{store: &dynamoStore{client: client, table: table, shards: shards}, now: time.Now}
+}
+
+type dynamoStore struct {
+	client DynamoDBAPI
+	table  string
+	shards int
+}
+
+func counterKey(key string, bucket time.Time) string {
+	return key + "#" + strconv.FormatInt(bucket.Unix(), 10)
+}
+
+func (s *dynamoStore) count(ctx context.Context, key string, bucket time.Time) (int, error) {
+	ctx, seg := tracing.Begin(ctx, "DynamoDB", tracing.NamespaceAWS)
+	seg.SetAWS("Query")
+	result, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("#pk = :pk"),
+		ProjectionExpression:   aws.String("#hits"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk":   attrKey,
+			"#hits": attrHits,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: counterKey(key, bucket)},
+		},
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyDynamoDB, err)
+		return 0, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+
+	total := 0
+	for _, item := range result.Items {
+		if hits, ok := item[attrHits].(*types.AttributeValueMemberN); ok {
+			n, err := strconv.Atoi(hits.Value)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read rate limit counter: %w", err)
+			}
+			total += n
+		}
+	}
+	return total, nil
+}
+
+func (s *dynamoStore) add(ctx context.Context, key string, bucket, expires time.Time) error {
+	ctx, seg := tracing.Begin(ctx, "DynamoDB", tracing.NamespaceAWS)
+	seg.SetAWS("UpdateItem")
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]types.AttributeValue{
+			attrKey:   &types.AttributeValueMemberS{Value: counterKey(key, bucket)},
+			attrShard: &types.AttributeValueMemberN{Value: strconv.Itoa(rand.IntN(s.shards))},
+		},
+		UpdateExpression: aws.String("ADD #hits :one SET #expires = :expires"),
+		ExpressionAttributeNames: map[string]string{
+			"#hits":    attrHits,
+			"#expires": attrExpires,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+			":expires": &types.AttributeValueMemberN{Value: strconv.FormatInt(expires.Unix(), 10)},
+		},
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyDynamoDB, err)
+		return fmt.Errorf("failed to update rate limit counter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDynamoDB struct {
+	mock.Mock
+}
+
+func (m *mockDynamoDB) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(ctx, input)
+	if len(args) == 2 {
+		output, _ := args.Get(0).(*dynamodb.UpdateItemOutput)
+		return output, args.Error(1)
+	}
+	return &dynamodb.UpdateItemOutput{}, args.Error(0)
+}
+
+func (m *mockDynamoDB) Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	args := m.Called(ctx, input)
+	output, _ := args.Get(0).(*dynamodb.QueryOutput)
+	return output, args.Error(1)
+}
+
+func queryFor(pk string) interface{} {
+	return mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		value, _ := input.ExpressionAttributeValues[":pk"].(*types.AttributeValueMemberS)
+		return *input.TableName == "rate-limits" && value != nil && value.Value == pk
+	})
+}
+
+func shards(hits ...string) *dynamodb.QueryOutput {
+	output := &dynamodb.QueryOutput{}
+	for _, h := range hits {
+		output.Items = append(output.Items, map[string]types.AttributeValue{attrHits: &types.AttributeValueMemberN{Value: h}})
+	}
+	return output
+}
+
+func TestDynamoLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	limit := Limit{Max: 10, Window: time.Hour}
+	current := "signup_domain:bots.example#" + "1767261600"
+	previous := "signup_domain:bots.example#" + "1767258000"
+
+	tests := []struct {
+		name        string
+		current     *dynamodb.QueryOutput
+		previous    *dynamodb.QueryOutput
+		queryErr    error
+		updateErr   error
+		expectWrite bool
+		expected    Decision
+		expectedErr string
+	}{
+		{
+			name:        "Shards Summed",
+			current:     shards("2", "3"),
+			previous:    shards("4"),
+			expectWrite: true,
+			expected:    Decision{Allowed: true, Count: 8},
+		},
+		{
+			name:     "Over Limit",
+			current:  shards("4", "4"),
+			previous: shards("6"),
+			expected: Decision{Count: 11, RetryAfter: 30 * time.Minute},
+		},
+		{
+			name:        "Read Fails",
+			queryErr:    errors.New("throttled"),
+			expectedErr: "failed to read rate limit counter: throttled",
+		},
+		{
+			name:        "Write Fails",
+			current:     shards(),
+			previous:    shards(),
+			expectWrite: true,
+			updateErr:   errors.New("throttled"),
+			expectedErr: "failed to update rate limit counter: throttled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockDynamoDB)
+			client.On("Query", mock.Anything, queryFor(current)).Return(tt.current, tt.queryErr)
+			if tt.queryErr == nil {
+				client.On("Query", mock.Anything, queryFor(previous)).Return(tt.previous, nil)
+			}
+			if tt.expectWrite {
+				client.On("UpdateItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+					pk, _ := input.Key[attrKey].(*types.AttributeValueMemberS)
+					shard, _ := input.Key[attrShard].(*types.AttributeValueMemberN)
+					expires, _ := input.ExpressionAttributeValues[":expires"].(*types.AttributeValueMemberN)
+					return pk.Value == current && shard.Value >= "0" && shard.Value <= "3" && expires.Value == "1767268800"
+				})).Return(tt.updateErr)
+			}
+
+			limiter := NewDynamoLimiter(client, "rate-limits", 4)
+			limiter.now = func() time.Time { return now }
+
+			decision, err := limiter.Allow(ctx, "signup_domain:bots.example", limit)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, decision)
+			}
+			client.AssertExpectations(t)
+		})
+	}
+}
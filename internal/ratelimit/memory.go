@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewMemoryLimiter returns a Limiter that keeps its counters in process,
+// for tests and local runs.
+func NewMemoryLimiter() *SlidingWindow {
+	return &SlidingWindow{store: &memoryStore{counters: map[memoryKey]*memoryCounter{}}, now: time.Now}
+}
+
+type memoryKey struct {
+	key    string
+	bucket int64
+}
+
+type memoryCounter struct {
+	value   int
+	expires time.Time
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	counters map[memoryKey]*memoryCounter
+}
+
+func (s *memoryStore) count(_ context.Context, key string, bucket time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.counters[memoryKey{key, bucket.UnixNano()}]; ok {
+		return c.value, nil
+	}
+	return 0, nil
+}
+
+func (s *memoryStore) add(_ context.Context, key string, bucket, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, c := range s.counters {
+		if c.expires.Before(bucket) {
+			delete(s.counters, k)
+		}
+	}
+
+	k := memoryKey{key, bucket.UnixNano()}
+	c, ok := s.counters[k]
+	if !ok {
+		c = &memoryCounter{expires: expires}
+		s.counters[k] = c
+	}
+	c.value++
+	return nil
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QuotaWindow is the period a quota applies to: a UTC day.
+const QuotaWindow = 24 * time.Hour
+
+// Usage is how much of a key's quota is used in the current window.
+// Allowed is only set by Take, for an event it recorded.
+type Usage struct {
+	Allowed  bool
+	Used     int
+	ResetsAt time.Time
+}
+
+// Quota counts events per key per UTC day and refuses them past a daily
+// maximum. Unlike a Limiter, it checks and records in one atomic write, so
+// a quota is never overshot, even by concurrent callers; it suits caps on
+// something scarce, such as the PDS's invite capacity.
+type Quota interface {
+	// Take records one event for key if fewer than max were recorded
+	// today.
+	Take(ctx context.Context, key string, max int) (Usage, error)
+	// Used reads key's count for today without changing it.
+	Used(ctx context.Context, key string) (Usage, error)
+}
+
+// quotaDay is the start of the window now falls in.
+func quotaDay(now time.Time) time.Time {
+	return now.UTC().Truncate(QuotaWindow)
+}
+
+// NewDynamoQuota returns a Quota whose counters live in table, the rate
+// limit table. A quota's counter is a single item, unlike a limiter's,
+// since the conditional write that keeps it exact can't span shards.
+func NewDynamoQuota(client DynamoDBAPI, table string) *DynamoQuota {
+	return &DynamoQuota{store: &dynamoStore{client: client, table: table, shards: 1}, now: time.Now}
+}
+
+type DynamoQuota struct {
+	store *dynamoStore
+	now   func() time.Time
+}
+
+func (q *DynamoQuota) Take(ctx context.Context, key string, max int) (Usage, error) {
+	day := quotaDay(q.now())
+	usage := Usage{ResetsAt: day.Add(QuotaWindow)}
+
+	ctx, seg := tracing.Begin(ctx, "DynamoDB", tracing.NamespaceAWS)
+	seg.SetAWS("UpdateItem")
+	result, err := q.store.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(q.store.table),
+		Key: map[string]types.AttributeValue{
+			attrKey:   &types.AttributeValueMemberS{Value: counterKey(key, day)},
+			attrShard: &types.AttributeValueMemberN{Value: "0"},
+		},
+		UpdateExpression:    aws.String("ADD #hits :one SET #expires = :expires"),
+		ConditionExpression: aws.String("attribute_not_exists(#hits) OR #hits < :max"),
+		ExpressionAttributeNames: map[string]string{
+			"#hits":    attrHits,
+			"#expires": attrExpires,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+			":max":     &types.AttributeValueMemberN{Value: strconv.Itoa(max)},
+			":expires": &types.AttributeValueMemberN{Value: strconv.FormatInt(usage.ResetsAt.Add(QuotaWindow).Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	var refused *types.ConditionalCheckFailedException
+	if errors.As(err, &refused) {
+		seg.Close(nil)
+		usage.Used = max
+		return usage, nil
+	}
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyDynamoDB, err)
+		return Usage{}, fmt.Errorf("failed to update quota counter: %w", err)
+	}
+
+	hits, ok := result.Attributes[attrHits].(*types.AttributeValueMemberN)
+	if !ok {
+		return Usage{}, errors.New("failed to update quota counter: no count returned")
+	}
+	if usage.Used, err = strconv.Atoi(hits.Value); err != nil {
+		return Usage{}, fmt.Errorf("failed to update quota counter: %w", err)
+	}
+	usage.Allowed = true
+	return usage, nil
+}
+
+func (q *DynamoQuota) Used(ctx context.Context, key string) (Usage, error) {
+	day := quotaDay(q.now())
+	used, err := q.store.count(ctx, key, day)
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Used: used, ResetsAt: day.Add(QuotaWindow)}, nil
+}
+
+// NewMemoryQuota returns a Quota that keeps its counters in process, for
+// tests and local runs.
+func NewMemoryQuota() *MemoryQuota {
+	return &MemoryQuota{counters: map[memoryKey]int{}, now: time.Now}
+}
+
+type MemoryQuota struct {
+	mu       sync.Mutex
+	counters map[memoryKey]int
+	now      func() time.Time
+}
+
+func (q *MemoryQuota) Take(_ context.Context, key string, max int) (Usage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := quotaDay(q.now())
+	for k := range q.counters {
+		if k.bucket < day.UnixNano() {
+			delete(q.counters, k)
+		}
+	}
+
+	k := memoryKey{key, day.UnixNano()}
+	usage := Usage{Used: q.counters[k], ResetsAt: day.Add(QuotaWindow)}
+	if usage.Used >= max {
+		return usage, nil
+	}
+	q.counters[k]++
+	usage.Used++
+	usage.Allowed = true
+	return usage, nil
+}
+
+func (q *MemoryQuota) Used(_ context.Context, key string) (Usage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := quotaDay(q.now())
+	return Usage{Used: q.counters[memoryKey{key, day.UnixNano()}], ResetsAt: day.Add(QuotaWindow)}, nil
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDynamoQuotaTake(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	resetsAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	pk := "signup_quota#1767225600"
+
+	tests := []struct {
+		name        string
+		output      *dynamodb.UpdateItemOutput
+		updateErr   error
+		expected    Usage
+		expectedErr string
+	}{
+		{
+			name:     "Taken",
+			output:   &dynamodb.UpdateItemOutput{Attributes: map[string]types.AttributeValue{attrHits: &types.AttributeValueMemberN{Value: "7"}}},
+			expected: Usage{Allowed: true, Used: 7, ResetsAt: resetsAt},
+		},
+		{
+			name:      "Used Up",
+			updateErr: &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")},
+			expected:  Usage{Used: 10, ResetsAt: resetsAt},
+		},
+		{
+			name:        "Write Fails",
+			updateErr:   errors.New("throttled"),
+			expectedErr: "failed to update quota counter: throttled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockDynamoDB)
+			client.On("UpdateItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+				key, _ := input.Key[attrKey].(*types.AttributeValueMemberS)
+				shard, _ := input.Key[attrShard].(*types.AttributeValueMemberN)
+				max, _ := input.ExpressionAttributeValues[":max"].(*types.AttributeValueMemberN)
+				expires, _ := input.ExpressionAttributeValues[":expires"].(*types.AttributeValueMemberN)
+				return key.Value == pk && shard.Value == "0" && max.Value == "10" && expires.Value == "1767398400" &&
+					*input.ConditionExpression == "attribute_not_exists(#hits) OR #hits < :max"
+			})).Return(tt.output, tt.updateErr)
+
+			quota := NewDynamoQuota(client, "rate-limits")
+			quota.now = func() time.Time { return now }
+
+			usage, err := quota.Take(ctx, "signup_quota", 10)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, usage)
+			}
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDynamoQuotaUsed(t *testing.T) {
+	ctx := context.Background()
+	client := new(mockDynamoDB)
+	client.On("Query", mock.Anything, queryFor("signup_quota#1767225600")).Return(shards("7"), nil)
+
+	quota := NewDynamoQuota(client, "rate-limits")
+	quota.now = func() time.Time { return time.Date(2026, 1, 1, 23, 59, 0, 0, time.UTC) }
+
+	usage, err := quota.Used(ctx, "signup_quota")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Used: 7, ResetsAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}, usage)
+	client.AssertExpectations(t)
+}
+
+func TestMemoryQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	quota := NewMemoryQuota()
+	quota.now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		usage, err := quota.Take(ctx, "signup_quota", 2)
+		assert.NoError(t, err)
+		assert.True(t, usage.Allowed)
+		assert.Equal(t, i, usage.Used)
+	}
+	usage, err := quota.Take(ctx, "signup_quota", 2)
+	assert.NoError(t, err)
+	assert.False(t, usage.Allowed)
+	assert.Equal(t, 2, usage.Used)
+
+	other, err := quota.Take(ctx, "signup_quota_domain:example.com", 2)
+	assert.NoError(t, err)
+	assert.True(t, other.Allowed)
+
+	now = now.Add(14 * time.Hour)
+	usage, err = quota.Used(ctx, "signup_quota")
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{ResetsAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)}, usage)
+}
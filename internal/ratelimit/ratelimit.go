@@ -0,0 +1,81 @@
+// Package ratelimit counts events per key in sliding windows, so limits such
+// as signups per email domain hold across every running Lambda instance.
+//
+// Each window is split into fixed buckets the length of the window. The
+// count for "now" is the current bucket plus the previous one weighted by
+// how much of it still overlaps the window, which smooths the burst a plain
+// fixed window allows at each boundary without storing every event.
+//
+// A Quota counts events per UTC day instead, with a counter that is checked
+// and incremented in one write so it is never overshot.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Max events per Window.
+type Limit struct {
+	Max    int
+	Window time.Duration
+}
+
+// Decision is the outcome of one Allow call. RetryAfter is set when the
+// event was refused.
+type Decision struct {
+	Allowed    bool
+	Count      int
+	RetryAfter time.Duration
+}
+
+// Limiter records events and refuses them past a limit.
+type Limiter interface {
+	// Allow records one event for key if it is within limit.
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// counterStore keeps one counter per key and bucket start.
+type counterStore interface {
+	count(ctx context.Context, key string, bucket time.Time) (int, error)
+	// add increments the counter; it may be forgotten after expires.
+	add(ctx context.Context, key string, bucket, expires time.Time) error
+}
+
+// SlidingWindow is a Limiter over a counter store. Checking and recording
+// aren't atomic, so concurrent callers can overshoot a limit by a few
+// events; that is acceptable for abuse limits and keeps every call to two
+// reads and a write.
+type SlidingWindow struct {
+	store counterStore
+	now   func() time.Time
+}
+
+func (w *SlidingWindow) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	now := w.now()
+	bucket := now.Truncate(limit.Window)
+
+	current, err := w.store.count(ctx, key, bucket)
+	if err != nil {
+		return Decision{}, err
+	}
+	previous, err := w.store.count(ctx, key, bucket.Add(-limit.Window))
+	if err != nil {
+		return Decision{}, err
+	}
+
+	elapsed := now.Sub(bucket)
+	overlap := 1 - float64(elapsed)/float64(limit.Window)
+	count := current + int(float64(previous)*overlap)
+
+	if count >= limit.Max {
+		return Decision{Count: count, RetryAfter: limit.Window - elapsed}, nil
+	}
+
+	// Counters outlive their bucket by a window so they can still be read
+	// as the previous bucket.
+	if err := w.store.add(ctx, key, bucket, bucket.Add(2*limit.Window)); err != nil {
+		return Decision{}, err
+	}
+	return Decision{Allowed: true, Count: count + 1}, nil
+}
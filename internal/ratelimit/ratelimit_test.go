@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Max: 3, Window: time.Hour}
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		earlier    []time.Duration
+		at         time.Duration
+		expected   bool
+		retryAfter time.Duration
+	}{
+		{name: "First Event", at: 10 * time.Minute, expected: true},
+		{name: "Under Limit", earlier: []time.Duration{0, time.Minute}, at: 10 * time.Minute, expected: true},
+		{name: "At Limit", earlier: []time.Duration{0, time.Minute, 2 * time.Minute}, at: 15 * time.Minute, retryAfter: 45 * time.Minute},
+		{
+			// Three events early in the previous hour still weigh 3 * 0.75
+			// a quarter of the way into this one.
+			name:     "Previous Window Weighted",
+			earlier:  []time.Duration{0, time.Minute, 2 * time.Minute},
+			at:       75 * time.Minute,
+			expected: true,
+		},
+		{
+			name:       "Previous Window Plus Current",
+			earlier:    []time.Duration{0, time.Minute, 2 * time.Minute, 61 * time.Minute},
+			at:         70 * time.Minute,
+			retryAfter: 50 * time.Minute,
+		},
+		{name: "Previous Window Expired", earlier: []time.Duration{0, time.Minute, 2 * time.Minute}, at: 125 * time.Minute, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewMemoryLimiter()
+			for _, offset := range tt.earlier {
+				limiter.now = func() time.Time { return start.Add(offset) }
+				_, err := limiter.Allow(ctx, "k", limit)
+				assert.NoError(t, err)
+			}
+
+			limiter.now = func() time.Time { return start.Add(tt.at) }
+			decision, err := limiter.Allow(ctx, "k", limit)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, decision.Allowed)
+			assert.Equal(t, tt.retryAfter, decision.RetryAfter)
+		})
+	}
+}
+
+func TestSlidingWindowRefusedEventsAreNotCounted(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewMemoryLimiter()
+	limit := Limit{Max: 1, Window: time.Minute}
+
+	first, _ := limiter.Allow(ctx, "k", limit)
+	second, _ := limiter.Allow(ctx, "k", limit)
+	other, _ := limiter.Allow(ctx, "other", limit)
+
+	assert.True(t, first.Allowed)
+	assert.False(t, second.Allowed)
+	assert.Equal(t, 1, second.Count)
+	assert.True(t, other.Allowed)
+}
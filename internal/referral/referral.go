@@ -0,0 +1,162 @@
+// Package referral tracks which accounts referred which signups and
+// rewards referrers once the accounts they referred are verified.
+// Referrals that look like someone referring themselves are recorded as
+// rejected rather than rewarded.
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// codeLength is the length of an issued code: 40 random bits in base32.
+const codeLength = 8
+
+// maxCodeLength bounds the codes accepted from signups.
+const maxCodeLength = 32
+
+// Reasons a referral is rejected.
+const (
+	ReasonSelfReferral       = "self_referral"
+	ReasonReferrerIneligible = "referrer_ineligible"
+)
+
+// Tracker records and rewards referrals.
+type Tracker struct {
+	Referrals postgres.ReferralStore
+	Accounts  postgres.PrivacyStore
+	// newCode returns a fresh referral code.
+	newCode func() (string, error)
+}
+
+func NewTracker(referrals postgres.ReferralStore, accounts postgres.PrivacyStore) *Tracker {
+	return &Tracker{Referrals: referrals, Accounts: accounts, newCode: randomCode}
+}
+
+// NormalizeCode uppercases code and trims it. It returns "" for anything
+// that can't be a referral code.
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) > maxCodeLength {
+		return ""
+	}
+	for _, r := range code {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return code
+}
+
+// IssueCode returns the account's referral code, creating one the first
+// time.
+func (t *Tracker) IssueCode(ctx context.Context, did string) (string, error) {
+	if _, err := t.Accounts.GetUser(ctx, did); err != nil {
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			return "", apperr.Errorf(apperr.NotFound, "not found: %w", err)
+		}
+		return "", fmt.Errorf("internal error: could not load account: %w", err)
+	}
+
+	code, err := t.newCode()
+	if err != nil {
+		return "", fmt.Errorf("internal error: could not generate referral code: %w", err)
+	}
+	return t.Referrals.IssueReferralCode(ctx, did, code)
+}
+
+// Capture records that the new account signed up with code. Referrals from
+// an account with the same normalized email, or from one that isn't
+// active, are recorded as rejected. Unknown codes record nothing.
+func (t *Tracker) Capture(ctx context.Context, referred models.UserRecord, code string) error {
+	code = NormalizeCode(code)
+	if code == "" {
+		return nil
+	}
+
+	owner, err := t.Referrals.ReferralCodeOwner(ctx, code)
+	if errors.Is(err, postgres.ErrReferralCodeNotFound) {
+		logging.FromContext(ctx).WithField("code", code).Info("Unknown referral code; ignoring")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	referral := models.Referral{
+		ReferredDID: referred.DID,
+		Code:        code,
+		ReferrerDID: owner.DID,
+		Status:      models.ReferralPending,
+	}
+	switch {
+	case owner.DID == referred.DID || owner.NormalizedEmail == postgres.NormalizeEmail(referred.Email):
+		referral.Status, referral.Reason = models.ReferralRejected, ReasonSelfReferral
+	case owner.Status == models.StatusPendingReview || owner.Status == models.StatusErased || owner.Status == models.StatusSuspended || owner.Status == models.StatusRejected:
+		referral.Status, referral.Reason = models.ReferralRejected, ReasonReferrerIneligible
+	}
+	if referral.Status == models.ReferralRejected {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"did":    referred.DID,
+			"code":   code,
+			"reason": referral.Reason,
+		}).Warn("Referral rejected")
+	}
+
+	return t.Referrals.RecordReferral(ctx, referral)
+}
+
+// Verified rewards the referrer of did, which must be verified by now. It
+// reports false when there was no pending referral to reward, which makes
+// repeated calls harmless.
+func (t *Tracker) Verified(ctx context.Context, did string) (models.Referral, bool, error) {
+	account, err := t.Accounts.GetUser(ctx, did)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return models.Referral{}, false, apperr.Errorf(apperr.NotFound, "not found: %w", err)
+	}
+	if err != nil {
+		return models.Referral{}, false, fmt.Errorf("internal error: could not load account: %w", err)
+	}
+	if !account.Verified {
+		return models.Referral{}, false, apperr.Errorf(apperr.Validation, "validation error: account %s is not verified", did)
+	}
+
+	referral, rewarded, err := t.Referrals.RewardReferral(ctx, did)
+	if err != nil {
+		return models.Referral{}, false, err
+	}
+	if rewarded {
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"did":          did,
+			"referrer_did": referral.ReferrerDID,
+			"code":         referral.Code,
+		}).Info("Referral rewarded")
+	}
+	return referral, rewarded, nil
+}
+
+// Stats counts the signups code drove.
+func (t *Tracker) Stats(ctx context.Context, code string) (models.ReferralStats, error) {
+	normalized := NormalizeCode(code)
+	if normalized == "" {
+		return models.ReferralStats{}, apperr.Errorf(apperr.Validation, "validation error: invalid referral code %q", code)
+	}
+	return t.Referrals.ReferralStats(ctx, normalized)
+}
+
+func randomCode() (string, error) {
+	var b [5]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b[:])[:codeLength], nil
+}
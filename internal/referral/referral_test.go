@@ -0,0 +1,198 @@
+package referral
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReferrals struct {
+	mock.Mock
+}
+
+func (m *mockReferrals) IssueReferralCode(ctx context.Context, did, code string) (string, error) {
+	args := m.Called(ctx, did, code)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockReferrals) ReferralCodeOwner(ctx context.Context, code string) (models.ReferralCodeOwner, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(models.ReferralCodeOwner), args.Error(1)
+}
+
+func (m *mockReferrals) RecordReferral(ctx context.Context, referral models.Referral) error {
+	return m.Called(ctx, referral).Error(0)
+}
+
+func (m *mockReferrals) RewardReferral(ctx context.Context, referredDID string) (models.Referral, bool, error) {
+	args := m.Called(ctx, referredDID)
+	return args.Get(0).(models.Referral), args.Bool(1), args.Error(2)
+}
+
+func (m *mockReferrals) ReferralStats(ctx context.Context, code string) (models.ReferralStats, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(models.ReferralStats), args.Error(1)
+}
+
+type mockAccounts struct {
+	mock.Mock
+}
+
+func (m *mockAccounts) GetUser(ctx context.Context, did string) (models.UserRecord, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).(models.UserRecord), args.Error(1)
+}
+
+func (m *mockAccounts) ListAuditEvents(ctx context.Context, did string) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, did)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func (m *mockAccounts) AnonymizeUser(ctx context.Context, did string) error {
+	return m.Called(ctx, did).Error(0)
+}
+
+var bob = models.UserRecord{DID: "did:plc:bob", Email: "Bob+signup@example.com", Handle: "bob.shareframe.social"}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345", NormalizeCode(" abcd2345 "))
+	assert.Equal(t, "", NormalizeCode("abc-123"))
+	assert.Equal(t, "", NormalizeCode("A123456789012345678901234567890123"))
+}
+
+func TestCapture(t *testing.T) {
+	ctx := context.Background()
+	alice := models.ReferralCodeOwner{DID: "did:plc:alice", NormalizedEmail: "alice@example.com", Status: "active"}
+
+	tests := []struct {
+		name           string
+		code           string
+		owner          models.ReferralCodeOwner
+		ownerErr       error
+		expectedStatus string
+		expectedReason string
+		expectedErr    string
+	}{
+		{name: "Pending", code: "abcd2345", owner: alice, expectedStatus: models.ReferralPending},
+		{name: "Same Email", code: "ABCD2345", owner: models.ReferralCodeOwner{DID: "did:plc:alice", NormalizedEmail: "bob@example.com", Status: "active"}, expectedStatus: models.ReferralRejected, expectedReason: ReasonSelfReferral},
+		{name: "Referrer Under Review", code: "ABCD2345", owner: models.ReferralCodeOwner{DID: "did:plc:alice", NormalizedEmail: "alice@example.com", Status: models.StatusPendingReview}, expectedStatus: models.ReferralRejected, expectedReason: ReasonReferrerIneligible},
+		{name: "Referrer Rejected", code: "ABCD2345", owner: models.ReferralCodeOwner{DID: "did:plc:alice", NormalizedEmail: "alice@example.com", Status: models.StatusRejected}, expectedStatus: models.ReferralRejected, expectedReason: ReasonReferrerIneligible},
+		{name: "Unknown Code", code: "ABCD2345", ownerErr: postgres.ErrReferralCodeNotFound},
+		{name: "Malformed Code", code: "abcd-2345"},
+		{name: "Lookup Failed", code: "ABCD2345", ownerErr: errors.New("failed to look up referral code: DB connection failed"), expectedErr: "failed to look up referral code: DB connection failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			referrals := new(mockReferrals)
+			referrals.On("ReferralCodeOwner", ctx, "ABCD2345").Return(test.owner, test.ownerErr).Maybe()
+			if test.expectedStatus != "" {
+				referrals.On("RecordReferral", ctx, models.Referral{
+					ReferredDID: bob.DID,
+					Code:        "ABCD2345",
+					ReferrerDID: "did:plc:alice",
+					Status:      test.expectedStatus,
+					Reason:      test.expectedReason,
+				}).Return(nil)
+			}
+
+			err := NewTracker(referrals, new(mockAccounts)).Capture(ctx, bob, test.code)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			referrals.AssertExpectations(t)
+			if test.expectedStatus == "" {
+				referrals.AssertNotCalled(t, "RecordReferral", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestVerified(t *testing.T) {
+	ctx := context.Background()
+	rewarded := models.Referral{ReferredDID: bob.DID, Code: "ABCD2345", ReferrerDID: "did:plc:alice", Status: models.ReferralRewarded}
+
+	tests := []struct {
+		name             string
+		account          models.UserRecord
+		accountErr       error
+		pending          bool
+		expectedCategory apperr.Category
+	}{
+		{name: "Rewarded", account: models.UserRecord{DID: bob.DID, Verified: true}, pending: true},
+		{name: "Nothing Pending", account: models.UserRecord{DID: bob.DID, Verified: true}},
+		{name: "Not Verified", account: models.UserRecord{DID: bob.DID}, expectedCategory: apperr.Validation},
+		{name: "Unknown Account", accountErr: postgres.ErrUserNotFound, expectedCategory: apperr.NotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			referrals := new(mockReferrals)
+			accounts := new(mockAccounts)
+			accounts.On("GetUser", ctx, bob.DID).Return(test.account, test.accountErr)
+			if test.expectedCategory == "" {
+				result := models.Referral{}
+				if test.pending {
+					result = rewarded
+				}
+				referrals.On("RewardReferral", ctx, bob.DID).Return(result, test.pending, nil)
+			}
+
+			referral, ok, err := NewTracker(referrals, accounts).Verified(ctx, bob.DID)
+
+			if test.expectedCategory != "" {
+				assert.Equal(t, test.expectedCategory, apperr.CategoryOf(err))
+				referrals.AssertNotCalled(t, "RewardReferral", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.pending, ok)
+			if test.pending {
+				assert.Equal(t, rewarded, referral)
+			}
+		})
+	}
+}
+
+func TestIssueCode(t *testing.T) {
+	ctx := context.Background()
+	referrals := new(mockReferrals)
+	accounts := new(mockAccounts)
+	accounts.On("GetUser", ctx, bob.DID).Return(bob, nil)
+	referrals.On("IssueReferralCode", ctx, bob.DID, "NEWCODE2").Return("OLDCODE2", nil)
+
+	tracker := NewTracker(referrals, accounts)
+	tracker.newCode = func() (string, error) { return "NEWCODE2", nil }
+	code, err := tracker.IssueCode(ctx, bob.DID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "OLDCODE2", code, "an account keeps the code it was issued first")
+
+	generated, err := randomCode()
+	assert.NoError(t, err)
+	assert.Len(t, generated, codeLength)
+	assert.Equal(t, generated, NormalizeCode(generated))
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	referrals := new(mockReferrals)
+	referrals.On("ReferralStats", ctx, "ABCD2345").Return(models.ReferralStats{Code: "ABCD2345", Signups: 3, Rewarded: 2, Rejected: 1}, nil)
+	tracker := NewTracker(referrals, new(mockAccounts))
+
+	stats, err := tracker.Stats(ctx, "abcd2345")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Signups)
+
+	_, err = tracker.Stats(ctx, "not a code")
+	assert.Equal(t, apperr.Validation, apperr.CategoryOf(err))
+}
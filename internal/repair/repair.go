@@ -0,0 +1,170 @@
+// Package repair fixes accounts that older code or failed signups left
+// inconsistent: users rows written before the current schema version, and
+// PDS accounts that never got a users row because a signup failed after
+// registering them.
+package repair
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ShareFrame/user-management/internal/lifecycle"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+)
+
+// DefaultBatchSize is how many rows or repositories are read at a time
+// when the Repairer's BatchSize is unset.
+const DefaultBatchSize = 100
+
+// RepoLister pages through the repositories on a PDS.
+type RepoLister interface {
+	ListRepos(ctx context.Context, cursor string, limit int) (models.RepoPage, error)
+}
+
+// Repairer walks a tenant's accounts in batches. A failure to fix one
+// account is reported in the response and the walk goes on; a failure to
+// read a batch stops it. Every fix can be repeated, so a run that stopped
+// part way is simply run again.
+type Repairer struct {
+	Users postgres.RepairStore
+	Repos RepoLister
+	// DeletePDSAccount removes an orphaned account from the PDS.
+	DeletePDSAccount func(ctx context.Context, did string) error
+	// Keep are DIDs that have no users row by design, such as the utility
+	// account signups use, and are never treated as orphans.
+	Keep      []string
+	BatchSize int
+	// DeleteOrphans deletes the orphaned PDS accounts found, rather than
+	// only reporting them.
+	DeleteOrphans bool
+	// DryRun reports what would be fixed without fixing it.
+	DryRun bool
+}
+
+// Run repairs stale users rows, then looks for orphaned PDS accounts.
+//
+// A signup in progress has a PDS account and no users row for a moment,
+// so orphans should only be deleted after a dry run has been reviewed.
+func (r *Repairer) Run(ctx context.Context) (models.RepairResponse, error) {
+	resp := models.RepairResponse{DryRun: r.DryRun}
+	if err := r.backfill(ctx, &resp); err != nil {
+		return resp, err
+	}
+	if err := r.orphans(ctx, &resp); err != nil {
+		return resp, err
+	}
+
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"stale":    resp.Stale,
+		"repaired": resp.Repaired,
+		"orphans":  len(resp.Orphans),
+		"deleted":  resp.Deleted,
+		"failed":   len(resp.Failed),
+		"dry_run":  r.DryRun,
+	}).Info("Repair finished")
+	return resp, nil
+}
+
+func (r *Repairer) backfill(ctx context.Context, resp *models.RepairResponse) error {
+	after := ""
+	for {
+		records, err := r.Users.ListStaleUsers(ctx, after, r.batchSize())
+		if err != nil {
+			return fmt.Errorf("failed to list stale accounts: %w", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		for _, record := range records {
+			resp.Stale++
+			if r.DryRun {
+				continue
+			}
+
+			record.Verified = verified(record)
+			err := r.Users.RepairUser(ctx, record)
+			switch {
+			case errors.Is(err, postgres.ErrStatusChanged):
+				// The account moved on while we looked; the next run will
+				// see its new status.
+				logging.FromContext(ctx).WithField("did", record.DID).Info("Account status changed during repair; skipping")
+			case err != nil:
+				resp.Failed = append(resp.Failed, models.RepairFailure{DID: record.DID, Error: err.Error()})
+			default:
+				resp.Repaired++
+			}
+		}
+		after = records[len(records)-1].DID
+	}
+}
+
+// verified is the verified flag the account's status implies. Review and
+// suspension don't change whether the address was confirmed, so accounts
+// in those statuses keep theirs.
+func verified(record models.UserRecord) bool {
+	switch record.Status {
+	case models.StatusPending, models.StatusVerified, models.StatusActive:
+		return lifecycle.Verified(record.Status)
+	default:
+		return record.Verified
+	}
+}
+
+func (r *Repairer) orphans(ctx context.Context, resp *models.RepairResponse) error {
+	keep := make(map[string]bool, len(r.Keep))
+	for _, did := range r.Keep {
+		keep[did] = true
+	}
+
+	cursor := ""
+	for {
+		page, err := r.Repos.ListRepos(ctx, cursor, r.batchSize())
+		if err != nil {
+			return fmt.Errorf("failed to list PDS accounts: %w", err)
+		}
+
+		dids := make([]string, 0, len(page.Repos))
+		for _, repo := range page.Repos {
+			if !keep[repo.DID] {
+				dids = append(dids, repo.DID)
+			}
+		}
+		existing, err := r.Users.ExistingDIDs(ctx, dids)
+		if err != nil {
+			return fmt.Errorf("failed to look up PDS accounts: %w", err)
+		}
+
+		for _, did := range dids {
+			if existing[did] {
+				continue
+			}
+			resp.Orphans = append(resp.Orphans, did)
+			if r.DryRun || !r.DeleteOrphans {
+				continue
+			}
+
+			if err := r.DeletePDSAccount(ctx, did); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("did", did).Error("Failed to delete orphaned PDS account")
+				resp.Failed = append(resp.Failed, models.RepairFailure{DID: did, Error: err.Error()})
+				continue
+			}
+			resp.Deleted++
+		}
+
+		if page.Cursor == "" || page.Cursor == cursor || len(page.Repos) == 0 {
+			return nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+func (r *Repairer) batchSize() int {
+	if r.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return r.BatchSize
+}
@@ -0,0 +1,166 @@
+package repair
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUsers struct {
+	mock.Mock
+}
+
+func (m *mockUsers) ListStaleUsers(ctx context.Context, after string, limit int) ([]models.UserRecord, error) {
+	args := m.Called(ctx, after, limit)
+	records, _ := args.Get(0).([]models.UserRecord)
+	return records, args.Error(1)
+}
+
+func (m *mockUsers) RepairUser(ctx context.Context, record models.UserRecord) error {
+	return m.Called(ctx, record).Error(0)
+}
+
+func (m *mockUsers) ExistingDIDs(ctx context.Context, dids []string) (map[string]bool, error) {
+	args := m.Called(ctx, dids)
+	existing, _ := args.Get(0).(map[string]bool)
+	return existing, args.Error(1)
+}
+
+type mockRepos struct {
+	mock.Mock
+}
+
+func (m *mockRepos) ListRepos(ctx context.Context, cursor string, limit int) (models.RepoPage, error) {
+	args := m.Called(ctx, cursor, limit)
+	return args.Get(0).(models.RepoPage), args.Error(1)
+}
+
+func newRepairer(users *mockUsers, repos *mockRepos, deleted *[]string) *Repairer {
+	return &Repairer{
+		Users: users,
+		Repos: repos,
+		DeletePDSAccount: func(ctx context.Context, did string) error {
+			if did == "did:plc:stuck" {
+				return errors.New("pds unavailable")
+			}
+			*deleted = append(*deleted, did)
+			return nil
+		},
+		Keep:      []string{"did:plc:util"},
+		BatchSize: 2,
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	users := new(mockUsers)
+	repos := new(mockRepos)
+	var deleted []string
+	repairer := newRepairer(users, repos, &deleted)
+
+	users.On("ListStaleUsers", ctx, "", 2).Return([]models.UserRecord{
+		{DID: "did:plc:a", Status: models.StatusVerified},
+		{DID: "did:plc:b", Status: models.StatusPending, Verified: true},
+	}, nil)
+	users.On("ListStaleUsers", ctx, "did:plc:b", 2).Return([]models.UserRecord{
+		{DID: "did:plc:c", Status: models.StatusSuspended, Verified: true},
+		{DID: "did:plc:d", Status: models.StatusActive},
+	}, nil)
+	users.On("ListStaleUsers", ctx, "did:plc:d", 2).Return(nil, nil)
+	users.On("RepairUser", ctx, models.UserRecord{DID: "did:plc:a", Status: models.StatusVerified, Verified: true}).Return(nil)
+	users.On("RepairUser", ctx, models.UserRecord{DID: "did:plc:b", Status: models.StatusPending}).Return(postgres.ErrStatusChanged)
+	users.On("RepairUser", ctx, models.UserRecord{DID: "did:plc:c", Status: models.StatusSuspended, Verified: true}).Return(nil)
+	users.On("RepairUser", ctx, models.UserRecord{DID: "did:plc:d", Status: models.StatusActive, Verified: true}).Return(errors.New("db down"))
+	repos.On("ListRepos", ctx, "", 2).Return(models.RepoPage{}, nil)
+	users.On("ExistingDIDs", ctx, []string{}).Return(map[string]bool{}, nil)
+
+	resp, err := repairer.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RepairResponse{
+		Stale:    4,
+		Repaired: 2,
+		Failed:   []models.RepairFailure{{DID: "did:plc:d", Error: "db down"}},
+	}, resp)
+	users.AssertExpectations(t)
+}
+
+func TestOrphans(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		dryRun          bool
+		deleteOrphans   bool
+		expectedDeleted []string
+		expectedResp    models.RepairResponse
+	}{
+		{
+			name:         "Reported",
+			expectedResp: models.RepairResponse{Orphans: []string{"did:plc:orphan", "did:plc:stuck"}},
+		},
+		{
+			name:            "Deleted",
+			deleteOrphans:   true,
+			expectedDeleted: []string{"did:plc:orphan"},
+			expectedResp: models.RepairResponse{
+				Orphans: []string{"did:plc:orphan", "did:plc:stuck"},
+				Deleted: 1,
+				Failed:  []models.RepairFailure{{DID: "did:plc:stuck", Error: "pds unavailable"}},
+			},
+		},
+		{
+			name:          "Dry Run",
+			dryRun:        true,
+			deleteOrphans: true,
+			expectedResp:  models.RepairResponse{DryRun: true, Orphans: []string{"did:plc:orphan", "did:plc:stuck"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := new(mockUsers)
+			repos := new(mockRepos)
+			var deleted []string
+			repairer := newRepairer(users, repos, &deleted)
+			repairer.DryRun, repairer.DeleteOrphans = test.dryRun, test.deleteOrphans
+
+			users.On("ListStaleUsers", ctx, "", 2).Return(nil, nil)
+			repos.On("ListRepos", ctx, "", 2).Return(models.RepoPage{Cursor: "1", Repos: []models.Repo{{DID: "did:plc:util"}, {DID: "did:plc:alice"}}}, nil)
+			repos.On("ListRepos", ctx, "1", 2).Return(models.RepoPage{Cursor: "2", Repos: []models.Repo{{DID: "did:plc:orphan"}, {DID: "did:plc:stuck"}}}, nil)
+			repos.On("ListRepos", ctx, "2", 2).Return(models.RepoPage{Cursor: "2"}, nil)
+			users.On("ExistingDIDs", ctx, []string{"did:plc:alice"}).Return(map[string]bool{"did:plc:alice": true}, nil)
+			users.On("ExistingDIDs", ctx, []string{"did:plc:orphan", "did:plc:stuck"}).Return(map[string]bool{}, nil)
+			users.On("ExistingDIDs", ctx, []string{}).Return(map[string]bool{}, nil)
+
+			resp, err := repairer.Run(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedResp, resp)
+			assert.Equal(t, test.expectedDeleted, deleted)
+		})
+	}
+}
+
+func TestRunStopsOnListFailure(t *testing.T) {
+	ctx := context.Background()
+	users := new(mockUsers)
+	repos := new(mockRepos)
+	var deleted []string
+	repairer := newRepairer(users, repos, &deleted)
+
+	users.On("ListStaleUsers", ctx, "", 2).Return([]models.UserRecord{{DID: "did:plc:a", Status: models.StatusActive, Verified: true}}, nil)
+	users.On("ListStaleUsers", ctx, "did:plc:a", 2).Return(nil, errors.New("db down"))
+	users.On("RepairUser", ctx, mock.Anything).Return(nil)
+
+	resp, err := repairer.Run(ctx)
+
+	assert.ErrorContains(t, err, "failed to list stale accounts: db down")
+	assert.Equal(t, 1, resp.Repaired)
+	repos.AssertNotCalled(t, "ListRepos", mock.Anything, mock.Anything, mock.Anything)
+}
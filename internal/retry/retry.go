@@ -0,0 +1,195 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/aws/smithy-go"
+)
+
+// ErrorClass groups failures so operators can choose which ones are worth
+// retrying without knowing every error type each dependency can return.
+type ErrorClass string
+
+const (
+	ClassNetwork   ErrorClass = "network"
+	ClassTimeout   ErrorClass = "timeout"
+	ClassThrottled ErrorClass = "throttled"
+	ClassServer    ErrorClass = "server"
+	ClassOther     ErrorClass = "other"
+)
+
+var DefaultClasses = []ErrorClass{ClassNetwork, ClassTimeout, ClassThrottled, ClassServer}
+
+type Policy struct {
+	MaxAttempts      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	RetryableClasses []ErrorClass
+	// unsentOnly limits retries to failures that show the request never
+	// reached the server; see ForWrite.
+	unsentOnly bool
+}
+
+// ForWrite returns p for a request that isn't safe to send twice, such as
+// one that creates something. A timeout or a 5xx may come after the server
+// has done the work, so the request is only retried when the failure shows
+// it was never processed; see Unsent.
+func (p Policy) ForWrite() Policy {
+	p.unsentOnly = true
+	return p
+}
+
+// StatusError reports an HTTP response that should be considered for retry.
+// The response is kept open so the final attempt can still be inspected by
+// the caller.
+type StatusError struct {
+	StatusCode int
+	Response   *http.Response
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// IsRetryableStatus reports whether an HTTP status is worth classifying as a
+// retry candidate.
+func IsRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusTooManyRequests {
+			return ClassThrottled
+		}
+		if statusErr.StatusCode >= http.StatusInternalServerError {
+			return ClassServer
+		}
+		return ClassOther
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		switch {
+		case strings.Contains(code, "Throttl"), strings.Contains(code, "TooManyRequests"):
+			return ClassThrottled
+		case strings.Contains(code, "Timeout"):
+			return ClassTimeout
+		case strings.Contains(code, "Unavailable"), strings.Contains(code, "InternalServer"), apiErr.ErrorFault() == smithy.FaultServer:
+			return ClassServer
+		}
+		return ClassOther
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ClassTimeout
+		}
+		return ClassNetwork
+	}
+
+	return ClassOther
+}
+
+// Unsent reports whether err shows the request never reached the server:
+// the connection couldn't be made, or the request was turned away by
+// throttling before any work was done.
+func Unsent(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && Classify(apiErr) == ClassThrottled {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Retryable reports whether err falls in one of the policy's retryable
+// classes and, for a write, whether it was never processed.
+func (p Policy) Retryable(err error) bool {
+	if p.unsentOnly && !Unsent(err) {
+		return false
+	}
+	class := Classify(err)
+	for _, allowed := range p.RetryableClasses {
+		if allowed == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns a full-jitter exponential delay for the given attempt.
+func (p Policy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay << attempt
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error, the attempts
+// are exhausted, or ctx is done. The last error is returned unchanged.
+func (p Policy) Do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt == attempts-1 || !p.Retryable(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"operation": operation,
+			"attempt":   attempt + 1,
+			"class":     Classify(err),
+			"delay":     delay.String(),
+		}).WithError(err).Warn("Retrying failed operation")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,170 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"Nil Error", nil, ""},
+		{"Throttled Status", &StatusError{StatusCode: http.StatusTooManyRequests}, ClassThrottled},
+		{"Server Status", &StatusError{StatusCode: http.StatusBadGateway}, ClassServer},
+		{"Deadline Exceeded", context.DeadlineExceeded, ClassTimeout},
+		{"Network Timeout", timeoutError{}, ClassTimeout},
+		{"AWS Throttling", &smithy.GenericAPIError{Code: "ThrottlingException"}, ClassThrottled},
+		{"AWS Unavailable", &smithy.GenericAPIError{Code: "ServiceUnavailableError"}, ClassServer},
+		{"Plain Error", errors.New("bad input"), ClassOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Classify(test.err))
+		})
+	}
+}
+
+func TestUnsent(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Dial Failed", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"DNS Failed", &net.DNSError{Err: "no such host", Name: "pds.example.com"}, true},
+		{"Throttled Status", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"AWS Throttling", &smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{"Read Failed", &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, false},
+		{"Server Status", &StatusError{StatusCode: http.StatusBadGateway}, false},
+		{"Timeout", context.DeadlineExceeded, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Unsent(test.err))
+		})
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	policy := Policy{
+		MaxAttempts:      3,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         2 * time.Millisecond,
+		RetryableClasses: DefaultClasses,
+	}
+
+	tests := []struct {
+		name          string
+		policy        Policy
+		errs          []error
+		expectedCalls int
+		expectedErr   string
+	}{
+		{
+			name:          "Succeeds First Try",
+			policy:        policy,
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			name:          "Recovers After Server Error",
+			policy:        policy,
+			errs:          []error{&StatusError{StatusCode: 503}, nil},
+			expectedCalls: 2,
+		},
+		{
+			name:          "Gives Up After Max Attempts",
+			policy:        policy,
+			errs:          []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded},
+			expectedCalls: 3,
+			expectedErr:   "context deadline exceeded",
+		},
+		{
+			name:          "Does Not Retry Other Errors",
+			policy:        policy,
+			errs:          []error{errors.New("bad input")},
+			expectedCalls: 1,
+			expectedErr:   "bad input",
+		},
+		{
+			name:          "Class Not Configured",
+			policy:        Policy{MaxAttempts: 3, RetryableClasses: []ErrorClass{ClassThrottled}},
+			errs:          []error{&StatusError{StatusCode: 500}},
+			expectedCalls: 1,
+			expectedErr:   "unexpected status code: 500",
+		},
+		{
+			name:          "Write Not Retried After Server Error",
+			policy:        policy.ForWrite(),
+			errs:          []error{&StatusError{StatusCode: 503}},
+			expectedCalls: 1,
+			expectedErr:   "unexpected status code: 503",
+		},
+		{
+			name:          "Write Not Retried After Timeout",
+			policy:        policy.ForWrite(),
+			errs:          []error{context.DeadlineExceeded},
+			expectedCalls: 1,
+			expectedErr:   "context deadline exceeded",
+		},
+		{
+			name:          "Write Retried When Never Sent",
+			policy:        policy.ForWrite(),
+			errs:          []error{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, &StatusError{StatusCode: 429}, nil},
+			expectedCalls: 3,
+		},
+		{
+			name:          "Zero Policy Runs Once",
+			policy:        Policy{},
+			errs:          []error{&StatusError{StatusCode: 500}},
+			expectedCalls: 1,
+			expectedErr:   "unexpected status code: 500",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := test.policy.Do(context.Background(), "test", func(ctx context.Context) error {
+				err := test.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, test.expectedCalls, calls)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicyBackoffRespectsMaxDelay(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 250*time.Millisecond)
+	}
+}
@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const TurnstileEndpoint = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// CaptchaVerifier checks a captcha token the client solved.
+type CaptchaVerifier interface {
+	VerifyCaptcha(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// TurnstileClient verifies Cloudflare Turnstile tokens.
+type TurnstileClient struct {
+	Endpoint   string
+	Secret     string
+	HTTPClient HTTPClient
+}
+
+func NewTurnstileClient(secret string, client HTTPClient) *TurnstileClient {
+	return &TurnstileClient{Endpoint: TurnstileEndpoint, Secret: secret, HTTPClient: client}
+}
+
+type turnstileResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (c *TurnstileClient) VerifyCaptcha(ctx context.Context, token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {c.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("failed to create captcha request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code from captcha verification: %d", resp.StatusCode)
+	}
+
+	var result turnstileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode captcha verification response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,69 @@
+package risk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	DoFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.DoFunc(req)
+}
+
+func TestVerifyCaptcha(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		status        int
+		body          string
+		httpErr       error
+		expected      bool
+		expectedError string
+	}{
+		{name: "Solved", token: "token", status: http.StatusOK, body: `{"success":true}`, expected: true},
+		{name: "Rejected", token: "token", status: http.StatusOK, body: `{"success":false,"error-codes":["invalid-input-response"]}`},
+		{name: "No Token", token: ""},
+		{name: "Provider Error", token: "token", status: http.StatusInternalServerError, expectedError: "unexpected status code from captcha verification: 500"},
+		{name: "Request Failed", token: "token", httpErr: errors.New("timeout"), expectedError: "captcha verification request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			client := NewTurnstileClient("secret", &mockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					body, _ := io.ReadAll(req.Body)
+					form, _ = url.ParseQuery(string(body))
+					if tt.httpErr != nil {
+						return nil, tt.httpErr
+					}
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader([]byte(tt.body)))}, nil
+				},
+			})
+
+			solved, err := client.VerifyCaptcha(context.Background(), tt.token, "203.0.113.7")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, solved)
+			}
+			if tt.token != "" {
+				assert.Equal(t, "secret", form.Get("secret"))
+				assert.Equal(t, tt.token, form.Get("response"))
+				assert.Equal(t, "203.0.113.7", form.Get("remoteip"))
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package risk scores how likely a signup is to be abusive from its recent
+// velocity, the email provider and the history of failed attempts.
+package risk
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+)
+
+//go:embed disposable_domains.txt
+var disposableDomainsList string
+
+var disposableDomains = parseDomainList(disposableDomainsList)
+
+// commonProviders are large mailbox providers whose signup volume says
+// nothing about a single actor, so their domain velocity isn't scored.
+var commonProviders = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"yahoo.com":      true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"aol.com":        true,
+	"gmx.com":        true,
+}
+
+// Reasons reported with an assessment, for logs and review queues.
+const (
+	ReasonDisposableEmail = "disposable_email"
+	ReasonIPVelocity      = "ip_velocity"
+	ReasonDomainVelocity  = "domain_velocity"
+	ReasonFailedAttempts  = "failed_attempts"
+)
+
+// Velocity is the recent signup activity around one request.
+type Velocity struct {
+	// IPAttempts and IPFailures count signups from the request's IP.
+	IPAttempts int
+	IPFailures int
+	// DomainSignups counts successful signups with the same email domain.
+	DomainSignups int
+}
+
+// Signals are everything a request is scored on.
+type Signals struct {
+	EmailDomain string
+	Velocity    Velocity
+}
+
+// Limits are the activity levels above which a signal starts to count.
+type Limits struct {
+	IPAttempts    int
+	DomainSignups int
+}
+
+var DefaultLimits = Limits{IPAttempts: 3, DomainSignups: 20}
+
+// Assessment is a score from 0 to 100 and the signals that contributed.
+type Assessment struct {
+	Score   int
+	Reasons []string
+}
+
+// Score weighs signals against limits. Each signal's contribution is
+// capped, so it takes more than one to reach the top of the scale.
+func Score(signals Signals, limits Limits) Assessment {
+	var a Assessment
+	add := func(reason string, points int) {
+		if points > 0 {
+			a.Score += points
+			a.Reasons = append(a.Reasons, reason)
+		}
+	}
+
+	if IsDisposable(signals.EmailDomain) {
+		add(ReasonDisposableEmail, 40)
+	}
+	if excess := signals.Velocity.IPAttempts - limits.IPAttempts; excess > 0 {
+		add(ReasonIPVelocity, min(excess*10, 40))
+	}
+	if !IsCommonProvider(signals.EmailDomain) && limits.DomainSignups > 0 && signals.Velocity.DomainSignups >= limits.DomainSignups {
+		add(ReasonDomainVelocity, 30)
+	}
+	add(ReasonFailedAttempts, min(signals.Velocity.IPFailures*5, 30))
+
+	a.Score = min(a.Score, 100)
+	return a
+}
+
+// IsCommonProvider reports whether domain is a large mailbox provider.
+func IsCommonProvider(domain string) bool {
+	return commonProviders[strings.TrimSuffix(strings.ToLower(domain), ".")]
+}
+
+// IsDisposable reports whether domain, or a domain it is under, is a known
+// disposable email provider.
+func IsDisposable(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for domain != "" {
+		if disposableDomains[domain] {
+			return true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			return false
+		}
+		domain = parent
+	}
+	return false
+}
+
+// EmailDomain returns the lowercased domain of an email address.
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func parseDomainList(list string) map[string]bool {
+	domains := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[strings.ToLower(line)] = true
+	}
+	return domains
+}
@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		signals       Signals
+		expectedScore int
+		expectedWhy   []string
+	}{
+		{
+			name:    "Quiet Signup",
+			signals: Signals{EmailDomain: "example.com", Velocity: Velocity{IPAttempts: 1}},
+		},
+		{
+			name:          "Disposable Email",
+			signals:       Signals{EmailDomain: "mailinator.com"},
+			expectedScore: 40,
+			expectedWhy:   []string{ReasonDisposableEmail},
+		},
+		{
+			name:          "IP Velocity Capped",
+			signals:       Signals{EmailDomain: "example.com", Velocity: Velocity{IPAttempts: 50}},
+			expectedScore: 40,
+			expectedWhy:   []string{ReasonIPVelocity},
+		},
+		{
+			name:          "Obscure Domain Velocity",
+			signals:       Signals{EmailDomain: "bots.example", Velocity: Velocity{DomainSignups: 20}},
+			expectedScore: 30,
+			expectedWhy:   []string{ReasonDomainVelocity},
+		},
+		{
+			name:    "Common Provider Velocity Ignored",
+			signals: Signals{EmailDomain: "gmail.com", Velocity: Velocity{DomainSignups: 5000}},
+		},
+		{
+			name:          "Everything At Once",
+			signals:       Signals{EmailDomain: "mailinator.com", Velocity: Velocity{IPAttempts: 10, IPFailures: 10, DomainSignups: 30}},
+			expectedScore: 100,
+			expectedWhy:   []string{ReasonDisposableEmail, ReasonIPVelocity, ReasonDomainVelocity, ReasonFailedAttempts},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Score(tt.signals, DefaultLimits)
+
+			assert.Equal(t, tt.expectedScore, a.Score)
+			assert.Equal(t, tt.expectedWhy, a.Reasons)
+		})
+	}
+}
+
+func TestIsDisposable(t *testing.T) {
+	tests := []struct {
+		domain   string
+		expected bool
+	}{
+		{"mailinator.com", true},
+		{"MAILINATOR.COM", true},
+		{"eu.mailinator.com", true},
+		{"mailinator.com.", true},
+		{"example.com", false},
+		{"notmailinator.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsDisposable(tt.domain))
+		})
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	assert.Equal(t, "example.com", EmailDomain("Alice@Example.com"))
+	assert.Equal(t, "example.com", EmailDomain(`"a@b"@example.com`))
+	assert.Equal(t, "", EmailDomain("no-at-sign"))
+}
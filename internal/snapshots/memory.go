@@ -0,0 +1,43 @@
+package snapshots
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ShareFrame/user-management/config"
+)
+
+// NewMemoryStore returns a Store that keeps its snapshots in process, for
+// tests and local runs.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+type MemoryStore struct {
+	mu     sync.Mutex
+	latest config.Snapshot
+}
+
+func (s *MemoryStore) Latest(_ context.Context) (config.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copySnapshot(s.latest), nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, snapshot config.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = copySnapshot(snapshot)
+	return nil
+}
+
+func copySnapshot(snapshot config.Snapshot) config.Snapshot {
+	if snapshot == nil {
+		return nil
+	}
+	copied := make(config.Snapshot, len(snapshot))
+	for key, value := range snapshot {
+		copied[key] = value
+	}
+	return copied
+}
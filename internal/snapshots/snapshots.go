@@ -0,0 +1,114 @@
+// Package snapshots keeps the configuration snapshots each cold start
+// compares itself against, so drift between deployments is flagged.
+package snapshots
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDB attribute names. The table's partition key is pk (string) and
+// its sort key taken_at (number, Unix nanoseconds).
+const (
+	attrKey      = "pk"
+	attrTakenAt  = "taken_at"
+	attrSnapshot = "snapshot"
+)
+
+// partitionKey is the partition every snapshot is kept under, so the
+// latest is the first item of a descending query.
+const partitionKey = "config_snapshot"
+
+// Store keeps configuration snapshots.
+type Store interface {
+	// Latest returns the most recent snapshot, or nil before the first is
+	// stored.
+	Latest(ctx context.Context) (config.Snapshot, error)
+	Put(ctx context.Context, snapshot config.Snapshot) error
+}
+
+// DynamoDBAPI is the part of the DynamoDB client the store uses.
+type DynamoDBAPI interface {
+	PutItem(ctx context.Context, input *dynamodb.PutItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// NewDynamoStore returns a Store whose snapshots live in table.
+func NewDynamoStore(client DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{client: client, table: table, now: time.Now}
+}
+
+type DynamoStore struct {
+	client DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+func (s *DynamoStore) Latest(ctx context.Context) (config.Snapshot, error) {
+	ctx, seg := tracing.Begin(ctx, "DynamoDB", tracing.NamespaceAWS)
+	seg.SetAWS("Query")
+	result, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("#pk = :pk"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk": attrKey,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: partitionKey},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(1),
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyDynamoDB, err)
+		return nil, fmt.Errorf("failed to load config snapshot: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	raw, ok := result.Items[0][attrSnapshot].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("failed to load config snapshot: unexpected %s attribute", attrSnapshot)
+	}
+	var snapshot config.Snapshot
+	if err := json.Unmarshal([]byte(raw.Value), &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse config snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, snapshot config.Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config snapshot: %w", err)
+	}
+
+	ctx, seg := tracing.Begin(ctx, "DynamoDB", tracing.NamespaceAWS)
+	seg.SetAWS("PutItem")
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item: map[string]types.AttributeValue{
+			attrKey:      &types.AttributeValueMemberS{Value: partitionKey},
+			attrTakenAt:  &types.AttributeValueMemberN{Value: strconv.FormatInt(s.now().UnixNano(), 10)},
+			attrSnapshot: &types.AttributeValueMemberS{Value: string(data)},
+		},
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyDynamoDB, err)
+		return fmt.Errorf("failed to store config snapshot: %w", err)
+	}
+	return nil
+}
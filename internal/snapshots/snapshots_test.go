@@ -0,0 +1,106 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDynamoDB struct {
+	mock.Mock
+}
+
+func (m *mockDynamoDB) PutItem(ctx context.Context, input *dynamodb.PutItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(ctx, input)
+	return &dynamodb.PutItemOutput{}, args.Error(0)
+}
+
+func (m *mockDynamoDB) Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	args := m.Called(ctx, input)
+	output, _ := args.Get(0).(*dynamodb.QueryOutput)
+	return output, args.Error(1)
+}
+
+func TestDynamoStoreLatest(t *testing.T) {
+	latestQuery := mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		pk, _ := input.ExpressionAttributeValues[":pk"].(*types.AttributeValueMemberS)
+		return *input.TableName == "config-snapshots" && pk != nil && pk.Value == partitionKey &&
+			!*input.ScanIndexForward && *input.Limit == 1
+	})
+	item := func(snapshot string) *dynamodb.QueryOutput {
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{{attrSnapshot: &types.AttributeValueMemberS{Value: snapshot}}}}
+	}
+
+	tests := []struct {
+		name        string
+		output      *dynamodb.QueryOutput
+		queryErr    error
+		expected    config.Snapshot
+		expectedErr string
+	}{
+		{name: "Latest Snapshot", output: item(`{"atprotoBaseUrl":"https://pds.example.com"}`), expected: config.Snapshot{"atprotoBaseUrl": "https://pds.example.com"}},
+		{name: "No Snapshot Yet", output: &dynamodb.QueryOutput{}},
+		{name: "Query Fails", queryErr: errors.New("throttled"), expectedErr: "failed to load config snapshot"},
+		{name: "Unreadable Snapshot", output: item("{"), expectedErr: "failed to parse config snapshot"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &mockDynamoDB{}
+			client.On("Query", mock.Anything, latestQuery).Return(test.output, test.queryErr)
+
+			snapshot, err := NewDynamoStore(client, "config-snapshots").Latest(context.Background())
+
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, snapshot)
+		})
+	}
+}
+
+func TestDynamoStorePut(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	client := &mockDynamoDB{}
+	client.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		takenAt, _ := input.Item[attrTakenAt].(*types.AttributeValueMemberN)
+		snapshot, _ := input.Item[attrSnapshot].(*types.AttributeValueMemberS)
+		return *input.TableName == "config-snapshots" &&
+			takenAt != nil && takenAt.Value == "1767263400000000000" &&
+			snapshot != nil && snapshot.Value == `{"databaseName":"users"}`
+	})).Return(nil).Once()
+	client.On("PutItem", mock.Anything, mock.Anything).Return(errors.New("throttled")).Once()
+
+	store := NewDynamoStore(client, "config-snapshots")
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Put(context.Background(), config.Snapshot{"databaseName": "users"}))
+	assert.ErrorContains(t, store.Put(context.Background(), config.Snapshot{}), "failed to store config snapshot")
+	client.AssertExpectations(t)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	latest, err := store.Latest(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	snapshot := config.Snapshot{"databaseName": "users"}
+	assert.NoError(t, store.Put(ctx, snapshot))
+	snapshot["databaseName"] = "changed"
+
+	latest, err = store.Latest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, config.Snapshot{"databaseName": "users"}, latest)
+}
@@ -0,0 +1,359 @@
+// Package fakepds is an in-process PDS for tests. It serves the XRPC
+// methods the service calls during signup — createSession,
+// createInviteCode, createAccount and getProfile — and getSession, with
+// enough state to behave like the real thing: invite codes are used up,
+// taken handles are refused and unknown profiles come back as 400, as
+// Bluesky's PDS does.
+// Behaviors make a method fail or stall for the cases a live PDS can't be
+// made to produce on demand:
+//
+//	pds := fakepds.New()
+//	defer pds.Close()
+//	pds.AddAccount("taken.shareframe.social", "taken@example.com")
+//	pds.Set(fakepds.CreateAccount, fakepds.RateLimited(1))
+//	client := atproto.NewATProtocolClient(pds.URL, http.DefaultClient, policy)
+package fakepds
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XRPC methods the fake serves.
+const (
+	CreateSession    = "com.atproto.server.createSession"
+	CreateInviteCode = "com.atproto.server.createInviteCode"
+	CreateAccount    = "com.atproto.server.createAccount"
+	GetProfile       = "app.bsky.actor.getProfile"
+	GetSession       = "com.atproto.server.getSession"
+)
+
+// Default admin credentials, for models.AdminCreds in tests.
+const (
+	AdminUsername = "admin"
+	AdminPassword = "admin-password"
+)
+
+// Account is an account on the fake PDS.
+type Account struct {
+	DID      string
+	Handle   string
+	Email    string
+	Password string
+}
+
+// Behavior overrides how a method responds. Times limits it to the next
+// that many calls; zero means every call until it is cleared.
+type Behavior struct {
+	// Delay holds the response back, for timeouts.
+	Delay time.Duration
+	// Status, when set, is returned with an XRPC error body instead of
+	// handling the request.
+	Status  int
+	Error   string
+	Message string
+	Times   int
+}
+
+// RateLimited answers the next times calls with 429, as the PDS does when
+// a client is over its rate limit.
+func RateLimited(times int) Behavior {
+	return Behavior{Status: http.StatusTooManyRequests, Error: "RateLimitExceeded", Message: "Rate Limit Exceeded", Times: times}
+}
+
+// Unavailable answers the next times calls with 503.
+func Unavailable(times int) Behavior {
+	return Behavior{Status: http.StatusServiceUnavailable, Error: "InternalServerError", Message: "Service Unavailable", Times: times}
+}
+
+// Slow holds every response back by delay before handling it.
+func Slow(delay time.Duration) Behavior {
+	return Behavior{Delay: delay}
+}
+
+// Server is a running fake PDS. Its URL is the base URL for the client.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	accounts  map[string]*Account
+	invites   map[string]int
+	sessions  map[string]string
+	behaviors map[string]Behavior
+	calls     map[string]int
+	nextID    int
+}
+
+// New starts a fake PDS with no accounts. Close it when done.
+func New() *Server {
+	s := &Server{
+		accounts:  map[string]*Account{},
+		invites:   map[string]int{},
+		sessions:  map[string]string{},
+		behaviors: map[string]Behavior{},
+		calls:     map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// AddAccount creates an account directly, as if it had signed up earlier,
+// and returns it. Its password is "password".
+func (s *Server) AddAccount(handle, email string) Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addAccount(handle, email, "password", "")
+}
+
+// Account returns the account with handle.
+func (s *Server) Account(handle string) (Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[strings.ToLower(handle)]
+	if !ok {
+		return Account{}, false
+	}
+	return *account, true
+}
+
+// Set makes method respond with behavior until it is used up or cleared.
+func (s *Server) Set(method string, behavior Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.behaviors[method] = behavior
+}
+
+// Clear restores method's normal behavior.
+func (s *Server) Clear(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.behaviors, method)
+}
+
+// Calls counts the requests made to method, including failed ones.
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/xrpc/")
+	if behavior, ok := s.behavior(method); ok {
+		if behavior.Delay > 0 {
+			select {
+			case <-time.After(behavior.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if behavior.Status != 0 {
+			writeError(w, behavior.Status, behavior.Error, behavior.Message)
+			return
+		}
+	}
+
+	switch method {
+	case CreateSession:
+		s.createSession(w, r)
+	case CreateInviteCode:
+		s.createInviteCode(w, r)
+	case CreateAccount:
+		s.createAccount(w, r)
+	case GetProfile:
+		s.getProfile(w, r)
+	case GetSession:
+		s.getSession(w, r)
+	default:
+		writeError(w, http.StatusNotImplemented, "MethodNotImplemented", "Method Not Implemented")
+	}
+}
+
+// behavior counts the call to method and returns the behavior it uses up.
+func (s *Server) behavior(method string) (Behavior, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+
+	behavior, ok := s.behaviors[method]
+	if !ok {
+		return Behavior{}, false
+	}
+	if behavior.Times > 0 {
+		if behavior.Times == 1 {
+			delete(s.behaviors, method)
+		} else {
+			behavior.Times--
+			s.behaviors[method] = behavior
+		}
+	}
+	return behavior, true
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "InvalidRequest", "Method Not Allowed")
+		return
+	}
+	var input struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := s.find(input.Identifier)
+	if account == nil || subtle.ConstantTimeCompare([]byte(account.Password), []byte(input.Password)) != 1 {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+		return
+	}
+	writeJSON(w, s.session(account))
+}
+
+func (s *Server) createInviteCode(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok || username != AdminUsername || password != AdminPassword {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid admin credentials")
+		return
+	}
+	var input struct {
+		UseCount int `json:"useCount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.UseCount < 1 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Input/useCount must be a positive integer")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	code := fmt.Sprintf("fake-pds-%05d", s.nextID)
+	s.invites[code] = input.UseCount
+	writeJSON(w, map[string]string{"code": code})
+}
+
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Handle     string `json:"handle"`
+		Email      string `json:"email"`
+		Password   string `json:"password"`
+		InviteCode string `json:"inviteCode"`
+		DID        string `json:"did"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid JSON body")
+		return
+	}
+	if input.Handle == "" || !strings.Contains(input.Handle, ".") {
+		writeError(w, http.StatusBadRequest, "InvalidHandle", "Handle must be a valid domain name")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.invites[input.InviteCode] < 1 {
+		writeError(w, http.StatusBadRequest, "InvalidInviteCode", "Provided invite code not available")
+		return
+	}
+	if s.accounts[strings.ToLower(input.Handle)] != nil {
+		writeError(w, http.StatusBadRequest, "HandleNotAvailable", "Handle already taken: "+input.Handle)
+		return
+	}
+	if input.Email != "" && s.find(input.Email) != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Email already taken: "+input.Email)
+		return
+	}
+
+	s.invites[input.InviteCode]--
+	account := s.addAccount(input.Handle, input.Email, input.Password, input.DID)
+	writeJSON(w, s.session(account))
+}
+
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]; !ok {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Authentication Required")
+		return
+	}
+
+	actor := r.URL.Query().Get("actor")
+	account := s.find(actor)
+	if account == nil {
+		// The real PDS answers a missing profile with 400, not 404.
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+	writeJSON(w, map[string]string{"did": account.DID, "handle": account.Handle})
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	did, ok := s.sessions[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidToken", "Token could not be verified")
+		return
+	}
+	account := s.find(did)
+	writeJSON(w, map[string]string{"did": account.DID, "handle": account.Handle, "email": account.Email})
+}
+
+// addAccount stores a new account. The caller holds s.mu.
+func (s *Server) addAccount(handle, email, password, did string) *Account {
+	s.nextID++
+	if did == "" {
+		did = fmt.Sprintf("did:plc:fakepds%017d", s.nextID)
+	}
+	account := &Account{DID: did, Handle: strings.ToLower(handle), Email: email, Password: password}
+	s.accounts[account.Handle] = account
+	return account
+}
+
+// find looks an account up by handle, email or DID. The caller holds s.mu.
+func (s *Server) find(identifier string) *Account {
+	identifier = strings.ToLower(identifier)
+	if account, ok := s.accounts[identifier]; ok {
+		return account
+	}
+	for _, account := range s.accounts {
+		if strings.EqualFold(account.Email, identifier) || account.DID == identifier {
+			return account
+		}
+	}
+	return nil
+}
+
+// session opens a session for account. The caller holds s.mu.
+func (s *Server) session(account *Account) map[string]string {
+	s.nextID++
+	access := fmt.Sprintf("fake-access-%d", s.nextID)
+	s.sessions[access] = account.DID
+	return map[string]string{
+		"did":        account.DID,
+		"handle":     account.Handle,
+		"accessJwt":  access,
+		"refreshJwt": fmt.Sprintf("fake-refresh-%d", s.nextID),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, name, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": name, "message": message})
+}
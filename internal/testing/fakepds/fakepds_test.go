@@ -0,0 +1,143 @@
+package fakepds
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/apperr"
+	ATProtocol "github.com/ShareFrame/user-management/internal/atproto"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+var adminCreds = models.AdminCreds{PDSAdminUsername: AdminUsername, PDSAdminPassword: AdminPassword}
+
+func TestSignupFlow(t *testing.T) {
+	ctx := context.Background()
+	pds := New()
+	defer pds.Close()
+	pds.AddAccount("util.shareframe.social", "util@example.com")
+	pds.AddAccount("taken.shareframe.social", "taken@example.com")
+	client := ATProtocol.NewATProtocolClient(pds.URL, http.DefaultClient, retry.Policy{})
+
+	session, err := client.CreateSession(ctx, "util.shareframe.social", "password")
+	assert.NoError(t, err)
+
+	exists, err := client.CheckUserExists(ctx, "taken.shareframe.social", session.AccessJwt)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = client.CheckUserExists(ctx, "alice.shareframe.social", session.AccessJwt)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	invite, err := client.CreateInviteCode(ctx, adminCreds)
+	assert.NoError(t, err)
+
+	_, err = client.RegisterUser(ctx, "taken.shareframe.social", "alice@example.com", invite.Code, "hunter22", "")
+	assert.Equal(t, apperr.Upstream, apperr.CategoryOf(err))
+
+	user, err := client.RegisterUser(ctx, "alice.shareframe.social", "alice@example.com", invite.Code, "hunter22", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice.shareframe.social", user.Handle)
+	assert.NotEmpty(t, user.DID)
+	assert.NotEmpty(t, user.AccessJWT)
+
+	account, ok := pds.Account("alice.shareframe.social")
+	assert.True(t, ok)
+	assert.Equal(t, Account{DID: user.DID, Handle: "alice.shareframe.social", Email: "alice@example.com", Password: "hunter22"}, account)
+
+	// The invite code was single-use.
+	_, err = client.RegisterUser(ctx, "bob.shareframe.social", "bob@example.com", invite.Code, "hunter22", "")
+	assert.Error(t, err)
+	assert.Equal(t, 3, pds.Calls(CreateAccount))
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+	pds := New()
+	defer pds.Close()
+	pds.AddAccount("util.shareframe.social", "util@example.com")
+	client := ATProtocol.NewATProtocolClient(pds.URL, http.DefaultClient, retry.Policy{})
+
+	_, err := client.CreateSession(ctx, "util.shareframe.social", "wrong")
+	assert.Error(t, err)
+	_, err = client.CreateInviteCode(ctx, models.AdminCreds{PDSAdminUsername: AdminUsername, PDSAdminPassword: "wrong"})
+	assert.Error(t, err)
+	_, err = client.CheckUserExists(ctx, "util.shareframe.social", "not-a-token")
+	assert.Error(t, err)
+}
+
+func TestBehaviors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		behavior      Behavior
+		policy        retry.Policy
+		timeout       time.Duration
+		expectedCalls int
+		expectedErr   bool
+	}{
+		{
+			name:          "Rate Limited Then Retried",
+			behavior:      RateLimited(2),
+			policy:        retry.Policy{MaxAttempts: 3, RetryableClasses: retry.DefaultClasses},
+			expectedCalls: 3,
+		},
+		{
+			name:          "Rate Limited Past The Policy",
+			behavior:      RateLimited(0),
+			policy:        retry.Policy{MaxAttempts: 2, RetryableClasses: retry.DefaultClasses},
+			expectedCalls: 2,
+			expectedErr:   true,
+		},
+		{
+			name:          "Unavailable Write Not Retried",
+			behavior:      Unavailable(1),
+			policy:        retry.Policy{MaxAttempts: 2, RetryableClasses: retry.DefaultClasses},
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "Slow",
+			behavior:      Slow(200 * time.Millisecond),
+			timeout:       50 * time.Millisecond,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pds := New()
+			defer pds.Close()
+			pds.Set(CreateInviteCode, test.behavior)
+			httpClient := &http.Client{Timeout: test.timeout}
+			client := ATProtocol.NewATProtocolClient(pds.URL, httpClient, test.policy)
+
+			invite, err := client.CreateInviteCode(ctx, adminCreds)
+
+			assert.Equal(t, test.expectedCalls, pds.Calls(CreateInviteCode))
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, invite.Code)
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	pds := New()
+	defer pds.Close()
+	pds.Set(CreateInviteCode, RateLimited(0))
+	pds.Clear(CreateInviteCode)
+
+	invite, err := ATProtocol.NewATProtocolClient(pds.URL, http.DefaultClient, retry.Policy{}).CreateInviteCode(context.Background(), adminCreds)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, invite.Code)
+}
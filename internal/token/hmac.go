@@ -0,0 +1,44 @@
+package token
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+const AlgorithmHS256 = "HS256"
+
+// HMAC signs and verifies HS256 tokens with a shared secret.
+type HMAC struct {
+	keyID  string
+	secret []byte
+}
+
+func NewHMAC(keyID string, secret []byte) *HMAC {
+	return &HMAC{keyID: keyID, secret: secret}
+}
+
+func (h *HMAC) Algorithm() string {
+	return AlgorithmHS256
+}
+
+func (h *HMAC) KeyID() string {
+	return h.keyID
+}
+
+func (h *HMAC) Sign(_ context.Context, input []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
+
+func (h *HMAC) Verify(ctx context.Context, algorithm, keyID string, input, signature []byte) error {
+	if algorithm != AlgorithmHS256 || keyID != h.keyID {
+		return ErrSignature
+	}
+	expected, _ := h.Sign(ctx, input)
+	if !hmac.Equal(expected, signature) {
+		return ErrSignature
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	"github.com/ShareFrame/user-management/internal/metrics"
+	"github.com/ShareFrame/user-management/internal/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+const AlgorithmRS256 = "RS256"
+
+type KMSAPI interface {
+	Sign(ctx context.Context, input *kms.SignInput, opts ...func(*kms.Options)) (*kms.SignOutput, error)
+	GetPublicKey(ctx context.Context, input *kms.GetPublicKeyInput, opts ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error)
+}
+
+// KMS signs RS256 tokens with an asymmetric KMS key, so the private key
+// never leaves KMS, and verifies them locally with the public keys of the
+// keys it trusts. Keys are named by their KMS key ID, which is the token's
+// kid. To rotate, sign with a new key and keep trusting the old one until
+// its tokens have expired.
+type KMS struct {
+	client  KMSAPI
+	keyID   string
+	trusted map[string]bool
+	// publicKeys caches *rsa.PublicKey by key ID; public keys don't change.
+	publicKeys sync.Map
+}
+
+// NewKMS signs with keyID and verifies tokens signed with it or with any of
+// previousKeyIDs.
+func NewKMS(client KMSAPI, keyID string, previousKeyIDs ...string) *KMS {
+	k := &KMS{client: client, keyID: keyID, trusted: map[string]bool{keyID: true}}
+	for _, id := range previousKeyIDs {
+		k.trusted[id] = true
+	}
+	return k
+}
+
+func (k *KMS) Algorithm() string {
+	return AlgorithmRS256
+}
+
+func (k *KMS) KeyID() string {
+	return k.keyID
+}
+
+func (k *KMS) Sign(ctx context.Context, input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+
+	ctx, seg := tracing.Begin(ctx, "KMS", tracing.NamespaceAWS)
+	seg.SetAWS("Sign")
+	out, err := k.client.Sign(ctx, &kms.SignInput{
+		KeyId:            aws.String(k.keyID),
+		Message:          digest[:],
+		MessageType:      types.MessageTypeDigest,
+		SigningAlgorithm: types.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+	})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyKMS, err)
+		return nil, fmt.Errorf("failed to sign with KMS key %s: %w", k.keyID, err)
+	}
+	return out.Signature, nil
+}
+
+func (k *KMS) Verify(ctx context.Context, algorithm, keyID string, input, signature []byte) error {
+	if algorithm != AlgorithmRS256 || !k.trusted[keyID] {
+		return ErrSignature
+	}
+
+	publicKey, err := k.publicKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(input)
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return ErrSignature
+	}
+	return nil
+}
+
+func (k *KMS) publicKey(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	if cached, ok := k.publicKeys.Load(keyID); ok {
+		return cached.(*rsa.PublicKey), nil
+	}
+
+	ctx, seg := tracing.Begin(ctx, "KMS", tracing.NamespaceAWS)
+	seg.SetAWS("GetPublicKey")
+	out, err := k.client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+	seg.Close(err)
+	if err != nil {
+		metrics.DependencyFailed(metrics.FromContext(ctx), metrics.DependencyKMS, err)
+		return nil, fmt.Errorf("failed to get public key for KMS key %s: %w", keyID, err)
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(out.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key for KMS key %s: %w", keyID, err)
+	}
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("KMS key %s is not an RSA key", keyID)
+	}
+	k.publicKeys.Store(keyID, publicKey)
+	return publicKey, nil
+}
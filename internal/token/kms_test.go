@@ -0,0 +1,110 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKMS holds RSA keys by key ID and counts public key lookups.
+type fakeKMS struct {
+	keys    map[string]*rsa.PrivateKey
+	lookups int
+}
+
+func (f *fakeKMS) Sign(ctx context.Context, input *kms.SignInput, opts ...func(*kms.Options)) (*kms.SignOutput, error) {
+	key, ok := f.keys[aws.ToString(input.KeyId)]
+	if !ok {
+		return nil, errors.New("NotFoundException")
+	}
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, input.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.SignOutput{Signature: signature}, nil
+}
+
+func (f *fakeKMS) GetPublicKey(ctx context.Context, input *kms.GetPublicKeyInput, opts ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	f.lookups++
+	key, ok := f.keys[aws.ToString(input.KeyId)]
+	if !ok {
+		return nil, errors.New("NotFoundException")
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GetPublicKeyOutput{PublicKey: der}, nil
+}
+
+func newFakeKMS(t *testing.T, keyIDs ...string) *fakeKMS {
+	f := &fakeKMS{keys: map[string]*rsa.PrivateKey{}}
+	for _, id := range keyIDs {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+		f.keys[id] = key
+	}
+	return f
+}
+
+func TestKMSRotation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	client := newFakeKMS(t, "key-2026-09", "key-2026-10", "key-other")
+
+	claims := SignupClaims{Registered: NewRegistered(PurposeEmailVerification, "did:plc:abc", now, time.Hour)}
+	signedOld, err := Encode(ctx, NewKMS(client, "key-2026-09"), claims)
+	assert.NoError(t, err)
+	signedNew, err := Encode(ctx, NewKMS(client, "key-2026-10"), claims)
+	assert.NoError(t, err)
+	signedOther, err := Encode(ctx, NewKMS(client, "key-other"), claims)
+	assert.NoError(t, err)
+
+	verifier := NewKMS(client, "key-2026-10", "key-2026-09")
+
+	tests := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"Current Key", signedNew, nil},
+		{"Previous Key", signedOld, nil},
+		{"Untrusted Key", signedOther, ErrSignature},
+		{"HMAC Token", mustEncode(t, NewHMAC("key-2026-10", []byte("secret")), claims), ErrSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded SignupClaims
+			err := Decode(ctx, verifier, tt.token, PurposeEmailVerification, now, &decoded)
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "did:plc:abc", decoded.Subject)
+		})
+	}
+
+	assert.Equal(t, 2, client.lookups, "public keys are fetched once per trusted key")
+}
+
+func TestKMSSignFailure(t *testing.T) {
+	_, err := NewKMS(newFakeKMS(t), "missing").Sign(context.Background(), []byte("input"))
+	assert.ErrorContains(t, err, "failed to sign with KMS key missing")
+}
+
+func mustEncode(t *testing.T, signer Signer, claims interface{}) string {
+	signed, err := Encode(context.Background(), signer, claims)
+	assert.NoError(t, err)
+	return signed
+}
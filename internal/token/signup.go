@@ -0,0 +1,10 @@
+package token
+
+// SignupClaims attest the result of one signup: the subject is the new
+// account's DID.
+type SignupClaims struct {
+	Registered
+	Handle   string `json:"handle"`
+	Tenant   string `json:"tenant"`
+	Verified bool   `json:"verified"`
+}
@@ -0,0 +1,148 @@
+// Package token issues and checks the compact JWTs the service hands out,
+// so other ShareFrame services can trust what a token says without asking
+// this one. Tokens carry the signing key's ID in their header, so keys can
+// be rotated while tokens signed with the previous one are still checked.
+package token
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Issuer is the iss claim of every token the service signs.
+const Issuer = "shareframe"
+
+// Purposes a token can be issued for. Decode rejects a token issued for
+// any other purpose, so a token can't be replayed somewhere it wasn't
+// meant for, such as a signup token used to confirm an account deletion.
+const (
+	PurposeSignup            = "signup"
+	PurposeEmailVerification = "email_verification"
+	PurposeAccountDeletion   = "account_deletion"
+)
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("token signature is invalid")
+	ErrExpired   = errors.New("token has expired")
+	ErrPurpose   = errors.New("token was issued for another purpose")
+)
+
+// Signer signs a token's header and payload.
+type Signer interface {
+	// Algorithm is the JWS alg the signatures use, e.g. HS256.
+	Algorithm() string
+	KeyID() string
+	Sign(ctx context.Context, input []byte) ([]byte, error)
+}
+
+// Verifier checks a signature made with the key named by keyID.
+type Verifier interface {
+	Verify(ctx context.Context, algorithm, keyID string, input, signature []byte) error
+}
+
+// Key signs tokens and verifies the ones it signed, such as HMAC and KMS.
+type Key interface {
+	Signer
+	Verifier
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Registered holds the standard claims the service sets on every token.
+// Claim types embed it.
+type Registered struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Purpose   string `json:"purpose"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewRegistered returns the standard claims for a token about subject, for
+// purpose, that is valid for ttl from now.
+func NewRegistered(purpose, subject string, now time.Time, ttl time.Duration) Registered {
+	return Registered{
+		Issuer:    Issuer,
+		Subject:   subject,
+		Purpose:   purpose,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+}
+
+// Encode signs claims into a compact JWT.
+func Encode(ctx context.Context, signer Signer, claims interface{}) (string, error) {
+	h, err := json.Marshal(header{Algorithm: signer.Algorithm(), Type: "JWT", KeyID: signer.KeyID()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token header: %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	input := encodeSegment(h) + "." + encodeSegment(payload)
+	signature, err := signer.Sign(ctx, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return input + "." + encodeSegment(signature), nil
+}
+
+// Decode checks token's signature, purpose and expiry at now and
+// unmarshals its payload into claims.
+func Decode(ctx context.Context, verifier Verifier, token, purpose string, now time.Time, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := verifier.Verify(ctx, h.Algorithm, h.KeyID, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return err
+	}
+
+	var registered Registered
+	if err := decodeSegment(parts[1], &registered); err != nil {
+		return err
+	}
+	if registered.Purpose != purpose {
+		return ErrPurpose
+	}
+	if now.Unix() >= registered.ExpiresAt {
+		return ErrExpired
+	}
+	return decodeSegment(parts[1], claims)
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	key := NewHMAC("2026-10", []byte("secret"))
+
+	signed, err := Encode(ctx, key, SignupClaims{
+		Registered: NewRegistered(PurposeSignup, "did:plc:abc", now, 5*time.Minute),
+		Handle:     "alice.shareframe.social",
+		Tenant:     "shareframe",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(signed, "."), 3)
+
+	var claims SignupClaims
+	assert.NoError(t, Decode(ctx, key, signed, PurposeSignup, now.Add(time.Minute), &claims))
+	assert.Equal(t, SignupClaims{
+		Registered: Registered{Issuer: Issuer, Subject: "did:plc:abc", Purpose: PurposeSignup, IssuedAt: 1700000000, ExpiresAt: 1700000300},
+		Handle:     "alice.shareframe.social",
+		Tenant:     "shareframe",
+	}, claims)
+}
+
+func TestDecodeRejects(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	key := NewHMAC("2026-10", []byte("secret"))
+
+	signed, err := Encode(ctx, key, SignupClaims{Registered: NewRegistered(PurposeSignup, "did:plc:abc", now, time.Minute)})
+	assert.NoError(t, err)
+	parts := strings.Split(signed, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		verifier Verifier
+		purpose  string
+		now      time.Time
+		expected error
+	}{
+		{"Expired", signed, key, PurposeSignup, now.Add(time.Minute), ErrExpired},
+		{"Other Purpose", signed, key, PurposeAccountDeletion, now, ErrPurpose},
+		{"Other Secret", signed, NewHMAC("2026-10", []byte("other")), PurposeSignup, now, ErrSignature},
+		{"Rotated Key", signed, NewHMAC("2026-11", []byte("secret")), PurposeSignup, now, ErrSignature},
+		{"Tampered Claims", parts[0] + "." + encodeSegment([]byte(`{"sub":"did:plc:evil","purpose":"signup","exp":9999999999}`)) + "." + parts[2], key, PurposeSignup, now, ErrSignature},
+		{"Malformed", "not-a-token", key, PurposeSignup, now, ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims SignupClaims
+			assert.ErrorIs(t, Decode(ctx, tt.verifier, tt.token, tt.purpose, tt.now, &claims), tt.expected)
+		})
+	}
+}
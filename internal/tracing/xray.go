@@ -0,0 +1,256 @@
+// Package tracing records AWS X-Ray subsegments for calls to the database,
+// Secrets Manager and the PDS, so the Lambda service map shows those
+// dependencies with their latency and error rates.
+//
+// Lambda opens the segment for each invocation and passes its trace header
+// on the context; subsegments are sent to the X-Ray daemon Lambda runs
+// alongside the function. This avoids the X-Ray SDK and its gRPC
+// dependencies for the handful of calls the service makes.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+)
+
+const (
+	DaemonAddressEnv = "AWS_XRAY_DAEMON_ADDRESS"
+	// lambdaTraceKey is the context key aws-lambda-go stores the trace
+	// header under.
+	lambdaTraceKey = "x-amzn-trace-id"
+
+	NamespaceAWS    = "aws"
+	NamespaceRemote = "remote"
+
+	daemonHeader = "{\"format\": \"json\", \"version\": 1}\n"
+)
+
+// Tracer sends finished subsegments to the X-Ray daemon.
+type Tracer struct {
+	out io.Writer
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+func NewTracer(out io.Writer) *Tracer {
+	return &Tracer{out: out, now: time.Now}
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultTracer *Tracer
+)
+
+// Default returns a tracer for the daemon named by AWS_XRAY_DAEMON_ADDRESS,
+// or nil when the variable isn't set, as when running outside Lambda.
+func Default() *Tracer {
+	defaultOnce.Do(func() {
+		addr := os.Getenv(DaemonAddressEnv)
+		if addr == "" {
+			return
+		}
+		// The address may also be in the "tcp:host:port udp:host:port" form
+		// the X-Ray SDKs accept; subsegments go over UDP.
+		for _, part := range strings.Fields(addr) {
+			if strings.HasPrefix(part, "udp:") {
+				addr = strings.TrimPrefix(part, "udp:")
+			}
+		}
+		conn, err := net.Dial("udp", addr)
+		if err != nil {
+			logging.Default().WithError(err).Warn("Failed to connect to the X-Ray daemon; tracing disabled")
+			return
+		}
+		defaultTracer = NewTracer(conn)
+	})
+	return defaultTracer
+}
+
+type tracerKey struct{}
+type parentKey struct{}
+
+// WithTracer returns a context whose subsegments are sent by t instead of
+// the default tracer.
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+func tracerFrom(ctx context.Context) *Tracer {
+	if t, ok := ctx.Value(tracerKey{}).(*Tracer); ok {
+		return t
+	}
+	return Default()
+}
+
+// traceHeader holds the fields of an X-Amzn-Trace-Id header that matter
+// here.
+type traceHeader struct {
+	root    string
+	parent  string
+	sampled bool
+}
+
+func parseTraceHeader(header string) traceHeader {
+	var h traceHeader
+	for _, part := range strings.Split(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Root":
+			h.root = value
+		case "Parent":
+			h.parent = value
+		case "Sampled":
+			h.sampled = value == "1"
+		}
+	}
+	return h
+}
+
+// Subsegment is one timed downstream call. A nil *Subsegment, returned when
+// the request isn't traced, ignores every call.
+type Subsegment struct {
+	tracer    *Tracer
+	Name      string            `json:"name"`
+	ID        string            `json:"id"`
+	TraceID   string            `json:"trace_id"`
+	ParentID  string            `json:"parent_id"`
+	Type      string            `json:"type"`
+	Namespace string            `json:"namespace,omitempty"`
+	StartTime float64           `json:"start_time"`
+	EndTime   float64           `json:"end_time,omitempty"`
+	Error     bool              `json:"error,omitempty"`
+	Fault     bool              `json:"fault,omitempty"`
+	Throttle  bool              `json:"throttle,omitempty"`
+	HTTP      *httpInfo         `json:"http,omitempty"`
+	AWS       map[string]string `json:"aws,omitempty"`
+}
+
+type httpInfo struct {
+	Request  httpRequest   `json:"request"`
+	Response *httpResponse `json:"response,omitempty"`
+}
+
+type httpRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type httpResponse struct {
+	Status int `json:"status"`
+}
+
+// Begin starts a subsegment under the current segment or subsegment in
+// ctx. The returned context parents any nested subsegments to it.
+func Begin(ctx context.Context, name, namespace string) (context.Context, *Subsegment) {
+	tracer := tracerFrom(ctx)
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	header, _ := ctx.Value(lambdaTraceKey).(string)
+	trace := parseTraceHeader(header)
+	if trace.root == "" || !trace.sampled {
+		return ctx, nil
+	}
+
+	parent := trace.parent
+	if p, ok := ctx.Value(parentKey{}).(string); ok {
+		parent = p
+	}
+
+	s := &Subsegment{
+		tracer:    tracer,
+		Name:      name,
+		ID:        newID(),
+		TraceID:   trace.root,
+		ParentID:  parent,
+		Type:      "subsegment",
+		Namespace: namespace,
+		StartTime: epochSeconds(tracer.now()),
+	}
+	return context.WithValue(ctx, parentKey{}, s.ID), s
+}
+
+// SetAWS records the AWS operation called, shown on the service map node.
+func (s *Subsegment) SetAWS(operation string) {
+	if s == nil {
+		return
+	}
+	s.AWS = map[string]string{"operation": operation}
+}
+
+// SetHTTP records the request. The query string is dropped since it can
+// carry handles.
+func (s *Subsegment) SetHTTP(method, url string) {
+	if s == nil {
+		return
+	}
+	url, _, _ = strings.Cut(url, "?")
+	s.HTTP = &httpInfo{Request: httpRequest{Method: method, URL: url}}
+}
+
+// SetStatus records the response status and classifies it the way X-Ray
+// does: 429 is a throttle, other 4xx an error, 5xx a fault.
+func (s *Subsegment) SetStatus(status int) {
+	if s == nil || s.HTTP == nil {
+		return
+	}
+	s.HTTP.Response = &httpResponse{Status: status}
+	switch {
+	case status == 429:
+		s.Error, s.Throttle = true, true
+	case status >= 500:
+		s.Fault = true
+	case status >= 400:
+		s.Error = true
+	}
+}
+
+// Close ends the subsegment, marking it faulted if err is non-nil, and
+// sends it to the daemon.
+func (s *Subsegment) Close(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.Fault = true
+	}
+	s.EndTime = epochSeconds(s.tracer.now())
+	s.tracer.send(s)
+}
+
+func (t *Tracer) send(s *Subsegment) {
+	doc, err := json.Marshal(s)
+	if err != nil {
+		logging.Default().WithError(err).Warn("Failed to encode X-Ray subsegment")
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := t.out.Write(append([]byte(daemonHeader), doc...)); err != nil {
+		logging.Default().WithError(err).Warn("Failed to send X-Ray subsegment")
+	}
+}
+
+func newID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		logging.Default().WithError(err).Warn("Failed to generate X-Ray subsegment id")
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func epochSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
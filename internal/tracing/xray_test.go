@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHeader = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+
+// packetWriter records each write as one UDP packet.
+type packetWriter struct {
+	packets []string
+}
+
+func (w *packetWriter) Write(p []byte) (int, error) {
+	w.packets = append(w.packets, string(p))
+	return len(p), nil
+}
+
+func tracedContext(out *packetWriter) context.Context {
+	tracer := NewTracer(out)
+	tracer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	ctx := WithTracer(context.Background(), tracer)
+	return context.WithValue(ctx, lambdaTraceKey, testHeader)
+}
+
+func decodeSubsegment(t *testing.T, packet string) Subsegment {
+	assert.True(t, strings.HasPrefix(packet, daemonHeader))
+	var doc Subsegment
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(packet, daemonHeader)), &doc))
+	return doc
+}
+
+func TestSubsegmentSentToDaemon(t *testing.T) {
+	var out packetWriter
+	ctx := tracedContext(&out)
+
+	_, seg := Begin(ctx, "RDSDataService", NamespaceAWS)
+	seg.SetAWS("ExecuteStatement")
+	seg.Close(nil)
+
+	assert.Len(t, out.packets, 1)
+	doc := decodeSubsegment(t, out.packets[0])
+	assert.Equal(t, "RDSDataService", doc.Name)
+	assert.Equal(t, "1-5759e988-bd862e3fe1be46a994272793", doc.TraceID)
+	assert.Equal(t, "53995c3f42cd8ad8", doc.ParentID)
+	assert.Equal(t, "subsegment", doc.Type)
+	assert.Equal(t, NamespaceAWS, doc.Namespace)
+	assert.Len(t, doc.ID, 16)
+	assert.Equal(t, float64(1700000000), doc.EndTime)
+	assert.Equal(t, map[string]string{"operation": "ExecuteStatement"}, doc.AWS)
+	assert.False(t, doc.Fault)
+}
+
+func TestNestedSubsegmentsAreParented(t *testing.T) {
+	var out packetWriter
+	ctx := tracedContext(&out)
+
+	outerCtx, outer := Begin(ctx, "outer", "")
+	_, inner := Begin(outerCtx, "inner", "")
+	inner.Close(nil)
+	outer.Close(nil)
+
+	assert.Equal(t, outer.ID, inner.ParentID)
+	assert.Equal(t, "53995c3f42cd8ad8", outer.ParentID)
+}
+
+func TestSubsegmentHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		err      error
+		error    bool
+		fault    bool
+		throttle bool
+	}{
+		{name: "Success", status: 200},
+		{name: "Client Error", status: 400, error: true},
+		{name: "Throttled", status: 429, error: true, throttle: true},
+		{name: "Server Error", status: 502, fault: true},
+		{name: "Transport Error", err: errors.New("connection refused"), fault: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out packetWriter
+			_, seg := Begin(tracedContext(&out), "pds.example.com", NamespaceRemote)
+			seg.SetHTTP("POST", "https://pds.example.com/xrpc/com.atproto.identity.resolveHandle?handle=alice")
+			if test.status != 0 {
+				seg.SetStatus(test.status)
+			}
+			seg.Close(test.err)
+
+			assert.Len(t, out.packets, 1)
+			doc := decodeSubsegment(t, out.packets[0])
+			assert.Equal(t, test.error, doc.Error)
+			assert.Equal(t, test.fault, doc.Fault)
+			assert.Equal(t, test.throttle, doc.Throttle)
+			assert.Equal(t, "https://pds.example.com/xrpc/com.atproto.identity.resolveHandle", doc.HTTP.Request.URL)
+		})
+	}
+}
+
+func TestUntracedRequestsAreIgnored(t *testing.T) {
+	var out packetWriter
+	tracer := NewTracer(&out)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"No Trace Header", WithTracer(context.Background(), tracer)},
+		{"Not Sampled", context.WithValue(WithTracer(context.Background(), tracer), lambdaTraceKey, "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=0")},
+		{"No Tracer", context.WithValue(WithTracer(context.Background(), nil), lambdaTraceKey, testHeader)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, seg := Begin(test.ctx, "RDSDataService", NamespaceAWS)
+			assert.Nil(t, seg)
+			assert.Equal(t, test.ctx, ctx)
+			assert.NotPanics(t, func() {
+				seg.SetAWS("ExecuteStatement")
+				seg.SetHTTP("GET", "https://example.com")
+				seg.SetStatus(200)
+				seg.Close(nil)
+			})
+		})
+	}
+	assert.Empty(t, out.packets)
+}
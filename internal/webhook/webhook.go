@@ -0,0 +1,176 @@
+// Package webhook delivers account lifecycle events to partner endpoints.
+// Each delivery is signed with the endpoint's secret so partners can verify
+// it came from us and wasn't replayed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/postgres"
+	"github.com/ShareFrame/user-management/internal/retry"
+)
+
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>". The
+	// HMAC covers "<t>.<body>", so partners should reject old timestamps.
+	SignatureHeader = "X-ShareFrame-Signature"
+	EventHeader     = "X-ShareFrame-Event"
+	// EventIDHeader lets partners drop an event they've already handled.
+	EventIDHeader = "X-ShareFrame-Event-Id"
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Endpoint is a partner URL with the secret its deliveries are signed with.
+type Endpoint struct {
+	Name   string
+	URL    string
+	Secret string
+	// Events limits the endpoint to these events; empty means all of them.
+	Events []string
+}
+
+func (e Endpoint) subscribed(event string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, name := range e.Events {
+		if name == event {
+			return true
+		}
+	}
+	return false
+}
+
+type Dispatcher struct {
+	HTTPClient HTTPClient
+	Endpoints  []Endpoint
+	Retry      retry.Policy
+	Deliveries postgres.WebhookDeliveryLog
+	now        func() time.Time
+}
+
+func NewDispatcher(client HTTPClient, endpoints []Endpoint, retryPolicy retry.Policy, deliveries postgres.WebhookDeliveryLog) *Dispatcher {
+	return &Dispatcher{
+		HTTPClient: client,
+		Endpoints:  endpoints,
+		Retry:      retryPolicy,
+		Deliveries: deliveries,
+		now:        time.Now,
+	}
+}
+
+// Publish delivers event to every endpoint subscribed to it and logs each
+// delivery. It returns the failed deliveries' errors joined together.
+func (d *Dispatcher) Publish(ctx context.Context, event models.AccountEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = d.now().UTC()
+	}
+	if event.RequestID == "" {
+		event.RequestID = logging.RequestID(ctx)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	var errs []error
+	for _, endpoint := range d.Endpoints {
+		if !endpoint.subscribed(event.Event) {
+			continue
+		}
+
+		delivery := d.deliver(ctx, endpoint, event, body)
+		delivery.DID = event.DID
+
+		if err := d.Deliveries.RecordWebhookDelivery(ctx, delivery); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("endpoint", endpoint.Name).Warn("Webhook delivery not logged")
+		}
+		if !delivery.Delivered {
+			errs = append(errs, fmt.Errorf("webhook %s: %s", endpoint.Name, delivery.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver posts body to endpoint under the retry policy. Every attempt is
+// signed afresh so its timestamp is current.
+func (d *Dispatcher) deliver(ctx context.Context, endpoint Endpoint, event models.AccountEvent, body []byte) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{Endpoint: endpoint.Name, Event: event.Event}
+
+	err := d.Retry.Do(ctx, "webhook."+endpoint.Name, func(ctx context.Context) error {
+		delivery.Attempts++
+		status, err := d.post(ctx, endpoint, event, body)
+		delivery.StatusCode = status
+		return err
+	})
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"endpoint": endpoint.Name,
+		"event":    event.Event,
+		"attempts": delivery.Attempts,
+	})
+	if err != nil {
+		delivery.Error = err.Error()
+		log.WithError(err).Error("Failed to deliver webhook")
+		return delivery
+	}
+
+	delivery.Delivered = true
+	log.Info("Webhook delivered")
+	return delivery
+}
+
+func (d *Dispatcher) post(ctx context.Context, endpoint Endpoint, event models.AccountEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Event)
+	if event.ID != "" {
+		req.Header.Set(EventIDHeader, event.ID)
+	}
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, d.now(), body))
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if retry.IsRetryableStatus(resp.StatusCode) {
+		return resp.StatusCode, &retry.StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code from webhook endpoint: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
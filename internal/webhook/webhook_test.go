@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShareFrame/user-management/internal/models"
+	"github.com/ShareFrame/user-management/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	responses []*http.Response
+	errs      []error
+	requests  []*http.Request
+	bodies    [][]byte
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	i := len(m.requests)
+	m.requests = append(m.requests, req)
+	body, _ := io.ReadAll(req.Body)
+	m.bodies = append(m.bodies, body)
+
+	var err error
+	if i < len(m.errs) {
+		err = m.errs[i]
+	}
+	var resp *http.Response
+	if i < len(m.responses) {
+		resp = m.responses[i]
+	}
+	return resp, err
+}
+
+type recordedDeliveries struct {
+	deliveries []models.WebhookDelivery
+	err        error
+}
+
+func (r *recordedDeliveries) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	r.deliveries = append(r.deliveries, delivery)
+	return r.err
+}
+
+func response(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(nil))}
+}
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	signature := Sign("secret", at, []byte(`{"event":"account.created"}`))
+
+	assert.Equal(t, "t=1700000000,v1=d4a71b297914e2582c3dc4128b1128b7732c6396a1c2d49aed2e67566aefaec2", signature)
+	assert.NotEqual(t, signature, Sign("other", at, []byte(`{"event":"account.created"}`)))
+	assert.NotEqual(t, signature, Sign("secret", at.Add(time.Second), []byte(`{"event":"account.created"}`)))
+}
+
+func TestPublish(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := models.NewAccountEvent(models.EventAccountCreated, "did:example:123", "alice.shareframe.social", "")
+	policy := retry.Policy{MaxAttempts: 3, RetryableClasses: retry.DefaultClasses}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name           string
+		endpoints      []Endpoint
+		responses      []*http.Response
+		errs           []error
+		wantRequests   int
+		wantDeliveries []models.WebhookDelivery
+		wantErr        string
+	}{
+		{
+			name:         "Delivered First Time",
+			endpoints:    []Endpoint{{Name: "analytics", URL: "https://hooks.example.com", Secret: "secret"}},
+			responses:    []*http.Response{response(http.StatusNoContent)},
+			wantRequests: 1,
+			wantDeliveries: []models.WebhookDelivery{
+				{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 1, StatusCode: http.StatusNoContent, Delivered: true},
+			},
+		},
+		{
+			name:         "Retried After Server Error",
+			endpoints:    []Endpoint{{Name: "analytics", URL: "https://hooks.example.com", Secret: "secret"}},
+			responses:    []*http.Response{response(http.StatusBadGateway), response(http.StatusOK)},
+			wantRequests: 2,
+			wantDeliveries: []models.WebhookDelivery{
+				{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 2, StatusCode: http.StatusOK, Delivered: true},
+			},
+		},
+		{
+			name:         "Rejected Without Retry",
+			endpoints:    []Endpoint{{Name: "analytics", URL: "https://hooks.example.com", Secret: "secret"}},
+			responses:    []*http.Response{response(http.StatusBadRequest)},
+			wantRequests: 1,
+			wantDeliveries: []models.WebhookDelivery{
+				{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 1, StatusCode: http.StatusBadRequest, Error: "unexpected status code from webhook endpoint: 400"},
+			},
+			wantErr: "webhook analytics: unexpected status code from webhook endpoint: 400",
+		},
+		{
+			name:         "Connection Failures Exhaust Retries",
+			endpoints:    []Endpoint{{Name: "analytics", URL: "https://hooks.example.com", Secret: "secret"}},
+			errs:         []error{refused, refused, refused},
+			wantRequests: 3,
+			wantDeliveries: []models.WebhookDelivery{
+				{Endpoint: "analytics", Event: models.EventAccountCreated, DID: "did:example:123", Attempts: 3, Error: "webhook request failed: dial tcp: connection refused"},
+			},
+			wantErr: "webhook analytics: webhook request failed: dial tcp: connection refused",
+		},
+		{
+			name:      "Endpoint Not Subscribed",
+			endpoints: []Endpoint{{Name: "crm", URL: "https://crm.example.com", Secret: "secret", Events: []string{models.EventAccountDeleted}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockHTTPClient{responses: tt.responses, errs: tt.errs}
+			deliveries := &recordedDeliveries{}
+			dispatcher := NewDispatcher(client, tt.endpoints, policy, deliveries)
+			dispatcher.now = func() time.Time { return now }
+
+			err := dispatcher.Publish(context.Background(), event)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, client.requests, tt.wantRequests)
+			assert.Equal(t, tt.wantDeliveries, deliveries.deliveries)
+			for i, req := range client.requests {
+				assert.Equal(t, models.EventAccountCreated, req.Header.Get(EventHeader))
+				assert.Equal(t, event.ID, req.Header.Get(EventIDHeader))
+				assert.Equal(t, Sign("secret", now, client.bodies[i]), req.Header.Get(SignatureHeader))
+			}
+		})
+	}
+}
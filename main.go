@@ -2,22 +2,71 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
 
-	"github.com/ShareFrame/user-management/internal/handlers"
+	appconfig "github.com/ShareFrame/user-management/config"
+	"github.com/ShareFrame/user-management/internal/app"
+	"github.com/ShareFrame/user-management/internal/lambdahttp"
+	"github.com/ShareFrame/user-management/internal/logging"
+	"github.com/ShareFrame/user-management/internal/metrics"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	awsCfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		panic("Failed to load AWS config: " + err.Error())
+	port := flag.Int("port", 0, "serve the handler over HTTP on this port instead of the Lambda runtime")
+	backend := flag.String("backend", "", "storage backend to use: postgres (default) or memory")
+	configFile := flag.String("config", "", "path to a JSON file of environment settings")
+	admin := flag.Bool("admin", false, "with -port, also serve the unauthenticated /admin routes; only for servers unreachable from outside")
+	handlerName := flag.String("handler", os.Getenv("APP_HANDLER"), "Lambda handler to start: users (default), blocklist, dlq, email-queue, privacy, crm, stats, referrals, claims, lifecycle, phone, review, bots, avatars, availability or admin")
+	integration := flag.String("integration", os.Getenv("LAMBDA_INTEGRATION"), "how the Lambda handler is invoked: auto (default), direct, apigateway, httpapi or url")
+	flag.Parse()
+
+	if *configFile != "" {
+		if err := appconfig.LoadEnvFile(*configFile); err != nil {
+			panic("Failed to load config file: " + err.Error())
+		}
+	}
+	if *backend != "" {
+		os.Setenv("STORAGE_BACKEND", *backend)
 	}
 
-	secretsManagerClient := secretsmanager.NewFromConfig(awsCfg)
+	if err := logging.Configure(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_DEBUG_SAMPLE_RATE")); err != nil {
+		panic("Invalid logging configuration: " + err.Error())
+	}
+	if err := logging.ConfigureBackend(os.Getenv("LOG_BACKEND")); err != nil {
+		panic("Invalid logging configuration: " + err.Error())
+	}
+	if err := metrics.Configure(os.Getenv("METRICS_BACKEND"), os.Getenv("METRICS_NAMESPACE"), os.Getenv("METRICS_STATSD_ADDRESS")); err != nil {
+		panic("Invalid metrics configuration: " + err.Error())
+	}
+	logging.InstallHooks(os.Getenv("LOG_REDACT_FIELDS"), os.Getenv("LOG_REDACT_ALLOW"))
 
-	userHandler := handlers.NewUserHandler(secretsManagerClient)
+	container, err := app.New(context.TODO(), app.Options{PrefetchSecrets: *port == 0, RecordConfigSnapshot: true})
+	if err != nil {
+		panic("Failed to build application: " + err.Error())
+	}
 
-	lambda.Start(userHandler.Handle)
+	if *port == 0 {
+		integration, err := lambdahttp.ParseIntegration(*integration)
+		if err != nil {
+			panic(err.Error())
+		}
+		handler, err := container.Lambda(*handlerName, integration)
+		if err != nil {
+			panic(err.Error())
+		}
+		lambda.Start(handler)
+		return
+	}
+
+	// cmd/server is the entrypoint for deployments.
+	addr := fmt.Sprintf(":%d", *port)
+	logrus.WithFields(logrus.Fields{"addr": addr, "admin": *admin}).Info("Starting local HTTP server")
+	if err := http.ListenAndServe(addr, container.Router(*admin)); err != nil {
+		panic("HTTP server stopped: " + err.Error())
+	}
 }
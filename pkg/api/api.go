@@ -0,0 +1,308 @@
+// Package api holds the request and response types of the user-creation
+// service and the error body it answers failures with, so ShareFrame's other
+// Go services can call it without copying them. Like pkg/validate it has no
+// dependencies on the service's internals; the error codes it returns are the
+// Code constants in pkg/validate.
+package api
+
+import "time"
+
+// CreateUserRequest signs a user up. Handle, Email and Password are
+// required unless IdentityProvider is set.
+type CreateUserRequest struct {
+	Handle      string `json:"handle"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Tenant      string `json:"tenant,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	// Locale is a BCP 47 tag, e.g. "es" or "pt-BR". It selects the language
+	// of validation messages and is stored for localized email.
+	Locale string `json:"locale,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code, stored for region-specific
+	// compliance rules.
+	Country string `json:"country,omitempty"`
+	// Timezone is an IANA zone name such as "Europe/Berlin", used for
+	// scheduled email and displayed timestamps.
+	Timezone string `json:"timezone,omitempty"`
+	// Bio, Pronouns, Website and Location fill in the new account's
+	// profile. All are optional; Website is stored as a full http or https
+	// URL.
+	Bio      string `json:"bio,omitempty"`
+	Pronouns string `json:"pronouns,omitempty"`
+	Website  string `json:"website,omitempty"`
+	Location string `json:"location,omitempty"`
+	// DID is only used with a custom-domain handle: the identity the domain
+	// already points to, which the account is created under.
+	DID string `json:"did,omitempty"`
+	// PasswordConfirm is optional; when present it must match Password.
+	PasswordConfirm string `json:"passwordConfirm,omitempty"`
+	// InviteCode is only accepted when the deployment has user-supplied
+	// invite codes enabled; otherwise the service mints one per signup.
+	InviteCode string `json:"inviteCode,omitempty"`
+	// ClientIP is the caller's address as seen by the API front end, used
+	// for abuse scoring. It is set by the front end, never by the client.
+	ClientIP string `json:"clientIp,omitempty"`
+	// CaptchaToken is the solved captcha, required only for signups that
+	// score as high risk.
+	CaptchaToken string `json:"captchaToken,omitempty"`
+	// Consents are the consent choices made on the signup form.
+	Consents []Consent `json:"consents,omitempty"`
+	// ReferralSource is how the user heard about us, e.g. "friend" or a
+	// campaign tag. It is stored for the CRM and reporting only.
+	ReferralSource string `json:"referralSource,omitempty"`
+	// ReferralCode is the code of the account that referred the user, if
+	// any. An unknown or ineligible code never fails the signup.
+	ReferralCode string `json:"referralCode,omitempty"`
+	// IdentityProvider and IDToken sign the user up with a social login
+	// instead of a password: "google" or "apple", and the OIDC identity
+	// token it issued. The email address is taken from the token.
+	IdentityProvider string `json:"identityProvider,omitempty"`
+	IDToken          string `json:"idToken,omitempty"`
+	// AccountType is AccountTypePerson, the default, or
+	// AccountTypeOrganization for a brand or community.
+	AccountType string `json:"accountType,omitempty"`
+	// Owners are the people who run an organization account, given as the
+	// DIDs, handles or email addresses of their own accounts. Validation
+	// replaces them with DIDs.
+	Owners []string `json:"owners,omitempty"`
+	// AvatarUpload asks for a link to upload a profile picture to, returned
+	// with the new account when the deployment accepts uploads.
+	AvatarUpload bool `json:"avatarUpload,omitempty"`
+	// IdempotencyKey identifies the signup across retries: a retried
+	// request with the same key resumes after the steps already done
+	// instead of starting over. Queued signups default to their SQS
+	// message ID.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// Version is the API version of the request's schema. Direct invokes
+	// set it to send a CreateUserRequestV2; over HTTP it is implied by the
+	// path and may be left out.
+	Version string `json:"version,omitempty"`
+}
+
+// API versions. A request that doesn't name one is a v1 request.
+const (
+	Version1 = "v1"
+	Version2 = "v2"
+)
+
+// Versions are the API versions the service serves, oldest first.
+var Versions = []string{Version1, Version2}
+
+// Account types. Organizations are validated with their own rules and bots
+// are only created through the bot provisioning path; both are labelled on
+// their PDS profile.
+const (
+	AccountTypePerson       = "person"
+	AccountTypeOrganization = "organization"
+	AccountTypeBot          = "bot"
+)
+
+// Consent purposes a signup can record.
+const (
+	ConsentMarketing      = "marketing"
+	ConsentDataProcessing = "data_processing"
+)
+
+// Consent is one consent choice. TextVersion identifies the wording the
+// user was shown, so a later change to the text doesn't change what they
+// agreed to. RecordedAt is set by the service when the choice is stored.
+type Consent struct {
+	Purpose     string    `json:"purpose"`
+	Granted     bool      `json:"granted"`
+	TextVersion string    `json:"textVersion"`
+	RecordedAt  time.Time `json:"recordedAt,omitempty"`
+}
+
+type CreateUserResponse struct {
+	Handle     string `json:"handle"`
+	DID        string `json:"did"`
+	AccessJWT  string `json:"accessJwt"`
+	RefreshJWT string `json:"refreshJwt"`
+	// SignupToken is a short-lived JWT, signed by ShareFrame, attesting the
+	// DID, handle and verification state. It is set only when response
+	// tokens are configured.
+	SignupToken string `json:"signupToken,omitempty"`
+	// AppPassword is set for social signups when the deployment hands out
+	// an app password instead of keeping a password for the account. It
+	// can't be retrieved again.
+	AppPassword string `json:"appPassword,omitempty"`
+	// AvatarUpload is set when the signup asked for a profile picture
+	// upload link.
+	AvatarUpload *AvatarUpload `json:"avatarUpload,omitempty"`
+	// Status is the account's status after signup: pending, verified or
+	// pending_review.
+	Status string `json:"status,omitempty"`
+	// VerificationRequired is set while the account's email address still
+	// has to be verified.
+	VerificationRequired bool `json:"verificationRequired"`
+	// VerificationEmailSentAt is when the verification email went out. It
+	// is unset when the email was queued for a retry or isn't sent at all.
+	VerificationEmailSentAt *time.Time `json:"verificationEmailSentAt,omitempty"`
+	// Onboarding is the onboarding state of the new account, empty when it
+	// wasn't seeded.
+	Onboarding string `json:"onboarding,omitempty"`
+	// NextSteps are what the client should show next, most pressing first.
+	// It is empty once the account has nothing left to do.
+	NextSteps []string `json:"nextSteps"`
+}
+
+// Next steps of a signup response.
+const (
+	NextStepAwaitReview  = "await_review"
+	NextStepVerifyEmail  = "verify_email"
+	NextStepVerifyPhone  = "verify_phone"
+	NextStepUploadAvatar = "upload_avatar"
+	// NextStepAwaitLaunch is the only step of a waitlisted signup.
+	NextStepAwaitLaunch = "await_launch"
+)
+
+// AvatarUpload is where a new account PUTs its profile picture, a PNG or
+// JPEG of at most MaxBytes. Once uploaded, the picture is applied with the
+// service's avatar operation.
+type AvatarUpload struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxBytes  int       `json:"maxBytes"`
+}
+
+// Account statuses. New accounts start pending, become verified once the
+// holder confirms their email address and active once onboarding is done.
+const (
+	StatusPending  = "pending"
+	StatusVerified = "verified"
+	StatusActive   = "active"
+)
+
+// StatusPendingReview is stored for accounts that were created but need a
+// moderator to look at them before they are treated as active.
+const StatusPendingReview = "pending_review"
+
+// StatusErased is stored for accounts whose personal data was erased at the
+// holder's request. The anonymized row stays so the DID isn't reused.
+const StatusErased = "erased"
+
+// StatusSuspended is stored for accounts an operator took down. The
+// account's data is kept.
+const StatusSuspended = "suspended"
+
+// StatusRejected is stored for accounts a moderator turned down on review.
+// Like an erased account, its handle and email address are released and
+// the row is kept so the DID isn't reused.
+const StatusRejected = "rejected"
+
+// StatusWaitlisted is answered to a signup the rollout gate put on the
+// waitlist. No account was created; the user can sign up again once the
+// rollout reaches them.
+const StatusWaitlisted = "waitlisted"
+
+// AvailabilityRequest checks a handle and an email address before signup,
+// so the form can show both fields' problems at once. Either may be left
+// out. Locale selects the language of the messages.
+type AvailabilityRequest struct {
+	Handle string `json:"handle,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	Locale string `json:"locale,omitempty"`
+}
+
+// AvailabilityResponse has a result for each field that was checked.
+type AvailabilityResponse struct {
+	Handle *FieldAvailability `json:"handle,omitempty"`
+	Email  *FieldAvailability `json:"email,omitempty"`
+}
+
+// FieldAvailability says whether a value can be signed up with. Value is
+// its normalized form, such as the handle with the tenant's suffix. An
+// unavailable value has the validation Code and its localized Message, and
+// a handle free Suggestions.
+type FieldAvailability struct {
+	Value       string   `json:"value"`
+	Available   bool     `json:"available"`
+	Code        string   `json:"code,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// HandleClaimRequest reserves a handle before the account exists.
+type HandleClaimRequest struct {
+	Handle string `json:"handle"`
+	Email  string `json:"email"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type HandleClaimResponse struct {
+	Handle    string    `json:"handle"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CallerHeader names the operator or service calling the admin routes, as
+// authenticated by the proxy in front of the HTTP server. Audit trails
+// record it; request bodies can't name a caller.
+const CallerHeader = "X-Authenticated-User"
+
+// LifecycleRequest moves an account along its lifecycle. Event is
+// "verify", "activate", "approve", "reject" or "suspend". The audit trail
+// names the caller the service authenticated, not anything in the request.
+type LifecycleRequest struct {
+	Event  string `json:"event"`
+	DID    string `json:"did"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// LifecycleResponse reports the account's status before and after the
+// event. Changed is false when the account was already in the status the
+// event leads to.
+type LifecycleResponse struct {
+	DID     string `json:"did"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Changed bool   `json:"changed"`
+}
+
+// AdminRequest is an operator action on one account, named by User as a
+// DID, handle or email address, or, for "mint_invites", a request for
+// Count invite codes. "quota" reports today's signup quotas, and Domain's
+// when it is set; "stats" reports the signup stats of the last Count
+// periods of Period, "hour" or "day".
+type AdminRequest struct {
+	Action string `json:"action"`
+	User   string `json:"user,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Period string `json:"period,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// PrivacyRequest is an operator action on behalf of an account holder.
+// Action is "export", "erase" or "confirm_erase"; erase returns the
+// confirmation token that confirm_erase needs before anything is deleted.
+type PrivacyRequest struct {
+	Action       string `json:"action"`
+	DID          string `json:"did"`
+	Tenant       string `json:"tenant,omitempty"`
+	Confirmation string `json:"confirmation,omitempty"`
+}
+
+type PrivacyResponse struct {
+	ExportURL    string    `json:"exportUrl,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"`
+	Confirmation string    `json:"confirmation,omitempty"`
+	Erased       bool      `json:"erased,omitempty"`
+}
+
+// User is an account as the lookup action returns it, with the fields other
+// services read. Status is one of the Status constants; Role is "user"
+// unless another role was assigned to the account's email address.
+type User struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	Email       string `json:"email"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
+	Verified    bool   `json:"verified"`
+	Role        string `json:"role"`
+	AccountType string `json:"accountType,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+}
@@ -0,0 +1,45 @@
+package api
+
+// ErrorResponse is the body of a failed request. Detail is the error as the
+// service logged it; Code and Message are set for validation failures, with
+// one FieldError per failure in Errors. A handle that is taken has free
+// alternatives in Suggestions.
+type ErrorResponse struct {
+	Detail      string       `json:"error"`
+	Code        string       `json:"code,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	Errors      []FieldError `json:"errors,omitempty"`
+	Suggestions []string     `json:"suggestions,omitempty"`
+}
+
+// Error returns the localized message when there is one.
+func (e *ErrorResponse) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Detail
+}
+
+// HasCode reports whether code is the response's code or that of one of
+// its field errors.
+func (e *ErrorResponse) HasCode(code string) bool {
+	if e.Code == code {
+		return true
+	}
+	for _, f := range e.Errors {
+		if f.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldError is one validation failure in an ErrorResponse. Params carries
+// the values a client needs to build its own message, such as a length
+// limit.
+type FieldError struct {
+	Field   string                 `json:"field,omitempty"`
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		codes   []string
+	}{
+		{
+			name:    "Validation failure",
+			body:    `{"error":"handle is taken","code":"handle_taken","message":"That handle is taken","errors":[{"field":"handle","code":"handle_taken","message":"That handle is taken"},{"field":"password","code":"weak_password","message":"Password is too weak","params":{"min":8}}],"suggestions":["alice2"]}`,
+			message: "That handle is taken",
+			codes:   []string{"handle_taken", "weak_password"},
+		},
+		{
+			name:    "Uncoded failure",
+			body:    `{"error":"internal error"}`,
+			message: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &resp))
+			assert.EqualError(t, &resp, tt.message)
+			for _, code := range tt.codes {
+				assert.True(t, resp.HasCode(code), code)
+			}
+			assert.False(t, resp.HasCode("unknown"))
+
+			encoded, err := json.Marshal(&resp)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.body, string(encoded))
+		})
+	}
+}
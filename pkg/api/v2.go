@@ -0,0 +1,74 @@
+package api
+
+// CreateUserRequestV2 is the v2 signup request, served at /v2/users while
+// v1 clients move over. It changes the format of two fields:
+//
+//   - Handle is always the full handle, such as "alice.shareframe.social"
+//     or a custom domain. v1 also takes the bare name and appends the
+//     tenant's suffix.
+//   - There is no password confirmation. Clients confirm the password on
+//     the form; the service only checks the password itself.
+//
+// The other fields are those of CreateUserRequest.
+type CreateUserRequestV2 struct {
+	Handle           string    `json:"handle"`
+	Email            string    `json:"email"`
+	Password         string    `json:"password"`
+	Tenant           string    `json:"tenant,omitempty"`
+	DisplayName      string    `json:"displayName,omitempty"`
+	Locale           string    `json:"locale,omitempty"`
+	Country          string    `json:"country,omitempty"`
+	Timezone         string    `json:"timezone,omitempty"`
+	Bio              string    `json:"bio,omitempty"`
+	Pronouns         string    `json:"pronouns,omitempty"`
+	Website          string    `json:"website,omitempty"`
+	Location         string    `json:"location,omitempty"`
+	DID              string    `json:"did,omitempty"`
+	InviteCode       string    `json:"inviteCode,omitempty"`
+	ClientIP         string    `json:"clientIp,omitempty"`
+	CaptchaToken     string    `json:"captchaToken,omitempty"`
+	Consents         []Consent `json:"consents,omitempty"`
+	ReferralSource   string    `json:"referralSource,omitempty"`
+	ReferralCode     string    `json:"referralCode,omitempty"`
+	IdentityProvider string    `json:"identityProvider,omitempty"`
+	IDToken          string    `json:"idToken,omitempty"`
+	AccountType      string    `json:"accountType,omitempty"`
+	Owners           []string  `json:"owners,omitempty"`
+	AvatarUpload     bool      `json:"avatarUpload,omitempty"`
+	IdempotencyKey   string    `json:"idempotencyKey,omitempty"`
+	// Version is Version2, or empty where the path implies it.
+	Version string `json:"version,omitempty"`
+}
+
+// V1 is the request in the v1 schema the service validates, with Version
+// set to Version2 so the v2 handle format is still enforced.
+func (r CreateUserRequestV2) V1() CreateUserRequest {
+	return CreateUserRequest{
+		Handle:           r.Handle,
+		Email:            r.Email,
+		Password:         r.Password,
+		Tenant:           r.Tenant,
+		DisplayName:      r.DisplayName,
+		Locale:           r.Locale,
+		Country:          r.Country,
+		Timezone:         r.Timezone,
+		Bio:              r.Bio,
+		Pronouns:         r.Pronouns,
+		Website:          r.Website,
+		Location:         r.Location,
+		DID:              r.DID,
+		InviteCode:       r.InviteCode,
+		ClientIP:         r.ClientIP,
+		CaptchaToken:     r.CaptchaToken,
+		Consents:         r.Consents,
+		ReferralSource:   r.ReferralSource,
+		ReferralCode:     r.ReferralCode,
+		IdentityProvider: r.IdentityProvider,
+		IDToken:          r.IDToken,
+		AccountType:      r.AccountType,
+		Owners:           r.Owners,
+		AvatarUpload:     r.AvatarUpload,
+		IdempotencyKey:   r.IdempotencyKey,
+		Version:          Version2,
+	}
+}
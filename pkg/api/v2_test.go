@@ -0,0 +1,52 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUserRequestV2V1(t *testing.T) {
+	v2 := CreateUserRequestV2{
+		Handle:         "alice.shareframe.social",
+		Email:          "alice@example.com",
+		Password:       "Valid@123",
+		Consents:       []Consent{{Purpose: ConsentMarketing, Granted: true, TextVersion: "2024-01"}},
+		Owners:         []string{"did:plc:owner"},
+		AvatarUpload:   true,
+		IdempotencyKey: "signup-1",
+	}
+
+	assert.Equal(t, CreateUserRequest{
+		Handle:         "alice.shareframe.social",
+		Email:          "alice@example.com",
+		Password:       "Valid@123",
+		Consents:       []Consent{{Purpose: ConsentMarketing, Granted: true, TextVersion: "2024-01"}},
+		Owners:         []string{"did:plc:owner"},
+		AvatarUpload:   true,
+		IdempotencyKey: "signup-1",
+		Version:        Version2,
+	}, v2.V1())
+}
+
+// TestCreateUserRequestV2Fields keeps the v2 schema in step with v1: a field
+// added to CreateUserRequest must be added to CreateUserRequestV2 and V1
+// too, unless v2 drops it on purpose.
+func TestCreateUserRequestV2Fields(t *testing.T) {
+	dropped := map[string]bool{"PasswordConfirm": true}
+
+	v1 := reflect.TypeOf(CreateUserRequest{})
+	v2 := reflect.TypeOf(CreateUserRequestV2{})
+	for i := 0; i < v1.NumField(); i++ {
+		field := v1.Field(i)
+		if dropped[field.Name] {
+			continue
+		}
+		v2Field, ok := v2.FieldByName(field.Name)
+		if assert.True(t, ok, "CreateUserRequestV2 has no %s", field.Name) {
+			assert.Equal(t, field.Type, v2Field.Type, field.Name)
+			assert.Equal(t, field.Tag, v2Field.Tag, field.Name)
+		}
+	}
+}
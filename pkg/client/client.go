@@ -0,0 +1,293 @@
+// Package client calls the user-creation service from other ShareFrame Go
+// services, over HTTP or by invoking its Lambda functions, so no team has
+// to hand-roll the requests, retries and error decoding. Requests and
+// responses are the types in pkg/api.
+//
+// Failures the service answered are returned as *Error, carrying the
+// response status and the api.ErrorResponse body; errors.As gets at the
+// validation code. Rate-limited requests and upstream failures are retried
+// with backoff, and signups are sent with an idempotency key so a retried
+// signup resumes instead of failing on its own handle.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+)
+
+// Defaults for a Client's retries.
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 200 * time.Millisecond
+)
+
+// Paths of the service's HTTP routes the client calls.
+const (
+	PathUsers     = "/users"
+	PathAdmin     = "/admin/accounts"
+	PathLifecycle = "/admin/lifecycle"
+	PathPrivacy   = "/admin/privacy"
+)
+
+// Client calls the user-creation service. Its fields may be changed
+// before the first call.
+type Client struct {
+	// Tenant is sent with requests that don't name one. Empty is the
+	// deployment's default tenant.
+	Tenant string
+	// Actor names the calling service in the audit trail of the
+	// verifications and deletions it makes. It is sent as the
+	// api.CallerHeader, so a proxy in front of the HTTP server must
+	// authenticate it.
+	Actor string
+	// Retries is how many times a request that failed transiently is
+	// sent again, and Backoff the wait before the first retry, doubled
+	// for each one after it. A Retry-After header is waited out instead.
+	Retries int
+	Backoff time.Duration
+
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a Client for the service's HTTP server at baseURL, such as
+// "https://users.internal.shareframe.social". httpClient may be nil for
+// http.DefaultClient. The operator routes must be served, so the server is
+// expected to be one only internal services can reach.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    httpClient,
+	}
+}
+
+// CreateUser signs a user up. Without an IdempotencyKey the request is
+// given one, used for every retry of this call.
+func (c *Client) CreateUser(ctx context.Context, req api.CreateUserRequest) (*api.CreateUserResponse, error) {
+	if req.Tenant == "" {
+		req.Tenant = c.Tenant
+	}
+	if req.IdempotencyKey == "" {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		req.IdempotencyKey = key
+	}
+
+	var resp api.CreateUserResponse
+	if err := c.call(ctx, PathUsers, req, req.IdempotencyKey, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyEmail marks the email address of the account did as verified,
+// moving the account from pending to verified. An account that is already
+// verified is answered with Changed false.
+func (c *Client) VerifyEmail(ctx context.Context, did string) (*api.LifecycleResponse, error) {
+	req := api.LifecycleRequest{Event: lifecycleVerify, DID: did, Tenant: c.Tenant}
+	var resp api.LifecycleResponse
+	if err := c.call(ctx, PathLifecycle, req, "", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUser looks an account up by its DID, handle or email address. An
+// unknown account is an *Error with status 404.
+func (c *Client) GetUser(ctx context.Context, user string) (*api.User, error) {
+	req := api.AdminRequest{Action: adminLookup, User: user, Tenant: c.Tenant}
+	var resp struct {
+		Account *api.User `json:"account"`
+	}
+	if err := c.call(ctx, PathAdmin, req, "", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Account == nil {
+		return nil, fmt.Errorf("user-creation: lookup of %s returned no account", user)
+	}
+	return resp.Account, nil
+}
+
+// DeleteUser erases the account did: it is deleted from the PDS and its
+// personal data is removed, keeping an anonymized record so the DID isn't
+// reused. The service's erasure confirmation is requested and sent back in
+// the same call.
+func (c *Client) DeleteUser(ctx context.Context, did string) error {
+	req := api.PrivacyRequest{Action: privacyErase, DID: did, Tenant: c.Tenant}
+	var requested api.PrivacyResponse
+	if err := c.call(ctx, PathPrivacy, req, "", &requested); err != nil {
+		return err
+	}
+
+	req.Action, req.Confirmation = privacyConfirmErase, requested.Confirmation
+	var confirmed api.PrivacyResponse
+	if err := c.call(ctx, PathPrivacy, req, "", &confirmed); err != nil {
+		return err
+	}
+	if !confirmed.Erased {
+		return fmt.Errorf("user-creation: erasure of %s was not carried out", did)
+	}
+	return nil
+}
+
+// Actions and events of the operator routes the client uses.
+const (
+	lifecycleVerify     = "verify"
+	adminLookup         = "lookup"
+	privacyErase        = "erase"
+	privacyConfirmErase = "confirm_erase"
+)
+
+// Error is a failure the service answered with. Its message is the
+// localized one when the failure has a validation code.
+type Error struct {
+	StatusCode int
+	api.ErrorResponse
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("user-creation: %d: %s", e.StatusCode, e.ErrorResponse.Error())
+}
+
+// Retryable reports whether the same request may succeed later: it was
+// rate limited or an upstream dependency of the service failed.
+func (e *Error) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// call POSTs in to path and decodes the response into out, retrying
+// transient failures.
+func (c *Client) call(ctx context.Context, path string, in interface{}, idempotencyKey string, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("user-creation: failed to encode request: %w", err)
+	}
+
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		wait, err := c.send(ctx, path, body, idempotencyKey, out)
+		if err == nil || attempt >= c.Retries || !retryable(ctx, err) {
+			return err
+		}
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt at a request. A failed attempt returns how long
+// the service asked to wait before the next, if it said.
+func (c *Client) send(ctx context.Context, path string, body []byte, idempotencyKey string, out interface{}) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("user-creation: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if c.Actor != "" {
+		req.Header.Set(api.CallerHeader, c.Actor)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, &transportError{fmt.Errorf("user-creation: request to %s failed: %w", path, err)}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, &transportError{fmt.Errorf("user-creation: failed to read response from %s: %w", path, err)}
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		failure := &Error{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, &failure.ErrorResponse) != nil || failure.Detail == "" {
+			failure.Detail = strings.TrimSpace(string(data))
+			if failure.Detail == "" {
+				failure.Detail = http.StatusText(resp.StatusCode)
+			}
+		}
+		return retryAfter(resp.Header.Get("Retry-After")), failure
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return 0, fmt.Errorf("user-creation: failed to decode response from %s: %w", path, err)
+	}
+	return 0, nil
+}
+
+// retryable reports whether a failed attempt is worth repeating: the
+// service said so, or the request didn't get an answer and the caller
+// hasn't given up.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var failure *Error
+	if errors.As(err, &failure) {
+		return failure.Retryable()
+	}
+	var transport *transportError
+	return errors.As(err, &transport) && !errors.Is(err, errMisconfigured)
+}
+
+// transportError is a request that got no answer.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter is the wait a Retry-After header of seconds asks for.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("user-creation: failed to generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// reply is one canned answer of a test server.
+type reply struct {
+	status int
+	body   string
+}
+
+// recorded is a request a test server received.
+type recorded struct {
+	path           string
+	idempotencyKey string
+	caller         string
+	body           map[string]interface{}
+}
+
+// newServer answers each request with the next of replies, the last one
+// repeating, and records what it was sent.
+func newServer(t *testing.T, replies ...reply) (*Client, *[]recorded) {
+	var requests []recorded
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recorded{path: r.URL.Path, idempotencyKey: r.Header.Get("Idempotency-Key"), caller: r.Header.Get(api.CallerHeader)}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req.body))
+		requests = append(requests, req)
+
+		next := replies[0]
+		if len(replies) > 1 {
+			replies = replies[1:]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(next.status)
+		w.Write([]byte(next.body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(server.URL, server.Client())
+	client.Backoff = 0
+	client.Tenant = "shareframe"
+	client.Actor = "billing"
+	return client, &requests
+}
+
+func TestCreateUser(t *testing.T) {
+	client, requests := newServer(t,
+		reply{http.StatusTooManyRequests, `{"error":"rate limited","code":"rate_limited"}`},
+		reply{http.StatusCreated, `{"handle":"alice.shareframe.social","did":"did:plc:alice","accessJwt":"a","refreshJwt":"r","nextSteps":["verify_email"]}`},
+	)
+
+	resp, err := client.CreateUser(context.Background(), api.CreateUserRequest{Handle: "alice", Email: "alice@example.com", Password: "Secret123!"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "did:plc:alice", resp.DID)
+	assert.Equal(t, []string{api.NextStepVerifyEmail}, resp.NextSteps)
+	if assert.Len(t, *requests, 2) {
+		first, retry := (*requests)[0], (*requests)[1]
+		assert.Equal(t, PathUsers, first.path)
+		assert.Equal(t, "shareframe", first.body["tenant"])
+		assert.NotEmpty(t, first.idempotencyKey)
+		assert.Equal(t, first.idempotencyKey, first.body["idempotencyKey"])
+		assert.Equal(t, first.idempotencyKey, retry.idempotencyKey, "a retry resumes the same signup")
+	}
+}
+
+func TestCreateUserErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    reply
+		attempts int
+		status   int
+		code     string
+		message  string
+	}{
+		{
+			name:     "Validation failure",
+			reply:    reply{http.StatusConflict, `{"error":"validation error: handle taken","code":"handle_taken","message":"That handle is taken","suggestions":["alice2"]}`},
+			attempts: 1,
+			status:   http.StatusConflict,
+			code:     "handle_taken",
+			message:  "user-creation: 409: That handle is taken",
+		},
+		{
+			name:     "Upstream failure retried until out of retries",
+			reply:    reply{http.StatusBadGateway, `{"error":"upstream error: PDS unavailable"}`},
+			attempts: DefaultRetries + 1,
+			status:   http.StatusBadGateway,
+			message:  "user-creation: 502: upstream error: PDS unavailable",
+		},
+		{
+			name:     "Body that isn't an error response",
+			reply:    reply{http.StatusInternalServerError, `oops`},
+			attempts: 1,
+			status:   http.StatusInternalServerError,
+			message:  "user-creation: 500: oops",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newServer(t, tt.reply)
+
+			_, err := client.CreateUser(context.Background(), api.CreateUserRequest{Handle: "alice", IdempotencyKey: "signup-1"})
+
+			var failure *Error
+			if assert.True(t, errors.As(err, &failure)) {
+				assert.Equal(t, tt.status, failure.StatusCode)
+				assert.Equal(t, tt.code, failure.Code)
+				assert.EqualError(t, err, tt.message)
+			}
+			assert.Len(t, *requests, tt.attempts)
+			assert.Equal(t, "signup-1", (*requests)[0].idempotencyKey)
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	client, requests := newServer(t, reply{http.StatusOK, `{"did":"did:plc:alice","from":"pending","to":"verified","changed":true}`})
+
+	resp, err := client.VerifyEmail(context.Background(), "did:plc:alice")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &api.LifecycleResponse{DID: "did:plc:alice", From: api.StatusPending, To: api.StatusVerified, Changed: true}, resp)
+	assert.Equal(t, recorded{
+		path:   PathLifecycle,
+		caller: "billing",
+		body:   map[string]interface{}{"event": "verify", "did": "did:plc:alice", "tenant": "shareframe"},
+	}, (*requests)[0])
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   reply
+		want    *api.User
+		wantErr string
+	}{
+		{
+			name:  "Found",
+			reply: reply{http.StatusOK, `{"account":{"did":"did:plc:alice","handle":"alice.shareframe.social","email":"alice@example.com","status":"verified","verified":true,"role":"user","theme":"dark"}}`},
+			want:  &api.User{DID: "did:plc:alice", Handle: "alice.shareframe.social", Email: "alice@example.com", Status: api.StatusVerified, Verified: true, Role: "user"},
+		},
+		{
+			name:    "Not found",
+			reply:   reply{http.StatusNotFound, `{"error":"not found: no account for bob.shareframe.social"}`},
+			wantErr: "user-creation: 404: not found: no account for bob.shareframe.social",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newServer(t, tt.reply)
+
+			user, err := client.GetUser(context.Background(), "alice")
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, user)
+			assert.Equal(t, map[string]interface{}{"action": "lookup", "user": "alice", "tenant": "shareframe"}, (*requests)[0].body)
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	client, requests := newServer(t,
+		reply{http.StatusOK, `{"confirmation":"confirm-token"}`},
+		reply{http.StatusOK, `{"erased":true}`},
+	)
+
+	err := client.DeleteUser(context.Background(), "did:plc:alice")
+
+	assert.NoError(t, err)
+	if assert.Len(t, *requests, 2) {
+		assert.Equal(t, "erase", (*requests)[0].body["action"])
+		assert.Equal(t, "billing", (*requests)[0].caller)
+		assert.Equal(t, "confirm_erase", (*requests)[1].body["action"])
+		assert.Equal(t, "confirm-token", (*requests)[1].body["confirmation"])
+	}
+}
+
+func TestCallCanceled(t *testing.T) {
+	client, requests := newServer(t, reply{http.StatusServiceUnavailable, `{"error":"unavailable"}`})
+	client.Backoff = 1 << 40
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.DeleteUser(ctx, "did:plc:alice")
+
+	assert.Error(t, err)
+	assert.Empty(t, *requests)
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LambdaInvoker invokes a Lambda function synchronously with payload and
+// returns the function's response. It fails when the invoke or the
+// function does. An adapter of the AWS SDK's lambda.Client returns an
+// error for an output with FunctionError set.
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, function string, payload []byte) ([]byte, error)
+}
+
+// Functions are the names or ARNs of the service's Lambda functions, by
+// the handler each runs. A function left empty can't be called.
+type Functions struct {
+	Users     string
+	Admin     string
+	Lifecycle string
+	Privacy   string
+}
+
+// NewLambda returns a Client that invokes the service's functions. Each
+// request is sent as an HTTP API event, so the functions must be deployed
+// with the auto or httpapi integration; they answer with the statuses and
+// error bodies of the HTTP server. The Client's Actor is passed on as the
+// IAM identity of the event, the caller the functions record.
+func NewLambda(invoker LambdaInvoker, functions Functions) *Client {
+	return New("lambda://user-creation", &http.Client{Transport: &lambdaTransport{
+		invoker: invoker,
+		functions: map[string]string{
+			PathUsers:     functions.Users,
+			PathAdmin:     functions.Admin,
+			PathLifecycle: functions.Lifecycle,
+			PathPrivacy:   functions.Privacy,
+		},
+	}})
+}
+
+// errMisconfigured fails requests that no retry can help: their function
+// isn't set, or isn't deployed to take HTTP API events.
+var errMisconfigured = errors.New("misconfigured Lambda client")
+
+// lambdaTransport is an http.RoundTripper that invokes the function for
+// each request's path.
+type lambdaTransport struct {
+	invoker   LambdaInvoker
+	functions map[string]string
+}
+
+func (t *lambdaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	function := t.functions[req.URL.Path]
+	if function == "" {
+		return nil, fmt.Errorf("%w: no function for %s", errMisconfigured, req.URL.Path)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+	}
+	actor := req.Header.Get(api.CallerHeader)
+	headers := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		if !strings.EqualFold(name, api.CallerHeader) {
+			headers[strings.ToLower(name)] = req.Header.Get(name)
+		}
+	}
+	event := events.APIGatewayV2HTTPRequest{
+		Version:        "2.0",
+		RawPath:        req.URL.Path,
+		RawQueryString: req.URL.RawQuery,
+		Headers:        headers,
+		Body:           string(body),
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: req.Method,
+				Path:   req.URL.Path,
+			},
+		},
+	}
+	if actor != "" {
+		event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{UserARN: actor},
+		}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Lambda event: %w", err)
+	}
+
+	out, err := t.invoker.Invoke(req.Context(), function, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke %s: %w", function, err)
+	}
+	var resp events.APIGatewayV2HTTPResponse
+	if err := json.Unmarshal(out, &resp); err != nil || resp.StatusCode == 0 {
+		// A function deployed for direct invokes answers with its
+		// handler's response object instead.
+		return nil, fmt.Errorf("%w: %s did not answer with an HTTP API response", errMisconfigured, function)
+	}
+
+	header := http.Header{}
+	for name, value := range resp.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(resp.Body))),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
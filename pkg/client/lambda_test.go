@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ShareFrame/user-management/pkg/api"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockInvoker struct {
+	mock.Mock
+}
+
+func (m *mockInvoker) Invoke(ctx context.Context, function string, payload []byte) ([]byte, error) {
+	args := m.Called(ctx, function, payload)
+	out, _ := args.Get(0).([]byte)
+	return out, args.Error(1)
+}
+
+// httpResponse is the payload a function deployed for HTTP API events
+// answers with.
+func httpResponse(status int, body string) []byte {
+	out, _ := json.Marshal(events.APIGatewayV2HTTPResponse{StatusCode: status, Body: body})
+	return out
+}
+
+func TestLambdaCreateUser(t *testing.T) {
+	invoker := &mockInvoker{}
+	var event events.APIGatewayV2HTTPRequest
+	invoker.On("Invoke", mock.Anything, "users-fn", mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.NoError(t, json.Unmarshal(args.Get(2).([]byte), &event))
+		}).
+		Return(httpResponse(201, `{"handle":"alice.shareframe.social","did":"did:plc:alice","nextSteps":[]}`), nil)
+
+	client := NewLambda(invoker, Functions{Users: "users-fn"})
+	resp, err := client.CreateUser(context.Background(), api.CreateUserRequest{Handle: "alice", IdempotencyKey: "signup-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "did:plc:alice", resp.DID)
+	assert.Equal(t, "2.0", event.Version)
+	assert.Equal(t, "POST", event.RequestContext.HTTP.Method)
+	assert.Equal(t, PathUsers, event.RawPath)
+	assert.Equal(t, "signup-1", event.Headers["idempotency-key"])
+	assert.Contains(t, event.Body, `"handle":"alice"`)
+	invoker.AssertExpectations(t)
+}
+
+func TestLambdaVerifyEmail(t *testing.T) {
+	invoker := &mockInvoker{}
+	var event events.APIGatewayV2HTTPRequest
+	invoker.On("Invoke", mock.Anything, "lifecycle-fn", mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.NoError(t, json.Unmarshal(args.Get(2).([]byte), &event))
+		}).
+		Return(httpResponse(200, `{"did":"did:plc:alice","from":"pending","to":"verified","changed":true}`), nil)
+
+	client := NewLambda(invoker, Functions{Lifecycle: "lifecycle-fn"})
+	client.Actor = "arn:aws:iam::123456789012:role/billing"
+	_, err := client.VerifyEmail(context.Background(), "did:plc:alice")
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, event.RequestContext.Authorizer) && assert.NotNil(t, event.RequestContext.Authorizer.IAM) {
+		assert.Equal(t, "arn:aws:iam::123456789012:role/billing", event.RequestContext.Authorizer.IAM.UserARN)
+	}
+	assert.NotContains(t, event.Headers, "x-authenticated-user")
+	invoker.AssertExpectations(t)
+}
+
+func TestLambdaErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions Functions
+		out       []byte
+		invokeErr error
+		invokes   int
+		status    int
+		wantErr   string
+	}{
+		{
+			name:      "Error response",
+			functions: Functions{Admin: "admin-fn"},
+			out:       httpResponse(404, `{"error":"not found: no account for alice.shareframe.social"}`),
+			invokes:   1,
+			status:    404,
+			wantErr:   "user-creation: 404: not found: no account for alice.shareframe.social",
+		},
+		{
+			name:      "Invoke failure retried",
+			functions: Functions{Admin: "admin-fn"},
+			invokeErr: errors.New("TooManyRequestsException"),
+			invokes:   DefaultRetries + 1,
+			wantErr:   `user-creation: request to /admin/accounts failed: Post "lambda://user-creation/admin/accounts": failed to invoke admin-fn: TooManyRequestsException`,
+		},
+		{
+			name:      "Function deployed for direct invokes",
+			functions: Functions{Admin: "admin-fn"},
+			out:       []byte(`{"account":{"did":"did:plc:alice"}}`),
+			invokes:   1,
+			wantErr:   `user-creation: request to /admin/accounts failed: Post "lambda://user-creation/admin/accounts": misconfigured Lambda client: admin-fn did not answer with an HTTP API response`,
+		},
+		{
+			name:    "No function",
+			wantErr: `user-creation: request to /admin/accounts failed: Post "lambda://user-creation/admin/accounts": misconfigured Lambda client: no function for /admin/accounts`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoker := &mockInvoker{}
+			invoker.On("Invoke", mock.Anything, "admin-fn", mock.Anything).Return(tt.out, tt.invokeErr)
+			client := NewLambda(invoker, tt.functions)
+			client.Backoff = 0
+
+			_, err := client.GetUser(context.Background(), "alice")
+
+			assert.EqualError(t, err, tt.wantErr)
+			var failure *Error
+			if errors.As(err, &failure) {
+				assert.Equal(t, tt.status, failure.StatusCode)
+			}
+			invoker.AssertNumberOfCalls(t, "Invoke", tt.invokes)
+		})
+	}
+}
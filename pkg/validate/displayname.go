@@ -0,0 +1,50 @@
+package validate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MaxDisplayNameLength matches the app.bsky.actor.profile limit.
+	MaxDisplayNameLength = 64
+	DisplayNameTooLong   = "display name cannot exceed 64 characters"
+
+	zeroWidthJoiner = '\u200d'
+)
+
+// NormalizeDisplayName NFC-normalizes name, drops control and invisible
+// formatting characters (including bidi overrides) and collapses runs of
+// whitespace. Zero-width joiners are kept so emoji sequences survive.
+func NormalizeDisplayName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range norm.NFC.String(name) {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r) && r != zeroWidthJoiner:
+			continue
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// DisplayName checks a normalized display name. An empty name is
+// valid; the handle is used instead.
+func DisplayName(name string) error {
+	if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return NewError(CodeDisplayNameTooLong, "%v", DisplayNameTooLong).
+			ForField(FieldDisplayName).With("max", MaxDisplayNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"Unchanged", "Jane Doe", "Jane Doe"},
+		{"Trims And Collapses Whitespace", "  Jane \t\n Doe  ", "Jane Doe"},
+		{"Strips Control Characters", "Jane\x00\x07Doe", "JaneDoe"},
+		{"Strips Bidi Override", "Jane\u202eeoD", "JaneeoD"},
+		{"Strips Zero Width Space", "Ja\u200bne", "Jane"},
+		{"Keeps Emoji Joiner", "👩\u200d💻 Jane", "👩\u200d💻 Jane"},
+		{"Composes To NFC", "Rene\u0301", "René"},
+		{"Only Controls", "\x00\x01", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeDisplayName(test.value))
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	assert.NoError(t, DisplayName(""))
+	assert.NoError(t, DisplayName(strings.Repeat("é", MaxDisplayNameLength)))
+	assert.Equal(t, CodeDisplayNameTooLong, ErrorCode(DisplayName(strings.Repeat("a", MaxDisplayNameLength+1))))
+}
@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// CanonicalizeEmail reduces what a user may paste, such as
+// " Foo <foo@Example.COM>", to the bare address with a lowercased domain.
+// The local part keeps its case; it is the form that is stored and
+// delivered to.
+func CanonicalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err == nil {
+		email = addr.Address
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	return email[:at] + "@" + strings.ToLower(email[at+1:])
+}
+
+// gmailDomains ignore dots in the local part and are aliases of each other.
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// NormalizeEmail reduces an address to the mailbox it delivers to:
+// canonicalized, lowercased, without a +tag, and with Gmail's dots and
+// googlemail.com alias folded. Two addresses that normalize the same reach
+// the same person, so it is the form to compare or bucket addresses by.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(CanonicalizeEmail(email))
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return email
+	}
+
+	local, _, _ = strings.Cut(local, "+")
+	if gmailDomains[domain] {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
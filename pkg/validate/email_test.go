@@ -0,0 +1,29 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"Lowercases Domain Only", "First.Last@Example.COM", "First.Last@example.com"},
+		{"Trims Whitespace", "  user@example.com\t", "user@example.com"},
+		{"Strips Display Name", `"Foo Bar" <foo@Bar.com>`, "foo@bar.com"},
+		{"Strips Bare Display Name", " Foo <foo@bar.com> ", "foo@bar.com"},
+		{"Strips Angle Brackets", "<foo@bar.com>", "foo@bar.com"},
+		{"Keeps Plus Tag", "user+tag@example.com", "user+tag@example.com"},
+		{"Leaves Invalid Input Trimmed", " not-an-email ", "not-an-email"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, CanonicalizeEmail(test.email))
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validation error codes. They are stable identifiers clients can switch on.
+// Some are only produced by the signup service, which checks storage and
+// policy lists this package knows nothing about, but they share one
+// namespace so clients handle every failure the same way.
+const (
+	CodeMissingFields        = "missing_fields"
+	CodeHandleTooShort       = "handle_too_short"
+	CodeHandleTooLong        = "handle_too_long"
+	CodeInvalidHandle        = "invalid_handle"
+	CodeMixedScriptHandle    = "mixed_script_handle"
+	CodeInvalidDomainHandle  = "invalid_domain_handle"
+	CodeDomainNotVerified    = "domain_not_verified"
+	CodeBlockedHandle        = "blocked_handle"
+	CodeHandleTaken          = "handle_taken"
+	CodeHandleClaimed        = "handle_claimed"
+	CodeHandleQuarantined    = "handle_quarantined"
+	CodeConfusableHandle     = "confusable_handle"
+	CodeProfaneHandle        = "profane_handle"
+	CodeProfaneDisplayName   = "profane_display_name"
+	CodeDisplayNameTooLong   = "display_name_too_long"
+	CodeBioTooLong           = "bio_too_long"
+	CodePronounsTooLong      = "pronouns_too_long"
+	CodeInvalidWebsite       = "invalid_website"
+	CodeLocationTooLong      = "location_too_long"
+	CodeInvalidEmail         = "invalid_email"
+	CodeEmailTaken           = "email_taken"
+	CodePasswordRequirements = "password_requirements"
+	CodePasswordTooWeak      = "password_too_weak"
+	CodePasswordBreached     = "password_breached"
+	CodePasswordMismatch     = "password_mismatch"
+	CodeInvalidInviteCode    = "invalid_invite_code"
+	CodeInvalidIDToken       = "invalid_id_token"
+	CodeInvalidSession       = "invalid_session"
+	CodeInvalidAccountType   = "invalid_account_type"
+	CodeOrganizationEmail    = "organization_email"
+	CodeDisplayNameRequired  = "display_name_required"
+	CodeOwnersRequired       = "owners_required"
+	CodeTooManyOwners        = "too_many_owners"
+	CodeUnknownOwner         = "unknown_owner"
+	CodeInvalidPhone         = "invalid_phone"
+	CodePhoneInUse           = "phone_in_use"
+	CodeInvalidPhoneCode     = "invalid_phone_code"
+	CodePhoneCodeLimit       = "phone_code_limit"
+	CodeInvalidLocale        = "invalid_locale"
+	CodeInvalidCountry       = "invalid_country"
+	CodeInvalidTimezone      = "invalid_timezone"
+	CodeInvalidConsent       = "invalid_consent"
+	CodeConsentRequired      = "consent_required"
+	CodeCaptchaRequired      = "captcha_required"
+	CodeInvalidRequest       = "invalid_request"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+)
+
+// Request fields that validation failures are reported against.
+const (
+	FieldHandle          = "handle"
+	FieldDisplayName     = "displayName"
+	FieldBio             = "bio"
+	FieldPronouns        = "pronouns"
+	FieldWebsite         = "website"
+	FieldLocation        = "location"
+	FieldEmail           = "email"
+	FieldPassword        = "password"
+	FieldPasswordConfirm = "passwordConfirm"
+	FieldInviteCode      = "inviteCode"
+	FieldIDToken         = "idToken"
+	FieldAccessJWT       = "accessJwt"
+	FieldPhone           = "phone"
+	FieldPhoneCode       = "code"
+	FieldLocale          = "locale"
+	FieldCountry         = "country"
+	FieldTimezone        = "timezone"
+	FieldCaptchaToken    = "captchaToken"
+	FieldConsents        = "consents"
+	FieldAccountType     = "accountType"
+	FieldOwners          = "owners"
+	FieldVersion         = "version"
+)
+
+// ValidationError is a validation failure with a stable code. Message is
+// English text meant for logs; clients should localize by Code and fill in
+// Params, such as the minimum length, rather than parsing Message.
+// Field is empty for failures that aren't about one field.
+type ValidationError struct {
+	Field   string
+	Code    string
+	Message string
+	Params  map[string]interface{}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) ErrorCode() string {
+	return e.Code
+}
+
+// NewError returns a ValidationError with a formatted message.
+func NewError(code, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ForField sets the field the failure is reported against.
+func (e *ValidationError) ForField(field string) *ValidationError {
+	e.Field = field
+	return e
+}
+
+// With sets a message parameter.
+func (e *ValidationError) With(key string, value interface{}) *ValidationError {
+	if e.Params == nil {
+		e.Params = map[string]interface{}{}
+	}
+	e.Params[key] = value
+	return e
+}
+
+// ValidationErrors holds every failure from one validation run so clients
+// can show them all at once.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// AsValidationError returns the first ValidationError in err's chain.
+func AsValidationError(err error) (*ValidationError, bool) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr, true
+	}
+	return nil, false
+}
+
+type codedError interface {
+	ErrorCode() string
+}
+
+// ErrorCode returns the validation code carried anywhere in err's chain, or
+// "" if err is not a validation failure.
+// For ValidationErrors this is the code of the first failure.
+func ErrorCode(err error) string {
+	var coded codedError
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return ""
+}
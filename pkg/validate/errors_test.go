@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	err := NewError(CodeHandleTooShort, "handle must be at least 3 characters long: %v", "ab").
+		ForField(FieldHandle).
+		With("min", MinHandleLength)
+
+	assert.Equal(t, "handle must be at least 3 characters long: ab", err.Error())
+	assert.Equal(t, FieldHandle, err.Field)
+	assert.Equal(t, map[string]interface{}{"min": MinHandleLength}, err.Params)
+
+	wrapped := fmt.Errorf("signup failed: %w", ValidationErrors{err, NewError(CodeInvalidEmail, InvalidEmail)})
+	found, ok := AsValidationError(wrapped)
+	assert.True(t, ok)
+	assert.Same(t, err, found)
+	assert.Equal(t, CodeHandleTooShort, ErrorCode(wrapped))
+
+	_, ok = AsValidationError(fmt.Errorf("internal error"))
+	assert.False(t, ok)
+}
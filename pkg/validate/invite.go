@@ -0,0 +1,26 @@
+package validate
+
+import (
+	"regexp"
+	"strings"
+)
+
+const InvalidInviteCode = "invite code is invalid or has already been used"
+
+// PDS invite codes are the PDS hostname with dots replaced by dashes,
+// followed by two groups of five base32 characters, for example
+// "shareframe-app-abcde-fghij".
+var inviteCodeRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*-[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+// NormalizeInviteCode trims and lowercases a code as typed by a user.
+func NormalizeInviteCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// InviteCode checks the format of a normalized invite code.
+func InviteCode(code string) error {
+	if !inviteCodeRegex.MatchString(code) {
+		return NewError(CodeInvalidInviteCode, "%v", InvalidInviteCode).ForField(FieldInviteCode)
+	}
+	return nil
+}
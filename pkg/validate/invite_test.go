@@ -0,0 +1,37 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInviteCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		expectedErr bool
+	}{
+		{"Valid Code", "shareframe-app-abcde-fghij", false},
+		{"Single Label Host", "localhost-a2b3c-d4e5f", false},
+		{"Normalized From User Input", NormalizeInviteCode("  ShareFrame-App-ABCDE-FGHIJ "), false},
+		{"Empty", "", true},
+		{"Missing Group", "shareframe-app-abcde", true},
+		{"Short Group", "shareframe-app-abcd-fghij", true},
+		{"Non Base32 Characters", "shareframe-app-abcd1-fghij", true},
+		{"Uppercase", "shareframe-app-ABCDE-FGHIJ", true},
+		{"Whitespace", "shareframe-app-abcde fghij", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := InviteCode(test.code)
+			if !test.expectedErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, CodeInvalidInviteCode, ErrorCode(err))
+			assert.EqualError(t, err, InvalidInviteCode)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package validate
+
+import (
+	_ "embed"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	InvalidLocale  = "locale must be a BCP 47 language tag, such as en or pt-BR"
+	InvalidCountry = "country must be an ISO 3166-1 alpha-2 code, such as US or DE"
+)
+
+//go:embed iso3166-1.txt
+var iso3166File string
+
+// countries holds the officially assigned ISO 3166-1 alpha-2 codes.
+var countries = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, line := range strings.Split(iso3166File, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			codes[line] = true
+		}
+	}
+	return codes
+}()
+
+// Locale checks a BCP 47 language tag against the IANA subtag registry and
+// returns it in canonical form, so "pt_br" becomes "pt-BR". A region
+// subtag, when present, must be an assigned country or a UN M.49 area such
+// as 419.
+func Locale(locale string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil || tag == language.Und {
+		return "", invalidLocale(locale)
+	}
+
+	if region, confidence := tag.Region(); confidence == language.Exact {
+		if code := region.String(); len(code) == 2 && !countries[code] {
+			return "", invalidLocale(locale)
+		}
+	}
+	return tag.String(), nil
+}
+
+func invalidLocale(locale string) error {
+	return NewError(CodeInvalidLocale, "%v: %v", InvalidLocale, locale).ForField(FieldLocale)
+}
+
+// Country checks an ISO 3166-1 alpha-2 code and returns it uppercased.
+func Country(country string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(country))
+	if !countries[code] {
+		return "", NewError(CodeInvalidCountry, "%v: %v", InvalidCountry, country).ForField(FieldCountry)
+	}
+	return code, nil
+}
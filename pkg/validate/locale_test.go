@@ -0,0 +1,68 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name        string
+		locale      string
+		expected    string
+		expectedErr bool
+	}{
+		{"Language", "en", "en", false},
+		{"Language And Region", "pt-BR", "pt-BR", false},
+		{"Canonicalized", "pt_br", "pt-BR", false},
+		{"Script", "zh-Hant-TW", "zh-Hant-TW", false},
+		{"Area Code Region", "es-419", "es-419", false},
+		{"Unknown Language", "xx", "", true},
+		{"Unassigned Region", "en-XX", "", true},
+		{"Undetermined", "und", "", true},
+		{"Not A Tag", "english", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			locale, err := Locale(test.locale)
+			if test.expectedErr {
+				assert.Equal(t, CodeInvalidLocale, ErrorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, locale)
+		})
+	}
+}
+
+func TestCountry(t *testing.T) {
+	tests := []struct {
+		name        string
+		country     string
+		expected    string
+		expectedErr bool
+	}{
+		{"Assigned Code", "US", "US", false},
+		{"Lowercase", " de ", "DE", false},
+		{"Antarctica", "AQ", "AQ", false},
+		{"Alpha-3", "USA", "", true},
+		{"Reserved Code", "UK", "", true},
+		{"User Assigned", "XK", "", true},
+		{"Withdrawn Code", "YU", "", true},
+		{"Numeric", "840", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			country, err := Country(test.country)
+			if test.expectedErr {
+				assert.Equal(t, CodeInvalidCountry, ErrorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, country)
+		})
+	}
+}
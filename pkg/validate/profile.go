@@ -0,0 +1,88 @@
+package validate
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Profile field limits. The bio and pronouns limits match the
+// app.bsky.actor.profile record they are written to.
+const (
+	MaxBioLength      = 256
+	MaxPronounsLength = 20
+	MaxWebsiteLength  = 256
+	MaxLocationLength = 64
+
+	InvalidWebsite = "website must be an http or https URL"
+)
+
+// NormalizeBio NFC-normalizes a bio, drops control and invisible
+// formatting characters other than line breaks and zero-width joiners,
+// and trims surrounding whitespace.
+func NormalizeBio(bio string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(bio) {
+		if r != '\n' && (unicode.IsControl(r) || unicode.Is(unicode.Cf, r) && r != zeroWidthJoiner) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Bio checks a normalized bio. An empty bio is valid.
+func Bio(bio string) error {
+	return maxLength(bio, MaxBioLength, CodeBioTooLong, FieldBio, "bio")
+}
+
+// Pronouns checks pronouns normalized like a display name, e.g.
+// "she/her". Empty pronouns are valid.
+func Pronouns(pronouns string) error {
+	return maxLength(pronouns, MaxPronounsLength, CodePronounsTooLong, FieldPronouns, "pronouns")
+}
+
+// Location checks a location normalized like a display name. It is free
+// text such as "Lisbon, Portugal". An empty location is valid.
+func Location(location string) error {
+	return maxLength(location, MaxLocationLength, CodeLocationTooLong, FieldLocation, "location")
+}
+
+func maxLength(value string, max int, code, field, name string) error {
+	if utf8.RuneCountInString(value) > max {
+		return NewError(code, "%s cannot exceed %d characters", name, max).ForField(field).With("max", max)
+	}
+	return nil
+}
+
+// Website checks a website address and returns it in canonical form. An
+// address without a scheme, such as "example.com", is taken to be https.
+// Only public-looking http and https URLs without credentials are
+// accepted.
+func Website(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+
+	u, err := url.Parse(website)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil ||
+		!strings.Contains(strings.Trim(u.Hostname(), "."), ".") {
+		return "", invalidWebsite(website)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	website = u.String()
+	if len(website) > MaxWebsiteLength {
+		return "", invalidWebsite(website)
+	}
+	return website, nil
+}
+
+func invalidWebsite(website string) error {
+	return NewError(CodeInvalidWebsite, "%v: %v", InvalidWebsite, website).ForField(FieldWebsite)
+}
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"strings"
+
+	zxcvbn "github.com/ccojocar/zxcvbn-go"
+)
+
+const MaxPasswordScore = 4
+
+const (
+	feedbackCommonPassword = "this is similar to a commonly used password"
+	feedbackUserInputs     = "avoid using your handle or email in the password"
+	feedbackCommonWords    = "avoid common words and names on their own"
+	feedbackSequence       = "avoid sequences like abc or 123"
+	feedbackRepeat         = "avoid repeated characters like aaa"
+	feedbackSpatial        = "avoid keyboard patterns like qwerty"
+	feedbackDate           = "avoid dates and years"
+	feedbackLonger         = "add another word or two; uncommon words are better"
+)
+
+// ScorePassword estimates password strength on zxcvbn's 0-4 scale and
+// explains the weak patterns it found. userInputs are values like the
+// handle and email that should not appear in the password.
+func ScorePassword(password string, userInputs []string) (int, []string) {
+	result := zxcvbn.PasswordStrength(password, userInputs)
+
+	var feedback []string
+	add := func(message string) {
+		for _, existing := range feedback {
+			if existing == message {
+				return
+			}
+		}
+		feedback = append(feedback, message)
+	}
+
+	for _, m := range result.MatchSequence {
+		switch m.Pattern {
+		case "dictionary":
+			switch {
+			case m.DictionaryName == "user_inputs":
+				add(feedbackUserInputs)
+			case m.DictionaryName == "Passwords":
+				add(feedbackCommonPassword)
+			case len(m.Token) >= 3:
+				add(feedbackCommonWords)
+			}
+		case "sequence":
+			add(feedbackSequence)
+		case "repeat":
+			add(feedbackRepeat)
+		case "spatial":
+			add(feedbackSpatial)
+		case "date":
+			add(feedbackDate)
+		}
+	}
+
+	return result.Score, feedback
+}
+
+// PasswordStrength rejects passwords scoring below minScore. A
+// minScore of zero disables the check. The error carries the score,
+// minScore, maxScore and feedback params so clients can show a meter.
+func PasswordStrength(password string, minScore int, userInputs []string) error {
+	if minScore <= 0 {
+		return nil
+	}
+
+	score, feedback := ScorePassword(password, userInputs)
+	if score >= minScore {
+		return nil
+	}
+
+	if len(feedback) == 0 {
+		feedback = []string{feedbackLonger}
+	}
+	return NewError(CodePasswordTooWeak, "password is too weak (score %d of %d, need %d): %s",
+		score, MaxPasswordScore, minScore, strings.Join(feedback, "; ")).
+		ForField(FieldPassword).
+		With("score", score).
+		With("minScore", minScore).
+		With("maxScore", MaxPasswordScore).
+		With("feedback", feedback)
+}
@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordStrength(t *testing.T) {
+	tests := []struct {
+		name             string
+		password         string
+		minScore         int
+		expectedErr      bool
+		expectedFeedback string
+	}{
+		{"Common Password", "Password1!", 3, true, feedbackCommonPassword},
+		{"Keyboard Pattern", "qwerty123!A", 3, true, feedbackSequence},
+		{"Contains Handle", "alice2024!A", 3, true, feedbackUserInputs},
+		{"Passphrase", "correct horse battery staple", 3, false, ""},
+		{"Random", "xK9#mP2$vL7q", 4, false, ""},
+		{"Scoring Disabled", "Password1!", 0, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := PasswordStrength(test.password, test.minScore, []string{"alice", "alice.smith"})
+			if !test.expectedErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			weak, ok := AsValidationError(err)
+			assert.True(t, ok)
+			assert.Equal(t, CodePasswordTooWeak, weak.Code)
+			assert.Equal(t, FieldPassword, weak.Field)
+			assert.Less(t, weak.Params["score"], test.minScore)
+			assert.Equal(t, test.minScore, weak.Params["minScore"])
+			assert.Contains(t, weak.Params["feedback"], test.expectedFeedback)
+		})
+	}
+}
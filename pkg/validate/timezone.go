@@ -0,0 +1,31 @@
+package validate
+
+import (
+	"strings"
+	"time"
+	// Lambda images don't ship a zoneinfo database, so embed one.
+	_ "time/tzdata"
+)
+
+const InvalidTimezone = "timezone must be an IANA time zone name, such as Europe/Berlin"
+
+// Timezone checks an IANA time zone name such as "America/New_York" and
+// returns the name of the loaded location.
+func Timezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	// LoadLocation maps "" to UTC and "Local" to the server's zone; neither
+	// is something a user chose.
+	if timezone == "" || timezone == "Local" {
+		return "", invalidTimezone(timezone)
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", invalidTimezone(timezone)
+	}
+	return location.String(), nil
+}
+
+func invalidTimezone(timezone string) error {
+	return NewError(CodeInvalidTimezone, "%v: %v", InvalidTimezone, timezone).ForField(FieldTimezone)
+}
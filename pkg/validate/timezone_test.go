@@ -0,0 +1,37 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimezone(t *testing.T) {
+	tests := []struct {
+		name        string
+		timezone    string
+		expected    string
+		expectedErr bool
+	}{
+		{"Region Name", "America/New_York", "America/New_York", false},
+		{"Trimmed", " Europe/Berlin ", "Europe/Berlin", false},
+		{"UTC", "UTC", "UTC", false},
+		{"Unknown Zone", "Mars/Olympus_Mons", "", true},
+		{"Offset", "+02:00", "", true},
+		{"Server Local", "Local", "", true},
+		{"Path Traversal", "../../etc/passwd", "", true},
+		{"Empty", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timezone, err := Timezone(test.timezone)
+			if test.expectedErr {
+				assert.Equal(t, CodeInvalidTimezone, ErrorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, timezone)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package validate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+const MixedScriptHandle = "handle cannot mix characters from different scripts"
+
+// handleScripts lists the scripts an internationalized handle may be written
+// in. Runes outside these tables are rejected rather than guessed at.
+var handleScripts = map[string]*unicode.RangeTable{
+	"Latin":      unicode.Latin,
+	"Greek":      unicode.Greek,
+	"Cyrillic":   unicode.Cyrillic,
+	"Arabic":     unicode.Arabic,
+	"Hebrew":     unicode.Hebrew,
+	"Devanagari": unicode.Devanagari,
+	"Thai":       unicode.Thai,
+	"Hangul":     unicode.Hangul,
+	"Han":        unicode.Han,
+	"Hiragana":   unicode.Hiragana,
+	"Katakana":   unicode.Katakana,
+}
+
+// scriptCombinations are the multi-script mixes that occur in ordinary
+// writing; any other mix is treated as a likely homograph.
+var scriptCombinations = []map[string]bool{
+	{"Han": true, "Hiragana": true, "Katakana": true},
+	{"Han": true, "Hangul": true},
+}
+
+var handleProfile = idna.New(
+	idna.MapForLookup(),
+	idna.Transitional(false),
+	idna.VerifyDNSLength(true),
+)
+
+// NormalizeUnicodeHandle validates an internationalized handle and returns
+// its NFC-normalized display form along with the punycode form used in DNS.
+func NormalizeUnicodeHandle(handle string) (string, string, error) {
+	display := strings.ToLower(norm.NFC.String(handle))
+
+	length := utf8.RuneCountInString(display)
+	if length < MinHandleLength {
+		return "", "", NewError(CodeHandleTooShort, "handle must be at least 3 characters long: %v", handle).
+			ForField(FieldHandle).With("min", MinHandleLength)
+	}
+	if length > MaxHandleLength {
+		return "", "", NewError(CodeHandleTooLong, "handle cannot exceed 18 characters: %v", handle).
+			ForField(FieldHandle).With("max", MaxHandleLength)
+	}
+
+	scripts := map[string]bool{}
+	for _, r := range display {
+		if unicode.IsDigit(r) && r < utf8.RuneSelf {
+			continue
+		}
+		script := scriptOf(r)
+		if script == "" || !unicode.IsLetter(r) {
+			return "", "", NewError(CodeInvalidHandle, "provided handle is invalid: %v", InvalidHandle).ForField(FieldHandle)
+		}
+		scripts[script] = true
+	}
+	if !allowedScriptMix(scripts) {
+		return "", "", NewError(CodeMixedScriptHandle, "provided handle is invalid: %v", MixedScriptHandle).ForField(FieldHandle)
+	}
+
+	ascii, err := handleProfile.ToASCII(display)
+	if err != nil {
+		return "", "", NewError(CodeInvalidHandle, "provided handle is invalid: %v", err).ForField(FieldHandle)
+	}
+
+	return display, ascii, nil
+}
+
+func scriptOf(r rune) string {
+	for name, table := range handleScripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+func allowedScriptMix(scripts map[string]bool) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	for _, combination := range scriptCombinations {
+		allowed := true
+		for script := range scripts {
+			if !combination[script] {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
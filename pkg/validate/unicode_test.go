@@ -0,0 +1,41 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUnicodeHandle(t *testing.T) {
+	tests := []struct {
+		name            string
+		handle          string
+		expectedDisplay string
+		expectedASCII   string
+		expectedErr     string
+	}{
+		{"Latin With Diacritics", "München", "münchen", "xn--mnchen-3ya", ""},
+		{"Decomposed Input Is Normalized", "Mu\u0308nchen", "münchen", "xn--mnchen-3ya", ""},
+		{"Japanese Mix", "すし寿司", "すし寿司", "xn--68jd274y3yg", ""},
+		{"Cyrillic With Digits", "привет42", "привет42", "xn--42-dlcmn5bht", ""},
+		{"Mixed Latin And Cyrillic", "pаypal", "", "", MixedScriptHandle},
+		{"Emoji Rejected", "hi😀there", "", "", InvalidHandle},
+		{"Too Short", "ü", "", "", "handle must be at least 3 characters long"},
+		{"Too Long", "üüüüüüüüüüüüüüüüüüü", "", "", "handle cannot exceed 18 characters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			display, ascii, err := NormalizeUnicodeHandle(test.handle)
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedDisplay, display)
+			assert.Equal(t, test.expectedASCII, ascii)
+		})
+	}
+}
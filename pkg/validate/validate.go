@@ -0,0 +1,180 @@
+// Package validate holds the signup field rules shared by the user-creation
+// service and ShareFrame's other backends, so every client enforces the same
+// handle, email, password and display name rules. It has no dependencies on
+// AWS or the service's logging so it can be imported anywhere.
+//
+// Checks that need storage or policy lists, such as duplicate emails and
+// reserved handles, stay in the service.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 18
+	// MaxOrganizationHandleLength leaves room for brand and community
+	// names, which run longer than people's.
+	MaxOrganizationHandleLength = 32
+
+	PasswordError  = "password must be at least 8 characters long and include at least one uppercase letter, one lowercase letter, one digit, and one special character"
+	InvalidHandle  = "handle can only include letters and numbers"
+	HandleTooShort = "handle must be at least 3 characters long"
+	HandleTooLong  = "handle cannot exceed 18 characters"
+	InvalidEmail   = "invalid email format"
+
+	InvalidDomainHandle = "handle must be a valid domain name"
+	maxDomainLength     = 253
+	maxLabelLength      = 63
+
+	// RFC 5321 limits: a 64 octet local part, and a 254 octet address once
+	// the angle brackets of a forward path are taken off its 256.
+	maxEmailLength     = 254
+	maxLocalPartLength = 64
+)
+
+var (
+	emailRegex       = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	handleRegex      = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	upperCaseRegex   = regexp.MustCompile(`[A-Z]`)
+	lowerCaseRegex   = regexp.MustCompile(`[a-z]`)
+	digitRegex       = regexp.MustCompile(`\d`)
+	specialCharRegex = regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{}|;:'",.<>?/\\]`)
+	labelRegex       = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+// Handle checks the length and characters of an ASCII handle without its
+// domain suffix. Internationalized handles go through NormalizeUnicodeHandle.
+func Handle(handle string) error {
+	return handleWithin(handle, MaxHandleLength)
+}
+
+// OrganizationHandle is Handle for organization accounts, which may be up
+// to MaxOrganizationHandleLength characters long.
+func OrganizationHandle(handle string) error {
+	return handleWithin(handle, MaxOrganizationHandleLength)
+}
+
+func handleWithin(handle string, max int) error {
+	if len(handle) < MinHandleLength {
+		return NewError(CodeHandleTooShort, "handle must be at least 3 characters long: %v", handle).
+			ForField(FieldHandle).With("min", MinHandleLength)
+	}
+	if len(handle) > max {
+		return NewError(CodeHandleTooLong, "handle cannot exceed %d characters: %v", max, handle).
+			ForField(FieldHandle).With("max", max)
+	}
+	if !handleRegex.MatchString(handle) {
+		return NewError(CodeInvalidHandle, "provided handle is invalid: %v", InvalidHandle).ForField(FieldHandle)
+	}
+	return nil
+}
+
+// DomainHandle checks a custom-domain handle such as "alice.example.com"
+// against the AT Protocol handle syntax. It expects a lowercased domain
+// without a trailing dot.
+func DomainHandle(domain string) error {
+	labels := strings.Split(domain, ".")
+	if len(domain) > maxDomainLength || len(labels) < 2 {
+		return invalidDomain(domain)
+	}
+	for _, label := range labels {
+		if len(label) > maxLabelLength || !labelRegex.MatchString(label) {
+			return invalidDomain(domain)
+		}
+	}
+	// The top-level domain can't start with a digit.
+	if tld := labels[len(labels)-1]; tld[0] >= '0' && tld[0] <= '9' {
+		return invalidDomain(domain)
+	}
+	return nil
+}
+
+func invalidDomain(domain string) error {
+	return NewError(CodeInvalidDomainHandle, "%v: %v", InvalidDomainHandle, domain).
+		ForField(FieldHandle).With("domain", domain)
+}
+
+// EnsureHandleSuffix appends the PDS domain suffix unless handle has it.
+func EnsureHandleSuffix(handle, suffix string) string {
+	if strings.HasSuffix(handle, suffix) {
+		return handle
+	}
+	return handle + suffix
+}
+
+// IsASCII reports whether value has only ASCII characters.
+func IsASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Email checks the format of an address against the RFC 5321 length
+// limits and the dot-atom syntax mail servers accept. Quoted local parts
+// and address literals are legal but rarely deliverable, so they are
+// rejected. Callers should canonicalize the address with CanonicalizeEmail
+// first.
+func Email(email string) error {
+	if len(email) > maxEmailLength {
+		return invalidEmail("address exceeds %d characters", maxEmailLength)
+	}
+	if !emailRegex.MatchString(email) {
+		return invalidEmail("")
+	}
+
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], email[at+1:]
+	if len(local) > maxLocalPartLength {
+		return invalidEmail("local part exceeds %d characters", maxLocalPartLength)
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return invalidEmail("local part has a leading, trailing or repeated dot")
+	}
+	if len(domain) > maxDomainLength {
+		return invalidEmail("domain exceeds %d characters", maxDomainLength)
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > maxLabelLength || label[0] == '-' || label[len(label)-1] == '-' {
+			return invalidEmail("domain %q is not a valid host name", domain)
+		}
+	}
+
+	// Anything the checks above let through must still be a bare address to
+	// the standard library's RFC 5322 parser.
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return invalidEmail("")
+	}
+	return nil
+}
+
+func invalidEmail(format string, args ...interface{}) error {
+	if format == "" {
+		return NewError(CodeInvalidEmail, InvalidEmail).ForField(FieldEmail)
+	}
+	return NewError(CodeInvalidEmail, "%v: %v", InvalidEmail, fmt.Sprintf(format, args...)).ForField(FieldEmail)
+}
+
+// Password checks the length and character-class requirements.
+func Password(password string) error {
+	if len(password) < 8 {
+		return NewError(CodePasswordRequirements, PasswordError).ForField(FieldPassword)
+	}
+
+	if !upperCaseRegex.MatchString(password) ||
+		!lowerCaseRegex.MatchString(password) ||
+		!digitRegex.MatchString(password) ||
+		!specialCharRegex.MatchString(password) {
+		return NewError(CodePasswordRequirements, PasswordError).ForField(FieldPassword)
+	}
+
+	return nil
+}
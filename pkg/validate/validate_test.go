@@ -0,0 +1,151 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		handle       string
+		expectedCode string
+	}{
+		{"Valid Handle", "validuser", ""},
+		{"Too Short", "ab", CodeHandleTooShort},
+		{"Too Long", "thisisaverylonghandle", CodeHandleTooLong},
+		{"Contains Special Characters", "invalid@handle", CodeInvalidHandle},
+		{"Non ASCII", "münchen", CodeInvalidHandle},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedCode, ErrorCode(Handle(test.handle)))
+		})
+	}
+}
+
+func TestOrganizationHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		handle       string
+		expectedCode string
+	}{
+		{"Longer Than A Person's", "thisisaverylonghandle", ""},
+		{"Too Short", "ab", CodeHandleTooShort},
+		{"Too Long", strings.Repeat("a", MaxOrganizationHandleLength+1), CodeHandleTooLong},
+		{"Contains Special Characters", "acme-corp", CodeInvalidHandle},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedCode, ErrorCode(OrganizationHandle(test.handle)))
+		})
+	}
+}
+
+func TestDomainHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		domain       string
+		expectedCode string
+	}{
+		{"Valid Domain", "alice.example.com", ""},
+		{"Hyphenated Label", "my-site.co.uk", ""},
+		{"Single Label", "localhost", CodeInvalidDomainHandle},
+		{"Empty Label", "alice..example.com", CodeInvalidDomainHandle},
+		{"Leading Hyphen", "-alice.example.com", CodeInvalidDomainHandle},
+		{"Underscore", "alice_b.example.com", CodeInvalidDomainHandle},
+		{"Numeric TLD", "alice.example.123", CodeInvalidDomainHandle},
+		{"Label Too Long", strings.Repeat("a", 64) + ".com", CodeInvalidDomainHandle},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedCode, ErrorCode(DomainHandle(test.domain)))
+		})
+	}
+}
+
+func TestPassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		expectedErr string
+	}{
+		{"Valid Password", "Strong@123", ""},
+		{"Too Short", "Short1!", PasswordError},
+		{"No Uppercase", "weakpassword1!", PasswordError},
+		{"No Lowercase", "WEAKPASSWORD1!", PasswordError},
+		{"No Digit", "NoDigits!!", PasswordError},
+		{"No Special Character", "NoSpecial1", PasswordError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Password(test.password)
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEnsureHandleSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		handle   string
+		expected string
+	}{
+		{"Already Has Suffix", "username.shareframe.social", "username.shareframe.social"},
+		{"Missing Suffix", "username", "username.shareframe.social"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := EnsureHandleSuffix(test.handle, ".shareframe.social")
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       string
+		expectedErr string
+	}{
+		{"Valid Email", "user@example.com", ""},
+		{"Missing @", "userexample.com", "invalid email format"},
+		{"Missing domain", "user@", "invalid email format"},
+		{"Invalid TLD", "user@example.c", "invalid email format"},
+		{"Plus Addressing", "user+tag@mail.example.com", ""},
+		{"Longest Local Part", strings.Repeat("a", 64) + "@example.com", ""},
+		{"Local Part Too Long", strings.Repeat("a", 65) + "@example.com", "local part exceeds 64 characters"},
+		{"Address Too Long", "user@" + strings.Repeat(strings.Repeat("a", 60)+".", 5) + "com", "address exceeds 254 characters"},
+		{"Label Too Long", "user@" + strings.Repeat("a", 64) + ".com", "is not a valid host name"},
+		{"Leading Dot", ".user@example.com", "leading, trailing or repeated dot"},
+		{"Trailing Dot", "user.@example.com", "leading, trailing or repeated dot"},
+		{"Consecutive Dots", "first..last@example.com", "leading, trailing or repeated dot"},
+		{"Empty Domain Label", "user@example..com", "is not a valid host name"},
+		{"Leading Dot In Domain", "user@.example.com", "is not a valid host name"},
+		{"Hyphen At Label Edge", "user@-example.com", "is not a valid host name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Email(test.email)
+			if test.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}